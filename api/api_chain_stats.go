@@ -0,0 +1,251 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/store"
+)
+
+const (
+	// defaultChainStatsBlocks is the default number of recent blocks of the chain statistics
+	defaultChainStatsBlocks = uint64(100)
+
+	// maxChainStatsBlocks is the max number of recent blocks of the chain statistics
+	maxChainStatsBlocks = uint64(10000)
+
+	// chainStatsCacheSize is the number of cached block statistics
+	chainStatsCacheSize = 20000
+)
+
+// blockStats is the statistics of a block, which is cached by the block hash
+type blockStats struct {
+	parent     common.Hash
+	height     uint64
+	timestamp  uint64
+	difficulty *big.Int
+	txs        uint64
+	debts      uint64
+	usedGas    uint64
+}
+
+// IntervalStats is the statistics of the intervals in seconds between the consecutive blocks
+type IntervalStats struct {
+	Average float64
+	Min     uint64
+	Max     uint64
+	P50     uint64
+	P90     uint64
+	P99     uint64
+}
+
+// DifficultyStats is the trend of the block difficulty
+type DifficultyStats struct {
+	First   *big.Int // difficulty of the first block
+	Last    *big.Int // difficulty of the last block
+	Min     *big.Int
+	Max     *big.Int
+	Average *big.Int
+	Change  float64 // percentage of the difference between the last and first difficulty
+}
+
+// CountStats is the statistics of a per-block amount
+type CountStats struct {
+	Total   uint64
+	Average float64
+	Max     uint64
+}
+
+// ChainStats is the statistics of the recent blocks of the chain
+type ChainStats struct {
+	From   uint64 // height of the first block
+	To     uint64 // height of the last block
+	Blocks uint64
+
+	BlockInterval IntervalStats
+	Difficulty    DifficultyStats
+	TxsPerBlock   CountStats // reward txs are excluded
+	DebtsPerBlock CountStats
+	GasUsed       CountStats
+}
+
+// GetChainStats returns the statistics of the block interval, difficulty, txs, debts and gas usage
+// of the recent blocks, 0 means the default 100 blocks.
+func (api *PublicScdoAPI) GetChainStats(lastNBlocks uint64) (*ChainStats, error) {
+	if lastNBlocks == 0 {
+		lastNBlocks = defaultChainStatsBlocks
+	}
+
+	if lastNBlocks > maxChainStatsBlocks {
+		lastNBlocks = maxChainStatsBlocks
+	}
+
+	head := api.s.ChainBackend().CurrentHeader()
+	if lastNBlocks > head.Height-common.ScdoForkHeight+1 {
+		lastNBlocks = head.Height - common.ScdoForkHeight + 1
+	}
+
+	bcStore := api.s.ChainBackend().GetStore()
+
+	// the stats of the blocks in descending order, and the parent of the first block if any
+	var blocks []*blockStats
+	for hash := head.Hash(); uint64(len(blocks)) <= lastNBlocks; {
+		stats, err := api.getBlockStats(bcStore, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, stats)
+		if stats.height == common.ScdoForkHeight {
+			break
+		}
+
+		hash = stats.parent
+	}
+
+	return computeChainStats(blocks, lastNBlocks), nil
+}
+
+// getBlockStats returns the statistics of the block from the cache, or computes them from the store.
+func (api *PublicScdoAPI) getBlockStats(bcStore store.BlockchainStore, hash common.Hash) (*blockStats, error) {
+	if cached, ok := api.blockStatsCache.Get(hash); ok {
+		return cached.(*blockStats), nil
+	}
+
+	block, err := bcStore.GetBlock(hash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get block %s", hash.Hex())
+	}
+
+	stats := &blockStats{
+		parent:     block.Header.PreviousBlockHash,
+		height:     block.Header.Height,
+		timestamp:  block.Header.CreateTimestamp.Uint64(),
+		difficulty: block.Header.Difficulty,
+		debts:      uint64(len(block.Debts)),
+	}
+
+	// the first tx is the reward tx except the genesis block
+	if len(block.Transactions) > 0 && block.Header.Height > common.ScdoForkHeight {
+		stats.txs = uint64(len(block.Transactions) - 1)
+	}
+
+	if len(block.Transactions) > 0 {
+		receipts, err := bcStore.GetReceiptsByBlockHash(hash)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get receipts of block %s", hash.Hex())
+		}
+
+		for _, receipt := range receipts {
+			stats.usedGas += receipt.UsedGas
+		}
+	}
+
+	api.blockStatsCache.Add(hash, stats)
+
+	return stats, nil
+}
+
+// computeChainStats computes the statistics of the blocks in descending order, which may
+// contain the parent of the first block to compute the interval of the first block.
+func computeChainStats(blocks []*blockStats, n uint64) *ChainStats {
+	if uint64(len(blocks)) < n {
+		n = uint64(len(blocks))
+	}
+
+	result := &ChainStats{Blocks: n}
+	if n == 0 {
+		return result
+	}
+
+	result.To, result.From = blocks[0].height, blocks[n-1].height
+
+	var intervals []uint64
+	difficultySum := new(big.Int)
+	for i := uint64(0); i < n; i++ {
+		b := blocks[i]
+
+		// the time of the genesis block is set by users, so the interval after it is skipped
+		if i+1 < uint64(len(blocks)) && blocks[i+1].height > common.ScdoForkHeight {
+			var interval uint64
+			if parentTime := blocks[i+1].timestamp; b.timestamp > parentTime {
+				interval = b.timestamp - parentTime
+			}
+
+			intervals = append(intervals, interval)
+		}
+
+		if b.difficulty != nil {
+			difficultySum.Add(difficultySum, b.difficulty)
+			if result.Difficulty.Min == nil || b.difficulty.Cmp(result.Difficulty.Min) < 0 {
+				result.Difficulty.Min = b.difficulty
+			}
+
+			if result.Difficulty.Max == nil || b.difficulty.Cmp(result.Difficulty.Max) > 0 {
+				result.Difficulty.Max = b.difficulty
+			}
+		}
+
+		result.TxsPerBlock.add(b.txs)
+		result.DebtsPerBlock.add(b.debts)
+		result.GasUsed.add(b.usedGas)
+	}
+
+	result.BlockInterval = computeIntervalStats(intervals)
+
+	result.Difficulty.First, result.Difficulty.Last = blocks[n-1].difficulty, blocks[0].difficulty
+	result.Difficulty.Average = difficultySum.Div(difficultySum, new(big.Int).SetUint64(n))
+	if first, last := result.Difficulty.First, result.Difficulty.Last; first != nil && last != nil && first.Sign() > 0 {
+		change := new(big.Float).SetInt(new(big.Int).Sub(last, first))
+		change.Quo(change, new(big.Float).SetInt(first))
+		result.Difficulty.Change, _ = change.Mul(change, big.NewFloat(100)).Float64()
+	}
+
+	for _, count := range []*CountStats{&result.TxsPerBlock, &result.DebtsPerBlock, &result.GasUsed} {
+		count.Average = float64(count.Total) / float64(n)
+	}
+
+	return result
+}
+
+func (stats *CountStats) add(count uint64) {
+	stats.Total += count
+	if count > stats.Max {
+		stats.Max = count
+	}
+}
+
+func computeIntervalStats(intervals []uint64) IntervalStats {
+	if len(intervals) == 0 {
+		return IntervalStats{}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+
+	var sum uint64
+	for _, interval := range intervals {
+		sum += interval
+	}
+
+	// nearest-rank percentile
+	percentile := func(p int) uint64 {
+		rank := (len(intervals)*p + 99) / 100
+		return intervals[rank-1]
+	}
+
+	return IntervalStats{
+		Average: float64(sum) / float64(len(intervals)),
+		Min:     intervals[0],
+		Max:     intervals[len(intervals)-1],
+		P50:     percentile(50),
+		P90:     percentile(90),
+		P99:     percentile(99),
+	}
+}
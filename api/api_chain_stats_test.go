@@ -0,0 +1,53 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ComputeChainStats(t *testing.T) {
+	genesis := uint64(common.ScdoForkHeight)
+
+	// blocks in descending order with the parent of the first block
+	blocks := []*blockStats{
+		{height: genesis + 4, timestamp: 1060, difficulty: big.NewInt(150), txs: 3, debts: 1, usedGas: 300},
+		{height: genesis + 3, timestamp: 1030, difficulty: big.NewInt(120), txs: 1, usedGas: 100},
+		{height: genesis + 2, timestamp: 1020, difficulty: big.NewInt(100), txs: 2, debts: 2, usedGas: 200},
+		{height: genesis + 1, timestamp: 1000, difficulty: big.NewInt(90)},
+	}
+
+	stats := computeChainStats(blocks, 3)
+	assert.Equal(t, stats.From, genesis+2)
+	assert.Equal(t, stats.To, genesis+4)
+	assert.Equal(t, stats.Blocks, uint64(3))
+	assert.Equal(t, stats.BlockInterval, IntervalStats{Average: 20, Min: 10, Max: 30, P50: 20, P90: 30, P99: 30})
+	assert.Equal(t, stats.Difficulty, DifficultyStats{
+		First:   big.NewInt(100),
+		Last:    big.NewInt(150),
+		Min:     big.NewInt(100),
+		Max:     big.NewInt(150),
+		Average: big.NewInt(123),
+		Change:  50,
+	})
+	assert.Equal(t, stats.TxsPerBlock, CountStats{Total: 6, Average: 2, Max: 3})
+	assert.Equal(t, stats.DebtsPerBlock, CountStats{Total: 3, Average: 1, Max: 2})
+	assert.Equal(t, stats.GasUsed, CountStats{Total: 600, Average: 200, Max: 300})
+
+	// the interval after the genesis block is skipped
+	blocks = append(blocks, &blockStats{height: genesis, timestamp: 1, difficulty: big.NewInt(90)})
+	stats = computeChainStats(blocks, 10)
+	assert.Equal(t, stats.From, genesis)
+	assert.Equal(t, stats.Blocks, uint64(5))
+	assert.Equal(t, stats.BlockInterval.Min, uint64(10))
+	assert.Equal(t, stats.BlockInterval.Max, uint64(30))
+
+	assert.Equal(t, computeChainStats(nil, 10), &ChainStats{})
+}
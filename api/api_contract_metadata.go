@@ -0,0 +1,45 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/contract/system"
+)
+
+// errContractMetadataNotFound is returned if the metadata of the contract is not registered
+var errContractMetadataNotFound = errors.New("contract metadata not registered")
+
+// GetContractMetadata gets the metadata registered by the deployer of the contract in the HEAD state,
+// e.g. for the explorers to display the verified contract info.
+func (api *PublicScdoAPI) GetContractMetadata(contract common.Address) (*system.ContractMetadata, error) {
+	if contract.Equal(common.EmptyAddress) {
+		return nil, ErrInvalidAccount
+	}
+
+	if common.LocalShardNumber != contract.Shard() {
+		return nil, fmt.Errorf("local shard is: %d, the contract shard is: %d, you need to change to shard %d to get the metadata", common.LocalShardNumber, contract.Shard(), contract.Shard())
+	}
+
+	state, err := api.getStatedb("", -1)
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to get statedb")
+	}
+
+	metadata, err := system.GetContractMetadata(state, contract)
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to decode contract metadata")
+	}
+
+	if metadata == nil {
+		return nil, errContractMetadataNotFound
+	}
+
+	return metadata, nil
+}
@@ -11,27 +11,48 @@ import (
 	"strconv"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/common/hexutil"
 	"github.com/scdoproject/go-scdo/core/state"
 	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
 )
 
-// ErrInvalidAccount the account is invalid
-var ErrInvalidAccount = errors.New("invalid account")
+var (
+	// ErrInvalidAccount the account is invalid
+	ErrInvalidAccount = errors.New("invalid account")
+
+	// ErrInvalidCodeHash the code hash is not a 32 bytes hash
+	ErrInvalidCodeHash = errors.New("invalid code hash")
+)
 
 // maximum number of blocks to return in function GetBlocks
 const maxSizeLimit = 64
 
+// status of the transaction returned by GetTransactionByHash
+const (
+	TxStatusPending = "pending"
+	TxStatusMined   = "mined"
+	TxStatusDropped = "dropped"
+)
+
 // PublicScdoAPI provides an API to access full node-related information.
 type PublicScdoAPI struct {
 	s Backend
+
+	blockStatsCache *lru.Cache // block hash -> *blockStats of GetChainStats
 }
 
 // NewPublicScdoAPI creates a new PublicScdoAPI object for rpc service.
 func NewPublicScdoAPI(s Backend) *PublicScdoAPI {
-	return &PublicScdoAPI{s}
+	blockStatsCache, err := lru.New(chainStatsCacheSize)
+	if err != nil {
+		panic(err)
+	}
+
+	return &PublicScdoAPI{s, blockStatsCache}
 }
 
 // GetBalance get balance of the account.
@@ -249,28 +270,68 @@ func (api *PublicScdoAPI) GetBlockByHash(hashHex string, fulltx bool) (map[strin
 	return rpcOutputBlock(block, fulltx, totalDifficulty)
 }
 
-// rpcOutputBlock converts the given block to the RPC output which depends on fullTx
-func rpcOutputBlock(b *types.Block, fullTx bool, totalDifficulty *big.Int) (map[string]interface{}, error) {
-	head := b.Header
-	headmap := map[string]interface{}{
-		"Consensus":         head.Consensus,
-		"CreateTimestamp":   head.CreateTimestamp,
-		"Creator":           head.Creator.Hex(),
-		"DebtHash":          head.DebtHash,
-		"Difficulty":        head.Difficulty,
-		"ExtraData":         head.ExtraData,
-		"Height":            head.Height,
-		"PreviousBlockHash": head.PreviousBlockHash,
-		"ReceiptHash":       head.ReceiptHash,
-		"SecondWitness":     head.SecondWitness,
-		"StateHash":         head.StateHash,
-		"TxDebtHash":        head.TxDebtHash,
-		"TxHash":            head.TxHash,
-		"Witness":           head.Witness,
+// GetHeader returns the requested block header by hash, or by height if the hash is empty.
+// It reads the header only without the block body.
+func (api *PublicScdoAPI) GetHeader(hashHex string, height int64) (map[string]interface{}, error) {
+	if len(hashHex) > 0 {
+		return api.GetHeaderByHash(hashHex)
+	}
+
+	return api.GetHeaderByHeight(height)
+}
+
+// GetHeaderByHeight returns the requested block header. When height is less than 0 the chain head is returned.
+func (api *PublicScdoAPI) GetHeaderByHeight(height int64) (map[string]interface{}, error) {
+	if height < 0 {
+		header := api.s.ChainBackend().CurrentHeader()
+		return api.rpcOutputHeaderWithTD(header.Hash(), header)
+	}
+
+	hash, err := api.s.ChainBackend().GetStore().GetBlockHash(uint64(height))
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := api.s.ChainBackend().GetStore().GetBlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.rpcOutputHeaderWithTD(hash, header)
+}
+
+// GetHeaderByHash returns the requested block header.
+func (api *PublicScdoAPI) GetHeaderByHash(hashHex string) (map[string]interface{}, error) {
+	hash, err := common.HexToHash(hashHex)
+	if err != nil {
+		return nil, err
 	}
 
+	header, err := api.s.ChainBackend().GetStore().GetBlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.rpcOutputHeaderWithTD(hash, header)
+}
+
+func (api *PublicScdoAPI) rpcOutputHeaderWithTD(hash common.Hash, header *types.BlockHeader) (map[string]interface{}, error) {
+	totalDifficulty, err := api.s.GetBlockTotalDifficulty(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"header":          rpcOutputHeader(header),
+		"hash":            hash.Hex(),
+		"totalDifficulty": totalDifficulty,
+	}, nil
+}
+
+// rpcOutputBlock converts the given block to the RPC output which depends on fullTx
+func rpcOutputBlock(b *types.Block, fullTx bool, totalDifficulty *big.Int) (map[string]interface{}, error) {
 	fields := map[string]interface{}{
-		"header": headmap,
+		"header": rpcOutputHeader(b.Header),
 		"hash":   b.HeaderHash.Hex(),
 	}
 
@@ -293,6 +354,31 @@ func rpcOutputBlock(b *types.Block, fullTx bool, totalDifficulty *big.Int) (map[
 	return fields, nil
 }
 
+// PrintableOutputBlock converts the given block to the RPC output which depends on fullTx
+func PrintableOutputBlock(b *types.Block, fullTx bool, totalDifficulty *big.Int) (map[string]interface{}, error) {
+	return rpcOutputBlock(b, fullTx, totalDifficulty)
+}
+
+// rpcOutputHeader converts the given block header to the RPC output
+func rpcOutputHeader(head *types.BlockHeader) map[string]interface{} {
+	return map[string]interface{}{
+		"Consensus":         head.Consensus,
+		"CreateTimestamp":   head.CreateTimestamp,
+		"Creator":           head.Creator.Hex(),
+		"DebtHash":          head.DebtHash,
+		"Difficulty":        head.Difficulty,
+		"ExtraData":         head.ExtraData,
+		"Height":            head.Height,
+		"PreviousBlockHash": head.PreviousBlockHash,
+		"ReceiptHash":       head.ReceiptHash,
+		"SecondWitness":     head.SecondWitness,
+		"StateHash":         head.StateHash,
+		"TxDebtHash":        head.TxDebtHash,
+		"TxHash":            head.TxHash,
+		"Witness":           head.Witness,
+	}
+}
+
 // getOutputDebts return the full details of the input debts if fullTx is true,
 // otherwise only the hashes of the debts are returned
 func getOutputDebts(debts []*types.Debt, fullTx bool) []interface{} {
@@ -329,6 +415,8 @@ func PrintableOutputTx(tx *types.Transaction) map[string]interface{} {
 
 	transaction := map[string]interface{}{
 		"hash":         tx.Hash.Hex(),
+		"type":         tx.Data.Type,
+		"category":     tx.Category(),
 		"from":         tx.Data.From.Hex(),
 		"to":           toAddr,
 		"amount":       tx.Data.Amount,
@@ -347,6 +435,10 @@ func (api *PublicScdoAPI) AddTx(tx types.Transaction) (bool, error) {
 	var err error
 	if shard != common.LocalShardNumber {
 		if err = tx.ValidateWithoutState(true, false); err == nil {
+			err = api.s.ProtocolBackend().CheckNonceReservation(&tx)
+		}
+
+		if err == nil {
 			api.s.ProtocolBackend().SendDifferentShardTx(&tx, shard)
 		}
 	} else {
@@ -371,6 +463,35 @@ func (api *PublicScdoAPI) GetCode(contractAdd common.Address, height int64) (int
 	return hexutil.BytesToHex(code), nil
 }
 
+// ComputeContractAddress computes the address of the contract deployed by the CREATE2 opcode
+// with the deployer address, salt and the keccak256 hash of the init code. The contract
+// address is in the same shard as the deployer.
+func (api *PublicScdoAPI) ComputeContractAddress(deployer common.Address, salt, codeHash string) (common.Address, error) {
+	if deployer.IsEmpty() {
+		return common.EmptyAddress, ErrInvalidAccount
+	}
+
+	saltBytes, err := hexutil.HexToBytes(salt)
+	if err != nil {
+		return common.EmptyAddress, errors.NewStackedError(err, "failed to convert HEX to salt")
+	}
+
+	if len(saltBytes) > common.HashLength {
+		return common.EmptyAddress, fmt.Errorf("salt exceeds %d bytes", common.HashLength)
+	}
+
+	hash, err := hexutil.HexToBytes(codeHash)
+	if err != nil {
+		return common.EmptyAddress, errors.NewStackedError(err, "failed to convert HEX to code hash")
+	}
+
+	if len(hash) != common.HashLength {
+		return common.EmptyAddress, ErrInvalidCodeHash
+	}
+
+	return crypto.CreateAddress2(deployer, common.BytesToHash(saltBytes), hash), nil
+}
+
 // GetReceiptByTxHash get receipt by transaction hash
 func (api *PublicScdoAPI) GetReceiptByTxHash(txHash, abiJSON string) (map[string]interface{}, error) {
 	hash, err := common.HexToHash(txHash)
@@ -386,6 +507,67 @@ func (api *PublicScdoAPI) GetReceiptByTxHash(txHash, abiJSON string) (map[string
 	return printReceiptByABI(api, receipt, abiJSON)
 }
 
+// GetTransactionByHash returns the transaction and its status by the given transaction hash.
+// It looks up the tx pool first and then the canonical chain, so that a tx reverted by a chain reorg
+// is reported as pending again once reinjected, or dropped otherwise.
+func (api *PublicScdoAPI) GetTransactionByHash(txHash string) (map[string]interface{}, error) {
+	hash, err := common.HexToHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	bcStore := api.s.ChainBackend().GetStore()
+	tx, idx, err := api.s.GetTransaction(api.s.TxPoolBackend(), bcStore, hash)
+	if err != nil {
+		api.s.Log().Debug("Failed to get transaction by hash, %v", err.Error())
+	}
+
+	if tx != nil && idx == nil {
+		return map[string]interface{}{
+			"transaction": PrintableOutputTx(tx),
+			"status":      TxStatusPending,
+		}, nil
+	}
+
+	if tx != nil {
+		// the tx index may be stale if the block is reverted by a chain reorg
+		canonicalHash, err := bcStore.GetBlockHash(idx.BlockHeight)
+		if err == nil && canonicalHash == idx.BlockHash {
+			currentHeight := api.s.ChainBackend().CurrentHeader().Height
+			return map[string]interface{}{
+				"transaction":   PrintableOutputTx(tx),
+				"status":        TxStatusMined,
+				"blockHash":     idx.BlockHash.Hex(),
+				"blockHeight":   idx.BlockHeight,
+				"txIndex":       idx.Index,
+				"confirmations": currentHeight - idx.BlockHeight + 1,
+			}, nil
+		}
+	}
+
+	if dropped, reason := api.s.TxPoolBackend().GetDroppedTransaction(hash); dropped != nil {
+		return map[string]interface{}{
+			"transaction": PrintableOutputTx(dropped),
+			"status":      TxStatusDropped,
+			"reason":      reason,
+		}, nil
+	}
+
+	if tx != nil {
+		return map[string]interface{}{
+			"transaction": PrintableOutputTx(tx),
+			"status":      TxStatusDropped,
+			"reason":      "reverted by chain reorg",
+		}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, ErrTransactionNotFound
+}
+
 // GetTransactionByBlockIndex returns the transaction in the block with the given block hash/height and index.
 func (api *PublicScdoAPI) GetTransactionByBlockIndex(hashHex string, height int64, index uint) (map[string]interface{}, error) {
 	if len(hashHex) > 0 {
@@ -498,8 +680,9 @@ func (api *PublicScdoAPI) GetBlockDebtCountByHash(blockHash string) (int, error)
 	return len(block.Debts), nil
 }
 
-// GetReceiptsByBlockHash get receipts by block hash
-func (api *PublicScdoAPI) GetReceiptsByBlockHash(blockHash string) (map[string]interface{}, error) {
+// GetReceiptsByBlockHash get receipts by block hash. The optional filter returns only the receipts with the logs
+// of the contract addresses and topics, and omits the fields of default values in compact mode.
+func (api *PublicScdoAPI) GetReceiptsByBlockHash(blockHash string, filter *ReceiptFilter) (map[string]interface{}, error) {
 	hash, err := common.HexToHash(blockHash)
 	if err != nil {
 		return nil, err
@@ -510,9 +693,15 @@ func (api *PublicScdoAPI) GetReceiptsByBlockHash(blockHash string) (map[string]i
 		return nil, err
 	}
 
+	receipts = filterReceipts(receipts, filter)
+	printable := PrintableReceipt
+	if filter != nil && filter.Compact {
+		printable = PrintableCompactReceipt
+	}
+
 	outMaps := make([]map[string]interface{}, 0, len(receipts))
 	for _, re := range receipts {
-		outMap, err := PrintableReceipt(re)
+		outMap, err := printable(re)
 		if err != nil {
 			return nil, err
 		}
@@ -641,16 +830,17 @@ func (api *PublicScdoAPI) GetTransactionsToByHeight(account common.Address, heig
 	return result, nil
 }
 
-// GetAccountTransactions get transaction of one account at specific height or blockhash
-func (api *PublicScdoAPI) GetAccountTransactions(account common.Address, blockHash string, height int64) (result []map[string]interface{}, err error) {
+// GetAccountTransactions get transaction of one account at specific height or blockhash,
+// and the debts credited to the account in the block if includeDebts is true
+func (api *PublicScdoAPI) GetAccountTransactions(account common.Address, blockHash string, height int64, includeDebts *bool) (result []map[string]interface{}, err error) {
 	if len(blockHash) > 0 {
-		return api.GetAccountTransactionsByHash(account, blockHash)
+		return api.GetAccountTransactionsByHash(account, blockHash, includeDebts)
 	}
-	return api.GetAccountTransactionsByHeight(account, height)
+	return api.GetAccountTransactionsByHeight(account, height, includeDebts)
 }
 
-// GetAccountTransactionsByHash get transaction of one account at specific height
-func (api *PublicScdoAPI) GetAccountTransactionsByHash(account common.Address, blockHash string) (result []map[string]interface{}, err error) {
+// GetAccountTransactionsByHash get transaction of one account at specific blockhash
+func (api *PublicScdoAPI) GetAccountTransactionsByHash(account common.Address, blockHash string, includeDebts *bool) (result []map[string]interface{}, err error) {
 	hash, err := common.HexToHash(blockHash)
 	if err != nil {
 		return nil, err
@@ -659,34 +849,70 @@ func (api *PublicScdoAPI) GetAccountTransactionsByHash(account common.Address, b
 	if err != nil {
 		return nil, err
 	}
-	txs := block.Transactions
-	for i, tx := range txs {
-		if tx.FromAccount() == account || tx.ToAccount() == account {
-			output := map[string]interface{}{
-				"transaction" + fmt.Sprintf(" %d", i): PrintableOutputTx(tx),
-			}
-			result = append(result, output)
-		}
-	}
-	return result, nil
+	return accountTransactions(block, account, includeDebts != nil && *includeDebts), nil
 }
 
-// GetAccountTransactionsByHeight get transaction of one account at specific blockhash
-func (api *PublicScdoAPI) GetAccountTransactionsByHeight(account common.Address, height int64) (result []map[string]interface{}, err error) {
+// GetAccountTransactionsByHeight get transaction of one account at specific height
+func (api *PublicScdoAPI) GetAccountTransactionsByHeight(account common.Address, height int64, includeDebts *bool) (result []map[string]interface{}, err error) {
 	block, err := api.s.GetBlock(common.EmptyHash, height)
 	if err != nil {
 		return nil, err
 	}
-	txs := block.Transactions
-	for i, tx := range txs {
-		if tx.FromAccount() == account || tx.ToAccount() == account {
-			output := map[string]interface{}{
+	return accountTransactions(block, account, includeDebts != nil && *includeDebts), nil
+}
+
+// accountTransactions returns the txs from or to the account in the block. If includeDebts is true, the debts
+// credited to the account are included, and all entries are in the same schema with the type tx or debt.
+func accountTransactions(block *types.Block, account common.Address, includeDebts bool) (result []map[string]interface{}) {
+	for i, tx := range block.Transactions {
+		if tx.FromAccount() != account && tx.ToAccount() != account {
+			continue
+		}
+
+		if !includeDebts {
+			result = append(result, map[string]interface{}{
 				"transaction" + fmt.Sprintf(" %d", i): PrintableOutputTx(tx),
-			}
-			result = append(result, output)
+			})
+			continue
+		}
+
+		to := ""
+		if !tx.Data.To.IsEmpty() {
+			to = tx.Data.To.Hex()
 		}
+
+		result = append(result, map[string]interface{}{
+			"type":        "tx",
+			"index":       i,
+			"hash":        tx.Hash.Hex(),
+			"from":        tx.Data.From.Hex(),
+			"to":          to,
+			"amount":      tx.Data.Amount,
+			"transaction": PrintableOutputTx(tx),
+		})
 	}
-	return result, nil
+
+	if !includeDebts {
+		return result
+	}
+
+	for i, debt := range block.Debts {
+		if debt.Data.Account != account {
+			continue
+		}
+
+		result = append(result, map[string]interface{}{
+			"type":   "debt",
+			"index":  i,
+			"hash":   debt.Hash.Hex(),
+			"from":   debt.Data.From.Hex(),
+			"to":     debt.Data.Account.Hex(),
+			"amount": debt.Data.Amount,
+			"debt":   debt,
+		})
+	}
+
+	return result
 }
 
 // GetBlockTransactions get all txs in the block with height or blockhash
@@ -0,0 +1,64 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AccountTransactions(t *testing.T) {
+	account, other := *crypto.MustGenerateShardAddress(1), *crypto.MustGenerateShardAddress(1)
+
+	txs := []*types.Transaction{
+		newTestPoolTx(other, other, 1, 1),
+		newTestPoolTx(account, other, 1, 1),
+		newTestPoolTx(other, account, 2, 1),
+	}
+	for _, tx := range txs {
+		tx.Data.Amount = big.NewInt(10)
+		tx.Hash = crypto.MustHash(tx.Data)
+	}
+
+	debts := []*types.Debt{
+		{Hash: common.StringToHash("debt1"), Data: types.DebtData{From: *crypto.MustGenerateShardAddress(2), Account: other, Amount: big.NewInt(1)}},
+		{Hash: common.StringToHash("debt2"), Data: types.DebtData{From: *crypto.MustGenerateShardAddress(2), Account: account, Amount: big.NewInt(2)}},
+	}
+
+	block := &types.Block{Header: &types.BlockHeader{}, Transactions: txs, Debts: debts}
+
+	// the txs only in the original schema
+	result := accountTransactions(block, account, false)
+	assert.Equal(t, len(result), 2)
+	assert.Equal(t, result[0]["transaction 1"], PrintableOutputTx(txs[1]))
+	assert.Equal(t, result[1]["transaction 2"], PrintableOutputTx(txs[2]))
+
+	// the txs and the debts credited to the account
+	result = accountTransactions(block, account, true)
+	assert.Equal(t, len(result), 3)
+	assert.Equal(t, result[0]["type"], "tx")
+	assert.Equal(t, result[0]["index"], 1)
+	assert.Equal(t, result[0]["hash"], txs[1].Hash.Hex())
+	assert.Equal(t, result[0]["to"], other.Hex())
+	assert.Equal(t, result[1]["type"], "tx")
+	assert.Equal(t, result[1]["amount"], big.NewInt(10))
+
+	assert.Equal(t, result[2]["type"], "debt")
+	assert.Equal(t, result[2]["index"], 1)
+	assert.Equal(t, result[2]["hash"], debts[1].Hash.Hex())
+	assert.Equal(t, result[2]["from"], debts[1].Data.From.Hex())
+	assert.Equal(t, result[2]["to"], account.Hex())
+	assert.Equal(t, result[2]["amount"], big.NewInt(2))
+	assert.Equal(t, result[2]["debt"], debts[1])
+
+	// no entries of the account
+	assert.Equal(t, len(accountTransactions(block, *crypto.MustGenerateShardAddress(1), true)), 0)
+}
@@ -0,0 +1,127 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// ReceiptFilter filters the receipts of a block by the logs, so that the explorers only download the
+// receipts of the interested contracts and events.
+type ReceiptFilter struct {
+	// Addresses are the contract addresses of the logs, any address if empty
+	Addresses []common.Address `json:"addresses"`
+
+	// Topics are the topics of the logs by position, any topic at the position if empty,
+	// e.g. [[A], [], [B, C]] matches the logs of topic A at position 0 and topic B or C at position 2
+	Topics [][]common.Hash `json:"topics"`
+
+	// Compact omits the fields of the receipts with default values and the post state
+	Compact bool `json:"compact"`
+}
+
+// filtersLogs returns whether the receipts are filtered by the logs
+func (f *ReceiptFilter) filtersLogs() bool {
+	return f != nil && (len(f.Addresses) > 0 || len(f.Topics) > 0)
+}
+
+// matchLog returns whether the log matches the addresses and the topics of the filter
+func (f *ReceiptFilter) matchLog(log *types.Log) bool {
+	if len(f.Addresses) > 0 && !containsAddress(f.Addresses, log.Address) {
+		return false
+	}
+
+	for i, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+
+		if i >= len(log.Topics) || !containsHash(topics, log.Topics[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterReceipts returns the receipts with the logs matching the filter, and only the matched logs are
+// kept in the returned receipts. All the receipts are returned if no address or topic is filtered.
+func filterReceipts(receipts []*types.Receipt, filter *ReceiptFilter) []*types.Receipt {
+	if !filter.filtersLogs() {
+		return receipts
+	}
+
+	filtered := make([]*types.Receipt, 0)
+	for _, re := range receipts {
+		var logs []*types.Log
+		for _, log := range re.Logs {
+			if filter.matchLog(log) {
+				logs = append(logs, log)
+			}
+		}
+
+		if len(logs) > 0 {
+			copied := *re
+			copied.Logs = logs
+			filtered = append(filtered, &copied)
+		}
+	}
+
+	return filtered
+}
+
+// PrintableCompactReceipt returns the printable receipt without the fields of default values and the post state
+func PrintableCompactReceipt(re *types.Receipt) (map[string]interface{}, error) {
+	outMap := map[string]interface{}{
+		"txhash":   re.TxHash.Hex(),
+		"usedGas":  re.UsedGas,
+		"totalFee": re.TotalFee,
+	}
+
+	if re.Failed {
+		outMap["failed"] = true
+		outMap["result"] = string(re.Result)
+	} else if len(re.Result) > 0 {
+		outMap["result"] = hexutil.BytesToHex(re.Result)
+	}
+
+	if len(re.ContractAddress) > 0 {
+		contractAddr, err := common.NewAddress(re.ContractAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		outMap["contract"] = contractAddr.Hex()
+	}
+
+	if len(re.Logs) > 0 {
+		outMap["logs"] = re.Logs
+	}
+
+	return outMap, nil
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FilterReceipts(t *testing.T) {
+	contract1, contract2 := *crypto.MustGenerateShardAddress(1), *crypto.MustGenerateShardAddress(1)
+	transfer, approval := common.StringToHash("Transfer"), common.StringToHash("Approval")
+	owner := common.StringToHash("owner")
+
+	log1 := &types.Log{Address: contract1, Topics: []common.Hash{transfer, owner}}
+	log2 := &types.Log{Address: contract1, Topics: []common.Hash{approval}}
+	log3 := &types.Log{Address: contract2, Topics: []common.Hash{transfer}}
+
+	receipts := []*types.Receipt{
+		{TxHash: common.StringToHash("tx1"), Logs: []*types.Log{log1, log2}},
+		{TxHash: common.StringToHash("tx2"), Logs: []*types.Log{log3}},
+		{TxHash: common.StringToHash("tx3")},
+	}
+
+	// no filter
+	assert.Equal(t, filterReceipts(receipts, nil), receipts)
+	assert.Equal(t, filterReceipts(receipts, &ReceiptFilter{Compact: true}), receipts)
+
+	// by address
+	filtered := filterReceipts(receipts, &ReceiptFilter{Addresses: []common.Address{contract2}})
+	assert.Equal(t, len(filtered), 1)
+	assert.Equal(t, filtered[0].Logs, []*types.Log{log3})
+
+	// by topic, only the matched logs are kept
+	filtered = filterReceipts(receipts, &ReceiptFilter{Topics: [][]common.Hash{{transfer}}})
+	assert.Equal(t, len(filtered), 2)
+	assert.Equal(t, filtered[0].Logs, []*types.Log{log1})
+	assert.Equal(t, filtered[1].Logs, []*types.Log{log3})
+	assert.Equal(t, len(receipts[0].Logs), 2)
+
+	// by address and topic of the position
+	filtered = filterReceipts(receipts, &ReceiptFilter{
+		Addresses: []common.Address{contract1, contract2},
+		Topics:    [][]common.Hash{{}, {owner}},
+	})
+	assert.Equal(t, len(filtered), 1)
+	assert.Equal(t, filtered[0].TxHash, receipts[0].TxHash)
+	assert.Equal(t, filtered[0].Logs, []*types.Log{log1})
+}
+
+func Test_PrintableCompactReceipt(t *testing.T) {
+	re := &types.Receipt{TxHash: common.StringToHash("tx"), UsedGas: 21000, TotalFee: 210000}
+
+	out, err := PrintableCompactReceipt(re)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, out, map[string]interface{}{
+		"txhash":   re.TxHash.Hex(),
+		"usedGas":  uint64(21000),
+		"totalFee": uint64(210000),
+	})
+
+	re.Failed, re.Result = true, []byte("out of gas")
+	out, err = PrintableCompactReceipt(re)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, out["failed"], true)
+	assert.Equal(t, out["result"], "out of gas")
+}
@@ -0,0 +1,78 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus"
+)
+
+// RewardSchedule is the block reward emission schedule
+type RewardSchedule struct {
+	ShardCount int
+	Eras       []*consensus.RewardEra // the block reward is 0 after the last era
+	MaxSupply  *big.Int               // total reward of all shards in the schedule
+}
+
+// BlockReward is the block reward and the projected supply at a height
+type BlockReward struct {
+	Height         uint64
+	Era            int
+	EraStartHeight uint64
+	EraEndHeight   uint64
+	Reward         *big.Int // reward of the block of a shard
+	ShardSupply    *big.Int // projected total reward of a shard up to the height
+	TotalSupply    *big.Int // projected total reward of all shards up to the height
+}
+
+// CirculatingSupply is the circulating supply of the local shard at the HEAD block
+type CirculatingSupply struct {
+	Height            uint64
+	Shard             uint
+	GenesisAllocation *big.Int // balance allocated in the genesis block, including the rewards mined before fork
+	MinedReward       *big.Int // rewards of the blocks after the genesis block
+	CirculatingSupply *big.Int
+}
+
+// GetRewardSchedule returns the block reward of each era and the max supply of the schedule
+func (api *PublicScdoAPI) GetRewardSchedule() (*RewardSchedule, error) {
+	eras := consensus.GetRewardSchedule()
+
+	return &RewardSchedule{
+		ShardCount: common.ShardCount,
+		Eras:       eras,
+		MaxSupply:  allShardsSupply(consensus.GetTotalReward(eras[len(eras)-1].EndHeight)),
+	}, nil
+}
+
+// GetBlockReward returns the block reward, reward era and projected supply at the height,
+// or at the HEAD block if height is less than 0.
+func (api *PublicScdoAPI) GetBlockReward(height int64) (*BlockReward, error) {
+	h := uint64(height)
+	if height < 0 {
+		h = api.s.ChainBackend().CurrentHeader().Height
+	}
+
+	era := consensus.GetRewardEra(h)
+	supply := consensus.GetTotalReward(h)
+
+	return &BlockReward{
+		Height:         h,
+		Era:            era.Era,
+		EraStartHeight: era.StartHeight,
+		EraEndHeight:   era.EndHeight,
+		Reward:         era.Reward,
+		ShardSupply:    supply,
+		TotalSupply:    allShardsSupply(supply),
+	}, nil
+}
+
+// allShardsSupply returns the supply of all shards with the same supply of each shard
+func allShardsSupply(shardSupply *big.Int) *big.Int {
+	return new(big.Int).Mul(shardSupply, big.NewInt(common.ShardCount))
+}
@@ -0,0 +1,94 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+const (
+	// defaultSignalTallyBlocks is the default number of recent blocks to tally the signaling
+	defaultSignalTallyBlocks = uint64(1000)
+
+	// maxSignalTallyBlocks is the max number of recent blocks to tally the signaling
+	maxSignalTallyBlocks = uint64(20000)
+)
+
+// SignalTally is the tally of the governance signaling in the block header extra data
+type SignalTally struct {
+	From    uint64            // height of the first tallied block
+	To      uint64            // height of the last tallied block
+	Blocks  uint64            // number of tallied blocks
+	Signals map[string]uint64 // hex encoded extra data -> number of blocks
+	Bits    map[int]uint64    // bit index in the extra data bitfield -> number of blocks which set it
+}
+
+func newSignalTally() *SignalTally {
+	return &SignalTally{
+		Signals: make(map[string]uint64),
+		Bits:    make(map[int]uint64),
+	}
+}
+
+// add tallies the signaling of the block header
+func (tally *SignalTally) add(header *types.BlockHeader) {
+	tally.Blocks++
+
+	extra := header.SignalingExtra()
+	if len(extra) == 0 {
+		return
+	}
+
+	tally.Signals[hexutil.BytesToHex(extra)]++
+
+	// bit i is the (i % 8) lowest bit of the (i / 8) byte
+	for i, b := range extra {
+		for j := 0; j < 8; j++ {
+			if b&(1<<uint(j)) != 0 {
+				tally.Bits[i*8+j]++
+			}
+		}
+	}
+}
+
+// GetSignalTally tallies the governance signaling in the extra data of the recent blocks,
+// 0 means the default 1000 blocks.
+func (api *PublicScdoAPI) GetSignalTally(blocks uint64) (*SignalTally, error) {
+	if blocks == 0 {
+		blocks = defaultSignalTallyBlocks
+	}
+
+	if blocks > maxSignalTallyBlocks {
+		blocks = maxSignalTallyBlocks
+	}
+
+	head := api.s.ChainBackend().CurrentHeader()
+	if blocks > head.Height+1 {
+		blocks = head.Height + 1
+	}
+
+	tally := newSignalTally()
+	tally.From, tally.To = head.Height+1-blocks, head.Height
+
+	bcStore := api.s.ChainBackend().GetStore()
+	for header := head; ; {
+		tally.add(header)
+		if header.Height == tally.From {
+			break
+		}
+
+		parent, err := bcStore.GetBlockHeader(header.PreviousBlockHash)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get block header at height %d", header.Height-1)
+		}
+
+		header = parent
+	}
+
+	return tally, nil
+}
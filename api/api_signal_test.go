@@ -0,0 +1,30 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SignalTally(t *testing.T) {
+	tally := newSignalTally()
+
+	tally.add(&types.BlockHeader{})
+	tally.add(&types.BlockHeader{ExtraData: []byte{0x01, 0x80}})
+	tally.add(&types.BlockHeader{ExtraData: []byte{0x01}})
+
+	// istanbul extra vanity with zero padding
+	vanity := append([]byte{0x01}, bytes.Repeat([]byte{0x00}, types.IstanbulExtraVanity-1)...)
+	tally.add(&types.BlockHeader{Consensus: types.IstanbulConsensus, ExtraData: append(vanity, 0xc0)})
+
+	assert.Equal(t, tally.Blocks, uint64(4))
+	assert.Equal(t, tally.Signals, map[string]uint64{"0x0180": 1, "0x01": 2})
+	assert.Equal(t, tally.Bits, map[int]uint64{0: 3, 15: 1})
+}
@@ -6,7 +6,13 @@
 package api
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/hexutil"
@@ -19,6 +25,13 @@ import (
 var (
 	ErrTransactionNotFound = errors.New("transaction not found")
 	ErrDebtNotFound        = errors.New("debt not found")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrInvalidGasPrice     = errors.New("invalid gas price")
+)
+
+const (
+	defaultTxPoolPageLimit = 100
+	maxTxPoolPageLimit     = 1000
 )
 
 // TransactionPoolAPI provides an API to access transaction pool information.
@@ -108,6 +121,9 @@ func (api *TransactionPoolAPI) GetTransactionByHash(txHash string) (map[string]i
 
 	if idx == nil {
 		output["status"] = "pool"
+		if queued, ok := api.s.TxPoolBackend().(queuedPool); ok && queued.IsQueuedTransaction(hash) {
+			output["status"] = "queued"
+		}
 	} else {
 		output["status"] = "block"
 
@@ -183,8 +199,7 @@ func GetDebt(pool *core.DebtPool, bcStore store.BlockchainStore, debtHash common
 
 // GetTxPoolContent returns the transactions contained within the transaction pool
 func (api *TransactionPoolAPI) GetTxPoolContent() (map[string][]map[string]interface{}, error) {
-	txPool := api.s.TxPoolBackend()
-	data := txPool.GetTransactions(true, true)
+	data := getPoolTransactions(api.s.TxPoolBackend())
 
 	content := make(map[string][]map[string]interface{})
 	for _, tx := range data {
@@ -211,3 +226,276 @@ func (api *TransactionPoolAPI) GetPendingTransactions() ([]map[string]interface{
 
 	return transactions, nil
 }
+
+// queuedPool is the pool which queues the transactions with nonce gaps until the gaps are filled
+type queuedPool interface {
+	GetQueuedTransactions() []*types.Transaction
+	IsQueuedTransaction(txHash common.Hash) bool
+}
+
+// getPoolTransactions returns the processing and pending transactions in the pool, together with the queued
+// ones if the pool queues the transactions with nonce gaps.
+func getPoolTransactions(txPool Pool) []*types.Transaction {
+	txs := txPool.GetTransactions(true, true)
+	if queued, ok := txPool.(queuedPool); ok {
+		txs = append(txs, queued.GetQueuedTransactions()...)
+	}
+
+	return txs
+}
+
+// TxPoolStatus is the number of the transactions in the pool by status
+type TxPoolStatus struct {
+	Pending int `json:"pending"` // processable transactions, including the ones being packed
+	Queued  int `json:"queued"`  // transactions waiting for the nonce gaps to be filled
+}
+
+// GetTxPoolStatus returns the number of the pending and queued transactions in the pool
+func (api *TransactionPoolAPI) GetTxPoolStatus() (*TxPoolStatus, error) {
+	txPool := api.s.TxPoolBackend()
+	status := &TxPoolStatus{Pending: txPool.GetTxCount()}
+	if queued, ok := txPool.(queuedPool); ok {
+		status.Queued = len(queued.GetQueuedTransactions())
+	}
+
+	return status, nil
+}
+
+// GetQueuedTransactions returns the transactions with nonce gaps in the pool, which are not processable
+// until the transactions of the missing nonces are added.
+func (api *TransactionPoolAPI) GetQueuedTransactions() ([]map[string]interface{}, error) {
+	transactions := make([]map[string]interface{}, 0)
+	if queued, ok := api.s.TxPoolBackend().(queuedPool); ok {
+		for _, tx := range queued.GetQueuedTransactions() {
+			transactions = append(transactions, PrintableOutputTx(tx))
+		}
+	}
+
+	return transactions, nil
+}
+
+// TxPoolPage is a page of the transactions in the pool, sorted by sender and nonce
+type TxPoolPage struct {
+	Transactions []map[string]interface{} `json:"transactions"`
+	Total        int                      `json:"total"`      // number of transactions matching the filter
+	NextCursor   string                   `json:"nextCursor"` // cursor of the next page, empty if no more transactions
+}
+
+// PriceBucket is the number of transactions with gas price in [Min, Max)
+type PriceBucket struct {
+	Min   *big.Int `json:"min"`
+	Max   *big.Int `json:"max"`
+	Count int      `json:"count"`
+}
+
+// TxPoolSummary is the summary of the transactions in the pool
+type TxPoolSummary struct {
+	Total          int            `json:"total"`
+	Shards         map[uint]int   `json:"shards"`         // number of transactions per sender shard
+	PriceHistogram []*PriceBucket `json:"priceHistogram"` // number of transactions per order of magnitude of gas price
+}
+
+// txPoolFilter filters the transactions in the pool, the zero fields are not filtered
+type txPoolFilter struct {
+	account  common.Address // sender or receiver
+	shard    uint           // shard of the sender
+	minPrice *big.Int
+	maxPrice *big.Int
+}
+
+func newTxPoolFilter(account common.Address, shard uint, minPrice, maxPrice string) (*txPoolFilter, error) {
+	filter := &txPoolFilter{account: account, shard: shard}
+
+	var err error
+	if filter.minPrice, err = parseGasPrice(minPrice); err != nil {
+		return nil, err
+	}
+
+	if filter.maxPrice, err = parseGasPrice(maxPrice); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// parseGasPrice parses the gas price in decimal, returns nil for empty string
+func parseGasPrice(price string) (*big.Int, error) {
+	if len(price) == 0 {
+		return nil, nil
+	}
+
+	value, ok := new(big.Int).SetString(price, 10)
+	if !ok || value.Sign() < 0 {
+		return nil, ErrInvalidGasPrice
+	}
+
+	return value, nil
+}
+
+func (f *txPoolFilter) match(tx *types.Transaction) bool {
+	if !f.account.IsEmpty() && !f.account.Equal(tx.Data.From) && !f.account.Equal(tx.Data.To) {
+		return false
+	}
+
+	if f.shard != 0 && tx.Data.From.Shard() != f.shard {
+		return false
+	}
+
+	if f.minPrice != nil && tx.Data.GasPrice.Cmp(f.minPrice) < 0 {
+		return false
+	}
+
+	return f.maxPrice == nil || tx.Data.GasPrice.Cmp(f.maxPrice) <= 0
+}
+
+// txPoolCursor is the position of a transaction in the pool sorted by sender and nonce
+type txPoolCursor struct {
+	from  common.Address
+	nonce uint64
+}
+
+func newTxPoolCursor(tx *types.Transaction) txPoolCursor {
+	return txPoolCursor{tx.Data.From, tx.Data.AccountNonce}
+}
+
+func parseTxPoolCursor(cursor string) (*txPoolCursor, error) {
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	from, err := common.HexToAddress(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	nonce, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &txPoolCursor{from, nonce}, nil
+}
+
+func (c txPoolCursor) String() string {
+	return fmt.Sprintf("%s:%d", c.from.Hex(), c.nonce)
+}
+
+func (c txPoolCursor) less(other txPoolCursor) bool {
+	if cmp := bytes.Compare(c.from.Bytes(), other.from.Bytes()); cmp != 0 {
+		return cmp < 0
+	}
+
+	return c.nonce < other.nonce
+}
+
+// pageTxs returns the page of the filtered txs after the cursor. The txs are sorted by sender and nonce,
+// so that the cursor is still valid when the txs before it are removed from the pool.
+func pageTxs(txs []*types.Transaction, filter *txPoolFilter, cursor *txPoolCursor, limit int) *TxPoolPage {
+	matched := make([]*types.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if filter.match(tx) {
+			matched = append(matched, tx)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return newTxPoolCursor(matched[i]).less(newTxPoolCursor(matched[j]))
+	})
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return cursor.less(newTxPoolCursor(matched[i]))
+		})
+	}
+
+	end := start + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	page := &TxPoolPage{
+		Transactions: make([]map[string]interface{}, 0, end-start),
+		Total:        len(matched),
+	}
+
+	for _, tx := range matched[start:end] {
+		page.Transactions = append(page.Transactions, PrintableOutputTx(tx))
+	}
+
+	if end < len(matched) {
+		page.NextCursor = newTxPoolCursor(matched[end-1]).String()
+	}
+
+	return page
+}
+
+// GetTxPoolContentPage returns a page of the transactions in the pool after the cursor, which are
+// filtered by the sender or receiver account, the sender shard and the gas price range (inclusive).
+// The empty account, zero shard and empty gas prices are not filtered, and the empty cursor is the first page.
+func (api *TransactionPoolAPI) GetTxPoolContentPage(cursor string, limit uint, account common.Address, shard uint, minPrice, maxPrice string) (*TxPoolPage, error) {
+	start, err := parseTxPoolCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newTxPoolFilter(account, shard, minPrice, maxPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit == 0 {
+		limit = defaultTxPoolPageLimit
+	} else if limit > maxTxPoolPageLimit {
+		limit = maxTxPoolPageLimit
+	}
+
+	txs := getPoolTransactions(api.s.TxPoolBackend())
+	return pageTxs(txs, filter, start, int(limit)), nil
+}
+
+// GetTxPoolSummary returns the number of transactions in the pool per shard and per gas price range
+func (api *TransactionPoolAPI) GetTxPoolSummary() (*TxPoolSummary, error) {
+	txs := getPoolTransactions(api.s.TxPoolBackend())
+	return summarizeTxs(txs), nil
+}
+
+// summarizeTxs counts the txs per sender shard, and per order of magnitude of gas price, e.g. [10, 100)
+func summarizeTxs(txs []*types.Transaction) *TxPoolSummary {
+	summary := &TxPoolSummary{
+		Total:          len(txs),
+		Shards:         make(map[uint]int),
+		PriceHistogram: make([]*PriceBucket, 0),
+	}
+
+	buckets := make(map[int]int)
+	for _, tx := range txs {
+		summary.Shards[tx.Data.From.Shard()]++
+
+		// the gas price of tx in pool is always positive
+		buckets[len(tx.Data.GasPrice.String())]++
+	}
+
+	digits := make([]int, 0, len(buckets))
+	for d := range buckets {
+		digits = append(digits, d)
+	}
+	sort.Ints(digits)
+
+	ten := big.NewInt(10)
+	for _, d := range digits {
+		min := new(big.Int).Exp(ten, big.NewInt(int64(d-1)), nil)
+		summary.PriceHistogram = append(summary.PriceHistogram, &PriceBucket{
+			Min:   min,
+			Max:   new(big.Int).Mul(min, ten),
+			Count: buckets[d],
+		})
+	}
+
+	return summary
+}
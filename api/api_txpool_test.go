@@ -0,0 +1,132 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package api
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPoolTx(from, to common.Address, nonce uint64, price int64) *types.Transaction {
+	return &types.Transaction{
+		Data: types.TransactionData{
+			From:         from,
+			To:           to,
+			AccountNonce: nonce,
+			GasPrice:     big.NewInt(price),
+		},
+	}
+}
+
+func Test_PageTxs(t *testing.T) {
+	from1, from2, to := *crypto.MustGenerateShardAddress(1), *crypto.MustGenerateShardAddress(2), *crypto.MustGenerateShardAddress(1)
+	txs := []*types.Transaction{
+		newTestPoolTx(from1, to, 2, 10),
+		newTestPoolTx(from2, to, 1, 100),
+		newTestPoolTx(from1, to, 1, 20),
+		newTestPoolTx(from2, from1, 2, 5),
+	}
+
+	filter, err := newTxPoolFilter(common.EmptyAddress, 0, "", "")
+	assert.Equal(t, err, nil)
+
+	// iterate all pages by cursor
+	var nonces []uint64
+	var cursor *txPoolCursor
+	for pages := 0; ; pages++ {
+		page := pageTxs(txs, filter, cursor, 3)
+		assert.Equal(t, page.Total, 4)
+		for _, tx := range page.Transactions {
+			nonces = append(nonces, tx["accountNonce"].(uint64))
+		}
+
+		if len(page.NextCursor) == 0 {
+			assert.Equal(t, pages, 1)
+			break
+		}
+
+		cursor, err = parseTxPoolCursor(page.NextCursor)
+		assert.Equal(t, err, nil)
+	}
+	assert.Equal(t, len(nonces), 4)
+
+	// the cursor is still valid after the tx in the previous page is removed
+	page := pageTxs(txs, filter, nil, 1)
+	cursor, _ = parseTxPoolCursor(page.NextCursor)
+	var remaining []*types.Transaction
+	for _, tx := range txs {
+		if newTxPoolCursor(tx) != *cursor {
+			remaining = append(remaining, tx)
+		}
+	}
+	assert.Equal(t, len(pageTxs(remaining, filter, cursor, 10).Transactions), 3)
+
+	// filter by account, shard and gas price
+	filter, _ = newTxPoolFilter(from1, 0, "", "")
+	assert.Equal(t, pageTxs(txs, filter, nil, 10).Total, 3)
+
+	filter, _ = newTxPoolFilter(common.EmptyAddress, 2, "", "")
+	assert.Equal(t, pageTxs(txs, filter, nil, 10).Total, 2)
+
+	filter, _ = newTxPoolFilter(common.EmptyAddress, 0, "10", "20")
+	assert.Equal(t, pageTxs(txs, filter, nil, 10).Total, 2)
+
+	_, err = newTxPoolFilter(common.EmptyAddress, 0, "abc", "")
+	assert.Equal(t, err, ErrInvalidGasPrice)
+
+	_, err = parseTxPoolCursor("abc")
+	assert.Equal(t, err, ErrInvalidCursor)
+}
+
+func Test_SummarizeTxs(t *testing.T) {
+	from1, from2 := *crypto.MustGenerateShardAddress(1), *crypto.MustGenerateShardAddress(2)
+	txs := []*types.Transaction{
+		newTestPoolTx(from1, from2, 1, 1),
+		newTestPoolTx(from1, from2, 2, 9),
+		newTestPoolTx(from2, from1, 1, 10),
+		newTestPoolTx(from2, from1, 2, 150),
+	}
+
+	summary := summarizeTxs(txs)
+	assert.Equal(t, summary.Total, 4)
+	assert.Equal(t, summary.Shards, map[uint]int{1: 2, 2: 2})
+	assert.Equal(t, summary.PriceHistogram, []*PriceBucket{
+		{Min: big.NewInt(1), Max: big.NewInt(10), Count: 2},
+		{Min: big.NewInt(10), Max: big.NewInt(100), Count: 1},
+		{Min: big.NewInt(100), Max: big.NewInt(1000), Count: 1},
+	})
+}
+
+type testQueuedPool struct {
+	Pool
+	pending, queued []*types.Transaction
+}
+
+func (pool *testQueuedPool) GetTransactions(processing, pending bool) []*types.Transaction {
+	return pool.pending
+}
+
+func (pool *testQueuedPool) GetQueuedTransactions() []*types.Transaction { return pool.queued }
+func (pool *testQueuedPool) IsQueuedTransaction(txHash common.Hash) bool { return false }
+
+func Test_GetPoolTransactions(t *testing.T) {
+	from, to := *crypto.MustGenerateShardAddress(1), *crypto.MustGenerateShardAddress(1)
+	pool := &testQueuedPool{
+		pending: []*types.Transaction{newTestPoolTx(from, to, 1, 10)},
+		queued:  []*types.Transaction{newTestPoolTx(from, to, 3, 10)},
+	}
+
+	// the queued txs with nonce gaps are in the pool content
+	txs := getPoolTransactions(pool)
+	assert.Equal(t, len(txs), 2)
+	assert.Equal(t, txs[1].Data.AccountNonce, uint64(3))
+	assert.Equal(t, summarizeTxs(txs).Total, 2)
+}
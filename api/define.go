@@ -106,6 +106,7 @@ type Pool interface {
 	PoolCore
 	GetTransactions(processing, pending bool) []*types.Transaction
 	GetTxCount() int
+	GetDroppedTransaction(txHash common.Hash) (*types.Transaction, string)
 }
 
 type Chain interface {
@@ -117,5 +118,6 @@ type Chain interface {
 
 type Protocol interface {
 	SendDifferentShardTx(tx *types.Transaction, shard uint)
+	CheckNonceReservation(tx *types.Transaction) error
 	GetProtocolVersion() (uint, error)
 }
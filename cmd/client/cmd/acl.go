@@ -0,0 +1,100 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/contract/system"
+	"github.com/scdoproject/go-scdo/rpc"
+)
+
+var (
+	accessActions = map[string]byte{
+		"deploy": system.AccessDeploy,
+		"call":   system.AccessCall,
+	}
+
+	accessPermissions = map[string]byte{
+		"unset": system.AccessUnset,
+		"allow": system.AccessAllow,
+		"deny":  system.AccessDeny,
+	}
+)
+
+// newAccessRule creates the access rule from the flags
+func newAccessRule() (*system.AccessRule, error) {
+	action, ok := accessActions[accessActionValue]
+	if !ok {
+		return nil, fmt.Errorf("invalid action %q, it should be deploy or call", accessActionValue)
+	}
+
+	rule := &system.AccessRule{Action: action}
+
+	var err error
+	if len(contractValue) > 0 {
+		if rule.Target, err = common.HexToAddress(contractValue); err != nil {
+			return nil, fmt.Errorf("invalid contract address: %s", err)
+		}
+	}
+
+	if len(accountValue) > 0 {
+		if rule.Account, err = common.HexToAddress(accountValue); err != nil {
+			return nil, fmt.Errorf("invalid account address: %s", err)
+		}
+	}
+
+	return rule, nil
+}
+
+// voteAccessRule votes for an access rule by the validator
+func voteAccessRule(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+	rule, err := newAccessRule()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permission, ok := accessPermissions[permissionValue]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid permission %q, it should be allow, deny or unset", permissionValue)
+	}
+	rule.Permission = permission
+
+	if err = rule.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := sendSystemContractTx(client, system.AccessControlContractAddress, system.CmdVoteAccessRule, common.SerializePanic(rule))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output := make(map[string]interface{})
+	output["Tx"] = *tx
+	output["rule"] = rule
+	return output, tx, err
+}
+
+// getAccessRule gets the permission of an access rule
+func getAccessRule(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+	rule, err := newAccessRule()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := sendSystemContractTx(client, system.AccessControlContractAddress, system.CmdGetAccessRule, common.SerializePanic(rule))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output := make(map[string]interface{})
+	output["Tx"] = *tx
+	output["rule"] = rule
+	return output, tx, err
+}
@@ -0,0 +1,263 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/howeyc/gopass"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/keystore"
+	"github.com/urfave/cli"
+)
+
+// aliasNameRegexp matches the alias names, which start with a letter so that they never look like an address
+var aliasNameRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]{0,63}$`)
+
+// addressBook is the local address book of alias names, which is encrypted with a password in file
+type addressBook struct {
+	path    string
+	Aliases map[string]common.Address `json:"aliases"`
+}
+
+// defaultAddressBookFile returns the default address book file in the scdo data folder
+func defaultAddressBookFile() string {
+	return filepath.Join(common.GetDefaultDataFolder(), "addressbook.json")
+}
+
+// loadAddressBook loads and decrypts the address book file, or returns an empty book if the file not exists
+func loadAddressBook(path, pass string) (*addressBook, error) {
+	book := &addressBook{path: path, Aliases: make(map[string]common.Address)}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return book, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address book: %s", err)
+	}
+
+	data, err := keystore.DecryptData(content, pass)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt address book: %s", err)
+	}
+
+	if err = json.Unmarshal(data, book); err != nil {
+		return nil, fmt.Errorf("invalid address book: %s", err)
+	}
+
+	if book.Aliases == nil {
+		book.Aliases = make(map[string]common.Address)
+	}
+
+	return book, nil
+}
+
+// save encrypts the address book with the password and writes it to file
+func (b *addressBook) save(pass string) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	content, err := keystore.EncryptData(data, pass)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt address book: %s", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(b.path), 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(b.path, content, 0600)
+}
+
+// add adds the alias of the address, the existing alias must be removed first
+func (b *addressBook) add(name string, address common.Address) error {
+	if !aliasNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid alias name %q, it should start with a letter and contain letters, digits, '_', '-' or '.' only", name)
+	}
+
+	if existing, ok := b.Aliases[name]; ok {
+		return fmt.Errorf("alias %q already exists for address %s, remove it first", name, existing.Hex())
+	}
+
+	b.Aliases[name] = address
+
+	return nil
+}
+
+// remove removes the alias
+func (b *addressBook) remove(name string) error {
+	if _, ok := b.Aliases[name]; !ok {
+		return fmt.Errorf("alias %q not found", name)
+	}
+
+	delete(b.Aliases, name)
+
+	return nil
+}
+
+// names returns the sorted alias names
+func (b *addressBook) names() []string {
+	names := make([]string, 0, len(b.Aliases))
+	for name := range b.Aliases {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// getAddressBookPassword asks the password of the address book, twice if the book is to be created
+func getAddressBookPassword(path string) (string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Printf("Creating address book %s, please set the password\n", path)
+		return common.SetPassword()
+	}
+
+	fmt.Printf("Please input your address book password: ")
+	pass, err := gopass.GetPasswd()
+	if err != nil {
+		return "", err
+	}
+
+	return string(pass), nil
+}
+
+func openAddressBook() (*addressBook, string, error) {
+	pass, err := getAddressBookPassword(aliasFileValue)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get password %s", err)
+	}
+
+	book, err := loadAddressBook(aliasFileValue, pass)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return book, pass, nil
+}
+
+// AliasAddAction adds the alias of an address to the address book
+func AliasAddAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: alias add <name> <address>")
+	}
+
+	address, err := common.HexToAddress(c.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("invalid address: %s", err)
+	}
+
+	book, pass, err := openAddressBook()
+	if err != nil {
+		return err
+	}
+
+	if err = book.add(c.Args().Get(0), address); err != nil {
+		return err
+	}
+
+	if err = book.save(pass); err != nil {
+		return err
+	}
+
+	fmt.Printf("alias %s added, address %s, shard %d\n", c.Args().Get(0), address.Hex(), address.Shard())
+
+	return nil
+}
+
+// AliasRemoveAction removes the alias from the address book
+func AliasRemoveAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("usage: alias remove <name>")
+	}
+
+	book, pass, err := openAddressBook()
+	if err != nil {
+		return err
+	}
+
+	if err = book.remove(c.Args().Get(0)); err != nil {
+		return err
+	}
+
+	return book.save(pass)
+}
+
+// AliasListAction prints the aliases in the address book
+func AliasListAction(c *cli.Context) error {
+	book, _, err := openAddressBook()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range book.names() {
+		address := book.Aliases[name]
+		fmt.Printf("%s\t%s\tshard %d\n", name, address.Hex(), address.Shard())
+	}
+
+	return nil
+}
+
+// resolveAddress returns the address of the hex value, or the address of the alias in the address book,
+// which is confirmed by the user with the resolved address and shard to avoid sending to a wrong account.
+func resolveAddress(value string) (common.Address, error) {
+	address, err := common.HexToAddress(value)
+	if err == nil || !aliasNameRegexp.MatchString(value) {
+		return address, err
+	}
+
+	if _, err = os.Stat(aliasFileValue); os.IsNotExist(err) {
+		return common.EmptyAddress, fmt.Errorf("no address book %s to resolve alias %q", aliasFileValue, value)
+	}
+
+	book, _, err := openAddressBook()
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+
+	address, ok := book.Aliases[value]
+	if !ok {
+		return common.EmptyAddress, fmt.Errorf("alias %q not found in address book %s", value, aliasFileValue)
+	}
+
+	fmt.Printf("alias %s resolves to address %s, shard %d\n", value, address.Hex(), address.Shard())
+	if !askForConfirmation(os.Stdin, "Continue with this receiver? [y/N]: ") {
+		return common.EmptyAddress, fmt.Errorf("cancelled, receiver alias %s not confirmed", value)
+	}
+
+	return address, nil
+}
+
+// askForConfirmation prints the prompt and returns true only if the user answers yes
+func askForConfirmation(in io.Reader, prompt string) bool {
+	fmt.Print(prompt)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && len(answer) == 0 {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
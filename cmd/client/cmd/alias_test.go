@@ -0,0 +1,66 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scdoproject/go-scdo/crypto"
+)
+
+func Test_AddressBook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addressbook")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "addressbook.json")
+	addr1 := *crypto.MustGenerateShardAddress(1)
+	addr2 := *crypto.MustGenerateShardAddress(2)
+
+	// empty book if file not exists
+	book, err := loadAddressBook(path, "pass")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(book.Aliases), 0)
+
+	assert.Equal(t, book.add("alice", addr1), nil)
+	assert.Equal(t, book.add("bob.2", addr2), nil)
+	assert.Equal(t, book.add("alice", addr2) != nil, true)
+	assert.Equal(t, book.add(addr1.Hex(), addr1) != nil, true)
+	assert.Equal(t, book.add("1bob", addr1) != nil, true)
+	assert.Equal(t, book.names(), []string{"alice", "bob.2"})
+	assert.Equal(t, book.save("pass"), nil)
+
+	// encrypted in file
+	content, err := ioutil.ReadFile(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, strings.Contains(string(content), "alice"), false)
+
+	_, err = loadAddressBook(path, "badpass")
+	assert.Equal(t, err != nil, true)
+
+	book, err = loadAddressBook(path, "pass")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, book.Aliases["alice"], addr1)
+	assert.Equal(t, book.Aliases["bob.2"], addr2)
+
+	assert.Equal(t, book.remove("alice"), nil)
+	assert.Equal(t, book.remove("alice") != nil, true)
+	assert.Equal(t, book.names(), []string{"bob.2"})
+}
+
+func Test_askForConfirmation(t *testing.T) {
+	assert.Equal(t, askForConfirmation(strings.NewReader("y\n"), ""), true)
+	assert.Equal(t, askForConfirmation(strings.NewReader("Yes"), ""), true)
+	assert.Equal(t, askForConfirmation(strings.NewReader("\n"), ""), false)
+	assert.Equal(t, askForConfirmation(strings.NewReader("no\n"), ""), false)
+	assert.Equal(t, askForConfirmation(strings.NewReader(""), ""), false)
+}
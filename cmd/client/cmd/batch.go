@@ -0,0 +1,210 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/scdoproject/go-scdo/cmd/util"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/keystore"
+	"github.com/scdoproject/go-scdo/rpc"
+	"github.com/urfave/cli"
+)
+
+// batchRow is a (to, amount) row of the batch file
+type batchRow struct {
+	Row    int
+	To     common.Address
+	Amount *big.Int
+}
+
+// batchResult is the result of sending the tx of a batch row
+type batchResult struct {
+	Row    int
+	To     string
+	Amount *big.Int
+	Nonce  *uint64 `json:",omitempty"`
+	Hash   string  `json:",omitempty"`
+	Error  string  `json:",omitempty"`
+}
+
+// readBatchFile reads the (to, amount) rows from the csv file, the header row is optional.
+func readBatchFile(r io.Reader) ([]*batchRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var rows []*batchRow
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "to") {
+			continue
+		}
+
+		to, err := common.HexToAddress(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid receiver address at row %d: %s", row, err)
+		}
+
+		amount, ok := big.NewInt(0).SetString(strings.TrimSpace(record[1]), 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, fmt.Errorf("invalid amount value at row %d", row)
+		}
+
+		rows = append(rows, &batchRow{row, to, amount})
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no rows to send")
+	}
+
+	return rows, nil
+}
+
+// batchInterval returns the interval between the txs sent at the rate per second, 0 means no limit.
+func batchInterval(rate uint) (time.Duration, error) {
+	if rate == 0 {
+		return 0, nil
+	}
+
+	if uint64(rate) > uint64(time.Second) {
+		return 0, fmt.Errorf("rate %d exceeds the max rate %d", rate, uint64(time.Second))
+	}
+
+	return time.Second / time.Duration(rate), nil
+}
+
+// sendBatchAction sends the txs of the batch file with consecutive nonces. The nonce is
+// assigned only when the tx is accepted by the node, so that a failed row leaves no nonce gap.
+func sendBatchAction(c *cli.Context) error {
+	interval, err := batchInterval(rateValue)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(batchFileValue)
+	if err != nil {
+		return fmt.Errorf("failed to open batch file: %s", err)
+	}
+	defer file.Close()
+
+	rows, err := readBatchFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read batch file: %s", err)
+	}
+
+	price, ok := big.NewInt(0).SetString(priceValue, 10)
+	if !ok {
+		return fmt.Errorf("invalid gas price value")
+	}
+
+	pass, err := common.GetPassword()
+	if err != nil {
+		return fmt.Errorf("failed to get password %s", err)
+	}
+
+	key, err := keystore.GetKey(keyFileValue, pass)
+	if err != nil {
+		return fmt.Errorf("invalid sender key file. it should be a private key: %s", err)
+	}
+
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := util.GetAccountNonce(client, key.Address, "", -1)
+	if err != nil {
+		return fmt.Errorf("failed to get the sender account's nonce: %s", err)
+	}
+
+	if nonceValue != DefaultNonce {
+		if nonceValue < nonce {
+			return fmt.Errorf("start nonce %d is smaller than database nonce %d", nonceValue, nonce)
+		}
+		nonce = nonceValue
+	}
+
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+	}
+
+	results := make([]*batchResult, 0, len(rows))
+	var sent, failed int
+	for i, row := range rows {
+		if ticker != nil && i > 0 {
+			<-ticker.C
+		}
+
+		result := &batchResult{Row: row.Row, To: row.To.Hex(), Amount: row.Amount}
+		results = append(results, result)
+
+		hash, err := sendBatchTx(client, key, row, price, nonce)
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+			fmt.Printf("row %d: failed to send to %s, %s\n", row.Row, result.To, err)
+			continue
+		}
+
+		txNonce := nonce
+		result.Nonce, result.Hash = &txNonce, hash.Hex()
+		nonce++
+		sent++
+		fmt.Printf("row %d: sent to %s, nonce %d, hash %s\n", row.Row, result.To, txNonce, result.Hash)
+	}
+
+	encoded, err := json.MarshalIndent(map[string]interface{}{
+		"Sent":    sent,
+		"Failed":  failed,
+		"Results": results,
+	}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(encoded))
+	return nil
+}
+
+// sendBatchTx signs the tx of the batch row with the nonce and sends it to the node
+func sendBatchTx(client *rpc.Client, key *keystore.Key, row *batchRow, price *big.Int, nonce uint64) (common.Hash, error) {
+	tx, err := util.GenerateTx(key.PrivateKey, &key.Address, row.To, row.Amount, price, gasLimitValue, nonce, nil)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	var added bool
+	if err = client.Call(&added, "scdo_addTx", *tx); err != nil {
+		return common.EmptyHash, err
+	}
+
+	if !added {
+		return common.EmptyHash, fmt.Errorf("transaction is not added")
+	}
+
+	return tx.Hash, nil
+}
@@ -0,0 +1,64 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scdoproject/go-scdo/crypto"
+)
+
+func Test_readBatchFile(t *testing.T) {
+	to1 := *crypto.MustGenerateShardAddress(1)
+	to2 := *crypto.MustGenerateShardAddress(2)
+
+	// with header and comment
+	rows, err := readBatchFile(strings.NewReader("to,amount\n# comment\n" + to1.Hex() + ", 100\n" + to2.Hex() + ",200\n"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(rows), 2)
+	assert.Equal(t, rows[0], &batchRow{2, to1, big.NewInt(100)})
+	assert.Equal(t, rows[1], &batchRow{3, to2, big.NewInt(200)})
+
+	// without header
+	rows, err = readBatchFile(strings.NewReader(to1.Hex() + ",100\n"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, rows, []*batchRow{{1, to1, big.NewInt(100)}})
+
+	// invalid rows
+	_, err = readBatchFile(strings.NewReader(to1.Hex() + ",-1\n"))
+	assert.Equal(t, err != nil, true)
+
+	_, err = readBatchFile(strings.NewReader("0x01,100\n"))
+	assert.Equal(t, err != nil, true)
+
+	_, err = readBatchFile(strings.NewReader(to1.Hex() + ",100,1\n"))
+	assert.Equal(t, err != nil, true)
+
+	_, err = readBatchFile(strings.NewReader("to,amount\n"))
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_batchInterval(t *testing.T) {
+	interval, err := batchInterval(0)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, interval, time.Duration(0))
+
+	interval, err = batchInterval(10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, interval, 100*time.Millisecond)
+
+	interval, err = batchInterval(uint(time.Second))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, interval, time.Nanosecond)
+
+	_, err = batchInterval(uint(time.Second) + 1)
+	assert.Equal(t, err != nil, true)
+}
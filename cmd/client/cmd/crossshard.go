@@ -0,0 +1,119 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/urfave/cli"
+)
+
+// crossShardCost is the estimated cost of a cross shard transfer, which is charged to the sender in the source
+// shard, and the debt gas is paid to the miner of the target shard once the debt is packed.
+type crossShardCost struct {
+	FromShard uint
+	ToShard   uint
+	Amount    *big.Int
+	GasPrice  *big.Int
+
+	SourceGas uint64   // gas of the tx in the source shard
+	SourceFee *big.Int // fee paid to the miner of the source shard
+	DebtGas   uint64   // gas of the debt in the target shard
+	DebtFee   *big.Int // fee paid to the miner of the target shard
+	TotalFee  *big.Int
+	TotalCost *big.Int // amount and total fee deducted from the sender
+
+	// blocks to confirm the tx in the source shard before the debt is propagated,
+	// and the blocks to confirm the debt in the target shard once packed
+	SourceConfirmBlocks uint64
+	TargetConfirmBlocks uint64
+	ExpectedDuration    string // expected duration to complete the transfer with the block pack interval
+
+	Warning string `json:",omitempty"`
+}
+
+// estimateCrossShardCost estimates the cost of transferring the amount from the source shard to the target shard
+// with the gas price. It warns if the amount is less than dustRatio times of the total fee.
+func estimateCrossShardCost(from, to common.Address, amount, price *big.Int, dustRatio float64) (*crossShardCost, error) {
+	if from.Shard() == to.Shard() {
+		return nil, fmt.Errorf("not a cross shard transfer, both addresses are in shard %d", from.Shard())
+	}
+
+	if amount.Sign() < 0 || price.Sign() < 0 {
+		return nil, fmt.Errorf("amount and price should not be negative")
+	}
+
+	cost := &crossShardCost{
+		FromShard:           from.Shard(),
+		ToShard:             to.Shard(),
+		Amount:              amount,
+		GasPrice:            price,
+		SourceGas:           types.CrossShardTransactionGas,
+		SourceFee:           new(big.Int).Mul(price, new(big.Int).SetUint64(types.CrossShardTransactionGas)),
+		DebtGas:             types.DebtGas,
+		DebtFee:             new(big.Int).Mul(price, new(big.Int).SetUint64(types.DebtGas)),
+		SourceConfirmBlocks: common.ConfirmedBlockNumber,
+		TargetConfirmBlocks: common.ConfirmedBlockNumber,
+	}
+
+	cost.TotalFee = new(big.Int).Add(cost.SourceFee, cost.DebtFee)
+	cost.TotalCost = new(big.Int).Add(amount, cost.TotalFee)
+
+	blocks := cost.SourceConfirmBlocks + cost.TargetConfirmBlocks
+	cost.ExpectedDuration = (time.Duration(blocks) * common.BlockPackInterval).String()
+
+	threshold := new(big.Float).Mul(new(big.Float).SetInt(cost.TotalFee), big.NewFloat(dustRatio))
+	if new(big.Float).SetInt(amount).Cmp(threshold) < 0 {
+		cost.Warning = fmt.Sprintf("amount %s is less than %v times of the total fee %s", amount, dustRatio, cost.TotalFee)
+	}
+
+	return cost, nil
+}
+
+// estimateCrossShardAction prints the estimated cost of the cross shard transfer
+func estimateCrossShardAction(c *cli.Context) error {
+	from, err := resolveAddress(fromAddressValue)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %s", err)
+	}
+
+	to, err := resolveAddress(toValue)
+	if err != nil {
+		return fmt.Errorf("invalid receiver address: %s", err)
+	}
+
+	amount, ok := big.NewInt(0).SetString(amountValue, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount value")
+	}
+
+	price, ok := big.NewInt(0).SetString(priceValue, 10)
+	if !ok {
+		return fmt.Errorf("invalid gas price value")
+	}
+
+	cost, err := estimateCrossShardCost(from, to, amount, price, dustRatioValue)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.MarshalIndent(cost, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(encoded))
+	if cost.Warning != "" {
+		fmt.Printf("WARNING: %s\n", cost.Warning)
+	}
+
+	return nil
+}
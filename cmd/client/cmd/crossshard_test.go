@@ -0,0 +1,43 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+)
+
+func Test_estimateCrossShardCost(t *testing.T) {
+	from := *crypto.MustGenerateShardAddress(1)
+	to := *crypto.MustGenerateShardAddress(2)
+	price := big.NewInt(10)
+
+	cost, err := estimateCrossShardCost(from, to, big.NewInt(1000000000), price, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, cost.FromShard, uint(1))
+	assert.Equal(t, cost.ToShard, uint(2))
+	assert.Equal(t, cost.SourceFee, big.NewInt(int64(10*types.CrossShardTransactionGas)))
+	assert.Equal(t, cost.DebtFee, big.NewInt(int64(10*types.DebtGas)))
+	assert.Equal(t, cost.TotalFee, big.NewInt(int64(10*types.CrossShardTotalGas)))
+	assert.Equal(t, cost.TotalCost, big.NewInt(1000000000+int64(10*types.CrossShardTotalGas)))
+	assert.Equal(t, cost.SourceConfirmBlocks, uint64(common.ConfirmedBlockNumber))
+	assert.Equal(t, cost.Warning, "")
+
+	// dust amount
+	cost, err = estimateCrossShardCost(from, to, big.NewInt(1000), price, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, cost.Warning != "", true)
+
+	// same shard
+	_, err = estimateCrossShardCost(from, *crypto.MustGenerateShardAddress(1), big.NewInt(1), price, 10)
+	assert.Equal(t, err != nil, true)
+}
@@ -0,0 +1,377 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scdoproject/go-scdo/accounts/abi"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/contract/system"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/rpc"
+	"github.com/urfave/cli"
+)
+
+// erc20ABI is the ERC-20 style methods decoded when the abi of the contract is unknown
+const erc20ABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"approve","inputs":[{"name":"spender","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"transferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+// systemContractCommand is the name of a system contract command, and whether its input is a domain name
+type systemContractCommand struct {
+	name   string
+	domain bool
+}
+
+// systemContractCommands is the names of the system contracts and their commands to decode the tx payload
+var systemContractCommands = map[common.Address]struct {
+	name     string
+	commands map[byte]systemContractCommand
+}{
+	system.DomainNameContractAddress: {"domain", map[byte]systemContractCommand{
+		system.CmdCreateDomainName:   {"create", true},
+		system.CmdGetDomainNameOwner: {"getOwner", true},
+	}},
+	system.SubChainContractAddress: {"subchain", map[byte]systemContractCommand{
+		system.CmdSubChainRegister: {"register", false},
+		system.CmdSubChainQuery:    {"query", false},
+	}},
+	system.HashTimeLockContractAddress: {"htlc", map[byte]systemContractCommand{
+		system.CmdNewContract: {"create", false},
+		system.CmdWithdraw:    {"withdraw", false},
+		system.CmdRefund:      {"refund", false},
+		system.CmdGetContract: {"get", false},
+	}},
+	system.MasternodeContractAddress: {"masternode", map[byte]systemContractCommand{
+		system.CmdDeposit:         {"deposit", false},
+		system.CmdQueryMasternode: {"query", false},
+		system.CmdRecall:          {"recall", false},
+		system.CmdQuit:            {"quit", false},
+	}},
+	system.BTCRelayContractAddress: {"btcrelay", map[byte]systemContractCommand{
+		system.CmdVerifyTx:         {"verifyTx", false},
+		system.CmdRelayTx:          {"relayTx", false},
+		system.CmdStoreBlockHeader: {"storeBlockHeader", false},
+		system.CmdGetBlockHeader:   {"getBlockHeader", false},
+	}},
+	system.MetaTxRelayContractAddress: {"relay", map[byte]systemContractCommand{
+		system.CmdRelayMetaTx:   {"send", false},
+		system.CmdGetRelayNonce: {"nonce", false},
+	}},
+	system.ContractRegistryContractAddress: {"registry", map[byte]systemContractCommand{
+		system.CmdRegisterContractMetadata: {"register", false},
+		system.CmdGetContractMetadata:      {"get", false},
+	}},
+	system.AccessControlContractAddress: {"acl", map[byte]systemContractCommand{
+		system.CmdVoteAccessRule: {"vote", false},
+		system.CmdGetAccessRule:  {"get", false},
+	}},
+}
+
+// decodedTx is the human-readable decoding of a tx
+type decodedTx struct {
+	Amount string // in SCDO
+	Fee    *decodedFee
+	Call   *decodedCall `json:",omitempty"`
+}
+
+// decodedFee is the fee breakdown of a tx
+type decodedFee struct {
+	GasPrice string // in Wen
+	GasLimit uint64
+	MaxFee   string // gas price * gas limit in SCDO
+	UsedGas  uint64 `json:",omitempty"`
+	TotalFee string `json:",omitempty"` // the actual fee in SCDO if the tx is packed in a block
+}
+
+// decodedCall is the decoded payload of a contract call
+type decodedCall struct {
+	Contract string // the system contract name, or the abi which decodes the payload
+	Method   string
+	Args     map[string]interface{} `json:",omitempty"`
+}
+
+// scdoAmount formats the amount in Wen to SCDO
+func scdoAmount(amount *big.Int) string {
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+
+	return common.BigToDecimal(amount) + " SCDO"
+}
+
+// decodeTransaction decodes the amounts, fee and payload of the tx. The contract call is decoded by abiJSON
+// if it is not empty, otherwise by the abi registered locally for the receiver, or the ERC-20 style methods.
+// receipt is the receipt of the packed tx, or nil if the tx is not packed yet.
+func decodeTransaction(data *types.TransactionData, abiJSON string, receipt *types.Receipt) *decodedTx {
+	gasPrice := data.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(0)
+	}
+
+	decoded := &decodedTx{
+		Amount: scdoAmount(data.Amount),
+		Fee: &decodedFee{
+			GasPrice: gasPrice.String() + " Wen",
+			GasLimit: data.GasLimit,
+			MaxFee:   scdoAmount(new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(data.GasLimit))),
+		},
+	}
+
+	if receipt != nil {
+		decoded.Fee.UsedGas = receipt.UsedGas
+		decoded.Fee.TotalFee = scdoAmount(new(big.Int).SetUint64(receipt.TotalFee))
+	}
+
+	if len(data.Payload) > 0 {
+		decoded.Call = decodeCall(data.To, data.Payload, abiJSON)
+	}
+
+	return decoded
+}
+
+// decodeCall decodes the payload of the tx to the receiver, returns nil if the payload is unknown.
+func decodeCall(to common.Address, payload []byte, abiJSON string) *decodedCall {
+	if to.IsEmpty() {
+		return &decodedCall{Method: "deploy"}
+	}
+
+	if contract, ok := systemContractCommands[to]; ok {
+		return decodeSystemContractCall(contract.name, contract.commands, payload)
+	}
+
+	if abiJSON == "" {
+		abiJSON = loadRegisteredABI(abiDirValue, to)
+	}
+
+	for _, candidate := range []struct{ name, json string }{{"abi", abiJSON}, {"erc20", erc20ABI}} {
+		if candidate.json == "" {
+			continue
+		}
+
+		if call := decodeABICall(candidate.name, candidate.json, payload); call != nil {
+			return call
+		}
+	}
+
+	return nil
+}
+
+func decodeSystemContractCall(contract string, commands map[byte]systemContractCommand, payload []byte) *decodedCall {
+	command, ok := commands[payload[0]]
+	if !ok {
+		return &decodedCall{Contract: contract, Method: fmt.Sprintf("unknown command %d", payload[0])}
+	}
+
+	call := &decodedCall{Contract: contract, Method: command.name}
+	if len(payload) > 1 {
+		if command.domain {
+			call.Args = map[string]interface{}{"name": string(payload[1:])}
+		} else {
+			call.Args = map[string]interface{}{"input": hexutil.BytesToHex(payload[1:])}
+		}
+	}
+
+	return call
+}
+
+func decodeABICall(contract, abiJSON string, payload []byte) *decodedCall {
+	if len(payload) < 4 {
+		return nil
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil
+	}
+
+	method, err := parsed.MethodById(payload[:4])
+	if err != nil {
+		return nil
+	}
+
+	values, err := method.Inputs.UnpackValues(payload[4:])
+	if err != nil {
+		return nil
+	}
+
+	call := &decodedCall{Contract: contract, Method: method.Sig()}
+	if len(values) > 0 {
+		call.Args = make(map[string]interface{}, len(values))
+		for i, value := range values {
+			name := method.Inputs[i].Name
+			if name == "" {
+				name = fmt.Sprintf("arg%d", i)
+			}
+
+			call.Args[name] = value
+		}
+	}
+
+	return call
+}
+
+// defaultABIDir returns the default folder of the locally registered abi files in the scdo data folder
+func defaultABIDir() string {
+	return filepath.Join(common.GetDefaultDataFolder(), "abi")
+}
+
+func registeredABIFile(dir string, contract common.Address) string {
+	return filepath.Join(dir, contract.Hex()+".json")
+}
+
+// loadRegisteredABI returns the abi registered locally for the contract, or empty if not registered
+func loadRegisteredABI(dir string, contract common.Address) string {
+	content, err := ioutil.ReadFile(registeredABIFile(dir, contract))
+	if err != nil {
+		return ""
+	}
+
+	return string(content)
+}
+
+// ABIRegisterAction registers the abi of a contract locally to decode the txs to it
+func ABIRegisterAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: abi register <contract> <abi file>")
+	}
+
+	contract, err := common.HexToAddress(c.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("invalid contract address: %s", err)
+	}
+
+	abiJSON, err := readABIFile(c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	if _, err = abi.JSON(strings.NewReader(abiJSON)); err != nil {
+		return fmt.Errorf("invalid abi: %s", err)
+	}
+
+	if err = os.MkdirAll(abiDirValue, 0700); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(registeredABIFile(abiDirValue, contract), []byte(abiJSON), 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("abi of contract %s registered\n", contract.Hex())
+
+	return nil
+}
+
+// ABIListAction lists the contracts whose abi is registered locally
+func ABIListAction(c *cli.Context) error {
+	files, err := ioutil.ReadDir(abiDirValue)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var contracts []string
+	for _, f := range files {
+		if name := f.Name(); !f.IsDir() && strings.HasSuffix(name, ".json") {
+			contracts = append(contracts, strings.TrimSuffix(name, ".json"))
+		}
+	}
+
+	sort.Strings(contracts)
+	for _, contract := range contracts {
+		fmt.Println(contract)
+	}
+
+	return nil
+}
+
+// GetTxByHashAction gets the tx by hash, and decodes its amounts, fee and payload
+func GetTxByHashAction(c *cli.Context) error {
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	var result map[string]json.RawMessage
+	if err = client.Call(&result, "txpool_getTransactionByHash", hashValue); err != nil {
+		return fmt.Errorf("Failed to call rpc, %s", err)
+	}
+
+	if result == nil {
+		return handleCallResult(nil, nil)
+	}
+
+	var tx struct {
+		To           string
+		Amount       *big.Int
+		AccountNonce uint64
+		Payload      []byte
+		GasPrice     *big.Int
+		GasLimit     uint64
+	}
+
+	if err = json.Unmarshal(result["transaction"], &tx); err != nil {
+		return fmt.Errorf("invalid transaction, %s", err)
+	}
+
+	data := &types.TransactionData{
+		Amount:       tx.Amount,
+		AccountNonce: tx.AccountNonce,
+		Payload:      tx.Payload,
+		GasPrice:     tx.GasPrice,
+		GasLimit:     tx.GasLimit,
+	}
+
+	if len(tx.To) > 0 {
+		if data.To, err = common.HexToAddress(tx.To); err != nil {
+			return fmt.Errorf("invalid receiver, %s", err)
+		}
+	}
+
+	abiJSON := ""
+	if len(abiFile) > 0 {
+		if abiJSON, err = readABIFile(abiFile); err != nil {
+			return err
+		}
+	}
+
+	var receipt *types.Receipt
+	if status := string(result["status"]); status == `"block"` {
+		var r struct {
+			UsedGas  uint64
+			TotalFee uint64
+		}
+
+		if err = client.Call(&r, "scdo_getReceiptByTxHash", hashValue, ""); err == nil {
+			receipt = &types.Receipt{UsedGas: r.UsedGas, TotalFee: r.TotalFee}
+		}
+	}
+
+	decoded, err := json.Marshal(decodeTransaction(data, abiJSON, receipt))
+	if err != nil {
+		return err
+	}
+
+	result["decoded"] = decoded
+
+	return handleCallResult(nil, result)
+}
@@ -0,0 +1,93 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/accounts/abi"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/contract/system"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DecodeTransaction(t *testing.T) {
+	data := &types.TransactionData{
+		To:       system.DomainNameContractAddress,
+		Amount:   big.NewInt(150000000),
+		GasPrice: big.NewInt(10),
+		GasLimit: 100000,
+		Payload:  append([]byte{system.CmdCreateDomainName}, []byte("scdo-test")...),
+	}
+
+	decoded := decodeTransaction(data, "", &types.Receipt{UsedGas: 50000, TotalFee: 500000})
+	assert.Equal(t, decoded, &decodedTx{
+		Amount: "1.5 SCDO",
+		Fee: &decodedFee{
+			GasPrice: "10 Wen",
+			GasLimit: 100000,
+			MaxFee:   "0.01 SCDO",
+			UsedGas:  50000,
+			TotalFee: "0.005 SCDO",
+		},
+		Call: &decodedCall{Contract: "domain", Method: "create", Args: map[string]interface{}{"name": "scdo-test"}},
+	})
+
+	// unknown system contract command
+	data.Payload = []byte{100}
+	assert.Equal(t, decodeTransaction(data, "", nil).Call, &decodedCall{Contract: "domain", Method: "unknown command 100"})
+
+	// contract creation
+	data.To = common.EmptyAddress
+	assert.Equal(t, decodeTransaction(data, "", nil).Call, &decodedCall{Method: "deploy"})
+
+	// unknown payload
+	data.To = common.BytesToAddress([]byte("contract"))
+	data.Payload = []byte{1, 2, 3, 4, 5}
+	assert.Equal(t, decodeTransaction(data, "", nil).Call == nil, true)
+}
+
+func Test_DecodeCall_ABI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "abi")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	abiDirValue = dir
+	defer func() { abiDirValue = defaultABIDir() }()
+
+	contract := common.BytesToAddress([]byte("contract"))
+	erc20, err := abi.JSON(strings.NewReader(erc20ABI))
+	assert.Equal(t, err, nil)
+
+	// ERC-20 transfer without the abi
+	payload, err := erc20.Pack("transfer", contract, big.NewInt(100))
+	assert.Equal(t, err, nil)
+
+	call := decodeCall(contract, payload, "")
+	assert.Equal(t, call.Contract, "erc20")
+	assert.Equal(t, call.Method, "transfer(address,uint256)")
+	assert.Equal(t, call.Args["value"], big.NewInt(100))
+
+	// registered abi
+	registered := `[{"type":"function","name":"setValue","inputs":[{"name":"v","type":"uint256"}],"outputs":[]}]`
+	assert.Equal(t, ioutil.WriteFile(registeredABIFile(dir, contract), []byte(registered), 0600), nil)
+
+	parsed, err := abi.JSON(strings.NewReader(registered))
+	assert.Equal(t, err, nil)
+	payload, err = parsed.Pack("setValue", big.NewInt(7))
+	assert.Equal(t, err, nil)
+
+	call = decodeCall(contract, payload, "")
+	assert.Equal(t, call, &decodedCall{Contract: "abi", Method: "setValue(uint256)", Args: map[string]interface{}{"v": big.NewInt(7)}})
+
+	// the abi of other contract is not used
+	assert.Equal(t, decodeCall(common.BytesToAddress([]byte("other")), payload, "") == nil, true)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/keystore"
 	"github.com/urfave/cli"
 )
 
@@ -86,6 +87,34 @@ var (
 		Destination: &heightPosValue,
 	}
 
+	fromHeightValue uint64
+	fromHeightFlag  = cli.Uint64Flag{
+		Name:        "from",
+		Usage:       "start block height of the range",
+		Destination: &fromHeightValue,
+	}
+
+	toHeightValue uint64
+	toHeightFlag  = cli.Uint64Flag{
+		Name:        "to",
+		Usage:       "end block height of the range, inclusive",
+		Destination: &toHeightValue,
+	}
+
+	fromLogIndexValue uint64
+	fromLogIndexFlag  = cli.Uint64Flag{
+		Name:        "from",
+		Usage:       "start log index in the block",
+		Destination: &fromLogIndexValue,
+	}
+
+	toLogIndexValue uint64
+	toLogIndexFlag  = cli.Uint64Flag{
+		Name:        "to",
+		Usage:       "end log index in the block, inclusive",
+		Destination: &toLogIndexValue,
+	}
+
 	trialValue string
 	trialFlag  = cli.StringFlag{
 		Name:        "trial, t",
@@ -101,6 +130,13 @@ var (
 		Destination: &fulltxValue,
 	}
 
+	includeDebtsValue bool
+	includeDebtsFlag  = cli.BoolFlag{
+		Name:        "debts",
+		Usage:       "whether include the debts credited to the account, in the same output schema as the transactions",
+		Destination: &includeDebtsValue,
+	}
+
 	hashValue string
 	hashFlag  = cli.StringFlag{
 		Name:        "hash",
@@ -108,6 +144,20 @@ var (
 		Destination: &hashValue,
 	}
 
+	saltValue string
+	saltFlag  = cli.StringFlag{
+		Name:        "salt",
+		Usage:       "salt of the CREATE2 contract deployment in hex",
+		Destination: &saltValue,
+	}
+
+	codeHashValue string
+	codeHashFlag  = cli.StringFlag{
+		Name:        "codehash",
+		Usage:       "keccak256 hash of the contract init code in hex",
+		Destination: &codeHashValue,
+	}
+
 	fromValue string
 	fromFlag  = cli.StringFlag{
 		Name:        "from",
@@ -118,10 +168,26 @@ var (
 	toValue string
 	toFlag  = cli.StringFlag{
 		Name:        "to",
-		Usage:       "to address",
+		Usage:       "to address, or alias name in the address book",
 		Destination: &toValue,
 	}
 
+	aliasFileValue string
+	aliasFileFlag  = cli.StringFlag{
+		Name:        "aliasfile",
+		Value:       defaultAddressBookFile(),
+		Usage:       "encrypted address book file of the alias names",
+		Destination: &aliasFileValue,
+	}
+
+	abiDirValue string
+	abiDirFlag  = cli.StringFlag{
+		Name:        "abidir",
+		Value:       defaultABIDir(),
+		Usage:       "folder of the locally registered abi files to decode the txs",
+		Destination: &abiDirValue,
+	}
+
 	amountValue string
 	amountFlag  = cli.StringFlag{
 		Name:        "amount",
@@ -191,6 +257,92 @@ var (
 		Destination: &coinbaseValue,
 	}
 
+	weightValue uint64
+	weightFlag  = cli.Uint64Flag{
+		Name:        "weight",
+		Value:       1,
+		Usage:       "weight of the coinbase to be selected in pool mode, 0 means never selected",
+		Destination: &weightValue,
+	}
+
+	quotaValue uint64
+	quotaFlag  = cli.Uint64Flag{
+		Name:        "quota",
+		Usage:       "max blocks mined per day with the coinbase in pool mode, 0 means unlimited",
+		Destination: &quotaValue,
+	}
+
+	extraValue string
+	extraFlag  = cli.StringFlag{
+		Name:        "extra",
+		Usage:       "block header extra data for signaling, hex bitfield with 0x prefix or plain string",
+		Destination: &extraValue,
+	}
+
+	signalBlocksValue uint64
+	signalBlocksFlag  = cli.Uint64Flag{
+		Name:        "blocks",
+		Usage:       "number of recent blocks to tally, 0 means the default 1000 blocks",
+		Destination: &signalBlocksValue,
+	}
+
+	statsBlocksValue uint64
+	statsBlocksFlag  = cli.Uint64Flag{
+		Name:        "blocks",
+		Usage:       "number of recent blocks of the statistics, 0 means the default 100 blocks",
+		Destination: &statsBlocksValue,
+	}
+
+	cidrValue string
+	cidrFlag  = cli.StringFlag{
+		Name:        "cidr",
+		Usage:       "IP or CIDR range, for example: 10.0.0.1 or 10.0.0.0/24",
+		Destination: &cidrValue,
+	}
+
+	blockDurationValue string
+	blockDurationFlag  = cli.StringFlag{
+		Name:        "duration",
+		Usage:       "block duration, for example: 30m or 24h, empty means never expire",
+		Destination: &blockDurationValue,
+	}
+
+	hashrateValue float64
+	hashrateFlag  = cli.Float64Flag{
+		Name:        "hashrate",
+		Usage:       "nonce attempts per second of the miner, for example: the detrate of the miner",
+		Destination: &hashrateValue,
+	}
+
+	keyDirValue string
+	keyDirFlag  = cli.StringFlag{
+		Name:        "dir",
+		Usage:       "folder of the key files",
+		Destination: &keyDirValue,
+	}
+
+	backupToValue string
+	backupToFlag  = cli.StringFlag{
+		Name:        "to",
+		Usage:       "encrypted backup file to export the key files to",
+		Destination: &backupToValue,
+	}
+
+	backupFromValue string
+	backupFromFlag  = cli.StringFlag{
+		Name:        "from",
+		Usage:       "encrypted backup file to import the key files from",
+		Destination: &backupFromValue,
+	}
+
+	conflictValue string
+	conflictFlag  = cli.StringFlag{
+		Name:        "conflict",
+		Value:       keystore.ConflictSkip,
+		Usage:       "policy when a different key file of the same name exists: skip, overwrite (the existing file is backed up first) or rename",
+		Destination: &conflictValue,
+	}
+
 	miningNonceValue uint64
 	miningNonceFlag  = cli.Uint64Flag{
 		Name:        "nonce",
@@ -243,6 +395,21 @@ var (
 		Destination: &dumpFileValue,
 	}
 
+	tracerValue string
+	tracerFlag  = cli.StringFlag{
+		Name:        "tracer",
+		Value:       "structLogger",
+		Usage:       "tracer of the txs, structLogger for evm op code logs, gasAudit for the gas accounting and refunds, or none for receipts only",
+		Destination: &tracerValue,
+	}
+
+	blockDataValue string
+	blockDataFlag  = cli.StringFlag{
+		Name:        "block",
+		Usage:       "the block to validate, hex encoded rlp bytes or json",
+		Destination: &blockDataValue,
+	}
+
 	timeLockValue int64
 	timeLockFlag  = cli.Int64Flag{
 		Name:        "time",
@@ -257,13 +424,63 @@ var (
 		Destination: &preimageValue,
 	}
 
+	metaTxValue string
+	metaTxFlag  = cli.StringFlag{
+		Name:        "metatx",
+		Usage:       "signed meta tx in hex",
+		Destination: &metaTxValue,
+	}
+
+	chainIDValue uint64
+	chainIDFlag  = cli.Uint64Flag{
+		Name:        "chainid",
+		Value:       common.MainChainID,
+		Usage:       "chain id of the network signed in the meta tx, default is the main network",
+		Destination: &chainIDValue,
+	}
+
 	nameValue string
 	nameFlag  = cli.StringFlag{
 		Name:        "name",
-		Usage:       "domain or subchain name",
+		Usage:       "domain, subchain or contract name",
 		Destination: &nameValue,
 	}
 
+	deployNonceValue uint64
+	deployNonceFlag  = cli.Uint64Flag{
+		Name:        "deploynonce",
+		Usage:       "account nonce of the tx which deploys the contract",
+		Destination: &deployNonceValue,
+	}
+
+	sourceURIValue string
+	sourceURIFlag  = cli.StringFlag{
+		Name:        "source",
+		Usage:       "URI of the verified contract source code",
+		Destination: &sourceURIValue,
+	}
+
+	versionValue string
+	versionFlag  = cli.StringFlag{
+		Name:        "version",
+		Usage:       "contract version",
+		Destination: &versionValue,
+	}
+
+	accessActionValue string
+	accessActionFlag  = cli.StringFlag{
+		Name:        "action",
+		Usage:       "access control action, deploy or call",
+		Destination: &accessActionValue,
+	}
+
+	permissionValue string
+	permissionFlag  = cli.StringFlag{
+		Name:        "permission",
+		Usage:       "access control permission, allow, deny or unset",
+		Destination: &permissionValue,
+	}
+
 	subChainJSONFileVale string
 	subChainJSONFileFlag = cli.StringFlag{
 		Name:        "file",
@@ -292,6 +509,98 @@ var (
 		Value:       "sha256",
 		Destination: &algorithmValue,
 	}
+
+	blockFileValue string
+	blockFileFlag  = cli.StringFlag{
+		Name:        "file",
+		Usage:       "json file of the raw block and its receipts, e.g. the output of getrawblock, or the block of the hash is fetched from the node",
+		Destination: &blockFileValue,
+	}
+
+	powAlgorithmValue string
+	powAlgorithmFlag  = cli.StringFlag{
+		Name:        "algorithm",
+		Usage:       "pow algorithm of the block, [zpow, sha256]",
+		Value:       "zpow",
+		Destination: &powAlgorithmValue,
+	}
+
+	batchFileValue string
+	batchFileFlag  = cli.StringFlag{
+		Name:        "file",
+		Usage:       "csv file of (to, amount) rows, amount unit is wen",
+		Destination: &batchFileValue,
+	}
+
+	keyFileValue string
+	keyFileFlag  = cli.StringFlag{
+		Name:        "key",
+		Usage:       "key file of the sender",
+		Destination: &keyFileValue,
+	}
+
+	rateValue uint
+	rateFlag  = cli.UintFlag{
+		Name:        "rate",
+		Value:       10,
+		Usage:       "max transactions sent per second, 0 means no limit",
+		Destination: &rateValue,
+	}
+
+	cursorValue string
+	cursorFlag  = cli.StringFlag{
+		Name:        "cursor",
+		Value:       "",
+		Usage:       "cursor returned by the previous page, empty for the first page",
+		Destination: &cursorValue,
+	}
+
+	limitValue uint
+	limitFlag  = cli.UintFlag{
+		Name:        "limit",
+		Value:       100,
+		Usage:       "max transactions in the page",
+		Destination: &limitValue,
+	}
+
+	poolShardValue uint
+	poolShardFlag  = cli.UintFlag{
+		Name:        "shard",
+		Value:       0,
+		Usage:       "shard number of the sender, 0 means all shards",
+		Destination: &poolShardValue,
+	}
+
+	minPriceValue string
+	minPriceFlag  = cli.StringFlag{
+		Name:        "minprice",
+		Value:       "",
+		Usage:       "min gas price in Wen, empty means no limit",
+		Destination: &minPriceValue,
+	}
+
+	maxPriceValue string
+	maxPriceFlag  = cli.StringFlag{
+		Name:        "maxprice",
+		Value:       "",
+		Usage:       "max gas price in Wen, empty means no limit",
+		Destination: &maxPriceValue,
+	}
+
+	fromAddressValue string
+	fromAddressFlag  = cli.StringFlag{
+		Name:        "from",
+		Usage:       "sender address, or alias name in the address book",
+		Destination: &fromAddressValue,
+	}
+
+	dustRatioValue float64
+	dustRatioFlag  = cli.Float64Flag{
+		Name:        "dustratio",
+		Value:       10,
+		Usage:       "warn if the amount is less than the ratio times of the total fee",
+		Destination: &dustRatioValue,
+	}
 )
 
 // GeneratePayload
@@ -319,6 +628,11 @@ var (
 		Destination: &eventName,
 	}
 
+	accountsFlag = cli.StringSliceFlag{
+		Name:  "accounts",
+		Usage: "the public keys or addresses, and a public key is queried in all shards",
+	}
+
 	// args     []interface{}
 	argsFlag = cli.StringSliceFlag{
 		Name:  "args",
@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/howeyc/gopass"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/keystore"
+	"github.com/urfave/cli"
+)
+
+// KeyExportAction exports the key files in a folder to an encrypted backup file
+func KeyExportAction(c *cli.Context) error {
+	if keyDirValue == "" || backupToValue == "" {
+		return fmt.Errorf("usage: key export --dir <key folder> --to <backup file>")
+	}
+
+	if common.FileOrFolderExists(backupToValue) {
+		return fmt.Errorf("backup file %s already exists", backupToValue)
+	}
+
+	backup, err := keystore.ExportKeys(keyDirValue)
+	if err != nil {
+		return fmt.Errorf("failed to export key files: %s", err)
+	}
+
+	if len(backup.Keys) == 0 {
+		return fmt.Errorf("no key file found in %s", keyDirValue)
+	}
+
+	fmt.Printf("Exporting %d key files, please set the backup password\n", len(backup.Keys))
+	pass, err := common.SetPassword()
+	if err != nil {
+		return fmt.Errorf("failed to get password %s", err)
+	}
+
+	content, err := keystore.EncryptBackup(backup, pass)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt backup: %s", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(backupToValue), 0700); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(backupToValue, content, 0600); err != nil {
+		return err
+	}
+
+	for _, key := range backup.Keys {
+		fmt.Printf("%s\t%s\tshard %d\n", key.File, key.Address, key.Shard)
+	}
+
+	fmt.Printf("%d key files exported to %s\n", len(backup.Keys), backupToValue)
+
+	return nil
+}
+
+// KeyImportAction imports the key files from an encrypted backup file to a folder
+func KeyImportAction(c *cli.Context) error {
+	if keyDirValue == "" || backupFromValue == "" {
+		return fmt.Errorf("usage: key import --from <backup file> --dir <key folder> [--conflict skip|overwrite|rename]")
+	}
+
+	content, err := ioutil.ReadFile(backupFromValue)
+	if err != nil {
+		return fmt.Errorf("failed to read backup file: %s", err)
+	}
+
+	fmt.Printf("Please input your backup password: ")
+	pass, err := gopass.GetPasswd()
+	if err != nil {
+		return fmt.Errorf("failed to get password %s", err)
+	}
+
+	backup, err := keystore.DecryptBackup(content, string(pass))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt backup: %s", err)
+	}
+
+	results, err := keystore.ImportKeys(backup, keyDirValue, conflictValue)
+	for _, result := range results {
+		fmt.Printf("%s\t%s\tshard %d\t%s\t%s\n", result.File, result.Address, result.Shard, result.Status, result.Path)
+	}
+
+	return err
+}
@@ -221,6 +221,7 @@ func onTxAdded(inputs []interface{}, result interface{}) error {
 		"Hash":      tx.Hash,
 		"Data":      txData,
 		"Signature": tx.Signature,
+		"Decoded":   decodeTransaction(&tx.Data, "", nil),
 	}
 	encoded, err := json.MarshalIndent(txOutput, "", "\t")
 	if err != nil {
@@ -0,0 +1,60 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/contract/system"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/rpc"
+)
+
+// registerContractMetadata registers the metadata of the contract deployed by the sender
+func registerContractMetadata(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+	contract, err := common.HexToAddress(contractValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid contract address: %s", err)
+	}
+
+	metadata := &system.ContractMetadata{
+		Contract:    contract,
+		DeployNonce: deployNonceValue,
+		Name:        nameValue,
+		SourceURI:   sourceURIValue,
+		Version:     versionValue,
+	}
+
+	if len(abiFile) > 0 {
+		abiJSON, err := readABIFile(abiFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		metadata.ABIHash = crypto.HashBytes([]byte(abiJSON))
+	}
+
+	if err = metadata.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	encoded, err := common.Serialize(metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := sendSystemContractTx(client, system.ContractRegistryContractAddress, system.CmdRegisterContractMetadata, encoded)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output := make(map[string]interface{})
+	output["Tx"] = *tx
+	output["metadata"] = metadata
+	return output, tx, err
+}
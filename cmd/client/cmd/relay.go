@@ -0,0 +1,108 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/common/keystore"
+	"github.com/scdoproject/go-scdo/contract/system"
+	"github.com/scdoproject/go-scdo/rpc"
+	"github.com/urfave/cli"
+)
+
+// signMetaTx signs a meta tx by the key file of the signer, which could be relayed by others who pay the gas
+func signMetaTx(c *cli.Context) error {
+	pass, err := common.GetPassword()
+	if err != nil {
+		return fmt.Errorf("failed to get password %s", err)
+	}
+
+	key, err := keystore.GetKey(fromValue, pass)
+	if err != nil {
+		return fmt.Errorf("invalid signer key file. it should be a private key: %s", err)
+	}
+
+	to, err := common.HexToAddress(toValue)
+	if err != nil {
+		return fmt.Errorf("invalid receiver address: %s", err)
+	}
+
+	amount, ok := big.NewInt(0).SetString(amountValue, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount value")
+	}
+
+	payload := []byte{}
+	if len(payloadValue) > 0 {
+		if payload, err = hexutil.HexToBytes(payloadValue); err != nil {
+			return fmt.Errorf("invalid payload, %s", err)
+		}
+	}
+
+	signed, err := system.NewSignedMetaTx(key.PrivateKey, &system.MetaTx{
+		From:    key.Address,
+		To:      to,
+		Amount:  amount,
+		Payload: payload,
+		Nonce:   nonceValue,
+		Expiry:  uint64(time.Now().Unix() + timeLockValue),
+		ChainID: chainIDValue,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded, err := common.Serialize(signed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("metatx:", hexutil.BytesToHex(encoded))
+	return nil
+}
+
+// relayMetaTx sends the signed meta tx to the relay contract, the gas is paid by the sender
+func relayMetaTx(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+	metaTxBytes, err := hexutil.HexToBytes(metaTxValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to convert Hex to Bytes %s", err)
+	}
+
+	tx, err := sendSystemContractTx(client, system.MetaTxRelayContractAddress, system.CmdRelayMetaTx, metaTxBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output := make(map[string]interface{})
+	output["Tx"] = *tx
+	output["metatx"] = metaTxValue
+	return output, tx, err
+}
+
+// getRelayNonce gets the next meta tx nonce of the account
+func getRelayNonce(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+	account, err := common.HexToAddress(accountValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid account address: %s", err)
+	}
+
+	tx, err := sendSystemContractTx(client, system.MetaTxRelayContractAddress, system.CmdGetRelayNonce, account.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output := make(map[string]interface{})
+	output["Tx"] = *tx
+	output["account"] = accountValue
+	return output, tx, err
+}
@@ -23,9 +23,22 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		{
 			Name:   "sendtx",
 			Usage:  "send transaction to node",
-			Flags:  rpcFlags(fromFlag, toFlag, shardFlag, amountFlag, priceFlag, gasLimitFlag, payloadFlag, nonceFlag),
+			Flags:  rpcFlags(fromFlag, toFlag, aliasFileFlag, shardFlag, amountFlag, priceFlag, gasLimitFlag, payloadFlag, nonceFlag),
 			Action: rpcActionEx("scdo", "addTx", makeTransaction, onTxAdded),
 		},
+		{
+			Name:   "sendbatch",
+			Usage:  "send transactions in batch from a csv file of (to, amount) rows with consecutive nonces",
+			Flags:  rpcFlags(batchFileFlag, keyFileFlag, priceFlag, gasLimitFlag, nonceFlag, rateFlag),
+			Action: sendBatchAction,
+		},
+		{
+			Name:    "estimatecrossshard",
+			Aliases: []string{"estimate-crossshard"},
+			Usage:   "estimate the gas, fees and confirmation blocks of a cross shard transfer",
+			Flags:   []cli.Flag{fromAddressFlag, toFlag, aliasFileFlag, amountFlag, priceFlag, dustRatioFlag},
+			Action:  estimateCrossShardAction,
+		},
 		{
 			Name:   "getnonce",
 			Usage:  "get account nonce",
@@ -56,6 +69,54 @@ func AddCommands(app *cli.App, isFullNode bool) {
 			Flags:  rpcFlags(hashFlag, heightFlag, fulltxFlag),
 			Action: rpcAction("scdo", "getBlock"),
 		},
+		{
+			Name:   "getpendingblock",
+			Usage:  "get the block being mined by the miner with the chosen txs and debts, and the projected receipts if fulltx",
+			Flags:  rpcFlags(fulltxFlag),
+			Action: rpcAction("scdo", "getPendingBlock"),
+		},
+		{
+			Name:   "getrawblock",
+			Usage:  "get the raw block by hash with its receipts, which could be verified by verifyblock",
+			Flags:  rpcFlags(hashFlag),
+			Action: rpcAction("scdo", "getRawBlock"),
+		},
+		{
+			Name:   "verifyblock",
+			Usage:  "verify the header hash, merkle roots and pow of the block in the file or of the hash on the node locally",
+			Flags:  rpcFlags(blockFileFlag, hashFlag, powAlgorithmFlag),
+			Action: VerifyBlockAction,
+		},
+		{
+			Name:   "getheader",
+			Usage:  "get block header by height or hash without the block body",
+			Flags:  rpcFlags(hashFlag, heightFlag),
+			Action: rpcAction("scdo", "getHeader"),
+		},
+		{
+			Name:   "getrewardschedule",
+			Usage:  "get the block reward of each era and the max supply of the schedule",
+			Flags:  rpcFlags(),
+			Action: rpcAction("scdo", "getRewardSchedule"),
+		},
+		{
+			Name:   "getblockreward",
+			Usage:  "get the block reward and projected supply at the height",
+			Flags:  rpcFlags(heightFlag),
+			Action: rpcAction("scdo", "getBlockReward"),
+		},
+		{
+			Name:   "getsignaltally",
+			Usage:  "tally the signaling in block header extra data of recent blocks",
+			Flags:  rpcFlags(signalBlocksFlag),
+			Action: rpcAction("scdo", "getSignalTally"),
+		},
+		{
+			Name:   "getchainstats",
+			Usage:  "get the statistics of block interval, difficulty, txs, debts and gas usage of recent blocks",
+			Flags:  rpcFlags(statsBlocksFlag),
+			Action: rpcAction("scdo", "getChainStats"),
+		},
 		{
 			Name:   "gettxpoolcontent",
 			Usage:  "get transaction pool contents",
@@ -68,6 +129,18 @@ func AddCommands(app *cli.App, isFullNode bool) {
 			Flags:  rpcFlags(),
 			Action: rpcAction("txpool", "getTxPoolTxCount"),
 		},
+		{
+			Name:   "gettxpoolpage",
+			Usage:  "get a page of transaction pool contents filtered by account, shard and gas price",
+			Flags:  rpcFlags(cursorFlag, limitFlag, accountFlag, poolShardFlag, minPriceFlag, maxPriceFlag),
+			Action: rpcAction("txpool", "getTxPoolContentPage"),
+		},
+		{
+			Name:   "gettxpoolsummary",
+			Usage:  "get transaction pool transaction counts per shard and gas price histogram",
+			Flags:  rpcFlags(),
+			Action: rpcAction("txpool", "getTxPoolSummary"),
+		},
 		{
 			Name:   "getblocktxcount",
 			Usage:  "get block transaction count by block height or block hash",
@@ -94,8 +167,8 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		},
 		{
 			Name:   "getaccounttx",
-			Usage:  "get transaction of one account at specific height or blockhash",
-			Flags:  rpcFlags(accountFlag, hashFlag, heightFlag),
+			Usage:  "get transaction of one account at specific height or blockhash, and optionally the debts credited to it",
+			Flags:  rpcFlags(accountFlag, hashFlag, heightFlag, includeDebtsFlag),
 			Action: rpcAction("scdo", "getAccountTransactions"),
 		},
 		{
@@ -118,9 +191,9 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		},
 		{
 			Name:   "gettxbyhash",
-			Usage:  "get transaction by transaction hash",
-			Flags:  rpcFlags(hashFlag),
-			Action: rpcAction("txpool", "getTransactionByHash"),
+			Usage:  "get transaction by transaction hash, and decode its amounts, fee and payload",
+			Flags:  rpcFlags(hashFlag, abiFileFlag, abiDirFlag),
+			Action: GetTxByHashAction,
 		},
 		{
 			Name:   "getgasprice",
@@ -134,12 +207,36 @@ func AddCommands(app *cli.App, isFullNode bool) {
 			Flags:  rpcFlags(hashFlag, abiFileFlag),
 			Action: rpcAction("scdo", "getReceiptByTxHash"),
 		},
+		{
+			Name:   "getreceiptproof",
+			Usage:  "get receipt with the block header and merkle proofs by transaction hash",
+			Flags:  rpcFlags(hashFlag),
+			Action: rpcAction("scdo", "getReceiptProof"),
+		},
 		{
 			Name:   "getpendingtxs",
 			Usage:  "get pending transactions",
 			Flags:  rpcFlags(),
 			Action: rpcAction("txpool", "getPendingTransactions"),
 		},
+		{
+			Name:   "getqueuedtxs",
+			Usage:  "get the transactions with nonce gaps queued in the transaction pool",
+			Flags:  rpcFlags(),
+			Action: rpcAction("txpool", "getQueuedTransactions"),
+		},
+		{
+			Name:   "gettxpoolstatus",
+			Usage:  "get the number of the pending and queued transactions in the transaction pool",
+			Flags:  rpcFlags(),
+			Action: rpcAction("txpool", "getTxPoolStatus"),
+		},
+		{
+			Name:   "computecontractaddress",
+			Usage:  "compute the contract address deployed by CREATE2 with deployer, salt and init code hash",
+			Flags:  rpcFlags(accountFlag, saltFlag, codeHashFlag),
+			Action: rpcAction("scdo", "computeContractAddress"),
+		},
 		{
 			Name:  "getshardnum",
 			Usage: "get account shard number",
@@ -166,6 +263,7 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				shardFlag,
 				privateKeyFlag,
 				toFlag,
+				aliasFileFlag,
 				amountFlag,
 				priceFlag,
 				gasLimitFlag,
@@ -181,6 +279,20 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				shardFlag,
 			},
 			Action: GenerateKeyAction,
+			Subcommands: []cli.Command{
+				{
+					Name:   "export",
+					Usage:  "export the key files in a folder to an encrypted backup file with their shards",
+					Flags:  []cli.Flag{keyDirFlag, backupToFlag},
+					Action: KeyExportAction,
+				},
+				{
+					Name:   "import",
+					Usage:  "import the key files from an encrypted backup file to a folder",
+					Flags:  []cli.Flag{backupFromFlag, keyDirFlag, conflictFlag},
+					Action: KeyImportAction,
+				},
+			},
 		},
 		{
 			Name:  "payload",
@@ -200,6 +312,53 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		},
 	}
 
+	aliasCommands := cli.Command{
+		Name:  "alias",
+		Usage: "address book commands, the alias name can be used as the receiver by --to",
+		Subcommands: []cli.Command{
+			{
+				Name:      "add",
+				Usage:     "add the alias name of an address",
+				ArgsUsage: "<name> <address>",
+				Flags:     []cli.Flag{aliasFileFlag},
+				Action:    AliasAddAction,
+			},
+			{
+				Name:      "remove",
+				Usage:     "remove the alias name",
+				ArgsUsage: "<name>",
+				Flags:     []cli.Flag{aliasFileFlag},
+				Action:    AliasRemoveAction,
+			},
+			{
+				Name:   "list",
+				Usage:  "list the alias names and addresses",
+				Flags:  []cli.Flag{aliasFileFlag},
+				Action: AliasListAction,
+			},
+		},
+	}
+
+	abiCommands := cli.Command{
+		Name:  "abi",
+		Usage: "local abi registry commands, the registered abi is used to decode the txs to the contract",
+		Subcommands: []cli.Command{
+			{
+				Name:      "register",
+				Usage:     "register the abi of a contract",
+				ArgsUsage: "<contract> <abi file>",
+				Flags:     []cli.Flag{abiDirFlag},
+				Action:    ABIRegisterAction,
+			},
+			{
+				Name:   "list",
+				Usage:  "list the contracts whose abi is registered",
+				Flags:  []cli.Flag{abiDirFlag},
+				Action: ABIListAction,
+			},
+		},
+	}
+
 	htlcCommands := cli.Command{
 		Name:  "htlc",
 		Usage: "Hash time lock contract commands",
@@ -271,6 +430,69 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		},
 	}
 
+	relayCommands := cli.Command{
+		Name:  "relay",
+		Usage: "meta tx relay contract commands",
+		Subcommands: []cli.Command{
+			{
+				Name:   "sign",
+				Usage:  "sign a meta tx, the nonce is the meta tx nonce and the time is the valid duration in seconds",
+				Flags:  []cli.Flag{fromFlag, toFlag, amountFlag, payloadFlag, nonceFlag, timeLockFlag, chainIDFlag},
+				Action: signMetaTx,
+			},
+			{
+				Name:   "send",
+				Usage:  "relay a signed meta tx and pay the gas",
+				Flags:  rpcFlags(fromFlag, priceFlag, gasLimitFlag, nonceFlag, metaTxFlag),
+				Action: rpcActionSystemContract("relay", "send", handleCallResult),
+			},
+			{
+				Name:   "nonce",
+				Usage:  "get the next meta tx nonce of the account",
+				Flags:  rpcFlags(fromFlag, accountFlag),
+				Action: rpcActionSystemContract("relay", "nonce", handleCallResult),
+			},
+		},
+	}
+
+	registryCommands := cli.Command{
+		Name:  "registry",
+		Usage: "contract metadata registry commands",
+		Subcommands: []cli.Command{
+			{
+				Name:   "register",
+				Usage:  "register or update the metadata of the contract deployed by the sender with the deploy nonce",
+				Flags:  rpcFlags(fromFlag, priceFlag, gasLimitFlag, nonceFlag, contractFlag, deployNonceFlag, nameFlag, abiFileFlag, sourceURIFlag, versionFlag),
+				Action: rpcActionSystemContract("registry", "register", handleCallResult),
+			},
+			{
+				Name:   "get",
+				Usage:  "get the registered metadata of the contract",
+				Flags:  rpcFlags(contractFlag),
+				Action: rpcAction("scdo", "getContractMetadata"),
+			},
+		},
+	}
+
+	aclCommands := cli.Command{
+		Name:  "acl",
+		Usage: "access control commands of contract deployment and calls in the BFT networks",
+		Subcommands: []cli.Command{
+			{
+				Name:   "vote",
+				Usage:  "vote for an access rule by a validator, the empty account sets the default permission",
+				Flags:  rpcFlags(fromFlag, priceFlag, gasLimitFlag, nonceFlag, accessActionFlag, contractFlag, accountFlag, permissionFlag),
+				Action: rpcActionSystemContract("acl", "vote", handleCallResult),
+			},
+			{
+				Name:   "get",
+				Usage:  "get the permission of an access rule",
+				Flags:  rpcFlags(fromFlag, accessActionFlag, contractFlag, accountFlag),
+				Action: rpcActionSystemContract("acl", "get", handleCallResult),
+			},
+		},
+	}
+
 	subChainCommands := cli.Command{
 		Name:  "subchain",
 		Usage: "system sub chain commands",
@@ -318,6 +540,12 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  rpcFlags(),
 				Action: rpcAction("network", "getPeersInfo"),
 			},
+			{
+				Name:   "lightserverstats",
+				Usage:  "get light server load statistics and request accounting of light clients",
+				Flags:  rpcFlags(),
+				Action: rpcAction("light", "serverStats"),
+			},
 			{
 				Name:   "netversion",
 				Usage:  "get current net version",
@@ -360,6 +588,73 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  rpcFlags(),
 				Action: rpcAction("network", "getBlockListCount"),
 			},
+			{
+				Name:   "blockip",
+				Usage:  "block the IP or CIDR range for both the discovery and peer connections",
+				Flags:  rpcFlags(cidrFlag, blockDurationFlag),
+				Action: rpcAction("p2p", "blockIP"),
+			},
+			{
+				Name:   "unblockip",
+				Usage:  "remove the IP or CIDR range from the block list",
+				Flags:  rpcFlags(cidrFlag),
+				Action: rpcAction("p2p", "unblockIP"),
+			},
+			{
+				Name:   "listblocked",
+				Usage:  "list the blocked IPs and CIDR ranges",
+				Flags:  rpcFlags(),
+				Action: rpcAction("p2p", "listBlocked"),
+			},
+			{
+				Name:   "trafficstats",
+				Usage:  "get the bytes and messages in and out by protocol message, of the node and each peer",
+				Flags:  rpcFlags(),
+				Action: rpcAction("p2p", "trafficStats"),
+			},
+			{
+				Name:   "subnetdistribution",
+				Usage:  "get the number of the connected peers by subnet",
+				Flags:  rpcFlags(),
+				Action: rpcAction("p2p", "subnetDistribution"),
+			},
+		},
+	}
+
+	adminCommands := cli.Command{
+		Name:  "admin",
+		Usage: "admin commands",
+		Subcommands: []cli.Command{
+			{
+				Name:   "reloadconfig",
+				Usage:  "reload the non-consensus config from the node config file without restart",
+				Flags:  rpcFlags(),
+				Action: rpcAction("admin", "reloadConfig"),
+			},
+			{
+				Name:   "health",
+				Usage:  "get the health of the node, including the sync status, peers, database, miner and head age",
+				Flags:  rpcFlags(),
+				Action: rpcAction("admin", "health"),
+			},
+			{
+				Name:   "promote",
+				Usage:  "promote the follower node to active, and start the miner with the configured coinbase",
+				Flags:  rpcFlags(),
+				Action: rpcAction("admin", "promote"),
+			},
+			{
+				Name:   "isfollower",
+				Usage:  "check whether the node is a follower not promoted to active yet",
+				Flags:  rpcFlags(),
+				Action: rpcAction("admin", "isFollower"),
+			},
+			{
+				Name:   "allowdeepreorgonce",
+				Usage:  "allow the next chain reorg deeper than the max reorg depth",
+				Flags:  rpcFlags(),
+				Action: rpcAction("admin", "allowDeepReorgOnce"),
+			},
 		},
 	}
 
@@ -391,18 +686,48 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  rpcFlags(coinbaseFlag),
 				Action: rpcAction("miner", "setCoinbase"),
 			},
+			{
+				Name:   "setcoinbaseweight",
+				Usage:  "set the weight and the daily quota of the coinbase in pool mode",
+				Flags:  rpcFlags(coinbaseFlag, weightFlag, quotaFlag),
+				Action: rpcAction("miner", "setCoinbaseWeight"),
+			},
+			{
+				Name:   "getcoinbaseweights",
+				Usage:  "get the weights, the daily quotas and the blocks mined today of the coinbases in pool mode",
+				Flags:  rpcFlags(),
+				Action: rpcAction("miner", "getCoinbaseWeights"),
+			},
 			{
 				Name:   "getcoinbase",
 				Usage:  "get miner coinbase",
 				Flags:  rpcFlags(),
 				Action: rpcAction("miner", "getCoinbase"),
 			},
+			{
+				Name:   "setextra",
+				Usage:  "set block header extra data for signaling",
+				Flags:  rpcFlags(extraFlag),
+				Action: rpcAction("miner", "setExtra"),
+			},
+			{
+				Name:   "getextra",
+				Usage:  "get block header extra data for signaling",
+				Flags:  rpcFlags(),
+				Action: rpcAction("miner", "getExtra"),
+			},
 			{
 				Name:   "status",
 				Usage:  "get miner status",
 				Flags:  rpcFlags(),
 				Action: rpcAction("miner", "status"),
 			},
+			{
+				Name:   "warmupstatus",
+				Usage:  "get the decision of the miner warmup after the last sync",
+				Flags:  rpcFlags(),
+				Action: rpcAction("miner", "getWarmupStatus"),
+			},
 			{
 				Name:   "detrate",
 				Usage:  "get detrate",
@@ -415,6 +740,18 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  rpcFlags(),
 				Action: rpcAction("miner", "getThreads"),
 			},
+			{
+				Name:   "getalgorithmparams",
+				Usage:  "get the zpow algorithm params, e.g. the matrix dimension, activated at the block height",
+				Flags:  rpcFlags(heightFlag),
+				Action: rpcAction("miner", "getAlgorithmParams"),
+			},
+			{
+				Name:   "estimatetimetoblock",
+				Usage:  "estimate the time for the hashrate to seal the next block, and its share of the shard",
+				Flags:  rpcFlags(hashrateFlag),
+				Action: rpcAction("miner", "estimateTimeToBlock"),
+			},
 			{
 				Name:   "getwork",
 				Usage:  "get miner current mining task",
@@ -433,6 +770,18 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  rpcFlags(heightPosFlag, miningNonceFlag),
 				Action: rpcAction("scdo", "submitNonce"),
 			},
+			{
+				Name:   "getworkleases",
+				Usage:  "get the nonce ranges of the current mining task leased to the workers",
+				Flags:  rpcFlags(),
+				Action: rpcAction("miner", "getWorkLeases"),
+			},
+			{
+				Name:   "getorphanstats",
+				Usage:  "get the number and the rate of the mined blocks which failed to become canonical",
+				Flags:  rpcFlags(),
+				Action: rpcAction("miner", "getOrphanStats"),
+			},
 		},
 	}
 
@@ -445,18 +794,78 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  rpcFlags(),
 				Action: rpcAction("scdo", "getInfo"),
 			},
+			{
+				Name:   "getdifficulty",
+				Usage:  "get the block difficulty and the expected nonce attempts to seal a block of the difficulty",
+				Flags:  rpcFlags(heightFlag),
+				Action: rpcAction("scdo", "getDifficulty"),
+			},
+			{
+				Name:   "getdifficultyrange",
+				Usage:  "get the difficulty and total difficulty of the canonical blocks in the height range, at most 10000 blocks",
+				Flags:  rpcFlags(fromHeightFlag, toHeightFlag),
+				Action: rpcAction("scdo", "getDifficultyRange"),
+			},
+			{
+				Name:   "getlogsbyindex",
+				Usage:  "get the logs of the block by the log index range in the block with their global log index, at most 10000 logs",
+				Flags:  rpcFlags(hashFlag, fromLogIndexFlag, toLogIndexFlag),
+				Action: rpcAction("scdo", "getLogsByIndex"),
+			},
 			{
 				Name:   "getdebts",
 				Usage:  "get pending debts",
 				Flags:  rpcFlags(),
 				Action: rpcAction("txpool", "getPendingDebts"),
 			},
+			{
+				Name:   "getadmissionrejections",
+				Usage:  "get the local admission policies of the tx pool and the number of txs rejected by each policy",
+				Flags:  rpcFlags(),
+				Action: rpcAction("txpool", "getAdmissionRejections"),
+			},
+			{
+				Name:   "getdependencygraph",
+				Usage:  "get the nonce chains of the accounts in the tx pool and the ordering constraints across the accounts",
+				Flags:  rpcFlags(accountFlag),
+				Action: rpcAction("txpool", "getDependencyGraph"),
+			},
 			{
 				Name:   "dumpheap",
 				Usage:  "dump heap for profiling, return the file path",
 				Flags:  rpcFlags(dumpFileFlag, gcBeforeDumpFlag),
 				Action: rpcAction("debug", "dumpHeap"),
 			},
+			{
+				Name:   "getdebtshardstatus",
+				Usage:  "get the propagation status of the debts to each target shard, and whether the shard is unreachable",
+				Flags:  rpcFlags(),
+				Action: rpcAction("debug", "getDebtShardStatus"),
+			},
+			{
+				Name:   "traceblock",
+				Usage:  "re-execute the block on the state of its parent block and return the traces of the txs",
+				Flags:  rpcFlags(heightFlag, tracerFlag),
+				Action: rpcAction("debug", "traceBlockByNumber"),
+			},
+			{
+				Name:   "tracetx",
+				Usage:  "re-execute the block which packs the tx on the state of its parent block and return the trace of the tx",
+				Flags:  rpcFlags(hashFlag, tracerFlag),
+				Action: rpcAction("debug", "traceTransaction"),
+			},
+			{
+				Name:   "validateblock",
+				Usage:  "validate and execute the block on the state of its parent block without writing it, return the receipts, state root and errors",
+				Flags:  rpcFlags(blockDataFlag),
+				Action: rpcAction("debug", "validateBlock"),
+			},
+			{
+				Name:   "slowtxreport",
+				Usage:  "get the recent txs whose execution exceeds the threshold when the blocks are written",
+				Flags:  rpcFlags(),
+				Action: rpcAction("debug", "slowTxReport"),
+			},
 			{
 				Name:   "call",
 				Usage:  "call contract",
@@ -475,16 +884,55 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  rpcFlags(hashFlag),
 				Action: rpcAction("txpool", "getDebtByHash"),
 			},
+			{
+				Name:   "getexpireddebts",
+				Usage:  "get the debts expired without being packed and not requeued",
+				Flags:  rpcFlags(),
+				Action: rpcAction("txpool", "getExpiredDebts"),
+			},
+			{
+				Name:   "getcirculatingsupply",
+				Usage:  "get the circulating supply of the shard computed from the genesis allocation and reward schedule",
+				Flags:  rpcFlags(),
+				Action: rpcAction("scdo", "getCirculatingSupply"),
+			},
+			{
+				Name:   "getaggregatebalance",
+				Usage:  "get the balances of the public keys or addresses in all shards and the total",
+				Flags:  rpcFlags(accountsFlag),
+				Action: rpcAction("scdo", "getAggregateBalance"),
+			},
+			{
+				Name:   "getcrossshardtxstatus",
+				Usage:  "get the end-to-end status of a cross shard transaction by transaction hash",
+				Flags:  rpcFlags(hashFlag),
+				Action: rpcAction("scdo", "getCrossShardTxStatus"),
+			},
+			{
+				Name:   "getshardheads",
+				Usage:  "get the best-known chain head of each shard observed from the peers",
+				Flags:  rpcFlags(),
+				Action: rpcAction("scdo", "getShardHeads"),
+			},
+			{
+				Name:   "isdebtspent",
+				Usage:  "check whether the debt is already applied in the chain by debt hash",
+				Flags:  rpcFlags(hashFlag),
+				Action: rpcAction("scdo", "isDebtSpent"),
+			},
 		}...)
 
 		baseCommands = append(baseCommands,
 			htlcCommands,
 			domainCommands,
 			subChainCommands,
+			relayCommands,
+			registryCommands,
+			aclCommands,
 			minerCommands)
 	}
 
-	baseCommands = append(baseCommands, p2pCommands)
+	baseCommands = append(baseCommands, aliasCommands, abiCommands, p2pCommands, adminCommands)
 
 	app.Commands = baseCommands
 
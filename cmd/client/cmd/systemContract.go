@@ -37,6 +37,17 @@ var (
 			"register": registerSubChain,
 			"query":    querySubChain,
 		},
+		"relay": map[string]handler{
+			"send":  relayMetaTx,
+			"nonce": getRelayNonce,
+		},
+		"registry": map[string]handler{
+			"register": registerContractMetadata,
+		},
+		"acl": map[string]handler{
+			"vote": voteAccessRule,
+			"get":  getAccessRule,
+		},
 	}
 
 	// if the method have key-value, use the call method to get receipt
@@ -44,6 +55,12 @@ var (
 		"htlc": map[string]string{
 			"get": "1",
 		},
+		"relay": map[string]string{
+			"nonce": "1",
+		},
+		"acl": map[string]string{
+			"get": "1",
+		},
 	}
 )
 
@@ -0,0 +1,183 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus/pow"
+	"github.com/scdoproject/go-scdo/consensus/zpow/verifier"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/rpc"
+	"github.com/urfave/cli"
+)
+
+var errBlockVerifyFailed = errors.New("block verification failed")
+
+// rawBlock is the block with its receipts, which is the output of the getrawblock command
+type rawBlock struct {
+	Block    *types.Block
+	Receipts []*types.Receipt
+}
+
+// blockCheck is the result of a check of the block, the check is skipped if err is nil and skipped is set
+type blockCheck struct {
+	name    string
+	skipped string
+	err     error
+}
+
+func (c *blockCheck) String() string {
+	switch {
+	case c.err != nil:
+		return fmt.Sprintf("%-16s FAILED, %s", c.name, c.err)
+	case len(c.skipped) > 0:
+		return fmt.Sprintf("%-16s SKIPPED, %s", c.name, c.skipped)
+	default:
+		return fmt.Sprintf("%-16s OK", c.name)
+	}
+}
+
+// VerifyBlockAction verifies the block in the file or the block of the hash on the node locally
+func VerifyBlockAction(c *cli.Context) error {
+	raw, err := loadRawBlock()
+	if err != nil {
+		return err
+	}
+
+	if raw.Block == nil || raw.Block.Header == nil {
+		return errors.New("block not found")
+	}
+
+	fmt.Printf("block %s, height %d\n", raw.Block.HeaderHash.Hex(), raw.Block.Header.Height)
+
+	failed := false
+	for _, check := range verifyBlock(raw.Block, raw.Receipts, powAlgorithmValue) {
+		fmt.Println(check)
+		failed = failed || check.err != nil
+	}
+
+	if failed {
+		return errBlockVerifyFailed
+	}
+
+	return nil
+}
+
+// loadRawBlock loads the raw block from the file if specified, otherwise gets the raw block of the hash from the node
+func loadRawBlock() (*rawBlock, error) {
+	var raw rawBlock
+
+	if len(blockFileValue) > 0 {
+		data, err := ioutil.ReadFile(blockFileValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid block file, %s", err)
+		}
+
+		// the file could be a bare block without receipts
+		if raw.Block == nil {
+			raw.Block = new(types.Block)
+			if err = json.Unmarshal(data, raw.Block); err != nil {
+				return nil, fmt.Errorf("invalid block file, %s", err)
+			}
+		}
+
+		return &raw, nil
+	}
+
+	if len(hashValue) == 0 {
+		return nil, errors.New("either the block file or the block hash is required")
+	}
+
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = client.Call(&raw, "scdo_getRawBlock", hashValue); err != nil {
+		return nil, fmt.Errorf("Failed to call rpc, %s", err)
+	}
+
+	return &raw, nil
+}
+
+// verifyBlock recomputes the header hash, the merkle roots and the pow target of the block,
+// the receipts root is not verified if receipts is nil, e.g. pruned on the node.
+func verifyBlock(block *types.Block, receipts []*types.Receipt, algorithm string) []*blockCheck {
+	header := block.Header
+
+	checks := []*blockCheck{
+		hashCheck("header hash", block.HeaderHash, header.Hash()),
+		txHashesCheck(block.Transactions),
+		hashCheck("tx root", header.TxHash, types.MerkleRootHash(block.Transactions)),
+		hashCheck("tx debt root", header.TxDebtHash, types.DebtMerkleRootHash(types.NewDebts(block.Transactions))),
+		hashCheck("debt root", header.DebtHash, types.DebtMerkleRootHash(block.Debts)),
+	}
+
+	if receipts == nil {
+		checks = append(checks, &blockCheck{name: "receipt root", skipped: "receipts not available"})
+	} else {
+		checks = append(checks, hashCheck("receipt root", header.ReceiptHash, types.ReceiptMerkleRootHash(receipts)))
+	}
+
+	return append(checks, powCheck(header, algorithm))
+}
+
+func hashCheck(name string, expected, computed common.Hash) *blockCheck {
+	check := &blockCheck{name: name}
+	if !expected.Equal(computed) {
+		check.err = fmt.Errorf("header %s, computed %s", expected.Hex(), computed.Hex())
+	}
+
+	return check
+}
+
+// txHashesCheck verifies the hash of each tx, since the tx root is computed with the tx hashes
+func txHashesCheck(txs []*types.Transaction) *blockCheck {
+	check := &blockCheck{name: "tx hashes"}
+	for _, tx := range txs {
+		if computed := tx.CalculateHash(); !tx.Hash.Equal(computed) {
+			check.err = fmt.Errorf("tx %s, computed %s", tx.Hash.Hex(), computed.Hex())
+			break
+		}
+	}
+
+	return check
+}
+
+func powCheck(header *types.BlockHeader, algorithm string) *blockCheck {
+	check := &blockCheck{name: "pow target"}
+
+	if header.Height == 0 {
+		check.skipped = "genesis block"
+		return check
+	}
+
+	if header.Consensus == types.IstanbulConsensus {
+		check.skipped = "istanbul consensus"
+		return check
+	}
+
+	switch algorithm {
+	case common.ZpowAlgorithm:
+		check.err = verifier.VerifyTarget(common.DefaultChainConfig(), header)
+	case common.Sha256Algorithm:
+		check.err = pow.VerifyTarget(header)
+	default:
+		check.skipped = fmt.Sprintf("unsupported algorithm %s", algorithm)
+	}
+
+	return check
+}
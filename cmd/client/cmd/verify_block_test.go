@@ -0,0 +1,100 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVerifyTestBlock() (*types.Block, []*types.Receipt) {
+	header := &types.BlockHeader{
+		Height:          1,
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(1),
+	}
+
+	txs := []*types.Transaction{types.NewTestTxDetail(1, 1, 1), types.NewTestTxDetail(1, 1, 2)}
+	receipts := []*types.Receipt{{TxHash: txs[0].Hash, UsedGas: 1}, {TxHash: txs[1].Hash, UsedGas: 2}}
+	debt := &types.Debt{Hash: common.StringToHash("debt"), Data: types.DebtData{Amount: big.NewInt(1), Price: big.NewInt(1)}}
+
+	return types.NewBlock(header, txs, receipts, []*types.Debt{debt}), receipts
+}
+
+func failedChecks(checks []*blockCheck) []string {
+	var failed []string
+	for _, check := range checks {
+		if check.err != nil {
+			failed = append(failed, check.name)
+		}
+	}
+
+	return failed
+}
+
+func Test_VerifyBlock(t *testing.T) {
+	block, receipts := newVerifyTestBlock()
+
+	// the block is verified after the json round trip of getrawblock
+	data, err := json.Marshal(&rawBlock{block, receipts})
+	assert.Equal(t, err, nil)
+
+	var raw rawBlock
+	assert.Equal(t, json.Unmarshal(data, &raw), nil)
+
+	checks := verifyBlock(raw.Block, raw.Receipts, common.Sha256Algorithm)
+	assert.Equal(t, len(checks), 7)
+	assert.Equal(t, len(failedChecks(checks)), 0)
+
+	// receipts pruned
+	checks = verifyBlock(block, nil, common.Sha256Algorithm)
+	assert.Equal(t, checks[5].name, "receipt root")
+	assert.Equal(t, checks[5].skipped, "receipts not available")
+
+	// tampered receipt
+	receipts[1].UsedGas = 3
+	assert.Equal(t, failedChecks(verifyBlock(block, receipts, common.Sha256Algorithm)), []string{"receipt root"})
+
+	// tampered tx
+	block, receipts = newVerifyTestBlock()
+	block.Transactions[0].Data.Amount = big.NewInt(100)
+	assert.Equal(t, failedChecks(verifyBlock(block, receipts, common.Sha256Algorithm)), []string{"tx hashes", "tx root", "tx debt root"})
+
+	// tampered debts
+	block, receipts = newVerifyTestBlock()
+	block.Debts = nil
+	assert.Equal(t, failedChecks(verifyBlock(block, receipts, common.Sha256Algorithm)), []string{"debt root"})
+
+	// tampered header
+	block, receipts = newVerifyTestBlock()
+	block.Header.Height = 2
+	assert.Equal(t, failedChecks(verifyBlock(block, receipts, common.Sha256Algorithm)), []string{"header hash"})
+}
+
+func Test_VerifyBlock_Pow(t *testing.T) {
+	block, receipts := newVerifyTestBlock()
+
+	// the hash of the block is far greater than the target of the max difficulty
+	block.Header.Difficulty = new(big.Int).Lsh(big.NewInt(1), 255)
+	block.HeaderHash = block.Header.Hash()
+	assert.Equal(t, failedChecks(verifyBlock(block, receipts, common.Sha256Algorithm)), []string{"pow target"})
+
+	checks := verifyBlock(block, receipts, "unknown")
+	assert.Equal(t, checks[6].skipped, "unsupported algorithm unknown")
+
+	block.Header.Consensus = types.IstanbulConsensus
+	checks = verifyBlock(block, receipts, common.Sha256Algorithm)
+	assert.Equal(t, checks[6].skipped, "istanbul consensus")
+
+	block.Header.Height = 0
+	checks = verifyBlock(block, receipts, common.ZpowAlgorithm)
+	assert.Equal(t, checks[6].skipped, "genesis block")
+}
@@ -70,7 +70,8 @@ var startCmd = &cobra.Command{
 			mynode = n
 		}
 
-		discovery.StartService(common.GetTempFolder(), mynode.ID, mynode.GetUDPAddr(), bootstrap, *shard)
+		discovery.StartService(common.GetTempFolder(), mynode.ID, mynode.GetUDPAddr(), bootstrap, *shard,
+			discovery.NewSubnetLimit(0, 0, discovery.DefaultMaxNodesPerSubnet))
 
 		wg := sync.WaitGroup{}
 		wg.Add(1)
@@ -8,6 +8,7 @@ package cmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"path/filepath"
@@ -18,6 +19,7 @@ import (
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database"
 	"github.com/scdoproject/go-scdo/log/comm"
 	"github.com/scdoproject/go-scdo/node"
 	"github.com/scdoproject/go-scdo/p2p"
@@ -37,14 +39,38 @@ func GetConfigFromFile(filepath string) (*util.Config, error) {
 
 // Cast cast RPC address to 0.0.0.0
 // miner mehtods already have security-defence setting, 0.0.0.0 is ok (after mainnet matures and becomes stable, we can switch to 127.0.0.1)
+// The additional rpc listeners are bound to the addresses as configured.
 func Cast(conf *node.Config) {
 	endpoint := conf.BasicConfig.RPCAddr
+	if endpoint == "" {
+		return
+	}
+
 	pos := strings.LastIndex(endpoint, ":")
 	port := endpoint[pos+1:]
 	endpoint = "0.0.0.0:" + port
 	conf.BasicConfig.RPCAddr = endpoint
 }
 
+// ApplyDevMode switches the node to the instant seal engine of the local dev network with
+// the in-memory database, and pre-funds the developer accounts in the dev config in the genesis.
+func ApplyDevMode(conf *node.Config) {
+	conf.BasicConfig.MinerAlgorithm = common.DevAlgorithm
+
+	// the dev networks are ephemeral, keep the chain data in memory unless specified
+	if conf.BasicConfig.DBEngine == "" {
+		conf.BasicConfig.DBEngine = database.EngineMemory
+	}
+
+	if conf.ScdoConfig.GenesisConfig.Accounts == nil {
+		conf.ScdoConfig.GenesisConfig.Accounts = make(map[common.Address]*big.Int)
+	}
+
+	for addr, balance := range conf.DevConfig.Accounts {
+		conf.ScdoConfig.GenesisConfig.Accounts[addr] = balance
+	}
+}
+
 // LoadConfigFromFile gets node config from the given file
 func LoadConfigFromFile(configFile string, accounts string, poolAccounts string) (*node.Config, error) {
 	cmdConfig, err := GetConfigFromFile(configFile)
@@ -80,13 +106,32 @@ func LoadConfigFromFile(configFile string, accounts string, poolAccounts string)
 	}
 
 	if len(poolAccounts) > 0 {
-		config.ScdoConfig.CoinbaseList, err = LoadPoolAccountConfig(poolAccounts)
+		config.ScdoConfig.PoolAccounts, err = LoadPoolAccountConfig(poolAccounts)
 		if err != nil {
 			return nil, err
 		}
+
+		for addr := range config.ScdoConfig.PoolAccounts {
+			config.ScdoConfig.CoinbaseList = append(config.ScdoConfig.CoinbaseList, addr)
+		}
 	}
 
 	config.ScdoConfig.TxConf = *core.DefaultTxPoolConfig()
+	if cmdConfig.TxPoolConfig.Capacity > 0 {
+		config.ScdoConfig.TxConf.Capacity = cmdConfig.TxPoolConfig.Capacity
+	}
+	config.ScdoConfig.TxConf.FutureCapacity = cmdConfig.TxPoolConfig.FutureCapacity
+	config.ScdoConfig.TxConf.Policies = cmdConfig.TxPoolConfig.Policies
+	config.ScdoConfig.DebtConf = *core.DefaultDebtPoolConfig()
+	if cmdConfig.DebtPoolConfig.MinPrice != nil {
+		config.ScdoConfig.DebtConf.MinPrice = cmdConfig.DebtPoolConfig.MinPrice
+	}
+	if cmdConfig.DebtPoolConfig.Timeout > 0 {
+		config.ScdoConfig.DebtConf.Timeout = cmdConfig.DebtPoolConfig.Timeout
+	}
+	if cmdConfig.DebtPoolConfig.MaxRequeues != 0 {
+		config.ScdoConfig.DebtConf.MaxRequeues = cmdConfig.DebtPoolConfig.MaxRequeues
+	}
 	config.ScdoConfig.GenesisConfig = cmdConfig.GenesisConfig
 	comm.LogConfiguration.PrintLog = config.LogConfig.PrintLog
 	comm.LogConfiguration.IsDebug = config.LogConfig.IsDebug
@@ -109,13 +154,34 @@ func convertIPCServerPath(cmdConfig *util.Config, config *node.Config) {
 // CopyConfig copy Config from the given config
 func CopyConfig(cmdConfig *util.Config) *node.Config {
 	config := &node.Config{
-		BasicConfig:    cmdConfig.BasicConfig,
-		LogConfig:      cmdConfig.LogConfig,
-		HTTPServer:     cmdConfig.HTTPServer,
-		WSServerConfig: cmdConfig.WSServerConfig,
-		P2PConfig:      cmdConfig.P2PConfig,
-		ScdoConfig:     node.ScdoConfig{},
-		MetricsConfig:  cmdConfig.MetricsConfig,
+		BasicConfig:           cmdConfig.BasicConfig,
+		LogConfig:             cmdConfig.LogConfig,
+		HTTPServer:            cmdConfig.HTTPServer,
+		WSServerConfig:        cmdConfig.WSServerConfig,
+		RPCConfig:             cmdConfig.RPCConfig,
+		P2PConfig:             cmdConfig.P2PConfig,
+		ScdoConfig:            node.ScdoConfig{},
+		WatchdogConfig:        cmdConfig.WatchdogConfig,
+		MinerWarmupConfig:     cmdConfig.MinerWarmupConfig,
+		MinerPreemptionConfig: cmdConfig.MinerPreemptionConfig,
+		LightServerConfig:     cmdConfig.LightServerConfig,
+		WebhookConfig:         cmdConfig.WebhookConfig,
+		DiskQuotaConfig:       cmdConfig.DiskQuotaConfig,
+		RetentionConfig:       cmdConfig.RetentionConfig,
+		RPCSyncConfig:         cmdConfig.RPCSyncConfig,
+		DevConfig:             cmdConfig.DevConfig,
+		TxSyncConfig:          cmdConfig.TxSyncConfig,
+		PeerKnownCacheConfig:  cmdConfig.PeerKnownCacheConfig,
+		LeaseMiningConfig:     cmdConfig.LeaseMiningConfig,
+		FollowerConfig:        cmdConfig.FollowerConfig,
+		BackupConfig:          cmdConfig.BackupConfig,
+		BlockTemplateConfig:   cmdConfig.BlockTemplateConfig,
+		DataDirsConfig:        cmdConfig.DataDirsConfig,
+		SlowTxConfig:          cmdConfig.SlowTxConfig,
+		ShardRPCConfig:        cmdConfig.ShardRPCConfig,
+		MetricsConfig:         cmdConfig.MetricsConfig,
+		TracingConfig:         cmdConfig.TracingConfig,
+		SnapshotConfig:        cmdConfig.SnapshotConfig,
 	}
 	return config
 }
@@ -148,10 +214,11 @@ func LoadAccountConfig(account string) (map[common.Address]*big.Int, error) {
 	return result, err
 }
 
-// LoadPoolAccountConfig get accounts from the given file
-func LoadPoolAccountConfig(account string) ([]common.Address, error) {
-	addrMap := make(map[common.Address]*big.Int)
-	var result []common.Address
+// LoadPoolAccountConfig get accounts from the given file. The value of each account is either an object
+// with the coinbase weight and the daily quota, e.g. {"weight": 2, "quota": 100}, or a number in the legacy
+// format, which is the account balance and not used, so the account is weighted 1 without quota.
+func LoadPoolAccountConfig(account string) (map[common.Address]*node.PoolAccount, error) {
+	result := make(map[common.Address]*node.PoolAccount)
 	if account == "" {
 		return result, nil
 	}
@@ -161,10 +228,38 @@ func LoadPoolAccountConfig(account string) ([]common.Address, error) {
 		return result, err
 	}
 
-	err = json.Unmarshal(buff, &addrMap)
+	addrMap := make(map[common.Address]json.RawMessage)
+	if err = json.Unmarshal(buff, &addrMap); err != nil {
+		return result, err
+	}
+
+	for addr, value := range addrMap {
+		poolAccount := &node.PoolAccount{Weight: 1}
+		if strings.HasPrefix(strings.TrimSpace(string(value)), "{") {
+			var weighted struct {
+				Weight *uint64 `json:"weight"`
+				Quota  uint64  `json:"quota"`
+			}
+
+			if err = json.Unmarshal(value, &weighted); err != nil {
+				return result, fmt.Errorf("invalid pool account %s, %s", addr.Hex(), err)
+			}
 
-	for addr, _ := range addrMap {
-		result = append(result, addr)
+			if weighted.Weight != nil {
+				if *weighted.Weight == 0 {
+					return result, fmt.Errorf("invalid pool account %s, weight must be positive, remove the account to never select it", addr.Hex())
+				}
+
+				poolAccount.Weight = *weighted.Weight
+			}
+
+			poolAccount.Quota = weighted.Quota
+		} else if err = json.Unmarshal(value, new(big.Int)); err != nil {
+			return result, fmt.Errorf("invalid pool account %s, %s", addr.Hex(), err)
+		}
+
+		result[addr] = poolAccount
 	}
-	return result, err
+
+	return result, nil
 }
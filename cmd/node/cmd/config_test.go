@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
 	"github.com/scdoproject/go-scdo/node"
 	"github.com/stretchr/testify/assert"
 )
@@ -54,3 +57,31 @@ func Test_CopyConfig(t *testing.T) {
 	copied.ScdoConfig.GenesisConfig.ShardNumber = uint(2)
 	assert.Equal(t, copied.ScdoConfig.GenesisConfig.ShardNumber, uint(2))
 }
+
+func Test_LoadPoolAccountConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "poolaccounts")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	load := func(content string) (map[common.Address]*node.PoolAccount, error) {
+		file := filepath.Join(dir, "poolaccounts.json")
+		assert.Equal(t, ioutil.WriteFile(file, []byte(content), 0644), nil)
+		return LoadPoolAccountConfig(file)
+	}
+
+	addr1, addr2 := *crypto.MustGenerateRandomAddress(), *crypto.MustGenerateRandomAddress()
+
+	// the legacy balances are weighted 1
+	accounts, err := load(`{"` + addr1.Hex() + `": 100000000, "` + addr2.Hex() + `": 0}`)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, accounts[addr1], &node.PoolAccount{Weight: 1})
+	assert.Equal(t, accounts[addr2], &node.PoolAccount{Weight: 1})
+
+	accounts, err = load(`{"` + addr1.Hex() + `": {"weight": 3, "quota": 10}, "` + addr2.Hex() + `": {"quota": 5}}`)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, accounts[addr1], &node.PoolAccount{Weight: 3, Quota: 10})
+	assert.Equal(t, accounts[addr2], &node.PoolAccount{Weight: 1, Quota: 5})
+
+	_, err = load(`{"` + addr1.Hex() + `": {"weight": 0}}`)
+	assert.Equal(t, err != nil, true)
+}
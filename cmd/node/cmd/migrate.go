@@ -0,0 +1,64 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/scdo"
+	"github.com/spf13/cobra"
+)
+
+var migrateConfigFile string
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "move the databases of the node from the data dir to the paths configured in dataDirs",
+	Long: `For example:
+			node.exe migrate -c cmd\node.json
+			the node must be stopped. The databases are also moved once the node starts, while this command
+			moves them ahead of time, which takes a while if the databases are copied to another disk.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nCfg, err := LoadConfigFromFile(migrateConfigFile, "", "")
+		if err != nil {
+			fmt.Printf("failed to reading the config file: %s\n", err.Error())
+			return
+		}
+
+		migrateDataDirs(nCfg)
+	},
+}
+
+// migrateDataDirs moves the databases under the data dir to the configured paths
+func migrateDataDirs(nCfg *node.Config) {
+	dirs, err := scdo.ResolveDataDirs(nCfg.BasicConfig.DataDir, nCfg.DataDirsConfig)
+	if err != nil {
+		fmt.Printf("invalid data dirs: %s\n", err.Error())
+		return
+	}
+
+	migrated, err := scdo.MigrateDataDirs(nCfg.BasicConfig.DataDir, dirs)
+	if len(migrated) > 0 {
+		fmt.Printf("moved the %s to the configured data dirs\n", strings.Join(migrated, ", "))
+	}
+
+	if err != nil {
+		fmt.Printf("failed to move the databases: %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("chain db: %s\naccount state db: %s\ndebt manager db: %s\n", dirs.ChainDB, dirs.AccountStateDB, dirs.DebtManagerDB)
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVarP(&migrateConfigFile, "config", "c", "", "scdo node config file (required)")
+	migrateCmd.MustMarkFlagRequired("config")
+}
@@ -0,0 +1,55 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/scdo/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreConfigFile string
+	restoreSnapshot   string
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "restore the chain and state databases of the node from a backup",
+	Long: `For example:
+			node.exe restore -c cmd\node.json --snapshot backup\snapshot_00000000000000100000
+			the node must be stopped, and its data dir must not be initialized.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nCfg, err := LoadConfigFromFile(restoreConfigFile, "", "")
+		if err != nil {
+			fmt.Printf("failed to reading the config file: %s\n", err.Error())
+			return
+		}
+
+		manifest, err := snapshot.Restore(restoreSnapshot, nCfg.BasicConfig.DataDir)
+		if err != nil {
+			fmt.Printf("failed to restore from %s: %s\n", restoreSnapshot, err.Error())
+			return
+		}
+
+		fmt.Printf("restored the chain at height %d, block hash %s into %s\n", manifest.Height, manifest.BlockHash.Hex(), nCfg.BasicConfig.DataDir)
+
+		// the databases are restored into the data dir, and moved to the configured paths if any
+		migrateDataDirs(nCfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVarP(&restoreConfigFile, "config", "c", "", "scdo node config file (required)")
+	restoreCmd.MustMarkFlagRequired("config")
+
+	restoreCmd.Flags().StringVarP(&restoreSnapshot, "snapshot", "", "", "path of the backup to restore (required)")
+	restoreCmd.MustMarkFlagRequired("snapshot")
+}
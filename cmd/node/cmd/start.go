@@ -10,11 +10,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/scdoproject/go-scdo/common"
@@ -24,6 +26,7 @@ import (
 	"github.com/scdoproject/go-scdo/log"
 	"github.com/scdoproject/go-scdo/log/comm"
 	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
 	miner2 "github.com/scdoproject/go-scdo/miner"
 	"github.com/scdoproject/go-scdo/monitor"
 	"github.com/scdoproject/go-scdo/node"
@@ -54,6 +57,24 @@ var (
 
 	maxConns       = int(0)
 	maxActiveConns = int(0)
+
+	// ntpServer is the NTP server to check the local clock at startup, empty to skip
+	ntpServer string
+
+	// cacheMB is the memory in MB of the state trie node cache, 0 to use the config
+	cacheMB int
+
+	// devMode seals a block instantly once there are txs in the pool, for the local dev networks
+	devMode bool
+
+	// dbEngine is the database engine, leveldb or memory, empty to use the config
+	dbEngine string
+
+	// stateMismatchReport dumps the diagnostics when the state root hash of a block mismatches
+	stateMismatchReport bool
+
+	// stateDiff computes and stores the state diff of every written block
+	stateDiff bool
 )
 
 // startCmd represents the start command
@@ -72,6 +93,22 @@ var startCmd = &cobra.Command{
 			return
 		}
 		Cast(nCfg)
+		if devMode {
+			ApplyDevMode(nCfg)
+		}
+		if dbEngine != "" {
+			nCfg.BasicConfig.DBEngine = dbEngine
+		}
+		if stateMismatchReport {
+			nCfg.BasicConfig.StateMismatchReport = true
+		}
+		if stateDiff {
+			nCfg.BasicConfig.StateDiff = true
+		}
+		if cacheMB > 0 {
+			nCfg.BasicConfig.Cache = cacheMB
+		}
+
 		if !comm.LogConfiguration.PrintLog {
 			fmt.Printf("log folder: %s\n", filepath.Join(log.LogFolder, comm.LogConfiguration.DataDir))
 		}
@@ -82,9 +119,29 @@ var startCmd = &cobra.Command{
 			return
 		}
 
+		// reload the non-consensus config on SIGHUP or admin_reloadConfig
+		scdoNode.SetConfigLoader(func() (*node.Config, error) {
+			conf, err := LoadConfigFromFile(scdoNodeConfigFile, accountsConfig, poolAccountsConfig)
+			if err != nil {
+				return nil, err
+			}
+
+			Cast(conf)
+			if devMode {
+				ApplyDevMode(conf)
+			}
+			return conf, nil
+		})
+		go reloadConfigOnSignal(scdoNode)
+
 		// Create scdo service and register the service
 		scdolog := log.GetLogger("scdo")
 		lightLog := log.GetLogger("scdo-light")
+
+		if err = tracing.Start(nCfg.TracingConfig, log.GetLogger("tracing")); err != nil {
+			fmt.Printf("failed to start tracing: %s\n", err)
+			return
+		}
 		serviceContext := scdo.ServiceContext{
 			DataDir: nCfg.BasicConfig.DataDir,
 		}
@@ -186,10 +243,18 @@ var startCmd = &cobra.Command{
 				return
 			}
 
+			if ntpServer != "" {
+				checkClockDrift(scdoService.Miner())
+			}
+
 			minerInfo := strings.ToLower(miner)
-			if minerInfo == "start" {
+			if minerInfo == "start" && scdoService.IsFollower() {
+				fmt.Println("miner is not started in the follower mode until promoted to active")
+			} else if minerInfo == "start" {
 				err = scdoService.Miner().Start()
-				if err != nil && err != miner2.ErrMinerIsRunning {
+				if err == miner2.ErrClockDrift {
+					fmt.Println("miner is not started for the local clock drift")
+				} else if err != nil && err != miner2.ErrMinerIsRunning {
 					fmt.Println("failed to start the miner : ", err)
 					return
 				}
@@ -218,6 +283,39 @@ var startCmd = &cobra.Command{
 	},
 }
 
+// checkClockDrift checks the local clock with the NTP server, and the miner refuses
+// to start if the local clock drifts too much.
+func checkClockDrift(m *miner2.Miner) {
+	drift, err := common.ClockDrift(ntpServer)
+	if err != nil {
+		fmt.Printf("failed to check the local clock with ntp server %s: %s\n", ntpServer, err)
+		return
+	}
+
+	m.SetClockDrift(drift)
+	if drift > common.MaxClockDrift || drift < -common.MaxClockDrift {
+		fmt.Println("********************************************************************************")
+		fmt.Printf("WARNING: the local clock drifts %s from ntp server %s, more than %s.\n", drift, ntpServer, common.MaxClockDrift)
+		fmt.Println("Blocks with wrong timestamps are rejected by other nodes, mining is refused.")
+		fmt.Println("Please synchronize the local clock and restart the node.")
+		fmt.Println("********************************************************************************")
+	}
+}
+
+// reloadConfigOnSignal reloads the node config once SIGHUP is received
+func reloadConfigOnSignal(n *node.Node) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	for range sigCh {
+		if err := n.ReloadConfig(); err != nil {
+			fmt.Printf("failed to reload config: %s\n", err)
+		} else {
+			fmt.Println("config reloaded")
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(startCmd)
 
@@ -237,6 +335,12 @@ func init() {
 	startCmd.Flags().BoolVarP(&isPoolMode, "pool", "", false, "pool mode")
 	startCmd.Flags().IntVarP(&threadblocks, "threadblocks", "", 0, "number of thread blocks in a gpu device")
 	startCmd.Flags().IntVarP(&blockthreads, "blockthreads", "", 1, "number of threads per block in a gpu device")
+	startCmd.Flags().StringVarP(&ntpServer, "ntpserver", "", common.DefaultNTPServer, "ntp server to check the local clock at startup, empty to skip")
+	startCmd.Flags().IntVarP(&cacheMB, "cache", "", 0, "memory in MB of the state trie node cache, 0 means the config value or the default 128 MB")
+	startCmd.Flags().BoolVarP(&devMode, "dev", "", false, "dev mode, seal a block instantly once there are txs in the pool, with the dev accounts pre-funded")
+	startCmd.Flags().StringVarP(&dbEngine, "db.engine", "", "", "database engine, leveldb or memory, the memory engine loses the data once the node stops, default to memory in dev mode")
+	startCmd.Flags().BoolVarP(&stateMismatchReport, "diag.statemismatch", "", false, "dump the account differences to a report file in the data dir when the state root hash of a block mismatches")
+	startCmd.Flags().BoolVarP(&stateDiff, "diag.statediff", "", false, "compute and store the state diff of every written block for debug_getStateDiff")
 
 }
 
@@ -0,0 +1,185 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/database"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/scdo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nodeDataDir    string
+	nodeChainDB    string
+	nodeStateDB    string
+	inactiveBlocks uint64
+	dustBalance    uint64
+	listReclaim    bool
+
+	stateUsageCmd = &cobra.Command{
+		Use:   "stateusage",
+		Short: "scan the state of a stopped node for the dust accounts and dead contracts, and report the reclaimable storage",
+		Long: `usage example:
+		tool.exe stateusage --datadir ~/.scdo/node1 --inactive 100000 --dust 1000
+		the --chaindb and --statedb should be the same as the dataDirs config of the node if configured.
+		the accounts changed in the last inactive blocks are active, the inactive accounts with balance
+		no more than dust are dust accounts, and the inactive contracts with zero balance are dead contracts.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := scanStateUsage(); err != nil {
+				log("failed to scan the state usage: %v", err)
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(stateUsageCmd)
+
+	stateUsageCmd.Flags().StringVar(&nodeDataDir, "datadir", "", "data folder of the stopped node, which contains the db folder")
+	stateUsageCmd.MarkFlagRequired("datadir")
+	stateUsageCmd.Flags().StringVar(&nodeChainDB, "chaindb", "", "path of the chain db if configured, the relative path is based on the datadir")
+	stateUsageCmd.Flags().StringVar(&nodeStateDB, "statedb", "", "path of the account state db if configured, the relative path is based on the datadir")
+	stateUsageCmd.Flags().Uint64Var(&inactiveBlocks, "inactive", 100000, "accounts not changed in the last inactive blocks are inactive")
+	stateUsageCmd.Flags().Uint64Var(&dustBalance, "dust", 0, "inactive accounts with balance no more than dust (in Wen) are dust accounts")
+	stateUsageCmd.Flags().BoolVar(&listReclaim, "list", false, "list the address hash of the dust accounts and dead contracts")
+}
+
+// stateUsage is the state usage of a kind of accounts
+type stateUsage struct {
+	accounts int
+	size     int
+}
+
+func (u *stateUsage) add(usage *state.AccountUsage) {
+	u.accounts++
+	u.size += usage.Size
+}
+
+func (u *stateUsage) String() string {
+	return fmt.Sprintf("%v accounts, %v", u.accounts, sizeToString(uint64(u.size)))
+}
+
+// openExistingDB opens the leveldb at the path, which is not created if missing, e.g. a wrong path
+func openExistingDB(path string) (database.Database, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	return leveldb.NewLevelDB(path)
+}
+
+func scanStateUsage() error {
+	dirs, err := scdo.ResolveDataDirs(nodeDataDir, node.DataDirsConfig{ChainDB: nodeChainDB, AccountStateDB: nodeStateDB})
+	if err != nil {
+		return err
+	}
+
+	chainDB, err := openExistingDB(dirs.ChainDB)
+	if err != nil {
+		return errors.NewStackedError(err, "failed to open the chain db")
+	}
+	defer chainDB.Close()
+
+	stateDB, err := openExistingDB(dirs.AccountStateDB)
+	if err != nil {
+		return errors.NewStackedError(err, "failed to open the account state db")
+	}
+	defer stateDB.Close()
+
+	bcStore := store.NewBlockchainDatabase(chainDB)
+	headHash, err := bcStore.GetHeadBlockHash()
+	if err != nil {
+		return errors.NewStackedError(err, "failed to get the head block hash")
+	}
+
+	head, err := bcStore.GetBlockHeader(headHash)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to get the head block header %v", headHash)
+	}
+
+	active, err := getActiveAccounts(bcStore, head.Height)
+	if err != nil {
+		return err
+	}
+	log("%v accounts changed in the last %v blocks, head height %v", len(active), inactiveBlocks, head.Height)
+
+	statedb, err := state.NewStatedb(head.StateHash, stateDB)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to create statedb with root hash %v", head.StateHash)
+	}
+
+	var total, dust, dead stateUsage
+	dustLimit := new(big.Int).SetUint64(dustBalance)
+
+	err = statedb.ScanAccounts(func(usage *state.AccountUsage) error {
+		total.add(usage)
+		if total.accounts%100000 == 0 {
+			log("scanned %v", total.String())
+		}
+
+		if _, ok := active[usage.AddrHash]; ok {
+			return nil
+		}
+
+		if usage.IsContract && usage.Balance.Sign() == 0 {
+			dead.add(usage)
+			if listReclaim {
+				fmt.Println("dead contract:", usage.AddrHash.Hex(), "storage items:", usage.StorageItems)
+			}
+		} else if !usage.IsContract && usage.Balance.Cmp(dustLimit) <= 0 {
+			dust.add(usage)
+			if listReclaim {
+				fmt.Println("dust account:", usage.AddrHash.Hex(), "balance:", usage.Balance)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.NewStackedError(err, "failed to scan the state trie")
+	}
+
+	fmt.Println("total:", total.String())
+	fmt.Println("dust accounts:", dust.String())
+	fmt.Println("dead contracts:", dead.String())
+	fmt.Println("reclaimable:", sizeToString(uint64(dust.size+dead.size)))
+
+	return nil
+}
+
+// getActiveAccounts returns the hash of the accounts changed in the last inactiveBlocks blocks
+func getActiveAccounts(bcStore store.BlockchainStore, headHeight uint64) (map[common.Hash]struct{}, error) {
+	active := make(map[common.Hash]struct{})
+
+	for i := uint64(0); i < inactiveBlocks && i <= headHeight; i++ {
+		height := headHeight - i
+		hash, err := bcStore.GetBlockHash(height)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get block hash by height %v", height)
+		}
+
+		accounts, err := bcStore.GetDirtyAccountsByBlockHash(hash)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get the changed accounts of block %v, they may be pruned, try a smaller inactive window", height)
+		}
+
+		for _, addr := range accounts {
+			active[state.AddressHash(addr)] = struct{}{}
+		}
+	}
+
+	return active, nil
+}
@@ -9,8 +9,10 @@ import (
 	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/log/comm"
 	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
 	"github.com/scdoproject/go-scdo/node"
 	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/scdo/snapshot"
 )
 
 // Config is the Configuration of node
@@ -33,9 +35,78 @@ type Config struct {
 	// The configuration of ipc rpc service
 	Ipcconfig node.IpcConfig `json:"ipcconfig"`
 
+	// The configuration of rpc execution limits
+	RPCConfig node.RPCConfig `json:"rpc"`
+
+	// The configuration of tx pool, 0 capacity means the default
+	TxPoolConfig core.TransactionPoolConfig `json:"txpool"`
+
+	// The configuration of debt pool, nil min price means no limit
+	DebtPoolConfig core.DebtPoolConfig `json:"debtpool"`
+
+	// The configuration of the watchdog
+	WatchdogConfig node.WatchdogConfig `json:"watchdog"`
+
+	// The configuration of the miner warmup after sync
+	MinerWarmupConfig node.MinerWarmupConfig `json:"minerWarmup"`
+
+	// The configuration of the miner preemption on a new head
+	MinerPreemptionConfig node.MinerPreemptionConfig `json:"minerPreemption"`
+
+	// The configuration of the light server
+	LightServerConfig node.LightServerConfig `json:"lightServer"`
+
+	// The configuration of the chain event webhooks
+	WebhookConfig node.WebhookConfig `json:"webhooks"`
+
+	// The configuration of the disk usage quota of the chain database
+	DiskQuotaConfig node.DiskQuotaConfig `json:"diskQuota"`
+
+	// The configuration of the retention of the receipts and dirty accounts
+	RetentionConfig node.RetentionConfig `json:"retention"`
+
+	// The configuration of syncing from the trusted rpc endpoints
+	RPCSyncConfig node.RPCSyncConfig `json:"rpcSync"`
+
+	// The configuration of the local dev network, used only in dev mode
+	DevConfig node.DevConfig `json:"dev"`
+
+	// The configuration of syncing the pending txs to the new peers
+	TxSyncConfig node.TxSyncConfig `json:"txSync"`
+
+	// The configuration of the hashes known by each peer, which are not sent to the peer again
+	PeerKnownCacheConfig node.PeerKnownCacheConfig `json:"peerKnownCache"`
+
+	// The configuration of mining the nonce ranges leased from the primary node
+	LeaseMiningConfig node.LeaseMiningConfig `json:"leaseMining"`
+
+	// The configuration of the warm standby which follows the chain until promoted to active
+	FollowerConfig node.FollowerConfig `json:"follower"`
+
+	// The configuration of the scheduled backups of the chain and state databases
+	BackupConfig node.BackupConfig `json:"backup"`
+
+	// The configuration of customizing the txs of the block template of the miner
+	BlockTemplateConfig node.BlockTemplateConfig `json:"blockTemplate"`
+
+	// The configuration of the paths of the databases, which are under the data dir by default
+	DataDirsConfig node.DataDirsConfig `json:"dataDirs"`
+
+	// The configuration of reporting the slow txs when the blocks are written
+	SlowTxConfig node.SlowTxConfig `json:"slowTx"`
+
+	// The configuration of the rpc endpoints of the nodes of other shards
+	ShardRPCConfig node.ShardRPCConfig `json:"shardRpc"`
+
 	// metrics config info
 	MetricsConfig *metrics.Config `json:"metrics"`
 
+	// tracing config info
+	TracingConfig *tracing.Config `json:"tracing"`
+
+	// snapshot bootstrap config info
+	SnapshotConfig *snapshot.Config `json:"snapshot"`
+
 	// genesis config info
 	GenesisConfig core.GenesisInfo `json:"genesis"`
 }
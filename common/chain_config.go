@@ -0,0 +1,300 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package common
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"sort"
+)
+
+// ErrForkIDIncompatible is returned when the fork id of a peer is incompatible with the local chain config
+var ErrForkIDIncompatible = errors.New("incompatible fork id")
+
+// ChainConfig is the fork activation heights of a chain. It is carried in the genesis info and persisted
+// in the blockchain store as JSON, so that a private network could activate the forks at its own heights.
+// The genesis block is always at ScdoForkHeight.
+type ChainConfig struct {
+	// EmeryForkHeight updates the zpow consensus and evm
+	EmeryForkHeight uint64 `json:"emeryForkHeight"`
+
+	// SecondForkHeight changes the difficulty adjustment
+	SecondForkHeight uint64 `json:"secondForkHeight"`
+
+	// ThirdForkHeight changes the validation of tx
+	ThirdForkHeight uint64 `json:"thirdForkHeight"`
+
+	// SmartContractNonceForkHeight reverts the state of the failed contract tx except the nonce
+	SmartContractNonceForkHeight uint64 `json:"smartContractNonceForkHeight"`
+
+	// SmartContractNonceFixHeight fixes the smart contract nonce when the contract calls setNonce
+	SmartContractNonceFixHeight uint64 `json:"smartContractNonceFixHeight"`
+
+	// SignalingExtraForkHeight allows the pow miners to set the extra data for governance signaling
+	SignalingExtraForkHeight uint64 `json:"signalingExtraForkHeight"`
+
+	// BlockTimeDriftForkHeight tightens the max future drift of block time to MaxBlockFutureDrift
+	BlockTimeDriftForkHeight uint64 `json:"blockTimeDriftForkHeight"`
+
+	// StateCleanupForkHeight deletes the empty accounts touched by a tx from the state
+	StateCleanupForkHeight uint64 `json:"stateCleanupForkHeight"`
+
+	// AccessListForkHeight allows the txs to carry access lists, and charges the state access by the warm/cold
+	// costs of EIP-2929
+	AccessListForkHeight uint64 `json:"accessListForkHeight"`
+
+	// MetaTxRelayForkHeight activates the meta tx relay system contract
+	MetaTxRelayForkHeight uint64 `json:"metaTxRelayForkHeight"`
+
+	// ContractRegistryForkHeight activates the contract metadata registry system contract
+	ContractRegistryForkHeight uint64 `json:"contractRegistryForkHeight"`
+
+	// AccessControlForkHeight activates the access control system contract and the access checks of the contract
+	// deployments and calls, which only takes effect with the BFT consensus
+	AccessControlForkHeight uint64 `json:"accessControlForkHeight"`
+
+	// ChainID identifies the chain in the signed messages, e.g. meta txs, so that they could not be replayed
+	// on another network. The private networks should use their own chain id other than MainChainID.
+	ChainID uint64 `json:"chainId"`
+
+	// ZpowParams are the zpow algorithm params activated at their fork heights, the default params are used
+	// before the first one.
+	ZpowParams []ZpowParams `json:"zpowParams,omitempty"`
+}
+
+// DefaultChainConfig returns the chain config of the main network
+func DefaultChainConfig() *ChainConfig {
+	return &ChainConfig{
+		EmeryForkHeight:              EmeryForkHeight,
+		SecondForkHeight:             SecondForkHeight,
+		ThirdForkHeight:              ThirdForkHeight,
+		SmartContractNonceForkHeight: SmartContractNonceForkHeight,
+		SmartContractNonceFixHeight:  SmartContractNonceFixHeight,
+		SignalingExtraForkHeight:     SignalingExtraForkHeight,
+		BlockTimeDriftForkHeight:     BlockTimeDriftForkHeight,
+		StateCleanupForkHeight:       StateCleanupForkHeight,
+		AccessListForkHeight:         AccessListForkHeight,
+		MetaTxRelayForkHeight:        MetaTxRelayForkHeight,
+		ContractRegistryForkHeight:   ContractRegistryForkHeight,
+		AccessControlForkHeight:      AccessControlForkHeight,
+		ChainID:                      MainChainID,
+	}
+}
+
+// UnmarshalJSON decodes the chain config over the config of the main network, so that the fields omitted,
+// e.g. in a genesis info or a stored config written before the field is added, keep the main network values
+// instead of the zero values, which would activate the forks since the genesis.
+func (c *ChainConfig) UnmarshalJSON(input []byte) error {
+	type plainChainConfig ChainConfig
+	config := plainChainConfig(*DefaultChainConfig())
+	if err := json.Unmarshal(input, &config); err != nil {
+		return err
+	}
+
+	*c = ChainConfig(config)
+	return nil
+}
+
+// IsEmery returns whether the emery fork is activated at the height
+func (c *ChainConfig) IsEmery(height uint64) bool {
+	return height >= c.EmeryForkHeight
+}
+
+// IsSecondFork returns whether the second fork is activated at the height
+func (c *ChainConfig) IsSecondFork(height uint64) bool {
+	return height >= c.SecondForkHeight
+}
+
+// IsSignalingExtra returns whether the extra data of pow block is allowed at the height
+func (c *ChainConfig) IsSignalingExtra(height uint64) bool {
+	return height >= c.SignalingExtraForkHeight
+}
+
+// IsBlockTimeDrift returns whether the max future drift of block time is tightened at the height
+func (c *ChainConfig) IsBlockTimeDrift(height uint64) bool {
+	return height >= c.BlockTimeDriftForkHeight
+}
+
+// IsStateCleanup returns whether the empty accounts touched by a tx are deleted from the state at the height
+func (c *ChainConfig) IsStateCleanup(height uint64) bool {
+	return height >= c.StateCleanupForkHeight
+}
+
+// IsAccessList returns whether the tx access lists and the EIP-2929 gas costs are activated at the height
+func (c *ChainConfig) IsAccessList(height uint64) bool {
+	return height >= c.AccessListForkHeight
+}
+
+// IsMetaTxRelay returns whether the meta tx relay system contract is activated at the height
+func (c *ChainConfig) IsMetaTxRelay(height uint64) bool {
+	return height >= c.MetaTxRelayForkHeight
+}
+
+// IsContractRegistry returns whether the contract metadata registry system contract is activated at the height
+func (c *ChainConfig) IsContractRegistry(height uint64) bool {
+	return height >= c.ContractRegistryForkHeight
+}
+
+// IsAccessControl returns whether the access control of the contract deployments and calls is activated at the height
+func (c *ChainConfig) IsAccessControl(height uint64) bool {
+	return height >= c.AccessControlForkHeight
+}
+
+// IsSmartContractNonceFork returns whether the smart contract nonce fork is activated at the height
+func (c *ChainConfig) IsSmartContractNonceFork(height uint64) bool {
+	return height > c.SmartContractNonceForkHeight
+}
+
+// IsSmartContractNonceFix returns whether the smart contract nonce fix is activated at the height
+func (c *ChainConfig) IsSmartContractNonceFix(height uint64) bool {
+	return height > c.SmartContractNonceFixHeight
+}
+
+// forkHeights returns the sorted fork heights after the genesis, the forks at or before the genesis are
+// activated since the genesis and identified by the genesis already, and the forks at math.MaxUint64
+// are not scheduled yet.
+func (c *ChainConfig) forkHeights() []uint64 {
+	all := []uint64{
+		c.EmeryForkHeight,
+		c.SecondForkHeight,
+		c.ThirdForkHeight,
+		c.SmartContractNonceForkHeight,
+		c.SmartContractNonceFixHeight,
+		c.SignalingExtraForkHeight,
+		c.BlockTimeDriftForkHeight,
+		c.StateCleanupForkHeight,
+		c.AccessListForkHeight,
+		c.MetaTxRelayForkHeight,
+		c.ContractRegistryForkHeight,
+		c.AccessControlForkHeight,
+	}
+
+	for _, p := range c.ZpowParams {
+		all = append(all, p.ForkHeight)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	var heights []uint64
+	for _, h := range all {
+		if h > ScdoForkHeight && h != math.MaxUint64 && (len(heights) == 0 || heights[len(heights)-1] != h) {
+			heights = append(heights, h)
+		}
+	}
+
+	return heights
+}
+
+// ForkID identifies the block validation rules of a chain at a height, which is exchanged in the handshake
+// so that the peers with incompatible fork config are rejected up-front instead of after the block exchange.
+type ForkID struct {
+	Hash uint32 // crc32 checksum of the fork heights passed
+	Next uint64 // the next fork height, 0 if no fork is scheduled
+}
+
+// forkChecksums returns the fork heights and the checksums of each fork stage, i.e. sums[i] is the
+// checksum after the first i forks are passed.
+func (c *ChainConfig) forkChecksums() ([]uint64, []uint32) {
+	heights := c.forkHeights()
+	sums := make([]uint32, len(heights)+1)
+
+	var buf [8]byte
+	for i, h := range heights {
+		binary.BigEndian.PutUint64(buf[:], h)
+		sums[i+1] = crc32.Update(sums[i], crc32.IEEETable, buf[:])
+	}
+
+	return heights, sums
+}
+
+// ForkID returns the fork id of the chain with the head at height
+func (c *ChainConfig) ForkID(height uint64) ForkID {
+	heights, sums := c.forkChecksums()
+
+	passed := sort.Search(len(heights), func(i int) bool { return heights[i] > height })
+	id := ForkID{Hash: sums[passed]}
+	if passed < len(heights) {
+		id.Next = heights[passed]
+	}
+
+	return id
+}
+
+// CheckForkID returns ErrForkIDIncompatible if the fork id of a peer is incompatible with the chain
+// with the head at height. The peer is compatible if it is on the same fork stage, or behind but aware
+// of the next fork, or ahead on the forks scheduled locally.
+func (c *ChainConfig) CheckForkID(height uint64, remote ForkID) error {
+	heights, sums := c.forkChecksums()
+	passed := sort.Search(len(heights), func(i int) bool { return heights[i] > height })
+
+	for i, sum := range sums {
+		if sum != remote.Hash {
+			continue
+		}
+
+		switch {
+		case i == passed:
+			// same stage, the peer should not have passed a fork not scheduled locally
+			if remote.Next == 0 || remote.Next > height {
+				return nil
+			}
+		case i < passed:
+			// the peer is behind, and should be aware of the next fork passed locally
+			if remote.Next == heights[i] {
+				return nil
+			}
+		default:
+			// the peer is ahead on the forks scheduled locally
+			return nil
+		}
+
+		return ErrForkIDIncompatible
+	}
+
+	return ErrForkIDIncompatible
+}
+
+// CheckCompatible returns an error if the chain config could not replace the stored config of a chain with
+// the head at height, i.e. a fork height is changed while the old or new height is already passed by the chain.
+func (c *ChainConfig) CheckCompatible(stored *ChainConfig, height uint64) error {
+	forks := []struct {
+		name        string
+		stored, new uint64
+	}{
+		{"emery", stored.EmeryForkHeight, c.EmeryForkHeight},
+		{"second", stored.SecondForkHeight, c.SecondForkHeight},
+		{"third", stored.ThirdForkHeight, c.ThirdForkHeight},
+		{"smart contract nonce", stored.SmartContractNonceForkHeight, c.SmartContractNonceForkHeight},
+		{"smart contract nonce fix", stored.SmartContractNonceFixHeight, c.SmartContractNonceFixHeight},
+		{"signaling extra", stored.SignalingExtraForkHeight, c.SignalingExtraForkHeight},
+		{"block time drift", stored.BlockTimeDriftForkHeight, c.BlockTimeDriftForkHeight},
+		{"state cleanup", stored.StateCleanupForkHeight, c.StateCleanupForkHeight},
+		{"access list", stored.AccessListForkHeight, c.AccessListForkHeight},
+		{"meta tx relay", stored.MetaTxRelayForkHeight, c.MetaTxRelayForkHeight},
+		{"contract registry", stored.ContractRegistryForkHeight, c.ContractRegistryForkHeight},
+		{"access control", stored.AccessControlForkHeight, c.AccessControlForkHeight},
+	}
+
+	if stored.ChainID != c.ChainID {
+		return fmt.Errorf("incompatible chain id, stored %d, new %d", stored.ChainID, c.ChainID)
+	}
+
+	for _, fork := range forks {
+		if fork.stored != fork.new && (fork.stored <= height || fork.new <= height) {
+			return fmt.Errorf("incompatible %s fork height, stored %d, new %d, chain head %d", fork.name, fork.stored, fork.new, height)
+		}
+	}
+
+	if !checkZpowParamsCompatible(stored.ZpowParams, c.ZpowParams, height) {
+		return fmt.Errorf("incompatible zpow params passed by chain head %d", height)
+	}
+
+	return nil
+}
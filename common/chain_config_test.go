@@ -0,0 +1,172 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ChainConfig_Forks(t *testing.T) {
+	config := &ChainConfig{
+		EmeryForkHeight:              10,
+		SmartContractNonceForkHeight: 10,
+	}
+
+	assert.Equal(t, config.IsEmery(9), false)
+	assert.Equal(t, config.IsEmery(10), true)
+
+	// the smart contract nonce fork is activated after the fork height
+	assert.Equal(t, config.IsSmartContractNonceFork(10), false)
+	assert.Equal(t, config.IsSmartContractNonceFork(11), true)
+}
+
+func Test_ChainConfig_NotScheduled(t *testing.T) {
+	config := DefaultChainConfig()
+	assert.Equal(t, config.IsMetaTxRelay(ScdoForkHeight), false)
+	assert.Equal(t, config.ForkID(ScdoForkHeight).Next, uint64(0))
+
+	config.MetaTxRelayForkHeight = ScdoForkHeight + 100
+	assert.Equal(t, config.IsMetaTxRelay(ScdoForkHeight+100), true)
+	assert.Equal(t, config.ForkID(ScdoForkHeight).Next, uint64(ScdoForkHeight+100))
+
+	config = DefaultChainConfig()
+	assert.Equal(t, config.IsStateCleanup(ScdoForkHeight), false)
+	config.StateCleanupForkHeight = ScdoForkHeight + 200
+	assert.Equal(t, config.IsStateCleanup(ScdoForkHeight+200), true)
+	assert.Equal(t, config.ForkID(ScdoForkHeight).Next, uint64(ScdoForkHeight+200))
+
+	assert.Equal(t, config.IsAccessList(ScdoForkHeight+200), false)
+	config.AccessListForkHeight = ScdoForkHeight + 150
+	assert.Equal(t, config.IsAccessList(ScdoForkHeight+150), true)
+	assert.Equal(t, config.ForkID(ScdoForkHeight).Next, uint64(ScdoForkHeight+150))
+
+	// the chain id could not be changed
+	stored := DefaultChainConfig()
+	config = DefaultChainConfig()
+	config.ChainID = MainChainID + 1
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight) != nil, true)
+}
+
+func Test_ChainConfig_CheckCompatible(t *testing.T) {
+	stored := DefaultChainConfig()
+	assert.Equal(t, DefaultChainConfig().CheckCompatible(stored, ScdoForkHeight), nil)
+
+	// postpone the fork not reached yet
+	config := DefaultChainConfig()
+	config.EmeryForkHeight = ScdoForkHeight + 100
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight-1), nil)
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight) != nil, true)
+
+	// bring forward the fork not reached yet
+	config = DefaultChainConfig()
+	stored.SignalingExtraForkHeight = ScdoForkHeight + 100
+	config.SignalingExtraForkHeight = ScdoForkHeight + 50
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight+10), nil)
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight+50) != nil, true)
+}
+
+func Test_ChainConfig_UnmarshalJSON(t *testing.T) {
+	var config ChainConfig
+	assert.Equal(t, json.Unmarshal([]byte(`{"emeryForkHeight": 100}`), &config), nil)
+
+	// the omitted fields keep the main network values
+	expected := DefaultChainConfig()
+	expected.EmeryForkHeight = 100
+	assert.Equal(t, &config, expected)
+
+	var info struct {
+		ChainConfig *ChainConfig `json:"chainConfig"`
+	}
+	assert.Equal(t, json.Unmarshal([]byte(`{"chainConfig": {"chainId": 10}}`), &info), nil)
+	assert.Equal(t, info.ChainConfig.ChainID, uint64(10))
+	assert.Equal(t, info.ChainConfig.MetaTxRelayForkHeight, uint64(MetaTxRelayForkHeight))
+	assert.Equal(t, info.ChainConfig.SecondForkHeight, uint64(SecondForkHeight))
+}
+
+func Test_ChainConfig_ForkID(t *testing.T) {
+	config := DefaultChainConfig()
+	config.SignalingExtraForkHeight = ScdoForkHeight + 100
+	config.BlockTimeDriftForkHeight = ScdoForkHeight + 200
+
+	// the forks at the genesis are not included
+	genesis := config.ForkID(ScdoForkHeight)
+	assert.Equal(t, genesis, ForkID{Hash: 0, Next: ScdoForkHeight + 100})
+
+	first := config.ForkID(ScdoForkHeight + 100)
+	assert.Equal(t, first.Hash != genesis.Hash, true)
+	assert.Equal(t, first.Next, uint64(ScdoForkHeight+200))
+	assert.Equal(t, config.ForkID(ScdoForkHeight+200).Next, uint64(0))
+
+	// same stage
+	height := uint64(ScdoForkHeight + 150)
+	assert.Equal(t, config.CheckForkID(height, first), nil)
+	assert.Equal(t, config.CheckForkID(height, ForkID{Hash: first.Hash}), nil)
+	assert.Equal(t, config.CheckForkID(height, ForkID{Hash: first.Hash, Next: height - 10}), ErrForkIDIncompatible)
+
+	// behind and aware of the next fork, or not upgraded
+	assert.Equal(t, config.CheckForkID(height, genesis), nil)
+	assert.Equal(t, config.CheckForkID(height, ForkID{Hash: 0}), ErrForkIDIncompatible)
+
+	// ahead on the forks scheduled locally
+	assert.Equal(t, config.CheckForkID(ScdoForkHeight, first), nil)
+
+	// unknown forks
+	other := DefaultChainConfig()
+	other.SignalingExtraForkHeight = ScdoForkHeight + 120
+	assert.Equal(t, config.CheckForkID(height, other.ForkID(height)), ErrForkIDIncompatible)
+}
+
+func Test_ChainConfig_ZpowParams(t *testing.T) {
+	config := DefaultChainConfig()
+	assert.Equal(t, config.ZpowParamsAt(ScdoForkHeight), DefaultZpowParams())
+
+	config.ZpowParams = []ZpowParams{
+		{ForkHeight: ScdoForkHeight + 200, MatrixDim: 40},
+		{ForkHeight: ScdoForkHeight + 100, MatrixDim: 32, Multiplier: 100},
+	}
+
+	assert.Equal(t, config.ZpowParamsAt(ScdoForkHeight+99), DefaultZpowParams())
+
+	params := config.ZpowParamsAt(ScdoForkHeight + 150)
+	assert.Equal(t, params.MatrixDim, uint64(32))
+	assert.Equal(t, params.Multiplier, uint64(100))
+	assert.Equal(t, params.MaxTarget, DefaultZpowMaxTarget)
+
+	// the zero values of the latest fork are the defaults instead of the ones of the previous fork
+	params = config.ZpowParamsAt(ScdoForkHeight + 200)
+	assert.Equal(t, params.MatrixDim, uint64(40))
+	assert.Equal(t, params.Multiplier, uint64(DefaultZpowMultiplier))
+
+	// mining target is bounded
+	assert.Equal(t, params.MiningTarget(big.NewInt(10)), big.NewInt(10*DefaultZpowMultiplier))
+	assert.Equal(t, params.MiningTarget(DefaultZpowMaxTarget), DefaultZpowMaxTarget)
+
+	// zpow params forks are included in the fork ID
+	assert.Equal(t, config.ForkID(ScdoForkHeight).Next, uint64(ScdoForkHeight+100))
+}
+
+func Test_ChainConfig_CheckCompatible_ZpowParams(t *testing.T) {
+	stored := DefaultChainConfig()
+	stored.ZpowParams = []ZpowParams{{ForkHeight: ScdoForkHeight + 100, MatrixDim: 32}}
+
+	// the default values are the same as the zero values
+	config := DefaultChainConfig()
+	config.ZpowParams = []ZpowParams{{ForkHeight: ScdoForkHeight + 100, MatrixDim: 32, Multiplier: DefaultZpowMultiplier}}
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight+100), nil)
+
+	// change the params not reached yet
+	config.ZpowParams[0].MatrixDim = 36
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight+99), nil)
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight+100) != nil, true)
+
+	// remove the params reached
+	config.ZpowParams = nil
+	assert.Equal(t, config.CheckCompatible(stored, ScdoForkHeight+100) != nil, true)
+}
@@ -6,6 +6,7 @@
 package common
 
 import (
+	"math"
 	"math/big"
 	"os/user"
 	"path/filepath"
@@ -58,6 +59,40 @@ const (
 	// SmartContractNonceFixHeight fix smart contract nonce bug when user use setNonce
 	SmartContractNonceFixHeight = ScdoForkHeight
 
+	// SignalingExtraForkHeight after this height pow miners can set extra data in block header for governance signaling: hardFork.
+	// It is not scheduled on the main network yet.
+	SignalingExtraForkHeight = math.MaxUint64
+
+	// BlockTimeDriftForkHeight after this height the max future drift of block time is tightened to MaxBlockFutureDrift: hardFork.
+	// It is not scheduled on the main network yet.
+	BlockTimeDriftForkHeight = math.MaxUint64
+
+	// StateCleanupForkHeight after this height the empty accounts touched by a tx are deleted from the state: hardFork.
+	// It is not scheduled on the main network yet.
+	StateCleanupForkHeight = math.MaxUint64
+
+	// AccessListForkHeight after this height the txs could carry access lists, and the evm charges the state access
+	// by the warm/cold costs of EIP-2929: hardFork. It is not scheduled on the main network yet.
+	AccessListForkHeight = math.MaxUint64
+
+	// MetaTxRelayForkHeight after this height the meta tx relay system contract is activated: hardFork.
+	// It is not scheduled on the main network yet.
+	MetaTxRelayForkHeight = math.MaxUint64
+
+	// ContractRegistryForkHeight after this height the contract metadata registry system contract is activated: hardFork.
+	// It is not scheduled on the main network yet.
+	ContractRegistryForkHeight = math.MaxUint64
+
+	// AccessControlForkHeight after this height the access control system contract is activated, and the contract
+	// deployments and calls are checked against it in the BFT networks: hardFork. It is not scheduled on the main network.
+	AccessControlForkHeight = math.MaxUint64
+
+	// MainChainID is the chain id of the main network, which is signed in the meta txs
+	MainChainID = 1
+
+	// MaxBlockFutureDrift is the max time the block time could be ahead of the local time
+	MaxBlockFutureDrift = 5 * time.Second
+
 	// LightChainDir lightchain data directory based on config.DataRoot
 	LightChainDir = "/db/lightchain"
 
@@ -67,6 +102,9 @@ const (
 	// zpow miner algorithm
 	ZpowAlgorithm = "zpow"
 
+	// DevAlgorithm instant seal algorithm for the local dev networks
+	DevAlgorithm = "dev"
+
 	// BFT mineralgorithm
 	BFTEngine = "bft"
 
@@ -0,0 +1,217 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+)
+
+// conflict policies when the key file to import already exists with different content
+const (
+	ConflictSkip      = "skip"      // keep the existing key file
+	ConflictOverwrite = "overwrite" // replace the existing key file, which is backed up first
+	ConflictRename    = "rename"    // import the key file with a new name
+)
+
+// import status of a key in the backup
+const (
+	ImportStatusImported    = "imported"
+	ImportStatusExists      = "exists" // the same key file already exists
+	ImportStatusSkipped     = "skipped"
+	ImportStatusOverwritten = "overwritten"
+	ImportStatusRenamed     = "renamed"
+)
+
+// Backup is the bundle of the key files in a directory. The key files are kept
+// encrypted by their own passwords, and the bundle is encrypted again when saved.
+type Backup struct {
+	Version int          `json:"version"`
+	Created int64        `json:"created"`
+	Keys    []*BackupKey `json:"keys"`
+}
+
+// BackupKey is a key file in the backup with its shard
+type BackupKey struct {
+	File    string `json:"file"`
+	Address string `json:"address"`
+	Shard   uint   `json:"shard"`
+	Content string `json:"content"` // key file content as is
+}
+
+// ImportResult is the import status of a key in the backup
+type ImportResult struct {
+	File    string // name of the key file in the backup
+	Address string
+	Shard   uint
+	Status  string
+	Path    string // path of the imported or existing key file
+}
+
+// ExportKeys bundles the key files in the directory, the files which are not key files are ignored.
+func ExportKeys(dir string) (*Backup, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &Backup{Version: Version, Created: time.Now().Unix()}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := newBackupKey(f.Name(), content)
+		if err != nil {
+			continue
+		}
+
+		backup.Keys = append(backup.Keys, key)
+	}
+
+	return backup, nil
+}
+
+// newBackupKey validates the key file content and returns the key with its shard
+func newBackupKey(name string, content []byte) (*BackupKey, error) {
+	k := new(encryptedKey)
+	if err := json.Unmarshal(content, k); err != nil {
+		return nil, err
+	}
+
+	if k.Crypto.CipherText == "" {
+		return nil, errors.New("no encrypted key")
+	}
+
+	address, err := common.HexToAddress(k.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackupKey{
+		File:    name,
+		Address: address.Hex(),
+		Shard:   address.Shard(),
+		Content: string(content),
+	}, nil
+}
+
+// EncryptBackup encrypts the backup with the password
+func EncryptBackup(backup *Backup, auth string) ([]byte, error) {
+	data, err := json.Marshal(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncryptData(data, auth)
+}
+
+// DecryptBackup decrypts the backup encrypted by EncryptBackup
+func DecryptBackup(content []byte, auth string) (*Backup, error) {
+	data, err := DecryptData(content, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := new(Backup)
+	if err = json.Unmarshal(data, backup); err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// ImportKeys writes the key files in the backup to the directory. A key file which already exists
+// with the same content is not written again, otherwise it is handled by the conflict policy.
+func ImportKeys(backup *Backup, dir string, conflict string) ([]*ImportResult, error) {
+	if conflict != ConflictSkip && conflict != ConflictOverwrite && conflict != ConflictRename {
+		return nil, fmt.Errorf("invalid conflict policy %q, it should be %s, %s or %s", conflict, ConflictSkip, ConflictOverwrite, ConflictRename)
+	}
+
+	// validate all keys before writing any of them
+	for _, key := range backup.Keys {
+		name := filepath.Base(key.File)
+		if name != key.File || name == "." || name == ".." {
+			return nil, fmt.Errorf("invalid key file name %q in the backup", key.File)
+		}
+
+		if _, err := newBackupKey(name, []byte(key.Content)); err != nil {
+			return nil, errors.NewStackedErrorf(err, "invalid key file %s in the backup", key.File)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	var results []*ImportResult
+	for _, key := range backup.Keys {
+		result, err := importKey(key, dir, conflict)
+		if err != nil {
+			return results, errors.NewStackedErrorf(err, "failed to import key file %s", key.File)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func importKey(key *BackupKey, dir string, conflict string) (*ImportResult, error) {
+	result := &ImportResult{
+		File:    key.File,
+		Address: key.Address,
+		Shard:   key.Shard,
+		Status:  ImportStatusImported,
+		Path:    filepath.Join(dir, key.File),
+	}
+
+	existing, err := ioutil.ReadFile(result.Path)
+	if os.IsNotExist(err) {
+		return result, common.SaveFile(result.Path, []byte(key.Content))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if string(existing) == key.Content {
+		result.Status = ImportStatusExists
+		return result, nil
+	}
+
+	switch conflict {
+	case ConflictOverwrite:
+		if err = common.SaveFile(fmt.Sprintf("%s.bak-%d", result.Path, time.Now().Unix()), existing); err != nil {
+			return nil, err
+		}
+
+		result.Status = ImportStatusOverwritten
+	case ConflictRename:
+		for i := 1; common.FileOrFolderExists(result.Path); i++ {
+			result.Path = filepath.Join(dir, fmt.Sprintf("%s.imported-%d", key.File, i))
+		}
+
+		result.Status = ImportStatusRenamed
+	default:
+		result.Status = ImportStatusSkipped
+		return result, nil
+	}
+
+	return result, common.SaveFile(result.Path, []byte(key.Content))
+}
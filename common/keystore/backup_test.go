@@ -0,0 +1,142 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package keystore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestKeyFile returns a key file content without the slow encryption
+func newTestKeyFile(t *testing.T, cipherText string) ([]byte, string) {
+	addr, _, err := crypto.GenerateKeyPair(1)
+	assert.Equal(t, err, nil)
+
+	content, err := json.MarshalIndent(encryptedKey{
+		Version: Version,
+		Address: addr.Hex(),
+		Crypto:  cryptoInfo{CipherText: cipherText},
+	}, "", "\t")
+	assert.Equal(t, err, nil)
+
+	return content, addr.Hex()
+}
+
+func Test_Backup_ExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	assert.Equal(t, os.MkdirAll(src, 0700), nil)
+
+	key1, addr1 := newTestKeyFile(t, "01")
+	key2, addr2 := newTestKeyFile(t, "02")
+	assert.Equal(t, ioutil.WriteFile(filepath.Join(src, "key1"), key1, 0600), nil)
+	assert.Equal(t, ioutil.WriteFile(filepath.Join(src, "key2"), key2, 0600), nil)
+	assert.Equal(t, ioutil.WriteFile(filepath.Join(src, "readme"), []byte("not a key"), 0600), nil)
+
+	backup, err := ExportKeys(src)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(backup.Keys), 2)
+	assert.Equal(t, backup.Keys[0].File, "key1")
+	assert.Equal(t, backup.Keys[0].Address, addr1)
+	assert.Equal(t, backup.Keys[1].Address, addr2)
+
+	// the key files are kept as is after json round trip
+	data, err := json.Marshal(backup)
+	assert.Equal(t, err, nil)
+	backup = new(Backup)
+	assert.Equal(t, json.Unmarshal(data, backup), nil)
+
+	results, err := ImportKeys(backup, dst, ConflictSkip)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, results[0].Status, ImportStatusImported)
+	assert.Equal(t, results[1].Status, ImportStatusImported)
+
+	content, err := ioutil.ReadFile(filepath.Join(dst, "key1"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, content, key1)
+
+	// import again
+	results, err = ImportKeys(backup, dst, ConflictSkip)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, results[0].Status, ImportStatusExists)
+	assert.Equal(t, results[1].Status, ImportStatusExists)
+}
+
+func Test_Backup_ImportConflict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	existing, _ := newTestKeyFile(t, "01")
+	assert.Equal(t, ioutil.WriteFile(filepath.Join(dir, "key"), existing, 0600), nil)
+
+	key, _ := newTestKeyFile(t, "02")
+	backup := &Backup{Version: Version, Keys: []*BackupKey{{File: "key", Content: string(key)}}}
+
+	// skip
+	results, err := ImportKeys(backup, dir, ConflictSkip)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, results[0].Status, ImportStatusSkipped)
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "key"))
+	assert.Equal(t, content, existing)
+
+	// rename
+	results, err = ImportKeys(backup, dir, ConflictRename)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, results[0].Status, ImportStatusRenamed)
+	assert.Equal(t, results[0].Path, filepath.Join(dir, "key.imported-1"))
+	content, _ = ioutil.ReadFile(results[0].Path)
+	assert.Equal(t, content, key)
+
+	// overwrite and the existing key file is backed up
+	results, err = ImportKeys(backup, dir, ConflictOverwrite)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, results[0].Status, ImportStatusOverwritten)
+	content, _ = ioutil.ReadFile(filepath.Join(dir, "key"))
+	assert.Equal(t, content, key)
+
+	matches, err := filepath.Glob(filepath.Join(dir, "key.bak-*"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(matches), 1)
+	content, _ = ioutil.ReadFile(matches[0])
+	assert.Equal(t, content, existing)
+
+	// invalid conflict policy and file name
+	_, err = ImportKeys(backup, dir, "merge")
+	assert.Equal(t, err != nil, true)
+
+	backup.Keys[0].File = "../key"
+	_, err = ImportKeys(backup, dir, ConflictSkip)
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_Backup_Encrypt(t *testing.T) {
+	key, addr := newTestKeyFile(t, "01")
+	backup := &Backup{Version: Version, Keys: []*BackupKey{{File: "key", Address: addr, Content: string(key)}}}
+
+	content, err := EncryptBackup(backup, "test")
+	assert.Equal(t, err, nil)
+
+	result, err := DecryptBackup(content, "test")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, result, backup)
+
+	_, err = DecryptBackup(content, "badpass")
+	assert.Equal(t, err != nil, true)
+}
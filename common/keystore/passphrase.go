@@ -32,6 +32,42 @@ const (
 // passphrase -> script function -> decryption key
 // decryption key + private key ->  aes-128-ctr algorithm -> encrypted private key
 func EncryptKey(key *Key, auth string) ([]byte, error) {
+	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
+	info, err := encrypt(keyBytes, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey := encryptedKey{
+		Version: Version,
+		Address: key.Address.Hex(),
+		Crypto:  *info,
+	}
+
+	return json.MarshalIndent(encryptedKey, "", "\t")
+}
+
+// EncryptData encrypts arbitrary data with the passphrase into a json, in the same way as the key
+func EncryptData(data []byte, auth string) ([]byte, error) {
+	info, err := encrypt(data, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(encryptedData{Version, *info}, "", "\t")
+}
+
+// DecryptData decrypts the data from a json blob encrypted by EncryptData
+func DecryptData(datajson []byte, auth string) ([]byte, error) {
+	d := new(encryptedData)
+	if err := json.Unmarshal(datajson, d); err != nil {
+		return nil, err
+	}
+
+	return decrypt(d.Version, &d.Crypto, auth)
+}
+
+func encrypt(plainText []byte, auth string) (*cryptoInfo, error) {
 	salt := getRandBuff(32)
 	scryptKey, err := getScryptKey(salt, auth)
 	if err != nil {
@@ -39,29 +75,20 @@ func EncryptKey(key *Key, auth string) ([]byte, error) {
 	}
 
 	encryptKey := scryptKey[:16]
-	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
-
 	iv := getRandBuff(aes.BlockSize) // 16
-	cipherText, err := aesCTRXOR(encryptKey, keyBytes, iv)
+	cipherText, err := aesCTRXOR(encryptKey, plainText, iv)
 	if err != nil {
 		return nil, err
 	}
 
 	mac := crypto.HashBytes(scryptKey[16:32], cipherText)
-	info := cryptoInfo{
+
+	return &cryptoInfo{
 		CipherText: hex.EncodeToString(cipherText),
 		CipherIV:   hex.EncodeToString(iv),
 		Salt:       hex.EncodeToString(salt),
 		MAC:        mac.Hex(),
-	}
-
-	encryptedKey := encryptedKey{
-		Version: Version,
-		Address: key.Address.Hex(),
-		Crypto:  info,
-	}
-
-	return json.MarshalIndent(encryptedKey, "", "\t")
+	}, nil
 }
 
 // DecryptKey decrypts a key from a json blob, returning the private key itself.
@@ -90,26 +117,30 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 }
 
 func doDecrypt(keyProtected *encryptedKey, auth string) ([]byte, error) {
-	if keyProtected.Version != Version {
-		return nil, errors.Create(errors.ErrKeyVersionMismatch, keyProtected.Version)
+	return decrypt(keyProtected.Version, &keyProtected.Crypto, auth)
+}
+
+func decrypt(version int, info *cryptoInfo, auth string) ([]byte, error) {
+	if version != Version {
+		return nil, errors.Create(errors.ErrKeyVersionMismatch, version)
 	}
 
-	mac, err := common.HexToHash(keyProtected.Crypto.MAC)
+	mac, err := common.HexToHash(info.MAC)
 	if err != nil {
 		return nil, err
 	}
 
-	iv, err := hex.DecodeString(keyProtected.Crypto.CipherIV)
+	iv, err := hex.DecodeString(info.CipherIV)
 	if err != nil {
 		return nil, err
 	}
 
-	cipherText, err := hex.DecodeString(keyProtected.Crypto.CipherText)
+	cipherText, err := hex.DecodeString(info.CipherText)
 	if err != nil {
 		return nil, err
 	}
 
-	salt, err := hex.DecodeString(keyProtected.Crypto.Salt)
+	salt, err := hex.DecodeString(info.Salt)
 	if err != nil {
 		return nil, err
 	}
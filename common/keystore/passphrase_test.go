@@ -50,3 +50,17 @@ func Test_PassPhrase(t *testing.T) {
 	_, err = DecryptKey(result, password)
 	assert.Equal(t, err.Error(), "Version not supported: 2")
 }
+
+func Test_EncryptData(t *testing.T) {
+	data := []byte("address book")
+
+	result, err := EncryptData(data, "test")
+	assert.Equal(t, err, nil)
+
+	decrypted, err := DecryptData(result, "test")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decrypted, data)
+
+	_, err = DecryptData(result, "badpass")
+	assert.Equal(t, err, errors.Get(errors.ErrDecrypt))
+}
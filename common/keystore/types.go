@@ -30,6 +30,11 @@ type encryptedKey struct {
 	Crypto  cryptoInfo `json:"crypto"`
 }
 
+type encryptedData struct {
+	Version int        `json:"version"`
+	Crypto  cryptoInfo `json:"crypto"`
+}
+
 type cryptoInfo struct {
 	CipherText string `json:"ciphertext"`
 	CipherIV   string `json:"iv"`
@@ -0,0 +1,88 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package common
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultNTPServer is the default NTP server to check the local clock
+	DefaultNTPServer = "pool.ntp.org"
+
+	// MaxClockDrift is the max drift of the local clock to mine, so that the mined
+	// blocks are not rejected by the nodes with correct clocks for MaxBlockFutureDrift.
+	MaxClockDrift = 3 * time.Second
+
+	ntpPacketSize = 48
+	ntpTimeout    = 3 * time.Second
+)
+
+// ntpEpoch is the start time of the NTP timestamps
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var errInvalidNTPReply = errors.New("invalid ntp reply")
+
+// ClockDrift queries the time from the NTP server with SNTP, and returns the drift of
+// the local clock, which is positive if the local clock is ahead of the server time.
+func ClockDrift(server string) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(server, "123"), ntpTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(ntpTimeout)); err != nil {
+		return 0, err
+	}
+
+	// leap indicator 0, version 3, mode 3 (client)
+	request := make([]byte, ntpPacketSize)
+	request[0] = 3<<3 | 3
+
+	sent := time.Now()
+	if _, err = conn.Write(request); err != nil {
+		return 0, err
+	}
+
+	reply := make([]byte, ntpPacketSize)
+	n, err := conn.Read(reply)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+
+	serverTime, err := parseNTPReply(reply[:n])
+	if err != nil {
+		return 0, err
+	}
+
+	// compare with the local time in the middle of the round trip
+	local := sent.Add(received.Sub(sent) / 2)
+
+	return local.Sub(serverTime), nil
+}
+
+// parseNTPReply returns the transmit time of the NTP reply
+func parseNTPReply(reply []byte) (time.Time, error) {
+	if len(reply) < ntpPacketSize {
+		return time.Time{}, errInvalidNTPReply
+	}
+
+	// the transmit timestamp in seconds and fraction since ntpEpoch
+	seconds := uint64(binary.BigEndian.Uint32(reply[40:44]))
+	fraction := uint64(binary.BigEndian.Uint32(reply[44:48]))
+	if seconds == 0 {
+		return time.Time{}, errInvalidNTPReply
+	}
+
+	nanos := seconds*uint64(time.Second) + fraction*uint64(time.Second)>>32
+
+	return ntpEpoch.Add(time.Duration(nanos)), nil
+}
@@ -0,0 +1,32 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package common
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseNTPReply(t *testing.T) {
+	expected := time.Date(2020, 1, 2, 3, 4, 5, 500000000, time.UTC)
+
+	reply := make([]byte, ntpPacketSize)
+	binary.BigEndian.PutUint32(reply[40:44], uint32(expected.Sub(ntpEpoch)/time.Second))
+	binary.BigEndian.PutUint32(reply[44:48], 1<<31) // half a second
+
+	actual, err := parseNTPReply(reply)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, actual.Equal(expected), true)
+
+	_, err = parseNTPReply(reply[:40])
+	assert.Equal(t, err, errInvalidNTPReply)
+
+	_, err = parseNTPReply(make([]byte, ntpPacketSize))
+	assert.Equal(t, err, errInvalidNTPReply)
+}
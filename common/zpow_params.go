@@ -0,0 +1,115 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package common
+
+import (
+	"math/big"
+)
+
+const (
+	// DefaultZpowMatrixDim is the dimension of the zpow mining matrix before any zpow params fork
+	DefaultZpowMatrixDim = 30
+
+	// DefaultZpowMultiplier is the multiplier of the difficulty to the zpow mining target before any zpow params fork
+	DefaultZpowMultiplier = 3000000000
+)
+
+// DefaultZpowMaxTarget is the bound of the zpow mining target, i.e. the determinant of the 30x30 mining matrix
+var DefaultZpowMaxTarget = new(big.Int).Mul(big.NewInt(2), new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil))
+
+// ZpowParams is the zpow algorithm parameter set activated at the fork height,
+// the zero values mean the default ones.
+type ZpowParams struct {
+	ForkHeight uint64   `json:"forkHeight"`
+	MatrixDim  uint64   `json:"matrixDim"`  // dimension of the mining matrix
+	Multiplier uint64   `json:"multiplier"` // the mining target is the difficulty multiplied by the multiplier
+	MaxTarget  *big.Int `json:"maxTarget"`  // bound of the mining target
+}
+
+// DefaultZpowParams returns the zpow params since the genesis
+func DefaultZpowParams() ZpowParams {
+	return ZpowParams{
+		MatrixDim:  DefaultZpowMatrixDim,
+		Multiplier: DefaultZpowMultiplier,
+		MaxTarget:  new(big.Int).Set(DefaultZpowMaxTarget),
+	}
+}
+
+// withDefaults returns the params whose zero values are replaced with the default ones
+func (p ZpowParams) withDefaults() ZpowParams {
+	if p.MatrixDim == 0 {
+		p.MatrixDim = DefaultZpowMatrixDim
+	}
+
+	if p.Multiplier == 0 {
+		p.Multiplier = DefaultZpowMultiplier
+	}
+
+	if p.MaxTarget == nil || p.MaxTarget.Sign() <= 0 {
+		p.MaxTarget = new(big.Int).Set(DefaultZpowMaxTarget)
+	} else {
+		p.MaxTarget = new(big.Int).Set(p.MaxTarget)
+	}
+
+	return p
+}
+
+// equal returns whether the params are the same after the default values are applied
+func (p ZpowParams) equal(other ZpowParams) bool {
+	p, other = p.withDefaults(), other.withDefaults()
+	return p.ForkHeight == other.ForkHeight && p.MatrixDim == other.MatrixDim &&
+		p.Multiplier == other.Multiplier && p.MaxTarget.Cmp(other.MaxTarget) == 0
+}
+
+// MiningTarget returns the mining target of the difficulty, the params must be returned by ZpowParamsAt
+func (p ZpowParams) MiningTarget(difficulty *big.Int) *big.Int {
+	target := new(big.Int).Mul(difficulty, new(big.Int).SetUint64(p.Multiplier))
+	if target.Cmp(p.MaxTarget) > 0 {
+		return new(big.Int).Set(p.MaxTarget)
+	}
+
+	return target
+}
+
+// ZpowParamsAt returns the zpow params activated at the height, i.e. the params of the latest zpow params
+// fork not after the height, or the default params if no such fork.
+func (c *ChainConfig) ZpowParamsAt(height uint64) ZpowParams {
+	params := DefaultZpowParams()
+	for _, p := range c.ZpowParams {
+		if p.ForkHeight <= height && p.ForkHeight >= params.ForkHeight {
+			params = p
+		}
+	}
+
+	return params.withDefaults()
+}
+
+// checkZpowParamsCompatible returns whether the zpow params of the forks passed by the chain head are not changed
+func checkZpowParamsCompatible(stored, current []ZpowParams, height uint64) bool {
+	passed := func(all []ZpowParams) []ZpowParams {
+		var result []ZpowParams
+		for _, p := range all {
+			if p.ForkHeight <= height {
+				result = append(result, p)
+			}
+		}
+
+		return result
+	}
+
+	stored, current = passed(stored), passed(current)
+	if len(stored) != len(current) {
+		return false
+	}
+
+	for i := range stored {
+		if !stored[i].equal(current[i]) {
+			return false
+		}
+	}
+
+	return true
+}
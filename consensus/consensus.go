@@ -6,6 +6,8 @@
 package consensus
 
 import (
+	"math/big"
+
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/rpc"
@@ -30,6 +32,31 @@ type Engine interface {
 	SetGpuBlocksThreads(blocks int, threads int)
 }
 
+// SolveEstimator is implemented by the engines whose chance to seal a block can be estimated by the difficulty
+type SolveEstimator interface {
+	// SolveProbability returns the probability that one nonce attempt seals a block of the difficulty
+	SolveProbability(difficulty *big.Int) float64
+}
+
+// InstantSealer is implemented by the engines which seal the block instantly without any work, so that
+// the miner only seals a new block when there are txs or debts to pack
+type InstantSealer interface {
+	Engine
+
+	// InstantSeal marks the engine seals the block instantly
+	InstantSeal()
+}
+
+// RangeSealer is implemented by the engines which could seal the block with a nonce in the given range, so that
+// the nonce space of a task could be shared by several workers without duplicated work
+type RangeSealer interface {
+	Engine
+
+	// SealRange seals the block with a nonce in [min, max], the sealed block is sent to the results,
+	// or nil if no nonce in the range is found
+	SealRange(block *types.Block, min uint64, max uint64, stop <-chan struct{}, results chan<- *types.Block)
+}
+
 // Istanbul is a consensus engine to avoid byzantine failure
 type Istanbul interface {
 	Engine
@@ -68,6 +95,9 @@ type Protocol struct {
 // ChainReader defines a small collection of methods needed to access the local
 // blockchain during header and/or uncle verification.
 type ChainReader interface {
+	// Config retrieves the fork activation heights of the chain.
+	Config() *common.ChainConfig
+
 	// CurrentHeader retrieves the current header from the local chain.
 	CurrentHeader() *types.BlockHeader
 
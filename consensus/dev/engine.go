@@ -0,0 +1,85 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package dev
+
+import (
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/rpc"
+)
+
+// difficulty is the constant difficulty of the dev blocks, so that the total difficulty still grows with the height
+var difficulty = big.NewInt(1)
+
+// Engine is the instant seal engine for the local dev networks, which seals the block without any work,
+// and the header is verified without the pow target.
+type Engine struct{}
+
+// NewEngine returns the instant seal engine
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+// Prepare sets the constant difficulty of the header
+func (engine *Engine) Prepare(reader consensus.ChainReader, header *types.BlockHeader) error {
+	if parent := reader.GetHeaderByHash(header.PreviousBlockHash); parent == nil {
+		return consensus.ErrBlockInvalidParentHash
+	}
+
+	header.Difficulty = new(big.Int).Set(difficulty)
+
+	return nil
+}
+
+// VerifyHeader verifies the height, timestamp and the constant difficulty of the header
+func (engine *Engine) VerifyHeader(reader consensus.ChainReader, header *types.BlockHeader) error {
+	parent := reader.GetHeaderByHash(header.PreviousBlockHash)
+	if parent == nil {
+		return consensus.ErrBlockInvalidParentHash
+	}
+
+	if header.Height != parent.Height+1 {
+		return consensus.ErrBlockInvalidHeight
+	}
+
+	if header.CreateTimestamp.Cmp(parent.CreateTimestamp) < 0 {
+		return consensus.ErrBlockCreateTimeOld
+	}
+
+	if header.Difficulty == nil || header.Difficulty.Cmp(difficulty) != 0 {
+		return consensus.ErrBlockDifficultInvalid
+	}
+
+	return nil
+}
+
+// Seal sends the block to the results immediately
+func (engine *Engine) Seal(reader consensus.ChainReader, block *types.Block, stop <-chan struct{}, results chan<- *types.Block) error {
+	go func() {
+		select {
+		case results <- block:
+		case <-stop:
+		}
+	}()
+
+	return nil
+}
+
+// InstantSeal implements the consensus.InstantSealer
+func (engine *Engine) InstantSeal() {}
+
+// APIs returns no rpc apis
+func (engine *Engine) APIs(chain consensus.ChainReader) []rpc.API {
+	return nil
+}
+
+// SetThreads does nothing since there is no work to seal a block
+func (engine *Engine) SetThreads(threads int) {}
+
+// SetGpuBlocksThreads does nothing since there is no work to seal a block
+func (engine *Engine) SetGpuBlocksThreads(blocks int, threads int) {}
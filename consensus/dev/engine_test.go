@@ -0,0 +1,82 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package dev
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockChainReader struct {
+	headers map[common.Hash]*types.BlockHeader
+}
+
+func (r *mockChainReader) Config() *common.ChainConfig                        { return common.DefaultChainConfig() }
+func (r *mockChainReader) CurrentHeader() *types.BlockHeader                  { return nil }
+func (r *mockChainReader) GetHeaderByHeight(height uint64) *types.BlockHeader { return nil }
+func (r *mockChainReader) GetBlockByHash(hash common.Hash) *types.Block       { return nil }
+func (r *mockChainReader) GetHeaderByHash(hash common.Hash) *types.BlockHeader {
+	return r.headers[hash]
+}
+
+func newTestHeaders() (*mockChainReader, *types.BlockHeader) {
+	parent := &types.BlockHeader{Height: 10, Difficulty: big.NewInt(1000), CreateTimestamp: big.NewInt(100)}
+	reader := &mockChainReader{map[common.Hash]*types.BlockHeader{parent.Hash(): parent}}
+
+	header := &types.BlockHeader{PreviousBlockHash: parent.Hash(), Height: 11, CreateTimestamp: big.NewInt(100)}
+
+	return reader, header
+}
+
+func Test_Engine_VerifyHeader(t *testing.T) {
+	engine := NewEngine()
+	reader, header := newTestHeaders()
+
+	assert.Equal(t, engine.Prepare(reader, header), nil)
+	assert.Equal(t, header.Difficulty.Int64(), int64(1))
+	assert.Equal(t, engine.VerifyHeader(reader, header), nil)
+
+	// no pow target is verified
+	header.Witness = []byte("12345")
+	assert.Equal(t, engine.VerifyHeader(reader, header), nil)
+
+	header.Difficulty = big.NewInt(2)
+	assert.Equal(t, engine.VerifyHeader(reader, header), consensus.ErrBlockDifficultInvalid)
+
+	header.Difficulty, header.Height = big.NewInt(1), 12
+	assert.Equal(t, engine.VerifyHeader(reader, header), consensus.ErrBlockInvalidHeight)
+
+	header.Height, header.CreateTimestamp = 11, big.NewInt(99)
+	assert.Equal(t, engine.VerifyHeader(reader, header), consensus.ErrBlockCreateTimeOld)
+
+	header.PreviousBlockHash = common.EmptyHash
+	assert.Equal(t, engine.Prepare(reader, header), consensus.ErrBlockInvalidParentHash)
+	assert.Equal(t, engine.VerifyHeader(reader, header), consensus.ErrBlockInvalidParentHash)
+}
+
+func Test_Engine_Seal(t *testing.T) {
+	var engine consensus.Engine = NewEngine()
+	_, ok := engine.(consensus.InstantSealer)
+	assert.Equal(t, ok, true)
+
+	reader, header := newTestHeaders()
+	assert.Equal(t, engine.Prepare(reader, header), nil)
+	block := types.NewBlock(header, nil, nil, nil)
+
+	results := make(chan *types.Block, 1)
+	assert.Equal(t, engine.Seal(reader, block, make(chan struct{}), results), nil)
+	assert.Equal(t, <-results, block)
+
+	// stopped before the result is received
+	stop := make(chan struct{})
+	close(stop)
+	assert.Equal(t, engine.Seal(reader, block, stop, make(chan *types.Block)), nil)
+}
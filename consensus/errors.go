@@ -17,6 +17,9 @@ var (
 	// ErrBlockCreateTimeOld is returned when block create time is previous of parent block time
 	ErrBlockCreateTimeOld = errors.New("block time must be later than parent block time")
 
+	// ErrBlockCreateTimeFuture is returned when block create time is too far ahead of the local time
+	ErrBlockCreateTimeFuture = errors.New("block time is too far in the future")
+
 	// ErrBlockInvalidParentHash is returned when inserting a new header with invalid parent block hash.
 	ErrBlockInvalidParentHash = errors.New("invalid parent block hash")
 
@@ -13,6 +13,7 @@ import (
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/consensus/dev"
 	"github.com/scdoproject/go-scdo/consensus/istanbul"
 	"github.com/scdoproject/go-scdo/consensus/istanbul/backend"
 	"github.com/scdoproject/go-scdo/consensus/pow"
@@ -28,6 +29,8 @@ func GetConsensusEngine(minerAlgorithm string) (consensus.Engine, error) {
 		minerEngine = pow.NewEngine(1)
 	} else if minerAlgorithm == common.ZpowAlgorithm {
 		minerEngine = zpow.NewZpowEngine(1)
+	} else if minerAlgorithm == common.DevAlgorithm {
+		minerEngine = dev.NewEngine()
 	} else {
 		return nil, fmt.Errorf("unknown miner algorithm")
 	}
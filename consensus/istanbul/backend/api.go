@@ -8,6 +8,8 @@ package backend
 import (
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/consensus/istanbul"
+	istanbulCore "github.com/scdoproject/go-scdo/consensus/istanbul/core"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/rpc"
 )
@@ -105,3 +107,96 @@ func (api *API) Discard(address common.Address) {
 
 	delete(api.istanbul.candidates, address)
 }
+
+const (
+	// defaultStatusBlocks is the default number of recent blocks to calculate the validator participation
+	defaultStatusBlocks = 64
+
+	// maxStatusBlocks is the max number of recent blocks to calculate the validator participation
+	maxStatusBlocks = 1024
+)
+
+// Participation is the participation of a validator in the recent blocks
+type Participation struct {
+	Proposed  uint64  `json:"proposed"`
+	Committed uint64  `json:"committed"`
+	Rate      float64 `json:"rate"` // the ratio of committed blocks
+}
+
+// Status is the consensus status and the validator participation over the recent blocks
+type Status struct {
+	istanbulCore.Status
+	Blocks        uint64                            `json:"blocks"`
+	Participation map[common.Address]*Participation `json:"participation"`
+}
+
+// Status returns the consensus status, including the current sequence and round, the last commit latency,
+// the round change counters and the validator participation over the last blocks (64 by default).
+func (api *API) Status(blocks *uint64) (*Status, error) {
+	n := uint64(defaultStatusBlocks)
+	if blocks != nil && *blocks > 0 {
+		n = *blocks
+	}
+
+	if n > maxStatusBlocks {
+		n = maxStatusBlocks
+	}
+
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+
+	snap, err := api.istanbul.snapshot(api.chain, header.Height, header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Status:        api.istanbul.core.Status(),
+		Participation: make(map[common.Address]*Participation),
+	}
+
+	for _, addr := range snap.validators() {
+		status.Participation[addr] = &Participation{}
+	}
+
+	getParticipation := func(addr common.Address) *Participation {
+		p, ok := status.Participation[addr]
+		if !ok {
+			p = &Participation{}
+			status.Participation[addr] = p
+		}
+
+		return p
+	}
+
+	// the genesis block has no committed seals
+	for ; header != nil && header.Height > 0 && status.Blocks < n; header = api.chain.GetHeaderByHash(header.PreviousBlockHash) {
+		status.Blocks++
+
+		if proposer, err := api.istanbul.Author(header); err == nil {
+			getParticipation(proposer).Proposed++
+		}
+
+		extra, err := types.ExtractIstanbulExtra(header)
+		if err != nil {
+			continue
+		}
+
+		proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+		for _, seal := range extra.CommittedSeal {
+			if addr, err := istanbul.GetSignatureAddress(proposalSeal, seal); err == nil {
+				getParticipation(addr).Committed++
+			}
+		}
+	}
+
+	if status.Blocks > 0 {
+		for _, p := range status.Participation {
+			p.Rate = float64(p.Committed) / float64(status.Blocks)
+		}
+	}
+
+	return status, nil
+}
@@ -23,7 +23,9 @@ import (
 	"github.com/scdoproject/go-scdo/core/txs"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database"
 	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/database/memorydb"
 )
 
 var genesisAccount = crypto.MustGenerateShardAddress(1)
@@ -50,6 +52,11 @@ func newTestGenesis(n int) (*core.Genesis, []*ecdsa.PrivateKey) {
 // other fake events to process Istanbul.
 func newBlockChain(n int) (*core.Blockchain, *backend) {
 	db, _ := leveldb.NewTestDatabase()
+	return newBlockChainWithDB(n, db)
+}
+
+// newBlockChainWithDB creates the blockchain and backend in the same way as newBlockChain on the given database.
+func newBlockChainWithDB(n int, db database.Database) (*core.Blockchain, *backend) {
 	bcStore := store.NewCachedStore(store.NewBlockchainDatabase(db))
 	genesis, nodeKeys := newTestGenesis(n)
 
@@ -244,6 +251,23 @@ func TestSealCommitted(t *testing.T) {
 	}
 }
 
+func TestSealCommittedMemoryDB(t *testing.T) {
+	db, dispose := memorydb.NewTestDatabase()
+	defer dispose()
+
+	chain, engine := newBlockChainWithDB(1, db)
+	block := makeBlockWithoutSeal(chain, engine, chain.Genesis())
+	expectedBlock, _ := engine.updateBlock(engine.chain.GetHeaderByHash(block.ParentHash()), block)
+
+	finalBlock, err := engine.SealWithReturn(chain, block, nil)
+	if err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+	if finalBlock.Hash() != expectedBlock.Hash() {
+		t.Errorf("hash mismatch: have %v, want %v", finalBlock.Hash(), expectedBlock.Hash())
+	}
+}
+
 func TestVerifyHeader(t *testing.T) {
 	chain, engine := newBlockChain(1)
 
@@ -19,7 +19,9 @@ import (
 	"github.com/scdoproject/go-scdo/core/store"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database"
 	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/database/memorydb"
 )
 
 type testerVote struct {
@@ -392,6 +394,20 @@ func TestVoting(t *testing.T) {
 }
 
 func TestSaveAndLoad(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	testSaveAndLoad(t, db)
+}
+
+func TestSaveAndLoadMemoryDB(t *testing.T) {
+	db, dispose := memorydb.NewTestDatabase()
+	defer dispose()
+
+	testSaveAndLoad(t, db)
+}
+
+func testSaveAndLoad(t *testing.T, db database.Database) {
 	snap := &Snapshot{
 		Epoch:  5,
 		Height: 10,
@@ -415,7 +431,6 @@ func TestSaveAndLoad(t *testing.T) {
 			common.BytesToAddress([]byte{5}),
 		}, istanbul.RoundRobin),
 	}
-	db, _ := leveldb.NewTestDatabase()
 	err := snap.store(db)
 	if err != nil {
 		t.Errorf("store snapshot failed: %v", err)
@@ -80,6 +80,8 @@ type core struct {
 	sequenceMeter metrics.Meter
 	// the timer to record consensus duration (from accepting a preprepare to final committed stage)
 	consensusTimer metrics.Timer
+	// the recorder of consensus status exposed by API
+	status statusRecorder
 }
 
 func (c *core) finalizeMessage(msg *message) ([]byte, error) {
@@ -178,6 +180,7 @@ func (c *core) startNewRound(round *big.Int) {
 
 		if !c.consensusTimestamp.IsZero() {
 			c.consensusTimer.UpdateSince(c.consensusTimestamp)
+			c.status.committed(time.Since(c.consensusTimestamp))
 			c.consensusTimestamp = time.Time{}
 		}
 		c.logger.Debug("Catch up latest proposal. height %d. hash %s", lastProposal.Height(), lastProposal.Hash())
@@ -230,6 +233,7 @@ func (c *core) startNewRound(round *big.Int) {
 		}
 	}
 	c.newRoundChangeTimer()
+	c.status.newRound(newView, c.valSet.GetProposer(), c.isProposer(), roundChange)
 
 	c.logger.Debug("New round", "new_round", newView.Round, "new_seq", newView.Sequence, "new_proposer", c.valSet.GetProposer(), "valSet", c.valSet.List(), "size", c.valSet.Size(), "isProposer", c.isProposer())
 }
@@ -244,6 +248,7 @@ func (c *core) catchUpRound(view *istanbul.View) {
 	c.updateRoundState(view, c.valSet, true)
 	c.roundChangeSet.Clear(view.Round)
 	c.newRoundChangeTimer()
+	c.status.newRound(view, c.valSet.GetProposer(), c.isProposer(), true)
 
 	c.logger.Debug("Catch up round. new_round %d. new_seq %d. new_proposer %s", view.Round, view.Sequence, c.valSet)
 }
@@ -265,6 +270,7 @@ func (c *core) updateRoundState(view *istanbul.View, validatorSet istanbul.Valid
 func (c *core) setState(state State) {
 	if c.state != state {
 		c.state = state
+		c.status.setState(state)
 	}
 	if state == StateAcceptRequest {
 		c.processPendingRequests()
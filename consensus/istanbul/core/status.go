@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus/istanbul"
+)
+
+// roundChangeStormThreshold is the number of round changes in a sequence regarded as a round change storm
+const roundChangeStormThreshold = 3
+
+// Status is the consensus status of the Istanbul core
+type Status struct {
+	Sequence            uint64         `json:"sequence"`
+	Round               uint64         `json:"round"`
+	State               string         `json:"state"`
+	Proposer            common.Address `json:"proposer"`
+	IsProposer          bool           `json:"isProposer"`
+	LastCommitLatencyMs float64        `json:"lastCommitLatencyMs"`
+	RoundChanges        uint64         `json:"roundChanges"`      // total round changes
+	Timeouts            uint64         `json:"timeouts"`          // total round change timeouts
+	RoundChangeStorms   uint64         `json:"roundChangeStorms"` // sequences which reached roundChangeStormThreshold rounds
+	ProposerChanges     uint64         `json:"proposerChanges"`
+}
+
+// statusRecorder records the consensus status, which is updated in the event loop and read by the API.
+type statusRecorder struct {
+	lock          sync.RWMutex
+	status        Status
+	stormSequence uint64 // the last sequence counted as a round change storm
+}
+
+func (r *statusRecorder) get() Status {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.status
+}
+
+// newRound records a new round of the view, roundChange indicates whether the round changes in the same sequence
+func (r *statusRecorder) newRound(view *istanbul.View, proposer istanbul.Validator, isProposer bool, roundChange bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	sequence, round := view.Sequence.Uint64(), view.Round.Uint64()
+	if roundChange && round > r.status.Round {
+		r.status.RoundChanges += round - r.status.Round
+	}
+
+	if round >= roundChangeStormThreshold && r.stormSequence != sequence {
+		r.stormSequence = sequence
+		r.status.RoundChangeStorms++
+	}
+
+	if proposer != nil {
+		if addr := proposer.Address(); addr != r.status.Proposer {
+			r.status.Proposer = addr
+			r.status.ProposerChanges++
+		}
+	}
+
+	r.status.Sequence = sequence
+	r.status.Round = round
+	r.status.IsProposer = isProposer
+}
+
+func (r *statusRecorder) setState(state State) {
+	r.lock.Lock()
+	r.status.State = state.String()
+	r.lock.Unlock()
+}
+
+func (r *statusRecorder) committed(latency time.Duration) {
+	r.lock.Lock()
+	r.status.LastCommitLatencyMs = float64(latency) / float64(time.Millisecond)
+	r.lock.Unlock()
+}
+
+func (r *statusRecorder) timeout() {
+	r.lock.Lock()
+	r.status.Timeouts++
+	r.lock.Unlock()
+}
+
+// Status implements core.Engine.Status
+func (c *core) Status() Status {
+	return c.status.get()
+}
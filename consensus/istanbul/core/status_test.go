@@ -0,0 +1,62 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus/istanbul"
+	"github.com/scdoproject/go-scdo/consensus/istanbul/validator"
+)
+
+func newTestView(sequence, round int64) *istanbul.View {
+	return &istanbul.View{
+		Sequence: big.NewInt(sequence),
+		Round:    big.NewInt(round),
+	}
+}
+
+func TestStatusRecorder(t *testing.T) {
+	var r statusRecorder
+	proposer1 := validator.New(common.BytesToAddress([]byte{1}))
+	proposer2 := validator.New(common.BytesToAddress([]byte{2}))
+
+	r.newRound(newTestView(1, 0), proposer1, true, false)
+	r.setState(StateAcceptRequest)
+	r.committed(1500 * time.Millisecond)
+
+	status := r.get()
+	if status.Sequence != 1 || status.Round != 0 || !status.IsProposer || status.State != StateAcceptRequest.String() {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if status.LastCommitLatencyMs != 1500 || status.ProposerChanges != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// round changes in sequence 2 until storm
+	r.newRound(newTestView(2, 0), proposer1, true, false)
+	for round := int64(1); round <= roundChangeStormThreshold+1; round++ {
+		r.timeout()
+		r.newRound(newTestView(2, round), proposer2, false, true)
+	}
+
+	status = r.get()
+	if status.RoundChanges != roundChangeStormThreshold+1 {
+		t.Errorf("round changes mismatch, got %d", status.RoundChanges)
+	}
+	if status.RoundChangeStorms != 1 {
+		t.Errorf("round change storms mismatch, got %d", status.RoundChangeStorms)
+	}
+	if status.Timeouts != roundChangeStormThreshold+1 {
+		t.Errorf("timeouts mismatch, got %d", status.Timeouts)
+	}
+	if status.Proposer != proposer2.Address() || status.ProposerChanges != 2 || status.IsProposer {
+		t.Errorf("unexpected proposer status %+v", status)
+	}
+}
@@ -16,6 +16,8 @@ import (
 type Engine interface {
 	Start() error
 	Stop() error
+	// Status returns the current consensus status
+	Status() Status
 }
 
 type State uint64
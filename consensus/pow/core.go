@@ -95,6 +95,46 @@ miner:
 	}
 }
 
+// searchRange searches the nonce in [min, max] for the header, and returns the header copy with the nonce
+// found, or nil if no nonce in the range is found or the search is aborted.
+func searchRange(header *types.BlockHeader, min uint64, max uint64, abort <-chan struct{}, isNonceFound *int32,
+	hashrate metrics.Meter) *types.BlockHeader {
+	var hashInt big.Int
+	var caltimes = int64(0)
+	defer func() { hashrate.Mark(caltimes) }()
+
+	target := getMiningTarget(header.Difficulty)
+	header = header.Clone()
+
+	for nonce := min; ; nonce++ {
+		select {
+		case <-abort:
+			return nil
+		default:
+		}
+
+		if atomic.LoadInt32(isNonceFound) != 0 {
+			return nil
+		}
+
+		caltimes++
+		if caltimes == 0x7FFF {
+			hashrate.Mark(caltimes)
+			caltimes = 0
+		}
+
+		header.Witness = []byte(strconv.FormatUint(nonce, 10))
+		hashInt.SetBytes(header.Hash().Bytes())
+		if hashInt.Cmp(target) <= 0 {
+			return header
+		}
+
+		if nonce == max {
+			return nil
+		}
+	}
+}
+
 // logAbort logs the info that nonce finding is aborted
 func logAbort(log *log.ScdoLog) {
 	log.Info("nonce finding aborted")
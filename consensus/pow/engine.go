@@ -11,6 +11,7 @@ import (
 	"math/rand"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rcrowley/go-metrics"
@@ -67,7 +68,7 @@ func (engine *Engine) VerifyHeader(reader consensus.ChainReader, header *types.B
 		return consensus.ErrBlockInvalidParentHash
 	}
 
-	if err := utils.VerifyHeaderCommon(header, parent); err != nil {
+	if err := utils.VerifyHeaderCommon(reader.Config(), header, parent); err != nil {
 		return err
 	}
 
@@ -87,7 +88,7 @@ func (engine *Engine) Prepare(reader consensus.ChainReader, header *types.BlockH
 		return consensus.ErrBlockInvalidParentHash
 	}
 
-	header.Difficulty = utils.GetDifficult(header.CreateTimestamp.Uint64(), parent)
+	header.Difficulty = utils.GetDifficult(reader.Config(), header.CreateTimestamp.Uint64(), parent)
 
 	return nil
 }
@@ -129,6 +130,69 @@ func (engine *Engine) Seal(reader consensus.ChainReader, block *types.Block, sto
 	return nil
 }
 
+// SealRange seals the block with a nonce in [min, max] only, which is the nonce range leased from the primary
+// node in the distributed solo mining. The range is split by the threads, and the sealed block is sent to
+// the results, or nil if no nonce in the range is found.
+func (engine *Engine) SealRange(block *types.Block, min uint64, max uint64, stop <-chan struct{}, results chan<- *types.Block) {
+	threads := engine.threads
+	if threads <= 0 || max-min < uint64(threads) {
+		threads = 1
+	}
+
+	var (
+		isNonceFound int32
+		found        *types.BlockHeader
+		once         sync.Once
+		wg           sync.WaitGroup
+	)
+
+	step := (max - min) / uint64(threads)
+	for i := 0; i < threads; i++ {
+		tmin := min + uint64(i)*step
+		tmax := tmin + step - 1
+		if i == threads-1 {
+			tmax = max
+		}
+
+		wg.Add(1)
+		go func(tmin uint64, tmax uint64) {
+			defer wg.Done()
+
+			if header := searchRange(block.Header, tmin, tmax, stop, &isNonceFound, engine.hashrate); header != nil {
+				once.Do(func() {
+					found = header
+					atomic.StoreInt32(&isNonceFound, 1)
+				})
+			}
+		}(tmin, tmax)
+	}
+
+	go func() {
+		wg.Wait()
+
+		var result *types.Block
+		if found != nil {
+			result = &types.Block{
+				HeaderHash:   found.Hash(),
+				Header:       found,
+				Transactions: block.Transactions,
+				Debts:        block.Debts,
+			}
+		}
+
+		select {
+		case <-stop:
+			logAbort(engine.log)
+		case results <- result:
+		}
+	}()
+}
+
+// VerifyTarget verifies whether the hash of the header is not greater than the mining target of its difficulty
+func VerifyTarget(header *types.BlockHeader) error {
+	return verifyTarget(header)
+}
+
 func verifyTarget(header *types.BlockHeader) error {
 	headerHash := header.Hash()
 	var hashInt big.Int
@@ -148,6 +212,17 @@ func getMiningTarget(difficulty *big.Int) *big.Int {
 	return new(big.Int).Div(maxUint256, difficulty)
 }
 
+// SolveProbability returns the probability that one hash is not greater than the mining target of the difficulty
+func (engine *Engine) SolveProbability(difficulty *big.Int) float64 {
+	if difficulty == nil || difficulty.Sign() <= 0 {
+		return 1
+	}
+
+	target := new(big.Int).Add(getMiningTarget(difficulty), big.NewInt(1))
+	p, _ := new(big.Float).Quo(new(big.Float).SetInt(target), new(big.Float).SetInt(maxUint256)).Float64()
+	return math.Min(p, 1)
+}
+
 // returns the second mining target based on the difference of current clock time
 // and the timestamp of parent block. Initially, the difficulty should be high
 // This function is used to determine which coinbase can mine.
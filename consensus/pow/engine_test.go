@@ -8,6 +8,7 @@ package pow
 import (
 	"math/big"
 	"runtime"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -114,3 +115,36 @@ func sealTest(t *testing.T, wg *sync.WaitGroup) {
 
 	engine.Seal(nil, block, stop, results)
 }
+
+func Test_SealRange(t *testing.T) {
+	engine := NewEngine(4)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// a nonce is found in the range
+	header := newTestBlockHeader(t)
+	header.Difficulty = big.NewInt(10)
+	results := make(chan *types.Block, 1)
+	engine.SealRange(&types.Block{Header: header}, 1000, 100000, stop, results)
+
+	block := <-results
+	assert.Equal(t, block != nil, true)
+	assert.Equal(t, verifyTarget(block.Header), nil)
+	assert.Equal(t, block.HeaderHash, block.Header.Hash())
+	nonce, err := strconv.ParseUint(string(block.Header.Witness), 10, 64)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, nonce >= 1000 && nonce <= 100000, true)
+
+	// nil if the range is exhausted
+	header.Difficulty = big.NewInt(10000000000)
+	engine.SealRange(&types.Block{Header: header}, 0, 100, stop, results)
+	assert.Equal(t, <-results == nil, true)
+}
+
+func Test_SolveProbability(t *testing.T) {
+	engine := NewEngine(1)
+
+	assert.Equal(t, engine.SolveProbability(big.NewInt(1)), float64(1))
+	assert.Equal(t, engine.SolveProbability(big.NewInt(4)), 0.25)
+	assert.Equal(t, engine.SolveProbability(big.NewInt(1000000)), 1e-6)
+}
@@ -61,3 +61,54 @@ func GetReward(blockHeight uint64) *big.Int {
 
 	return big.NewInt(0).Set(result)
 }
+
+// RewardEra is an era of the block reward schedule
+type RewardEra struct {
+	Era         int
+	StartHeight uint64   // the first block height of the era
+	EndHeight   uint64   // the last block height of the era
+	Reward      *big.Int // reward of each block of a shard in Wen
+}
+
+// GetRewardEra returns the reward era of the block height, the reward is 0 out of the schedule.
+func GetRewardEra(blockHeight uint64) *RewardEra {
+	era := int(blockHeight / blockNumberPerEra)
+
+	return &RewardEra{
+		Era:         era,
+		StartHeight: uint64(era) * blockNumberPerEra,
+		EndHeight:   uint64(era+1)*blockNumberPerEra - 1,
+		Reward:      GetReward(blockHeight),
+	}
+}
+
+// GetRewardSchedule returns the reward eras in order including the tail era, and
+// the block reward is 0 after the last era.
+func GetRewardSchedule() []*RewardEra {
+	eras := make([]*RewardEra, 0, len(rewardTableCoin)+1)
+	for era := 0; era <= len(rewardTableCoin); era++ {
+		eras = append(eras, GetRewardEra(uint64(era)*blockNumberPerEra))
+	}
+
+	return eras
+}
+
+// GetTotalReward returns the total reward of the blocks of a shard from height 0 to blockHeight inclusive
+func GetTotalReward(blockHeight uint64) *big.Int {
+	total := big.NewInt(0)
+	for _, era := range GetRewardSchedule() {
+		if era.StartHeight > blockHeight {
+			break
+		}
+
+		end := era.EndHeight
+		if end > blockHeight {
+			end = blockHeight
+		}
+
+		blocks := new(big.Int).SetUint64(end - era.StartHeight + 1)
+		total.Add(total, blocks.Mul(blocks, era.Reward))
+	}
+
+	return total
+}
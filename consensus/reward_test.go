@@ -40,3 +40,42 @@ func Test_RewardTotal(t *testing.T) {
 	assert.True(t, sum.Cmp(new(big.Int).Add(targetReward, duration)) < 0)
 	assert.True(t, sum.Cmp(new(big.Int).Sub(targetReward, duration)) > 0)
 }
+
+func Test_RewardSchedule(t *testing.T) {
+	eras := GetRewardSchedule()
+	assert.Equal(t, len(eras), len(rewardTableCoin)+1)
+
+	for i, era := range eras {
+		assert.Equal(t, era.Era, i)
+		assert.Equal(t, era.StartHeight, uint64(i)*blockNumberPerEra)
+		assert.Equal(t, era.EndHeight, uint64(i+1)*blockNumberPerEra-1)
+		assert.Equal(t, era.Reward, GetReward(era.StartHeight))
+		assert.Equal(t, GetRewardEra(era.EndHeight), era)
+	}
+
+	assert.Equal(t, eras[len(eras)-1].Reward, tailRewardCoin)
+	assert.Equal(t, GetRewardEra(blockNumberPerEra*uint64(len(eras))).Reward, big.NewInt(0))
+}
+
+func Test_TotalReward(t *testing.T) {
+	assert.Equal(t, GetTotalReward(0), rewardTableCoin[0])
+	assert.Equal(t, GetTotalReward(9), new(big.Int).Mul(rewardTableCoin[0], big.NewInt(10)))
+
+	// the genesis allocation of the mined rewards before fork
+	assert.Equal(t, GetTotalReward(common.ScdoForkHeight-1), new(big.Int).Mul(GetReward(common.ScdoForkHeight), big.NewInt(common.ScdoForkHeight)))
+
+	// the first block of the second era
+	expected := new(big.Int).Mul(rewardTableCoin[0], new(big.Int).SetUint64(blockNumberPerEra))
+	assert.Equal(t, GetTotalReward(blockNumberPerEra), expected.Add(expected, rewardTableCoin[1]))
+
+	// no more reward after the schedule
+	end := blockNumberPerEra*uint64(len(rewardTableCoin)+1) - 1
+	assert.Equal(t, GetTotalReward(end+1000), GetTotalReward(end))
+
+	// same as the sum of block rewards
+	sum := big.NewInt(0)
+	for i := uint64(0); i <= 2*blockNumberPerEra; i++ {
+		sum.Add(sum, GetReward(i))
+	}
+	assert.Equal(t, GetTotalReward(2*blockNumberPerEra), sum)
+}
@@ -14,7 +14,7 @@ import (
 )
 
 // getDifficult adjust difficult by parent info
-func GetDifficult(time uint64, parentHeader *types.BlockHeader) *big.Int {
+func GetDifficult(config *common.ChainConfig, time uint64, parentHeader *types.BlockHeader) *big.Int {
 	// algorithm:
 	// diff = parentDiff + parentDiff / 1024 * max (1 - (blockTime - parentTime) / 20, -99)
 	// target block time is 20 seconds
@@ -38,7 +38,7 @@ func GetDifficult(time uint64, parentHeader *types.BlockHeader) *big.Int {
 	}
 
 	var y = new(big.Int).Set(parentDifficult)
-	if parentHeader.Height < common.SecondForkHeight {
+	if !config.IsSecondFork(parentHeader.Height) {
 		y.Div(parentDifficult, big2048)
 	} else {
 		y.Div(parentDifficult, big1024)
@@ -52,8 +52,8 @@ func GetDifficult(time uint64, parentHeader *types.BlockHeader) *big.Int {
 }
 
 // VerifyDifficulty verify the difficulty of the given block based on parent info
-func VerifyDifficulty(parent *types.BlockHeader, header *types.BlockHeader) error {
-	difficult := GetDifficult(header.CreateTimestamp.Uint64(), parent)
+func VerifyDifficulty(config *common.ChainConfig, parent *types.BlockHeader, header *types.BlockHeader) error {
+	difficult := GetDifficult(config, header.CreateTimestamp.Uint64(), parent)
 	if difficult.Cmp(header.Difficulty) != 0 {
 		return consensus.ErrBlockDifficultInvalid
 	}
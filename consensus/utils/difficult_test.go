@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,5 +46,5 @@ func getDiffWithHeight(interval uint64, diff *big.Int, height uint64) *big.Int {
 		Height:          height,
 	}
 
-	return GetDifficult(interval, header)
+	return GetDifficult(common.DefaultChainConfig(), interval, header)
 }
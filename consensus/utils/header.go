@@ -6,13 +6,17 @@
 package utils
 
 import (
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/consensus"
 	"github.com/scdoproject/go-scdo/core/types"
 )
 
 // VerifyHeaderCommon verify the height, timestamp and difficulty
 // of the given header based on parent info
-func VerifyHeaderCommon(header, parent *types.BlockHeader) error {
+func VerifyHeaderCommon(config *common.ChainConfig, header, parent *types.BlockHeader) error {
 	if header.Height != parent.Height+1 {
 		return consensus.ErrBlockInvalidHeight
 	}
@@ -21,7 +25,14 @@ func VerifyHeaderCommon(header, parent *types.BlockHeader) error {
 		return consensus.ErrBlockCreateTimeOld
 	}
 
-	if err := VerifyDifficulty(parent, header); err != nil {
+	if config.IsBlockTimeDrift(header.Height) {
+		future := big.NewInt(time.Now().Add(common.MaxBlockFutureDrift).Unix())
+		if header.CreateTimestamp.Cmp(future) > 0 {
+			return consensus.ErrBlockCreateTimeFuture
+		}
+	}
+
+	if err := VerifyDifficulty(config, parent, header); err != nil {
 		return err
 	}
 
@@ -0,0 +1,69 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package utils
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHeader(parent *types.BlockHeader, timestamp int64) *types.BlockHeader {
+	header := &types.BlockHeader{
+		CreateTimestamp: big.NewInt(timestamp),
+		Height:          parent.Height + 1,
+	}
+	header.Difficulty = GetDifficult(common.DefaultChainConfig(), uint64(timestamp), parent)
+
+	return header
+}
+
+func Test_VerifyHeaderCommon_FutureDrift(t *testing.T) {
+	config := common.DefaultChainConfig()
+	config.BlockTimeDriftForkHeight = common.ScdoForkHeight + 100
+	now := time.Now().Unix()
+	parent := &types.BlockHeader{
+		CreateTimestamp: big.NewInt(now - 20),
+		Difficulty:      big.NewInt(10000),
+		Height:          config.BlockTimeDriftForkHeight,
+	}
+
+	assert.Equal(t, VerifyHeaderCommon(config, newTestHeader(parent, now), parent), nil)
+
+	future := now + int64(common.MaxBlockFutureDrift/time.Second) + 60
+	assert.Equal(t, VerifyHeaderCommon(config, newTestHeader(parent, future), parent), consensus.ErrBlockCreateTimeFuture)
+
+	// not checked before the fork
+	parent.Height = config.BlockTimeDriftForkHeight - 2
+	assert.Equal(t, VerifyHeaderCommon(config, newTestHeader(parent, future), parent), nil)
+}
+
+func Test_VerifyHeaderCommon_ChainConfig(t *testing.T) {
+	config := common.DefaultChainConfig()
+	now := time.Now().Unix()
+	parent := &types.BlockHeader{
+		CreateTimestamp: big.NewInt(now - 20),
+		Difficulty:      big.NewInt(10000),
+		Height:          10,
+	}
+
+	future := now + int64(common.MaxBlockFutureDrift/time.Second) + 60
+	assert.Equal(t, VerifyHeaderCommon(config, newTestHeader(parent, future), parent), nil)
+
+	// not scheduled on the main network
+	parent.Height = common.ScdoForkHeight + 10
+	assert.Equal(t, VerifyHeaderCommon(config, newTestHeader(parent, future), parent), nil)
+	parent.Height = 10
+
+	// the fork is activated at the height of the chain config
+	config.BlockTimeDriftForkHeight = 11
+	assert.Equal(t, VerifyHeaderCommon(config, newTestHeader(parent, future), parent), consensus.ErrBlockCreateTimeFuture)
+}
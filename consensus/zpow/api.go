@@ -5,8 +5,14 @@
 
 package zpow
 
+import (
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus"
+)
+
 type API struct {
 	engine *ZpowEngine
+	chain  consensus.ChainReader
 }
 
 // GetDetrate returns the current detrate for local CPU miner and remote miner.
@@ -18,3 +24,13 @@ func (api *API) GetDetrate() uint64 {
 func (api *API) GetThreads() int {
 	return api.engine.threads
 }
+
+// GetAlgorithmParams returns the zpow algorithm params activated at the height, or the current height
+// for negative value.
+func (api *API) GetAlgorithmParams(height int64) common.ZpowParams {
+	if height < 0 {
+		return api.chain.Config().ZpowParamsAt(api.chain.CurrentHeader().Height)
+	}
+
+	return api.chain.Config().ZpowParamsAt(uint64(height))
+}
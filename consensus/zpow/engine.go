@@ -7,7 +7,6 @@ void Determinant(int* hashBytes, double* retDets, int Blocks, int Threads, int m
 import "C"
 
 import (
-	"encoding/binary"
 	"math"
 	"math/big"
 	"math/rand"
@@ -20,8 +19,8 @@ import (
 	"github.com/rcrowley/go-metrics"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/consensus"
-	"github.com/scdoproject/go-scdo/consensus/scdorand"
 	"github.com/scdoproject/go-scdo/consensus/utils"
+	"github.com/scdoproject/go-scdo/consensus/zpow/verifier"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/log"
 	"github.com/scdoproject/go-scdo/rpc"
@@ -30,13 +29,6 @@ import (
 
 //note: the path /usr/lib/cuda/lib64 shall be changed to point to the local lib
 
-var (
-	// bound of the determinant
-	maxDet30x30 = new(big.Int).Mul(big.NewInt(2), new(big.Int).Exp(big.NewInt(10), big.NewInt(30), big.NewInt(0)))
-	matrixDim   = int(30)
-	multiplier  = big.NewInt(3000000000)
-)
-
 // Engine provides the consensus operations based on ZPOW.
 type ZpowEngine struct {
 	threads      int
@@ -45,6 +37,8 @@ type ZpowEngine struct {
 	log          *log.ScdoLog
 	detrate      metrics.Meter
 	lock         sync.Mutex
+
+	params common.ZpowParams // zpow params of the latest prepared or verified header
 }
 
 func NewZpowEngine(threads int) *ZpowEngine {
@@ -53,6 +47,7 @@ func NewZpowEngine(threads int) *ZpowEngine {
 		threads: threads,
 		log:     log.GetLogger("zpow_engine"),
 		detrate: metrics.NewMeter(),
+		params:  common.DefaultZpowParams(),
 	}
 }
 
@@ -84,7 +79,7 @@ func (engine *ZpowEngine) APIs(chain consensus.ChainReader) []rpc.API {
 		{
 			Namespace: "miner",
 			Version:   "1.0",
-			Service:   &API{engine},
+			Service:   &API{engine, chain},
 			Public:    true,
 		},
 	}
@@ -97,11 +92,28 @@ func (engine *ZpowEngine) Prepare(reader consensus.ChainReader, header *types.Bl
 		return consensus.ErrBlockInvalidParentHash
 	}
 
-	header.Difficulty = utils.GetDifficult(header.CreateTimestamp.Uint64(), parent)
+	header.Difficulty = utils.GetDifficult(reader.Config(), header.CreateTimestamp.Uint64(), parent)
+	engine.setParams(reader.Config().ZpowParamsAt(header.Height))
 
 	return nil
 }
 
+// setParams updates the zpow params of the latest header
+func (engine *ZpowEngine) setParams(params common.ZpowParams) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	engine.params = params
+}
+
+// currentParams returns the zpow params of the latest prepared or verified header
+func (engine *ZpowEngine) currentParams() common.ZpowParams {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	return engine.params
+}
+
 // Seal partitions the nonces for the threads and let the threads mine in parallel
 func (engine *ZpowEngine) Seal(reader consensus.ChainReader, block *types.Block, stop <-chan struct{}, results chan<- *types.Block) error {
 	threads := engine.threads
@@ -142,9 +154,9 @@ func (engine *ZpowEngine) Seal(reader consensus.ChainReader, block *types.Block,
 
 		go func(tseed uint64, tmin uint64, tmax uint64, GPU bool) {
 			if GPU {
-				engine.StartMiningGpu(block, tseed, tmin, tmax, results, stop, &isNonceFound, once, engine.detrate, engine.log)
+				engine.StartMiningGpu(reader.Config(), block, tseed, tmin, tmax, results, stop, &isNonceFound, once, engine.detrate, engine.log)
 			} else {
-				engine.StartMining(block, tseed, tmin, tmax, results, stop, &isNonceFound, once, engine.detrate, engine.log)
+				engine.StartMining(reader.Config(), block, tseed, tmin, tmax, results, stop, &isNonceFound, once, engine.detrate, engine.log)
 
 			}
 		}(tSeed, min, max, gpu)
@@ -154,14 +166,15 @@ func (engine *ZpowEngine) Seal(reader consensus.ChainReader, block *types.Block,
 }
 
 // StartMining is the core mining rountine
-func (engine *ZpowEngine) StartMiningGpu(block *types.Block, seed uint64, min uint64, max uint64, result chan<- *types.Block, abort <-chan struct{},
+func (engine *ZpowEngine) StartMiningGpu(config *common.ChainConfig, block *types.Block, seed uint64, min uint64, max uint64, result chan<- *types.Block, abort <-chan struct{},
 	isNonceFound *int32, once *sync.Once, detrate metrics.Meter, log *log.ScdoLog) {
 	var nonce = seed
 	var caltimes = int64(0)
-	target := new(big.Float).SetInt(getMiningTarget(block.Header.Difficulty))
+	params := config.ZpowParamsAt(block.Header.Height)
+	target := new(big.Float).SetInt(verifier.MiningTarget(params, block.Header.Difficulty))
 	header := block.Header.Clone()
 	numBytes := 32
-	dim := matrixDim
+	dim := int(params.MatrixDim)
 	blocks := engine.blocks        //gpu thread blocks
 	threads := engine.blockthreads //gpu threads per block
 
@@ -268,13 +281,14 @@ miner:
 	}
 }
 
-func (engine *ZpowEngine) StartMining(block *types.Block, seed uint64, min uint64, max uint64, result chan<- *types.Block, abort <-chan struct{},
+func (engine *ZpowEngine) StartMining(config *common.ChainConfig, block *types.Block, seed uint64, min uint64, max uint64, result chan<- *types.Block, abort <-chan struct{},
 	isNonceFound *int32, once *sync.Once, detrate metrics.Meter, log *log.ScdoLog) {
 	var nonce = seed
 	var caltimes = int64(0)
-	target := new(big.Float).SetInt(getMiningTarget(block.Header.Difficulty))
+	params := config.ZpowParamsAt(block.Header.Height)
+	target := new(big.Float).SetInt(verifier.MiningTarget(params, block.Header.Difficulty))
 	header := block.Header.Clone()
-	dim := matrixDim
+	dim := int(params.MatrixDim)
 miner:
 	for {
 		select {
@@ -298,7 +312,7 @@ miner:
 			header.Witness = []byte(strconv.FormatUint(nonce, 10))
 			hash := header.Hash()
 
-			matrix := generateRandomMat(hash, dim, header.Height)
+			matrix := verifier.RandomMatrix(hash, dim, config.IsEmery(header.Height))
 			// compute matrix det
 
 			res := mat.Det(matrix)
@@ -350,88 +364,25 @@ func (engine *ZpowEngine) VerifyHeader(reader consensus.ChainReader, header *typ
 		return consensus.ErrBlockInvalidParentHash
 	}
 
-	if err := utils.VerifyHeaderCommon(header, parent); err != nil {
+	if err := utils.VerifyHeaderCommon(reader.Config(), header, parent); err != nil {
 		return err
 	}
 
-	if err := engine.verifyTarget(header); err != nil {
+	if err := engine.verifyTarget(reader.Config(), header); err != nil {
 		return err
 	}
 
-	return nil
-}
+	engine.setParams(reader.Config().ZpowParamsAt(header.Height))
 
-// verifyTarget verifies whether the nonce is a valid solution
-func (engine *ZpowEngine) verifyTarget(header *types.BlockHeader) error {
-	dim := matrixDim
-	NewHeader := header.Clone()
-	hash := NewHeader.Hash()
-
-	// generate matrix
-	matrix := generateRandomMat(hash, dim, header.Height)
-
-	// compute matrix det
-	res := mat.Det(matrix)
-	restBig := big.NewFloat(res)
-	target := new(big.Float).SetInt(getMiningTarget(header.Difficulty))
-	if restBig.Cmp(target) < 0 {
-		return consensus.ErrBlockNonceInvalid
-	}
 	return nil
 }
 
-// getMiningTarget returns the mining target for the specified difficulty.
-func getMiningTarget(difficulty *big.Int) *big.Int {
-	target := new(big.Int).Mul(difficulty, multiplier)
-	if target.Cmp(maxDet30x30) > 0 {
-		return maxDet30x30
-	}
-	return target
+// verifyTarget verifies whether the nonce is a valid solution
+func (engine *ZpowEngine) verifyTarget(config *common.ChainConfig, header *types.BlockHeader) error {
+	return verifier.VerifyTarget(config, header)
 }
 
 // logAbort logs the info that nonce finding is aborted
 func logAbort(log *log.ScdoLog) {
 	log.Info("nonce finding aborted")
 }
-
-// bytesToInt64 converts a byte array to int64
-// note that the input should have at most 8 bytes
-
-func bytesToInt64(buf []byte) int64 {
-
-	return int64(binary.BigEndian.Uint64(buf))
-}
-
-// generateRandomMat generates a random matrix given a hash and the size of
-// the matrix
-func generateRandomMat(hash common.Hash, dim int, height uint64) *mat.Dense {
-	matrix := mat.NewDense(dim, dim, nil)
-	hashBytes := hash.Bytes()
-	var hashSeed [4]int64
-	curNum := int64(0)
-	hashSeed[0] = bytesToInt64(hashBytes[:8])
-	hashSeed[1] = bytesToInt64(hashBytes[8:16])
-	hashSeed[2] = bytesToInt64(hashBytes[16:24])
-	hashSeed[3] = bytesToInt64(hashBytes[24:32])
-
-	for i := 0; i < dim; i++ {
-		curNum ^= hashSeed[i%4]
-		var randObj *scdorand.RandObj
-		// EmeryFork enhances the generation of random state
-		if height >= common.EmeryForkHeight {
-			randObj = scdorand.NewRandObj(scdorand.NewSource_EmeryFork(curNum))
-		} else {
-			randObj = scdorand.NewRandObj(scdorand.NewSource(curNum))
-		}
-
-		for j := 0; j < dim; j++ {
-
-			curNum = randObj.Int63n(1<<63 - 1)
-
-			matrix.Set(i, j, float64(randObj.Int63n(3)))
-
-		}
-
-	}
-	return matrix
-}
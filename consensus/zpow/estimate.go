@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package zpow
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"sync"
+
+	"github.com/scdoproject/go-scdo/consensus/zpow/verifier"
+	"gonum.org/v1/gonum/mat"
+)
+
+// detSamples is the number of random matrices sampled to estimate the determinant distribution
+const detSamples = 4096
+
+// logDetStats is the mean and standard deviation of the log absolute determinant
+type logDetStats struct {
+	mean float64
+	std  float64
+}
+
+var (
+	logDetLock  sync.Mutex
+	logDetCache = make(map[int]logDetStats) // matrix dimension -> stats
+)
+
+// logDetDistribution returns the mean and standard deviation of the log absolute determinant of the
+// mining matrix of the dimension, which are sampled once with the same entries as verifier.RandomMatrix.
+func logDetDistribution(dim int) (float64, float64) {
+	logDetLock.Lock()
+	defer logDetLock.Unlock()
+
+	if stats, ok := logDetCache[dim]; ok {
+		return stats.mean, stats.std
+	}
+
+	r := rand.New(rand.NewSource(1))
+	matrix := mat.NewDense(dim, dim, nil)
+
+	var sum, sumSquare float64
+	n := 0
+	for i := 0; i < detSamples; i++ {
+		for row := 0; row < dim; row++ {
+			for col := 0; col < dim; col++ {
+				matrix.Set(row, col, float64(r.Int63n(3)))
+			}
+		}
+
+		// skip the singular matrix
+		logDet, _ := mat.LogDet(matrix)
+		if math.IsInf(logDet, 0) || math.IsNaN(logDet) {
+			continue
+		}
+
+		sum += logDet
+		sumSquare += logDet * logDet
+		n++
+	}
+
+	var stats logDetStats
+	if n > 0 {
+		stats.mean = sum / float64(n)
+		stats.std = math.Sqrt(math.Max(sumSquare/float64(n)-stats.mean*stats.mean, 0))
+	}
+
+	logDetCache[dim] = stats
+	return stats.mean, stats.std
+}
+
+// SolveProbability returns the probability that the determinant of one mining matrix reaches the
+// mining target of the difficulty with the zpow params of the latest header. The log absolute determinant of the random matrix is approximately
+// normal, and the sign of the determinant is symmetric, so only half of the matrices are positive.
+func (engine *ZpowEngine) SolveProbability(difficulty *big.Int) float64 {
+	if difficulty == nil || difficulty.Sign() <= 0 {
+		return 0.5
+	}
+
+	params := engine.currentParams()
+	target, _ := new(big.Float).SetInt(verifier.MiningTarget(params, difficulty)).Float64()
+	mean, std := logDetDistribution(int(params.MatrixDim))
+	if std == 0 {
+		if math.Log(target) > mean {
+			return 0
+		}
+
+		return 0.5
+	}
+
+	return 0.25 * math.Erfc((math.Log(target)-mean)/(std*math.Sqrt2))
+}
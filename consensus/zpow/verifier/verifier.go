@@ -0,0 +1,82 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+// Package verifier verifies the zpow solution of the block header without cgo, so that it could be
+// used by the tools which audit the blocks without the gpu libraries of the zpow miner.
+package verifier
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/consensus/scdorand"
+	"github.com/scdoproject/go-scdo/core/types"
+	"gonum.org/v1/gonum/mat"
+)
+
+// VerifyTarget verifies whether the nonce of the header is a valid solution
+func VerifyTarget(config *common.ChainConfig, header *types.BlockHeader) error {
+	hash := header.Clone().Hash()
+	params := config.ZpowParamsAt(header.Height)
+
+	// generate matrix
+	matrix := RandomMatrix(hash, int(params.MatrixDim), config.IsEmery(header.Height))
+
+	// compute matrix det
+	res := mat.Det(matrix)
+	restBig := big.NewFloat(res)
+	target := new(big.Float).SetInt(MiningTarget(params, header.Difficulty))
+	if restBig.Cmp(target) < 0 {
+		return consensus.ErrBlockNonceInvalid
+	}
+	return nil
+}
+
+// MiningTarget returns the mining target for the specified difficulty with the zpow params.
+func MiningTarget(params common.ZpowParams, difficulty *big.Int) *big.Int {
+	return params.MiningTarget(difficulty)
+}
+
+// bytesToInt64 converts a byte array to int64
+// note that the input should have at most 8 bytes
+func bytesToInt64(buf []byte) int64 {
+	return int64(binary.BigEndian.Uint64(buf))
+}
+
+// RandomMatrix generates a random matrix given a hash and the size of
+// the matrix
+func RandomMatrix(hash common.Hash, dim int, emeryFork bool) *mat.Dense {
+	matrix := mat.NewDense(dim, dim, nil)
+	hashBytes := hash.Bytes()
+	var hashSeed [4]int64
+	curNum := int64(0)
+	hashSeed[0] = bytesToInt64(hashBytes[:8])
+	hashSeed[1] = bytesToInt64(hashBytes[8:16])
+	hashSeed[2] = bytesToInt64(hashBytes[16:24])
+	hashSeed[3] = bytesToInt64(hashBytes[24:32])
+
+	for i := 0; i < dim; i++ {
+		curNum ^= hashSeed[i%4]
+		var randObj *scdorand.RandObj
+		// EmeryFork enhances the generation of random state
+		if emeryFork {
+			randObj = scdorand.NewRandObj(scdorand.NewSource_EmeryFork(curNum))
+		} else {
+			randObj = scdorand.NewRandObj(scdorand.NewSource(curNum))
+		}
+
+		for j := 0; j < dim; j++ {
+
+			curNum = randObj.Int63n(1<<63 - 1)
+
+			matrix.Set(i, j, float64(randObj.Int63n(3)))
+
+		}
+
+	}
+	return matrix
+}
@@ -0,0 +1,215 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package system
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+)
+
+const (
+	gasVoteAccessRule = uint64(50000)
+	gasGetAccessRule  = uint64(5000)
+)
+
+const (
+	// CmdVoteAccessRule votes for an access rule by a validator, the rule takes effect once voted by a quorum
+	CmdVoteAccessRule byte = iota
+	// CmdGetAccessRule gets the permission of an access rule
+	CmdGetAccessRule
+)
+
+const (
+	// AccessDeploy is the action to deploy contracts
+	AccessDeploy byte = iota + 1
+	// AccessCall is the action to call a contract
+	AccessCall
+)
+
+const (
+	// AccessUnset removes the permission of the rule
+	AccessUnset byte = iota
+	// AccessAllow allows the action
+	AccessAllow
+	// AccessDeny denies the action
+	AccessDeny
+)
+
+var (
+	// ErrAccessDenied is returned when the contract deployment or call is denied by the access control
+	ErrAccessDenied = errors.New("denied by the access control")
+
+	errAccessNotPermissioned = errors.New("Failed to vote, access control is only available with the BFT consensus")
+	errAccessNotValidator    = errors.New("Failed to vote, the sender is not a validator")
+	errAccessInvalidRule     = errors.New("Failed to vote, invalid access rule")
+
+	accessVotePrefix = []byte("vote")
+
+	accessControlCommands = map[byte]*cmdInfo{
+		CmdVoteAccessRule: &cmdInfo{gasVoteAccessRule, voteAccessRule},
+		CmdGetAccessRule:  &cmdInfo{gasGetAccessRule, getAccessRule},
+	}
+)
+
+// AccessRule allows or denies the account to deploy contracts, or to call the target contract. The empty account
+// sets the default permission of the action for all the accounts without their own rules, e.g. deny deploying
+// contracts by default and allow some deployers, and the action is allowed if neither is set.
+type AccessRule struct {
+	Action     byte
+	Target     common.Address // the called contract for AccessCall, empty for AccessDeploy
+	Account    common.Address // the sender of the tx, empty for all the accounts
+	Permission byte
+}
+
+// AccessVotes is the validators who voted for an access rule which doesn't take effect yet
+type AccessVotes struct {
+	Rule   AccessRule
+	Voters []common.Address
+}
+
+// Validate validates the action and permission of the rule
+func (r *AccessRule) Validate() error {
+	switch r.Action {
+	case AccessDeploy:
+		if !r.Target.IsEmpty() {
+			return errAccessInvalidRule
+		}
+	case AccessCall:
+		if r.Target.IsEmpty() || r.Target == AccessControlContractAddress {
+			return errAccessInvalidRule
+		}
+	default:
+		return errAccessInvalidRule
+	}
+
+	if r.Permission > AccessDeny {
+		return errAccessInvalidRule
+	}
+
+	return nil
+}
+
+func (r *AccessRule) key() common.Hash {
+	return crypto.HashBytes([]byte{r.Action}, r.Target.Bytes(), r.Account.Bytes())
+}
+
+func (r *AccessRule) voteKey() common.Hash {
+	return crypto.HashBytes(accessVotePrefix, r.key().Bytes(), []byte{r.Permission})
+}
+
+// voteAccessRule votes for the rule by the validator of the BFT consensus, and the rule takes effect once
+// voted by more than 2/3 of the current validators.
+func voteAccessRule(input []byte, context *Context) ([]byte, error) {
+	var rule AccessRule
+	if err := common.Deserialize(input, &rule); err != nil {
+		return nil, fmt.Errorf("Failed to decode access rule, %s", err)
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if context.BlockHeader.Consensus != types.IstanbulConsensus {
+		return nil, errAccessNotPermissioned
+	}
+
+	extra, err := types.ExtractIstanbulExtra(context.BlockHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	validators := make(map[common.Address]bool)
+	for _, v := range extra.Validators {
+		validators[v] = true
+	}
+
+	sender := context.tx.Data.From
+	if !validators[sender] {
+		return nil, errAccessNotValidator
+	}
+
+	votes := &AccessVotes{Rule: rule}
+	voteKey := rule.voteKey()
+	if value := context.statedb.GetData(AccessControlContractAddress, voteKey); len(value) > 0 {
+		if err = common.Deserialize(value, votes); err != nil {
+			return nil, err
+		}
+	}
+
+	// the votes of the removed validators are dropped
+	voters := []common.Address{sender}
+	for _, v := range votes.Voters {
+		if v != sender && validators[v] {
+			voters = append(voters, v)
+		}
+	}
+	votes.Voters = voters
+
+	context.statedb.CreateAccount(AccessControlContractAddress)
+	if len(voters) < accessQuorum(len(validators)) {
+		value := common.SerializePanic(votes)
+		context.statedb.SetData(AccessControlContractAddress, voteKey, value)
+		return value, nil
+	}
+
+	context.statedb.SetData(AccessControlContractAddress, voteKey, nil)
+	if rule.Permission == AccessUnset {
+		context.statedb.SetData(AccessControlContractAddress, rule.key(), nil)
+	} else {
+		context.statedb.SetData(AccessControlContractAddress, rule.key(), []byte{rule.Permission})
+	}
+
+	return common.SerializePanic(votes), nil
+}
+
+// accessQuorum returns the number of the votes to apply a rule, which is more than 2/3 of the validators
+func accessQuorum(validators int) int {
+	return 2*validators/3 + 1
+}
+
+// getAccessRule gets the permission of the serialized rule regardless of the permission field
+func getAccessRule(input []byte, context *Context) ([]byte, error) {
+	var rule AccessRule
+	if err := common.Deserialize(input, &rule); err != nil {
+		return nil, fmt.Errorf("Failed to decode access rule, %s", err)
+	}
+
+	return []byte{getAccessPermission(context.statedb, &rule)}, nil
+}
+
+func getAccessPermission(statedb *state.Statedb, rule *AccessRule) byte {
+	if value := statedb.GetData(AccessControlContractAddress, rule.key()); len(value) > 0 {
+		return value[0]
+	}
+
+	return AccessUnset
+}
+
+// CheckAccess returns ErrAccessDenied if the account is denied to deploy contracts or call the target contract
+// by its own rule, or by the default rule of the action if the account has no rule.
+func CheckAccess(statedb *state.Statedb, action byte, target, account common.Address) error {
+	if !statedb.Exist(AccessControlContractAddress) {
+		return nil
+	}
+
+	rule := &AccessRule{Action: action, Target: target, Account: account}
+	permission := getAccessPermission(statedb, rule)
+	if permission == AccessUnset {
+		rule.Account = common.EmptyAddress
+		permission = getAccessPermission(statedb, rule)
+	}
+
+	if permission == AccessDeny {
+		return ErrAccessDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package system
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+// setTestValidators sets the validators in the istanbul extra of the block header
+func setTestValidators(t *testing.T, header *types.BlockHeader, validators []common.Address) {
+	extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{Validators: validators, Seal: []byte{}, CommittedSeal: [][]byte{}})
+	assert.Equal(t, err, nil)
+
+	header.Consensus = types.IstanbulConsensus
+	header.ExtraData = append(make([]byte, types.IstanbulExtraVanity), extra...)
+}
+
+func voteTestAccessRule(context *Context, validator common.Address, rule *AccessRule) error {
+	context.tx.Data.From = validator
+	_, err := voteAccessRule(common.SerializePanic(rule), context)
+	return err
+}
+
+func Test_AccessControl_Vote(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, AccessControlContractAddress)
+	rule := &AccessRule{Action: AccessDeploy, Permission: AccessDeny}
+
+	// public network
+	assert.Equal(t, voteTestAccessRule(context, context.tx.Data.From, rule), errAccessNotPermissioned)
+
+	validators := []common.Address{
+		*crypto.MustGenerateShardAddress(1),
+		*crypto.MustGenerateShardAddress(1),
+		*crypto.MustGenerateShardAddress(1),
+		*crypto.MustGenerateShardAddress(1),
+	}
+	setTestValidators(t, context.BlockHeader, validators)
+
+	deployer := *crypto.MustGenerateShardAddress(1)
+	assert.Equal(t, voteTestAccessRule(context, deployer, rule), errAccessNotValidator)
+
+	// 3 of 4 validators are required, and the duplicated votes are ignored
+	assert.Equal(t, voteTestAccessRule(context, validators[0], rule), nil)
+	assert.Equal(t, voteTestAccessRule(context, validators[0], rule), nil)
+	assert.Equal(t, voteTestAccessRule(context, validators[1], rule), nil)
+	assert.Equal(t, CheckAccess(context.statedb, AccessDeploy, common.EmptyAddress, deployer), nil)
+
+	assert.Equal(t, voteTestAccessRule(context, validators[2], rule), nil)
+	assert.Equal(t, CheckAccess(context.statedb, AccessDeploy, common.EmptyAddress, deployer), ErrAccessDenied)
+
+	// allow the deployer
+	allow := &AccessRule{Action: AccessDeploy, Account: deployer, Permission: AccessAllow}
+	for _, v := range validators[1:] {
+		assert.Equal(t, voteTestAccessRule(context, v, allow), nil)
+	}
+	assert.Equal(t, CheckAccess(context.statedb, AccessDeploy, common.EmptyAddress, deployer), nil)
+	assert.Equal(t, CheckAccess(context.statedb, AccessDeploy, common.EmptyAddress, validators[0]), ErrAccessDenied)
+
+	result, err := getAccessRule(common.SerializePanic(&AccessRule{Action: AccessDeploy, Account: deployer}), context)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, result, []byte{AccessAllow})
+
+	// unset the default rule
+	rule.Permission = AccessUnset
+	for _, v := range validators[:3] {
+		assert.Equal(t, voteTestAccessRule(context, v, rule), nil)
+	}
+	assert.Equal(t, CheckAccess(context.statedb, AccessDeploy, common.EmptyAddress, validators[0]), nil)
+}
+
+func Test_AccessControl_Call(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, AccessControlContractAddress)
+	validator := *crypto.MustGenerateShardAddress(1)
+	setTestValidators(t, context.BlockHeader, []common.Address{validator})
+
+	target, caller := *crypto.MustGenerateShardAddress(1), *crypto.MustGenerateShardAddress(1)
+	assert.Equal(t, voteTestAccessRule(context, validator, &AccessRule{Action: AccessCall, Target: target, Account: caller, Permission: AccessDeny}), nil)
+	assert.Equal(t, CheckAccess(context.statedb, AccessCall, target, caller), ErrAccessDenied)
+	assert.Equal(t, CheckAccess(context.statedb, AccessCall, target, validator), nil)
+	assert.Equal(t, CheckAccess(context.statedb, AccessCall, DomainNameContractAddress, caller), nil)
+}
+
+func Test_AccessRule_Validate(t *testing.T) {
+	target := *crypto.MustGenerateShardAddress(1)
+	assert.Equal(t, (&AccessRule{Action: AccessDeploy}).Validate(), nil)
+	assert.Equal(t, (&AccessRule{Action: AccessDeploy, Target: target}).Validate(), errAccessInvalidRule)
+	assert.Equal(t, (&AccessRule{Action: AccessCall}).Validate(), errAccessInvalidRule)
+	assert.Equal(t, (&AccessRule{Action: AccessCall, Target: AccessControlContractAddress}).Validate(), errAccessInvalidRule)
+	assert.Equal(t, (&AccessRule{Action: AccessCall, Target: target, Permission: AccessDeny}).Validate(), nil)
+	assert.Equal(t, (&AccessRule{Action: AccessCall, Target: target, Permission: AccessDeny + 1}).Validate(), errAccessInvalidRule)
+	assert.Equal(t, (&AccessRule{}).Validate(), errAccessInvalidRule)
+}
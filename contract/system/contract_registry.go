@@ -0,0 +1,143 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package system
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/crypto"
+)
+
+const (
+	gasRegisterContractMetadata = uint64(50000)
+	gasGetContractMetadata      = uint64(5000)
+)
+
+const (
+	// CmdRegisterContractMetadata registers or updates the metadata of a contract by its deployer
+	CmdRegisterContractMetadata byte = iota
+	// CmdGetContractMetadata gets the metadata of a contract
+	CmdGetContractMetadata
+)
+
+const (
+	maxContractNameLength    = 64
+	maxContractSourceLength  = 256
+	maxContractVersionLength = 32
+)
+
+var (
+	errContractNameEmpty    = errors.New("Failed to register, contract name is empty")
+	errContractNameTooLong  = fmt.Errorf("Failed to register, contract name longer than %d", maxContractNameLength)
+	errContractSourceLong   = fmt.Errorf("Failed to register, source URI longer than %d", maxContractSourceLength)
+	errContractVersionLong  = fmt.Errorf("Failed to register, version longer than %d", maxContractVersionLength)
+	errContractNotDeployer  = errors.New("Failed to register, the contract is not deployed by the sender with the nonce")
+	errContractCodeNotFound = errors.New("Failed to register, contract code not found")
+
+	registryCommands = map[byte]*cmdInfo{
+		CmdRegisterContractMetadata: &cmdInfo{gasRegisterContractMetadata, registerContractMetadata},
+		CmdGetContractMetadata:      &cmdInfo{gasGetContractMetadata, getContractMetadata},
+	}
+)
+
+// ContractMetadata is the metadata registered by the deployer of a contract for the explorers to
+// display the verified contract info.
+type ContractMetadata struct {
+	Contract    common.Address
+	DeployNonce uint64 // account nonce of the deployer to create the contract, which binds the contract to the deployer
+	Name        string
+	ABIHash     common.Hash // hash of the abi json
+	SourceURI   string      // where to fetch the verified source code
+	Version     string
+
+	Deployer common.Address // set by the registry as the tx sender
+	Height   uint64         // height of the block which registers the metadata, set by the registry
+}
+
+// Validate validates the length of the fields
+func (m *ContractMetadata) Validate() error {
+	if len(m.Name) == 0 {
+		return errContractNameEmpty
+	}
+
+	if len(m.Name) > maxContractNameLength {
+		return errContractNameTooLong
+	}
+
+	if len(m.SourceURI) > maxContractSourceLength {
+		return errContractSourceLong
+	}
+
+	if len(m.Version) > maxContractVersionLength {
+		return errContractVersionLong
+	}
+
+	return nil
+}
+
+// registerContractMetadata registers the metadata of the contract created by the sender, and the sender
+// could update it with a new version anytime. The contracts created by the other contracts or CREATE2 are
+// not supported, because the contract address is not derived from the deployer and nonce.
+func registerContractMetadata(input []byte, context *Context) ([]byte, error) {
+	var metadata ContractMetadata
+	if err := common.Deserialize(input, &metadata); err != nil {
+		return nil, fmt.Errorf("Failed to decode contract metadata, %s", err)
+	}
+
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+
+	from := context.tx.Data.From
+	if crypto.CreateAddress(from, metadata.DeployNonce) != metadata.Contract {
+		return nil, errContractNotDeployer
+	}
+
+	if len(context.statedb.GetCode(metadata.Contract)) == 0 {
+		return nil, errContractCodeNotFound
+	}
+
+	metadata.Deployer = from
+	metadata.Height = context.BlockHeader.Height
+
+	value := common.SerializePanic(&metadata)
+	context.statedb.CreateAccount(ContractRegistryContractAddress)
+	context.statedb.SetData(ContractRegistryContractAddress, contractMetadataKey(metadata.Contract), value)
+
+	return value, nil
+}
+
+// getContractMetadata gets the serialized metadata of the contract
+func getContractMetadata(input []byte, context *Context) ([]byte, error) {
+	value := context.statedb.GetData(ContractRegistryContractAddress, contractMetadataKey(common.BytesToAddress(input)))
+	if len(value) == 0 {
+		return nil, errNotFound
+	}
+
+	return value, nil
+}
+
+// GetContractMetadata returns the metadata of the contract registered in the statedb, or nil if not registered
+func GetContractMetadata(statedb *state.Statedb, contract common.Address) (*ContractMetadata, error) {
+	value := statedb.GetData(ContractRegistryContractAddress, contractMetadataKey(contract))
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var metadata ContractMetadata
+	if err := common.Deserialize(value, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+func contractMetadataKey(contract common.Address) common.Hash {
+	return common.BytesToHash(contract.Bytes())
+}
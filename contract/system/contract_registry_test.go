@@ -0,0 +1,116 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package system
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRegistryInput(t *testing.T, metadata *ContractMetadata) []byte {
+	encoded, err := common.Serialize(metadata)
+	assert.Equal(t, err, nil)
+
+	return append([]byte{CmdRegisterContractMetadata}, encoded...)
+}
+
+func Test_ContractRegistry(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, ContractRegistryContractAddress)
+	deployer := context.tx.Data.From
+	contract := crypto.CreateAddress(deployer, 3)
+
+	metadata := &ContractMetadata{
+		Contract:    contract,
+		DeployNonce: 3,
+		Name:        "token",
+		ABIHash:     crypto.HashBytes([]byte("[]")),
+		SourceURI:   "https://example.com/token.sol",
+		Version:     "1.0.0",
+	}
+
+	c := GetContractByAddress(ContractRegistryContractAddress)
+	input := newTestRegistryInput(t, metadata)
+	assert.Equal(t, c.RequiredGas(input, context), gasRegisterContractMetadata)
+
+	// contract not deployed yet
+	_, err := c.Run(input, context)
+	assert.Equal(t, err, errContractCodeNotFound)
+
+	context.statedb.CreateAccount(contract)
+	context.statedb.SetCode(contract, []byte{1, 2, 3})
+	_, err = c.Run(input, context)
+	assert.Equal(t, err, nil)
+
+	registered, err := GetContractMetadata(context.statedb, contract)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, registered.Name, "token")
+	assert.Equal(t, registered.Deployer, deployer)
+	assert.Equal(t, registered.Height, context.BlockHeader.Height)
+
+	// updated by the deployer
+	metadata.Version = "1.0.1"
+	_, err = c.Run(newTestRegistryInput(t, metadata), context)
+	assert.Equal(t, err, nil)
+
+	result, err := c.Run(append([]byte{CmdGetContractMetadata}, contract.Bytes()...), context)
+	assert.Equal(t, err, nil)
+
+	var got ContractMetadata
+	assert.Equal(t, common.Deserialize(result, &got), nil)
+	assert.Equal(t, got.Version, "1.0.1")
+
+	// not registered
+	unknown, err := GetContractMetadata(context.statedb, crypto.CreateAddress(deployer, 4))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, unknown == nil, true)
+}
+
+func Test_ContractRegistry_NotDeployer(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, ContractRegistryContractAddress)
+	other := *crypto.MustGenerateShardAddress(1)
+	contract := crypto.CreateAddress(other, 0)
+	context.statedb.CreateAccount(contract)
+	context.statedb.SetCode(contract, []byte{1})
+
+	metadata := &ContractMetadata{Contract: contract, Name: "token"}
+	_, err := registerContractMetadata(common.SerializePanic(metadata), context)
+	assert.Equal(t, err, errContractNotDeployer)
+
+	// wrong nonce of the sender
+	metadata.Contract = crypto.CreateAddress(context.tx.Data.From, 1)
+	metadata.DeployNonce = 2
+	_, err = registerContractMetadata(common.SerializePanic(metadata), context)
+	assert.Equal(t, err, errContractNotDeployer)
+}
+
+func Test_ContractMetadata_Validate(t *testing.T) {
+	metadata := &ContractMetadata{}
+	assert.Equal(t, metadata.Validate(), errContractNameEmpty)
+
+	metadata.Name = string(make([]byte, maxContractNameLength+1))
+	assert.Equal(t, metadata.Validate(), errContractNameTooLong)
+
+	metadata.Name = "token"
+	metadata.SourceURI = string(make([]byte, maxContractSourceLength+1))
+	assert.Equal(t, metadata.Validate(), errContractSourceLong)
+
+	metadata.SourceURI = ""
+	metadata.Version = string(make([]byte, maxContractVersionLength+1))
+	assert.Equal(t, metadata.Validate(), errContractVersionLong)
+
+	metadata.Version = "1.0.0"
+	assert.Equal(t, metadata.Validate(), nil)
+}
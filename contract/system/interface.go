@@ -18,16 +18,27 @@ type Context struct {
 	tx          *types.Transaction
 	statedb     *state.Statedb
 	BlockHeader *types.BlockHeader
+
+	// ChainConfig is the fork activation heights of the chain. nil means the config of the main network.
+	ChainConfig *common.ChainConfig
 }
 
 // NewContext creates a system contract context.
 func NewContext(tx *types.Transaction, statedb *state.Statedb, BlockHeader *types.BlockHeader) *Context {
-	return &Context{tx, statedb, BlockHeader}
+	return &Context{tx: tx, statedb: statedb, BlockHeader: BlockHeader}
+}
+
+func (context *Context) chainConfig() *common.ChainConfig {
+	if context.ChainConfig == nil {
+		return common.DefaultChainConfig()
+	}
+
+	return context.ChainConfig
 }
 
 // Contract is the basic interface for native Go contracts in Scdo.
 type Contract interface {
-	RequiredGas(input []byte) uint64
+	RequiredGas(input []byte, context *Context) uint64
 	Run(input []byte, context *Context) ([]byte, error)
 }
 
@@ -50,14 +61,30 @@ var (
 	MasternodeContractAddress = common.BytesToAddress([]byte{1, 4})
 	// BTCRelayContractAddress btc-relay contract address
 	BTCRelayContractAddress = common.BytesToAddress([]byte{1, 5})
+	// MetaTxRelayContractAddress meta tx relay contract address
+	MetaTxRelayContractAddress = common.BytesToAddress([]byte{1, 6})
+	// ContractRegistryContractAddress contract metadata registry address
+	ContractRegistryContractAddress = common.BytesToAddress([]byte{1, 7})
+	// AccessControlContractAddress access control contract address of the permissioned networks
+	AccessControlContractAddress = common.BytesToAddress([]byte{1, 8})
 
 	// Contracts are system contracts
 	contracts = map[common.Address]Contract{
-		DomainNameContractAddress:   &contract{domainNameCommands},
-		SubChainContractAddress:     &contract{subChainCommands},
-		HashTimeLockContractAddress: &contract{htlcCommands},
-		MasternodeContractAddress:   &contract{masternodeCommands},
-		BTCRelayContractAddress:     &contract{brCommands},
+		DomainNameContractAddress:       &contract{domainNameCommands},
+		SubChainContractAddress:         &contract{subChainCommands},
+		HashTimeLockContractAddress:     &contract{htlcCommands},
+		MasternodeContractAddress:       &contract{masternodeCommands},
+		BTCRelayContractAddress:         &contract{brCommands},
+		MetaTxRelayContractAddress:      &metaTxRelayContract{},
+		ContractRegistryContractAddress: &contract{registryCommands},
+		AccessControlContractAddress:    &contract{accessControlCommands},
+	}
+
+	// contractForks are the forks activating the system contracts added after the genesis
+	contractForks = map[common.Address]func(*common.ChainConfig, uint64) bool{
+		MetaTxRelayContractAddress:      (*common.ChainConfig).IsMetaTxRelay,
+		ContractRegistryContractAddress: (*common.ChainConfig).IsContractRegistry,
+		AccessControlContractAddress:    (*common.ChainConfig).IsAccessControl,
 	}
 )
 
@@ -72,7 +99,7 @@ type contract struct {
 	cmds map[byte]*cmdInfo
 }
 
-func (c *contract) RequiredGas(input []byte) uint64 {
+func (c *contract) RequiredGas(input []byte, context *Context) uint64 {
 	if len(input) == 0 {
 		return gasInvalidCommand
 	}
@@ -100,3 +127,17 @@ func (c *contract) Run(input []byte, context *Context) ([]byte, error) {
 func GetContractByAddress(address common.Address) Contract {
 	return contracts[address]
 }
+
+// GetContractAt gets the system contract by the address if it is activated at the height, otherwise
+// the address is a normal account. nil config means the config of the main network.
+func GetContractAt(address common.Address, config *common.ChainConfig, height uint64) Contract {
+	if config == nil {
+		config = common.DefaultChainConfig()
+	}
+
+	if activated, found := contractForks[address]; found && !activated(config, height) {
+		return nil
+	}
+
+	return contracts[address]
+}
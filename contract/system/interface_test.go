@@ -67,15 +67,15 @@ func Test_RequiredGas(t *testing.T) {
 	assert.Equal(t, ok, true)
 
 	// input is nil
-	gas := c.RequiredGas(nil)
+	gas := c.RequiredGas(nil, nil)
 	assert.Equal(t, gas, gasInvalidCommand)
 
 	// CmdCreateDomainName is valid command
-	gas = c.RequiredGas([]byte{CmdCreateDomainName})
+	gas = c.RequiredGas([]byte{CmdCreateDomainName}, nil)
 	assert.Equal(t, gas, gasCreateDomainName)
 
 	// byte(123) is invalid command
-	gas = c.RequiredGas([]byte{byte(123)})
+	gas = c.RequiredGas([]byte{byte(123)}, nil)
 	assert.Equal(t, gas, gasInvalidCommand)
 }
 
@@ -117,3 +117,23 @@ func Test_GetContractByAddress(t *testing.T) {
 	c1 := GetContractByAddress(contractAddress)
 	assert.Equal(t, c1, nil)
 }
+
+func Test_GetContractAt(t *testing.T) {
+	// not scheduled on the main network
+	assert.Equal(t, GetContractAt(MetaTxRelayContractAddress, nil, common.ScdoForkHeight), nil)
+	assert.Equal(t, GetContractAt(DomainNameContractAddress, nil, common.ScdoForkHeight), &contract{domainNameCommands})
+
+	config := &common.ChainConfig{MetaTxRelayForkHeight: 10}
+	assert.Equal(t, GetContractAt(MetaTxRelayContractAddress, config, 9), nil)
+	assert.Equal(t, GetContractAt(MetaTxRelayContractAddress, config, 10), &metaTxRelayContract{})
+
+	assert.Equal(t, GetContractAt(ContractRegistryContractAddress, nil, common.ScdoForkHeight), nil)
+	config.ContractRegistryForkHeight = 20
+	assert.Equal(t, GetContractAt(ContractRegistryContractAddress, config, 19), nil)
+	assert.Equal(t, GetContractAt(ContractRegistryContractAddress, config, 20), &contract{registryCommands})
+
+	assert.Equal(t, GetContractAt(AccessControlContractAddress, nil, common.ScdoForkHeight), nil)
+	config.AccessControlForkHeight = 30
+	assert.Equal(t, GetContractAt(AccessControlContractAddress, config, 29), nil)
+	assert.Equal(t, GetContractAt(AccessControlContractAddress, config, 30), &contract{accessControlCommands})
+}
@@ -0,0 +1,238 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package system
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+)
+
+const (
+	gasRelayMetaTx   = uint64(30000)
+	gasGetRelayNonce = uint64(5000)
+)
+
+const (
+	// CmdRelayMetaTx relays a signed meta tx on behalf of its signer
+	CmdRelayMetaTx byte = iota
+	// CmdGetRelayNonce gets the next meta tx nonce of an account
+	CmdGetRelayNonce
+)
+
+var (
+	errMetaTxSignature   = errors.New("Failed to relay, invalid meta tx signature")
+	errMetaTxExpired     = errors.New("Failed to relay, meta tx expired")
+	errMetaTxNonce       = errors.New("Failed to relay, invalid meta tx nonce")
+	errMetaTxCrossShard  = errors.New("Failed to relay, cross shard meta tx is not supported")
+	errMetaTxEVMContract = errors.New("Failed to relay, evm contract is not supported")
+	errMetaTxRecursive   = errors.New("Failed to relay, meta tx to relay contract")
+	errMetaTxAmount      = errors.New("Failed to relay, amount is negative")
+	errMetaTxBalance     = errors.New("Failed to relay, signer balance is not enough")
+	errMetaTxChainID     = errors.New("Failed to relay, meta tx of another chain")
+
+	metaTxNoncePrefix = []byte("nonce")
+)
+
+// MetaTx is the inner message signed by the signer and executed on behalf of the signer,
+// while the gas is paid by the sender of the outer tx.
+type MetaTx struct {
+	From    common.Address
+	To      common.Address
+	Amount  *big.Int
+	Payload common.Bytes
+	Nonce   uint64 // the meta tx nonce of the signer maintained by the relay contract
+	Expiry  uint64 // unix timestamp in seconds after which the meta tx is invalid
+	ChainID uint64 // the chain id of the network, so that the meta tx could not be replayed on other networks
+}
+
+// SignedMetaTx is a meta tx with the signature of its signer
+type SignedMetaTx struct {
+	MetaTx
+	Signature crypto.Signature
+}
+
+// Hash returns the hash of the meta tx to be signed
+func (meta *MetaTx) Hash() common.Hash {
+	return crypto.HashBytes(MetaTxRelayContractAddress.Bytes(), common.SerializePanic(meta))
+}
+
+// NewSignedMetaTx signs the meta tx with the private key of the signer
+func NewSignedMetaTx(privKey *ecdsa.PrivateKey, meta *MetaTx) (*SignedMetaTx, error) {
+	if meta.Amount == nil {
+		meta.Amount = big.NewInt(0)
+	}
+
+	hash := meta.Hash()
+	sig, err := crypto.Sign(privKey, hash.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedMetaTx{*meta, *sig}, nil
+}
+
+// metaTxRelayContract relays meta txs to transfer amount or call system contracts on behalf of the signer.
+type metaTxRelayContract struct{}
+
+func (c *metaTxRelayContract) RequiredGas(input []byte, context *Context) uint64 {
+	if len(input) == 0 {
+		return gasInvalidCommand
+	}
+
+	switch input[0] {
+	case CmdRelayMetaTx:
+		gas := gasRelayMetaTx
+		// the gas of called system contract is also paid by the sender
+		var meta SignedMetaTx
+		if err := common.Deserialize(input[1:], &meta); err == nil && meta.To != MetaTxRelayContractAddress {
+			if target := GetContractAt(meta.To, context.ChainConfig, context.BlockHeader.Height); target != nil {
+				gas += target.RequiredGas(meta.Payload, context)
+			}
+		}
+
+		return gas
+	case CmdGetRelayNonce:
+		return gasGetRelayNonce
+	}
+
+	return gasInvalidCommand
+}
+
+func (c *metaTxRelayContract) Run(input []byte, context *Context) ([]byte, error) {
+	if len(input) == 0 {
+		return nil, errInvalidCommand
+	}
+
+	switch input[0] {
+	case CmdRelayMetaTx:
+		return relayMetaTx(input[1:], context)
+	case CmdGetRelayNonce:
+		nonce := getRelayNonce(context, common.BytesToAddress(input[1:]))
+		return encodeRelayNonce(nonce), nil
+	}
+
+	return nil, errInvalidCommand
+}
+
+func relayNonceKey(account common.Address) common.Hash {
+	return crypto.HashBytes(metaTxNoncePrefix, account.Bytes())
+}
+
+func encodeRelayNonce(nonce uint64) []byte {
+	buff := make([]byte, 8)
+	binary.BigEndian.PutUint64(buff, nonce)
+	return buff
+}
+
+// getRelayNonce returns the next meta tx nonce of the account
+func getRelayNonce(context *Context, account common.Address) uint64 {
+	value := context.statedb.GetData(MetaTxRelayContractAddress, relayNonceKey(account))
+	if len(value) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(value)
+}
+
+// relayMetaTx verifies the signed meta tx and executes it on behalf of the signer
+func relayMetaTx(input []byte, context *Context) ([]byte, error) {
+	var signed SignedMetaTx
+	if err := common.Deserialize(input, &signed); err != nil {
+		return nil, fmt.Errorf("Failed to decode meta tx, %s", err)
+	}
+
+	meta := &signed.MetaTx
+	if meta.Amount == nil {
+		meta.Amount = big.NewInt(0)
+	}
+
+	if err := validateMetaTx(&signed, context); err != nil {
+		return nil, err
+	}
+
+	if context.statedb.GetBalance(meta.From).Cmp(meta.Amount) < 0 {
+		return nil, errMetaTxBalance
+	}
+
+	context.statedb.CreateAccount(MetaTxRelayContractAddress)
+	context.statedb.SetData(MetaTxRelayContractAddress, relayNonceKey(meta.From), encodeRelayNonce(meta.Nonce+1))
+
+	if !context.statedb.Exist(meta.To) {
+		context.statedb.CreateAccount(meta.To)
+	}
+
+	context.statedb.SubBalance(meta.From, meta.Amount)
+	context.statedb.AddBalance(meta.To, meta.Amount)
+
+	target := GetContractAt(meta.To, context.ChainConfig, context.BlockHeader.Height)
+	if target == nil {
+		return nil, nil
+	}
+
+	// the called system contract regards the signer as the tx sender
+	innerTx := &types.Transaction{
+		Hash: meta.Hash(),
+		Data: types.TransactionData{
+			From:         meta.From,
+			To:           meta.To,
+			Amount:       meta.Amount,
+			GasPrice:     context.tx.Data.GasPrice,
+			GasLimit:     context.tx.Data.GasLimit,
+			AccountNonce: meta.Nonce,
+			Payload:      meta.Payload,
+		},
+	}
+
+	innerContext := NewContext(innerTx, context.statedb, context.BlockHeader)
+	innerContext.ChainConfig = context.ChainConfig
+
+	return target.Run(meta.Payload, innerContext)
+}
+
+func validateMetaTx(signed *SignedMetaTx, context *Context) error {
+	meta := &signed.MetaTx
+	hash := meta.Hash()
+	if !signed.Signature.Verify(meta.From, hash.Bytes()) {
+		return errMetaTxSignature
+	}
+
+	if meta.ChainID != context.chainConfig().ChainID {
+		return errMetaTxChainID
+	}
+
+	if meta.Expiry < context.BlockHeader.CreateTimestamp.Uint64() {
+		return errMetaTxExpired
+	}
+
+	if meta.Nonce != getRelayNonce(context, meta.From) {
+		return errMetaTxNonce
+	}
+
+	if meta.Amount.Sign() < 0 {
+		return errMetaTxAmount
+	}
+
+	if meta.To == MetaTxRelayContractAddress {
+		return errMetaTxRecursive
+	}
+
+	if meta.From.Shard() != context.tx.Data.From.Shard() || (!meta.To.IsReserved() && meta.To.Shard() != meta.From.Shard()) {
+		return errMetaTxCrossShard
+	}
+
+	if meta.To.IsEVMContract() {
+		return errMetaTxEVMContract
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package system
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRelayInput(t *testing.T, signed *SignedMetaTx) []byte {
+	encoded, err := common.Serialize(signed)
+	assert.Equal(t, err, nil)
+
+	return append([]byte{CmdRelayMetaTx}, encoded...)
+}
+
+func Test_MetaTxRelay(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, MetaTxRelayContractAddress)
+	signer, key := crypto.MustGenerateShardKeyPair(1)
+	to := *crypto.MustGenerateShardAddress(1)
+	context.statedb.CreateAccount(*signer)
+	context.statedb.AddBalance(*signer, big.NewInt(100))
+
+	meta := &MetaTx{
+		From:    *signer,
+		To:      to,
+		Amount:  big.NewInt(10),
+		Nonce:   0,
+		Expiry:  uint64(time.Now().Unix() + 60),
+		ChainID: common.MainChainID,
+	}
+	signed, err := NewSignedMetaTx(key, meta)
+	assert.Equal(t, err, nil)
+
+	c := GetContractByAddress(MetaTxRelayContractAddress)
+	input := newTestRelayInput(t, signed)
+	assert.Equal(t, c.RequiredGas(input, context), gasRelayMetaTx)
+
+	_, err = c.Run(input, context)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, context.statedb.GetBalance(*signer), big.NewInt(90))
+	assert.Equal(t, context.statedb.GetBalance(to), big.NewInt(10))
+	assert.Equal(t, getRelayNonce(context, *signer), uint64(1))
+
+	// replay
+	_, err = c.Run(input, context)
+	assert.Equal(t, err, errMetaTxNonce)
+
+	// nonce query
+	nonce, err := c.Run(append([]byte{CmdGetRelayNonce}, signer.Bytes()...), context)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, nonce, encodeRelayNonce(1))
+
+	// tampered amount
+	meta.Nonce = 1
+	signed, err = NewSignedMetaTx(key, meta)
+	assert.Equal(t, err, nil)
+	signed.Amount = big.NewInt(20)
+	_, err = c.Run(newTestRelayInput(t, signed), context)
+	assert.Equal(t, err, errMetaTxSignature)
+
+	// expired
+	meta.Expiry = uint64(time.Now().Unix() - 60)
+	signed, err = NewSignedMetaTx(key, meta)
+	assert.Equal(t, err, nil)
+	_, err = c.Run(newTestRelayInput(t, signed), context)
+	assert.Equal(t, err, errMetaTxExpired)
+
+	// signed for another network
+	meta.Expiry = uint64(time.Now().Unix() + 60)
+	meta.ChainID = common.MainChainID + 1
+	signed, err = NewSignedMetaTx(key, meta)
+	assert.Equal(t, err, nil)
+	_, err = c.Run(newTestRelayInput(t, signed), context)
+	assert.Equal(t, err, errMetaTxChainID)
+
+	context.ChainConfig = &common.ChainConfig{ChainID: common.MainChainID + 1}
+	_, err = c.Run(newTestRelayInput(t, signed), context)
+	assert.Equal(t, err, nil)
+
+	// balance not enough, nonce is not consumed
+	meta.Nonce = 2
+	meta.Amount = big.NewInt(1000)
+	signed, err = NewSignedMetaTx(key, meta)
+	assert.Equal(t, err, nil)
+	_, err = c.Run(newTestRelayInput(t, signed), context)
+	assert.Equal(t, err, errMetaTxBalance)
+	assert.Equal(t, getRelayNonce(context, *signer), uint64(2))
+}
+
+func Test_MetaTxRelay_SystemContract(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, MetaTxRelayContractAddress)
+	signer, key := crypto.MustGenerateShardKeyPair(1)
+	context.statedb.CreateAccount(*signer)
+
+	// register domain name on behalf of the signer
+	payload := append([]byte{CmdCreateDomainName}, []byte("scdo-relay")...)
+	signed, err := NewSignedMetaTx(key, &MetaTx{
+		From:    *signer,
+		To:      DomainNameContractAddress,
+		Payload: payload,
+		Expiry:  uint64(time.Now().Unix() + 60),
+		ChainID: common.MainChainID,
+	})
+	assert.Equal(t, err, nil)
+
+	c := GetContractByAddress(MetaTxRelayContractAddress)
+	input := newTestRelayInput(t, signed)
+	assert.Equal(t, c.RequiredGas(input, context), gasRelayMetaTx+gasCreateDomainName)
+
+	// system contract not activated yet is a normal account
+	registryInput := newTestRelayInput(t, &SignedMetaTx{MetaTx: MetaTx{To: ContractRegistryContractAddress, Payload: []byte{CmdRegisterContractMetadata}}})
+	context.ChainConfig = &common.ChainConfig{ChainID: common.MainChainID, ContractRegistryForkHeight: context.BlockHeader.Height + 1}
+	assert.Equal(t, c.RequiredGas(registryInput, context), gasRelayMetaTx)
+	context.ChainConfig = nil
+
+	owner, err := c.Run(input, context)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, owner, signer.Bytes())
+
+	// relay to the relay contract itself
+	signed, err = NewSignedMetaTx(key, &MetaTx{
+		From:    *signer,
+		To:      MetaTxRelayContractAddress,
+		Nonce:   1,
+		Expiry:  uint64(time.Now().Unix() + 60),
+		ChainID: common.MainChainID,
+	})
+	assert.Equal(t, err, nil)
+	_, err = c.Run(newTestRelayInput(t, signed), context)
+	assert.Equal(t, err, errMetaTxRecursive)
+}
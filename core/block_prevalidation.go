@@ -0,0 +1,156 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"runtime"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// prevalidatedBlocksSize is the max number of prevalidated blocks to cache
+const prevalidatedBlocksSize = 1024
+
+// ErrBlockReceiptHashEmpty is returned when the receipts root hash is not set in the block header.
+var ErrBlockReceiptHashEmpty = errors.New("block receipts hash is empty")
+
+// blockPrevalidator caches the blocks whose merkle roots are verified before the state execution, so
+// that they are not computed again under the chain lock. The block instance is cached instead of the
+// hash, since another block of the same header hash could have a different body.
+type blockPrevalidator struct {
+	blocks *lru.Cache
+}
+
+func newBlockPrevalidator() *blockPrevalidator {
+	return &blockPrevalidator{common.MustNewCache(prevalidatedBlocksSize)}
+}
+
+func (p *blockPrevalidator) contains(block *types.Block) bool {
+	if p == nil {
+		return false
+	}
+
+	cached, ok := p.blocks.Get(block.HeaderHash)
+	return ok && cached.(*types.Block) == block
+}
+
+func (p *blockPrevalidator) add(block *types.Block) {
+	if p != nil {
+		p.blocks.Add(block.HeaderHash, block)
+	}
+}
+
+// prevalidateBlock verifies the header hash, the merkle roots of the txs and debts concurrently,
+// and the presence of the receipts root, which don't depend on the chain state.
+func prevalidateBlock(block *types.Block) error {
+	if block == nil || block.Header == nil {
+		return types.ErrBlockHeaderNil
+	}
+
+	if len(block.Transactions) == 0 {
+		return ErrBlockEmptyTxs
+	}
+
+	if block.Header.ReceiptHash.IsEmpty() {
+		return ErrBlockReceiptHashEmpty
+	}
+
+	checks := []func() error{
+		func() error {
+			if !block.HeaderHash.Equal(block.Header.Hash()) {
+				return types.ErrBlockHashMismatch
+			}
+			return nil
+		},
+		func() error {
+			if h := types.MerkleRootHash(block.Transactions); !h.Equal(block.Header.TxHash) {
+				return types.ErrBlockTxsHashMismatch
+			}
+			return nil
+		},
+		func() error {
+			if h := types.DebtMerkleRootHash(types.NewDebts(block.Transactions)); !h.Equal(block.Header.TxDebtHash) {
+				return types.ErrBlockTxDebtHashMismatch
+			}
+			return nil
+		},
+		func() error {
+			if h := types.DebtMerkleRootHash(block.Debts); !h.Equal(block.Header.DebtHash) {
+				return types.ErrBlockDebtHashMismatch
+			}
+			return nil
+		},
+	}
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check func() error) {
+			defer wg.Done()
+			errs[i] = check()
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PrevalidateBlock verifies the merkle roots of the block before the state execution. It is safe to
+// call concurrently, and the verified block skips the same checks when written to the chain.
+func (bc *Blockchain) PrevalidateBlock(block *types.Block) error {
+	if block != nil && bc.prevalidator.contains(block) {
+		return nil
+	}
+
+	if err := prevalidateBlock(block); err != nil {
+		return err
+	}
+
+	bc.prevalidator.add(block)
+
+	return nil
+}
+
+// PrevalidateBlocks verifies the blocks concurrently in the background, and returns a channel per block
+// which receives the result of the block. The blocks are verified in order, so that the caller writing
+// the blocks in order could wait for the result of the next block while the later blocks are verified.
+func (bc *Blockchain) PrevalidateBlocks(blocks []*types.Block) []<-chan error {
+	results := make([]<-chan error, len(blocks))
+	jobs := make(chan int, len(blocks))
+	channels := make([]chan error, len(blocks))
+	for i := range blocks {
+		channels[i] = make(chan error, 1)
+		results[i] = channels[i]
+		jobs <- i
+	}
+	close(jobs)
+
+	workers := runtime.NumCPU()
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				channels[i] <- bc.PrevalidateBlock(blocks[i])
+			}
+		}()
+	}
+
+	return results
+}
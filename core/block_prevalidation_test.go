@@ -0,0 +1,83 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrevalidationTestBlock(nonce uint64) *types.Block {
+	header := &types.BlockHeader{
+		Height:          1,
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(1),
+	}
+
+	txs := []*types.Transaction{types.NewTestTxDetail(1, 1, nonce), types.NewTestTxDetail(1, 1, nonce+1)}
+	receipts := []*types.Receipt{{TxHash: txs[0].Hash}, {TxHash: txs[1].Hash}}
+
+	debt := &types.Debt{Hash: common.StringToHash("debt"), Data: types.DebtData{Amount: big.NewInt(1), Price: big.NewInt(1)}}
+
+	return types.NewBlock(header, txs, receipts, []*types.Debt{debt})
+}
+
+func Test_PrevalidateBlock(t *testing.T) {
+	assert.Equal(t, prevalidateBlock(newPrevalidationTestBlock(1)), nil)
+
+	block := newPrevalidationTestBlock(1)
+	block.HeaderHash = common.EmptyHash
+	assert.Equal(t, prevalidateBlock(block), types.ErrBlockHashMismatch)
+
+	block = newPrevalidationTestBlock(1)
+	block.Transactions = block.Transactions[:1]
+	assert.Equal(t, prevalidateBlock(block), types.ErrBlockTxsHashMismatch)
+
+	block = newPrevalidationTestBlock(1)
+	block.Debts = nil
+	assert.Equal(t, prevalidateBlock(block), types.ErrBlockDebtHashMismatch)
+
+	block = newPrevalidationTestBlock(1)
+	block.Header.ReceiptHash = common.EmptyHash
+	block.HeaderHash = block.Header.Hash()
+	assert.Equal(t, prevalidateBlock(block), ErrBlockReceiptHashEmpty)
+
+	block = newPrevalidationTestBlock(1)
+	block.Transactions = nil
+	assert.Equal(t, prevalidateBlock(block), ErrBlockEmptyTxs)
+}
+
+func Test_Blockchain_PrevalidateBlocks(t *testing.T) {
+	bc := &Blockchain{prevalidator: newBlockPrevalidator()}
+
+	blocks := make([]*types.Block, 10)
+	for i := range blocks {
+		blocks[i] = newPrevalidationTestBlock(uint64(i * 2))
+	}
+	blocks[5].Debts = nil
+
+	results := bc.PrevalidateBlocks(blocks)
+	assert.Equal(t, len(results), len(blocks))
+	for i, result := range results {
+		if i == 5 {
+			assert.Equal(t, <-result, types.ErrBlockDebtHashMismatch)
+			assert.Equal(t, bc.prevalidator.contains(blocks[i]), false)
+		} else {
+			assert.Equal(t, <-result, nil)
+			assert.Equal(t, bc.prevalidator.contains(blocks[i]), true)
+		}
+	}
+
+	// another block instance of the same hash is validated again
+	forged := *blocks[0]
+	forged.Transactions = forged.Transactions[:1]
+	assert.Equal(t, bc.prevalidator.contains(&forged), false)
+	assert.Equal(t, bc.PrevalidateBlock(&forged), types.ErrBlockTxsHashMismatch)
+}
@@ -6,6 +6,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"sync"
@@ -20,10 +21,12 @@ import (
 	"github.com/scdoproject/go-scdo/core/svm"
 	"github.com/scdoproject/go-scdo/core/txs"
 	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/core/vm"
 	"github.com/scdoproject/go-scdo/database"
 	"github.com/scdoproject/go-scdo/event"
 	"github.com/scdoproject/go-scdo/log"
 	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
 	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
 )
 
@@ -68,6 +71,15 @@ var (
 	// ErrBlockExtraDataNotEmpty is returned when the block extra data is not empty.
 	ErrBlockExtraDataNotEmpty = errors.New("block extra data is not empty")
 
+	// ErrBlockExtraDataTooLong is returned when the block extra data is longer than types.MaxSignalingExtraSize.
+	ErrBlockExtraDataTooLong = errors.New("block extra data is too long")
+
+	// ErrReorgTooDeep is returned when writing a block would reorg the chain deeper than the max reorg depth.
+	ErrReorgTooDeep = errors.New("reorg depth exceeds the max reorg depth")
+
+	// ErrDebtAlreadySpent is returned when the debt is already applied by an ancestor block.
+	ErrDebtAlreadySpent = errors.New("debt already spent")
+
 	// ErrNotSupported is returned when unsupported method invoked.
 	ErrNotSupported = errors.New("not supported function")
 	ErrOldDebtTx    = errors.New("failed to batch valudate debt")
@@ -81,6 +93,7 @@ type Blockchain struct {
 	bcStore        store.BlockchainStore
 	accountStateDB database.Database
 	engine         consensus.Engine
+	chainConfig    *common.ChainConfig
 	genesisBlock   *types.Block
 	lock           sync.RWMutex // lock for update blockchain info. for example write block
 
@@ -92,6 +105,37 @@ type Blockchain struct {
 	debtVerifier types.DebtVerifier
 
 	lastBlockTime time.Time // last sucessful written block time.
+
+	maxReorgDepth      uint64 // max depth of a reorg, 0 means unlimited
+	allowDeepReorgOnce bool   // allow the next reorg deeper than maxReorgDepth
+
+	prevalidator *blockPrevalidator // blocks whose merkle roots are verified before the state execution
+
+	stateMismatchReportDir string // folder of the state root mismatch reports, empty means disabled
+
+	stateDiff          bool  // always compute and store the state diffs of the written blocks
+	stateDiffConsumers int32 // number of the subscriptions consuming the state diffs, accessed atomically
+
+	slowTxs *slowTxReport // recent txs whose execution is slow when the blocks are written
+}
+
+// DeepReorgEvent is fired when a reorg deeper than the max reorg depth is refused
+type DeepReorgEvent struct {
+	BlockHash      common.Hash
+	BlockHeight    uint64
+	HeadHeight     uint64
+	CommonAncestor uint64
+	Depth          uint64
+}
+
+// BlockImportedEvent is fired when a block is written into the chain, so that the co-located indexers
+// get the execution results of the block without querying them again.
+type BlockImportedEvent struct {
+	Block           *types.Block
+	Receipts        []*types.Receipt
+	StateDiff       []*types.AccountDiff // nil if the state diff is neither enabled nor consumed
+	TotalDifficulty *big.Int
+	IsHead          bool // whether the block is the new HEAD of the chain
 }
 
 // NewBlockchain returns an initialized blockchain with the given store and account state DB.
@@ -104,6 +148,8 @@ func NewBlockchain(bcStore store.BlockchainStore, accountStateDB database.Databa
 		log:            log.GetLogger("blockchain"),
 		debtVerifier:   verifier,
 		lastBlockTime:  time.Now(),
+		prevalidator:   newBlockPrevalidator(),
+		slowTxs:        newSlowTxReport(),
 	}
 
 	var err error
@@ -129,6 +175,10 @@ func NewBlockchain(bcStore store.BlockchainStore, accountStateDB database.Databa
 		return nil, errors.NewStackedErrorf(err, "failed to get genesis block by hash %v", genesisHash)
 	}
 
+	if bc.chainConfig, err = GetChainConfig(bcStore); err != nil {
+		return nil, err
+	}
+
 	// Get the HEAD block from store
 	var currentHeaderHash common.Hash
 	if startHeight == -1 {
@@ -150,6 +200,11 @@ func NewBlockchain(bcStore store.BlockchainStore, accountStateDB database.Databa
 		}
 	}
 
+	// check the recent blocks, and rewind if corrupted
+	if currentHeaderHash, err = bc.checkIntegrity(currentHeaderHash); err != nil {
+		return nil, errors.NewStackedError(err, "failed to check chain data integrity")
+	}
+
 	currentBlock, err := bcStore.GetBlock(currentHeaderHash)
 	if err != nil {
 		return nil, errors.NewStackedErrorf(err, "failed to get HEAD block by hash %v", currentHeaderHash)
@@ -264,6 +319,11 @@ func (bc *Blockchain) Genesis() *types.Block {
 	return bc.genesisBlock
 }
 
+// Config returns the chain config
+func (bc *Blockchain) Config() *common.ChainConfig {
+	return bc.chainConfig
+}
+
 // GetCurrentInfo return the current block and current state info
 func (bc *Blockchain) GetCurrentInfo() (*types.Block, *state.Statedb, error) {
 	block := bc.CurrentBlock()
@@ -273,10 +333,20 @@ func (bc *Blockchain) GetCurrentInfo() (*types.Block, *state.Statedb, error) {
 
 // WriteBlock writes the specified block to the blockchain store.
 func (bc *Blockchain) WriteBlock(block *types.Block, txPool *Pool) error {
+	span := tracing.StartSpan("blockchain.WriteBlock", tracing.Uint("height", block.Header.Height), tracing.String("hash", block.HeaderHash.Hex()))
 	startWriteBlockTime := time.Now()
-	if err := bc.doWriteBlock(block, txPool); err != nil {
+
+	// verify the merkle roots before taking the chain lock, so that the bad blocks are rejected early
+	if err := bc.PrevalidateBlock(block); err != nil {
+		span.EndWithError(err)
+		return errors.NewStackedError(err, "failed to prevalidate block")
+	}
+
+	if err := bc.doWriteBlock(block, txPool, span); err != nil {
+		span.EndWithError(err)
 		return err
 	}
+	span.End()
 	markTime := time.Since(startWriteBlockTime)
 	metrics.MetricsWriteBlockMeter.Mark(markTime.Nanoseconds())
 	return nil
@@ -288,9 +358,11 @@ func (bc *Blockchain) WriteHeader(*types.BlockHeader) error {
 }
 
 // doWriteBlock writes the specified block to the blockchain store.
-func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool) error {
+func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool, span *tracing.Span) error {
+	lockSpan := span.StartChild("lock")
 	bc.lock.Lock()
 	defer bc.lock.Unlock()
+	lockSpan.End()
 
 	auditor := log.NewAuditor(bc.log)
 	auditor.AuditEnter("doWriteBlock")
@@ -298,9 +370,12 @@ func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool) error {
 	defer auditor.AuditLeave()
 
 	// validate block
+	stageSpan := span.StartChild("validation")
 	if err := bc.validateBlock(block); err != nil {
+		stageSpan.EndWithError(err)
 		return errors.NewStackedError(err, "failed to validate block")
 	}
+	stageSpan.End()
 	auditor.Audit("succeed to validate block %v", block.HeaderHash)
 
 	preHeader, err := bc.bcStore.GetBlockHeader(block.Header.PreviousBlockHash)
@@ -311,15 +386,22 @@ func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool) error {
 	// Process the txs in the block and check the state root hash.
 	var blockStatedb *state.Statedb
 	var receipts []*types.Receipt
+	stageSpan = span.StartChild("stateApply", tracing.Int("txs", int64(len(block.Transactions))), tracing.Int("debts", int64(len(block.Debts))))
 	if blockStatedb, receipts, err = bc.applyTxs(block, preHeader.StateHash); err != nil {
+		stageSpan.EndWithError(err)
 		return errors.NewStackedError(err, "failed to apply block txs")
 	}
 	auditor.Audit("succeed to apply %v txs and %v debts", len(block.Transactions), len(block.Debts))
 
 	// Validate receipts root hash.
 	if receiptsRootHash := types.ReceiptMerkleRootHash(receipts); !receiptsRootHash.Equal(block.Header.ReceiptHash) {
+		stageSpan.EndWithError(ErrBlockReceiptHashMismatch)
 		return ErrBlockReceiptHashMismatch
 	}
+	stageSpan.End()
+
+	stageSpan = span.StartChild("commit")
+	defer stageSpan.End()
 
 	// Validate state root hash.
 	batch := bc.accountStateDB.NewBatch()
@@ -337,9 +419,26 @@ func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool) error {
 	auditor.Audit("succeed to commit statedb changes to batch")
 
 	if !stateRootHash.Equal(block.Header.StateHash) {
+		if bc.stateMismatchReportDir != "" {
+			if path, err := bc.reportStateMismatch(block, preHeader.StateHash, stateRootHash, blockStatedb); err != nil {
+				bc.log.Warn("failed to report state hash mismatch of block %v, %v", block.HeaderHash, err)
+			} else {
+				bc.log.Warn("state hash mismatch of block %v is reported to %v", block.HeaderHash, path)
+			}
+		}
+
 		return ErrBlockStateHashMismatch
 	}
 
+	// the state diff is nil if not required, so that it is neither stored nor fired.
+	var stateDiff []*types.AccountDiff
+	if bc.stateDiffRequired() {
+		if stateDiff, err = bc.computeStateDiff(preHeader.StateHash, blockStatedb); err != nil {
+			return errors.NewStackedError(err, "failed to compute state diff")
+		}
+		auditor.Audit("succeed to compute state diff of %v accounts", len(stateDiff))
+	}
+
 	// Update block leaves and write the block into store.
 	currentBlock := &types.Block{
 		HeaderHash:   block.HeaderHash,
@@ -372,12 +471,19 @@ func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool) error {
 	isHead := bc.blockLeaves.IsBestBlockIndex(blockIndex)
 	auditor.Audit("succeed to prepare block index")
 
+	if isHead {
+		if err = bc.checkReorgDepth(block); err != nil {
+			return err
+		}
+	}
+
 	/////////////////////////////////////////////////////////////////
 	// PAY ATTENTION TO THE ORDER OF WRITING DATA INTO DB.
 	// OTHERWISE, THERE MAY BE INCONSISTENT DATA.
-	// 1. Write account states
-	// 2. Write receipts
-	// 3. Write block
+	// 1. Write account states in a batch of the state DB.
+	// 2. Write block, receipts, dirty accounts and state diff in a single batch of the chain DB.
+	// The account states are content addressed, so the orphaned states are harmless if the
+	// node crashes before the block is written, and the block is never written partially.
 	/////////////////////////////////////////////////////////////////
 	if err = batch.Commit(); err != nil {
 		return errors.NewStackedError(err, "failed to batch commit statedb changes to database")
@@ -388,18 +494,18 @@ func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool) error {
 		return errors.NewStackedErrorf(err, "failed to set recovery point before put block into store, isNewHead = %v", isHead)
 	}
 
-	if err = bc.bcStore.PutReceipts(block.HeaderHash, receipts); err != nil {
-		return errors.NewStackedErrorf(err, "failed to save receipts into store, blockHash = %v, receipts count = %v", block.HeaderHash, len(receipts))
+	blockData := &store.BlockData{
+		Receipts:      receipts,
+		DirtyAccounts: blockStatedb.GetDirtyAccounts(),
+		StateDiff:     stateDiff,
 	}
 
-	if err = bc.bcStore.PutBlock(block, currentTd, isHead); err != nil {
-		return errors.NewStackedErrorf(err, "failed to save block into store, blockHash = %v, newTD = %v, isNewHead = %v", block.HeaderHash, currentTd, isHead)
+	if err = bc.bcStore.WriteBlock(block, currentTd, isHead, blockData); err != nil {
+		return errors.NewStackedErrorf(err, "failed to write block into store, blockHash = %v, newTD = %v, isNewHead = %v, receipts count = %v",
+			block.HeaderHash, currentTd, isHead, len(receipts))
 	}
-	auditor.Audit("succeed to save block into store, newHead = %v", isHead)
+	auditor.Audit("succeed to write block into store, newHead = %v", isHead)
 
-	if err = bc.bcStore.PutDirtyAccounts(block.HeaderHash, blockStatedb.GetDirtyAccounts()); err != nil {
-		return errors.NewStackedErrorf(err, "failed to save dirty accounts into store, blockHash = %v, dirty accounts count = %v", block.HeaderHash, len(blockStatedb.GetDirtyAccounts()))
-	}
 	bc.rp.onPutBlockEnd()
 
 	// If the new block has larger TD, the canonical chain will be changed.
@@ -438,11 +544,74 @@ func (bc *Blockchain) doWriteBlock(block *types.Block, pool *Pool) error {
 		event.ChainHeaderChangedEventMananger.Fire(block)
 	}
 
+	event.BlockImportedEventManager.Fire(&BlockImportedEvent{block, receipts, stateDiff, currentTd, isHead})
+
 	bc.lastBlockTime = time.Now()
 
 	return nil
 }
 
+// SetMaxReorgDepth sets the max depth of a reorg, 0 means unlimited.
+func (bc *Blockchain) SetMaxReorgDepth(depth uint64) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.maxReorgDepth = depth
+}
+
+// MaxReorgDepth returns the max depth of a reorg, 0 means unlimited.
+func (bc *Blockchain) MaxReorgDepth() uint64 {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.maxReorgDepth
+}
+
+// AllowDeepReorgOnce allows the next reorg deeper than the max reorg depth.
+func (bc *Blockchain) AllowDeepReorgOnce() {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.allowDeepReorgOnce = true
+}
+
+// checkReorgDepth checks whether writing the block as new head would reorg the chain deeper
+// than the max reorg depth. A refused reorg is reported by the deep reorg event and metric.
+func (bc *Blockchain) checkReorgDepth(block *types.Block) error {
+	current := bc.CurrentBlock()
+	if bc.maxReorgDepth == 0 || block.Header.PreviousBlockHash.Equal(current.HeaderHash) {
+		return nil
+	}
+
+	ancestor, err := bc.FindCommonForkAncestor(block.Header, current.Header)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to find common ancestor of block %v and HEAD %v", block.HeaderHash, current.HeaderHash)
+	}
+
+	depth := current.Header.Height - ancestor
+	if depth <= bc.maxReorgDepth {
+		return nil
+	}
+
+	if bc.allowDeepReorgOnce {
+		bc.allowDeepReorgOnce = false
+		bc.log.Warn("allow reorg of depth %d once, block %v, height %d, common ancestor %d", depth, block.HeaderHash, block.Header.Height, ancestor)
+		return nil
+	}
+
+	bc.log.Error("refuse reorg of depth %d (max %d), block %v, height %d, common ancestor %d", depth, bc.maxReorgDepth, block.HeaderHash, block.Header.Height, ancestor)
+	metrics.MetricsDeepReorgMeter.Mark(1)
+	event.DeepReorgEventManager.Fire(&DeepReorgEvent{
+		BlockHash:      block.HeaderHash,
+		BlockHeight:    block.Header.Height,
+		HeadHeight:     current.Header.Height,
+		CommonAncestor: ancestor,
+		Depth:          depth,
+	})
+
+	return errors.NewStackedErrorf(ErrReorgTooDeep, "reorg depth %d, max %d", depth, bc.maxReorgDepth)
+}
+
 // validateBlock validates all blockhain independent fields in the block.
 func (bc *Blockchain) validateBlock(block *types.Block) error {
 	if block == nil {
@@ -453,8 +622,11 @@ func (bc *Blockchain) validateBlock(block *types.Block) error {
 		return errors.NewStackedError(err, "failed to validate block header")
 	}
 
-	if err := block.Validate(); err != nil {
-		return errors.NewStackedError(err, "failed to validate block")
+	// the merkle roots are verified in WriteBlock already
+	if !bc.prevalidator.contains(block) {
+		if err := block.Validate(); err != nil {
+			return errors.NewStackedError(err, "failed to validate block")
+		}
 	}
 
 	if len(block.Transactions) == 0 {
@@ -491,9 +663,16 @@ func ValidateBlockHeader(header *types.BlockHeader, engine consensus.Engine, bcS
 		return ErrBlockCreateTimeInFuture
 	}
 
-	// Now, the extra data in block header should be empty except the genesis block.
+	// The extra data in block header should be empty except the genesis block before the signaling fork,
+	// and after that, miners can set a small extra data for governance signaling.
 	if header.Consensus != types.IstanbulConsensus && len(header.ExtraData) > 0 {
-		return ErrBlockExtraDataNotEmpty
+		if !chainReader.Config().IsSignalingExtra(header.Height) {
+			return ErrBlockExtraDataNotEmpty
+		}
+
+		if len(header.ExtraData) > types.MaxSignalingExtraSize {
+			return ErrBlockExtraDataTooLong
+		}
 	}
 
 	if err := engine.VerifyHeader(chainReader, header); err != nil {
@@ -508,9 +687,68 @@ func (bc *Blockchain) GetStore() store.BlockchainStore {
 	return bc.bcStore
 }
 
+// TxTracerFunc returns the evm tracer of the tx at the index in the block, nil means the tx is not traced.
+type TxTracerFunc func(txIndex int, tx *types.Transaction) vm.Tracer
+
+// ReplayBlock re-executes the debts and txs of the specified block on the state of its parent block
+// without writing anything into the store, and returns the resulting state root hash and receipts.
+func (bc *Blockchain) ReplayBlock(block *types.Block, newTracer TxTracerFunc) (common.Hash, []*types.Receipt, error) {
+	if len(block.Transactions) == 0 {
+		return common.EmptyHash, nil, ErrBlockEmptyTxs
+	}
+
+	preHeader, err := bc.bcStore.GetBlockHeader(block.Header.PreviousBlockHash)
+	if err != nil {
+		return common.EmptyHash, nil, errors.NewStackedErrorf(err, "failed to get block header by hash %v", block.Header.PreviousBlockHash)
+	}
+
+	statedb, receipts, err := bc.applyTxsWithTracer(block, preHeader.StateHash, newTracer, nil)
+	if err != nil {
+		return common.EmptyHash, nil, err
+	}
+
+	root, err := statedb.Hash()
+	if err != nil {
+		return common.EmptyHash, nil, errors.NewStackedError(err, "failed to get statedb root hash")
+	}
+
+	return root, receipts, nil
+}
+
+// DryRunBlock validates the specified block and executes it on the state of its parent block like WriteBlock,
+// but nothing is written into the store. The resulting state root hash and receipts are returned along with
+// the error if the block is invalid because of the mismatched state or receipts root hash.
+func (bc *Blockchain) DryRunBlock(block *types.Block) (common.Hash, []*types.Receipt, error) {
+	if err := bc.validateBlock(block); err != nil {
+		return common.EmptyHash, nil, errors.NewStackedError(err, "failed to validate block")
+	}
+
+	root, receipts, err := bc.ReplayBlock(block, nil)
+	if err != nil {
+		return common.EmptyHash, nil, errors.NewStackedError(err, "failed to apply block txs")
+	}
+
+	if receiptsRootHash := types.ReceiptMerkleRootHash(receipts); !receiptsRootHash.Equal(block.Header.ReceiptHash) {
+		return root, receipts, ErrBlockReceiptHashMismatch
+	}
+
+	if !root.Equal(block.Header.StateHash) {
+		return root, receipts, ErrBlockStateHashMismatch
+	}
+
+	return root, receipts, nil
+}
+
 // applyTxs processes the txs in the specified block and returns the new state DB of the block.
 // This method supposes the specified block is validated.
+// The slow txs are recorded in the slow tx report.
 func (bc *Blockchain) applyTxs(block *types.Block, root common.Hash) (*state.Statedb, []*types.Receipt, error) {
+	return bc.applyTxsWithTracer(block, root, nil, bc.slowTxs)
+}
+
+// applyTxsWithTracer processes the txs like applyTxs, and the evm execution of the txs is traced by newTracer if not nil.
+// The slow txs are recorded in slowTxs if not nil.
+func (bc *Blockchain) applyTxsWithTracer(block *types.Block, root common.Hash, newTracer TxTracerFunc, slowTxs *slowTxReport) (*state.Statedb, []*types.Receipt, error) {
 	auditor := log.NewAuditor(bc.log)
 
 	statedb, err := state.NewStatedb(root, bc.accountStateDB)
@@ -546,7 +784,7 @@ func (bc *Blockchain) applyTxs(block *types.Block, root common.Hash) (*state.Sta
 	auditor.Audit("succeed to validate %v debts", len(block.Debts))
 
 	// apply txs
-	receipts, err := bc.applyRewardAndRegularTxs(statedb, block.Transactions[0], block.Transactions[1:], block.Header)
+	receipts, err := bc.applyRewardAndRegularTxs(statedb, block.Transactions[0], block.Transactions[1:], block.Header, newTracer, slowTxs)
 	if err != nil {
 		return nil, nil, errors.NewStackedErrorf(err, "failed to apply reward and regular txs")
 	}
@@ -555,8 +793,9 @@ func (bc *Blockchain) applyTxs(block *types.Block, root common.Hash) (*state.Sta
 	return statedb, receipts, nil
 }
 
-// applyRewardAndRegularTxs processes the reward tx and regular txs(not debts)
-func (bc *Blockchain) applyRewardAndRegularTxs(statedb *state.Statedb, rewardTx *types.Transaction, regularTxs []*types.Transaction, blockHeader *types.BlockHeader) ([]*types.Receipt, error) {
+// applyRewardAndRegularTxs processes the reward tx and regular txs(not debts), and records the slow txs in slowTxs if not nil
+func (bc *Blockchain) applyRewardAndRegularTxs(statedb *state.Statedb, rewardTx *types.Transaction, regularTxs []*types.Transaction,
+	blockHeader *types.BlockHeader, newTracer TxTracerFunc, slowTxs *slowTxReport) ([]*types.Receipt, error) {
 	auditor := log.NewAuditor(bc.log)
 
 	receipts := make([]*types.Receipt, len(regularTxs)+1)
@@ -583,15 +822,25 @@ func (bc *Blockchain) applyRewardAndRegularTxs(statedb *state.Statedb, rewardTx
 	for i, tx := range regularTxs {
 		txIdx := i + 1
 
-		if err := tx.ValidateState(statedb, blockHeader.Height); err != nil {
+		if err := tx.ValidateState(statedb, bc.chainConfig, blockHeader.Height); err != nil {
 			return nil, errors.NewStackedErrorf(err, "failed to validate tx[%v] against statedb", txIdx)
 		}
 
-		receipt, err := bc.ApplyTransaction(tx, txIdx, blockHeader.Creator, statedb, blockHeader)
+		var tracer vm.Tracer
+		if newTracer != nil {
+			tracer = newTracer(txIdx, tx)
+		}
+
+		start := time.Now()
+		receipt, err := bc.applyTransaction(nil, tx, txIdx, blockHeader.Creator, statedb, blockHeader, tracer)
 		if err != nil {
 			return nil, errors.NewStackedErrorf(err, "failed to apply tx[%v]", txIdx)
 		}
 
+		if slowTxs != nil && slowTxs.record(tx, txIdx, blockHeader, receipt, start) {
+			bc.log.Warn("slow tx %v in block %v, used gas %v, duration %v", tx.Hash.Hex(), blockHeader.Height, receipt.UsedGas, time.Since(start))
+		}
+
 		receipts[txIdx] = receipt
 	}
 	auditor.Audit("succeed to apply %v txs", len(regularTxs))
@@ -602,12 +851,28 @@ func (bc *Blockchain) applyRewardAndRegularTxs(statedb *state.Statedb, rewardTx
 // ApplyTransaction applies a transaction, changes corresponding statedb and generates its receipt
 func (bc *Blockchain) ApplyTransaction(tx *types.Transaction, txIndex int, coinbase common.Address, statedb *state.Statedb,
 	blockHeader *types.BlockHeader) (*types.Receipt, error) {
+	return bc.ApplyTransactionWithContext(nil, tx, txIndex, coinbase, statedb, blockHeader)
+}
+
+// ApplyTransactionWithContext applies a transaction like ApplyTransaction, and the evm execution
+// is aborted once the given context is cancelled, which is used by rpc calls.
+func (bc *Blockchain) ApplyTransactionWithContext(c context.Context, tx *types.Transaction, txIndex int, coinbase common.Address,
+	statedb *state.Statedb, blockHeader *types.BlockHeader) (*types.Receipt, error) {
+	return bc.applyTransaction(c, tx, txIndex, coinbase, statedb, blockHeader, nil)
+}
+
+// applyTransaction applies the tx, and the evm execution is traced by the tracer if not nil.
+func (bc *Blockchain) applyTransaction(c context.Context, tx *types.Transaction, txIndex int, coinbase common.Address,
+	statedb *state.Statedb, blockHeader *types.BlockHeader, tracer vm.Tracer) (*types.Receipt, error) {
 	ctx := &svm.Context{
 		Tx:          tx,
 		TxIndex:     txIndex,
 		Statedb:     statedb,
 		BlockHeader: blockHeader,
 		BcStore:     bc.bcStore,
+		Ctx:         c,
+		Tracer:      tracer,
+		ChainConfig: bc.chainConfig,
 	}
 
 	receipt, err := svm.Process(ctx, blockHeader.Height)
@@ -619,36 +884,110 @@ func (bc *Blockchain) ApplyTransaction(tx *types.Transaction, txIndex int, coinb
 	return receipt, nil
 }
 
-// ApplyDebtWithoutVerify applies a debt and update statedb.
-func (bc *Blockchain) ApplyDebtWithoutVerify(statedb *state.Statedb, d *types.Debt, coinbase common.Address, blockHeader *types.BlockHeader, commonAncestor uint64) error {
-	debtIndex, _ := bc.bcStore.GetDebtIndex(d.Hash)
-	if debtIndex != nil {
-		debtBlock, err := bc.bcStore.GetBlock(debtIndex.BlockHash)
+// isDebtSpent checks whether the debt is applied by the specified block header or its ancestors.
+// commonAncestor is the height of the common ancestor of the block header and the canonical HEAD.
+func (bc *Blockchain) isDebtSpent(debtHash common.Hash, header *types.BlockHeader, commonAncestor uint64) (bool, error) {
+	spents, err := bc.bcStore.GetSpentDebts(debtHash)
+	if err != nil {
+		return false, errors.NewStackedErrorf(err, "failed to get spent debts by hash %v", debtHash)
+	}
+
+	// the blocks written before the spent debt set are only checked in the canonical chain by the debt index
+	if len(spents) == 0 {
+		spent, err := bc.getSpentDebtByIndex(debtHash)
 		if err != nil {
-			return err
+			return false, err
+		}
+
+		return spent != nil && spent.BlockHeight <= commonAncestor, nil
+	}
+
+	for _, spent := range spents {
+		if spent.BlockHeight > header.Height {
+			continue
+		}
+
+		hash, err := bc.getAncestorHash(header, spent.BlockHeight, commonAncestor)
+		if err != nil {
+			return false, err
 		}
-		if debtBlock.Header.Height <= commonAncestor {
-			return fmt.Errorf("debt already packed, debt hash %s", d.Hash.Hex())
+
+		if hash.Equal(spent.BlockHash) {
+			return true, nil
 		}
 	}
 
-	// find debt in current fork (after fork height)
-	var blockHash common.Hash
-	blockHash = blockHeader.Hash()
-	blockHeight := blockHeader.Height
-	for blockHeight > commonAncestor {
-		if forkBlock, err := bc.GetStore().GetBlock(blockHash); err != nil {
-			return errors.NewStackedErrorf(err, "failed to get block header by hash %v", blockHash)
-		} else {
-			for _, debt := range forkBlock.Debts {
-				if d.Hash.Equal(debt.Hash) {
-					return fmt.Errorf("debt already packed, debt hash %s", d.Hash.Hex())
-				}
-			}
-			blockHeader = forkBlock.Header
+	return false, nil
+}
+
+// getAncestorHash returns the hash of the ancestor block at the specified height of the block header.
+func (bc *Blockchain) getAncestorHash(header *types.BlockHeader, height uint64, commonAncestor uint64) (common.Hash, error) {
+	// the ancestor is in the canonical chain
+	if height <= commonAncestor {
+		hash, err := bc.bcStore.GetBlockHash(height)
+		if err != nil {
+			return common.EmptyHash, errors.NewStackedErrorf(err, "failed to get block hash by height %v", height)
+		}
+
+		return hash, nil
+	}
+
+	for header.Height > height {
+		preHash := header.PreviousBlockHash
+
+		var err error
+		if header, err = bc.bcStore.GetBlockHeader(preHash); err != nil {
+			return common.EmptyHash, errors.NewStackedErrorf(err, "failed to get block header by hash %v", preHash)
+		}
+	}
+
+	return header.Hash(), nil
+}
+
+// GetSpentDebt returns the block in the canonical chain which applies the debt, or nil if the debt is not spent.
+func (bc *Blockchain) GetSpentDebt(debtHash common.Hash) (*types.SpentDebt, error) {
+	spents, err := bc.bcStore.GetSpentDebts(debtHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get spent debts by hash %v", debtHash)
+	}
+
+	if len(spents) == 0 {
+		return bc.getSpentDebtByIndex(debtHash)
+	}
+
+	for _, spent := range spents {
+		if hash, err := bc.bcStore.GetBlockHash(spent.BlockHeight); err == nil && hash.Equal(spent.BlockHash) {
+			return spent, nil
 		}
-		blockHash = blockHeader.PreviousBlockHash
-		blockHeight--
+	}
+
+	return nil, nil
+}
+
+// getSpentDebtByIndex returns the block in the canonical chain which applies the debt by the debt index.
+func (bc *Blockchain) getSpentDebtByIndex(debtHash common.Hash) (*types.SpentDebt, error) {
+	debtIndex, err := bc.bcStore.GetDebtIndex(debtHash)
+	if err != nil {
+		return nil, nil
+	}
+
+	header, err := bc.bcStore.GetBlockHeader(debtIndex.BlockHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get block header by hash %v", debtIndex.BlockHash)
+	}
+
+	return &types.SpentDebt{BlockHash: debtIndex.BlockHash, BlockHeight: header.Height}, nil
+}
+
+// ApplyDebtWithoutVerify applies a debt and update statedb.
+func (bc *Blockchain) ApplyDebtWithoutVerify(statedb *state.Statedb, d *types.Debt, coinbase common.Address, blockHeader *types.BlockHeader, commonAncestor uint64) error {
+	spent, err := bc.isDebtSpent(d.Hash, blockHeader, commonAncestor)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to check spent debt %v", d.Hash)
+	}
+
+	if spent {
+		return errors.NewStackedErrorf(ErrDebtAlreadySpent, "debt hash %s", d.Hash.Hex())
 	}
 
 	if !statedb.Exist(d.Data.Account) {
@@ -844,7 +1183,7 @@ func (bc *Blockchain) recoverHeightIndices() {
 	numGetBlockByHeight := 0
 	numGetBlockByHash := 0
 	numIrrecoverable := 0
-	for curHeight > common.SecondForkHeight {
+	for curHeight > bc.chainConfig.SecondForkHeight {
 		bc.log.Debug("checking blockchain database, height: %d", curHeight)
 		if curBlock, err := bc.bcStore.GetBlockByHeight(curHeight); err != nil {
 			bc.log.Error("height: %d, can't get block by height.", curHeight)
@@ -0,0 +1,248 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// integrityCheckDepth is the number of the most recent canonical blocks checked at startup
+const integrityCheckDepth = 128
+
+// integrityReport is the result of the chain data integrity check
+type integrityReport struct {
+	headHeight uint64
+	headHash   common.Hash
+	checked    int
+
+	// the lowest corrupted block, reason is empty if no corruption detected
+	corruptHeight uint64
+	corruptHash   common.Hash
+	reason        string
+
+	// the newest consistent block to rewind to
+	rewindHeight uint64
+	rewindHash   common.Hash
+}
+
+func (r *integrityReport) corrupted() bool {
+	return len(r.reason) > 0
+}
+
+func (r *integrityReport) String() string {
+	if !r.corrupted() {
+		return fmt.Sprintf("%d blocks checked from HEAD height %d, hash %v, no corruption detected", r.checked, r.headHeight, r.headHash.Hex())
+	}
+
+	return fmt.Sprintf("%d blocks checked from HEAD height %d, hash %v, corrupted block at height %d, hash %v, %s, rewind to height %d, hash %v",
+		r.checked, r.headHeight, r.headHash.Hex(), r.corruptHeight, r.corruptHash.Hex(), r.reason, r.rewindHeight, r.rewindHash.Hex())
+}
+
+// checkIntegrity checks the chain data integrity from the HEAD, and rewinds the chain to the newest consistent
+// block if any corruption detected, which is common after power loss. Returns the HEAD hash after rewind.
+func (bc *Blockchain) checkIntegrity(headHash common.Hash) (common.Hash, error) {
+	report, err := checkChainIntegrity(bc.bcStore, headHash, integrityCheckDepth)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	if !report.corrupted() {
+		bc.log.Info("chain data integrity checked, %s", report)
+		return headHash, nil
+	}
+
+	bc.log.Error("chain data corrupted, %s", report)
+
+	if err = rewindChain(bc.bcStore, bc.rp, report); err != nil {
+		return common.EmptyHash, errors.NewStackedErrorf(err, "failed to rewind chain to height %v", report.rewindHeight)
+	}
+
+	bc.log.Warn("chain rewound from height %d to %d, hash %v, the blocks above will be synced again",
+		report.headHeight, report.rewindHeight, report.rewindHash.Hex())
+
+	return report.rewindHash, nil
+}
+
+// checkChainIntegrity checks that the HEAD header, the height to hash mappings, and the bodies and receipts
+// of the most recent depth blocks are consistent, and finds the newest consistent block if any corruption detected.
+func checkChainIntegrity(bcStore store.BlockchainStore, headHash common.Hash, depth uint64) (*integrityReport, error) {
+	report := &integrityReport{headHash: headHash}
+
+	height, hash := uint64(0), headHash
+	if header, err := bcStore.GetBlockHeader(headHash); err == nil {
+		height = header.Height
+	} else {
+		// HEAD header is lost, check from the highest block in canonical chain
+		if height, err = highestCanonicalHeight(bcStore); err != nil {
+			return nil, errors.NewStackedError(err, "failed to get the highest canonical block height")
+		}
+
+		if hash, err = bcStore.GetBlockHash(height); err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get block hash by height %v", height)
+		}
+
+		// the HEAD block is regarded above the canonical chain
+		report.corruptHeight, report.corruptHash = height+1, headHash
+		report.reason = fmt.Sprintf("failed to get HEAD block header, %s", err)
+	}
+	report.headHeight = height
+
+	// the genesis block is not checked, whose receipts are not stored
+	bottom := uint64(genesisBlockHeight)
+	if height > bottom+depth {
+		bottom = height - depth
+	}
+
+	for h := height; h > bottom; h-- {
+		if err := checkBlockIntegrity(bcStore, h, hash); err != nil {
+			report.corruptHeight, report.corruptHash, report.reason = h, hash, err.Error()
+		}
+		report.checked++
+
+		// the parent hash by the header if available, otherwise by the canonical chain
+		if header, err := bcStore.GetBlockHeader(hash); err == nil {
+			hash = header.PreviousBlockHash
+		} else if hash, err = bcStore.GetBlockHash(h - 1); err != nil {
+			hash = common.EmptyHash
+		}
+	}
+
+	if !report.corrupted() {
+		return report, nil
+	}
+
+	// the blocks above the lowest corrupted one are discarded, so rewind to the
+	// newest consistent block below it, or the genesis block at last.
+	for h := report.corruptHeight; h > genesisBlockHeight; h-- {
+		hash, err := bcStore.GetBlockHash(h - 1)
+		if err != nil {
+			continue
+		}
+
+		if h-1 == genesisBlockHeight || checkBlockIntegrity(bcStore, h-1, hash) == nil {
+			report.rewindHeight, report.rewindHash = h-1, hash
+			return report, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no consistent block found below height %v, %s", report.corruptHeight, report.reason)
+}
+
+// checkBlockIntegrity checks the height to hash mapping, body and receipts of the block
+func checkBlockIntegrity(bcStore store.BlockchainStore, height uint64, hash common.Hash) error {
+	canonicalHash, err := bcStore.GetBlockHash(height)
+	if err != nil {
+		return fmt.Errorf("failed to get block hash by height, %s", err)
+	}
+
+	if !canonicalHash.Equal(hash) {
+		return fmt.Errorf("block hash by height mismatch, expected %v, got %v", hash.Hex(), canonicalHash.Hex())
+	}
+
+	block, err := bcStore.GetBlock(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get block, %s", err)
+	}
+
+	if block.Header.Height != height {
+		return fmt.Errorf("block height mismatch, expected %v, got %v", height, block.Header.Height)
+	}
+
+	if err = block.Validate(); err != nil {
+		return fmt.Errorf("invalid block body, %s", err)
+	}
+
+	receipts, err := bcStore.GetReceiptsByBlockHash(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get receipts, %s", err)
+	}
+
+	if h := types.ReceiptMerkleRootHash(receipts); !h.Equal(block.Header.ReceiptHash) {
+		return ErrBlockReceiptHashMismatch
+	}
+
+	return nil
+}
+
+// highestCanonicalHeight returns the highest height in canonical chain,
+// assuming the heights are continuous from the genesis block.
+func highestCanonicalHeight(bcStore store.BlockchainStore) (uint64, error) {
+	if _, err := bcStore.GetBlockHash(genesisBlockHeight); err != nil {
+		return 0, err
+	}
+
+	// the highest height is in [low, high)
+	low, high := uint64(genesisBlockHeight), uint64(genesisBlockHeight+1)
+	for step := uint64(1); ; step *= 2 {
+		if _, err := bcStore.GetBlockHash(high); err != nil {
+			break
+		}
+
+		low, high = high, high+step
+	}
+
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		if _, err := bcStore.GetBlockHash(mid); err == nil {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+// rewindChain rewinds the HEAD to the consistent block in report, and deletes the blocks above it from the
+// canonical chain. The recovery point is saved ahead, so that the rewind goes on if program crashes.
+func rewindChain(bcStore store.BlockchainStore, rp *recoveryPoint, report *integrityReport) error {
+	rp.PreviousHeadBlockHash = report.rewindHash
+	rp.onDeleteLargerHeightBlocks(report.rewindHeight + 1)
+
+	if err := bcStore.PutHeadBlockHash(report.rewindHash); err != nil {
+		return errors.NewStackedErrorf(err, "failed to put HEAD block hash %v", report.rewindHash)
+	}
+	rp.PreviousHeadBlockHash = common.EmptyHash
+
+	for h := report.rewindHeight + 1; ; h++ {
+		rp.onDeleteLargerHeightBlocks(h)
+
+		hash, err := bcStore.GetBlockHash(h)
+		if err != nil {
+			// the mapping may be lost below the HEAD
+			if h > report.headHeight {
+				break
+			}
+
+			continue
+		}
+
+		// the corrupted block may be unreadable, so delete the indices and block data if possible,
+		// and the block will be synced again.
+		if block, err := bcStore.GetBlock(hash); err == nil {
+			if err = bcStore.DeleteIndices(block); err != nil {
+				rpLog.Warn("failed to delete tx/debt indices of block %v, %s", hash.Hex(), err)
+			}
+		}
+
+		if _, err = bcStore.DeleteBlockHash(h); err != nil {
+			return errors.NewStackedErrorf(err, "failed to delete block hash by height %v", h)
+		}
+
+		if err = bcStore.DeleteBlock(hash); err != nil {
+			rpLog.Warn("failed to delete block %v, %s", hash.Hex(), err)
+		}
+	}
+
+	rp.onDeleteLargerHeightBlocks(0)
+
+	return nil
+}
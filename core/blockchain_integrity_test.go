@@ -0,0 +1,133 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestIntegrityChain writes the genesis block and n blocks in canonical chain, and returns the blocks
+func newTestIntegrityChain(bcStore store.BlockchainStore, n int) []*types.Block {
+	genesis := types.NewBlock(&types.BlockHeader{Height: genesisBlockHeight, Difficulty: big.NewInt(1)}, nil, nil, nil)
+	if err := bcStore.PutBlock(genesis, big.NewInt(1), true); err != nil {
+		panic(err)
+	}
+
+	blocks := []*types.Block{genesis}
+	for i := 1; i <= n; i++ {
+		header := &types.BlockHeader{
+			PreviousBlockHash: blocks[i-1].HeaderHash,
+			Height:            genesisBlockHeight + uint64(i),
+			Difficulty:        big.NewInt(1),
+		}
+
+		block := types.NewBlock(header, nil, nil, nil)
+		if err := bcStore.PutReceipts(block.HeaderHash, nil); err != nil {
+			panic(err)
+		}
+
+		if err := bcStore.PutBlock(block, big.NewInt(int64(i+1)), true); err != nil {
+			panic(err)
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+func Test_ChainIntegrity_Consistent(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := store.NewBlockchainDatabase(db)
+	blocks := newTestIntegrityChain(bcStore, 5)
+
+	report, err := checkChainIntegrity(bcStore, blocks[5].HeaderHash, 3)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, report.corrupted(), false)
+	assert.Equal(t, report.checked, 3)
+	assert.Equal(t, report.headHeight, blocks[5].Header.Height)
+
+	// genesis block is not checked
+	report, err = checkChainIntegrity(bcStore, blocks[5].HeaderHash, integrityCheckDepth)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, report.corrupted(), false)
+	assert.Equal(t, report.checked, 5)
+}
+
+func Test_ChainIntegrity_CorruptedReceipts(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := store.NewBlockchainDatabase(db)
+	blocks := newTestIntegrityChain(bcStore, 5)
+
+	receipts := []*types.Receipt{{TxHash: common.StringToHash("tx")}}
+	assert.Equal(t, bcStore.PutReceipts(blocks[3].HeaderHash, receipts), nil)
+
+	report, err := checkChainIntegrity(bcStore, blocks[5].HeaderHash, integrityCheckDepth)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, report.corrupted(), true)
+	assert.Equal(t, report.corruptHash, blocks[3].HeaderHash)
+	assert.Equal(t, report.rewindHash, blocks[2].HeaderHash)
+	assert.Equal(t, report.rewindHeight, blocks[2].Header.Height)
+
+	rp, _ := loadRecoveryPoint("")
+	assert.Equal(t, rewindChain(bcStore, rp, report), nil)
+	assert.Equal(t, *rp, recoveryPoint{})
+
+	head, err := bcStore.GetHeadBlockHash()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, head, blocks[2].HeaderHash)
+
+	for _, block := range blocks[3:] {
+		_, err = bcStore.GetBlockHash(block.Header.Height)
+		assert.Equal(t, err != nil, true)
+
+		has, _ := bcStore.HasBlock(block.HeaderHash)
+		assert.Equal(t, has, false)
+	}
+
+	// consistent after rewind
+	report, err = checkChainIntegrity(bcStore, head, integrityCheckDepth)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, report.corrupted(), false)
+}
+
+func Test_ChainIntegrity_MissingHeadHeader(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := store.NewBlockchainDatabase(db)
+	blocks := newTestIntegrityChain(bcStore, 5)
+
+	height, err := highestCanonicalHeight(bcStore)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, blocks[5].Header.Height)
+
+	// rewind to the highest block in canonical chain
+	report, err := checkChainIntegrity(bcStore, common.StringToHash("lost"), integrityCheckDepth)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, report.corrupted(), true)
+	assert.Equal(t, report.rewindHash, blocks[5].HeaderHash)
+
+	// the height to hash mapping is lost
+	_, err = bcStore.DeleteBlockHash(blocks[4].Header.Height)
+	assert.Equal(t, err, nil)
+
+	report, err = checkChainIntegrity(bcStore, blocks[5].HeaderHash, integrityCheckDepth)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, report.corruptHash, blocks[4].HeaderHash)
+	assert.Equal(t, report.rewindHash, blocks[3].HeaderHash)
+}
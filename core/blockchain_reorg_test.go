@@ -0,0 +1,92 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// putTestReorgBlocks puts a chain of n blocks after the parent into the store, and returns the last block
+func putTestReorgBlocks(t *testing.T, bc *Blockchain, parent *types.Block, n int, timestamp int64) *types.Block {
+	for i := 0; i < n; i++ {
+		header := &types.BlockHeader{
+			PreviousBlockHash: parent.HeaderHash,
+			Height:            parent.Header.Height + 1,
+			Difficulty:        big.NewInt(1),
+			CreateTimestamp:   big.NewInt(timestamp),
+		}
+
+		parent = types.NewBlock(header, nil, nil, nil)
+		assert.Equal(t, bc.bcStore.PutBlock(parent, big.NewInt(int64(header.Height+1)), false), nil)
+	}
+
+	return parent
+}
+
+func Test_Blockchain_CheckReorgDepth(t *testing.T) {
+	bc := NewTestBlockchain()
+
+	// genesis <- a1 <- a2 <- a3 (HEAD)
+	//         <- b1 <- b2 <- b3 <- b4
+	head := putTestReorgBlocks(t, bc, bc.genesisBlock, 3, 1)
+	bc.currentBlock.Store(head)
+	fork := putTestReorgBlocks(t, bc, bc.genesisBlock, 4, 2)
+
+	// unlimited
+	assert.Equal(t, bc.checkReorgDepth(fork), nil)
+
+	// reorg of depth 3 is allowed
+	bc.SetMaxReorgDepth(3)
+	assert.Equal(t, bc.checkReorgDepth(fork), nil)
+
+	// extending the HEAD is not a reorg
+	bc.SetMaxReorgDepth(1)
+	next := putTestReorgBlocks(t, bc, head, 1, 1)
+	assert.Equal(t, bc.checkReorgDepth(next), nil)
+
+	// reorg of depth 3 is refused, and reported by the event and metric
+	var refused *DeepReorgEvent
+	event.DeepReorgEventManager.AddOnceListener(func(e event.Event) {
+		refused = e.(*DeepReorgEvent)
+	})
+	count := metrics.MetricsDeepReorgMeter.Count()
+
+	err := bc.checkReorgDepth(fork)
+	assert.True(t, errors.IsOrContains(err, ErrReorgTooDeep))
+	assert.Equal(t, metrics.MetricsDeepReorgMeter.Count(), count+1)
+	assert.Equal(t, refused, &DeepReorgEvent{
+		BlockHash:      fork.HeaderHash,
+		BlockHeight:    fork.Header.Height,
+		HeadHeight:     head.Header.Height,
+		CommonAncestor: bc.genesisBlock.Header.Height,
+		Depth:          3,
+	})
+}
+
+func Test_Blockchain_AllowDeepReorgOnce(t *testing.T) {
+	bc := NewTestBlockchain()
+
+	head := putTestReorgBlocks(t, bc, bc.genesisBlock, 3, 1)
+	bc.currentBlock.Store(head)
+	fork := putTestReorgBlocks(t, bc, bc.genesisBlock, 4, 2)
+
+	bc.SetMaxReorgDepth(1)
+	assert.True(t, errors.IsOrContains(bc.checkReorgDepth(fork), ErrReorgTooDeep))
+
+	// the override only allows the next deep reorg
+	bc.AllowDeepReorgOnce()
+	count := metrics.MetricsDeepReorgMeter.Count()
+	assert.Equal(t, bc.checkReorgDepth(fork), nil)
+	assert.Equal(t, metrics.MetricsDeepReorgMeter.Count(), count)
+	assert.True(t, errors.IsOrContains(bc.checkReorgDepth(fork), ErrReorgTooDeep))
+}
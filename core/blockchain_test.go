@@ -14,10 +14,12 @@ import (
 	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/consensus"
 	"github.com/scdoproject/go-scdo/consensus/pow"
+	"github.com/scdoproject/go-scdo/consensus/utils"
 	"github.com/scdoproject/go-scdo/core/state"
 	"github.com/scdoproject/go-scdo/core/store"
 	"github.com/scdoproject/go-scdo/core/txs"
 	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/core/vm"
 	"github.com/scdoproject/go-scdo/database/leveldb"
 	"github.com/stretchr/testify/assert"
 	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
@@ -126,6 +128,64 @@ func newBlockWithDebtAndTxs(bc *Blockchain, parentHash common.Hash, blockHeight
 	return block
 }
 
+func Test_Blockchain_ReplayBlock(t *testing.T) {
+	bc := NewTestBlockchain()
+
+	newBlock := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 3, 0)
+
+	common.LocalShardNumber = newBlock.Transactions[0].Data.To.Shard()
+	defer func() {
+		common.LocalShardNumber = common.UndefinedShardNumber
+	}()
+
+	traced := 0
+	root, receipts, err := bc.ReplayBlock(newBlock, func(txIndex int, tx *types.Transaction) vm.Tracer {
+		traced++
+		return vm.NewStructLogger(nil)
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, root, newBlock.Header.StateHash)
+	assert.Equal(t, types.ReceiptMerkleRootHash(receipts), newBlock.Header.ReceiptHash)
+	assert.Equal(t, traced, len(newBlock.Transactions)-1)
+
+	// the replayed block is not written
+	assert.Equal(t, bc.CurrentBlock().HeaderHash, bc.genesisBlock.HeaderHash)
+}
+
+func Test_Blockchain_DryRunBlock(t *testing.T) {
+	bc := NewTestBlockchain()
+
+	// the block header must pass the consensus engine validation on top of the genesis block
+	parent := bc.genesisBlock.Header
+	newBlock := newTestBlock(bc, bc.genesisBlock.HeaderHash, parent.Height+1, 3, 0)
+	newBlock.Header.Difficulty = utils.GetDifficult(bc.Config(), newBlock.Header.CreateTimestamp.Uint64(), parent)
+	newBlock.HeaderHash = newBlock.Header.Hash()
+
+	common.LocalShardNumber = newBlock.Transactions[0].Data.To.Shard()
+	defer func() {
+		common.LocalShardNumber = common.UndefinedShardNumber
+	}()
+
+	root, receipts, err := bc.DryRunBlock(newBlock)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, root, newBlock.Header.StateHash)
+	assert.Equal(t, len(receipts), len(newBlock.Transactions))
+	assert.Equal(t, bc.CurrentBlock().HeaderHash, bc.genesisBlock.HeaderHash)
+
+	// state root mismatch, the executed root is returned
+	stateHash := newBlock.Header.StateHash
+	newBlock.Header.StateHash = common.EmptyHash
+	newBlock.HeaderHash = newBlock.Header.Hash()
+	root, _, err = bc.DryRunBlock(newBlock)
+	assert.Equal(t, err, ErrBlockStateHashMismatch)
+	assert.Equal(t, root, stateHash)
+
+	// invalid block
+	newBlock.HeaderHash = common.EmptyHash
+	_, _, err = bc.DryRunBlock(newBlock)
+	assert.True(t, errors.IsOrContains(err, types.ErrBlockHashMismatch))
+}
+
 func Test_Blockchain_WriteBlock_HeaderHashChanged(t *testing.T) {
 	bc := NewTestBlockchain()
 
@@ -6,11 +6,6 @@
 package core
 
 import (
-	"fmt"
-	"math/rand"
-	"sync"
-	"time"
-
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/core/types"
@@ -18,30 +13,26 @@ import (
 	"github.com/scdoproject/go-scdo/log"
 )
 
-var errTxCacheFull = errors.New("CachedTxs reaches max")
 var errDuplicateTx = errors.New("Tx already exists")
-var errTimestemp = errors.New("Tx time stamp is not set correctly")
 
 const CachedBlocks = uint64(24000)
-const PercentDelete = 20 // once CachedTxs reach max, 1/PercentDelete of capacity will be randomly deleted
+const PercentDelete = 20 // once SeenTxs shard reaches max, 1/PercentDelete of the shard will be deleted
 
+// CachedTxs is the hashes of txs in pool or recent blocks, which are kept in the
+// SeenTxs shared with the protocol layer instead of a separate hash set.
 type CachedTxs struct {
-	capacity uint64
-	lock     sync.RWMutex
-	content  map[common.Hash]*types.Transaction
-	log      *log.ScdoLog
+	seen *SeenTxs
+	log  *log.ScdoLog
 }
 
 // NewCachedTxs creates a new CachedTxs given capacity
 // 10 * 60 * 60s / 15(s) (block) * 500txs/block = 1.2M txs
-// 500 txs = 10KB, so total 1.2M txs will take up to 24MB size
+// each tx takes about 100 bytes in the SeenTxs, i.e. the 32 bytes hash key, the 32 bytes peer bits,
+// the epoch and the map overhead, so total 1.2M txs will take up to 120MB size
 func NewCachedTxs(capacity uint64) *CachedTxs {
-
 	return &CachedTxs{
-		content:  make(map[common.Hash]*types.Transaction),
-		capacity: capacity,
-		lock:     sync.RWMutex{},
-		log:      log.GetLogger("CachedTxs"),
+		seen: NewSeenTxs(int(capacity)),
+		log:  log.GetLogger("CachedTxs"),
 	}
 }
 
@@ -108,87 +99,23 @@ func (c *CachedTxs) getTxsInOneBlock(chain blockchain, h uint64) (int, int, erro
 	return duplicateTxCount, txCount, nil
 }
 
-// count returns teh number of cached txs
+// count returns the number of cached txs
 func (c *CachedTxs) count() int {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
-	return len(c.content)
+	return c.seen.cachedCount()
 }
 
 // add adds a tx to cached txs
 func (c *CachedTxs) add(tx *types.Transaction) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	if uint64(len(c.content)) >= c.capacity {
-		c.log.Error("Try to randomly remove txs, for %s", errTxCacheFull)
-		c.randomDeletes()
-		c.log.Debug("after remove, CachedTxs size %d", len(c.content))
-	}
-
-	if c.content[tx.Hash] != nil {
-		timestampstr := fmt.Sprint(tx.Data.Timestamp)
-		if len(timestampstr) < 10 {
-			c.log.Error("Block tx %s", errTimestemp)
-		}
-		c.log.Debug("Block tx, %s", errDuplicateTx)
-
-	}
-	c.content[tx.Hash] = tx
+	c.seen.markCached(tx.Hash)
 	c.log.Debug("[CachedTxs] add tx %+v", tx.Hash)
 }
 
 // remove removes a tx by hash from cached txs
 func (c *CachedTxs) remove(hash common.Hash) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	delete(c.content, hash)
+	c.seen.unmarkCached(hash)
 }
 
 // has returns true if the given tx is in the cached txs
 func (c *CachedTxs) has(hash common.Hash) bool {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
-	return c.content[hash] != nil
-}
-
-// getCachedTxs returns txs from the cached txs
-func (c *CachedTxs) getCachedTxs() []*types.Transaction {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
-
-	list := make([]*types.Transaction, len(c.content))
-	i := 0
-	for _, tx := range c.content {
-		list[i] = tx
-		i++
-	}
-	return list
-}
-
-// randomDeletes randomly delete 1/PercentDelete of map
-// random delete make sure the p2p network still have strongest protection from duplicate txs as possible with relasing RAM pressure.
-// Furthermore, even in the extreme case : the node own duplicate txs in pool, the node may not the node successfully mined the block.
-// At Last, the normal tx, we still have duplicate check in our pool.
-func (c *CachedTxs) randomDeletes() {
-	rand.Seed(time.Now().UnixNano())
-	deleteSize := (int)(len(c.content) / PercentDelete)
-	for i := 0; i < deleteSize; i++ {
-		k, _ := c.selRand()
-		delete(c.content, k)
-		i++
-	}
-}
-
-func (c *CachedTxs) selRand() (k common.Hash, v *types.Transaction) {
-	i := rand.Intn(len(c.content)) //24000 * 500
-	// since perm or use shuffle will consume either more RAM or more time.
-	// Here just use rand.Intn and then iterate the map to delete it.
-
-	for k := range c.content {
-		if i == 0 {
-			return k, c.content[k]
-		}
-		i--
-	}
-	return k, c.content[k]
+	return c.seen.Cached(hash)
 }
@@ -6,49 +6,93 @@
 package core
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/hashicorp/golang-lru"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/core/state"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/event"
 	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics"
 )
 
-const debtTimeoutDuration = 3 * time.Hour
+const (
+	defaultDebtTimeout     = 3 * time.Hour
+	defaultDebtMaxRequeues = 3
+
+	// expiredDebtsCapacity is the max number of the expired debts kept as dead letters
+	expiredDebtsCapacity = 10000
+
+	// expiredDebtsQueueSize is the max number of the expired debts waiting to be requeued
+	expiredDebtsQueueSize = 1024
+
+	// debtVerifyBatchSize is the max number of debts of a source shard verified at once by the batch verifier
+	debtVerifyBatchSize = 64
+)
+
+var errDebtPriceTooLow = errors.New("debt price is lower than the minimum debt price")
+
+// ExpiredDebt is a debt expired without being packed, which is not requeued
+type ExpiredDebt struct {
+	Debt      *types.Debt `json:"debt"`
+	Reason    string      `json:"reason"`
+	ExpiredAt int64       `json:"expiredAt"` // unix time
+	Requeues  int         `json:"requeues"`  // times the debt is requeued before
+}
 
 // DebtPool debt pool
 type DebtPool struct {
 	*Pool
 	verifier         types.DebtVerifier
 	toConfirmedDebts *ConcurrentDebtMap
+	minPrice         atomic.Value // *big.Int
+
+	timeout     int64      // time.Duration, accessed atomically
+	maxRequeues int64      // accessed atomically
+	requeues    *lru.Cache // debt hash -> times requeued
+	expired     chan *types.Debt
+	deadLetters *lru.Cache // debt hash -> *ExpiredDebt
 }
 
 // NewDebtPool creates and returns a new debt pool
 func NewDebtPool(chain blockchain, verifier types.DebtVerifier) *DebtPool {
 	log := log.GetLogger("debtpool")
+	debtPool := &DebtPool{
+		verifier:         verifier,
+		toConfirmedDebts: NewConcurrentDebtMap(ToConfirmedDebtCapacity),
+		requeues:         common.MustNewCache(expiredDebtsCapacity),
+		expired:          make(chan *types.Debt, expiredDebtsQueueSize),
+		deadLetters:      common.MustNewCache(expiredDebtsCapacity),
+	}
 
 	getObjectFromBlock := func(block *types.Block) []poolObject {
 		return debtsToObjects(block.Debts)
 	}
 	// 1st bool: can remove from object pool
 	// 2nd bool: can remove from cachedTxs
-	canRemove := func(chain blockchain, state *state.Statedb, item *poolItem) (bool, bool) {
-		nowTimestamp := time.Now()
-		duration := nowTimestamp.Sub(item.timestamp)
-		if duration > debtTimeoutDuration {
-			log.Debug("remove debt %s because not packed for more than three hours", item.GetHash().Hex())
-			return true, true
+	// 3rd string: the reason if the debt is dropped without being packed
+	canRemove := func(chain blockchain, state *state.Statedb, item *poolItem) (bool, bool, string) {
+		if debtIndex, err := chain.GetStore().GetDebtIndex(item.GetHash()); err == nil && debtIndex != nil {
+			return true, false, ""
 		}
-		debtIndex, err := chain.GetStore().GetDebtIndex(item.GetHash())
-		if err != nil || debtIndex == nil {
-			return false, false
+
+		timeout := time.Duration(atomic.LoadInt64(&debtPool.timeout))
+		if time.Now().Sub(item.timestamp) > timeout {
+			reason := fmt.Sprintf("not packed for more than %v", timeout)
+			log.Debug("remove debt %s because %s", item.GetHash().Hex(), reason)
+			debtPool.expire(item.poolObject.(*types.Debt), reason)
+			return true, true, reason
 		}
 
-		return true, false
+		return false, false, ""
 	}
 
 	objectValidation := func(state *state.Statedb, obj poolObject) error {
@@ -62,19 +106,139 @@ func NewDebtPool(chain blockchain, verifier types.DebtVerifier) *DebtPool {
 		event.DebtsInsertedEventManager.Fire(obj.(*types.Debt))
 	}
 	cachedTxs := NewCachedTxs(0)
-	pool := NewPool(DebtPoolCapacity, chain, getObjectFromBlock, canRemove, log, objectValidation, afterAdd, cachedTxs)
-
-	debtPool := &DebtPool{
-		Pool:             pool,
-		verifier:         verifier,
-		toConfirmedDebts: NewConcurrentDebtMap(ToConfirmedDebtCapacity),
-	}
+	debtPool.Pool = NewPool(DebtPoolCapacity, chain, getObjectFromBlock, canRemove, log, objectValidation, afterAdd, cachedTxs)
+	debtPool.SetMinPrice(nil)
+	debtPool.SetExpiry(0, 0)
 
 	go debtPool.loopCheckingDebt()
+	go debtPool.loopRequeueDebts()
 
 	return debtPool
 }
 
+// SetMinPrice sets the minimum price of the debts accepted by the pool, nil means no limit.
+// The debts already in the pool are not affected.
+func (dp *DebtPool) SetMinPrice(price *big.Int) {
+	if price == nil {
+		price = big.NewInt(0)
+	}
+
+	dp.minPrice.Store(new(big.Int).Set(price))
+}
+
+// MinPrice returns the minimum price of the debts accepted by the pool
+func (dp *DebtPool) MinPrice() *big.Int {
+	return new(big.Int).Set(dp.minPrice.Load().(*big.Int))
+}
+
+// SetExpiry sets the timeout in seconds after which the debts not packed are expired, and the max times an
+// expired debt is requeued if its tx is still confirmed in the source shard. 0 means the defaults, and
+// negative maxRequeues disables the requeue.
+func (dp *DebtPool) SetExpiry(timeout int64, maxRequeues int) {
+	duration := time.Duration(timeout) * time.Second
+	if timeout <= 0 {
+		duration = defaultDebtTimeout
+	}
+
+	if maxRequeues == 0 {
+		maxRequeues = defaultDebtMaxRequeues
+	}
+
+	atomic.StoreInt64(&dp.timeout, int64(duration))
+	atomic.StoreInt64(&dp.maxRequeues, int64(maxRequeues))
+}
+
+// expire requeues the expired debt if allowed, otherwise keeps it as a dead letter
+func (dp *DebtPool) expire(debt *types.Debt, reason string) {
+	requeues := dp.requeueCount(debt.Hash)
+	if dp.verifier == nil || int64(requeues) >= atomic.LoadInt64(&dp.maxRequeues) {
+		dp.addDeadLetter(debt, reason, requeues)
+		return
+	}
+
+	select {
+	case dp.expired <- debt:
+	default:
+		dp.addDeadLetter(debt, reason+", requeue queue is full", requeues)
+	}
+}
+
+// loopRequeueDebts requeues the expired debts whose txs are still confirmed in the source shard
+func (dp *DebtPool) loopRequeueDebts() {
+	if dp.verifier == nil {
+		return
+	}
+
+	for debt := range dp.expired {
+		dp.requeue(debt)
+	}
+}
+
+// requeue adds the expired debt to be confirmed and packed again if its tx is still confirmed in the source
+// shard, e.g. the debt is not packed in time as the pool is congested. Otherwise it is kept as a dead letter.
+func (dp *DebtPool) requeue(debt *types.Debt) {
+	requeues := dp.requeueCount(debt.Hash)
+
+	_, confirmed, err := dp.verifier.ValidateDebt(debt)
+	if err != nil || !confirmed {
+		reason := "tx is not confirmed in the source shard"
+		if err != nil {
+			reason = fmt.Sprintf("failed to confirm tx in the source shard, %s", err)
+		}
+
+		dp.addDeadLetter(debt, reason, requeues)
+		return
+	}
+
+	if err = dp.toConfirmedDebts.add(debt); err != nil {
+		dp.addDeadLetter(debt, fmt.Sprintf("failed to requeue, %s", err), requeues)
+		return
+	}
+
+	dp.requeues.Add(debt.Hash, requeues+1)
+	dp.log.Info("requeue expired debt %s, requeued %d times", debt.Hash.Hex(), requeues+1)
+}
+
+func (dp *DebtPool) requeueCount(hash common.Hash) int {
+	if value, ok := dp.requeues.Get(hash); ok {
+		return value.(int)
+	}
+
+	return 0
+}
+
+func (dp *DebtPool) addDeadLetter(debt *types.Debt, reason string, requeues int) {
+	dp.log.Warn("debt %s expired without being packed, %s", debt.Hash.Hex(), reason)
+	dp.deadLetters.Add(debt.Hash, &ExpiredDebt{
+		Debt:      debt,
+		Reason:    reason,
+		ExpiredAt: time.Now().Unix(),
+		Requeues:  requeues,
+	})
+}
+
+// GetExpiredDebt returns the expired debt kept as a dead letter, or nil if not found
+func (dp *DebtPool) GetExpiredDebt(hash common.Hash) *ExpiredDebt {
+	if value, ok := dp.deadLetters.Peek(hash); ok {
+		return value.(*ExpiredDebt)
+	}
+
+	return nil
+}
+
+// GetExpiredDebts returns the expired debts kept as dead letters, from the oldest to the newest
+func (dp *DebtPool) GetExpiredDebts() []*ExpiredDebt {
+	keys := dp.deadLetters.Keys()
+	debts := make([]*ExpiredDebt, 0, len(keys))
+	for _, key := range keys {
+		if value, ok := dp.deadLetters.Peek(key); ok {
+			debts = append(debts, value.(*ExpiredDebt))
+		}
+	}
+
+	return debts
+}
+
 // loopCheckingDebt check whether debt is confirmed.
 // we only add debt to pool when it is confirmed
 func (dp *DebtPool) loopCheckingDebt() {
@@ -100,6 +264,10 @@ func (dp *DebtPool) loopCheckingDebt() {
 // DoMulCheckingDebt use multiple threads to validate debts
 func (dp *DebtPool) DoMulCheckingDebt() error {
 	tmp := dp.toConfirmedDebts.getList()
+	if verifier, ok := dp.verifier.(types.BatchDebtVerifier); ok {
+		return dp.doBatchCheckingDebt(tmp, verifier)
+	}
+
 	len := len(tmp)
 	threads := runtime.NumCPU() / 2
 	dp.log.Info("use %d threads to validate debts", threads)
@@ -135,9 +303,45 @@ func (dp *DebtPool) DoMulCheckingDebt() error {
 	return err
 }
 
+// doBatchCheckingDebt validates the debts grouped by source shard with the batch verifier,
+// so that the debts of a shard are confirmed in a few requests instead of one request per debt.
+func (dp *DebtPool) doBatchCheckingDebt(debts []*types.Debt, verifier types.BatchDebtVerifier) error {
+	var err error
+	valid := make([]*types.Debt, 0, len(debts))
+	for _, d := range debts {
+		// the invalid debts are removed without verification
+		if _, e := d.Validate(nil, false, common.LocalShardNumber); e != nil {
+			dp.log.Info("check debt with unrecoverable error %s", e)
+			dp.toConfirmedDebts.removeByValue(d)
+			if err == nil {
+				err = e
+			}
+
+			continue
+		}
+
+		valid = append(valid, d)
+	}
+
+	dp.log.Debug("validate %d debts in batch", len(valid))
+	results := types.VerifyDebtsBySourceShard(valid, verifier, debtVerifyBatchSize)
+	for i, d := range valid {
+		if e := dp.checkDebt(d, results[i]); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
 // DoMulCheckingDebtHandler DoMulCheckingDebt handler
 func (dp *DebtPool) DoMulCheckingDebtHandler(d *types.Debt) error {
-	recoverable, err := d.Validate(dp.verifier, false, common.LocalShardNumber)
+	return dp.checkDebt(d, dp.verifier)
+}
+
+// checkDebt validates the debt with the verifier, and adds it to the pool once confirmed.
+func (dp *DebtPool) checkDebt(d *types.Debt, verifier types.DebtVerifier) error {
+	recoverable, err := d.Validate(verifier, false, common.LocalShardNumber)
 	if err != nil {
 		if recoverable {
 			dp.log.Debug("check debt with recoverable error %s", err)
@@ -206,6 +410,12 @@ func (dp *DebtPool) AddDebt(debt *types.Debt) error {
 		return nil
 	}
 
+	if debt.Data.Price != nil && debt.Data.Price.Cmp(dp.minPrice.Load().(*big.Int)) < 0 {
+		metrics.MetricsDebtPoolLowPriceMeter.Mark(1)
+		dp.log.Debug("reject debt %s with price %s lower than the minimum", debt.Hash.Hex(), debt.Data.Price)
+		return errDebtPriceTooLow
+	}
+
 	err := dp.toConfirmedDebts.add(debt)
 	if err != nil {
 		dp.log.Warn("add debts to to be confirmed pool failed debt hash:%s, err: %s.", debt.Hash, err)
@@ -219,11 +429,27 @@ func (dp *DebtPool) addToPool(debt *types.Debt) error {
 	err := dp.addObject(debt)
 	if err != nil {
 		dp.log.Warn("add debts failed debt hash:%s, err: %s.", debt.Hash, err)
+	} else {
+		metrics.MetricsDebtPoolPriceHistogram.Update(priceToInt64(debt.Data.Price))
+		dp.deadLetters.Remove(debt.Hash)
 	}
 
 	return err
 }
 
+// priceToInt64 returns the price as int64 for metrics, capped at math.MaxInt64
+func priceToInt64(price *big.Int) int64 {
+	if price == nil {
+		return 0
+	}
+
+	if !price.IsInt64() {
+		return math.MaxInt64
+	}
+
+	return price.Int64()
+}
+
 // GetProcessableDebts gets processable debts given the total size from the debt pool
 func (dp *DebtPool) GetProcessableDebts(size int) ([]*types.Debt, int) {
 	objects, remainSize := dp.getProcessableObjects(size)
@@ -231,6 +457,12 @@ func (dp *DebtPool) GetProcessableDebts(size int) ([]*types.Debt, int) {
 	return objectsToDebts(objects), remainSize
 }
 
+// ReturnDebts returns the processable debts not packed by the miner back to the pending queue,
+// so that they are processable again without waiting for the reinjection of the pool.
+func (dp *DebtPool) ReturnDebts(debts []*types.Debt) {
+	dp.returnObjects(debtsToObjects(debts))
+}
+
 // objectsToDebts converts objects to debts
 func objectsToDebts(objects []poolObject) []*types.Debt {
 	results := make([]*types.Debt, len(objects))
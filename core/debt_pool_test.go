@@ -6,10 +6,13 @@
 package core
 
 import (
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/fatih/set.v0"
 )
@@ -86,6 +89,51 @@ func Test_OrderByFee(t *testing.T) {
 	assert.Equal(t, results[0].Data.Price.Cmp(results[1].Data.Price), 1)
 }
 
+func Test_DebtPoolMinPrice(t *testing.T) {
+	bc := NewTestBlockchain()
+	pool := NewDebtPool(bc, nil)
+	assert.Equal(t, pool.MinPrice().Int64(), int64(0))
+
+	pool.SetMinPrice(big.NewInt(10))
+	assert.Equal(t, pool.MinPrice().Int64(), int64(10))
+
+	common.LocalShardNumber = 2
+	defer func() {
+		common.LocalShardNumber = common.UndefinedShardNumber
+	}()
+	assert.Equal(t, pool.AddDebt(newTestCrossShardDebt(1, 9)), errDebtPriceTooLow)
+	assert.Equal(t, pool.AddDebt(newTestCrossShardDebt(1, 10)), nil)
+	assert.Equal(t, pool.GetDebtCount(true, true), 1)
+
+	pool.SetMinPrice(nil)
+	assert.Equal(t, pool.AddDebt(newTestCrossShardDebt(1, 1)), nil)
+	assert.Equal(t, pool.GetDebtCount(true, true), 2)
+}
+
+func Test_ReturnDebts(t *testing.T) {
+	bc := NewTestBlockchain()
+	pool := NewDebtPool(bc, nil)
+
+	common.LocalShardNumber = 2
+	defer func() {
+		common.LocalShardNumber = common.UndefinedShardNumber
+	}()
+	pool.AddDebtArray([]*types.Debt{newTestCrossShardDebt(1, 10), newTestCrossShardDebt(2, 11)})
+	pool.DoCheckingDebt()
+
+	results, _ := pool.GetProcessableDebts(10000)
+	assert.Equal(t, len(results), 2)
+	assert.Equal(t, pool.getObjectCount(false, true), 0)
+
+	pool.ReturnDebts(results[1:])
+	assert.Equal(t, pool.getObjectCount(true, false), 1)
+	assert.Equal(t, pool.getObjectCount(false, true), 1)
+
+	results, _ = pool.GetProcessableDebts(10000)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Data.Price.Int64(), int64(10))
+}
+
 func Test_AddWithValidation(t *testing.T) {
 	verifier := types.NewTestVerifier(true, false, nil)
 	bc := NewTestBlockchain()
@@ -134,3 +182,62 @@ func Test_DebtPoolFull(t *testing.T) {
 	err := pool.addToPool(d)
 	assert.Equal(t, err, errObjectPoolFull)
 }
+
+// newTestCrossShardDebt creates a debt from shard 1 to the local shard 2
+func newTestCrossShardDebt(amount, price int64) *types.Debt {
+	from, key := crypto.MustGenerateShardKeyPair(1)
+	tx, _ := types.NewTransaction(*from, *crypto.MustGenerateShardAddress(2), big.NewInt(amount), big.NewInt(price), 1)
+	tx.Sign(key)
+
+	return types.NewDebtWithoutContext(tx)
+}
+
+func Test_DebtPoolExpiry(t *testing.T) {
+	bc := NewTestBlockchain()
+	pool := NewDebtPool(bc, nil)
+	pool.SetExpiry(60, -1)
+
+	d1 := newTestCrossShardDebt(1, 10)
+	d2 := newTestCrossShardDebt(2, 10)
+
+	common.LocalShardNumber = 2
+	defer func() {
+		common.LocalShardNumber = common.UndefinedShardNumber
+	}()
+	pool.AddDebtArray([]*types.Debt{d1, d2})
+	pool.DoCheckingDebt()
+	assert.Equal(t, pool.getObjectCount(true, true), 2)
+
+	pool.hashToTxMap[d1.Hash].timestamp = time.Now().Add(-time.Hour)
+	pool.removeObjects()
+
+	assert.Equal(t, pool.getObjectCount(true, true), 1)
+	expired := pool.GetExpiredDebts()
+	assert.Equal(t, len(expired), 1)
+	assert.Equal(t, expired[0].Debt, d1)
+	assert.Equal(t, expired[0].Reason, "not packed for more than 1m0s")
+	assert.Equal(t, pool.GetExpiredDebt(d2.Hash) == nil, true)
+}
+
+func Test_DebtPoolRequeue(t *testing.T) {
+	bc := NewTestBlockchain()
+	d := newTestCrossShardDebt(1, 10)
+
+	// requeued as the tx is still confirmed in the source shard
+	pool := NewDebtPool(bc, types.NewTestVerifier(true, true, nil))
+	pool.requeue(d)
+	assert.Equal(t, pool.toConfirmedDebts.has(d.Hash), true)
+	assert.Equal(t, pool.requeueCount(d.Hash), 1)
+	assert.Equal(t, pool.GetExpiredDebt(d.Hash) == nil, true)
+
+	// dead letter once requeued too many times
+	pool.SetExpiry(0, 1)
+	pool.expire(d, "expired")
+	assert.Equal(t, pool.GetExpiredDebt(d.Hash).Requeues, 1)
+
+	// dead letter if the tx is not confirmed
+	pool = NewDebtPool(bc, types.NewTestVerifier(true, false, nil))
+	pool.requeue(d)
+	assert.Equal(t, pool.toConfirmedDebts.has(d.Hash), false)
+	assert.Equal(t, pool.GetExpiredDebt(d.Hash).Reason, "tx is not confirmed in the source shard")
+}
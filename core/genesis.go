@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"reflect"
 
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/scdoproject/go-scdo/common"
@@ -65,6 +66,9 @@ type GenesisInfo struct {
 
 	// balance of the master account
 	Balance *big.Int `json:"balance"`
+
+	// ChainConfig fork activation heights of the chain, nil for the main network
+	ChainConfig *common.ChainConfig `json:"chainConfig,omitempty"`
 }
 
 func NewGenesisInfo(accounts map[common.Address]*big.Int, difficult int64, shard uint, timestamp *big.Int,
@@ -194,13 +198,43 @@ func (genesis *Genesis) GetShardNumber() uint {
 	return genesis.info.ShardNumber
 }
 
+// GetAllocation returns the total balance allocated to the accounts of the shard in genesis block,
+// including the master account which holds the mined rewards before fork.
+func (genesis *Genesis) GetAllocation() *big.Int {
+	total := big.NewInt(0)
+	if genesis.info.Balance != nil {
+		total.Add(total, genesis.info.Balance)
+	}
+
+	for addr, amount := range genesis.info.Accounts {
+		if !common.IsShardEnabled() || addr.Shard() == genesis.info.ShardNumber {
+			total.Add(total, amount)
+		}
+	}
+
+	return total
+}
+
+// GetChainConfig gets the chain config in genesis info, or the config of the main network if not specified.
+func (genesis *Genesis) GetChainConfig() *common.ChainConfig {
+	if genesis.info.ChainConfig == nil {
+		return common.DefaultChainConfig()
+	}
+
+	return genesis.info.ChainConfig
+}
+
 // InitializeAndValidate writes the genesis block in the blockchain store if unavailable.
 // Otherwise, check if the existing genesis block is valid in the blockchain store.
 func (genesis *Genesis) InitializeAndValidate(bcStore store.BlockchainStore, accountStateDB database.Database) error {
 	storedGenesisHash, err := bcStore.GetBlockHash(genesisBlockHeight)
 
 	if err == leveldbErrors.ErrNotFound {
-		return genesis.store(bcStore, accountStateDB)
+		if err = genesis.store(bcStore, accountStateDB); err != nil {
+			return err
+		}
+
+		return genesis.storeChainConfig(bcStore)
 	}
 
 	if err != nil {
@@ -225,7 +259,57 @@ func (genesis *Genesis) InitializeAndValidate(bcStore store.BlockchainStore, acc
 		return ErrGenesisHashMismatch
 	}
 
-	return nil
+	return genesis.storeChainConfig(bcStore)
+}
+
+// storeChainConfig writes the chain config in the blockchain store. If the chain config is already stored,
+// the changed fork heights should not be reached by the chain yet.
+func (genesis *Genesis) storeChainConfig(bcStore store.BlockchainStore) error {
+	config := genesis.GetChainConfig()
+
+	stored, err := bcStore.GetChainConfig()
+	if err == leveldbErrors.ErrNotFound {
+		return bcStore.PutChainConfig(config)
+	}
+
+	if err != nil {
+		return errors.NewStackedError(err, "failed to get chain config")
+	}
+
+	if reflect.DeepEqual(stored, config) {
+		return nil
+	}
+
+	headHash, err := bcStore.GetHeadBlockHash()
+	if err != nil {
+		return errors.NewStackedError(err, "failed to get HEAD block hash")
+	}
+
+	head, err := bcStore.GetBlockHeader(headHash)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to get HEAD block header by hash %v", headHash)
+	}
+
+	if err = config.CheckCompatible(stored, head.Height); err != nil {
+		return err
+	}
+
+	return bcStore.PutChainConfig(config)
+}
+
+// GetChainConfig gets the chain config stored in the blockchain store, or the config of the main network
+// if it is not stored, e.g. the store is initialized by an old version.
+func GetChainConfig(bcStore store.BlockchainStore) (*common.ChainConfig, error) {
+	config, err := bcStore.GetChainConfig()
+	if err == leveldbErrors.ErrNotFound {
+		return common.DefaultChainConfig(), nil
+	}
+
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to get chain config")
+	}
+
+	return config, nil
 }
 
 // store atomically stores the genesis block in the blockchain store.
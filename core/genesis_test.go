@@ -117,6 +117,36 @@ func Test_Genesis_Init_GenesisMismatch(t *testing.T) {
 	assert.Equal(t, err, ErrGenesisHashMismatch)
 }
 
+func Test_Genesis_Init_ChainConfig(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := store.NewBlockchainDatabase(db)
+
+	config := common.DefaultChainConfig()
+	config.SignalingExtraForkHeight = genesisBlockHeight + 100
+	assert.Equal(t, GetGenesis(&GenesisInfo{ChainConfig: config}).InitializeAndValidate(bcStore, db), nil)
+
+	stored, err := GetChainConfig(bcStore)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, stored, config)
+
+	// fork height not reached yet could be changed
+	config.SignalingExtraForkHeight = genesisBlockHeight + 200
+	assert.Equal(t, GetGenesis(&GenesisInfo{ChainConfig: config}).InitializeAndValidate(bcStore, db), nil)
+
+	stored, err = GetChainConfig(bcStore)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, stored, config)
+
+	// fork height already reached could not be changed
+	config.EmeryForkHeight = genesisBlockHeight + 100
+	assert.Equal(t, GetGenesis(&GenesisInfo{ChainConfig: config}).InitializeAndValidate(bcStore, db) != nil, true)
+
+	// main network by default
+	assert.Equal(t, GetGenesis(&GenesisInfo{}).GetChainConfig(), common.DefaultChainConfig())
+}
+
 func validateGenesisDefaultMembers(t *testing.T, genesis *Genesis) {
 	assert.Equal(t, genesis.header.PreviousBlockHash, common.EmptyHash)
 	assert.Equal(t, genesis.header.Creator, common.EmptyAddress)
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hashicorp/golang-lru"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/core/state"
@@ -27,6 +28,9 @@ var (
 
 var CachedCapacity = CachedBlocks * 500
 
+// droppedObjectsCapacity is the max number of recently dropped objects remembered by the pool
+const droppedObjectsCapacity = 10000
+
 type blockchain interface {
 	GetCurrentState() (*state.Statedb, error)
 	GetStore() store.BlockchainStore
@@ -49,6 +53,12 @@ type poolItem struct {
 	timestamp time.Time
 }
 
+// droppedObject is an object removed from the pool without being packed
+type droppedObject struct {
+	object poolObject
+	reason string
+}
+
 func newPooledItem(object poolObject) *poolItem {
 	return &poolItem{
 		poolObject: object,
@@ -56,8 +66,14 @@ func newPooledItem(object poolObject) *poolItem {
 	}
 }
 
+// accountNonce is the account and nonce of an object
+type accountNonce struct {
+	account common.Address
+	nonce   uint64
+}
+
 type getObjectFromBlockFunc func(block *types.Block) []poolObject
-type canRemoveFunc func(chain blockchain, state *state.Statedb, item *poolItem) (bool, bool)
+type canRemoveFunc func(chain blockchain, state *state.Statedb, item *poolItem) (bool, bool, string)
 type objectValidationFunc func(state *state.Statedb, obj poolObject) error
 type afterAddFunc func(obj poolObject)
 
@@ -69,13 +85,17 @@ type Pool struct {
 	chain              blockchain
 	hashToTxMap        map[common.Hash]*poolItem
 	pendingQueue       *pendingQueue
-	processingObjects  map[common.Hash]struct{}
+	processingObjects  map[common.Hash]accountNonce
+	processingNonces   map[accountNonce]common.Hash // index of the processing objects by account and nonce
 	log                *log.ScdoLog
 	getObjectFromBlock getObjectFromBlockFunc
 	canRemove          canRemoveFunc
 	objectValidation   objectValidationFunc
 	afterAdd           afterAddFunc
 	cachedTxs          *CachedTxs
+	dropped            *lru.Cache // recently dropped objects, hash -> *droppedObject
+	policies           *admissionPolicies
+	future             *futureQueue // objects with nonce gaps, nil if the nonce gaps are not queued
 }
 
 // NewPool creates and returns a transaction pool.
@@ -86,13 +106,16 @@ func NewPool(capacity int, chain blockchain, getObjectFromBlock getObjectFromBlo
 		chain:              chain,
 		hashToTxMap:        make(map[common.Hash]*poolItem),
 		pendingQueue:       newPendingQueue(),
-		processingObjects:  make(map[common.Hash]struct{}),
+		processingObjects:  make(map[common.Hash]accountNonce),
+		processingNonces:   make(map[accountNonce]common.Hash),
 		log:                log,
 		getObjectFromBlock: getObjectFromBlock,
 		canRemove:          canRemove,
 		objectValidation:   objectValidation,
 		afterAdd:           afterAdd,
 		cachedTxs:          cachedTxs,
+		dropped:            common.MustNewCache(droppedObjectsCapacity),
+		policies:           newAdmissionPolicies(),
 	}
 
 	go pool.loopCheckingPool()
@@ -100,11 +123,29 @@ func NewPool(capacity int, chain blockchain, getObjectFromBlock getObjectFromBlo
 	return pool
 }
 
+// enableFutureQueue queues the objects with nonce gaps in the future queue of the capacity,
+// 0 means the default capacity, instead of adding them into the pending queue.
+func (pool *Pool) enableFutureQueue(capacity int) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	pool.future = newFutureQueue(capacity)
+}
+
 // SetLogLevel sets the log level
 func (pool *Pool) SetLogLevel(level logrus.Level) {
 	pool.log.SetLevel(level)
 }
 
+// SetCapacity sets the max number of objects in the pool.
+// The objects exceeding the new capacity are not evicted, but no more objects are added until the pool is not full.
+func (pool *Pool) SetCapacity(capacity int) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	pool.capacity = capacity
+}
+
 // check the pool frequently, remove finalized and old txs, reinject the txs not on the chain yet
 func (pool *Pool) loopCheckingPool() {
 	for {
@@ -256,7 +297,7 @@ func (pool *Pool) Has(hash common.Hash) bool {
 	pool.mutex.RLock()
 	defer pool.mutex.RUnlock()
 
-	return pool.hashToTxMap[hash] != nil
+	return pool.hashToTxMap[hash] != nil || (pool.future != nil && pool.future.get(hash) != nil)
 }
 
 // addObject adds a single transaction into the pool if it is valid and returns nil.
@@ -266,6 +307,11 @@ func (pool *Pool) addObject(obj poolObject) error {
 		return errObjectHashExists
 	}
 
+	// check the local admission policies before the validation
+	if err := pool.policies.admit(obj); err != nil {
+		return err
+	}
+
 	// validate tx against the latest statedb
 	statedb, err := pool.chain.GetCurrentState()
 	if err != nil {
@@ -280,43 +326,197 @@ func (pool *Pool) addObject(obj poolObject) error {
 	pool.mutex.Lock()
 	defer pool.mutex.Unlock()
 
+	if pool.isFutureObject(statedb, obj) {
+		return pool.addFutureObject(obj)
+	}
+
 	// update obj with higher price, otherwise return errObjectNonceUsed
 	if existTx := pool.pendingQueue.get(obj.FromAccount(), obj.Nonce()); existTx != nil {
 		if obj.Price().Cmp(existTx.Price()) > 0 {
 			pool.log.Debug("got a object has higher gas price than before. remove old one. new: %s, old: %s",
 				obj.GetHash().Hex(), existTx.GetHash().Hex())
 			pool.doRemoveObject(existTx.GetHash())
+			pool.markDropped(existTx.poolObject, "replaced by "+obj.GetHash().Hex()+" with higher gas price")
 		} else {
 			return errObjectNonceUsed
 		}
 	}
 
-	// if txpool capacity reached, then discard lower price txs if any.
-	// Otherwise, return errObjectPoolFull.
-	if len(pool.hashToTxMap) >= pool.capacity {
-		c := pool.pendingQueue.discard(obj.Price())
-		if c == nil || c.len() == 0 {
-			return errObjectPoolFull
-		}
-
-		discardedAccount := c.peek().FromAccount()
-		pool.log.Info("object pool is full, discarded account = %v, object len = %v", discardedAccount.Hex(), c.len())
-
-		for c.len() > 0 {
-			delete(pool.hashToTxMap, c.pop().GetHash())
-		}
+	if err = pool.makeRoom(obj); err != nil {
+		return err
 	}
 
 	pool.doAddObject(obj)
 	pool.afterAdd(obj)
+	pool.promoteFutureObjects(statedb, obj.FromAccount(), obj.Nonce()+1)
+
+	return nil
+}
+
+// isFull returns whether the pool reaches its capacity
+func (pool *Pool) isFull() bool {
+	return len(pool.hashToTxMap) >= pool.capacity
+}
+
+// makeRoom discards the pending objects of the worst account with lower price than the object if the pool
+// is full, and returns errObjectPoolFull if no object could be discarded.
+func (pool *Pool) makeRoom(obj poolObject) error {
+	if !pool.isFull() {
+		return nil
+	}
+
+	c := pool.pendingQueue.discard(obj.Price())
+	if c == nil || c.len() == 0 {
+		return errObjectPoolFull
+	}
+
+	discardedAccount := c.peek().FromAccount()
+	pool.log.Info("object pool is full, discarded account = %v, object len = %v", discardedAccount.Hex(), c.len())
+
+	for c.len() > 0 {
+		item := c.pop()
+		delete(pool.hashToTxMap, item.GetHash())
+		pool.markDropped(item.poolObject, "discarded with lower gas price as pool is full")
+	}
 
 	return nil
 }
 
 func (pool *Pool) doAddObject(obj poolObject) {
-	poolTx := newPooledItem(obj)
-	pool.hashToTxMap[obj.GetHash()] = poolTx
-	pool.pendingQueue.add(poolTx)
+	pool.doAddItem(newPooledItem(obj))
+}
+
+func (pool *Pool) doAddItem(item *poolItem) {
+	pool.hashToTxMap[item.GetHash()] = item
+	pool.pendingQueue.add(item)
+	pool.dropped.Remove(item.GetHash())
+}
+
+// isFutureObject returns whether the object should be queued in the future queue, that is, its nonce is
+// larger than the account nonce, and the object of the previous nonce is neither pending nor processing.
+func (pool *Pool) isFutureObject(statedb *state.Statedb, obj poolObject) bool {
+	if pool.future == nil || obj.Nonce() <= statedb.GetNonce(obj.FromAccount()) {
+		return false
+	}
+
+	return !pool.hasNonce(obj.FromAccount(), obj.Nonce()-1)
+}
+
+// hasNonce returns whether the object of the account and nonce is pending or processing
+func (pool *Pool) hasNonce(account common.Address, nonce uint64) bool {
+	if pool.pendingQueue.get(account, nonce) != nil {
+		return true
+	}
+
+	_, ok := pool.processingNonces[accountNonce{account, nonce}]
+	return ok
+}
+
+// setProcessing marks the object as processing
+func (pool *Pool) setProcessing(obj poolObject) {
+	key := accountNonce{obj.FromAccount(), obj.Nonce()}
+	pool.processingObjects[obj.GetHash()] = key
+	pool.processingNonces[key] = obj.GetHash()
+}
+
+// unsetProcessing unmarks the processing object, and returns false if not processing
+func (pool *Pool) unsetProcessing(objHash common.Hash) bool {
+	key, ok := pool.processingObjects[objHash]
+	if !ok {
+		return false
+	}
+
+	delete(pool.processingObjects, objHash)
+	if pool.processingNonces[key] == objHash {
+		delete(pool.processingNonces, key)
+	}
+
+	return true
+}
+
+// addFutureObject adds the object with nonce gap into the future queue
+func (pool *Pool) addFutureObject(obj poolObject) error {
+	// the queued objects could not be promoted anyway if the pool is full
+	if pool.isFull() {
+		return errObjectPoolFull
+	}
+
+	replaced, err := pool.future.add(newPooledItem(obj))
+	if err != nil {
+		return err
+	}
+
+	if replaced != nil {
+		pool.markDropped(replaced.poolObject, "replaced by "+obj.GetHash().Hex()+" with higher gas price")
+	}
+
+	pool.dropped.Remove(obj.GetHash())
+	pool.log.Debug("queue object %s with nonce gap, account %s, nonce %d", obj.GetHash().Hex(), obj.FromAccount().Hex(), obj.Nonce())
+
+	return nil
+}
+
+// promoteFutureObjects moves the future objects of the account with the contiguous nonces from
+// the given nonce to the pending queue. Each object is validated against the statedb again, and
+// the promotion stops at an invalid object, which is dropped, or if the pool is full.
+func (pool *Pool) promoteFutureObjects(statedb *state.Statedb, account common.Address, nonce uint64) {
+	if pool.future == nil {
+		return
+	}
+
+	for item := pool.future.lowest(account); item != nil && item.Nonce() == nonce; item = pool.future.lowest(account) {
+		if err := pool.objectValidation(statedb, item.poolObject); err != nil {
+			pool.future.remove(item.GetHash())
+			pool.markDropped(item.poolObject, "failed to validate when the nonce gap is filled, "+err.Error())
+			return
+		}
+
+		// the pending objects of the account are not discarded for its own future object,
+		// which would leave a nonce gap before the promoted object
+		worst := pool.pendingQueue.worst()
+		if pool.isFull() && worst != nil && worst.FromAccount() == account || pool.makeRoom(item.poolObject) != nil {
+			pool.log.Debug("object pool is full, keep object %s in the future queue", item.GetHash().Hex())
+			return
+		}
+
+		pool.future.remove(item.GetHash())
+		pool.log.Debug("promote object %s as the nonce gap is filled, account %s, nonce %d", item.GetHash().Hex(),
+			account.Hex(), item.Nonce())
+		pool.doAddItem(item)
+		pool.afterAdd(item.poolObject)
+		nonce++
+	}
+}
+
+// checkFutureObjects removes the future objects which are packed, timeout or of the used nonces, and promotes
+// the future objects whose nonce gaps are filled, e.g. the previous objects are packed in the new blocks.
+func (pool *Pool) checkFutureObjects(statedb *state.Statedb) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if pool.future == nil {
+		return
+	}
+
+	for _, item := range pool.future.items() {
+		if remove, _, reason := pool.canRemove(pool.chain, statedb, item); remove {
+			pool.future.remove(item.GetHash())
+			if len(reason) > 0 {
+				pool.markDropped(item.poolObject, reason)
+			}
+		}
+	}
+
+	for account := range pool.future.accounts {
+		lowest := pool.future.lowest(account)
+		if lowest == nil {
+			continue
+		}
+
+		if lowest.Nonce() == statedb.GetNonce(account) || pool.hasNonce(account, lowest.Nonce()-1) {
+			pool.promoteFutureObjects(statedb, account, lowest.Nonce())
+		}
+	}
 }
 
 // GetObject returns a transaction if it is contained in the pool and nil otherwise.
@@ -328,9 +528,47 @@ func (pool *Pool) GetObject(objHash common.Hash) poolObject {
 		return pooledTx.poolObject
 	}
 
+	if pool.future != nil {
+		if item := pool.future.get(objHash); item != nil {
+			return item.poolObject
+		}
+	}
+
 	return nil
 }
 
+// isFutureObjectHash returns whether the object of the hash is in the future queue
+func (pool *Pool) isFutureObjectHash(objHash common.Hash) bool {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	return pool.future != nil && pool.future.get(objHash) != nil
+}
+
+// getFutureObjects returns the objects in the future queue sorted by account and nonce
+func (pool *Pool) getFutureObjects() []poolObject {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	if pool.future == nil {
+		return nil
+	}
+
+	return pool.future.list()
+}
+
+// getObjectTime returns the time when the object is added into the pool
+func (pool *Pool) getObjectTime(objHash common.Hash) (time.Time, bool) {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	if pooledTx, ok := pool.hashToTxMap[objHash]; ok {
+		return pooledTx.timestamp, true
+	}
+
+	return time.Time{}, false
+}
+
 // removeOject removes tx of specified tx hash from pool
 func (pool *Pool) removeOject(objHash common.Hash) {
 	defer pool.mutex.Unlock()
@@ -342,8 +580,10 @@ func (pool *Pool) removeOject(objHash common.Hash) {
 func (pool *Pool) doRemoveObject(objHash common.Hash) {
 	if tx := pool.hashToTxMap[objHash]; tx != nil {
 		pool.pendingQueue.remove(tx.FromAccount(), tx.Nonce())
-		delete(pool.processingObjects, objHash)
+		pool.unsetProcessing(objHash)
 		delete(pool.hashToTxMap, objHash)
+	} else if pool.future != nil {
+		pool.future.remove(objHash)
 	}
 }
 
@@ -357,14 +597,35 @@ func (pool *Pool) removeObjects() {
 
 	objMap := pool.getObjectMap()
 	for objHash, poolTx := range objMap {
-		objectRemove, cachedTxsRemove := pool.canRemove(pool.chain, state, poolTx)
+		objectRemove, cachedTxsRemove, dropReason := pool.canRemove(pool.chain, state, poolTx)
 		if objectRemove {
 			if cachedTxsRemove {
 				pool.cachedTxs.remove(objHash)
 			}
 			pool.removeOject(objHash)
+
+			if len(dropReason) > 0 {
+				pool.markDropped(poolTx.poolObject, dropReason)
+			}
 		}
 	}
+
+	pool.checkFutureObjects(state)
+}
+
+// markDropped records the object removed from the pool without being packed
+func (pool *Pool) markDropped(obj poolObject, reason string) {
+	pool.dropped.Add(obj.GetHash(), &droppedObject{obj, reason})
+}
+
+// getDroppedObject returns the recently dropped object and the reason, or nil if not dropped
+func (pool *Pool) getDroppedObject(objHash common.Hash) (poolObject, string) {
+	if value, ok := pool.dropped.Get(objHash); ok {
+		dropped := value.(*droppedObject)
+		return dropped.object, dropped.reason
+	}
+
+	return nil, ""
 }
 
 // getObjectMap returns the hash-to-tx map
@@ -388,9 +649,8 @@ func (pool *Pool) getProcessableObjects(size int) ([]poolObject, int) {
 	totalSize := 0
 	var txs []poolObject
 
-	for objHash, _ := range pool.processingObjects {
-		delete(pool.processingObjects, objHash)
-	}
+	pool.processingObjects = make(map[common.Hash]accountNonce)
+	pool.processingNonces = make(map[accountNonce]common.Hash)
 
 	for !pool.pendingQueue.empty() {
 		tx := pool.pendingQueue.peek().peek().poolObject
@@ -402,12 +662,30 @@ func (pool *Pool) getProcessableObjects(size int) ([]poolObject, int) {
 		tx = pool.pendingQueue.pop()
 		totalSize = tmpSize
 		txs = append(txs, tx)
-		pool.processingObjects[tx.GetHash()] = struct{}{}
+		pool.setProcessing(tx)
 	}
 
 	return txs, totalSize
 }
 
+// returnObjects returns the processing objects to the pending queue, the objects removed
+// from the pool or not processing are skipped.
+func (pool *Pool) returnObjects(objects []poolObject) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	for _, obj := range objects {
+		hash := obj.GetHash()
+		if !pool.unsetProcessing(hash) {
+			continue
+		}
+
+		if item := pool.hashToTxMap[hash]; item != nil {
+			pool.pendingQueue.add(item)
+		}
+	}
+}
+
 // getObjectCount return the total number of transactions in the transaction pool.
 func (pool *Pool) getObjectCount(processing, pending bool) int {
 	pool.mutex.RLock()
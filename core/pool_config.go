@@ -5,9 +5,21 @@
 
 package core
 
+import (
+	"math/big"
+	"time"
+)
+
 // TransactionPoolConfig is the configuration of the transaction pool.
 type TransactionPoolConfig struct {
-	Capacity int // Maximum number of transactions in the pool.
+	Capacity int `json:"capacity"` // Maximum number of transactions in the pool.
+
+	// FutureCapacity is the max number of the transactions with nonce gaps queued until the gaps are filled,
+	// 0 means the default. The queued transactions are not counted in the capacity.
+	FutureCapacity int `json:"futureCapacity"`
+
+	// Policies are the local admission rules checked before the transactions are added.
+	Policies []AdmissionRuleConfig `json:"policies,omitempty"`
 }
 
 // DefaultTxPoolConfig returns the default configuration of the transaction pool.
@@ -21,6 +33,28 @@ func DefaultTxPoolConfig() *TransactionPoolConfig {
 	}
 }
 
+// DebtPoolConfig is the configuration of the debt pool.
+type DebtPoolConfig struct {
+	// MinPrice is the minimum price of the debts accepted by the pool, nil or 0 means no limit.
+	MinPrice *big.Int `json:"minPrice"`
+
+	// Timeout is the time in seconds after which the debts not packed are expired, 0 means the default 3 hours
+	Timeout int64 `json:"timeout"`
+
+	// MaxRequeues is the max times an expired debt is requeued if its tx is still confirmed in the source shard,
+	// 0 means the default 3, and negative disables the requeue. The debts not requeued are kept as dead letters.
+	MaxRequeues int `json:"maxRequeues"`
+}
+
+// DefaultDebtPoolConfig returns the default configuration of the debt pool.
+func DefaultDebtPoolConfig() *DebtPoolConfig {
+	return &DebtPoolConfig{
+		MinPrice:    big.NewInt(0),
+		Timeout:     int64(defaultDebtTimeout / time.Second),
+		MaxRequeues: defaultDebtMaxRequeues,
+	}
+}
+
 // DebtPoolCapacity we need bigger capacity to hold more debt
 // in real test. the memory usage for 100000 will be about 150MB
 var DebtPoolCapacity = 100000
@@ -0,0 +1,136 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"sort"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+)
+
+const (
+	// defaultFutureCapacity is the default max number of the objects in the future queue
+	defaultFutureCapacity = 4096
+
+	// maxFutureObjectsPerAccount is the max number of the objects of an account in the future queue
+	maxFutureObjectsPerAccount = 64
+)
+
+var (
+	errFutureQueueFull        = errors.New("future queue is full")
+	errFutureQueueAccountFull = errors.New("too many objects of the account in the future queue")
+)
+
+// futureQueue holds the objects whose nonces are not contiguous to the pending objects of the accounts,
+// e.g. the object of nonce 5 arrives before the one of nonce 4. They are not processable, and are
+// promoted to the pending queue once the nonce gaps are filled.
+type futureQueue struct {
+	capacity int
+	objects  map[common.Hash]*poolItem
+	accounts map[common.Address]*txCollection
+}
+
+func newFutureQueue(capacity int) *futureQueue {
+	if capacity <= 0 {
+		capacity = defaultFutureCapacity
+	}
+
+	return &futureQueue{
+		capacity: capacity,
+		objects:  make(map[common.Hash]*poolItem),
+		accounts: make(map[common.Address]*txCollection),
+	}
+}
+
+// add adds the item into the queue, and returns the item of the same nonce replaced by the higher price.
+func (q *futureQueue) add(item *poolItem) (*poolItem, error) {
+	collection := q.accounts[item.FromAccount()]
+	if collection == nil {
+		collection = newTxCollection()
+	}
+
+	existing := collection.get(item.Nonce())
+	if existing != nil {
+		if item.Price().Cmp(existing.Price()) <= 0 {
+			return nil, errObjectNonceUsed
+		}
+
+		delete(q.objects, existing.GetHash())
+		collection.remove(existing.Nonce())
+	} else if collection.len() >= maxFutureObjectsPerAccount {
+		return nil, errFutureQueueAccountFull
+	} else if len(q.objects) >= q.capacity {
+		return nil, errFutureQueueFull
+	}
+
+	collection.add(item)
+	q.accounts[item.FromAccount()] = collection
+	q.objects[item.GetHash()] = item
+
+	return existing, nil
+}
+
+// get returns the item of the hash, or nil if not found
+func (q *futureQueue) get(hash common.Hash) *poolItem {
+	return q.objects[hash]
+}
+
+// remove removes the item of the hash, and returns false if not found
+func (q *futureQueue) remove(hash common.Hash) bool {
+	item := q.objects[hash]
+	if item == nil {
+		return false
+	}
+
+	delete(q.objects, hash)
+	if collection := q.accounts[item.FromAccount()]; collection != nil {
+		collection.remove(item.Nonce())
+		if collection.len() == 0 {
+			delete(q.accounts, item.FromAccount())
+		}
+	}
+
+	return true
+}
+
+// lowest returns the item of the lowest nonce of the account, or nil if the account has no item
+func (q *futureQueue) lowest(account common.Address) *poolItem {
+	if collection := q.accounts[account]; collection != nil {
+		return collection.peek()
+	}
+
+	return nil
+}
+
+// items returns all the items in the queue
+func (q *futureQueue) items() []*poolItem {
+	items := make([]*poolItem, 0, len(q.objects))
+	for _, item := range q.objects {
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// list returns the objects in the queue sorted by account and nonce
+func (q *futureQueue) list() []poolObject {
+	accounts := make([]common.Address, 0, len(q.accounts))
+	for account := range q.accounts {
+		accounts = append(accounts, account)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Hex() < accounts[j].Hex()
+	})
+
+	objects := make([]poolObject, 0, len(q.objects))
+	for _, account := range accounts {
+		objects = append(objects, q.accounts[account].list()...)
+	}
+
+	return objects
+}
@@ -0,0 +1,174 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FutureQueue(t *testing.T) {
+	q := newFutureQueue(0)
+	assert.Equal(t, q.capacity, defaultFutureCapacity)
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	item5 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 5, 1)
+	item6 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 6, 1)
+	item8 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 8, 1)
+
+	for _, item := range []*poolItem{item8, item6, item5} {
+		replaced, err := q.add(item)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, replaced == nil, true)
+	}
+
+	assert.Equal(t, q.lowest(fromAddress), item5)
+	assert.Equal(t, len(q.list()), 3)
+
+	// replaced only by the higher price
+	_, err := q.add(newTestPoolEx(t, fromPrivKey, fromAddress, 10, 6, 1))
+	assert.Equal(t, err, errObjectNonceUsed)
+
+	item6Higher := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 6, 2)
+	replaced, err := q.add(item6Higher)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, replaced, item6)
+	assert.Equal(t, q.get(item6.GetHash()) == nil, true)
+
+	assert.Equal(t, q.lowest(fromAddress), item5)
+	assert.Equal(t, q.remove(item5.GetHash()), true)
+	assert.Equal(t, q.lowest(fromAddress), item6Higher)
+	assert.Equal(t, q.remove(item6Higher.GetHash()), true)
+	assert.Equal(t, q.lowest(fromAddress), item8)
+
+	assert.Equal(t, q.remove(item8.GetHash()), true)
+	assert.Equal(t, q.remove(item8.GetHash()), false)
+	assert.Equal(t, len(q.accounts), 0)
+	assert.Equal(t, len(q.objects), 0)
+}
+
+func Test_FutureQueue_Full(t *testing.T) {
+	q := newFutureQueue(1)
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	_, err := q.add(newTestPoolEx(t, fromPrivKey, fromAddress, 10, 5, 1))
+	assert.Equal(t, err, nil)
+
+	_, err = q.add(newTestPoolEx(t, fromPrivKey, fromAddress, 10, 6, 1))
+	assert.Equal(t, err, errFutureQueueFull)
+
+	q = newFutureQueue(maxFutureObjectsPerAccount + 1)
+	for i := 0; i < maxFutureObjectsPerAccount; i++ {
+		_, err = q.add(newTestPoolEx(t, fromPrivKey, fromAddress, 10, uint64(i), 1))
+		assert.Equal(t, err, nil)
+	}
+
+	_, err = q.add(newTestPoolEx(t, fromPrivKey, fromAddress, 10, maxFutureObjectsPerAccount, 1))
+	assert.Equal(t, err, errFutureQueueAccountFull)
+}
+
+func Test_TransactionPool_FutureNonce(t *testing.T) {
+	pool, chain := newTestTransactionPool(DefaultTxPoolConfig())
+	defer chain.dispose()
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 1000000, 10)
+
+	// queued with the nonce gap
+	poolTx12 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 12, 1)
+	assert.Equal(t, pool.addObject(poolTx12.poolObject), nil)
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx12.GetHash()), true)
+	assert.Equal(t, len(pool.GetQueuedTransactions()), 1)
+	assert.Equal(t, pool.GetPendingTxCount(), 0)
+	assert.Equal(t, pool.Has(poolTx12.GetHash()), true)
+
+	// still queued as the nonce 11 is missing
+	poolTx10 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 10, 1)
+	assert.Equal(t, pool.addObject(poolTx10.poolObject), nil)
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx12.GetHash()), true)
+	assert.Equal(t, pool.GetPendingTxCount(), 1)
+
+	// promoted once the gap is filled
+	poolTx11 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 11, 1)
+	assert.Equal(t, pool.addObject(poolTx11.poolObject), nil)
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx12.GetHash()), false)
+	assert.Equal(t, len(pool.GetQueuedTransactions()), 0)
+	assert.Equal(t, pool.GetPendingTxCount(), 3)
+}
+
+func Test_TransactionPool_FutureNonce_PromotedByState(t *testing.T) {
+	pool, chain := newTestTransactionPool(DefaultTxPoolConfig())
+	defer chain.dispose()
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 1000000, 10)
+
+	poolTx := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 11, 1)
+	assert.Equal(t, pool.addObject(poolTx.poolObject), nil)
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx.GetHash()), true)
+
+	// the nonce 10 is packed in a block by another node
+	chain.statedb.SetNonce(fromAddress, 11)
+	pool.removeObjects()
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx.GetHash()), false)
+	assert.Equal(t, pool.GetPendingTxCount(), 1)
+}
+
+func Test_TransactionPool_FutureNonce_PoolFull(t *testing.T) {
+	config := DefaultTxPoolConfig()
+	config.Capacity = 3
+	pool, chain := newTestTransactionPool(config)
+	defer chain.dispose()
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 1000000, 10)
+	otherPrivKey, otherAddress := randomAccount(t)
+	chain.addAccount(otherAddress, 1000000, 0)
+
+	poolTx10 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 10, 5)
+	assert.Equal(t, pool.addObject(poolTx10.poolObject), nil)
+	poolTx12 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 12, 5)
+	assert.Equal(t, pool.addObject(poolTx12.poolObject), nil)
+	otherTx := newTestPoolEx(t, otherPrivKey, otherAddress, 10, 0, 5)
+	assert.Equal(t, pool.addObject(otherTx.poolObject), nil)
+
+	// the pool is full after the gap is filled, and no object with lower price to discard
+	poolTx11 := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 11, 5)
+	assert.Equal(t, pool.addObject(poolTx11.poolObject), nil)
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx12.GetHash()), true)
+	assert.Equal(t, pool.GetPendingTxCount(), 3)
+
+	// promoted once the pool has room
+	pool.removeOject(otherTx.GetHash())
+	pool.removeObjects()
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx12.GetHash()), false)
+	assert.Equal(t, pool.GetPendingTxCount(), 3)
+}
+
+func Test_TransactionPool_FutureNonce_InsufficientBalance(t *testing.T) {
+	pool, chain := newTestTransactionPool(DefaultTxPoolConfig())
+	defer chain.dispose()
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 1000000, 10)
+
+	poolTx := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 11, 1)
+	assert.Equal(t, pool.addObject(poolTx.poolObject), nil)
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx.GetHash()), true)
+
+	// the balance is spent by the nonce 10 packed in a block by another node
+	chain.statedb.SetNonce(fromAddress, 11)
+	chain.statedb.SetBalance(fromAddress, big.NewInt(0))
+	pool.removeObjects()
+	assert.Equal(t, pool.IsQueuedTransaction(poolTx.GetHash()), false)
+	assert.Equal(t, pool.GetPendingTxCount(), 0)
+
+	dropped, reason := pool.getDroppedObject(poolTx.GetHash())
+	assert.Equal(t, dropped != nil, true)
+	assert.Equal(t, len(reason) > 0, true)
+}
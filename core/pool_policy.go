@@ -0,0 +1,264 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"plugin"
+	"sort"
+	"sync"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// types of the config-driven admission rules
+const (
+	RuleBlockAddresses = "blockAddresses" // reject the txs to the addresses
+	RuleMaxPayloadSize = "maxPayloadSize" // reject the txs with larger payload
+	RuleMinGasPrice    = "minGasPrice"    // reject the txs of the senders with lower gas price
+	RulePlugin         = "plugin"         // the policy loaded from a Go plugin
+)
+
+// PolicyPluginSymbol is the symbol looked up in the policy plugin, which is a func() (core.AdmissionPolicy, error)
+const PolicyPluginSymbol = "NewAdmissionPolicy"
+
+// ErrTxRejectedByPolicy is returned when a tx is rejected by a local admission policy
+var ErrTxRejectedByPolicy = errors.New("tx rejected by local admission policy")
+
+// AdmissionPolicy is a local rule to admit the txs into the pool, which is checked before the tx is validated and added.
+type AdmissionPolicy interface {
+	// Name returns the unique name of the policy, which is used in the rejection counters.
+	Name() string
+
+	// Admit returns an error if the tx is rejected.
+	Admit(tx *types.Transaction) error
+}
+
+// AdmissionRuleConfig is the configuration of an admission rule of the transaction pool.
+type AdmissionRuleConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Addresses are the blocked receivers of blockAddresses, or the sender class of minGasPrice, empty for all senders.
+	Addresses      []common.Address `json:"addresses,omitempty"`
+	MaxPayloadSize int              `json:"maxPayloadSize,omitempty"`
+	MinGasPrice    *big.Int         `json:"minGasPrice,omitempty"`
+	Plugin         string           `json:"plugin,omitempty"` // path of the Go plugin
+}
+
+// NewAdmissionPolicies creates the admission policies of the rule configs.
+func NewAdmissionPolicies(configs []AdmissionRuleConfig) ([]AdmissionPolicy, error) {
+	var policies []AdmissionPolicy
+	names := make(map[string]bool)
+	for _, conf := range configs {
+		policy, err := newAdmissionPolicy(conf)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "invalid admission rule %q", conf.Name)
+		}
+
+		if names[policy.Name()] {
+			return nil, fmt.Errorf("duplicated admission rule %q", policy.Name())
+		}
+
+		names[policy.Name()] = true
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+func newAdmissionPolicy(conf AdmissionRuleConfig) (AdmissionPolicy, error) {
+	if conf.Type == RulePlugin {
+		return loadPolicyPlugin(conf.Plugin)
+	}
+
+	if len(conf.Name) == 0 {
+		return nil, errors.New("empty rule name")
+	}
+
+	switch conf.Type {
+	case RuleBlockAddresses:
+		if len(conf.Addresses) == 0 {
+			return nil, errors.New("no blocked addresses")
+		}
+
+		return &blockAddressesPolicy{conf.Name, addressSet(conf.Addresses)}, nil
+	case RuleMaxPayloadSize:
+		if conf.MaxPayloadSize <= 0 {
+			return nil, errors.New("max payload size should be positive")
+		}
+
+		return &maxPayloadSizePolicy{conf.Name, conf.MaxPayloadSize}, nil
+	case RuleMinGasPrice:
+		if conf.MinGasPrice == nil || conf.MinGasPrice.Sign() <= 0 {
+			return nil, errors.New("min gas price should be positive")
+		}
+
+		return &minGasPricePolicy{conf.Name, addressSet(conf.Addresses), conf.MinGasPrice}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule type %q", conf.Type)
+	}
+}
+
+// loadPolicyPlugin opens the Go plugin and creates the policy by the PolicyPluginSymbol function.
+func loadPolicyPlugin(path string) (AdmissionPolicy, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to open policy plugin %s", path)
+	}
+
+	symbol, err := p.Lookup(PolicyPluginSymbol)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to lookup %s in policy plugin %s", PolicyPluginSymbol, path)
+	}
+
+	newPolicy, ok := symbol.(func() (AdmissionPolicy, error))
+	if !ok {
+		return nil, fmt.Errorf("invalid %s in policy plugin %s, type %T", PolicyPluginSymbol, path, symbol)
+	}
+
+	return newPolicy()
+}
+
+func addressSet(addresses []common.Address) map[common.Address]bool {
+	set := make(map[common.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		set[addr] = true
+	}
+
+	return set
+}
+
+type blockAddressesPolicy struct {
+	name      string
+	addresses map[common.Address]bool
+}
+
+func (p *blockAddressesPolicy) Name() string { return p.name }
+
+func (p *blockAddressesPolicy) Admit(tx *types.Transaction) error {
+	if p.addresses[tx.Data.To] {
+		return fmt.Errorf("receiver %s is blocked", tx.Data.To.Hex())
+	}
+
+	return nil
+}
+
+type maxPayloadSizePolicy struct {
+	name string
+	size int
+}
+
+func (p *maxPayloadSizePolicy) Name() string { return p.name }
+
+func (p *maxPayloadSizePolicy) Admit(tx *types.Transaction) error {
+	if len(tx.Data.Payload) > p.size {
+		return fmt.Errorf("payload size %d exceeds %d", len(tx.Data.Payload), p.size)
+	}
+
+	return nil
+}
+
+type minGasPricePolicy struct {
+	name    string
+	senders map[common.Address]bool // empty for all senders
+	price   *big.Int
+}
+
+func (p *minGasPricePolicy) Name() string { return p.name }
+
+func (p *minGasPricePolicy) Admit(tx *types.Transaction) error {
+	if len(p.senders) > 0 && !p.senders[tx.Data.From] {
+		return nil
+	}
+
+	if tx.Data.GasPrice == nil || tx.Data.GasPrice.Cmp(p.price) < 0 {
+		return fmt.Errorf("gas price %v is lower than %v", tx.Data.GasPrice, p.price)
+	}
+
+	return nil
+}
+
+// admissionPolicies checks the objects against the policies and counts the rejections of each policy.
+type admissionPolicies struct {
+	lock       sync.RWMutex
+	policies   []AdmissionPolicy
+	rejections map[string]uint64 // policy name -> rejected count
+}
+
+func newAdmissionPolicies() *admissionPolicies {
+	return &admissionPolicies{
+		rejections: make(map[string]uint64),
+	}
+}
+
+// set replaces the policies, and the rejection counters of the removed policies are kept.
+func (p *admissionPolicies) set(policies []AdmissionPolicy) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.policies = policies
+	for _, policy := range policies {
+		if _, ok := p.rejections[policy.Name()]; !ok {
+			p.rejections[policy.Name()] = 0
+		}
+	}
+}
+
+// admit returns an error if the object is a tx rejected by any policy, the other objects are always admitted.
+func (p *admissionPolicies) admit(obj poolObject) error {
+	tx, ok := obj.(*types.Transaction)
+	if !ok {
+		return nil
+	}
+
+	p.lock.RLock()
+	policies := p.policies
+	p.lock.RUnlock()
+
+	for _, policy := range policies {
+		if err := policy.Admit(tx); err != nil {
+			p.lock.Lock()
+			p.rejections[policy.Name()]++
+			p.lock.Unlock()
+
+			return errors.NewStackedErrorf(ErrTxRejectedByPolicy, "policy:%s, %s", policy.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// names returns the sorted names of the current policies
+func (p *admissionPolicies) names() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	names := make([]string, 0, len(p.policies))
+	for _, policy := range p.policies {
+		names = append(names, policy.Name())
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// counters returns a copy of the rejection counters
+func (p *admissionPolicies) counters() map[string]uint64 {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	counters := make(map[string]uint64, len(p.rejections))
+	for name, count := range p.rejections {
+		counters[name] = count
+	}
+
+	return counters
+}
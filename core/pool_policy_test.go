@@ -0,0 +1,94 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPolicyTx(from, to common.Address, price int64, payload []byte) *types.Transaction {
+	return &types.Transaction{
+		Data: types.TransactionData{
+			From:     from,
+			To:       to,
+			GasPrice: big.NewInt(price),
+			Payload:  payload,
+		},
+	}
+}
+
+func Test_NewAdmissionPolicies(t *testing.T) {
+	addr := *crypto.MustGenerateRandomAddress()
+
+	policies, err := NewAdmissionPolicies(nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(policies), 0)
+
+	policies, err = NewAdmissionPolicies([]AdmissionRuleConfig{
+		{Name: "blocked", Type: RuleBlockAddresses, Addresses: []common.Address{addr}},
+		{Name: "payload", Type: RuleMaxPayloadSize, MaxPayloadSize: 10},
+		{Name: "price", Type: RuleMinGasPrice, MinGasPrice: big.NewInt(5)},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(policies), 3)
+
+	invalids := [][]AdmissionRuleConfig{
+		{{Name: "", Type: RuleMaxPayloadSize, MaxPayloadSize: 10}},
+		{{Name: "blocked", Type: RuleBlockAddresses}},
+		{{Name: "payload", Type: RuleMaxPayloadSize}},
+		{{Name: "price", Type: RuleMinGasPrice, MinGasPrice: big.NewInt(0)}},
+		{{Name: "unknown", Type: "unknown"}},
+		{{Name: "plugin", Type: RulePlugin, Plugin: "not_exist.so"}},
+		{{Name: "payload", Type: RuleMaxPayloadSize, MaxPayloadSize: 10}, {Name: "payload", Type: RuleMaxPayloadSize, MaxPayloadSize: 20}},
+	}
+
+	for _, configs := range invalids {
+		_, err = NewAdmissionPolicies(configs)
+		assert.Equal(t, err != nil, true)
+	}
+}
+
+func Test_AdmissionPolicies_Admit(t *testing.T) {
+	from, vip, to, blocked := *crypto.MustGenerateRandomAddress(), *crypto.MustGenerateRandomAddress(),
+		*crypto.MustGenerateRandomAddress(), *crypto.MustGenerateRandomAddress()
+
+	policies, err := NewAdmissionPolicies([]AdmissionRuleConfig{
+		{Name: "blocked", Type: RuleBlockAddresses, Addresses: []common.Address{blocked}},
+		{Name: "payload", Type: RuleMaxPayloadSize, MaxPayloadSize: 4},
+		{Name: "vipPrice", Type: RuleMinGasPrice, Addresses: []common.Address{vip}, MinGasPrice: big.NewInt(10)},
+	})
+	assert.Equal(t, err, nil)
+
+	p := newAdmissionPolicies()
+	p.set(policies)
+	assert.Equal(t, p.names(), []string{"blocked", "payload", "vipPrice"})
+
+	assert.Equal(t, p.admit(newTestPolicyTx(from, to, 1, []byte{1, 2, 3, 4})), nil)
+	assert.Equal(t, p.admit(newTestPolicyTx(vip, to, 10, nil)), nil)
+
+	err = p.admit(newTestPolicyTx(from, blocked, 1, nil))
+	assert.Equal(t, errors.IsOrContains(err, ErrTxRejectedByPolicy), true)
+	assert.Equal(t, p.admit(newTestPolicyTx(from, to, 1, []byte{1, 2, 3, 4, 5})) != nil, true)
+	assert.Equal(t, p.admit(newTestPolicyTx(vip, to, 9, nil)) != nil, true)
+	assert.Equal(t, p.admit(newTestPolicyTx(vip, to, 9, nil)) != nil, true)
+
+	// debts are always admitted
+	assert.Equal(t, p.admit(&types.Debt{}), nil)
+
+	assert.Equal(t, p.counters(), map[string]uint64{"blocked": 1, "payload": 1, "vipPrice": 2})
+
+	// the counters of the removed policies are kept
+	p.set(nil)
+	assert.Equal(t, p.admit(newTestPolicyTx(from, blocked, 1, nil)), nil)
+	assert.Equal(t, p.counters()["blocked"], uint64(1))
+}
@@ -154,19 +154,25 @@ func (q *pendingQueue) pop() poolObject {
 	return tx
 }
 
+// worst returns the tx of the lowest nonce of the worst account, or nil if the queue is empty
+func (q *pendingQueue) worst() *poolItem {
+	if q.empty() {
+		return nil
+	}
+
+	return q.worstHeap.Peek().(*heapedTxList).txCollection.peek()
+}
+
 // discard removes and returns the txs of worst account that has
 // lower price than the specified price. Return nil if no lower
 // price txs found.
 func (q *pendingQueue) discard(price *big.Int) *txCollection {
-	if q.empty() {
+	worstTx := q.worst()
+	if worstTx == nil || price.Cmp(worstTx.Price()) <= 0 {
 		return nil
 	}
 
 	worstCollection := q.worstHeap.Peek().(*heapedTxList).txCollection
-	worstTx := worstCollection.peek()
-	if worstTx == nil || price.Cmp(worstTx.Price()) <= 0 {
-		return nil
-	}
 
 	heap.Pop(q.worstHeap)
 	account := worstTx.FromAccount()
@@ -0,0 +1,331 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/metrics"
+)
+
+const (
+	seenTxsShardCount = 16
+
+	// SeenTxsMaxPeers is the max number of peers sharing the seen txs, the other peers keep their own known txs
+	SeenTxsMaxPeers = 256
+
+	// the seen txs only known by peers are pruned if not seen within seenTxsPeerEpochs epochs
+	seenTxsEpochDuration = time.Minute
+	seenTxsPeerEpochs    = 30
+)
+
+// peerBits is the bit set of peer slots
+type peerBits [SeenTxsMaxPeers / 64]uint64
+
+func (b *peerBits) set(slot int)      { b[slot/64] |= 1 << uint(slot%64) }
+func (b *peerBits) clear(slot int)    { b[slot/64] &^= 1 << uint(slot%64) }
+func (b *peerBits) has(slot int) bool { return b[slot/64]&(1<<uint(slot%64)) != 0 }
+
+func (b *peerBits) empty() bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+type seenTx struct {
+	epoch  uint32   // the last epoch the tx is seen
+	cached bool     // whether the tx is in the pool or recent blocks, see CachedTxs
+	peers  peerBits // the peers which know the tx
+}
+
+type seenTxsShard struct {
+	lock    sync.Mutex
+	entries map[common.Hash]seenTx
+}
+
+// SeenTxs is the memory bounded set of tx hashes shared by the tx pool and the protocol layer,
+// which records the txs in the pool or recent blocks and the peers knowing each tx, instead of
+// keeping a separate hash set for each of them. It is sharded by tx hash to reduce lock contention,
+// and the txs only known by peers are pruned by epoch.
+type SeenTxs struct {
+	shards        [seenTxsShardCount]*seenTxsShard
+	shardCapacity int
+
+	epoch      uint32 // current epoch, accessed atomically
+	epochStart int64  // start time in unix nano of the current epoch, accessed atomically
+	advancing  int32  // whether the epoch is advancing, accessed atomically
+
+	count  int64 // number of txs, accessed atomically
+	cached int64 // number of cached txs, accessed atomically
+
+	slotLock      sync.Mutex
+	freeSlots     []int
+	releasedSlots []int // released slots to clear from txs in the next epoch before reuse
+	usedSlots     int
+
+	reportMetrics bool
+}
+
+// NewSeenTxs creates a SeenTxs with the max number of txs
+func NewSeenTxs(capacity int) *SeenTxs {
+	s := &SeenTxs{
+		shardCapacity: capacity / seenTxsShardCount,
+		epochStart:    time.Now().UnixNano(),
+		freeSlots:     make([]int, 0, SeenTxsMaxPeers),
+	}
+
+	if s.shardCapacity <= 0 {
+		s.shardCapacity = 1
+	}
+
+	for i := range s.shards {
+		s.shards[i] = &seenTxsShard{entries: make(map[common.Hash]seenTx)}
+	}
+
+	for i := SeenTxsMaxPeers - 1; i >= 0; i-- {
+		s.freeSlots = append(s.freeSlots, i)
+	}
+
+	return s
+}
+
+func (s *SeenTxs) shard(hash common.Hash) *seenTxsShard {
+	return s.shards[hash[0]%seenTxsShardCount]
+}
+
+// Len returns the number of txs
+func (s *SeenTxs) Len() int {
+	return int(atomic.LoadInt64(&s.count))
+}
+
+// AcquirePeerSlot acquires a slot for the peer to record its known txs, returns false if no slot is free
+func (s *SeenTxs) AcquirePeerSlot() (int, bool) {
+	s.slotLock.Lock()
+	defer s.slotLock.Unlock()
+
+	if len(s.freeSlots) == 0 {
+		return 0, false
+	}
+
+	slot := s.freeSlots[len(s.freeSlots)-1]
+	s.freeSlots = s.freeSlots[:len(s.freeSlots)-1]
+	s.usedSlots++
+
+	return slot, true
+}
+
+// ReleasePeerSlot releases the slot of the disconnected peer, the slot is reused after cleared in the next epoch
+func (s *SeenTxs) ReleasePeerSlot(slot int) {
+	s.slotLock.Lock()
+	defer s.slotLock.Unlock()
+
+	s.releasedSlots = append(s.releasedSlots, slot)
+	s.usedSlots--
+}
+
+// MarkPeer marks the tx is known by the peer of the slot
+func (s *SeenTxs) MarkPeer(hash common.Hash, slot int) {
+	s.update(hash, false, func(tx *seenTx) { tx.peers.set(slot) })
+}
+
+// KnownByPeer returns whether the tx is known by the peer of the slot
+func (s *SeenTxs) KnownByPeer(hash common.Hash, slot int) bool {
+	tx, ok := s.get(hash)
+	return ok && tx.peers.has(slot)
+}
+
+// Cached returns whether the tx is in the pool or recent blocks
+func (s *SeenTxs) Cached(hash common.Hash) bool {
+	tx, ok := s.get(hash)
+	return ok && tx.cached
+}
+
+func (s *SeenTxs) markCached(hash common.Hash) {
+	s.update(hash, true, func(tx *seenTx) {
+		if !tx.cached {
+			tx.cached = true
+			atomic.AddInt64(&s.cached, 1)
+		}
+	})
+}
+
+func (s *SeenTxs) unmarkCached(hash common.Hash) {
+	shard := s.shard(hash)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	tx, ok := shard.entries[hash]
+	if !ok || !tx.cached {
+		return
+	}
+
+	atomic.AddInt64(&s.cached, -1)
+	if tx.peers.empty() {
+		delete(shard.entries, hash)
+		atomic.AddInt64(&s.count, -1)
+		return
+	}
+
+	tx.cached = false
+	shard.entries[hash] = tx
+}
+
+func (s *SeenTxs) cachedCount() int {
+	return int(atomic.LoadInt64(&s.cached))
+}
+
+func (s *SeenTxs) get(hash common.Hash) (seenTx, bool) {
+	shard := s.shard(hash)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	tx, ok := shard.entries[hash]
+	return tx, ok
+}
+
+// update updates the tx with the current epoch, and adds the tx if not exists. The tx is not added for
+// a peer mark if the shard is full of the cached txs.
+func (s *SeenTxs) update(hash common.Hash, cached bool, f func(tx *seenTx)) {
+	epoch := s.currentEpoch(time.Now())
+
+	shard := s.shard(hash)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	tx, ok := shard.entries[hash]
+	if !ok {
+		if len(shard.entries) >= s.shardCapacity && s.evict(shard, cached) == 0 {
+			return
+		}
+		atomic.AddInt64(&s.count, 1)
+	}
+
+	tx.epoch = epoch
+	f(&tx)
+	shard.entries[hash] = tx
+}
+
+// evict deletes 1/PercentDelete of the txs in the full shard, the txs only known by peers in the oldest
+// epoch first. The cached txs are only evicted to add a cached tx, so that the peer marks never evict
+// the txs in the pool or recent blocks. Returns the number of txs deleted.
+// Caller should hold the shard lock.
+func (s *SeenTxs) evict(shard *seenTxsShard, cached bool) int {
+	target := len(shard.entries) / PercentDelete
+	if target == 0 {
+		target = 1
+	}
+
+	// the oldest epoch of the txs only known by peers and the cached txs respectively
+	oldest := [2]uint32{^uint32(0), ^uint32(0)}
+	for _, tx := range shard.entries {
+		if kind := cachedKind(tx); tx.epoch < oldest[kind] {
+			oldest[kind] = tx.epoch
+		}
+	}
+
+	// delete the txs only known by peers first, and the cached txs only to add a cached tx,
+	// the ones in the oldest epoch first of each kind
+	passes := []func(tx seenTx) bool{
+		func(tx seenTx) bool { return !tx.cached && tx.epoch == oldest[0] },
+		func(tx seenTx) bool { return !tx.cached },
+	}
+
+	if cached {
+		passes = append(passes,
+			func(tx seenTx) bool { return tx.cached && tx.epoch == oldest[1] },
+			func(tx seenTx) bool { return true },
+		)
+	}
+
+	deleted := 0
+	for _, match := range passes {
+		for hash, tx := range shard.entries {
+			if deleted >= target {
+				return deleted
+			}
+
+			if match(tx) {
+				s.delete(shard, hash, tx)
+				deleted++
+			}
+		}
+	}
+
+	return deleted
+}
+
+func cachedKind(tx seenTx) int {
+	if tx.cached {
+		return 1
+	}
+
+	return 0
+}
+
+// delete deletes the tx, caller should hold the shard lock
+func (s *SeenTxs) delete(shard *seenTxsShard, hash common.Hash, tx seenTx) {
+	delete(shard.entries, hash)
+	atomic.AddInt64(&s.count, -1)
+	if tx.cached {
+		atomic.AddInt64(&s.cached, -1)
+	}
+}
+
+// currentEpoch returns the current epoch, and advances the epoch if the epoch duration elapsed
+func (s *SeenTxs) currentEpoch(now time.Time) uint32 {
+	if now.UnixNano()-atomic.LoadInt64(&s.epochStart) >= int64(seenTxsEpochDuration) &&
+		atomic.CompareAndSwapInt32(&s.advancing, 0, 1) {
+		s.advance(now)
+		atomic.StoreInt32(&s.advancing, 0)
+	}
+
+	return atomic.LoadUint32(&s.epoch)
+}
+
+// advance advances the epoch, prunes the txs only known by peers and not seen for seenTxsPeerEpochs,
+// and clears the released peer slots from the txs to reuse.
+func (s *SeenTxs) advance(now time.Time) {
+	epoch := atomic.AddUint32(&s.epoch, 1)
+	atomic.StoreInt64(&s.epochStart, now.UnixNano())
+
+	s.slotLock.Lock()
+	released := s.releasedSlots
+	s.releasedSlots = nil
+	s.slotLock.Unlock()
+
+	for _, shard := range s.shards {
+		shard.lock.Lock()
+		for hash, tx := range shard.entries {
+			for _, slot := range released {
+				tx.peers.clear(slot)
+			}
+
+			if !tx.cached && (tx.peers.empty() || tx.epoch+seenTxsPeerEpochs < epoch) {
+				s.delete(shard, hash, tx)
+			} else if len(released) > 0 {
+				shard.entries[hash] = tx
+			}
+		}
+		shard.lock.Unlock()
+	}
+
+	s.slotLock.Lock()
+	s.freeSlots = append(s.freeSlots, released...)
+	usedSlots := s.usedSlots
+	s.slotLock.Unlock()
+
+	if s.reportMetrics {
+		metrics.MetricsSeenTxsGauge.Update(int64(s.Len()))
+		metrics.MetricsSeenTxsPeersGauge.Update(int64(usedSlots))
+	}
+}
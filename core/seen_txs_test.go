@@ -0,0 +1,172 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SeenTxs_Cached(t *testing.T) {
+	s := NewSeenTxs(1000)
+	hash := common.StringToHash("tx")
+
+	assert.Equal(t, s.Cached(hash), false)
+
+	s.markCached(hash)
+	s.markCached(hash)
+	assert.Equal(t, s.Cached(hash), true)
+	assert.Equal(t, s.cachedCount(), 1)
+	assert.Equal(t, s.Len(), 1)
+
+	s.unmarkCached(hash)
+	assert.Equal(t, s.Cached(hash), false)
+	assert.Equal(t, s.cachedCount(), 0)
+	assert.Equal(t, s.Len(), 0)
+}
+
+func Test_SeenTxs_Peers(t *testing.T) {
+	s := NewSeenTxs(1000)
+	hash := common.StringToHash("tx")
+
+	slot1, ok := s.AcquirePeerSlot()
+	assert.Equal(t, ok, true)
+	slot2, ok := s.AcquirePeerSlot()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, slot1 != slot2, true)
+
+	s.markCached(hash)
+	s.MarkPeer(hash, slot1)
+	assert.Equal(t, s.KnownByPeer(hash, slot1), true)
+	assert.Equal(t, s.KnownByPeer(hash, slot2), false)
+	assert.Equal(t, s.Len(), 1)
+
+	// still known by peer after removed from pool
+	s.unmarkCached(hash)
+	assert.Equal(t, s.Cached(hash), false)
+	assert.Equal(t, s.KnownByPeer(hash, slot1), true)
+
+	// the released slot is cleared and reused after the next epoch
+	s.ReleasePeerSlot(slot1)
+	s.advance(time.Now())
+	assert.Equal(t, s.Len(), 0)
+
+	slot3, ok := s.AcquirePeerSlot()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, slot3, slot1)
+	assert.Equal(t, s.KnownByPeer(hash, slot3), false)
+}
+
+func Test_SeenTxs_PeerSlotsFull(t *testing.T) {
+	s := NewSeenTxs(1000)
+	for i := 0; i < SeenTxsMaxPeers; i++ {
+		_, ok := s.AcquirePeerSlot()
+		assert.Equal(t, ok, true)
+	}
+
+	_, ok := s.AcquirePeerSlot()
+	assert.Equal(t, ok, false)
+
+	// not reusable until the next epoch
+	s.ReleasePeerSlot(0)
+	_, ok = s.AcquirePeerSlot()
+	assert.Equal(t, ok, false)
+
+	s.advance(time.Now())
+	slot, ok := s.AcquirePeerSlot()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, slot, 0)
+}
+
+func Test_SeenTxs_PruneByEpoch(t *testing.T) {
+	s := NewSeenTxs(1000)
+	slot, _ := s.AcquirePeerSlot()
+
+	cached := common.StringToHash("cached")
+	known := common.StringToHash("known")
+	s.markCached(cached)
+	s.MarkPeer(known, slot)
+
+	for i := 0; i < seenTxsPeerEpochs; i++ {
+		s.advance(time.Now())
+	}
+	assert.Equal(t, s.KnownByPeer(known, slot), true)
+
+	// the txs only known by peers are pruned, but the cached txs are kept
+	s.advance(time.Now())
+	assert.Equal(t, s.KnownByPeer(known, slot), false)
+	assert.Equal(t, s.Cached(cached), true)
+	assert.Equal(t, s.Len(), 1)
+}
+
+func Test_SeenTxs_Evict(t *testing.T) {
+	s := NewSeenTxs(seenTxsShardCount * PercentDelete)
+
+	// all the txs fall in the same shard
+	var hashes []common.Hash
+	for i := 0; i < PercentDelete; i++ {
+		hash := common.BigToHash(common.Big1)
+		hash[1] = byte(i)
+		hashes = append(hashes, hash)
+	}
+
+	for _, hash := range hashes[:PercentDelete-1] {
+		s.markCached(hash)
+	}
+
+	// newer epoch for the last one
+	s.advance(time.Now())
+	s.markCached(hashes[PercentDelete-1])
+	assert.Equal(t, s.Len(), PercentDelete)
+
+	// the shard is full, the txs in the oldest epoch are evicted first
+	hash := common.BigToHash(common.Big1)
+	hash[1] = byte(PercentDelete)
+	s.markCached(hash)
+	assert.Equal(t, s.Len(), PercentDelete)
+	assert.Equal(t, s.Cached(hashes[PercentDelete-1]), true)
+	assert.Equal(t, s.Cached(hash), true)
+}
+
+func Test_SeenTxs_Evict_PeerMarks(t *testing.T) {
+	s := NewSeenTxs(seenTxsShardCount * PercentDelete)
+	slot, ok := s.AcquirePeerSlot()
+	assert.Equal(t, ok, true)
+
+	// all the txs fall in the same shard
+	newHash := func(i int) common.Hash {
+		hash := common.BigToHash(common.Big1)
+		hash[1] = byte(i)
+		return hash
+	}
+
+	for i := 0; i < PercentDelete-1; i++ {
+		s.markCached(newHash(i))
+	}
+
+	s.MarkPeer(newHash(PercentDelete-1), slot)
+	assert.Equal(t, s.Len(), PercentDelete)
+
+	// the txs only known by peers are evicted for the peer marks
+	s.MarkPeer(newHash(PercentDelete), slot)
+	assert.Equal(t, s.Len(), PercentDelete)
+	assert.Equal(t, s.KnownByPeer(newHash(PercentDelete-1), slot), false)
+	assert.Equal(t, s.KnownByPeer(newHash(PercentDelete), slot), true)
+
+	// the cached txs are never evicted for the peer marks
+	s.markCached(newHash(PercentDelete))
+	s.MarkPeer(newHash(PercentDelete+1), slot)
+	assert.Equal(t, s.KnownByPeer(newHash(PercentDelete+1), slot), false)
+	assert.Equal(t, s.cachedCount(), PercentDelete)
+
+	// the cached txs are evicted for a cached tx
+	s.markCached(newHash(PercentDelete + 1))
+	assert.Equal(t, s.Cached(newHash(PercentDelete+1)), true)
+	assert.Equal(t, s.Len(), PercentDelete)
+}
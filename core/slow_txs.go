@@ -0,0 +1,178 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+const (
+	// defaultSlowTxThreshold is the default execution time of a tx to be reported as slow
+	defaultSlowTxThreshold = 100 * time.Millisecond
+
+	// defaultSlowTxReportSize is the default number of the recent slow txs kept in the report
+	defaultSlowTxReportSize = 100
+)
+
+// SlowTx is a tx whose execution exceeds the threshold when the block is written
+type SlowTx struct {
+	TxHash      common.Hash
+	BlockHash   common.Hash
+	BlockHeight uint64
+	TxIndex     int
+	To          common.Address // the called contract, empty for the contract deployment
+	UsedGas     uint64
+	Failed      bool
+	Duration    float64 // execution time in milliseconds
+	Time        int64   // unix time when the tx is executed
+}
+
+// SlowTxReport is the rolling report of the recent slow txs, the newest first
+type SlowTxReport struct {
+	Threshold float64 // milliseconds, negative if disabled
+	Total     uint64  // number of the slow txs since the node started
+	Txs       []*SlowTx
+}
+
+// slowTxReport keeps the recent slow txs in a ring buffer
+type slowTxReport struct {
+	lock      sync.Mutex
+	threshold time.Duration // negative if disabled
+	txs       []*SlowTx     // ring buffer of the slow txs
+	next      int           // index of the next slow tx in the ring buffer
+	total     uint64
+}
+
+func newSlowTxReport() *slowTxReport {
+	return &slowTxReport{
+		threshold: defaultSlowTxThreshold,
+		txs:       make([]*SlowTx, 0, defaultSlowTxReportSize),
+	}
+}
+
+// setConfig sets the threshold in milliseconds and the number of the recent slow txs kept in the report.
+// The threshold 0 means the default 100 milliseconds, and negative disables the report. The size 0 means
+// the default 100 txs, and the recent slow txs are kept if the size changes.
+func (r *slowTxReport) setConfig(threshold int64, size int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	switch {
+	case threshold == 0:
+		r.threshold = defaultSlowTxThreshold
+	case threshold < 0:
+		r.threshold = -1
+	default:
+		r.threshold = time.Duration(threshold) * time.Millisecond
+	}
+
+	if size <= 0 {
+		size = defaultSlowTxReportSize
+	}
+
+	if size == cap(r.txs) {
+		return
+	}
+
+	txs := r.recent()
+	if len(txs) > size {
+		txs = txs[:size]
+	}
+
+	// keep the oldest first in the new ring buffer
+	r.txs = make([]*SlowTx, 0, size)
+	for i := len(txs) - 1; i >= 0; i-- {
+		r.txs = append(r.txs, txs[i])
+	}
+	r.next = len(r.txs) % size
+}
+
+// isSlow returns true if the duration exceeds the threshold
+func (r *slowTxReport) isSlow(duration time.Duration) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.threshold >= 0 && duration >= r.threshold
+}
+
+func (r *slowTxReport) add(tx *SlowTx) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.txs) < cap(r.txs) {
+		r.txs = append(r.txs, tx)
+	} else {
+		r.txs[r.next] = tx
+	}
+
+	r.next = (r.next + 1) % cap(r.txs)
+	r.total++
+}
+
+// recent returns the slow txs in the ring buffer, the newest first
+func (r *slowTxReport) recent() []*SlowTx {
+	txs := make([]*SlowTx, 0, len(r.txs))
+	for i := 1; i <= len(r.txs); i++ {
+		txs = append(txs, r.txs[(r.next-i+len(r.txs))%len(r.txs)])
+	}
+
+	return txs
+}
+
+func (r *slowTxReport) report() *SlowTxReport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	report := &SlowTxReport{
+		Threshold: -1,
+		Total:     r.total,
+		Txs:       r.recent(),
+	}
+
+	if r.threshold >= 0 {
+		report.Threshold = float64(r.threshold) / float64(time.Millisecond)
+	}
+
+	return report
+}
+
+// record adds the tx into the report if its execution exceeds the threshold, and returns true if so
+func (r *slowTxReport) record(tx *types.Transaction, txIndex int, header *types.BlockHeader, receipt *types.Receipt, start time.Time) bool {
+	duration := time.Since(start)
+	if !r.isSlow(duration) {
+		return false
+	}
+
+	r.add(&SlowTx{
+		TxHash:      tx.Hash,
+		BlockHash:   header.Hash(),
+		BlockHeight: header.Height,
+		TxIndex:     txIndex,
+		To:          tx.Data.To,
+		UsedGas:     receipt.UsedGas,
+		Failed:      receipt.Failed,
+		Duration:    float64(duration) / float64(time.Millisecond),
+		Time:        start.Unix(),
+	})
+
+	return true
+}
+
+// SetSlowTxConfig sets the threshold in milliseconds of the tx execution to be reported as slow when the block
+// is written, 0 means the default 100 milliseconds and negative disables the report, and the number of the recent
+// slow txs kept in the report, 0 means the default 100.
+func (bc *Blockchain) SetSlowTxConfig(threshold int64, size int) {
+	bc.slowTxs.setConfig(threshold, size)
+}
+
+// SlowTxReport returns the recent txs whose execution exceeds the threshold when the blocks are written.
+func (bc *Blockchain) SlowTxReport() *SlowTxReport {
+	return bc.slowTxs.report()
+}
@@ -0,0 +1,72 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSlowTx(height uint64) *SlowTx {
+	return &SlowTx{TxHash: common.BigToHash(new(big.Int).SetUint64(height)), BlockHeight: height}
+}
+
+func heightsOfSlowTxs(txs []*SlowTx) []uint64 {
+	heights := make([]uint64, len(txs))
+	for i, tx := range txs {
+		heights[i] = tx.BlockHeight
+	}
+
+	return heights
+}
+
+func Test_SlowTxReport_Rolling(t *testing.T) {
+	r := newSlowTxReport()
+	r.setConfig(0, 3)
+
+	report := r.report()
+	assert.Equal(t, report.Threshold, float64(100))
+	assert.Equal(t, len(report.Txs), 0)
+
+	for i := uint64(1); i <= 5; i++ {
+		r.add(newTestSlowTx(i))
+	}
+
+	report = r.report()
+	assert.Equal(t, report.Total, uint64(5))
+	assert.Equal(t, heightsOfSlowTxs(report.Txs), []uint64{5, 4, 3})
+
+	// keep the recent txs when resized
+	r.setConfig(0, 2)
+	assert.Equal(t, heightsOfSlowTxs(r.report().Txs), []uint64{5, 4})
+
+	r.add(newTestSlowTx(6))
+	assert.Equal(t, heightsOfSlowTxs(r.report().Txs), []uint64{6, 5})
+
+	r.setConfig(0, 4)
+	r.add(newTestSlowTx(7))
+	assert.Equal(t, heightsOfSlowTxs(r.report().Txs), []uint64{7, 6, 5})
+	assert.Equal(t, r.report().Total, uint64(7))
+}
+
+func Test_SlowTxReport_Threshold(t *testing.T) {
+	r := newSlowTxReport()
+	assert.Equal(t, r.isSlow(99*time.Millisecond), false)
+	assert.Equal(t, r.isSlow(100*time.Millisecond), true)
+
+	r.setConfig(10, 0)
+	assert.Equal(t, r.isSlow(10*time.Millisecond), true)
+	assert.Equal(t, cap(r.txs), defaultSlowTxReportSize)
+
+	// disabled
+	r.setConfig(-1, 0)
+	assert.Equal(t, r.isSlow(time.Hour), false)
+	assert.Equal(t, r.report().Threshold, float64(-1))
+}
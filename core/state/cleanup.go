@@ -0,0 +1,110 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package state
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/common"
+)
+
+// errTrieNotWalkable is returned when the trie of the statedb could not be scanned, e.g. the light trie
+var errTrieNotWalkable = errors.New("trie of the statedb is not walkable")
+
+// walker is the trie which could visit all the key-value pairs in the order of keys
+type walker interface {
+	Walk(fn func(key, value []byte) error) error
+}
+
+// AccountUsage is the state usage of an account scanned from the state trie. The address is not
+// stored in the trie, so the account is identified by the address hash.
+type AccountUsage struct {
+	AddrHash     common.Hash
+	Nonce        uint64
+	Balance      *big.Int
+	IsContract   bool
+	StorageItems int // number of the storage items of the contract
+	Size         int // bytes of the keys and values of the account, code and storage
+}
+
+// AddressHash returns the hash of the address, which identifies the account in the state trie
+func AddressHash(addr common.Address) common.Hash {
+	return newStateObject(addr).addrHash
+}
+
+// ScanAccounts scans the committed state trie, and reports the state usage of every account in the
+// order of the address hash. The scan stops once fn returns an error.
+func (s *Statedb) ScanAccounts(fn func(usage *AccountUsage) error) error {
+	w, ok := s.trie.(walker)
+	if !ok {
+		return errTrieNotWalkable
+	}
+
+	var current *AccountUsage
+	err := w.Walk(func(key, value []byte) error {
+		if len(key) <= common.HashLength {
+			return nil // not a key of the account data
+		}
+
+		addrHash := common.BytesToHash(key[:common.HashLength])
+		if current != nil && current.AddrHash != addrHash {
+			if err := fn(current); err != nil {
+				return err
+			}
+			current = nil
+		}
+
+		if current == nil {
+			current = &AccountUsage{AddrHash: addrHash, Balance: new(big.Int)}
+		}
+
+		current.Size += len(key) + len(value)
+
+		switch key[common.HashLength] {
+		case dataTypeAccount:
+			var acc account
+			if err := common.Deserialize(value, &acc); err != nil {
+				return err
+			}
+
+			current.Nonce = acc.Nonce
+			if acc.Amount != nil {
+				current.Balance = acc.Amount
+			}
+			current.IsContract = len(acc.CodeHash) > 0
+		case dataTypeStorage:
+			current.StorageItems++
+		}
+
+		return nil
+	})
+
+	if err != nil || current == nil {
+		return err
+	}
+
+	return fn(current)
+}
+
+// DeleteEmptyAccounts deletes the empty accounts (balance = nonce = code = 0) changed since the last
+// hash, e.g. created by a zero value transfer. The empty accounts are indistinguishable from the
+// nonexistent accounts except for Exist, so deleting them reclaims the state without side effects.
+// It returns the number of the accounts deleted.
+func (s *Statedb) DeleteEmptyAccounts() int {
+	var empties []common.Address
+	for addr := range s.curJournal.dirties {
+		if object, found := s.stateObjects[addr]; found && !object.deleted && !object.suicided && object.empty() {
+			empties = append(empties, addr)
+		}
+	}
+
+	for _, addr := range empties {
+		s.Suicide(addr)
+	}
+
+	return len(empties)
+}
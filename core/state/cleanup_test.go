@@ -0,0 +1,81 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Statedb_ScanAccounts(t *testing.T) {
+	db, remove := leveldb.NewTestDatabase()
+	defer remove()
+
+	user := common.BytesToAddress([]byte("user"))
+	contract := common.BytesToAddress([]byte("contract"))
+
+	statedb := NewEmptyStatedb(db)
+	statedb.CreateAccount(user)
+	statedb.SetBalance(user, big.NewInt(100))
+	statedb.CreateAccount(contract)
+	statedb.SetCode(contract, []byte("code"))
+	statedb.SetData(contract, common.StringToHash("k1"), []byte("v1"))
+	statedb.SetData(contract, common.StringToHash("k2"), []byte("v2"))
+
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, batch.Commit(), nil)
+
+	statedb, err = NewStatedb(root, db)
+	assert.Equal(t, err, nil)
+
+	usages := make(map[common.Hash]*AccountUsage)
+	assert.Equal(t, statedb.ScanAccounts(func(usage *AccountUsage) error {
+		usages[usage.AddrHash] = usage
+		return nil
+	}), nil)
+
+	assert.Equal(t, len(usages), 2)
+	assert.Equal(t, usages[AddressHash(user)].Balance, big.NewInt(100))
+	assert.Equal(t, usages[AddressHash(user)].IsContract, false)
+	assert.Equal(t, usages[AddressHash(contract)].IsContract, true)
+	assert.Equal(t, usages[AddressHash(contract)].StorageItems, 2)
+	assert.Equal(t, usages[AddressHash(contract)].Size > usages[AddressHash(user)].Size, true)
+}
+
+func Test_Statedb_DeleteEmptyAccounts(t *testing.T) {
+	db, remove := leveldb.NewTestDatabase()
+	defer remove()
+
+	user := common.BytesToAddress([]byte("user"))
+	empty := common.BytesToAddress([]byte("empty"))
+
+	statedb := NewEmptyStatedb(db)
+	statedb.CreateAccount(user)
+	statedb.SetBalance(user, big.NewInt(100))
+	statedb.CreateAccount(empty)
+	statedb.AddBalance(empty, big.NewInt(0))
+
+	assert.Equal(t, statedb.DeleteEmptyAccounts(), 1)
+	root, err := statedb.Hash()
+	assert.Equal(t, err, nil)
+
+	// the root is the same as the state without the empty account
+	expected := NewEmptyStatedb(db)
+	expected.CreateAccount(user)
+	expected.SetBalance(user, big.NewInt(100))
+	expectedRoot, err := expected.Hash()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, root, expectedRoot)
+
+	assert.Equal(t, statedb.Exist(empty), false)
+	assert.Equal(t, statedb.GetBalance(empty).Sign(), 0)
+}
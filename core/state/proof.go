@@ -0,0 +1,108 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/trie"
+)
+
+var (
+	// ErrCodeNotProven is returned when the code of the contract account is missing in the proof
+	ErrCodeNotProven = errors.New("code of the account is not proven")
+
+	// ErrCodeHashMismatch is returned when the proven code mismatches the code hash of the account
+	ErrCodeHashMismatch = errors.New("code hash mismatch")
+)
+
+// ProvenAccount is the account proven by the merkle proof against a state root
+type ProvenAccount struct {
+	Exist   bool
+	Nonce   uint64
+	Balance *big.Int
+	Code    []byte
+}
+
+// GetAccountProof returns the merkle proof of the account against the state root, including the
+// proof of the code if it is a contract account. For a nonexistent account, the proof proves
+// the absence of the account.
+func (s *Statedb) GetAccountProof(addr common.Address) (map[string][]byte, error) {
+	object := newStateObject(addr)
+
+	proof, err := s.trie.GetProof(object.dataKey(dataTypeAccount))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := object.loadAccount(s.trie)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok || len(object.account.CodeHash) == 0 {
+		return proof, nil
+	}
+
+	codeProof, err := s.trie.GetProof(object.dataKey(dataTypeCode))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range codeProof {
+		proof[k] = v
+	}
+
+	return proof, nil
+}
+
+// VerifyAccountProof verifies the merkle proof of the account against the state root, and returns
+// the account proven. The code of a contract account is verified against its code hash as well.
+func VerifyAccountProof(root common.Hash, addr common.Address, proof map[string][]byte) (*ProvenAccount, error) {
+	object := newStateObject(addr)
+
+	value, err := trie.VerifyProof(root, object.dataKey(dataTypeAccount), proof)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProvenAccount{Balance: new(big.Int)}
+	if value == nil {
+		return result, nil
+	}
+
+	if err = common.Deserialize(value, &object.account); err != nil {
+		return nil, err
+	}
+
+	result.Exist = true
+	result.Nonce = object.account.Nonce
+	if object.account.Amount != nil {
+		result.Balance = object.account.Amount
+	}
+
+	if len(object.account.CodeHash) == 0 {
+		return result, nil
+	}
+
+	if result.Code, err = trie.VerifyProof(root, object.dataKey(dataTypeCode), proof); err != nil {
+		return nil, err
+	}
+
+	if result.Code == nil {
+		return nil, ErrCodeNotProven
+	}
+
+	if !bytes.Equal(crypto.HashBytes(result.Code).Bytes(), object.account.CodeHash) {
+		return nil, ErrCodeHashMismatch
+	}
+
+	return result, nil
+}
@@ -0,0 +1,74 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Statedb_AccountProof(t *testing.T) {
+	db, remove := leveldb.NewTestDatabase()
+	defer remove()
+
+	user := common.BytesToAddress([]byte("user"))
+	contract := common.BytesToAddress([]byte("contract"))
+
+	statedb := NewEmptyStatedb(db)
+	statedb.CreateAccount(user)
+	statedb.SetBalance(user, big.NewInt(100))
+	statedb.SetNonce(user, 3)
+	statedb.CreateAccount(contract)
+	statedb.SetCode(contract, []byte("code"))
+
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, batch.Commit(), nil)
+
+	statedb, err = NewStatedb(root, db)
+	assert.Equal(t, err, nil)
+
+	// user account
+	proof, err := statedb.GetAccountProof(user)
+	assert.Equal(t, err, nil)
+	account, err := VerifyAccountProof(root, user, proof)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, account.Exist, true)
+	assert.Equal(t, account.Balance, big.NewInt(100))
+	assert.Equal(t, account.Nonce, uint64(3))
+	assert.Equal(t, len(account.Code), 0)
+
+	// contract account with code
+	proof, err = statedb.GetAccountProof(contract)
+	assert.Equal(t, err, nil)
+	account, err = VerifyAccountProof(root, contract, proof)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, account.Code, []byte("code"))
+
+	// nonexistent account
+	unknown := common.BytesToAddress([]byte("unknown"))
+	proof, err = statedb.GetAccountProof(unknown)
+	assert.Equal(t, err, nil)
+	account, err = VerifyAccountProof(root, unknown, proof)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, account.Exist, false)
+	assert.Equal(t, account.Balance.Sign(), 0)
+
+	// proof of another account
+	proof, err = statedb.GetAccountProof(user)
+	assert.Equal(t, err, nil)
+	_, err = VerifyAccountProof(root, contract, proof)
+	assert.Equal(t, err != nil, true)
+
+	// proof against another root
+	_, err = VerifyAccountProof(common.StringToHash("root"), user, proof)
+	assert.Equal(t, err != nil, true)
+}
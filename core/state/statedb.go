@@ -427,3 +427,18 @@ func (s *Statedb) GetDirtyAccounts() []common.Address {
 
 	return addresses
 }
+
+// GetChangedStorageKeys returns the storage keys of the specified account changed in this statedb
+func (s *Statedb) GetChangedStorageKeys(addr common.Address) []common.Hash {
+	object, found := s.stateObjects[addr]
+	if !found {
+		return nil
+	}
+
+	var keys []common.Hash
+	for key := range object.changedStorage {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
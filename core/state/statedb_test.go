@@ -413,3 +413,19 @@ func Test_Log(t *testing.T) {
 	assert.Equal(t, logs[1].TxIndex, uint(38))
 	assert.Equal(t, logs[2].TxIndex, uint(38))
 }
+
+func Test_GetChangedStorageKeys(t *testing.T) {
+	_, statedb, stateObj, dispose := newTestEVMStateDB()
+	defer dispose()
+
+	key := common.StringToHash("test key")
+	statedb.SetData(stateObj.address, key, []byte("test value"))
+
+	// changed keys are kept after the dirty storage is flushed
+	_, err := statedb.Hash()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, statedb.GetChangedStorageKeys(stateObj.address), []common.Hash{key})
+
+	// unknown account has no changed keys
+	assert.Equal(t, len(statedb.GetChangedStorageKeys(*crypto.MustGenerateRandomAddress())), 0)
+}
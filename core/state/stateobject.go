@@ -53,8 +53,9 @@ type stateObject struct {
 	code      []byte // contract code
 	dirtyCode bool
 
-	cachedStorage map[common.Hash][]byte // cache the retrieved account states.
-	dirtyStorage  map[common.Hash][]byte // changed account states that need to flush to DB.
+	cachedStorage  map[common.Hash][]byte   // cache the retrieved account states.
+	dirtyStorage   map[common.Hash][]byte   // changed account states that need to flush to DB.
+	changedStorage map[common.Hash]struct{} // keys of all account states changed in the statedb, kept after flush.
 
 	// When a state object is marked as suicided, it will be deleted from the trie when commit the state DB.
 	suicided bool
@@ -66,12 +67,13 @@ type stateObject struct {
 // newStateObject creates and returns a new state object instance
 func newStateObject(address common.Address) *stateObject {
 	return &stateObject{
-		address:       address,
-		addrHash:      crypto.MustHash(address),
-		account:       newAccount(),
-		dirtyAccount:  true,
-		cachedStorage: make(map[common.Hash][]byte),
-		dirtyStorage:  make(map[common.Hash][]byte),
+		address:        address,
+		addrHash:       crypto.MustHash(address),
+		account:        newAccount(),
+		dirtyAccount:   true,
+		cachedStorage:  make(map[common.Hash][]byte),
+		dirtyStorage:   make(map[common.Hash][]byte),
+		changedStorage: make(map[common.Hash]struct{}),
 	}
 }
 
@@ -83,6 +85,10 @@ func (s *stateObject) clone() *stateObject {
 	cloned.code = common.CopyBytes(s.code)
 	cloned.cachedStorage = copyStorage(s.cachedStorage)
 	cloned.dirtyStorage = copyStorage(s.dirtyStorage)
+	cloned.changedStorage = make(map[common.Hash]struct{}, len(s.changedStorage))
+	for k := range s.changedStorage {
+		cloned.changedStorage[k] = struct{}{}
+	}
 
 	return &cloned
 }
@@ -197,6 +203,7 @@ func (s *stateObject) empty() bool {
 // setState sets the state in the dirty storage of the state object
 func (s *stateObject) setState(key common.Hash, value []byte) {
 	s.dirtyStorage[key] = value
+	s.changedStorage[key] = struct{}{}
 }
 
 // getState gets the state from the trie
@@ -0,0 +1,86 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"bytes"
+	"sort"
+	"sync/atomic"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// SetStateDiff sets whether to always compute and store the state diffs of the written blocks.
+// Otherwise, the state diffs are only computed while there are consumers, e.g. subscriptions.
+func (bc *Blockchain) SetStateDiff(enabled bool) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.stateDiff = enabled
+}
+
+// AddStateDiffConsumer registers a consumer of the state diffs of the written blocks,
+// which must be removed by RemoveStateDiffConsumer once it no longer consumes.
+func (bc *Blockchain) AddStateDiffConsumer() {
+	atomic.AddInt32(&bc.stateDiffConsumers, 1)
+}
+
+// RemoveStateDiffConsumer removes a consumer registered by AddStateDiffConsumer.
+func (bc *Blockchain) RemoveStateDiffConsumer() {
+	atomic.AddInt32(&bc.stateDiffConsumers, -1)
+}
+
+// stateDiffRequired returns whether the state diff of the block being written is required,
+// which is called with the chain lock held.
+func (bc *Blockchain) stateDiffRequired() bool {
+	return bc.stateDiff || atomic.LoadInt32(&bc.stateDiffConsumers) > 0
+}
+
+// computeStateDiff computes the changes of the dirty accounts in the statedb
+// against the state of the specified pre state root hash.
+func (bc *Blockchain) computeStateDiff(preStateHash common.Hash, statedb *state.Statedb) ([]*types.AccountDiff, error) {
+	preStatedb, err := state.NewStatedb(preStateHash, bc.accountStateDB)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to create statedb by root hash %v", preStateHash)
+	}
+
+	diffs := make([]*types.AccountDiff, 0)
+	for _, addr := range statedb.GetDirtyAccounts() {
+		diff := &types.AccountDiff{
+			Address:        addr,
+			BalanceBefore:  preStatedb.GetBalance(addr),
+			BalanceAfter:   statedb.GetBalance(addr),
+			NonceBefore:    preStatedb.GetNonce(addr),
+			NonceAfter:     statedb.GetNonce(addr),
+			CodeHashBefore: preStatedb.GetCodeHash(addr),
+			CodeHashAfter:  statedb.GetCodeHash(addr),
+		}
+
+		for _, key := range statedb.GetChangedStorageKeys(addr) {
+			before, after := preStatedb.GetData(addr, key), statedb.GetData(addr, key)
+			if !bytes.Equal(before, after) {
+				diff.Storage = append(diff.Storage, &types.StorageDiff{Key: key, Before: before, After: after})
+			}
+		}
+
+		if diff.Changed() {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return bytes.Compare(diffs[i].Address[:], diffs[j].Address[:]) < 0
+	})
+
+	if err = preStatedb.GetDbErr(); err != nil {
+		return nil, errors.NewStackedError(err, "failed to read pre state")
+	}
+
+	return diffs, nil
+}
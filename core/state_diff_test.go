@@ -0,0 +1,47 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Blockchain_StateDiffRequired(t *testing.T) {
+	bc := NewTestBlockchain()
+
+	// not required by default
+	assert.Equal(t, bc.stateDiffRequired(), false)
+
+	// required while consumed
+	bc.AddStateDiffConsumer()
+	bc.AddStateDiffConsumer()
+	assert.Equal(t, bc.stateDiffRequired(), true)
+	bc.RemoveStateDiffConsumer()
+	assert.Equal(t, bc.stateDiffRequired(), true)
+	bc.RemoveStateDiffConsumer()
+	assert.Equal(t, bc.stateDiffRequired(), false)
+
+	// required if enabled
+	bc.SetStateDiff(true)
+	assert.Equal(t, bc.stateDiffRequired(), true)
+}
+
+func Test_Blockchain_ComputeStateDiff_NoChanges(t *testing.T) {
+	bc := NewTestBlockchain()
+
+	root := bc.genesisBlock.Header.StateHash
+	statedb, err := state.NewStatedb(root, bc.accountStateDB)
+	assert.Equal(t, err, nil)
+
+	// the computed diff is not nil, so that it is stored
+	diffs, err := bc.computeStateDiff(root, statedb)
+	assert.Equal(t, err, nil)
+	assert.NotNil(t, diffs)
+	assert.Equal(t, len(diffs), 0)
+}
@@ -0,0 +1,128 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// StateMismatchReport is the diagnostics of a block whose computed state root hash mismatches
+// the one in the block header, which is dumped to a report file for the bug triage across node versions.
+type StateMismatchReport struct {
+	NodeVersion       string
+	BlockHash         common.Hash
+	BlockHeight       uint64
+	PreStateHash      common.Hash
+	ExpectedStateHash common.Hash // state root hash in the block header
+	ComputedStateHash common.Hash // state root hash after the block is applied locally
+
+	ComputedDirtyAccounts []common.Address
+	AccountDiffs          []*types.AccountDiff // changes of the computed dirty accounts
+
+	// ExpectedDirtyAccounts is the dirty accounts of the block stored in the chain DB, e.g. written
+	// by another node version, nil if not available.
+	ExpectedDirtyAccounts []common.Address
+	MissingAccounts       []common.Address // expected dirty accounts that are not changed locally
+	UnexpectedAccounts    []common.Address // accounts changed locally that are not expected to be dirty
+}
+
+// SetStateMismatchReportDir sets the folder of the state root mismatch report files, empty means disabled.
+func (bc *Blockchain) SetStateMismatchReportDir(dir string) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.stateMismatchReportDir = dir
+}
+
+// newStateMismatchReport compares the computed dirty set of the block with the expected one if available.
+func (bc *Blockchain) newStateMismatchReport(block *types.Block, preStateHash, computedStateHash common.Hash, statedb *state.Statedb) (*StateMismatchReport, error) {
+	report := &StateMismatchReport{
+		NodeVersion:           common.ScdoNodeVersion,
+		BlockHash:             block.HeaderHash,
+		BlockHeight:           block.Header.Height,
+		PreStateHash:          preStateHash,
+		ExpectedStateHash:     block.Header.StateHash,
+		ComputedStateHash:     computedStateHash,
+		ComputedDirtyAccounts: sortAddresses(statedb.GetDirtyAccounts()),
+	}
+
+	var err error
+	if report.AccountDiffs, err = bc.computeStateDiff(preStateHash, statedb); err != nil {
+		return nil, err
+	}
+
+	if expected, err := bc.bcStore.GetDirtyAccountsByBlockHash(block.HeaderHash); err == nil {
+		report.ExpectedDirtyAccounts = sortAddresses(expected)
+		report.MissingAccounts = subtractAddresses(report.ExpectedDirtyAccounts, report.ComputedDirtyAccounts)
+		report.UnexpectedAccounts = subtractAddresses(report.ComputedDirtyAccounts, report.ExpectedDirtyAccounts)
+	}
+
+	return report, nil
+}
+
+// reportStateMismatch writes the state root mismatch report of the block into the report folder,
+// and returns the file path.
+func (bc *Blockchain) reportStateMismatch(block *types.Block, preStateHash, computedStateHash common.Hash, statedb *state.Statedb) (string, error) {
+	report, err := bc.newStateMismatchReport(block, preStateHash, computedStateHash, statedb)
+	if err != nil {
+		return "", errors.NewStackedError(err, "failed to create state mismatch report")
+	}
+
+	content, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return "", errors.NewStackedError(err, "failed to encode state mismatch report")
+	}
+
+	if err = os.MkdirAll(bc.stateMismatchReportDir, os.ModePerm); err != nil {
+		return "", errors.NewStackedErrorf(err, "failed to create folder %v", bc.stateMismatchReportDir)
+	}
+
+	path := filepath.Join(bc.stateMismatchReportDir, fmt.Sprintf("statemismatch_%v_%v.json", block.Header.Height, block.HeaderHash.Hex()))
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		return "", errors.NewStackedErrorf(err, "failed to write file %v", path)
+	}
+
+	return path, nil
+}
+
+func sortAddresses(addrs []common.Address) []common.Address {
+	sorted := make([]common.Address, len(addrs))
+	copy(sorted, addrs)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+
+	return sorted
+}
+
+// subtractAddresses returns the addresses in a but not in b.
+func subtractAddresses(a, b []common.Address) []common.Address {
+	set := make(map[common.Address]bool, len(b))
+	for _, addr := range b {
+		set[addr] = true
+	}
+
+	var result []common.Address
+	for _, addr := range a {
+		if !set[addr] {
+			result = append(result, addr)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,64 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Blockchain_StateMismatchReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "statemismatch")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	bc := NewTestBlockchain()
+	bc.SetStateMismatchReportDir(dir)
+
+	newBlock := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 3, 0)
+	computedStateHash := newBlock.Header.StateHash
+	newBlock.Header.StateHash = common.StringToHash("wrong state hash")
+	newBlock.HeaderHash = newBlock.Header.Hash()
+
+	// the dirty accounts of another node version
+	miner := newBlock.Transactions[0].Data.To
+	unknown := *crypto.MustGenerateShardAddress(1)
+	assert.Equal(t, bc.bcStore.PutDirtyAccounts(newBlock.HeaderHash, []common.Address{unknown}), nil)
+
+	common.LocalShardNumber = miner.Shard()
+	defer func() {
+		common.LocalShardNumber = common.UndefinedShardNumber
+	}()
+
+	statedb, _, err := bc.applyTxs(newBlock, bc.genesisBlock.Header.StateHash)
+	assert.Equal(t, err, nil)
+
+	path, err := bc.reportStateMismatch(newBlock, bc.genesisBlock.Header.StateHash, computedStateHash, statedb)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, filepath.Dir(path), dir)
+
+	content, err := ioutil.ReadFile(path)
+	assert.Equal(t, err, nil)
+
+	var report StateMismatchReport
+	assert.Equal(t, json.Unmarshal(content, &report), nil)
+	assert.Equal(t, report.BlockHash, newBlock.HeaderHash)
+	assert.Equal(t, report.BlockHeight, uint64(1))
+	assert.Equal(t, report.ExpectedStateHash, newBlock.Header.StateHash)
+	assert.Equal(t, report.ComputedStateHash, computedStateHash)
+	assert.Equal(t, report.ComputedDirtyAccounts, []common.Address{miner})
+	assert.Equal(t, len(report.AccountDiffs), 1)
+	assert.Equal(t, report.AccountDiffs[0].Address, miner)
+	assert.Equal(t, report.MissingAccounts, []common.Address{unknown})
+	assert.Equal(t, report.UnexpectedAccounts, []common.Address{miner})
+}
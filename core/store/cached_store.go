@@ -81,6 +81,16 @@ func (store *cachedStore) PutHeadBlockHash(hash common.Hash) error {
 	return store.raw.PutHeadBlockHash(hash)
 }
 
+// GetChainConfig retrieves the chain config.
+func (store *cachedStore) GetChainConfig() (*common.ChainConfig, error) {
+	return store.raw.GetChainConfig()
+}
+
+// PutChainConfig writes the chain config into the store.
+func (store *cachedStore) PutChainConfig(config *common.ChainConfig) error {
+	return store.raw.PutChainConfig(config)
+}
+
 // GetBlockHeader retrieves the block header for the specified block hash.
 func (store *cachedStore) GetBlockHeader(hash common.Hash) (*types.BlockHeader, error) {
 	if header, found := store.headerCache.Get(hash); found {
@@ -154,18 +164,33 @@ func (store *cachedStore) GetBlockTotalDifficulty(hash common.Hash) (*big.Int, e
 func (store *cachedStore) PutBlock(block *types.Block, td *big.Int, isHead bool) error {
 	err := store.raw.PutBlock(block, td, isHead)
 	if err == nil {
-		store.headerCache.Add(block.HeaderHash, block.Header)
-		store.tdCache.Add(block.HeaderHash, td)
-		store.blockCache.Add(block.HeaderHash, block)
+		store.cacheBlock(block, td, isHead)
+	}
 
-		if isHead {
-			store.hashCache.Add(block.Header.Height, block.HeaderHash)
-		}
+	return err
+}
+
+// WriteBlock writes the given block with the receipts, dirty accounts and state diff in data
+// into the store atomically, in the same way as PutBlock.
+func (store *cachedStore) WriteBlock(block *types.Block, td *big.Int, isHead bool, data *BlockData) error {
+	err := store.raw.WriteBlock(block, td, isHead, data)
+	if err == nil {
+		store.cacheBlock(block, td, isHead)
 	}
 
 	return err
 }
 
+func (store *cachedStore) cacheBlock(block *types.Block, td *big.Int, isHead bool) {
+	store.headerCache.Add(block.HeaderHash, block.Header)
+	store.tdCache.Add(block.HeaderHash, td)
+	store.blockCache.Add(block.HeaderHash, block)
+
+	if isHead {
+		store.hashCache.Add(block.Header.Height, block.HeaderHash)
+	}
+}
+
 // RecoverHeightToBlockMap rebuilds the Height-to-block map
 func (store *cachedStore) RecoverHeightToBlockMap(block *types.Block) error {
 	err := store.raw.RecoverHeightToBlockMap(block)
@@ -254,6 +279,16 @@ func (store *cachedStore) GetDirtyAccountsByBlockHash(hash common.Hash) ([]commo
 	return store.raw.GetDirtyAccountsByBlockHash(hash)
 }
 
+// PutStateDiff serializes given account diffs for the specified block hash.
+func (store *cachedStore) PutStateDiff(hash common.Hash, diffs []*types.AccountDiff) error {
+	return store.raw.PutStateDiff(hash, diffs)
+}
+
+// GetStateDiff retrieves the account diffs for the specified block hash.
+func (store *cachedStore) GetStateDiff(hash common.Hash) ([]*types.AccountDiff, error) {
+	return store.raw.GetStateDiff(hash)
+}
+
 // AddIndices addes tx/debt indices for the specified block.
 func (store *cachedStore) AddIndices(block *types.Block) error {
 	return store.raw.AddIndices(block)
@@ -273,3 +308,48 @@ func (store *cachedStore) GetDebtIndex(txHash common.Hash) (*types.DebtIndex, er
 func (store *cachedStore) DeleteIndices(block *types.Block) error {
 	return store.raw.DeleteIndices(block)
 }
+
+// PruneBlockData deletes the receipts and dirty accounts of the specified blocks, and updates the pruned height.
+func (store *cachedStore) PruneBlockData(hashes []common.Hash, prunedHeight uint64) (int, error) {
+	return store.raw.PruneBlockData(hashes, prunedHeight)
+}
+
+// GetPrunedHeight retrieves the height up to which the receipts and dirty accounts are pruned.
+func (store *cachedStore) GetPrunedHeight() (uint64, error) {
+	return store.raw.GetPrunedHeight()
+}
+
+// GetBlockLogIndex retrieves the log indices of the block for the specified block hash.
+func (store *cachedStore) GetBlockLogIndex(hash common.Hash) (*types.BlockLogIndex, error) {
+	return store.raw.GetBlockLogIndex(hash)
+}
+
+// GetLogIndexStartHeight retrieves the height from which the blocks have the log indices.
+func (store *cachedStore) GetLogIndexStartHeight() (uint64, error) {
+	return store.raw.GetLogIndexStartHeight()
+}
+
+// PruneReceipts deletes the receipts of the specified blocks, and updates the receipts pruned height.
+func (store *cachedStore) PruneReceipts(hashes []common.Hash, prunedHeight uint64) (int, error) {
+	return store.raw.PruneReceipts(hashes, prunedHeight)
+}
+
+// GetReceiptsPrunedHeight retrieves the height up to which the receipts are pruned.
+func (store *cachedStore) GetReceiptsPrunedHeight() (uint64, error) {
+	return store.raw.GetReceiptsPrunedHeight()
+}
+
+// PruneDirtyAccounts deletes the dirty accounts of the specified blocks, and updates the dirty accounts pruned height.
+func (store *cachedStore) PruneDirtyAccounts(hashes []common.Hash, prunedHeight uint64) (int, error) {
+	return store.raw.PruneDirtyAccounts(hashes, prunedHeight)
+}
+
+// GetDirtyAccountsPrunedHeight retrieves the height up to which the dirty accounts are pruned.
+func (store *cachedStore) GetDirtyAccountsPrunedHeight() (uint64, error) {
+	return store.raw.GetDirtyAccountsPrunedHeight()
+}
+
+// GetSpentDebts retrieves the blocks which apply the specified debt in all forks.
+func (store *cachedStore) GetSpentDebts(debtHash common.Hash) ([]*types.SpentDebt, error) {
+	return store.raw.GetSpentDebts(debtHash)
+}
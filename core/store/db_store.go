@@ -7,6 +7,7 @@ package store
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -18,6 +19,12 @@ import (
 
 var (
 	keyHeadBlockHash = []byte("HeadBlockHash")
+	keyChainConfig   = []byte("ChainConfig")
+	keyPrunedHeight  = []byte("PrunedHeight")
+
+	keyReceiptsPrunedHeight      = []byte("ReceiptsPrunedHeight")
+	keyDirtyAccountsPrunedHeight = []byte("DirtyAccountsPrunedHeight")
+	keyLogIndexStartHeight       = []byte("LogIndexStartHeight")
 
 	keyPrefixHash          = []byte("H")
 	keyPrefixHeader        = []byte("h")
@@ -25,8 +32,11 @@ var (
 	keyPrefixBody          = []byte("b")
 	keyPrefixReceipts      = []byte("r")
 	keyPrefixDirtyAccounts = []byte("D")
+	keyPrefixStateDiff     = []byte("s")
 	keyPrefixTxIndex       = []byte("i")
 	keyPrefixDebtIndex     = []byte("d")
+	keyPrefixSpentDebt     = []byte("S")
+	keyPrefixLogIndex      = []byte("l")
 )
 
 // blockBody represents the payload of a block
@@ -49,6 +59,11 @@ type blockchainDatabase struct {
 //   5) keyPrefixBody + hash => block body (transactions)
 //   6) keyPrefixReceipts + hash => block receipts
 //   7) keyPrefixTxIndex + txHash => txIndex
+//   8) keyPrefixStateDiff + hash => block state diff
+//   9) keyPrefixSpentDebt + debtHash => blocks which apply the debt in all forks
+//   10) keyChainConfig => chain config
+//   11) keyPrefixLogIndex + hash => block log index
+//   12) keyLogIndexStartHeight => height of the first block with log index, if the chain is written before the log indices
 func NewBlockchainDatabase(db database.Database) BlockchainStore {
 	return &blockchainDatabase{db}
 }
@@ -59,8 +74,11 @@ func hashToTDKey(hash []byte) []byte            { return append(keyPrefixTD, has
 func hashToBodyKey(hash []byte) []byte          { return append(keyPrefixBody, hash...) }
 func hashToReceiptsKey(hash []byte) []byte      { return append(keyPrefixReceipts, hash...) }
 func hashToDirtyAccountsKey(hash []byte) []byte { return append(keyPrefixDirtyAccounts, hash...) }
+func hashToStateDiffKey(hash []byte) []byte     { return append(keyPrefixStateDiff, hash...) }
 func txHashToIndexKey(txHash []byte) []byte     { return append(keyPrefixTxIndex, txHash...) }
 func debtHashToIndexKey(debtHash []byte) []byte { return append(keyPrefixDebtIndex, debtHash...) }
+func debtHashToSpentKey(debtHash []byte) []byte { return append(keyPrefixSpentDebt, debtHash...) }
+func hashToLogIndexKey(hash []byte) []byte      { return append(keyPrefixLogIndex, hash...) }
 
 // GetBlockHash gets the hash of the block with the specified height in the blockchain database
 func (store *blockchainDatabase) GetBlockHash(height uint64) (common.Hash, error) {
@@ -120,6 +138,84 @@ func (store *blockchainDatabase) PutHeadBlockHash(hash common.Hash) error {
 	return store.db.Put(keyHeadBlockHash, hash.Bytes())
 }
 
+// GetChainConfig gets the chain config in the blockchain database
+func (store *blockchainDatabase) GetChainConfig() (*common.ChainConfig, error) {
+	data, err := store.db.Get(keyChainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// the chain config was persisted as an RLP list by the previous versions
+	if len(data) > 0 && data[0] != '{' {
+		return decodeLegacyChainConfig(data)
+	}
+
+	config := new(common.ChainConfig)
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// PutChainConfig writes the chain config into the store. It is persisted as JSON, so that the fields added
+// later are decoded with the main network values from the configs stored before.
+func (store *blockchainDatabase) PutChainConfig(config *common.ChainConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	return store.db.Put(keyChainConfig, data)
+}
+
+// legacyChainConfig is the RLP layout of the chain config persisted by the previous versions
+type legacyChainConfig struct {
+	EmeryForkHeight              uint64
+	SecondForkHeight             uint64
+	ThirdForkHeight              uint64
+	SmartContractNonceForkHeight uint64
+	SmartContractNonceFixHeight  uint64
+	SignalingExtraForkHeight     uint64
+	BlockTimeDriftForkHeight     uint64
+	StateCleanupForkHeight       uint64
+	AccessListForkHeight         uint64
+	MetaTxRelayForkHeight        uint64
+	ContractRegistryForkHeight   uint64
+	AccessControlForkHeight      uint64
+	ChainID                      uint64
+	ZpowParams                   []common.ZpowParams `rlp:"tail"`
+}
+
+func decodeLegacyChainConfig(data []byte) (*common.ChainConfig, error) {
+	var legacy legacyChainConfig
+	if err := common.Deserialize(data, &legacy); err != nil {
+		return nil, err
+	}
+
+	config := common.DefaultChainConfig()
+	config.EmeryForkHeight = legacy.EmeryForkHeight
+	config.SecondForkHeight = legacy.SecondForkHeight
+	config.ThirdForkHeight = legacy.ThirdForkHeight
+	config.SmartContractNonceForkHeight = legacy.SmartContractNonceForkHeight
+	config.SmartContractNonceFixHeight = legacy.SmartContractNonceFixHeight
+	config.SignalingExtraForkHeight = legacy.SignalingExtraForkHeight
+	config.BlockTimeDriftForkHeight = legacy.BlockTimeDriftForkHeight
+	config.StateCleanupForkHeight = legacy.StateCleanupForkHeight
+	config.AccessListForkHeight = legacy.AccessListForkHeight
+	config.MetaTxRelayForkHeight = legacy.MetaTxRelayForkHeight
+	config.ContractRegistryForkHeight = legacy.ContractRegistryForkHeight
+	config.AccessControlForkHeight = legacy.AccessControlForkHeight
+	config.ChainID = legacy.ChainID
+
+	// the empty tail list is decoded as an empty slice
+	if len(legacy.ZpowParams) > 0 {
+		config.ZpowParams = legacy.ZpowParams
+	}
+
+	return config, nil
+}
+
 // GetBlockHeader gets the header of the block with the specified hash in the blockchain database
 func (store *blockchainDatabase) GetBlockHeader(hash common.Hash) (*types.BlockHeader, error) {
 	headerBytes, err := store.db.Get(hashToHeaderKey(hash.Bytes()))
@@ -155,6 +251,16 @@ func (store *blockchainDatabase) PutBlockHeader(hash common.Hash, header *types.
 }
 
 func (store *blockchainDatabase) putBlockInternal(hash common.Hash, header *types.BlockHeader, body *blockBody, td *big.Int, isHead bool) error {
+	batch := store.db.NewBatch()
+	if err := store.batchPutBlock(batch, hash, header, body, td, isHead); err != nil {
+		return err
+	}
+
+	return batch.Commit()
+}
+
+// batchPutBlock puts the block header, body and indices into the batch
+func (store *blockchainDatabase) batchPutBlock(batch database.Batch, hash common.Hash, header *types.BlockHeader, body *blockBody, td *big.Int, isHead bool) error {
 	if header == nil {
 		panic("header is nil")
 	}
@@ -163,12 +269,16 @@ func (store *blockchainDatabase) putBlockInternal(hash common.Hash, header *type
 
 	hashBytes := hash.Bytes()
 
-	batch := store.db.NewBatch()
 	batch.Put(hashToHeaderKey(hashBytes), headerBytes)
 	batch.Put(hashToTDKey(hashBytes), common.SerializePanic(td))
 
 	if body != nil {
 		batch.Put(hashToBodyKey(hashBytes), common.SerializePanic(body))
+
+		// add the spent debts of the block no matter it is HEAD or not
+		if err := store.batchAddSpentDebts(batch, hash, header.Height, body.Debts); err != nil {
+			return err
+		}
 	}
 
 	if isHead {
@@ -200,7 +310,7 @@ func (store *blockchainDatabase) putBlockInternal(hash common.Hash, header *type
 		batch.Put(keyHeadBlockHash, hashBytes)
 	}
 
-	return batch.Commit()
+	return nil
 }
 
 // DeleteBlockHeader deletes the block header of the specified block hash.
@@ -212,7 +322,8 @@ func (store *blockchainDatabase) DeleteBlockHeader(hash common.Hash) error {
 	headerKey := hashToHeaderKey(hashBytes)
 	tdKey := hashToTDKey(hashBytes)
 	receiptsKey := hashToReceiptsKey(hashBytes)
-	if err := store.delete(batch, headerKey, tdKey, receiptsKey); err != nil {
+	logIndexKey := hashToLogIndexKey(hashBytes)
+	if err := store.delete(batch, headerKey, tdKey, receiptsKey, logIndexKey); err != nil {
 		return err
 	}
 
@@ -255,6 +366,49 @@ func (store *blockchainDatabase) PutBlock(block *types.Block, td *big.Int, isHea
 	return store.putBlockInternal(block.HeaderHash, block.Header, &blockBody{block.Transactions, block.Debts}, td, isHead)
 }
 
+// WriteBlock writes the block with the receipts, dirty accounts and state diff in a single batch,
+// so that the block is either written completely or not written at all.
+func (store *blockchainDatabase) WriteBlock(block *types.Block, td *big.Int, isHead bool, data *BlockData) error {
+	if block == nil {
+		panic("block is nil")
+	}
+
+	hashBytes := block.HeaderHash.Bytes()
+	batch := store.db.NewBatch()
+
+	receipts, err := common.Serialize(data.Receipts)
+	if err != nil {
+		return err
+	}
+	batch.Put(hashToReceiptsKey(hashBytes), receipts)
+
+	logIndex, err := store.newBlockLogIndex(batch, block.Header, data.Receipts)
+	if err != nil {
+		return err
+	}
+	batch.Put(hashToLogIndexKey(hashBytes), common.SerializePanic(logIndex))
+
+	accounts, err := common.Serialize(data.DirtyAccounts)
+	if err != nil {
+		return err
+	}
+	batch.Put(hashToDirtyAccountsKey(hashBytes), accounts)
+
+	if data.StateDiff != nil {
+		diffs, err := common.Serialize(data.StateDiff)
+		if err != nil {
+			return err
+		}
+		batch.Put(hashToStateDiffKey(hashBytes), diffs)
+	}
+
+	if err = store.batchPutBlock(batch, block.HeaderHash, block.Header, &blockBody{block.Transactions, block.Debts}, td, isHead); err != nil {
+		return err
+	}
+
+	return batch.Commit()
+}
+
 // GetBlock gets the block with the specified hash in the blockchain database
 func (store *blockchainDatabase) GetBlock(hash common.Hash) (*types.Block, error) {
 	header, err := store.GetBlockHeader(hash)
@@ -302,7 +456,8 @@ func (store *blockchainDatabase) DeleteBlock(hash common.Hash) error {
 	headerKey := hashToHeaderKey(hashBytes)
 	tdKey := hashToTDKey(hashBytes)
 	receiptsKey := hashToReceiptsKey(hashBytes)
-	if err := store.delete(batch, headerKey, tdKey, receiptsKey); err != nil {
+	logIndexKey := hashToLogIndexKey(hashBytes)
+	if err := store.delete(batch, headerKey, tdKey, receiptsKey, logIndexKey); err != nil {
 		return err
 	}
 
@@ -332,6 +487,11 @@ func (store *blockchainDatabase) DeleteBlock(hash common.Hash) error {
 		return err
 	}
 
+	// delete the spent debts of the block.
+	if err = store.batchDeleteSpentDebts(batch, hash, body.Debts); err != nil {
+		return err
+	}
+
 	// delete body
 	batch.Delete(bodyKey)
 
@@ -383,6 +543,10 @@ func (store *blockchainDatabase) PutReceipts(hash common.Hash, receipts []*types
 func (store *blockchainDatabase) GetReceiptsByBlockHash(hash common.Hash) ([]*types.Receipt, error) {
 	key := hashToReceiptsKey(hash.Bytes())
 	encodedBytes, err := store.db.Get(key)
+	if err == errors.ErrNotFound && store.isPruned(hash, keyPrunedHeight, keyReceiptsPrunedHeight) {
+		return nil, ErrReceiptsPruned
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -414,6 +578,62 @@ func (store *blockchainDatabase) GetReceiptByTxHash(txHash common.Hash) (*types.
 	return receipts[txIndex.Index], nil
 }
 
+// GetBlockLogIndex retrieves the log indices of the block for the specified block hash. The log indices
+// are kept when the receipts are pruned, so that the global log index keeps increasing along the chain.
+func (store *blockchainDatabase) GetBlockLogIndex(hash common.Hash) (*types.BlockLogIndex, error) {
+	data, err := store.db.Get(hashToLogIndexKey(hash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+
+	index := &types.BlockLogIndex{}
+	if err := common.Deserialize(data, index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// newBlockLogIndex returns the log indices of the block which follows the log indices of the parent block,
+// and the global log index starts from 0 if the parent is the genesis block or written before the log indices.
+// In the latter case, the height of the block is recorded in the batch as the log index start height once,
+// since the logs of the blocks before are not counted.
+func (store *blockchainDatabase) newBlockLogIndex(batch database.Batch, header *types.BlockHeader, receipts []*types.Receipt) (*types.BlockLogIndex, error) {
+	parent, err := store.GetBlockLogIndex(header.PreviousBlockHash)
+	if err == errors.ErrNotFound {
+		if header.Height > 1 {
+			if has, err := store.db.Has(keyLogIndexStartHeight); err != nil {
+				return nil, err
+			} else if !has {
+				batch.Put(keyLogIndexStartHeight, encodeBlockHeight(header.Height))
+			}
+		}
+
+		return types.NewBlockLogIndex(nil, receipts), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewBlockLogIndex(parent, receipts), nil
+}
+
+// GetLogIndexStartHeight retrieves the height from which the blocks have the log indices, which is 0 if the
+// chain is indexed since the genesis. The global log index does not count the logs of the blocks before.
+func (store *blockchainDatabase) GetLogIndexStartHeight() (uint64, error) {
+	value, err := store.db.Get(keyLogIndexStartHeight)
+	if err == errors.ErrNotFound {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint64(value), nil
+}
+
 // PutDirtyAccounts serializes given dirty accounts for the specified block hash.
 func (store *blockchainDatabase) PutDirtyAccounts(hash common.Hash, accounts []common.Address) error {
 	encodedBytes, err := common.Serialize(accounts)
@@ -430,6 +650,10 @@ func (store *blockchainDatabase) PutDirtyAccounts(hash common.Hash, accounts []c
 func (store *blockchainDatabase) GetDirtyAccountsByBlockHash(hash common.Hash) ([]common.Address, error) {
 	key := hashToDirtyAccountsKey(hash.Bytes())
 	encodedBytes, err := store.db.Get(key)
+	if err == errors.ErrNotFound && store.isPruned(hash, keyPrunedHeight, keyDirtyAccountsPrunedHeight) {
+		return nil, ErrDirtyAccountsPruned
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -442,6 +666,141 @@ func (store *blockchainDatabase) GetDirtyAccountsByBlockHash(hash common.Hash) (
 	return accounts, nil
 }
 
+// PruneBlockData deletes the receipts and dirty accounts of the specified blocks, and updates the pruned
+// height atomically. The block headers and bodies are kept. Returns the size in bytes of the deleted data.
+func (store *blockchainDatabase) PruneBlockData(hashes []common.Hash, prunedHeight uint64) (int, error) {
+	return store.prune(hashes, prunedHeight, keyPrunedHeight, hashToReceiptsKey, hashToDirtyAccountsKey)
+}
+
+// PruneReceipts deletes the receipts of the specified blocks, and updates the receipts pruned height atomically.
+// Returns the size in bytes of the deleted data.
+func (store *blockchainDatabase) PruneReceipts(hashes []common.Hash, prunedHeight uint64) (int, error) {
+	return store.prune(hashes, prunedHeight, keyReceiptsPrunedHeight, hashToReceiptsKey)
+}
+
+// PruneDirtyAccounts deletes the dirty accounts of the specified blocks, and updates the dirty accounts pruned
+// height atomically. Returns the size in bytes of the deleted data.
+func (store *blockchainDatabase) PruneDirtyAccounts(hashes []common.Hash, prunedHeight uint64) (int, error) {
+	return store.prune(hashes, prunedHeight, keyDirtyAccountsPrunedHeight, hashToDirtyAccountsKey)
+}
+
+// prune deletes the keys of the specified blocks and writes the pruned height in a batch
+func (store *blockchainDatabase) prune(hashes []common.Hash, prunedHeight uint64, heightKey []byte, keyFuncs ...func([]byte) []byte) (int, error) {
+	batch := store.db.NewBatch()
+	size := 0
+
+	for _, hash := range hashes {
+		for _, keyFunc := range keyFuncs {
+			key := keyFunc(hash.Bytes())
+			value, err := store.db.Get(key)
+			if err == errors.ErrNotFound {
+				continue
+			}
+
+			if err != nil {
+				return 0, err
+			}
+
+			size += len(key) + len(value)
+			batch.Delete(key)
+		}
+	}
+
+	batch.Put(heightKey, encodeBlockHeight(prunedHeight))
+
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// GetPrunedHeight retrieves the height up to which the receipts and dirty accounts are pruned.
+func (store *blockchainDatabase) GetPrunedHeight() (uint64, error) {
+	return store.getPrunedHeight(keyPrunedHeight)
+}
+
+// GetReceiptsPrunedHeight retrieves the height up to which the receipts are pruned, either by the disk quota
+// or the retention policy.
+func (store *blockchainDatabase) GetReceiptsPrunedHeight() (uint64, error) {
+	return store.getPrunedHeight(keyPrunedHeight, keyReceiptsPrunedHeight)
+}
+
+// GetDirtyAccountsPrunedHeight retrieves the height up to which the dirty accounts are pruned, either by the
+// disk quota or the retention policy.
+func (store *blockchainDatabase) GetDirtyAccountsPrunedHeight() (uint64, error) {
+	return store.getPrunedHeight(keyPrunedHeight, keyDirtyAccountsPrunedHeight)
+}
+
+// getPrunedHeight returns the max pruned height of the keys, or ErrNotFound if never pruned
+func (store *blockchainDatabase) getPrunedHeight(keys ...[]byte) (uint64, error) {
+	var height uint64
+	found := false
+
+	for _, key := range keys {
+		value, err := store.db.Get(key)
+		if err == errors.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		if h := binary.BigEndian.Uint64(value); !found || h > height {
+			height = h
+		}
+
+		found = true
+	}
+
+	if !found {
+		return 0, errors.ErrNotFound
+	}
+
+	return height, nil
+}
+
+// isPruned returns whether the block is at or below the pruned height of the keys
+func (store *blockchainDatabase) isPruned(hash common.Hash, keys ...[]byte) bool {
+	prunedHeight, err := store.getPrunedHeight(keys...)
+	if err != nil {
+		return false
+	}
+
+	header, err := store.GetBlockHeader(hash)
+	if err != nil {
+		return false
+	}
+
+	return header.Height <= prunedHeight
+}
+
+// PutStateDiff serializes given account diffs for the specified block hash.
+func (store *blockchainDatabase) PutStateDiff(hash common.Hash, diffs []*types.AccountDiff) error {
+	encodedBytes, err := common.Serialize(diffs)
+	if err != nil {
+		return err
+	}
+
+	return store.db.Put(hashToStateDiffKey(hash.Bytes()), encodedBytes)
+}
+
+// GetStateDiff retrieves the account diffs for the specified block hash.
+func (store *blockchainDatabase) GetStateDiff(hash common.Hash) ([]*types.AccountDiff, error) {
+	encodedBytes, err := store.db.Get(hashToStateDiffKey(hash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]*types.AccountDiff, 0)
+	if err := common.Deserialize(encodedBytes, &diffs); err != nil {
+		return nil, err
+	}
+
+	return diffs, nil
+}
+
 // AddIndices adds tx/debt indices for the specified block.
 func (store *blockchainDatabase) AddIndices(block *types.Block) error {
 	batch := store.db.NewBatch()
@@ -529,3 +888,76 @@ func (store *blockchainDatabase) batchDeleteIndices(batch database.Batch, blockH
 
 	return nil
 }
+
+// GetSpentDebts retrieves the blocks which apply the specified debt in all forks,
+// returns empty if the debt is not applied by any block.
+func (store *blockchainDatabase) GetSpentDebts(debtHash common.Hash) ([]*types.SpentDebt, error) {
+	data, err := store.db.Get(debtHashToSpentKey(debtHash.Bytes()))
+	if err == errors.ErrNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	spents := make([]*types.SpentDebt, 0)
+	if err := common.Deserialize(data, &spents); err != nil {
+		return nil, err
+	}
+
+	return spents, nil
+}
+
+// batchAddSpentDebts adds the block into the spent debts of the specified debts
+func (store *blockchainDatabase) batchAddSpentDebts(batch database.Batch, blockHash common.Hash, height uint64, debts []*types.Debt) error {
+	for _, debt := range debts {
+		spents, err := store.GetSpentDebts(debt.Hash)
+		if err != nil {
+			return err
+		}
+
+		if indexOfSpentDebt(spents, blockHash) >= 0 {
+			continue
+		}
+
+		spents = append(spents, &types.SpentDebt{BlockHash: blockHash, BlockHeight: height})
+		batch.Put(debtHashToSpentKey(debt.Hash.Bytes()), common.SerializePanic(spents))
+	}
+
+	return nil
+}
+
+// batchDeleteSpentDebts deletes the block from the spent debts of the specified debts
+func (store *blockchainDatabase) batchDeleteSpentDebts(batch database.Batch, blockHash common.Hash, debts []*types.Debt) error {
+	for _, debt := range debts {
+		spents, err := store.GetSpentDebts(debt.Hash)
+		if err != nil {
+			return err
+		}
+
+		i := indexOfSpentDebt(spents, blockHash)
+		if i < 0 {
+			continue
+		}
+
+		key := debtHashToSpentKey(debt.Hash.Bytes())
+		if spents = append(spents[:i], spents[i+1:]...); len(spents) == 0 {
+			batch.Delete(key)
+		} else {
+			batch.Put(key, common.SerializePanic(spents))
+		}
+	}
+
+	return nil
+}
+
+func indexOfSpentDebt(spents []*types.SpentDebt, blockHash common.Hash) int {
+	for i, spent := range spents {
+		if spent.BlockHash.Equal(blockHash) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -6,12 +6,28 @@
 package store
 
 import (
+	"errors"
 	"math/big"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/core/types"
 )
 
+var (
+	// ErrReceiptsPruned is returned when the receipts of the block are pruned by the disk quota or retention policy
+	ErrReceiptsPruned = errors.New("receipts of the block are pruned")
+
+	// ErrDirtyAccountsPruned is returned when the dirty accounts of the block are pruned by the disk quota or retention policy
+	ErrDirtyAccountsPruned = errors.New("dirty accounts of the block are pruned")
+)
+
+// BlockData is the data of a block written with the block atomically.
+type BlockData struct {
+	Receipts      []*types.Receipt
+	DirtyAccounts []common.Address
+	StateDiff     []*types.AccountDiff // nil if the state diff is not computed, which is not stored then
+}
+
 // BlockchainStore is the interface that wraps the atomic CRUD methods of blockchain.
 type BlockchainStore interface {
 	// GetBlockHash retrieves the block hash for the specified canonical block height.
@@ -29,6 +45,12 @@ type BlockchainStore interface {
 	// PutHeadBlockHash writes the HEAD block hash into the store.
 	PutHeadBlockHash(hash common.Hash) error
 
+	// GetChainConfig retrieves the chain config.
+	GetChainConfig() (*common.ChainConfig, error)
+
+	// PutChainConfig writes the chain config into the store.
+	PutChainConfig(config *common.ChainConfig) error
+
 	// GetBlockHeader retrieves the block header for the specified block hash.
 	GetBlockHeader(hash common.Hash) (*types.BlockHeader, error)
 
@@ -46,6 +68,10 @@ type BlockchainStore interface {
 	// The input parameter isHead indicates if the given block is a HEAD block.
 	PutBlock(block *types.Block, td *big.Int, isHead bool) error
 
+	// WriteBlock writes the given block with the receipts, dirty accounts and state diff in data
+	// into the store atomically, in the same way as PutBlock.
+	WriteBlock(block *types.Block, td *big.Int, isHead bool, data *BlockData) error
+
 	// GetBlock retrieves the block for the specified block hash.
 	GetBlock(hash common.Hash) (*types.Block, error)
 
@@ -70,12 +96,44 @@ type BlockchainStore interface {
 	// GetReceiptByTxHash retrieves the receipt for the specified tx hash.
 	GetReceiptByTxHash(txHash common.Hash) (*types.Receipt, error)
 
+	// GetBlockLogIndex retrieves the log indices of the block for the specified block hash.
+	GetBlockLogIndex(hash common.Hash) (*types.BlockLogIndex, error)
+
+	// GetLogIndexStartHeight retrieves the height from which the blocks have the log indices.
+	GetLogIndexStartHeight() (uint64, error)
+
 	// PutDirtyAccounts serializes given dirty accounts for the specified block hash.
 	PutDirtyAccounts(hash common.Hash, accounts []common.Address) error
 
 	// GetDirtyAccountsByBlockHash retrieves the receipts for the specified block hash.
 	GetDirtyAccountsByBlockHash(hash common.Hash) ([]common.Address, error)
 
+	// PruneBlockData deletes the receipts and dirty accounts of the specified blocks, and updates the pruned
+	// height atomically. The block headers and bodies are kept. Returns the size in bytes of the deleted data.
+	PruneBlockData(hashes []common.Hash, prunedHeight uint64) (int, error)
+
+	// GetPrunedHeight retrieves the height up to which the receipts and dirty accounts are pruned.
+	GetPrunedHeight() (uint64, error)
+
+	// PruneReceipts deletes the receipts of the specified blocks, and updates the receipts pruned height atomically.
+	PruneReceipts(hashes []common.Hash, prunedHeight uint64) (int, error)
+
+	// GetReceiptsPrunedHeight retrieves the height up to which the receipts are pruned.
+	GetReceiptsPrunedHeight() (uint64, error)
+
+	// PruneDirtyAccounts deletes the dirty accounts of the specified blocks, and updates the dirty accounts
+	// pruned height atomically.
+	PruneDirtyAccounts(hashes []common.Hash, prunedHeight uint64) (int, error)
+
+	// GetDirtyAccountsPrunedHeight retrieves the height up to which the dirty accounts are pruned.
+	GetDirtyAccountsPrunedHeight() (uint64, error)
+
+	// PutStateDiff serializes given account diffs for the specified block hash.
+	PutStateDiff(hash common.Hash, diffs []*types.AccountDiff) error
+
+	// GetStateDiff retrieves the account diffs for the specified block hash.
+	GetStateDiff(hash common.Hash) ([]*types.AccountDiff, error)
+
 	// AddIndices addes tx/debt indices for the specified block.
 	AddIndices(block *types.Block) error
 
@@ -87,4 +145,7 @@ type BlockchainStore interface {
 
 	// DeleteIndices deletes tx/debt indices of the specified block.
 	DeleteIndices(block *types.Block) error
+
+	// GetSpentDebts retrieves the blocks which apply the specified debt in all forks.
+	GetSpentDebts(debtHash common.Hash) ([]*types.SpentDebt, error)
 }
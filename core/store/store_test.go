@@ -309,3 +309,368 @@ func Test_blockchainDatabase_DeleteIndices_BlockHashMismatch(t *testing.T) {
 	debtIdx2, _ := bcStore.GetDebtIndex(debts[2].Hash)
 	assert.Equal(t, debtIdx2.BlockHash, common.StringToHash("block 2"))
 }
+
+func Test_blockchainDatabase_SpentDebts(t *testing.T) {
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	// debt not spent
+	spents, err := bcStore.GetSpentDebts(common.StringToHash("debt"))
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(spents), 0)
+
+	// the same debts applied by blocks in 2 forks
+	block1 := newTestFullBlock(3, 3)
+	assert.Nil(t, bcStore.PutBlock(block1, block1.Header.Difficulty, true))
+
+	block2 := &types.Block{
+		HeaderHash: common.StringToHash("block 2"),
+		Header:     block1.Header.Clone(),
+		Debts:      block1.Debts,
+	}
+	block2.Header.Height = 2
+	assert.Nil(t, bcStore.PutBlock(block2, block2.Header.Difficulty, false))
+
+	for _, d := range block1.Debts {
+		spents, err := bcStore.GetSpentDebts(d.Hash)
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, spents, []*types.SpentDebt{
+			{BlockHash: block1.HeaderHash, BlockHeight: 1},
+			{BlockHash: block2.HeaderHash, BlockHeight: 2},
+		})
+	}
+
+	// delete the block in fork
+	assert.Nil(t, bcStore.DeleteBlock(block2.HeaderHash))
+	spents, err = bcStore.GetSpentDebts(block1.Debts[0].Hash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, spents, []*types.SpentDebt{{BlockHash: block1.HeaderHash, BlockHeight: 1}})
+
+	assert.Nil(t, bcStore.DeleteBlock(block1.HeaderHash))
+	spents, err = bcStore.GetSpentDebts(block1.Debts[0].Hash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(spents), 0)
+}
+
+func newTestBlockData(block *types.Block) *BlockData {
+	data := &BlockData{}
+	for _, tx := range block.Transactions {
+		data.Receipts = append(data.Receipts, &types.Receipt{TxHash: tx.Hash})
+		data.DirtyAccounts = append(data.DirtyAccounts, tx.Data.From)
+		data.StateDiff = append(data.StateDiff, &types.AccountDiff{
+			Address:       tx.Data.From,
+			BalanceBefore: big.NewInt(100),
+			BalanceAfter:  big.NewInt(99),
+			NonceAfter:    1,
+			Storage:       []*types.StorageDiff{},
+		})
+	}
+
+	return data
+}
+
+func Test_blockchainDatabase_WriteBlock(t *testing.T) {
+	block := newTestFullBlock(3, 3)
+	data := newTestBlockData(block)
+
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	err := bcStore.WriteBlock(block, block.Header.Difficulty, true, data)
+	assert.Equal(t, err, nil)
+
+	storedBlock, err := bcStore.GetBlock(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, storedBlock, block)
+
+	hash, err := bcStore.GetHeadBlockHash()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, hash, block.HeaderHash)
+
+	receipts, err := bcStore.GetReceiptsByBlockHash(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(receipts), 3)
+
+	receipt, err := bcStore.GetReceiptByTxHash(block.Transactions[1].Hash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.TxHash, block.Transactions[1].Hash)
+
+	accounts, err := bcStore.GetDirtyAccountsByBlockHash(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, accounts, data.DirtyAccounts)
+
+	diffs, err := bcStore.GetStateDiff(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, diffs, data.StateDiff)
+}
+
+func Test_blockchainDatabase_WriteBlock_NoStateDiff(t *testing.T) {
+	block := newTestFullBlock(3, 3)
+	data := newTestBlockData(block)
+	data.StateDiff = nil
+
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	assert.Equal(t, bcStore.WriteBlock(block, block.Header.Difficulty, true, data), nil)
+
+	_, err := bcStore.GetStateDiff(block.HeaderHash)
+	assert.NotNil(t, err)
+
+	// computed but no account changed
+	assert.Equal(t, bcStore.PutStateDiff(block.HeaderHash, make([]*types.AccountDiff, 0)), nil)
+	diffs, err := bcStore.GetStateDiff(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(diffs), 0)
+}
+
+func Test_blockchainDatabase_ChainConfig(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := NewBlockchainDatabase(db)
+
+	config := common.DefaultChainConfig()
+	config.MetaTxRelayForkHeight = 100
+	config.ZpowParams = []common.ZpowParams{{ForkHeight: 200, MatrixDim: 20, Multiplier: 1, MaxTarget: big.NewInt(1000)}}
+	assert.Equal(t, bcStore.PutChainConfig(config), nil)
+
+	stored, err := bcStore.GetChainConfig()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored, config)
+
+	// the fields added later keep the main network values
+	assert.Equal(t, db.Put(keyChainConfig, []byte(`{"emeryForkHeight":10,"chainId":2}`)), nil)
+	stored, err = bcStore.GetChainConfig()
+	assert.Equal(t, err, nil)
+	expected := common.DefaultChainConfig()
+	expected.EmeryForkHeight = 10
+	expected.ChainID = 2
+	assert.Equal(t, stored, expected)
+}
+
+func Test_blockchainDatabase_LegacyChainConfig(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := NewBlockchainDatabase(db)
+
+	// the chain config stored as an RLP list by the previous versions
+	legacy := &legacyChainConfig{
+		EmeryForkHeight:       10,
+		SecondForkHeight:      20,
+		MetaTxRelayForkHeight: 100,
+		ChainID:               2,
+	}
+	assert.Equal(t, db.Put(keyChainConfig, common.SerializePanic(legacy)), nil)
+
+	stored, err := bcStore.GetChainConfig()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored, &common.ChainConfig{
+		EmeryForkHeight:       10,
+		SecondForkHeight:      20,
+		MetaTxRelayForkHeight: 100,
+		ChainID:               2,
+	})
+
+	legacy.ZpowParams = []common.ZpowParams{{ForkHeight: 200, MatrixDim: 20, Multiplier: 1, MaxTarget: big.NewInt(1000)}}
+	assert.Equal(t, db.Put(keyChainConfig, common.SerializePanic(legacy)), nil)
+
+	stored, err = bcStore.GetChainConfig()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.ZpowParams, legacy.ZpowParams)
+
+	// rewritten as JSON
+	assert.Equal(t, bcStore.PutChainConfig(stored), nil)
+	data, err := db.Get(keyChainConfig)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, data[0], byte('{'))
+}
+
+func Test_blockchainDatabase_BlockLogIndex(t *testing.T) {
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	// the global log index starts from 0 without the parent log index
+	parent := newTestFullBlock(0, 2)
+	data := newTestBlockData(parent)
+	data.Receipts[0].Logs = []*types.Log{&types.Log{}, &types.Log{}}
+	data.Receipts[1].Logs = []*types.Log{&types.Log{}}
+	assert.Equal(t, bcStore.WriteBlock(parent, parent.Header.Difficulty, true, data), nil)
+
+	index, err := bcStore.GetBlockLogIndex(parent.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *index, types.BlockLogIndex{First: 0, Count: 3})
+
+	// the chain is indexed since the genesis
+	height, err := bcStore.GetLogIndexStartHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, uint64(0))
+
+	block := newTestFullBlock(0, 1)
+	block.Header.PreviousBlockHash = parent.HeaderHash
+	block.HeaderHash = block.Header.Hash()
+	data = newTestBlockData(block)
+	data.Receipts[0].Logs = []*types.Log{&types.Log{}}
+	assert.Equal(t, bcStore.WriteBlock(block, block.Header.Difficulty, true, data), nil)
+
+	index, err = bcStore.GetBlockLogIndex(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *index, types.BlockLogIndex{First: 3, Count: 1})
+
+	// the log index is kept when the receipts are pruned
+	_, err = bcStore.PruneReceipts([]common.Hash{parent.HeaderHash}, parent.Header.Height)
+	assert.Equal(t, err, nil)
+	_, err = bcStore.GetBlockLogIndex(parent.HeaderHash)
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, bcStore.DeleteBlock(block.HeaderHash), nil)
+	_, err = bcStore.GetBlockLogIndex(block.HeaderHash)
+	assert.Equal(t, err, errors.ErrNotFound)
+}
+
+func Test_blockchainDatabase_LogIndexStartHeight(t *testing.T) {
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	// the parent is written before the log indices
+	block := newTestFullBlock(0, 1)
+	block.Header.Height = 10
+	block.HeaderHash = block.Header.Hash()
+	data := newTestBlockData(block)
+	data.Receipts[0].Logs = []*types.Log{&types.Log{}}
+	assert.Equal(t, bcStore.WriteBlock(block, block.Header.Difficulty, true, data), nil)
+
+	index, err := bcStore.GetBlockLogIndex(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *index, types.BlockLogIndex{First: 0, Count: 1})
+
+	height, err := bcStore.GetLogIndexStartHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, uint64(10))
+
+	// recorded once
+	other := newTestFullBlock(0, 1)
+	other.Header.Height = 12
+	other.HeaderHash = other.Header.Hash()
+	assert.Equal(t, bcStore.WriteBlock(other, other.Header.Difficulty, true, newTestBlockData(other)), nil)
+
+	height, err = bcStore.GetLogIndexStartHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, uint64(10))
+}
+
+func Test_blockchainDatabase_PruneBlockData(t *testing.T) {
+	block := newTestFullBlock(3, 3)
+	data := newTestBlockData(block)
+
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	assert.Equal(t, bcStore.WriteBlock(block, block.Header.Difficulty, true, data), nil)
+
+	_, err := bcStore.GetPrunedHeight()
+	assert.Equal(t, err, errors.ErrNotFound)
+
+	size, err := bcStore.PruneBlockData([]common.Hash{block.HeaderHash}, block.Header.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, size > 0, true)
+
+	height, err := bcStore.GetPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, block.Header.Height)
+
+	_, err = bcStore.GetReceiptsByBlockHash(block.HeaderHash)
+	assert.Equal(t, err, ErrReceiptsPruned)
+	_, err = bcStore.GetDirtyAccountsByBlockHash(block.HeaderHash)
+	assert.Equal(t, err, ErrDirtyAccountsPruned)
+
+	// the header, body and state diff are kept
+	storedBlock, err := bcStore.GetBlock(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, storedBlock, block)
+	diffs, err := bcStore.GetStateDiff(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, diffs, data.StateDiff)
+
+	// nothing to prune again
+	size, err = bcStore.PruneBlockData([]common.Hash{block.HeaderHash}, block.Header.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, size, 0)
+}
+
+func Test_blockchainDatabase_PruneReceipts(t *testing.T) {
+	block := newTestFullBlock(3, 3)
+	data := newTestBlockData(block)
+
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	assert.Equal(t, bcStore.WriteBlock(block, block.Header.Difficulty, true, data), nil)
+
+	_, err := bcStore.GetReceiptsPrunedHeight()
+	assert.Equal(t, err, errors.ErrNotFound)
+
+	size, err := bcStore.PruneReceipts([]common.Hash{block.HeaderHash}, block.Header.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, size > 0, true)
+
+	height, err := bcStore.GetReceiptsPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, block.Header.Height)
+
+	_, err = bcStore.GetReceiptsByBlockHash(block.HeaderHash)
+	assert.Equal(t, err, ErrReceiptsPruned)
+	_, err = bcStore.GetReceiptByTxHash(block.Transactions[1].Hash)
+	assert.Equal(t, err, ErrReceiptsPruned)
+
+	// the dirty accounts are kept
+	_, err = bcStore.GetDirtyAccountsPrunedHeight()
+	assert.Equal(t, err, errors.ErrNotFound)
+	accounts, err := bcStore.GetDirtyAccountsByBlockHash(block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, accounts, data.DirtyAccounts)
+
+	// the disk quota pruned height applies to both
+	_, err = bcStore.PruneBlockData(nil, block.Header.Height+10)
+	assert.Equal(t, err, nil)
+	height, err = bcStore.GetDirtyAccountsPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, block.Header.Height+10)
+	height, err = bcStore.GetReceiptsPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, block.Header.Height+10)
+}
+
+func benchmarkBlocks(b *testing.B) []*types.Block {
+	blocks := make([]*types.Block, b.N)
+	for i := range blocks {
+		blocks[i] = newTestFullBlock(3, 100)
+	}
+
+	b.ResetTimer()
+
+	return blocks
+}
+
+func Benchmark_blockchainDatabase_SeparatePuts(b *testing.B) {
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	for _, block := range benchmarkBlocks(b) {
+		data := newTestBlockData(block)
+		bcStore.PutReceipts(block.HeaderHash, data.Receipts)
+		bcStore.PutBlock(block, block.Header.Difficulty, true)
+		bcStore.PutDirtyAccounts(block.HeaderHash, data.DirtyAccounts)
+		bcStore.PutStateDiff(block.HeaderHash, data.StateDiff)
+	}
+}
+
+func Benchmark_blockchainDatabase_WriteBlock(b *testing.B) {
+	bcStore, dispose := newTestBlockchainDatabase()
+	defer dispose()
+
+	for _, block := range benchmarkBlocks(b) {
+		bcStore.WriteBlock(block, block.Header.Difficulty, true, newTestBlockData(block))
+	}
+}
@@ -18,6 +18,13 @@ import (
 // NewEVMByDefaultConfig returns a new EVM. The returned EVM is not thread safe and should
 // only ever be used *once*.
 func NewEVMByDefaultConfig(tx *types.Transaction, statedb *StateDB, blockHeader *types.BlockHeader, bcStore store.BlockchainStore) *vm.EVM {
+	return NewEVM(tx, statedb, blockHeader, bcStore, common.DefaultChainConfig(), nil)
+}
+
+// NewEVM returns a new EVM with the fork heights of the chain config, and the execution is traced
+// by the tracer if it is not nil.
+func NewEVM(tx *types.Transaction, statedb *StateDB, blockHeader *types.BlockHeader, bcStore store.BlockchainStore,
+	config *common.ChainConfig, tracer vm.Tracer) *vm.EVM {
 	evmContext := newEVMContext(tx, blockHeader, blockHeader.Creator, bcStore)
 	chainConfig := &params.ChainConfig{
 		ChainID:             big.NewInt(1),
@@ -28,13 +35,22 @@ func NewEVMByDefaultConfig(tx *types.Transaction, statedb *StateDB, blockHeader
 		EIP155Block:         big.NewInt(0),
 		EIP158Block:         big.NewInt(0),
 		ByzantiumBlock:      big.NewInt(0),
-		ConstantinopleBlock: big.NewInt(int64(common.EmeryForkHeight)),
-		IstanbulBlock:       big.NewInt(int64(common.EmeryForkHeight)),
+		ConstantinopleBlock: new(big.Int).SetUint64(config.EmeryForkHeight),
+		IstanbulBlock:       new(big.Int).SetUint64(config.EmeryForkHeight),
 		Ethash:              new(params.EthashConfig),
 	}
-	vmConfig := &vm.Config{}
+	vmConfig := &vm.Config{
+		EIP2929: config.IsAccessList(blockHeader.Height),
+	}
+	if tracer != nil {
+		vmConfig.Debug = true
+		vmConfig.Tracer = tracer
+	}
+
+	e := vm.NewEVM(*evmContext, statedb, chainConfig, *vmConfig)
+	e.PrepareAccessList(tx.Data.From, tx.Data.To, tx.Data.AccessList)
 
-	return vm.NewEVM(*evmContext, statedb, chainConfig, *vmConfig)
+	return e
 }
 
 // NewEVMContext creates a new context for use in the EVM.
@@ -7,13 +7,17 @@ package evm
 
 import (
 	"math/big"
+	"testing"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/hexutil"
 	"github.com/scdoproject/go-scdo/core/state"
 	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/core/vm"
 	"github.com/scdoproject/go-scdo/crypto"
 	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
 )
 
 //////////////////////////////////////////////////////////////////////////////////////////////////
@@ -52,3 +56,33 @@ func preprocessContract(balance, nonce uint64) (*state.Statedb, store.Blockchain
 		dispose()
 	}
 }
+
+func Test_Create2(t *testing.T) {
+	statedb, bcStore, from, dispose := preprocessContract(1000000000, 0)
+	defer dispose()
+
+	// child init code that returns the runtime code 0x01
+	childCode := mustHexToBytes("0x600160005360016000f3")
+
+	// factory init code that deploys the child by CREATE2 with salt 0x2a:
+	// PUSH10 childCode, PUSH1 0, MSTORE, PUSH1 0x2a, PUSH1 10, PUSH1 22, PUSH1 0, CREATE2, STOP
+	factoryCode := mustHexToBytes("0x69600160005360016000f3600052602a600a60166000f500")
+
+	tx, err := types.NewContractTransaction(from, big.NewInt(0), big.NewInt(1), 1000000, 0, factoryCode)
+	assert.Equal(t, err, nil)
+
+	header := &types.BlockHeader{
+		Creator:         from,
+		Height:          common.EmeryForkHeight,
+		CreateTimestamp: big.NewInt(1),
+		Difficulty:      big.NewInt(1),
+	}
+
+	evm := NewEVMByDefaultConfig(tx, &StateDB{statedb}, header, bcStore)
+	_, factory, _, err := evm.Create(vm.AccountRef(from), factoryCode, tx.Data.GasLimit, big.NewInt(0))
+	assert.Equal(t, err, nil)
+
+	child := crypto.CreateAddress2(factory, common.BigToHash(big.NewInt(0x2a)), crypto.Keccak256Hash(childCode).Bytes())
+	assert.Equal(t, child.Shard(), from.Shard())
+	assert.Equal(t, statedb.GetCode(child), []byte{0x01})
+}
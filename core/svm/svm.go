@@ -6,6 +6,7 @@
 package svm
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -19,6 +20,9 @@ import (
 	"github.com/scdoproject/go-scdo/core/vm"
 )
 
+// ErrExecutionAborted is returned when the evm execution is aborted by the cancelled context
+var ErrExecutionAborted = errors.New("evm execution aborted")
+
 // Context for other vm constructs
 type Context struct {
 	Tx          *types.Transaction
@@ -26,6 +30,33 @@ type Context struct {
 	Statedb     *state.Statedb
 	BlockHeader *types.BlockHeader
 	BcStore     store.BlockchainStore
+
+	// Ctx aborts the evm execution once it is cancelled, e.g. the rpc call timeout. nil means never abort.
+	Ctx context.Context
+
+	// Tracer traces the evm execution, e.g. when replaying a block for debug. nil means not traced.
+	Tracer vm.Tracer
+
+	// ChainConfig is the fork activation heights of the chain. nil means the config of the main network.
+	ChainConfig *common.ChainConfig
+}
+
+func (ctx *Context) chainConfig() *common.ChainConfig {
+	if ctx.ChainConfig == nil {
+		return common.DefaultChainConfig()
+	}
+
+	return ctx.ChainConfig
+}
+
+// refundQuotient caps the refund to the used gas divided by it
+const refundQuotient = 2
+
+// auditRefund records the refund applied to the tx if it is traced by a refund tracer, e.g. the gas audit
+func auditRefund(ctx *Context, path string, intrinsicGas, usedGas, quotient, applied uint64) {
+	if tracer, ok := ctx.Tracer.(vm.RefundTracer); ok {
+		tracer.CaptureRefund(path, intrinsicGas, usedGas, ctx.Statedb.GetRefund(), quotient, applied)
+	}
 }
 
 // Process the tx. If it is called by api.estimateGas to ge the gas usage estimate, ctx.TxIndex is set to be 0.
@@ -44,13 +75,13 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 		ctx.TxIndex = 0
 		getEstGas = true
 	}
-	if err := ctx.Tx.ValidateState(ctx.Statedb, height); err != nil {
+	if err := ctx.Tx.ValidateState(ctx.Statedb, ctx.chainConfig(), height); err != nil {
 		s = fmt.Sprintf("gasLimit= %d, IntriinsicGas= %d", gasLimit, intrGas)
 		return nil, errors.NewStackedError(err, s+"failed to validate tx against statedb")
 	}
 	snapshot := ctx.Statedb.Prepare(ctx.TxIndex)
 
-	contract := system.GetContractByAddress(ctx.Tx.Data.To)
+	contract := system.GetContractAt(ctx.Tx.Data.To, ctx.chainConfig(), height)
 
 	var leftOverGas = gasLimit - intrGas
 	if leftOverGas < 0 && !getEstGas { //this happen if the tx is a normal transaction and not esitmate, then return more accurate message --including input gas limit and possible transaction cost -IntriinsicGas
@@ -68,7 +99,9 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 	// init statedb and set snapshot
 
 	// create or execute contract
-	if contract != nil { // system contract
+	if err = checkContractAccess(ctx, contract); err != nil { // denied in the permissioned networks
+		receipt = &types.Receipt{TxHash: ctx.Tx.Hash}
+	} else if contract != nil { // system contract
 		receipt, err = processSystemContract(ctx, contract, snapshot, leftOverGas)
 	} else if ctx.Tx.IsCrossShardTx() && !ctx.Tx.Data.To.IsEVMContract() { // cross shard tx
 		receipt, err = processCrossShardTransaction(ctx, snapshot)
@@ -76,10 +109,16 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 			err = errors.NewStackedError(err, s)
 		}
 		if !getEstGas {
+			if receipt != nil {
+				auditRefund(ctx, vm.RefundPathCrossShard, intrGas, receipt.UsedGas, 0, 0)
+			}
 			return receipt, err
 		}
 	} else { // evm
 		receipt, err = processEvmContract(ctx, leftOverGas, height)
+		if ctx.Ctx != nil && ctx.Ctx.Err() != nil {
+			return nil, revertStatedb(ctx.Statedb, snapshot, errors.NewStackedErrorf(ErrExecutionAborted, "failed to execute evm, %s", ctx.Ctx.Err()))
+		}
 	}
 
 	// account balance is not enough (account.balance < tx.amount)
@@ -89,7 +128,7 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 
 	if err != nil {
 
-		if height <= common.SmartContractNonceForkHeight {
+		if !ctx.chainConfig().IsSmartContractNonceFork(height) {
 			// smart contract OLD logic
 			ctx.Statedb.RevertToSnapshot(snapshot)
 			receipt.Failed = true
@@ -116,14 +155,21 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 	// include the intrinsic gas
 	receipt.UsedGas += intrGas
 
-	// refund gas, capped to 5th of the used gas if no error.
+	// refund gas, capped to half of the used gas.
 	refund := ctx.Statedb.GetRefund()
 	if getEstGas {
 		//no refund
+		auditRefund(ctx, vm.RefundPathEstimate, intrGas, receipt.UsedGas, 0, 0)
 	} else {
-		if maxRefund := receipt.UsedGas / 2; refund > maxRefund {
+		if maxRefund := receipt.UsedGas / refundQuotient; refund > maxRefund {
 			refund = maxRefund
 		}
+
+		path := vm.RefundPathEvm
+		if contract != nil {
+			path = vm.RefundPathSystemContract
+		}
+		auditRefund(ctx, path, intrGas, receipt.UsedGas, refundQuotient, refund)
 	}
 
 	if getEstGas { // if it is to get the estimate of gas usage, no refund but add 5% more to avoid giving a lower estimate than the actual used gas.
@@ -135,6 +181,28 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 	return handleFee(ctx, receipt, snapshot)
 }
 
+// checkContractAccess checks whether the sender is allowed to deploy the contract or call the contract by the
+// access control system contract in the permissioned networks of BFT consensus which activate the access control
+// fork, and it is bypassed in the other networks. The access control contract itself is always callable, so that
+// the validators are never locked out.
+func checkContractAccess(ctx *Context, contract system.Contract) error {
+	to := ctx.Tx.Data.To
+	if ctx.BlockHeader.Consensus != types.IstanbulConsensus || !ctx.chainConfig().IsAccessControl(ctx.BlockHeader.Height) ||
+		to == system.AccessControlContractAddress {
+		return nil
+	}
+
+	if to.IsEmpty() {
+		return system.CheckAccess(ctx.Statedb, system.AccessDeploy, common.EmptyAddress, ctx.Tx.Data.From)
+	}
+
+	if contract != nil || to.IsEVMContract() {
+		return system.CheckAccess(ctx.Statedb, system.AccessCall, to, ctx.Tx.Data.From)
+	}
+
+	return nil
+}
+
 // processCrossShardTransaction processes the cross-shard tx
 func processCrossShardTransaction(ctx *Context, snapshot int) (*types.Receipt, error) {
 	receipt := &types.Receipt{
@@ -165,6 +233,8 @@ func processCrossShardTransaction(ctx *Context, snapshot int) (*types.Receipt, e
 	minerFee := new(big.Int).Mul(ctx.Tx.Data.GasPrice, new(big.Int).SetUint64(types.CrossShardTransactionGas))
 	ctx.Statedb.AddBalance(ctx.BlockHeader.Creator, minerFee)
 
+	cleanupState(ctx)
+
 	// Record statedb hash
 	var err error
 	if receipt.PostState, err = ctx.Statedb.Hash(); err != nil {
@@ -198,13 +268,16 @@ func processSystemContract(ctx *Context, contract system.Contract, snapshot int,
 	ctx.Statedb.SubBalance(sender, amount)
 	ctx.Statedb.AddBalance(recipient, amount)
 
+	sysContext := system.NewContext(ctx.Tx, ctx.Statedb, ctx.BlockHeader)
+	sysContext.ChainConfig = ctx.chainConfig()
+
 	// Check used gas is over flow
-	receipt.UsedGas = contract.RequiredGas(ctx.Tx.Data.Payload)
+	receipt.UsedGas = contract.RequiredGas(ctx.Tx.Data.Payload, sysContext)
 	if receipt.UsedGas > leftOverGas {
 		return receipt, vm.ErrOutOfGas
 	}
 	// Run
-	receipt.Result, err = contract.Run(ctx.Tx.Data.Payload, system.NewContext(ctx.Tx, ctx.Statedb, ctx.BlockHeader))
+	receipt.Result, err = contract.Run(ctx.Tx.Data.Payload, sysContext)
 
 	return receipt, err
 }
@@ -217,10 +290,23 @@ func processEvmContract(ctx *Context, gas uint64, height uint64) (*types.Receipt
 	}
 
 	statedb := &evm.StateDB{Statedb: ctx.Statedb}
-	e := evm.NewEVMByDefaultConfig(ctx.Tx, statedb, ctx.BlockHeader, ctx.BcStore)
+	e := evm.NewEVM(ctx.Tx, statedb, ctx.BlockHeader, ctx.BcStore, ctx.chainConfig(), ctx.Tracer)
 	caller := vm.AccountRef(ctx.Tx.Data.From)
 	var leftOverGas uint64
 
+	if ctx.Ctx != nil && ctx.Ctx.Done() != nil {
+		finished := make(chan struct{})
+		defer close(finished)
+
+		go func() {
+			select {
+			case <-ctx.Ctx.Done():
+				e.Cancel()
+			case <-finished:
+			}
+		}()
+	}
+
 	if ctx.Tx.Data.To.IsEmpty() {
 		// this is smart contract deployment
 		var createdContractAddr common.Address
@@ -229,7 +315,7 @@ func processEvmContract(ctx *Context, gas uint64, height uint64) (*types.Receipt
 			receipt.ContractAddress = createdContractAddr.Bytes()
 		}
 
-		if height > common.SmartContractNonceFixHeight {
+		if ctx.chainConfig().IsSmartContractNonceFix(height) {
 			if err == nil {
 				dbnonce := ctx.Statedb.GetNonce(ctx.Tx.Data.From)
 				// here only need to compare dbnonce with accountnonce + 1, since dbnonce is already set
@@ -250,6 +336,13 @@ func processEvmContract(ctx *Context, gas uint64, height uint64) (*types.Receipt
 	return receipt, err
 }
 
+// cleanupState deletes the empty accounts touched by the tx after the state cleanup fork
+func cleanupState(ctx *Context) {
+	if ctx.chainConfig().IsStateCleanup(ctx.BlockHeader.Height) {
+		ctx.Statedb.DeleteEmptyAccounts()
+	}
+}
+
 // handleFee handles the tx fee and return a receipt
 func handleFee(ctx *Context, receipt *types.Receipt, snapshot int) (*types.Receipt, error) {
 	// Calculating the total fee
@@ -264,6 +357,8 @@ func handleFee(ctx *Context, receipt *types.Receipt, snapshot int) (*types.Recei
 	ctx.Statedb.AddBalance(ctx.BlockHeader.Creator, totalFee)
 	receipt.TotalFee = totalFee.Uint64()
 
+	cleanupState(ctx)
+
 	// Record statedb hash
 	var err error
 	if receipt.PostState, err = ctx.Statedb.Hash(); err != nil {
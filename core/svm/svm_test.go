@@ -5,12 +5,15 @@
 package svm
 
 import (
+	"context"
 	"math"
 	"math/big"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/common/hexutil"
 	"github.com/scdoproject/go-scdo/contract/system"
 	"github.com/scdoproject/go-scdo/core/state"
@@ -53,6 +56,117 @@ func Test_Process_EVM(t *testing.T) {
 	assert.Equal(t, logs, receipt.Logs)
 }
 
+func Test_Process_SysContractFork(t *testing.T) {
+	ctx, err := newTestContext(big.NewInt(0))
+	assert.Equal(t, err, nil)
+
+	from := ctx.Tx.Data.From
+	ctx.Tx.Data.To = system.MetaTxRelayContractAddress
+	ctx.Tx.Data.Payload = append([]byte{system.CmdGetRelayNonce}, from.Bytes()...)
+	ctx.Tx.Hash = ctx.Tx.CalculateHash()
+
+	// a normal account before the fork
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(receipt.Result), 0)
+
+	ctx.ChainConfig = common.DefaultChainConfig()
+	ctx.ChainConfig.MetaTxRelayForkHeight = ctx.BlockHeader.Height
+	ctx.Tx.Data.AccountNonce = 39
+	ctx.Tx.Hash = ctx.Tx.CalculateHash()
+	receipt, err = Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.Failed, false)
+	assert.Equal(t, len(receipt.Result), 8)
+}
+
+func Test_Process_StateCleanup(t *testing.T) {
+	ctx, err := newTestContext(big.NewInt(0))
+	assert.Equal(t, err, nil)
+
+	// zero value transfer to an existing empty account
+	newEmptyAccount := func() common.Address {
+		to := *crypto.MustGenerateShardAddress(ctx.Tx.Data.From.Shard())
+		ctx.Statedb.CreateAccount(to)
+		_, err := ctx.Statedb.Hash()
+		assert.Equal(t, err, nil)
+
+		ctx.Tx.Data.To = to
+		ctx.Tx.Data.Payload = nil
+		ctx.Tx.Hash = ctx.Tx.CalculateHash()
+		return to
+	}
+
+	// the empty account is kept before the fork
+	to := newEmptyAccount()
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.Failed, false)
+	assert.Equal(t, ctx.Statedb.Exist(to), true)
+
+	ctx.ChainConfig = common.DefaultChainConfig()
+	ctx.ChainConfig.StateCleanupForkHeight = ctx.BlockHeader.Height
+	ctx.Tx.Data.AccountNonce = 39
+	to = newEmptyAccount()
+	receipt, err = Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.Failed, false)
+	assert.Equal(t, ctx.Statedb.Exist(to), false)
+}
+
+func Test_Process_AccessControl(t *testing.T) {
+	ctx, err := newTestContext(big.NewInt(0))
+	assert.Equal(t, err, nil)
+
+	// the sender is the only validator of the BFT consensus
+	from := ctx.Tx.Data.From
+	extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{Validators: []common.Address{from}, Seal: []byte{}, CommittedSeal: [][]byte{}})
+	assert.Equal(t, err, nil)
+	ctx.BlockHeader.Consensus = types.IstanbulConsensus
+	ctx.BlockHeader.ExtraData = append(make([]byte, types.IstanbulExtraVanity), extra...)
+	ctx.ChainConfig = common.DefaultChainConfig()
+	ctx.ChainConfig.AccessControlForkHeight = ctx.BlockHeader.Height
+
+	// deny deploying contracts by default
+	deployTx := *ctx.Tx
+	rule := &system.AccessRule{Action: system.AccessDeploy, Permission: system.AccessDeny}
+	ctx.Tx.Data.To = system.AccessControlContractAddress
+	ctx.Tx.Data.Payload = append([]byte{system.CmdVoteAccessRule}, common.SerializePanic(rule)...)
+	ctx.Tx.Hash = ctx.Tx.CalculateHash()
+
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.Failed, false)
+
+	// the deployment fails and only the intrinsic gas is charged
+	deployTx.Data.AccountNonce = 39
+	deployTx.Hash = deployTx.CalculateHash()
+	ctx.Tx = &deployTx
+	receipt, err = Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.Failed, true)
+	assert.Equal(t, string(receipt.Result), system.ErrAccessDenied.Error())
+	assert.Equal(t, len(receipt.ContractAddress), 0)
+	assert.Equal(t, receipt.UsedGas, deployTx.IntrinsicGas())
+
+	// the access control is bypassed before the fork
+	ctx.ChainConfig.AccessControlForkHeight = ctx.BlockHeader.Height + 1
+	deployTx.Data.AccountNonce = 40
+	deployTx.Hash = deployTx.CalculateHash()
+	receipt, err = Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.Failed, false)
+
+	// the access control is bypassed in the public networks
+	ctx.ChainConfig.AccessControlForkHeight = ctx.BlockHeader.Height
+	ctx.BlockHeader.Consensus = types.PowConsensus
+	deployTx.Data.AccountNonce = 41
+	deployTx.Hash = deployTx.CalculateHash()
+	receipt, err = Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipt.Failed, false)
+}
+
 func Test_Process_SysContract(t *testing.T) {
 	// CreateDomainName
 	ctx, _ := newTestContext(big.NewInt(0))
@@ -227,6 +341,28 @@ func Test_Process_ErrOutOfGas(t *testing.T) {
 	assert.Equal(t, balanceOri4.Uint64(), balanceCur4.Uint64()+receipt4.TotalFee)
 }
 
+func Test_Process_Aborted(t *testing.T) {
+	ctx, err := newTestContext(big.NewInt(0))
+	assert.Equal(t, err, nil)
+
+	// infinite loop: JUMPDEST PUSH1 0 JUMP
+	code := mustHexToBytes("0x5b600056")
+	ctx.Tx, err = types.NewContractTransaction(ctx.Tx.Data.From, big.NewInt(0), big.NewInt(1), 10000000000, 38, code)
+	assert.Equal(t, err, nil)
+
+	c, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	ctx.Ctx = c
+
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.Equal(t, receipt == nil, true)
+	assert.Equal(t, errors.IsOrContains(err, ErrExecutionAborted), true)
+
+	// statedb is reverted
+	assert.Equal(t, ctx.Statedb.GetNonce(ctx.Tx.Data.From), uint64(38))
+	assert.Equal(t, ctx.Statedb.GetBalance(ctx.Tx.Data.From).Uint64(), fromBalance)
+}
+
 func mustHexToBytes(hex string) []byte {
 	code, err := hexutil.HexToBytes(hex)
 	if err != nil {
@@ -0,0 +1,182 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// TxConstraintFunding is the constraint that a tx spends the balance transferred by another tx in the pool
+const TxConstraintFunding = "funding"
+
+// TxDependencyNode is a tx in the nonce chain of an account
+type TxDependencyNode struct {
+	Hash  common.Hash `json:"hash"`
+	Nonce uint64      `json:"nonce"`
+	Cost  *big.Int    `json:"cost"` // amount and max fee of the tx
+
+	// Executable is whether the nonce of the tx follows the state nonce without any gap
+	Executable bool `json:"executable"`
+
+	// Funded is whether the balance of the account covers the total cost of the txs up to this one
+	Funded bool `json:"funded"`
+}
+
+// TxNonceChain is the txs of an account in the pool in nonce order
+type TxNonceChain struct {
+	Account    common.Address      `json:"account"`
+	StateNonce uint64              `json:"stateNonce"`
+	Balance    *big.Int            `json:"balance"`
+	Txs        []*TxDependencyNode `json:"txs"`
+}
+
+// TxOrderConstraint is a cross-account constraint that the tx Before should be applied ahead of the tx After
+type TxOrderConstraint struct {
+	Before        common.Hash    `json:"before"`
+	BeforeAccount common.Address `json:"beforeAccount"`
+	After         common.Hash    `json:"after"`
+	AfterAccount  common.Address `json:"afterAccount"`
+	Reason        string         `json:"reason"`
+}
+
+// TxDependencyGraph is the nonce chains of the accounts in the pool and the ordering constraints across the
+// accounts, so that the external block builders could order the txs without trial and error.
+type TxDependencyGraph struct {
+	Chains      []*TxNonceChain      `json:"chains"`
+	Constraints []*TxOrderConstraint `json:"constraints"`
+}
+
+// GetDependencyGraph returns the dependency graph of the txs in the pool. If the account is not empty, only
+// its nonce chain and the constraints involving it are returned.
+func (pool *TransactionPool) GetDependencyGraph(account common.Address) (*TxDependencyGraph, error) {
+	statedb, err := pool.chain.GetCurrentState()
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to get current state")
+	}
+
+	queues := make(map[common.Address][]*types.Transaction)
+	incoming := make(map[common.Address][]*types.Transaction)
+	// the queued txs with nonce gaps are in the chains as not executable
+	txs := append(pool.GetTransactions(true, true), pool.GetQueuedTransactions()...)
+	for _, tx := range txs {
+		queues[tx.FromAccount()] = append(queues[tx.FromAccount()], tx)
+		if tx.Data.Amount != nil && tx.Data.Amount.Sign() > 0 && tx.ToAccount() != tx.FromAccount() {
+			incoming[tx.ToAccount()] = append(incoming[tx.ToAccount()], tx)
+		}
+	}
+
+	graph := &TxDependencyGraph{
+		Chains:      make([]*TxNonceChain, 0),
+		Constraints: make([]*TxOrderConstraint, 0),
+	}
+
+	for from, txs := range queues {
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+
+		chain := &TxNonceChain{
+			Account:    from,
+			StateNonce: statedb.GetNonce(from),
+			Balance:    statedb.GetBalance(from),
+		}
+
+		nextNonce := chain.StateNonce
+		total := big.NewInt(0)
+		for _, tx := range txs {
+			node := &TxDependencyNode{
+				Hash:       tx.Hash,
+				Nonce:      tx.Nonce(),
+				Cost:       txCost(tx),
+				Executable: tx.Nonce() == nextNonce,
+			}
+
+			if node.Executable {
+				nextNonce++
+			}
+
+			total.Add(total, node.Cost)
+			node.Funded = total.Cmp(chain.Balance) <= 0
+
+			// the later txs depend on the first underfunded one by nonce already
+			if !node.Funded && (len(chain.Txs) == 0 || chain.Txs[len(chain.Txs)-1].Funded) {
+				for _, funding := range incoming[from] {
+					graph.Constraints = append(graph.Constraints, &TxOrderConstraint{
+						Before:        funding.Hash,
+						BeforeAccount: funding.FromAccount(),
+						After:         tx.Hash,
+						AfterAccount:  from,
+						Reason:        TxConstraintFunding,
+					})
+				}
+			}
+
+			chain.Txs = append(chain.Txs, node)
+		}
+
+		graph.Chains = append(graph.Chains, chain)
+	}
+
+	if !account.IsEmpty() {
+		graph = graph.filter(account)
+	}
+
+	graph.sort()
+
+	return graph, nil
+}
+
+// filter returns the graph of the nonce chain of the account and the constraints involving it
+func (graph *TxDependencyGraph) filter(account common.Address) *TxDependencyGraph {
+	filtered := &TxDependencyGraph{
+		Chains:      make([]*TxNonceChain, 0),
+		Constraints: make([]*TxOrderConstraint, 0),
+	}
+
+	for _, chain := range graph.Chains {
+		if chain.Account == account {
+			filtered.Chains = append(filtered.Chains, chain)
+		}
+	}
+
+	for _, c := range graph.Constraints {
+		if c.BeforeAccount == account || c.AfterAccount == account {
+			filtered.Constraints = append(filtered.Constraints, c)
+		}
+	}
+
+	return filtered
+}
+
+// sort orders the chains by account and the constraints by the tx hashes, so that the output is stable
+func (graph *TxDependencyGraph) sort() {
+	sort.Slice(graph.Chains, func(i, j int) bool {
+		return bytes.Compare(graph.Chains[i].Account[:], graph.Chains[j].Account[:]) < 0
+	})
+
+	sort.Slice(graph.Constraints, func(i, j int) bool {
+		a, b := graph.Constraints[i], graph.Constraints[j]
+		if cmp := bytes.Compare(a.After[:], b.After[:]); cmp != 0 {
+			return cmp < 0
+		}
+
+		return bytes.Compare(a.Before[:], b.Before[:]) < 0
+	})
+}
+
+// txCost returns the amount and the max fee of the tx, which is the balance required by the tx validation
+func txCost(tx *types.Transaction) *big.Int {
+	cost := new(big.Int).Mul(tx.Data.GasPrice, new(big.Int).SetUint64(tx.Data.GasLimit))
+	if tx.Data.Amount != nil {
+		cost.Add(cost, tx.Data.Amount)
+	}
+
+	return cost
+}
@@ -0,0 +1,91 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDependencyTx(t *testing.T, key *ecdsa.PrivateKey, from, to common.Address, amount int64, nonce uint64) *types.Transaction {
+	tx, err := types.NewTransaction(from, to, big.NewInt(amount), big.NewInt(1), nonce)
+	assert.Equal(t, err, nil)
+	tx.Sign(key)
+
+	return tx
+}
+
+func Test_TransactionPool_GetDependencyGraph(t *testing.T) {
+	pool, chain := newTestTransactionPool(DefaultTxPoolConfig())
+	defer chain.dispose()
+
+	keyA, addrA := randomAccount(t)
+	keyB, addrB := randomAccount(t)
+	_, addrC := randomAccount(t)
+
+	// A funds B, while B could only afford its first tx without the funding
+	funding := newTestDependencyTx(t, keyA, addrA, addrB, 1000, 5)
+	b1 := newTestDependencyTx(t, keyB, addrB, addrC, 100, 0)
+	b2 := newTestDependencyTx(t, keyB, addrB, addrC, 100, 1)
+	b4 := newTestDependencyTx(t, keyB, addrB, addrC, 100, 3)
+
+	chain.addAccount(addrA, txCost(funding).Uint64(), 5)
+	chain.addAccount(addrB, txCost(b1).Uint64()+10, 0)
+
+	for _, tx := range []*types.Transaction{funding, b1, b2, b4} {
+		assert.Equal(t, pool.AddTransaction(tx), nil)
+	}
+
+	graph, err := pool.GetDependencyGraph(common.EmptyAddress)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(graph.Chains), 2)
+
+	var chainB *TxNonceChain
+	for _, c := range graph.Chains {
+		if c.Account == addrB {
+			chainB = c
+		}
+	}
+
+	assert.Equal(t, chainB.StateNonce, uint64(0))
+	assert.Equal(t, len(chainB.Txs), 3)
+	assert.Equal(t, chainB.Txs[0].Hash, b1.Hash)
+	assert.Equal(t, chainB.Txs[0].Executable, true)
+	assert.Equal(t, chainB.Txs[0].Funded, true)
+	assert.Equal(t, chainB.Txs[1].Executable, true)
+	assert.Equal(t, chainB.Txs[1].Funded, false)
+
+	// nonce gap, the tx is queued in the pool
+	assert.Equal(t, pool.IsQueuedTransaction(b4.Hash), true)
+	assert.Equal(t, chainB.Txs[2].Nonce, uint64(3))
+	assert.Equal(t, chainB.Txs[2].Executable, false)
+
+	// only the first underfunded tx depends on the funding tx
+	assert.Equal(t, graph.Constraints, []*TxOrderConstraint{{
+		Before:        funding.Hash,
+		BeforeAccount: addrA,
+		After:         b2.Hash,
+		AfterAccount:  addrB,
+		Reason:        TxConstraintFunding,
+	}})
+
+	// filtered by account
+	graph, err = pool.GetDependencyGraph(addrA)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(graph.Chains), 1)
+	assert.Equal(t, graph.Chains[0].Account, addrA)
+	assert.Equal(t, len(graph.Constraints), 1)
+
+	graph, err = pool.GetDependencyGraph(addrC)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(graph.Chains), 0)
+	assert.Equal(t, len(graph.Constraints), 0)
+}
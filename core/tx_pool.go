@@ -18,6 +18,15 @@ import (
 
 const transactionTimeoutDuration = 3 * time.Hour
 
+// ErrNoncePending is returned when a transaction with the same account and nonce is pending to be packed.
+var ErrNoncePending = errors.New("nonce is pending in tx pool, please WAIT or manually set a HIGHER gas price to replace it")
+
+// chainConfigReader is the blockchain which provides its chain config and head
+type chainConfigReader interface {
+	Config() *common.ChainConfig
+	CurrentHeader() *types.BlockHeader
+}
+
 // TransactionPool is a thread-safe container for transactions received from the network or submitted locally.
 // A transaction will be removed from the pool once included in a blockchain or pending time too long (> transactionTimeoutDuration).
 type TransactionPool struct {
@@ -32,7 +41,8 @@ func NewTransactionPool(config TransactionPoolConfig, chain blockchain) *Transac
 	}
 	// 1st bool: can remove from object pool
 	// 2nd bool: can remove from cachedTxs
-	canRemove := func(chain blockchain, state *state.Statedb, item *poolItem) (bool, bool) {
+	// 3rd string: the reason if the tx is dropped without being packed
+	canRemove := func(chain blockchain, state *state.Statedb, item *poolItem) (bool, bool, string) {
 		nowTimestamp := time.Now()
 		txIndex, _ := chain.GetStore().GetTxIndex(item.GetHash())
 		nonce := state.GetNonce(item.FromAccount())
@@ -44,21 +54,29 @@ func NewTransactionPool(config TransactionPoolConfig, chain blockchain) *Transac
 				if item.Nonce() < nonce {
 					log.Debug("remove tx %s because nonce too low, account %s, tx nonce %d, target nonce %d", item.GetHash().Hex(),
 						item.FromAccount().Hex(), item.Nonce(), nonce)
-					return true, false // the true stand for "not timeout"
+					return true, false, "nonce used by another tx" // the true stand for "not timeout"
 				} else if duration > transactionTimeoutDuration {
 					log.Debug("remove tx %s because not packed for more than three hours", item.GetHash().Hex())
-					return true, true
+					return true, true, "not packed for more than three hours"
 				}
 			}
-			return true, false
+			return true, false, ""
 		}
 
-		return false, false
+		return false, false, ""
 	}
 
+	validationCache := newTxValidationCache(txValidationCacheSize)
 	objectValidation := func(state *state.Statedb, obj poolObject) error {
 		tx := obj.(*types.Transaction)
-		if err := tx.Validate(state, common.ThirdForkHeight); err != nil {
+
+		// validate against the forks of the next block if the chain provides its config
+		chainConfig, height := common.DefaultChainConfig(), uint64(common.ThirdForkHeight)
+		if reader, ok := chain.(chainConfigReader); ok {
+			chainConfig, height = reader.Config(), reader.CurrentHeader().Height+1
+		}
+
+		if err := validationCache.validate(tx, state, chainConfig, height); err != nil {
 			return errors.NewStackedError(err, "failed to validate tx")
 		}
 
@@ -73,9 +91,11 @@ func NewTransactionPool(config TransactionPoolConfig, chain blockchain) *Transac
 	}
 
 	cachedTxs := NewCachedTxs(CachedCapacity)
+	cachedTxs.seen.reportMetrics = true
 	cachedTxs.init(chain)
 
 	pool := NewPool(config.Capacity, chain, getObjectFromBlock, canRemove, log, objectValidation, afterAdd, cachedTxs)
+	pool.enableFutureQueue(config.FutureCapacity)
 
 	return &TransactionPool{pool}
 }
@@ -96,7 +116,27 @@ func (pool *TransactionPool) AddTransaction(tx *types.Transaction) error {
 
 	// be noted: soft forking reverseBCstore will directly use pool.addObjectArray which will call pool.addObject(tx)
 	// so cachedTxs check won't have any effect to reinject txs
-	return pool.addObject(tx)
+	err := pool.addObject(tx)
+	if err == errObjectNonceUsed {
+		return errors.NewStackedErrorf(ErrNoncePending, "account:%s, tx nonce:%d", tx.Data.From.Hex(), tx.Data.AccountNonce)
+	}
+
+	return err
+}
+
+// SetAdmissionPolicies replaces the local admission policies of the txs added into the pool.
+func (pool *TransactionPool) SetAdmissionPolicies(policies []AdmissionPolicy) {
+	pool.policies.set(policies)
+}
+
+// GetAdmissionPolicies returns the names of the local admission policies.
+func (pool *TransactionPool) GetAdmissionPolicies() []string {
+	return pool.policies.names()
+}
+
+// GetAdmissionRejections returns the number of txs rejected by each admission policy.
+func (pool *TransactionPool) GetAdmissionRejections() map[string]uint64 {
+	return pool.policies.counters()
 }
 
 // GetTransaction returns a transaction if it is contained in the pool and nil otherwise.
@@ -114,6 +154,27 @@ func (pool *TransactionPool) GetTransaction(txHash common.Hash) *types.Transacti
 	return nil
 }
 
+// GetDroppedTransaction returns a recently dropped transaction and the reason if it is removed from the pool
+// without being packed, e.g. replaced, timeout or discarded. Otherwise, return nil.
+func (pool *TransactionPool) GetDroppedTransaction(txHash common.Hash) (*types.Transaction, string) {
+	obj, reason := pool.getDroppedObject(txHash)
+	if tx, ok := obj.(*types.Transaction); ok {
+		return tx, reason
+	}
+
+	return nil, ""
+}
+
+// GetTxReceivedTime returns the time when the transaction is added into the pool, false if not in the pool.
+func (pool *TransactionPool) GetTxReceivedTime(txHash common.Hash) (time.Time, bool) {
+	return pool.getObjectTime(txHash)
+}
+
+// SeenTxs returns the seen txs shared with the protocol layer
+func (pool *TransactionPool) SeenTxs() *SeenTxs {
+	return pool.cachedTxs.seen
+}
+
 // RemoveTransaction removes transaction of specified transaction hash from pool
 func (pool *TransactionPool) RemoveTransaction(txHash common.Hash) {
 	pool.removeOject(txHash)
@@ -126,6 +187,17 @@ func (pool *TransactionPool) GetProcessableTransactions(size int) ([]*types.Tran
 	return poolObjectToTxs(objects), size
 }
 
+// ReturnTransactions returns the processable transactions not packed by the miner back to the pending queue,
+// so that they are processable again without waiting for the reinjection of the pool.
+func (pool *TransactionPool) ReturnTransactions(txs []*types.Transaction) {
+	objects := make([]poolObject, len(txs))
+	for i, tx := range txs {
+		objects[i] = tx
+	}
+
+	pool.returnObjects(objects)
+}
+
 // GetPendingTxCount returns the total number of pending transactions in the transaction pool.
 func (pool *TransactionPool) GetPendingTxCount() int {
 	return pool.getObjectCount(false, true)
@@ -136,6 +208,17 @@ func (pool *TransactionPool) GetTxCount() int {
 	return pool.getObjectCount(true, true)
 }
 
+// GetQueuedTransactions returns the transactions with nonce gaps queued in the transaction pool,
+// which are promoted to pending once the gaps are filled.
+func (pool *TransactionPool) GetQueuedTransactions() []*types.Transaction {
+	return poolObjectToTxs(pool.getFutureObjects())
+}
+
+// IsQueuedTransaction returns whether the transaction is queued for a nonce gap in the transaction pool.
+func (pool *TransactionPool) IsQueuedTransaction(txHash common.Hash) bool {
+	return pool.isFutureObjectHash(txHash)
+}
+
 // GetTransactions returns the transactions in the transaction pool.
 func (pool *TransactionPool) GetTransactions(processing, pending bool) []*types.Transaction {
 	objects := pool.getObjects(processing, pending)
@@ -195,6 +195,39 @@ func Test_TransactionPool_Add_TxNonceUsed(t *testing.T) {
 	assert.Equal(t, err, errObjectNonceUsed)
 }
 
+func Test_TransactionPool_GetDroppedTransaction(t *testing.T) {
+	pool, chain := newTestTransactionPool(DefaultTxPoolConfig())
+	defer chain.dispose()
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 1000000, 10)
+
+	poolTx := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 100, 1)
+	err := pool.addObject(poolTx.poolObject)
+	assert.Equal(t, err, error(nil))
+
+	tx, reason := pool.GetDroppedTransaction(poolTx.GetHash())
+	assert.Equal(t, tx == nil, true)
+	assert.Equal(t, reason, "")
+
+	// replaced by higher gas price
+	newPoolTx := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 100, 2)
+	err = pool.addObject(newPoolTx.poolObject)
+	assert.Equal(t, err, error(nil))
+
+	tx, reason = pool.GetDroppedTransaction(poolTx.GetHash())
+	assert.Equal(t, tx, poolTx.poolObject)
+	assert.Equal(t, reason, "replaced by "+newPoolTx.GetHash().Hex()+" with higher gas price")
+
+	// added again
+	pool.removeOject(newPoolTx.GetHash())
+	err = pool.addObject(poolTx.poolObject)
+	assert.Equal(t, err, error(nil))
+
+	tx, _ = pool.GetDroppedTransaction(poolTx.GetHash())
+	assert.Equal(t, tx == nil, true)
+}
+
 func Test_TransactionPool_GetTransaction(t *testing.T) {
 	pool, chain := newTestTransactionPool(DefaultTxPoolConfig())
 	defer chain.dispose()
@@ -0,0 +1,69 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"encoding/binary"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/metrics"
+)
+
+// txValidationCacheSize is the max number of txs which passed the stateless validation to cache
+const txValidationCacheSize = 100000
+
+// txValidationCache caches the txs which passed the stateless validation (e.g. hash and signature),
+// so that the same tx re-gossiped by multiple peers only validates against the statedb.
+// Only the passed txs are cached, so that a tx with an invalid signature could not poison the cache.
+type txValidationCache struct {
+	cache *lru.Cache
+}
+
+func newTxValidationCache(size int) *txValidationCache {
+	return &txValidationCache{common.MustNewCache(size)}
+}
+
+// txValidationKey returns the cache key made up of sender, nonce, hash and signature of the tx,
+// the signature is included since it is not covered by the tx hash. The tx hash must be verified
+// against the tx data before, since it is supplied by the sender.
+func txValidationKey(tx *types.Transaction) string {
+	nonce := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonce, tx.Data.AccountNonce)
+
+	key := make([]byte, 0, common.AddressLen+len(nonce)+common.HashLength+len(tx.Signature.Sig))
+	key = append(key, tx.Data.From.Bytes()...)
+	key = append(key, nonce...)
+	key = append(key, tx.Hash.Bytes()...)
+	key = append(key, tx.Signature.Sig...)
+
+	return string(key)
+}
+
+// validate validates the tx, the stateless validation is skipped if the tx is cached.
+// The tx hash is always recomputed, so that a cached tx with the tampered data is not admitted.
+func (c *txValidationCache) validate(tx *types.Transaction, statedb *state.Statedb, config *common.ChainConfig, height uint64) error {
+	if txHash := tx.CalculateHash(); !txHash.Equal(tx.Hash) {
+		return types.ErrHashMismatch
+	}
+
+	key := txValidationKey(tx)
+	if c.cache.Contains(key) {
+		metrics.MetricsTxValidationCacheHitMeter.Mark(1)
+	} else {
+		metrics.MetricsTxValidationCacheMissMeter.Mark(1)
+
+		if err := tx.ValidateWithoutState(true, true); err != nil {
+			return err
+		}
+
+		c.cache.Add(key, struct{}{})
+	}
+
+	return tx.ValidateState(statedb, config, height)
+}
@@ -0,0 +1,81 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TxValidationCache(t *testing.T) {
+	chain := newMockBlockchain()
+	defer chain.dispose()
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 1000000, 10)
+
+	tx := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 10, 1).poolObject.(*types.Transaction)
+	cache := newTxValidationCache(10)
+
+	hits := metrics.MetricsTxValidationCacheHitMeter.Count()
+	misses := metrics.MetricsTxValidationCacheMissMeter.Count()
+
+	// cache missed
+	assert.Equal(t, cache.validate(tx, chain.statedb, common.DefaultChainConfig(), 0), nil)
+	assert.Equal(t, metrics.MetricsTxValidationCacheMissMeter.Count(), misses+1)
+
+	// cache hit, but still validated against the statedb
+	assert.Equal(t, cache.validate(tx, chain.statedb, common.DefaultChainConfig(), 0), nil)
+	assert.Equal(t, metrics.MetricsTxValidationCacheHitMeter.Count(), hits+1)
+
+	chain.statedb.SetNonce(fromAddress, 11)
+	assert.Equal(t, cache.validate(tx, chain.statedb, common.DefaultChainConfig(), 0) != nil, true)
+	assert.Equal(t, metrics.MetricsTxValidationCacheHitMeter.Count(), hits+2)
+
+	// the same tx with an invalid signature is not cached
+	chain.statedb.SetNonce(fromAddress, 10)
+	otherPrivKey, _ := randomAccount(t)
+	forged := *tx
+	forged.Sign(otherPrivKey)
+	assert.Equal(t, cache.validate(&forged, chain.statedb, common.DefaultChainConfig(), 0), types.ErrSigInvalid)
+	assert.Equal(t, cache.validate(&forged, chain.statedb, common.DefaultChainConfig(), 0), types.ErrSigInvalid)
+	assert.Equal(t, metrics.MetricsTxValidationCacheMissMeter.Count(), misses+3)
+	assert.Equal(t, cache.cache.Len(), 1)
+
+	// the cached tx with the tampered data is not admitted
+	tampered := *tx
+	tampered.Data.Amount = big.NewInt(11)
+	assert.Equal(t, tampered.ValidateWithoutState(true, true), types.ErrHashMismatch)
+	assert.Equal(t, cache.validate(&tampered, chain.statedb, common.DefaultChainConfig(), 0), types.ErrHashMismatch)
+	assert.Equal(t, cache.validate(tx, chain.statedb, common.DefaultChainConfig(), 0), nil)
+}
+
+func Test_TxValidationCache_AccessList(t *testing.T) {
+	chain := newMockBlockchain()
+	defer chain.dispose()
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 1000000, 10)
+
+	tx := newTestPoolEx(t, fromPrivKey, fromAddress, 10, 10, 1).poolObject.(*types.Transaction)
+	tx.Data.AccessList = types.AccessList{{Address: fromAddress}}
+	tx.Data.GasLimit += tx.Data.AccessList.IntrinsicGas()
+	tx.Sign(fromPrivKey)
+	cache := newTxValidationCache(10)
+
+	// the access list is not activated on the main network yet
+	config := common.DefaultChainConfig()
+	assert.Equal(t, cache.validate(tx, chain.statedb, config, 100), types.ErrAccessListNotActivated)
+
+	config.AccessListForkHeight = 100
+	assert.Equal(t, cache.validate(tx, chain.statedb, config, 99), types.ErrAccessListNotActivated)
+	assert.Equal(t, cache.validate(tx, chain.statedb, config, 100), nil)
+}
@@ -0,0 +1,48 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package types
+
+import (
+	"github.com/scdoproject/go-scdo/common"
+)
+
+const (
+	// TxAccessListAddressGas is the intrinsic gas of an address in the access list of tx
+	TxAccessListAddressGas uint64 = 2400
+
+	// TxAccessListStorageKeyGas is the intrinsic gas of a storage key in the access list of tx
+	TxAccessListStorageKeyGas uint64 = 1900
+)
+
+// AccessTuple is an account and its storage keys accessed by a tx
+type AccessTuple struct {
+	Address     common.Address
+	StorageKeys []common.Hash
+}
+
+// AccessList is the accounts and storage keys a tx plans to access, which are warmed up before the tx
+// is executed after the access list fork, so that the state access in the evm is charged the warm cost.
+type AccessList []AccessTuple
+
+// StorageKeys returns the total number of the storage keys in the access list
+func (al AccessList) StorageKeys() int {
+	sum := 0
+	for _, tuple := range al {
+		sum += len(tuple.StorageKeys)
+	}
+
+	return sum
+}
+
+// Size returns the bytes of the addresses and storage keys in the access list
+func (al AccessList) Size() int {
+	return len(al)*common.AddressLen + al.StorageKeys()*common.HashLength
+}
+
+// IntrinsicGas returns the intrinsic gas of the access list
+func (al AccessList) IntrinsicGas() uint64 {
+	return uint64(len(al))*TxAccessListAddressGas + uint64(al.StorageKeys())*TxAccessListStorageKeyGas
+}
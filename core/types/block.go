@@ -6,6 +6,7 @@
 package types
 
 import (
+	"bytes"
 	"errors"
 	"math/big"
 
@@ -33,6 +34,10 @@ var (
 	ErrBlockDebtHashMismatch = errors.New("block debts hash mismatch")
 )
 
+// MaxSignalingExtraSize is the max size of the extra data set by miners for governance signaling,
+// which is the same as the istanbul extra vanity.
+const MaxSignalingExtraSize = 32
+
 type ConsensusType uint
 
 const (
@@ -90,6 +95,21 @@ func (header *BlockHeader) Hash() common.Hash {
 	return crypto.MustHash(header)
 }
 
+// SignalingExtra returns the extra data set by the miner for governance signaling without the trailing
+// zero bytes, which are the padding of the extra vanity in istanbul blocks.
+func (header *BlockHeader) SignalingExtra() []byte {
+	extra := header.ExtraData
+	if header.Consensus == IstanbulConsensus {
+		if len(extra) < IstanbulExtraVanity {
+			return nil
+		}
+
+		extra = extra[:IstanbulExtraVanity]
+	}
+
+	return bytes.TrimRight(extra, "\x00")
+}
+
 // Block represents a block in the blockchain.
 type Block struct {
 	HeaderHash   common.Hash    // HeaderHash is the hash of the RLP encoded header bytes
@@ -46,6 +46,12 @@ type Debt struct {
 // DebtIndex debt index
 type DebtIndex indexInBlock
 
+// SpentDebt is a block which applies the debt, the same debt could be applied by blocks in different forks
+type SpentDebt struct {
+	BlockHash   common.Hash
+	BlockHeight uint64
+}
+
 // GetDebtTrie generates a debt trie for the specified debts.
 func GetDebtTrie(debts []*Debt) *trie.Trie {
 	debtTrie := trie.NewEmptyTrie(make([]byte, 0), nil)
@@ -251,10 +257,30 @@ func DebtArrayToMap(debts []*Debt) [][]*Debt {
 	return debtsMap
 }
 
-// BatchValidateDebt validates a batch of debts
+// BatchValidateDebt validates a batch of debts. If the verifier is a BatchDebtVerifier,
+// the debts are verified in batch per source shard after the debts themselves are validated.
 func BatchValidateDebt(debts []*Debt, verifier DebtVerifier) error {
-	return BatchValidate(func(index int) error {
-		_, err := debts[index].Validate(verifier, false, common.LocalShardNumber)
+	batchVerifier, ok := verifier.(BatchDebtVerifier)
+	if !ok || len(debts) == 0 {
+		return BatchValidate(func(index int) error {
+			_, err := debts[index].Validate(verifier, false, common.LocalShardNumber)
+			return err
+		}, len(debts))
+	}
+
+	if err := BatchValidate(func(index int) error {
+		_, err := debts[index].Validate(nil, false, common.LocalShardNumber)
+		return err
+	}, len(debts)); err != nil {
 		return err
-	}, len(debts))
+	}
+
+	results := VerifyDebtsBySourceShard(debts, batchVerifier, len(debts))
+	for i, d := range debts {
+		if _, err := d.Validate(results[i], false, common.LocalShardNumber); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
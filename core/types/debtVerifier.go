@@ -5,6 +5,10 @@
 
 package types
 
+import (
+	"sync"
+)
+
 // DebtVerifier interface
 type DebtVerifier interface {
 	// ValidateDebt validate debt
@@ -20,6 +24,74 @@ type DebtVerifier interface {
 	IfDebtPacked(debt *Debt) (packed bool, confirmed bool, err error)
 }
 
+// BatchDebtVerifier is a DebtVerifier which validates many debts at once,
+// e.g. the light clients confirm the debts in a single request per source shard.
+type BatchDebtVerifier interface {
+	DebtVerifier
+
+	// ValidateDebts validates the debts like ValidateDebt,
+	// and returns the results in the order of the debts.
+	ValidateDebts(debts []*Debt) []*DebtVerifyResult
+}
+
+// DebtVerifyResult is the result of validating a debt by the verifier. It implements the DebtVerifier
+// which returns the result itself, so that Debt.Validate could be applied on the results of a BatchDebtVerifier.
+type DebtVerifyResult struct {
+	Packed    bool
+	Confirmed bool
+	Err       error
+}
+
+// ValidateDebt returns the verified result
+func (r *DebtVerifyResult) ValidateDebt(debt *Debt) (packed bool, confirmed bool, err error) {
+	return r.Packed, r.Confirmed, r.Err
+}
+
+// IfDebtPacked returns the verified result
+func (r *DebtVerifyResult) IfDebtPacked(debt *Debt) (packed bool, confirmed bool, err error) {
+	return r.Packed, r.Confirmed, r.Err
+}
+
+// VerifyDebtsBySourceShard groups the debts by source shard, and validates the debts of each shard
+// in parallel with the verifier, at most batchSize debts at once. The results are in the order of the debts.
+func VerifyDebtsBySourceShard(debts []*Debt, verifier BatchDebtVerifier, batchSize int) []*DebtVerifyResult {
+	results := make([]*DebtVerifyResult, len(debts))
+
+	groups := make(map[uint][]int) // source shard -> indexes of the debts
+	for i, d := range debts {
+		shard := d.Data.From.Shard()
+		groups[shard] = append(groups[shard], i)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, indexes := range groups {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+
+			for start := 0; start < len(indexes); start += batchSize {
+				end := start + batchSize
+				if end > len(indexes) {
+					end = len(indexes)
+				}
+
+				batch := make([]*Debt, 0, end-start)
+				for _, i := range indexes[start:end] {
+					batch = append(batch, debts[i])
+				}
+
+				for j, r := range verifier.ValidateDebts(batch) {
+					results[indexes[start+j]] = r
+				}
+			}
+		}(indexes)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
 type TestVerifier struct {
 	packed    bool
 	confirmed bool
@@ -8,6 +8,7 @@ package types
 import (
 	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/scdoproject/go-scdo/common"
@@ -77,3 +78,74 @@ func Test_DebtSize(t *testing.T) {
 	fmt.Println(len(buff) / 5)
 	assert.Equal(t, len(buff)/5, DebtSize-2)
 }
+
+type testBatchVerifier struct {
+	TestVerifier
+	lock    sync.Mutex
+	batches map[uint][]int // source shard -> sizes of the batches
+}
+
+func (v *testBatchVerifier) ValidateDebts(debts []*Debt) []*DebtVerifyResult {
+	v.lock.Lock()
+	shard := debts[0].Data.From.Shard()
+	v.batches[shard] = append(v.batches[shard], len(debts))
+	v.lock.Unlock()
+
+	results := make([]*DebtVerifyResult, len(debts))
+	for i, d := range debts {
+		results[i] = &DebtVerifyResult{Packed: true, Confirmed: d.Data.Amount.Sign() > 0}
+	}
+
+	return results
+}
+
+func newTestShardDebt(fromShard, toShard uint, amount int64) *Debt {
+	data := DebtData{
+		TxHash:  crypto.MustHash(uint64(amount)),
+		From:    *crypto.MustGenerateShardAddress(fromShard),
+		Account: *crypto.MustGenerateShardAddress(toShard),
+		Amount:  big.NewInt(amount),
+		Price:   big.NewInt(1),
+		Code:    make([]byte, 0),
+	}
+
+	return &Debt{Data: data, Hash: data.Hash()}
+}
+
+func Test_VerifyDebtsBySourceShard(t *testing.T) {
+	var debts []*Debt
+	for i := 0; i < 5; i++ {
+		debts = append(debts, newTestShardDebt(1, 3, int64(i)), newTestShardDebt(2, 3, 1))
+	}
+
+	verifier := &testBatchVerifier{batches: make(map[uint][]int)}
+	results := VerifyDebtsBySourceShard(debts, verifier, 2)
+
+	assert.Equal(t, verifier.batches[1], []int{2, 2, 1})
+	assert.Equal(t, verifier.batches[2], []int{2, 2, 1})
+	for i, r := range results {
+		assert.Equal(t, r.Confirmed, debts[i].Data.Amount.Sign() > 0)
+	}
+}
+
+func Test_BatchValidateDebt_BatchVerifier(t *testing.T) {
+	common.LocalShardNumber = 3
+	defer func() {
+		common.LocalShardNumber = common.UndefinedShardNumber
+	}()
+
+	debts := []*Debt{newTestShardDebt(1, 3, 1), newTestShardDebt(2, 3, 1), newTestShardDebt(1, 3, 1)}
+	verifier := &testBatchVerifier{batches: make(map[uint][]int)}
+	assert.Equal(t, BatchValidateDebt(debts, verifier), nil)
+	assert.Equal(t, verifier.batches[1], []int{2})
+	assert.Equal(t, verifier.batches[2], []int{1})
+
+	// not confirmed
+	debts = append(debts, newTestShardDebt(2, 3, 0))
+	assert.Equal(t, BatchValidateDebt(debts, verifier) != nil, true)
+
+	// invalid debt is not verified
+	verifier = &testBatchVerifier{batches: make(map[uint][]int)}
+	assert.Equal(t, BatchValidateDebt([]*Debt{newTestShardDebt(3, 3, 1)}, verifier) != nil, true)
+	assert.Equal(t, len(verifier.batches), 0)
+}
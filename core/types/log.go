@@ -50,3 +50,26 @@ func (log *Log) MarshalJSON() ([]byte, error) {
 	o.TxIndex = log.TxIndex
 	return json.Marshal(&o)
 }
+
+// BlockLogIndex represents the log indices of a block, which is derived by the node and persisted with the
+// receipts of the block. The logs are indexed from 0 in the block in the order of the receipts, and the
+// global index of a log is First plus its index in the block, which increases monotonically along the chain.
+type BlockLogIndex struct {
+	First uint64 // global index of the first log in the block
+	Count uint64 // number of logs in the block
+}
+
+// NewBlockLogIndex returns the log indices of the block with the specified receipts, which follows the
+// log indices of the parent block. The global index starts from 0 if the parent has no log indices.
+func NewBlockLogIndex(parent *BlockLogIndex, receipts []*Receipt) *BlockLogIndex {
+	index := &BlockLogIndex{}
+	if parent != nil {
+		index.First = parent.First + parent.Count
+	}
+
+	for _, receipt := range receipts {
+		index.Count += uint64(len(receipt.Logs))
+	}
+
+	return index
+}
@@ -0,0 +1,39 @@
+/**
+* @file
+* @copyright defined in scdo/LICENSE
+ */
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/common"
+)
+
+// StorageDiff represents the change of an account storage slot in a block.
+type StorageDiff struct {
+	Key    common.Hash
+	Before []byte
+	After  []byte
+}
+
+// AccountDiff represents the change of an account in a block.
+type AccountDiff struct {
+	Address        common.Address
+	BalanceBefore  *big.Int
+	BalanceAfter   *big.Int
+	NonceBefore    uint64
+	NonceAfter     uint64
+	CodeHashBefore common.Hash
+	CodeHashAfter  common.Hash
+	Storage        []*StorageDiff
+}
+
+// Changed returns whether any field of the account is changed.
+func (diff *AccountDiff) Changed() bool {
+	return diff.BalanceBefore.Cmp(diff.BalanceAfter) != 0 ||
+		diff.NonceBefore != diff.NonceAfter ||
+		!diff.CodeHashBefore.Equal(diff.CodeHashAfter) ||
+		len(diff.Storage) > 0
+}
@@ -7,7 +7,6 @@ package types
 
 import (
 	"crypto/ecdsa"
-	"errors"
 	"fmt"
 	"math/big"
 	"runtime"
@@ -15,7 +14,9 @@ import (
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/crypto"
 	"github.com/scdoproject/go-scdo/trie"
 )
@@ -29,6 +30,19 @@ const (
 	TxTypeReward
 )
 
+// TxCategory is the category of a transaction decoded from its type and addresses, e.g. for the explorers
+type TxCategory string
+
+// Transaction categories
+const (
+	TxCategoryReward         TxCategory = "reward"
+	TxCategoryRegular        TxCategory = "regular"
+	TxCategoryContractDeploy TxCategory = "contract-deploy"
+	TxCategoryContractCall   TxCategory = "contract-call"
+	TxCategorySystemContract TxCategory = "system-contract"
+	TxCategoryCrossShard     TxCategory = "cross-shard"
+)
+
 const (
 	defaultMaxPayloadSize = 32 * 1024
 
@@ -67,6 +81,12 @@ var (
 	// ErrSigMissing is returned when the transaction signature is missing.
 	ErrSigMissing = errors.New("signature missing")
 
+	// ErrAccessListNotActivated is returned when the tx has an access list before the access list fork.
+	ErrAccessListNotActivated = errors.New("access list is not activated")
+
+	// ErrNonceUsedInChain is returned when the transaction nonce has already been used by a transaction in chain.
+	ErrNonceUsedInChain = errors.New("nonce already used in chain, please set a HIGHER nonce")
+
 	emptyTxRootHash = common.EmptyHash
 
 	// MaxPayloadSize limits the payload size to prevent malicious transactions.
@@ -96,6 +116,25 @@ type TransactionData struct {
 	GasLimit     uint64         // Maximum gas for contract creation/execution
 	Timestamp    uint64         // Timestamp is used for the miner reward transaction, referring to the block timestamp
 	Payload      common.Bytes   // Payload is the extra data of the transaction
+
+	// AccessList is the optional accounts and storage keys accessed by the tx, allowed after the access list
+	// fork. It is a tail list, so that the hash of the txs without it is not changed.
+	AccessList AccessList `rlp:"tail"`
+}
+
+// DecodeRLP implements rlp.Decoder, and decodes the absent access list as nil instead of an
+// empty list, so that the decoded tx data is the same as the one without access list.
+func (data *TransactionData) DecodeRLP(s *rlp.Stream) error {
+	type plainTransactionData TransactionData
+	if err := s.Decode((*plainTransactionData)(data)); err != nil {
+		return err
+	}
+
+	if len(data.AccessList) == 0 {
+		data.AccessList = nil
+	}
+
+	return nil
 }
 
 // Transaction represents a transaction in the blockchain.
@@ -132,9 +171,28 @@ func (tx *Transaction) IsCrossShardTx() bool {
 	return !tx.Data.From.IsEmpty() && !tx.Data.To.IsEmpty() && !tx.Data.To.IsReserved() && tx.Data.From.Shard() != tx.Data.To.Shard()
 }
 
+// Category returns the category of the tx. The cross-shard tx is categorized as cross-shard even if it
+// calls a contract, since it is applied as a debt on the other shard.
+func (tx *Transaction) Category() TxCategory {
+	switch {
+	case tx.Data.Type == TxTypeReward:
+		return TxCategoryReward
+	case tx.IsCrossShardTx():
+		return TxCategoryCrossShard
+	case tx.Data.To.IsEmpty():
+		return TxCategoryContractDeploy
+	case tx.Data.To.IsReserved():
+		return TxCategorySystemContract
+	case tx.Data.To.IsEVMContract():
+		return TxCategoryContractCall
+	default:
+		return TxCategoryRegular
+	}
+}
+
 // Size return the transaction size
 func (tx *Transaction) Size() int {
-	return TransactionPreSize + len(tx.Data.Payload)
+	return TransactionPreSize + len(tx.Data.Payload) + tx.Data.AccessList.Size()
 }
 
 // FromAccount returns the sender address of the tx
@@ -319,16 +377,20 @@ func (tx *Transaction) verifySignature() error {
 }
 
 // Validate validates all fields in tx.
-func (tx *Transaction) Validate(statedb stateDB, height uint64) error {
+func (tx *Transaction) Validate(statedb stateDB, config *common.ChainConfig, height uint64) error {
 	if err := tx.ValidateWithoutState(true, true); err != nil {
 		return err
 	}
 
-	return tx.ValidateState(statedb, height)
+	return tx.ValidateState(statedb, config, height)
 }
 
-// ValidateState validates state dependent fields in tx.
-func (tx *Transaction) ValidateState(statedb stateDB, height uint64) error {
+// ValidateState validates state dependent fields in tx, and the fields activated by the forks of the chain config.
+func (tx *Transaction) ValidateState(statedb stateDB, config *common.ChainConfig, height uint64) error {
+	if len(tx.Data.AccessList) > 0 && !config.IsAccessList(height) {
+		return ErrAccessListNotActivated
+	}
+
 	fee := new(big.Int).Mul(tx.Data.GasPrice, new(big.Int).SetUint64(tx.Data.GasLimit))
 	cost := new(big.Int).Add(tx.Data.Amount, fee)
 
@@ -337,7 +399,7 @@ func (tx *Transaction) ValidateState(statedb stateDB, height uint64) error {
 	}
 
 	if accountNonce := statedb.GetNonce(tx.Data.From); tx.Data.AccountNonce < accountNonce {
-		return fmt.Errorf("nonce is too small, account:%s, tx nonce:%d, state db nonce:%d", tx.Data.From.Hex(), tx.Data.AccountNonce, accountNonce)
+		return errors.NewStackedErrorf(ErrNonceUsedInChain, "nonce is too small, account:%s, tx nonce:%d, state db nonce:%d", tx.Data.From.Hex(), tx.Data.AccountNonce, accountNonce)
 	}
 
 	return nil
@@ -430,7 +492,7 @@ func BatchValidate(handler func(index int) error, len int) error {
 
 // IntrinsicGas computes the 'intrinsic gas' for a tx.
 func (tx *Transaction) IntrinsicGas() uint64 {
-	gas := ethIntrinsicGas(tx.Data.Payload)
+	gas := ethIntrinsicGas(tx.Data.Payload) + tx.Data.AccessList.IntrinsicGas()
 
 	if tx.IsCrossShardTx() {
 		return gas * 2
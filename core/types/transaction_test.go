@@ -62,7 +62,7 @@ func newTestStateDB(address common.Address, nonce, balance uint64) *mockStateDB
 func Test_Transaction_Validate_NoDataChange(t *testing.T) {
 	tx := newTestTxWithSign(100, 2, 38, true)
 	statedb := newTestStateDB(tx.Data.From, 38, 200000)
-	err := tx.Validate(statedb)
+	err := tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	assert.Equal(t, err, error(nil))
 }
 
@@ -71,7 +71,7 @@ func Benchmark_Transaction_ValidateWithState(b *testing.B) {
 	statedb := newTestStateDB(tx.Data.From, 38, 200)
 
 	for i := 0; i < b.N; i++ {
-		tx.Validate(statedb)
+		tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	}
 }
 
@@ -127,7 +127,7 @@ func Benchmark_Transaction_MerkleRootHash(b *testing.B) {
 func Test_Transaction_Validate_NotSigned(t *testing.T) {
 	tx := newTestTxWithSign(100, 2, 38, false)
 	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
+	err := tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	assert.Equal(t, err, ErrSigMissing)
 }
 
@@ -136,7 +136,7 @@ func Test_Transaction_Validate_HashChanged(t *testing.T) {
 	tx := newTestTxWithSign(100, 2, 38, true)
 	tx.Hash = crypto.HashBytes([]byte("test"))
 	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
+	err := tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	assert.Equal(t, err, ErrHashMismatch)
 }
 
@@ -145,7 +145,7 @@ func Test_Transaction_Validate_TxDataChanged(t *testing.T) {
 	tx := newTestTxWithSign(100, 2, 38, true)
 	tx.Data.Amount.SetInt64(200)
 	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
+	err := tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	assert.Equal(t, err, ErrHashMismatch)
 }
 
@@ -158,7 +158,7 @@ func Test_Transaction_Validate_SignInvalid(t *testing.T) {
 	tx.Hash = crypto.MustHash(tx.Data)
 
 	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
+	err := tx.Validate(statedb, common.DefaultChainConfig(), 0)
 
 	assert.Equal(t, err, ErrSigInvalid)
 }
@@ -171,14 +171,14 @@ func Test_MerkleRootHash_Empty(t *testing.T) {
 func Test_Transaction_Validate_BalanceNotEnough(t *testing.T) {
 	tx := newTestTxWithSign(100, 2, 38, true)
 	statedb := newTestStateDB(tx.Data.From, 38, 101)
-	err := tx.Validate(statedb)
+	err := tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	assert.Equal(t, err != nil, true)
 }
 
 func Test_Transaction_Validate_NonceTooLow(t *testing.T) {
 	tx := newTestTxWithSign(100, 2, 38, true)
 	statedb := newTestStateDB(tx.Data.From, 40, 200)
-	err := tx.Validate(statedb)
+	err := tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	assert.Equal(t, err != nil, true)
 }
 
@@ -197,7 +197,7 @@ func Test_Transaction_Validate_PayloadOversized(t *testing.T) {
 
 	statedb := newTestStateDB(tx.Data.From, 38, 200)
 
-	err = tx.Validate(statedb)
+	err = tx.Validate(statedb, common.DefaultChainConfig(), 0)
 	assert.Equal(t, err, ErrPayloadOversized)
 }
 
@@ -278,7 +278,7 @@ func Test_Transaction_Validate_EmptyPayloadError(t *testing.T) {
 	tx.Sign(fromPrivKey)
 
 	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	assert.Equal(t, tx.Validate(statedb), ErrPayloadEmpty)
+	assert.Equal(t, tx.Validate(statedb, common.DefaultChainConfig(), 0), ErrPayloadEmpty)
 }
 
 func assertTxRlp(t *testing.T, tx *Transaction) {
@@ -316,6 +316,17 @@ func Test_Transaction_RlpMsgTx(t *testing.T) {
 	assertTxRlp(t, tx)
 }
 
+func Test_Transaction_RlpAccessListTx(t *testing.T) {
+	from := *crypto.MustGenerateRandomAddress()
+	to := *crypto.MustGenerateRandomAddress()
+	tx, err := NewTransaction(from, to, big.NewInt(3), big.NewInt(1), 38)
+	assert.Equal(t, err, nil)
+	assertTxRlp(t, tx)
+
+	tx.Data.AccessList = AccessList{{Address: to, StorageKeys: []common.Hash{common.StringToHash("key")}}}
+	assertTxRlp(t, tx)
+}
+
 func Test_Transaction_InvalidAmount(t *testing.T) {
 	_, fromAddress := randomAccount(t)
 	toAddress := *crypto.MustGenerateRandomAddress()
@@ -368,3 +379,30 @@ func Test_Transaction_SigCache(t *testing.T) {
 	assert.Equal(t, ErrSigInvalid, tx.verifySignature())
 	assert.Equal(t, 2, sigCache.Len())
 }
+
+func Test_Transaction_Category(t *testing.T) {
+	from := *crypto.MustGenerateShardAddress(1)
+	to := *crypto.MustGenerateShardAddress(1)
+
+	tx, err := NewTransaction(from, to, big.NewInt(3), big.NewInt(1), 38)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tx.Category(), TxCategoryRegular)
+
+	tx, err = NewTransaction(from, *crypto.MustGenerateShardAddress(2), big.NewInt(3), big.NewInt(1), 38)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tx.Category(), TxCategoryCrossShard)
+
+	tx, err = NewContractTransaction(from, big.NewInt(3), big.NewInt(1), math.MaxUint64, 38, []byte("test code"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tx.Category(), TxCategoryContractDeploy)
+
+	tx, err = NewMessageTransaction(from, crypto.CreateAddress(from, 38), big.NewInt(3), big.NewInt(1), math.MaxUint64, 38, []byte("test input message"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tx.Category(), TxCategoryContractCall)
+
+	tx.Data.To = common.BytesToAddress([]byte{1, 1})
+	assert.Equal(t, tx.Category(), TxCategorySystemContract)
+
+	tx.Data.Type = TxTypeReward
+	assert.Equal(t, tx.Category(), TxCategoryReward)
+}
@@ -0,0 +1,139 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package vm
+
+import (
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// accessList is the accounts and storage slots accessed in a tx, which are charged the warm cost of EIP-2929.
+// The changes are journaled, so that the accesses in a reverted call are cold again.
+type accessList struct {
+	addresses map[common.Address]map[common.Hash]struct{}
+	journal   []accessListChange
+}
+
+// accessListChange is an address or a slot added to the access list, slot is nil for the address
+type accessListChange struct {
+	address common.Address
+	slot    *common.Hash
+}
+
+func newAccessList() *accessList {
+	return &accessList{
+		addresses: make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+// containsAddress returns whether the address is in the access list
+func (al *accessList) containsAddress(address common.Address) bool {
+	_, ok := al.addresses[address]
+	return ok
+}
+
+// containsSlot returns whether the address and the slot of the address are in the access list
+func (al *accessList) containsSlot(address common.Address, slot common.Hash) (addressOk bool, slotOk bool) {
+	slots, ok := al.addresses[address]
+	if !ok {
+		return false, false
+	}
+
+	_, slotOk = slots[slot]
+	return true, slotOk
+}
+
+// addAddress adds the address to the access list, and returns true if it is not in the list before
+func (al *accessList) addAddress(address common.Address) bool {
+	if al.containsAddress(address) {
+		return false
+	}
+
+	al.addresses[address] = make(map[common.Hash]struct{})
+	al.journal = append(al.journal, accessListChange{address: address})
+	return true
+}
+
+// addSlot adds the address and the slot to the access list, and returns whether they are not in the list before
+func (al *accessList) addSlot(address common.Address, slot common.Hash) (addressAdded bool, slotAdded bool) {
+	addressAdded = al.addAddress(address)
+	if _, ok := al.addresses[address][slot]; ok {
+		return addressAdded, false
+	}
+
+	al.addresses[address][slot] = struct{}{}
+	al.journal = append(al.journal, accessListChange{address: address, slot: &slot})
+	return addressAdded, true
+}
+
+// snapshot returns the revision of the access list, 0 if the access list is not enabled
+func (al *accessList) snapshot() int {
+	if al == nil {
+		return 0
+	}
+
+	return len(al.journal)
+}
+
+// revertToSnapshot removes the addresses and slots added after the revision
+func (al *accessList) revertToSnapshot(revision int) {
+	if al == nil {
+		return
+	}
+
+	for i := len(al.journal) - 1; i >= revision; i-- {
+		change := al.journal[i]
+		if change.slot != nil {
+			delete(al.addresses[change.address], *change.slot)
+		} else {
+			delete(al.addresses, change.address)
+		}
+	}
+
+	al.journal = al.journal[:revision]
+}
+
+// PrepareAccessList warms up the sender, the recipient, the precompiled contracts and the access list of the
+// tx before execution, if the warm/cold gas accounting of EIP-2929 is enabled. The recipient is empty to
+// create a contract, whose address is warmed up once created.
+func (evm *EVM) PrepareAccessList(sender common.Address, dst common.Address, list types.AccessList) {
+	if evm.accessList == nil {
+		return
+	}
+
+	evm.accessList.addAddress(sender)
+	if !dst.IsEmpty() {
+		evm.accessList.addAddress(dst)
+	}
+
+	for addr := range PrecompiledContractsByzantium {
+		evm.accessList.addAddress(addr)
+	}
+
+	for _, tuple := range list {
+		evm.accessList.addAddress(tuple.Address)
+		for _, key := range tuple.StorageKeys {
+			evm.accessList.addSlot(tuple.Address, key)
+		}
+	}
+}
+
+// evmSnapshot is the revision of the state and the access list
+type evmSnapshot struct {
+	state      int
+	accessList int
+}
+
+// snapshot takes a snapshot of the state and the access list
+func (evm *EVM) snapshot() evmSnapshot {
+	return evmSnapshot{evm.StateDB.Snapshot(), evm.accessList.snapshot()}
+}
+
+// revertToSnapshot reverts the state and the access list to the snapshot
+func (evm *EVM) revertToSnapshot(s evmSnapshot) {
+	evm.StateDB.RevertToSnapshot(s.state)
+	evm.accessList.revertToSnapshot(s.accessList)
+}
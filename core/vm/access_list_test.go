@@ -0,0 +1,86 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AccessList_Revert(t *testing.T) {
+	al := newAccessList()
+	addr1 := common.BytesToAddress([]byte{1})
+	addr2 := common.BytesToAddress([]byte{2})
+	slot := common.BytesToHash([]byte{3})
+
+	assert.Equal(t, al.addAddress(addr1), true)
+	assert.Equal(t, al.addAddress(addr1), false)
+
+	revision := al.snapshot()
+	addressAdded, slotAdded := al.addSlot(addr2, slot)
+	assert.Equal(t, addressAdded, true)
+	assert.Equal(t, slotAdded, true)
+
+	addressAdded, slotAdded = al.addSlot(addr1, slot)
+	assert.Equal(t, addressAdded, false)
+	assert.Equal(t, slotAdded, true)
+
+	al.revertToSnapshot(revision)
+	assert.Equal(t, al.containsAddress(addr1), true)
+	assert.Equal(t, al.containsAddress(addr2), false)
+
+	addressOk, slotOk := al.containsSlot(addr1, slot)
+	assert.Equal(t, addressOk, true)
+	assert.Equal(t, slotOk, false)
+
+	// disabled access list
+	var disabled *accessList
+	assert.Equal(t, disabled.snapshot(), 0)
+	disabled.revertToSnapshot(0)
+}
+
+func Test_PrepareAccessList(t *testing.T) {
+	sender := common.BytesToAddress([]byte{0x10})
+	key := common.BytesToHash([]byte{2})
+	tuple := types.AccessTuple{Address: common.BytesToAddress([]byte{0x11}), StorageKeys: []common.Hash{key}}
+
+	evm := &EVM{accessList: newAccessList()}
+	evm.PrepareAccessList(sender, common.EmptyAddress, types.AccessList{tuple})
+
+	assert.Equal(t, evm.accessList.containsAddress(sender), true)
+	assert.Equal(t, evm.accessList.containsAddress(common.EmptyAddress), false)
+	assert.Equal(t, evm.accessList.containsAddress(common.BytesToAddress([]byte{1})), true) // precompiled
+
+	_, slotOk := evm.accessList.containsSlot(tuple.Address, key)
+	assert.Equal(t, slotOk, true)
+
+	// no-op if EIP-2929 is not enabled
+	evm = &EVM{}
+	evm.PrepareAccessList(sender, tuple.Address, nil)
+	assert.Equal(t, evm.accessList == nil, true)
+}
+
+func Test_GasSLoadEIP2929(t *testing.T) {
+	evm := &EVM{accessList: newAccessList()}
+	addr := common.BytesToAddress([]byte{1})
+	contract := NewContract(AccountRef(addr), AccountRef(addr), big.NewInt(0), 100000)
+
+	stack := newstack()
+	stack.push(big.NewInt(7))
+
+	gas, err := gasSLoadEIP2929(params.GasTable{}, evm, contract, stack, nil, 0)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, gas, ColdSloadCostEIP2929)
+
+	gas, err = gasSLoadEIP2929(params.GasTable{}, evm, contract, stack, nil, 0)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, gas, WarmStorageReadCostEIP2929)
+}
@@ -0,0 +1,182 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package vm
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+)
+
+// op code groups of the gas audit
+const (
+	OpGroupStorage      = "storage"      // SLOAD, SSTORE
+	OpGroupCall         = "call"         // CALL, CALLCODE, DELEGATECALL, STATICCALL
+	OpGroupCreate       = "create"       // CREATE, CREATE2
+	OpGroupAccount      = "account"      // BALANCE, EXTCODESIZE, EXTCODECOPY, EXTCODEHASH
+	OpGroupMemory       = "memory"       // MLOAD, MSTORE, MSTORE8, the copy ops and RETURN, REVERT
+	OpGroupLog          = "log"          // LOG0 ~ LOG4
+	OpGroupSha3         = "sha3"         // SHA3
+	OpGroupSelfDestruct = "selfdestruct" // SELFDESTRUCT
+	OpGroupCompute      = "compute"      // all the others, e.g. arithmetic, stack and jump ops
+)
+
+// refund sources of the gas audit
+const (
+	RefundSourceSstore       = "sstore"       // storage cleared or reset, a negative amount if a refund is removed
+	RefundSourceSelfDestruct = "selfdestruct" // contract self-destructed
+	RefundSourceRevert       = "revert"       // refunds dropped by a reverted call or tx, always negative
+)
+
+// refund paths of the tx processing, which apply different refund caps
+const (
+	RefundPathEvm            = "evm"            // evm contract or regular tx, refund capped to half of the used gas
+	RefundPathSystemContract = "systemContract" // system contract, refund capped to half of the used gas
+	RefundPathCrossShard     = "crossShard"     // cross shard tx, never refunded
+	RefundPathEstimate       = "estimate"       // gas estimate, never refunded
+)
+
+// OpGroupGas is the gas used by the ops of a group
+type OpGroupGas struct {
+	Count uint64 // number of the executed ops
+	Gas   uint64 // gas charged for the ops, the gas of the call and create ops includes the gas passed to the callee
+}
+
+// GasAudit is the gas accounting of a tx
+type GasAudit struct {
+	Path           string                 // refund path of the tx, see RefundPathEvm
+	IntrinsicGas   uint64                 // intrinsic gas of the tx
+	UsedGas        uint64                 // used gas before the refund, including the intrinsic gas
+	OpGroups       map[string]*OpGroupGas // gas of the ops by group, see OpGroupStorage
+	RefundSources  map[string]int64       // refund counter changes by source, see RefundSourceSstore
+	SelfDestructs  uint64                 // number of the SELFDESTRUCT ops
+	RefundCounter  uint64                 // refund counter at the end of the tx
+	RefundQuotient uint64                 // refund is capped to UsedGas / RefundQuotient, 0 if not refunded on the path
+	RefundCap      uint64                 // max refund of the tx
+	Refund         uint64                 // refund applied to the tx
+}
+
+// RefundTracer is implemented by the tracers which audit the refund applied after the evm execution
+type RefundTracer interface {
+	CaptureRefund(path string, intrinsicGas, usedGas, counter, quotient, applied uint64)
+}
+
+// GasAuditLogger is an EVM logger which implements Tracer and RefundTracer to audit the gas
+// accounting of a tx, e.g. to validate the refund behavior at the fork heights.
+type GasAuditLogger struct {
+	audit      GasAudit
+	lastRefund uint64
+}
+
+// NewGasAuditLogger returns a new gas audit logger
+func NewGasAuditLogger() *GasAuditLogger {
+	return &GasAuditLogger{
+		audit: GasAudit{
+			OpGroups:      make(map[string]*OpGroupGas),
+			RefundSources: make(map[string]int64),
+		},
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (l *GasAuditLogger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState aggregates the gas cost of the op into its group, and attributes the refund counter
+// changed since the last op. The refunds of SSTORE and SELFDESTRUCT are added when the gas cost is
+// calculated, which is before the op is captured.
+func (l *GasAuditLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	group := l.audit.OpGroups[opGroup(op)]
+	if group == nil {
+		group = &OpGroupGas{}
+		l.audit.OpGroups[opGroup(op)] = group
+	}
+
+	group.Count++
+	group.Gas += cost
+
+	if op == SELFDESTRUCT {
+		l.audit.SelfDestructs++
+	}
+
+	refund := env.StateDB.GetRefund()
+	if refund != l.lastRefund {
+		delta := int64(refund) - int64(l.lastRefund)
+		switch {
+		case op == SSTORE:
+			l.audit.RefundSources[RefundSourceSstore] += delta
+		case op == SELFDESTRUCT && delta > 0:
+			l.audit.RefundSources[RefundSourceSelfDestruct] += delta
+		default:
+			l.audit.RefundSources[RefundSourceRevert] += delta
+		}
+
+		l.lastRefund = refund
+	}
+
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (l *GasAuditLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (l *GasAuditLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	return nil
+}
+
+// CaptureRefund implements the RefundTracer interface to record the refund applied to the tx. The refunds
+// dropped after the last op, e.g. the tx is reverted, are attributed to revert.
+func (l *GasAuditLogger) CaptureRefund(path string, intrinsicGas, usedGas, counter, quotient, applied uint64) {
+	if counter != l.lastRefund {
+		l.audit.RefundSources[RefundSourceRevert] += int64(counter) - int64(l.lastRefund)
+		l.lastRefund = counter
+	}
+
+	l.audit.Path = path
+	l.audit.IntrinsicGas = intrinsicGas
+	l.audit.UsedGas = usedGas
+	l.audit.RefundCounter = counter
+	l.audit.RefundQuotient = quotient
+	if quotient > 0 {
+		l.audit.RefundCap = usedGas / quotient
+	}
+	l.audit.Refund = applied
+}
+
+// GasAudit returns the gas audit of the tx
+func (l *GasAuditLogger) GasAudit() *GasAudit {
+	return &l.audit
+}
+
+func opGroup(op OpCode) string {
+	switch {
+	case op == SLOAD || op == SSTORE:
+		return OpGroupStorage
+	case op == CALL || op == CALLCODE || op == DELEGATECALL || op == STATICCALL:
+		return OpGroupCall
+	case op == CREATE || op == CREATE2:
+		return OpGroupCreate
+	case op == BALANCE || op == EXTCODESIZE || op == EXTCODECOPY || op == EXTCODEHASH:
+		return OpGroupAccount
+	case op == MLOAD || op == MSTORE || op == MSTORE8 || op == CALLDATACOPY || op == CODECOPY ||
+		op == RETURNDATACOPY || op == RETURN || op == REVERT:
+		return OpGroupMemory
+	case op >= LOG0 && op <= LOG4:
+		return OpGroupLog
+	case op == SHA3:
+		return OpGroupSha3
+	case op == SELFDESTRUCT:
+		return OpGroupSelfDestruct
+	default:
+		return OpGroupCompute
+	}
+}
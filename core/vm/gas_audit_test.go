@@ -0,0 +1,87 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type refundStateDB struct {
+	StateDB
+	refund uint64
+}
+
+func (db *refundStateDB) GetRefund() uint64 {
+	return db.refund
+}
+
+func Test_GasAuditLogger(t *testing.T) {
+	db := &refundStateDB{}
+	evm := &EVM{StateDB: db}
+	addr := common.BytesToAddress([]byte{1})
+	contract := NewContract(AccountRef(addr), AccountRef(addr), big.NewInt(0), 100000)
+	logger := NewGasAuditLogger()
+
+	capture := func(op OpCode, cost uint64) {
+		assert.Equal(t, logger.CaptureState(evm, 0, op, 100000, cost, NewMemory(), newstack(), contract, 1, nil), nil)
+	}
+
+	capture(PUSH1, 3)
+	capture(MSTORE, 6)
+
+	// storage cleared
+	db.refund = 15000
+	capture(SSTORE, 5000)
+	capture(SLOAD, 800)
+
+	// self destructed
+	db.refund = 39000
+	capture(SELFDESTRUCT, 5000)
+
+	// the sstore refund dropped by a reverted call
+	db.refund = 24000
+	capture(STOP, 0)
+
+	logger.CaptureRefund(RefundPathEvm, 21000, 60000, 24000, 2, 24000)
+	audit := logger.GasAudit()
+
+	assert.Equal(t, *audit.OpGroups[OpGroupCompute], OpGroupGas{Count: 2, Gas: 3})
+	assert.Equal(t, *audit.OpGroups[OpGroupMemory], OpGroupGas{Count: 1, Gas: 6})
+	assert.Equal(t, *audit.OpGroups[OpGroupStorage], OpGroupGas{Count: 2, Gas: 5800})
+	assert.Equal(t, *audit.OpGroups[OpGroupSelfDestruct], OpGroupGas{Count: 1, Gas: 5000})
+	assert.Equal(t, audit.SelfDestructs, uint64(1))
+	assert.Equal(t, audit.RefundSources, map[string]int64{
+		RefundSourceSstore:       15000,
+		RefundSourceSelfDestruct: 24000,
+		RefundSourceRevert:       -15000,
+	})
+	assert.Equal(t, audit.Path, RefundPathEvm)
+	assert.Equal(t, audit.RefundCap, uint64(30000))
+	assert.Equal(t, audit.Refund, uint64(24000))
+}
+
+func Test_GasAuditLogger_RevertedTx(t *testing.T) {
+	db := &refundStateDB{refund: 15000}
+	evm := &EVM{StateDB: db}
+	addr := common.BytesToAddress([]byte{1})
+	contract := NewContract(AccountRef(addr), AccountRef(addr), big.NewInt(0), 100000)
+	logger := NewGasAuditLogger()
+
+	assert.Equal(t, logger.CaptureState(evm, 0, SSTORE, 100000, 5000, NewMemory(), newstack(), contract, 1, nil), nil)
+
+	// all the refunds are dropped once the tx is reverted, and not refunded on the estimate path
+	logger.CaptureRefund(RefundPathEstimate, 21000, 26000, 0, 0, 0)
+	audit := logger.GasAudit()
+
+	assert.Equal(t, audit.RefundSources[RefundSourceSstore], int64(15000))
+	assert.Equal(t, audit.RefundSources[RefundSourceRevert], int64(-15000))
+	assert.Equal(t, audit.RefundCounter, uint64(0))
+	assert.Equal(t, audit.RefundCap, uint64(0))
+}
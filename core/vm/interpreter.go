@@ -46,6 +46,9 @@ type Config struct {
 	EWASMInterpreter string
 	// Type of the EVM interpreter
 	EVMInterpreter string
+
+	// EIP2929 enables the warm/cold gas costs of the state access, after the access list fork
+	EIP2929 bool
 }
 
 // Interpreter is used to run Ethereum based contracts and will utilise the
@@ -100,6 +103,8 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 	// we'll set the default jump table.
 	if !cfg.JumpTable[STOP].valid {
 		switch {
+		case cfg.EIP2929:
+			cfg.JumpTable = accessListInstructionSet
 		case evm.ChainConfig().IsIstanbul(evm.BlockNumber):
 			cfg.JumpTable = istanbulInstructionSet
 		case evm.ChainConfig().IsConstantinople(evm.BlockNumber):
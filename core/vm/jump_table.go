@@ -56,8 +56,26 @@ var (
 	byzantiumInstructionSet      = newByzantiumInstructionSet()
 	constantinopleInstructionSet = newConstantinopleInstructionSet()
 	istanbulInstructionSet       = newIstanbulInstructionSet()
+	accessListInstructionSet     = newAccessListInstructionSet()
 )
 
+// newAccessListInstructionSet returns the istanbul instructions with the warm/cold gas costs of EIP-2929.
+func newAccessListInstructionSet() [256]operation {
+	instructionSet := newIstanbulInstructionSet()
+	instructionSet[SLOAD].gasCost = gasSLoadEIP2929
+	instructionSet[SSTORE].gasCost = gasSStoreEIP2929
+	instructionSet[BALANCE].gasCost = gasAccountCheckEIP2929
+	instructionSet[EXTCODESIZE].gasCost = gasAccountCheckEIP2929
+	instructionSet[EXTCODEHASH].gasCost = gasAccountCheckEIP2929
+	instructionSet[EXTCODECOPY].gasCost = gasExtCodeCopyEIP2929
+	instructionSet[CALL].gasCost = makeCallVariantGasEIP2929(gasCall)
+	instructionSet[CALLCODE].gasCost = makeCallVariantGasEIP2929(gasCallCode)
+	instructionSet[DELEGATECALL].gasCost = makeCallVariantGasEIP2929(gasDelegateCall)
+	instructionSet[STATICCALL].gasCost = makeCallVariantGasEIP2929(gasStaticCall)
+	instructionSet[SELFDESTRUCT].gasCost = gasSuicideEIP2929
+	return instructionSet
+}
+
 // NewIstanbulInstructionSet returns the frontier, homestead
 // byzantium, contantinople and istanbul instructions.
 func newIstanbulInstructionSet() [256]operation {
@@ -0,0 +1,146 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package vm
+
+import (
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/scdoproject/go-scdo/common"
+)
+
+// The gas costs of the state access defined in EIP-2929
+const (
+	ColdAccountAccessCostEIP2929 = uint64(2600) // COLD_ACCOUNT_ACCESS_COST
+	ColdSloadCostEIP2929         = uint64(2100) // COLD_SLOAD_COST
+	WarmStorageReadCostEIP2929   = uint64(100)  // WARM_STORAGE_READ_COST
+)
+
+// gasSLoadEIP2929 charges the cold cost for the first access of a slot in the tx, and the warm cost otherwise
+func gasSLoadEIP2929(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	slot := common.BigToHash(stack.Back(0))
+	if _, slotAdded := evm.accessList.addSlot(contract.Address(), slot); slotAdded {
+		return ColdSloadCostEIP2929, nil
+	}
+
+	return WarmStorageReadCostEIP2929, nil
+}
+
+// gasSStoreEIP2929 is the net gas metering of EIP-1283 with the warm/cold costs of EIP-2929, i.e. the cold
+// slot is charged COLD_SLOAD_COST additionally, and the SLOAD_GAS in the costs and refunds is replaced by
+// WARM_STORAGE_READ_COST.
+func gasSStoreEIP2929(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	var (
+		y, x = stack.Back(1), stack.Back(0)
+		slot = common.BigToHash(x)
+		cost uint64
+	)
+
+	if _, slotAdded := evm.accessList.addSlot(contract.Address(), slot); slotAdded {
+		cost = ColdSloadCostEIP2929
+	}
+
+	value := common.BigToHash(y)
+	current := evm.StateDB.GetState(contract.Address(), slot)
+	if current == value { // noop
+		return cost + WarmStorageReadCostEIP2929, nil
+	}
+
+	original := evm.StateDB.GetCommittedState(contract.Address(), slot)
+	if original == current {
+		if original == (common.Hash{}) { // create slot
+			return cost + params.SstoreSetGas, nil
+		}
+		if value == (common.Hash{}) { // delete slot
+			evm.StateDB.AddRefund(params.NetSstoreClearRefund)
+		}
+		return cost + (params.SstoreResetGas - ColdSloadCostEIP2929), nil // write existing slot
+	}
+
+	if original != (common.Hash{}) {
+		if current == (common.Hash{}) { // recreate slot
+			evm.StateDB.SubRefund(params.NetSstoreClearRefund)
+		} else if value == (common.Hash{}) { // delete slot
+			evm.StateDB.AddRefund(params.NetSstoreClearRefund)
+		}
+	}
+
+	if original == value {
+		if original == (common.Hash{}) { // reset to original inexistent slot
+			evm.StateDB.AddRefund(params.SstoreSetGas - WarmStorageReadCostEIP2929)
+		} else { // reset to original existing slot
+			evm.StateDB.AddRefund(params.SstoreResetGas - ColdSloadCostEIP2929 - WarmStorageReadCostEIP2929)
+		}
+	}
+
+	return cost + WarmStorageReadCostEIP2929, nil // dirty update
+}
+
+// accountAccessCost returns the cold cost for the first access of an account in the tx, and the warm cost otherwise
+func accountAccessCost(evm *EVM, address common.Address) uint64 {
+	if evm.accessList.addAddress(address) {
+		return ColdAccountAccessCostEIP2929
+	}
+
+	return WarmStorageReadCostEIP2929
+}
+
+// gasAccountCheckEIP2929 is the gas of BALANCE, EXTCODESIZE and EXTCODEHASH
+func gasAccountCheckEIP2929(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return accountAccessCost(evm, common.BigToAddress(stack.Back(0))), nil
+}
+
+func gasExtCodeCopyEIP2929(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	gt.ExtcodeCopy = accountAccessCost(evm, common.BigToAddress(stack.Back(0)))
+	return gasExtCodeCopy(gt, evm, contract, stack, mem, memorySize)
+}
+
+// makeCallVariantGasEIP2929 replaces the base cost of the call variant by the warm cost, and charges the cold
+// surcharge for the first access of the callee in the tx. The surcharge is deducted before the gas passed to
+// the callee is calculated by the 63/64 rule.
+func makeCallVariantGasEIP2929(oldCalculator gasFunc) gasFunc {
+	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		var coldCost uint64
+		if evm.accessList.addAddress(common.BigToAddress(stack.Back(1))) {
+			coldCost = ColdAccountAccessCostEIP2929 - WarmStorageReadCostEIP2929
+			if !contract.UseGas(coldCost) {
+				return 0, ErrOutOfGas
+			}
+		}
+
+		gt.Calls = WarmStorageReadCostEIP2929
+		gas, err := oldCalculator(gt, evm, contract, stack, mem, memorySize)
+
+		// the cold surcharge is charged by the interpreter together with the returned gas
+		contract.Gas += coldCost
+		if err != nil {
+			return 0, err
+		}
+
+		var overflow bool
+		if gas, overflow = math.SafeAdd(gas, coldCost); overflow {
+			return 0, errGasUintOverflow
+		}
+
+		return gas, nil
+	}
+}
+
+// gasSuicideEIP2929 charges the cold cost additionally for the first access of the beneficiary in the tx
+func gasSuicideEIP2929(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	gas, err := gasSuicide(gt, evm, contract, stack, mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+
+	if evm.accessList.addAddress(common.BigToAddress(stack.Back(0))) {
+		var overflow bool
+		if gas, overflow = math.SafeAdd(gas, ColdAccountAccessCostEIP2929); overflow {
+			return 0, errGasUintOverflow
+		}
+	}
+
+	return gas, nil
+}
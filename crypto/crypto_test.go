@@ -6,6 +6,7 @@
 package crypto
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/scdoproject/go-scdo/common"
@@ -146,6 +147,17 @@ func Test_CreateAddress_Shard(t *testing.T) {
 	assert.Equal(t, contractAddr.Shard(), uint(2))
 }
 
+func Test_CreateAddress2_Shard(t *testing.T) {
+	fromAddr := MustGenerateShardAddress(2)
+	codeHash := Keccak256Hash([]byte("code")).Bytes()
+
+	contractAddr := CreateAddress2(*fromAddr, common.BigToHash(big.NewInt(1)), codeHash)
+	assert.Equal(t, contractAddr.Shard(), uint(2))
+	assert.Equal(t, contractAddr.Type(), common.AddressTypeContract)
+	assert.Equal(t, contractAddr, CreateAddress2(*fromAddr, common.BigToHash(big.NewInt(1)), codeHash))
+	assert.Equal(t, contractAddr == CreateAddress2(*fromAddr, common.BigToHash(big.NewInt(2)), codeHash), false)
+}
+
 func Test_MustGenerateShardAddress(t *testing.T) {
 	addr := MustGenerateShardAddress(2)
 	assert.Equal(t, addr.Shard(), uint(2))
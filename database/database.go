@@ -26,3 +26,16 @@ type Batch interface {
 	Commit() error
 	Rollback()
 }
+
+// Checkpointer is the database which copies a consistent point-in-time view of itself into a new database
+type Checkpointer interface {
+	Checkpoint(path string) error
+}
+
+const (
+	// EngineLevelDB is the default database engine which persists the data on disk
+	EngineLevelDB = "leveldb"
+
+	// EngineMemory is the in-memory database engine, the data is lost once the node stops
+	EngineMemory = "memory"
+)
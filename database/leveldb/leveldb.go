@@ -12,8 +12,12 @@ import (
 	"github.com/scdoproject/go-scdo/database"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+// checkpointBatchSize is the number of keys written in a batch when copying the database
+const checkpointBatchSize = 10000
+
 var (
 	// ErrEmptyKey key is empty
 	ErrEmptyKey = errors.New("key could not be empty")
@@ -97,6 +101,49 @@ func (db *LevelDB) DeleteSring(key string) error {
 	return db.Delete([]byte(key))
 }
 
+// Compact compacts the whole database, which reclaims the disk space of the deleted keys
+func (db *LevelDB) Compact() error {
+	return db.db.CompactRange(util.Range{})
+}
+
+// Checkpoint copies a consistent point-in-time view of the whole database into a new database at the path,
+// while the database is still being written.
+func (db *LevelDB) Checkpoint(path string) error {
+	snapshot, err := db.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snapshot.Release()
+
+	dst, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	iter := snapshot.NewIterator(nil, nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Put(iter.Key(), iter.Value())
+		if batch.Len() < checkpointBatchSize {
+			continue
+		}
+
+		if err = dst.Write(batch, nil); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+
+	if err = iter.Error(); err != nil {
+		return err
+	}
+
+	return dst.Write(batch, nil)
+}
+
 // NewBatch constructs and returns a batch object
 func (db *LevelDB) NewBatch() database.Batch {
 	batch := &Batch{
@@ -108,6 +108,34 @@ func Test_LevelDB_Newbatch(t *testing.T) {
 	}
 }
 
+func Test_LevelDB_Checkpoint(t *testing.T) {
+	dir := prepareDbFolder("", "leveldbtest")
+	defer os.RemoveAll(dir)
+	db := newDbInstance(dir)
+	defer db.Close()
+
+	assert.Equal(t, db.PutString("1", "2"), nil)
+	assert.Equal(t, db.PutString("3", "4"), nil)
+
+	backup := prepareDbFolder("", "leveldbcheckpoint")
+	defer os.RemoveAll(backup)
+	assert.Equal(t, db.(database.Checkpointer).Checkpoint(backup), nil)
+
+	// changes after the checkpoint are not copied
+	assert.Equal(t, db.PutString("5", "6"), nil)
+
+	copied := newDbInstance(backup)
+	defer copied.Close()
+
+	value, err := copied.GetString("3")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "4")
+
+	exist, err := copied.HasString("5")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, exist, false)
+}
+
 func prepareDbFolder(pathRoot string, subDir string) string {
 	dir, err := ioutil.TempDir(pathRoot, subDir)
 	if err != nil {
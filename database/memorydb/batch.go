@@ -0,0 +1,50 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package memorydb
+
+// batchOp is a put or delete operation of the batch
+type batchOp struct {
+	key    string
+	value  []byte
+	delete bool
+}
+
+// Batch implements batch for the memory database, the operations are applied atomically on commit
+type Batch struct {
+	db  *MemoryDB
+	ops []batchOp
+}
+
+// Put sets the value for the given key
+func (b *Batch) Put(key []byte, value []byte) {
+	b.ops = append(b.ops, batchOp{string(key), copyBytes(value), false})
+}
+
+// Delete deletes the value for the given key.
+func (b *Batch) Delete(key []byte) {
+	b.ops = append(b.ops, batchOp{string(key), nil, true})
+}
+
+// Commit commits batch operation.
+func (b *Batch) Commit() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	for _, op := range b.ops {
+		if op.delete {
+			delete(b.db.db, op.key)
+		} else {
+			b.db.db[op.key] = op.value
+		}
+	}
+
+	return nil
+}
+
+// Rollback rollbacks batch operation.
+func (b *Batch) Rollback() {
+	b.ops = nil
+}
@@ -0,0 +1,136 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package memorydb
+
+import (
+	"sync"
+
+	"github.com/scdoproject/go-scdo/database"
+	"github.com/syndtr/goleveldb/leveldb/errors"
+)
+
+var (
+	// ErrEmptyKey key is empty
+	ErrEmptyKey = errors.New("key could not be empty")
+)
+
+// MemoryDB is a pure in-memory database, which is used by the unit tests and the ephemeral nodes.
+// The missing keys are reported with the same ErrNotFound of the leveldb, so it is interchangeable
+// with the leveldb.
+type MemoryDB struct {
+	lock sync.RWMutex
+	db   map[string][]byte
+}
+
+// NewMemoryDB constructs and returns a MemoryDB instance
+func NewMemoryDB() database.Database {
+	return &MemoryDB{
+		db: make(map[string][]byte),
+	}
+}
+
+// Close is used to close the db when not used
+func (db *MemoryDB) Close() {}
+
+// GetString gets the value for the given key
+func (db *MemoryDB) GetString(key string) (string, error) {
+	value, err := db.Get([]byte(key))
+
+	return string(value), err
+}
+
+// Get gets the value for the given key
+func (db *MemoryDB) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	value, ok := db.db[string(key)]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	return copyBytes(value), nil
+}
+
+// Put sets the value for the given key
+func (db *MemoryDB) Put(key []byte, value []byte) error {
+	if len(key) < 1 {
+		return ErrEmptyKey
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.db[string(key)] = copyBytes(value)
+
+	return nil
+}
+
+// PutString sets the value for the given key
+func (db *MemoryDB) PutString(key string, value string) error {
+	return db.Put([]byte(key), []byte(value))
+}
+
+// Has returns true if the DB does contain the given key.
+func (db *MemoryDB) Has(key []byte) (ret bool, err error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	_, ok := db.db[string(key)]
+
+	return ok, nil
+}
+
+// HasString returns true if the DB does contain the given key.
+func (db *MemoryDB) HasString(key string) (ret bool, err error) {
+	return db.Has([]byte(key))
+}
+
+// Delete deletes the value for the given key.
+func (db *MemoryDB) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	delete(db.db, string(key))
+
+	return nil
+}
+
+// DeleteSring deletes the value for the given key.
+func (db *MemoryDB) DeleteSring(key string) error {
+	return db.Delete([]byte(key))
+}
+
+// Len returns the number of keys in the database
+func (db *MemoryDB) Len() int {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return len(db.db)
+}
+
+// NewBatch constructs and returns a batch object
+func (db *MemoryDB) NewBatch() database.Batch {
+	return &Batch{db: db}
+}
+
+// NewTestDatabase creates an in-memory database instance for the unit tests.
+func NewTestDatabase() (db database.Database, dispose func()) {
+	db = NewMemoryDB()
+
+	return db, db.Close
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+
+	c := make([]byte, len(b))
+	copy(c, b)
+
+	return c
+}
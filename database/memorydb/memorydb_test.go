@@ -0,0 +1,70 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package memorydb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb/errors"
+)
+
+func Test_MemoryDB(t *testing.T) {
+	db, dispose := NewTestDatabase()
+	defer dispose()
+
+	_, err := db.Get([]byte("1"))
+	assert.Equal(t, err, errors.ErrNotFound)
+	assert.Equal(t, db.Put(nil, []byte("1")), ErrEmptyKey)
+
+	value := []byte("11")
+	assert.Equal(t, db.Put([]byte("1"), value), nil)
+	value[0] = '2' // the value is copied on put
+
+	got, err := db.Get([]byte("1"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, got, []byte("11"))
+
+	exist, err := db.HasString("1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, exist, true)
+
+	assert.Equal(t, db.DeleteSring("1"), nil)
+	exist, err = db.HasString("1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, exist, false)
+}
+
+func Test_MemoryDB_Batch(t *testing.T) {
+	db, dispose := NewTestDatabase()
+	defer dispose()
+
+	assert.Equal(t, db.PutString("3", "33"), nil)
+
+	batch := db.NewBatch()
+	batch.Put([]byte("1"), []byte("11"))
+	batch.Put([]byte("2"), []byte("22"))
+	batch.Delete([]byte("3"))
+
+	// not applied before commit
+	_, err := db.GetString("1")
+	assert.Equal(t, err, errors.ErrNotFound)
+
+	assert.Equal(t, batch.Commit(), nil)
+	value, err := db.GetString("2")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "22")
+	_, err = db.GetString("3")
+	assert.Equal(t, err, errors.ErrNotFound)
+	assert.Equal(t, db.(*MemoryDB).Len(), 2)
+
+	// rollback discards the operations
+	batch = db.NewBatch()
+	batch.Put([]byte("4"), []byte("44"))
+	batch.Rollback()
+	assert.Equal(t, batch.Commit(), nil)
+	assert.Equal(t, db.(*MemoryDB).Len(), 2)
+}
@@ -25,3 +25,12 @@ var TransactionInsertedEventManager = NewEventManager()
 var ChainHeaderChangedEventMananger = NewEventManager()
 
 var DebtsInsertedEventManager = NewEventManager()
+
+// DeepReorgEventManager represents the event that a reorg deeper than the max reorg depth is refused
+var DeepReorgEventManager = NewEventManager()
+
+// BlockImportedEventManager represents the event that a block is written into the chain with its receipts and state diff
+var BlockImportedEventManager = NewEventManager()
+
+// WatchdogEventManager represents the event that the watchdog detects a stall
+var WatchdogEventManager = NewEventManager()
@@ -0,0 +1,82 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package light
+
+import (
+	"math/big"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+)
+
+// PublicLightClientAPI provides an API to query the accounts with merkle proofs verified by the light client
+type PublicLightClientAPI struct {
+	l *LightBackend
+}
+
+// NewPublicLightClientAPI creates a new PublicLightClientAPI object for rpc service.
+func NewPublicLightClientAPI(l *LightBackend) *PublicLightClientAPI {
+	return &PublicLightClientAPI{l}
+}
+
+// blockHash returns the hash of the block given the block hash or height, the empty hash for the current block
+func (api *PublicLightClientAPI) blockHash(hexHash string, height int64) (common.Hash, error) {
+	if len(hexHash) > 0 {
+		hash, err := common.HexToHash(hexHash)
+		if err != nil {
+			return common.EmptyHash, errors.NewStackedError(err, "failed to convert HEX to hash")
+		}
+
+		return hash, nil
+	}
+
+	if height < 0 {
+		return common.EmptyHash, nil
+	}
+
+	hash, err := api.l.ChainBackend().GetStore().GetBlockHash(uint64(height))
+	if err != nil {
+		return common.EmptyHash, errors.NewStackedErrorf(err, "failed to get block hash by height %v", height)
+	}
+
+	return hash, nil
+}
+
+// GetBalance returns the balance of the account at the block, which is proven against the state root of the block
+func (api *PublicLightClientAPI) GetBalance(account common.Address, hexHash string, height int64) (*big.Int, error) {
+	hash, err := api.blockHash(hexHash, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.l.GetBalance(account, hash)
+}
+
+// GetNonce returns the nonce of the account at the block, which is proven against the state root of the block
+func (api *PublicLightClientAPI) GetNonce(account common.Address, hexHash string, height int64) (uint64, error) {
+	hash, err := api.blockHash(hexHash, height)
+	if err != nil {
+		return 0, err
+	}
+
+	return api.l.GetNonce(account, hash)
+}
+
+// GetCode returns the code of the contract at the block, which is proven against the state root of the block
+func (api *PublicLightClientAPI) GetCode(contract common.Address, hexHash string, height int64) (string, error) {
+	hash, err := api.blockHash(hexHash, height)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := api.l.GetCode(contract, hash)
+	if err != nil {
+		return "", err
+	}
+
+	return hexutil.BytesToHex(code), nil
+}
@@ -0,0 +1,25 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package light
+
+import (
+	"time"
+)
+
+// PublicLightServerAPI provides an API to access the light server
+type PublicLightServerAPI struct {
+	s *ServiceServer
+}
+
+// NewPublicLightServerAPI creates a new PublicLightServerAPI object for rpc service.
+func NewPublicLightServerAPI(s *ServiceServer) *PublicLightServerAPI {
+	return &PublicLightServerAPI{s}
+}
+
+// ServerStats returns the load statistics and the request accounting of the connected light clients
+func (api *PublicLightServerAPI) ServerStats() *ServerStats {
+	return api.s.scdoProtocol.load.stats(time.Now())
+}
@@ -117,5 +117,13 @@ func (s *ServiceClient) Stop() error {
 
 // APIs implements node.Service, returning the collection of RPC services the scdo package offers.
 func (s *ServiceClient) APIs() (apis []rpc.API) {
-	return append(apis, api.GetAPIs(NewLightBackend(s))...)
+	backend := NewLightBackend(s)
+	apis = append(apis, api.GetAPIs(backend)...)
+
+	return append(apis, rpc.API{
+		Namespace: "light",
+		Version:   "1.0",
+		Service:   NewPublicLightClientAPI(backend),
+		Public:    true,
+	})
 }
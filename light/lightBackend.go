@@ -6,6 +6,7 @@ import (
 	"github.com/scdoproject/go-scdo/api"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/state"
 	"github.com/scdoproject/go-scdo/core/store"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/log"
@@ -113,6 +114,36 @@ func (l *LightBackend) RemoveTransaction(txHash common.Hash) {
 	l.s.txPool.Remove(txHash)
 }
 
+// TxResult is the tx and its index retrieved in batch, and Err is the error to retrieve the tx if any.
+type TxResult struct {
+	Tx         *types.Transaction
+	BlockIndex *api.BlockIndex
+	Err        error
+}
+
+// GetTransactions returns the txs and their indexes of the specified hashes, which are retrieved
+// from the remote peer in a few requests. The results are in the order of the hashes.
+func (l *LightBackend) GetTransactions(txHashes []common.Hash) ([]*TxResult, error) {
+	results := make([]*TxResult, len(txHashes))
+	for start := 0; start < len(txHashes); start += maxTxsPerRequest {
+		end := start + maxTxsPerRequest
+		if end > len(txHashes) {
+			end = len(txHashes)
+		}
+
+		response, err := l.s.odrBackend.retrieve(&odrTxsByHashRequest{TxHashes: txHashes[start:end]})
+		if err != nil {
+			return nil, err
+		}
+
+		for i, tx := range response.(*odrTxsByHashResponse).Txs {
+			results[start+i] = &TxResult{tx.Tx, tx.BlockIndex, tx.getError()}
+		}
+	}
+
+	return results, nil
+}
+
 // GetDebt returns the debt and its index for the specified debt hash.
 func (l *LightBackend) GetDebt(debtHash common.Hash) (*types.Debt, *api.BlockIndex, error) {
 	response, err := l.s.odrBackend.retrieve(&odrDebtRequest{DebtHash: debtHash})
@@ -124,3 +155,49 @@ func (l *LightBackend) GetDebt(debtHash common.Hash) (*types.Debt, *api.BlockInd
 
 	return result.Debt, result.BlockIndex, nil
 }
+
+// getProvenAccount retrieves the account with the merkle proof against the state root of the block,
+// which is verified with the locally synced header. The current header is used if the hash is empty.
+func (l *LightBackend) getProvenAccount(account common.Address, blockHash common.Hash) (*state.ProvenAccount, error) {
+	if blockHash.IsEmpty() {
+		blockHash = l.ChainBackend().CurrentHeader().Hash()
+	}
+
+	filter := peerFilter{blockHash: blockHash}
+	response, err := l.s.odrBackend.retrieveWithFilter(&odrAccountRequest{BlockHash: blockHash, Account: account}, filter)
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to retrieve ODR account proof")
+	}
+
+	return response.(*odrAccountResponse).provenAccount, nil
+}
+
+// GetBalance returns the proven balance of the account at the block, 0 for a nonexistent account.
+func (l *LightBackend) GetBalance(account common.Address, blockHash common.Hash) (*big.Int, error) {
+	proven, err := l.getProvenAccount(account, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return proven.Balance, nil
+}
+
+// GetNonce returns the proven nonce of the account at the block, 0 for a nonexistent account.
+func (l *LightBackend) GetNonce(account common.Address, blockHash common.Hash) (uint64, error) {
+	proven, err := l.getProvenAccount(account, blockHash)
+	if err != nil {
+		return 0, err
+	}
+
+	return proven.Nonce, nil
+}
+
+// GetCode returns the proven contract code of the account at the block, nil if not a contract.
+func (l *LightBackend) GetCode(account common.Address, blockHash common.Hash) ([]byte, error) {
+	proven, err := l.getProvenAccount(account, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return proven.Code, nil
+}
@@ -27,6 +27,7 @@ type LightChain struct {
 	bcStore                   store.BlockchainStore
 	odrBackend                *odrBackend
 	engine                    consensus.Engine
+	chainConfig               *common.ChainConfig
 	currentHeader             *types.BlockHeader
 	canonicalTD               *big.Int
 	headerChangedEventManager *event.EventManager
@@ -62,9 +63,18 @@ func newLightChain(bcStore store.BlockchainStore, lightDB database.Database, odr
 
 	chain.canonicalTD = td
 
+	if chain.chainConfig, err = core.GetChainConfig(bcStore); err != nil {
+		return nil, err
+	}
+
 	return chain, nil
 }
 
+// Config returns the chain config
+func (lc *LightChain) Config() *common.ChainConfig {
+	return lc.chainConfig
+}
+
 // GetState get statedb by root hash(not supported, just implement the interface here)
 func (lc *LightChain) GetState(root common.Hash) (*state.Statedb, error) {
 	panic("unsupported")
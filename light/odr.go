@@ -11,6 +11,9 @@ import (
 	"github.com/scdoproject/go-scdo/core/store"
 )
 
+// maxTxsPerRequest is the max number of txs retrieved in a single txsByHash request
+const maxTxsPerRequest = 64
+
 const (
 	blockRequestCode uint16 = 10 + iota
 	blockResponseCode
@@ -24,26 +27,34 @@ const (
 	txByHashResponseCode
 	debtRequestCode
 	debtResponseCode
+	txsByHashRequestCode
+	txsByHashResponseCode
+	accountRequestCode
+	accountResponseCode
 	protocolMsgCodeLength // protocolMsgCodeLength always defined in the end.
 )
 
 var (
 	odrRequestFactories = map[uint16]func() odrRequest{
-		blockRequestCode:    func() odrRequest { return &odrBlock{} },
-		addTxRequestCode:    func() odrRequest { return &odrAddTx{} },
-		trieRequestCode:     func() odrRequest { return &odrTriePoof{} },
-		receiptRequestCode:  func() odrRequest { return &odrReceiptRequest{} },
-		txByHashRequestCode: func() odrRequest { return &odrTxByHashRequest{} },
-		debtRequestCode:     func() odrRequest { return &odrDebtRequest{} },
+		blockRequestCode:     func() odrRequest { return &odrBlock{} },
+		addTxRequestCode:     func() odrRequest { return &odrAddTx{} },
+		trieRequestCode:      func() odrRequest { return &odrTriePoof{} },
+		receiptRequestCode:   func() odrRequest { return &odrReceiptRequest{} },
+		txByHashRequestCode:  func() odrRequest { return &odrTxByHashRequest{} },
+		debtRequestCode:      func() odrRequest { return &odrDebtRequest{} },
+		txsByHashRequestCode: func() odrRequest { return &odrTxsByHashRequest{} },
+		accountRequestCode:   func() odrRequest { return &odrAccountRequest{} },
 	}
 
 	odrResponseFactories = map[uint16]func() odrResponse{
-		blockResponseCode:    func() odrResponse { return &odrBlock{} },
-		addTxResponseCode:    func() odrResponse { return &odrAddTx{} },
-		trieResponseCode:     func() odrResponse { return &odrTriePoof{} },
-		receiptResponseCode:  func() odrResponse { return &odrReceiptResponse{} },
-		txByHashResponseCode: func() odrResponse { return &odrTxByHashResponse{} },
-		debtResponseCode:     func() odrResponse { return &odrDebtResponse{} },
+		blockResponseCode:     func() odrResponse { return &odrBlock{} },
+		addTxResponseCode:     func() odrResponse { return &odrAddTx{} },
+		trieResponseCode:      func() odrResponse { return &odrTriePoof{} },
+		receiptResponseCode:   func() odrResponse { return &odrReceiptResponse{} },
+		txByHashResponseCode:  func() odrResponse { return &odrTxByHashResponse{} },
+		debtResponseCode:      func() odrResponse { return &odrDebtResponse{} },
+		txsByHashResponseCode: func() odrResponse { return &odrTxsByHashResponse{} },
+		accountResponseCode:   func() odrResponse { return &odrAccountResponse{} },
 	}
 )
 
@@ -0,0 +1,77 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package light
+
+import (
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+)
+
+// odrAccountRequest requests the account with the merkle proof against the state root of the block
+type odrAccountRequest struct {
+	OdrItem
+	BlockHash common.Hash
+	Account   common.Address
+}
+
+// odrAccountResponse is the merkle proof of the account and its code, the proven account is
+// decoded from the proof on validation, and never trusted from the remote peer.
+type odrAccountResponse struct {
+	OdrItem
+	Proof []proofNode
+
+	provenAccount *state.ProvenAccount `rlp:"-"`
+}
+
+func (request *odrAccountRequest) code() uint16 {
+	return accountRequestCode
+}
+
+func (request *odrAccountRequest) handle(lp *LightProtocol) (uint16, odrResponse) {
+	header, err := lp.chain.GetStore().GetBlockHeader(request.BlockHash)
+	if err != nil {
+		err = errors.NewStackedErrorf(err, "failed to get block header by hash %v", request.BlockHash)
+		return newErrorResponse(accountResponseCode, request.ReqID, err)
+	}
+
+	statedb, err := lp.chain.GetState(header.StateHash)
+	if err != nil {
+		err = errors.NewStackedErrorf(err, "failed to get statedb by root hash %v", header.StateHash)
+		return newErrorResponse(accountResponseCode, request.ReqID, err)
+	}
+
+	proof, err := statedb.GetAccountProof(request.Account)
+	if err != nil {
+		err = errors.NewStackedError(err, "failed to get account proof")
+		return newErrorResponse(accountResponseCode, request.ReqID, err)
+	}
+
+	var result odrAccountResponse
+	result.ReqID = request.ReqID
+	result.Proof = mapToArray(proof)
+
+	return accountResponseCode, &result
+}
+
+// validate verifies the proof against the state root of the block header, which is verified and
+// stored locally by the header sync.
+func (response *odrAccountResponse) validate(request odrRequest, bcStore store.BlockchainStore) error {
+	accountRequest := request.(*odrAccountRequest)
+
+	header, err := bcStore.GetBlockHeader(accountRequest.BlockHash)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to get block header by hash %v", accountRequest.BlockHash)
+	}
+
+	response.provenAccount, err = state.VerifyAccountProof(header.StateHash, accountRequest.Account, arrayToMap(response.Proof))
+	if err != nil {
+		return errors.NewStackedError(err, "failed to verify the account proof")
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package light
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/memorydb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_odrAccount_Serializable(t *testing.T) {
+	request := &odrAccountRequest{
+		OdrItem:   OdrItem{ReqID: 38},
+		BlockHash: common.StringToHash("block"),
+		Account:   randomAddress(),
+	}
+	assertSerializable(t, request, &odrAccountRequest{})
+
+	response := &odrAccountResponse{
+		OdrItem: OdrItem{ReqID: 38},
+		Proof:   []proofNode{{"key", []byte("value")}},
+	}
+	assertSerializable(t, response, &odrAccountResponse{})
+}
+
+func Test_odrAccountResponse_Validate(t *testing.T) {
+	db, dispose := memorydb.NewTestDatabase()
+	defer dispose()
+
+	account := randomAddress()
+	statedb := state.NewEmptyStatedb(db)
+	statedb.CreateAccount(account)
+	statedb.SetBalance(account, big.NewInt(100))
+	statedb.SetNonce(account, 2)
+
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, batch.Commit(), nil)
+
+	bcStore := store.NewBlockchainDatabase(db)
+	header := &types.BlockHeader{StateHash: root, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}
+	assert.Equal(t, bcStore.PutBlockHeader(header.Hash(), header, big.NewInt(1), true), nil)
+
+	statedb, err = state.NewStatedb(root, db)
+	assert.Equal(t, err, nil)
+	proof, err := statedb.GetAccountProof(account)
+	assert.Equal(t, err, nil)
+
+	request := &odrAccountRequest{BlockHash: header.Hash(), Account: account}
+	response := &odrAccountResponse{Proof: mapToArray(proof)}
+	assert.Equal(t, response.validate(request, bcStore), nil)
+	assert.Equal(t, response.provenAccount.Balance, big.NewInt(100))
+	assert.Equal(t, response.provenAccount.Nonce, uint64(2))
+
+	// tampered proof
+	response = &odrAccountResponse{Proof: mapToArray(proof)}
+	response.Proof[0].Value = []byte("tampered")
+	assert.Equal(t, response.validate(request, bcStore) != nil, true)
+
+	// unknown block
+	request.BlockHash = common.StringToHash("unknown")
+	response = &odrAccountResponse{Proof: mapToArray(proof)}
+	assert.Equal(t, response.validate(request, bcStore) != nil, true)
+}
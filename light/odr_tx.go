@@ -6,6 +6,8 @@
 package light
 
 import (
+	"fmt"
+
 	"github.com/scdoproject/go-scdo/api"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
@@ -51,34 +53,41 @@ func (req *odrTxByHashRequest) code() uint16 {
 }
 
 func (req *odrTxByHashRequest) handle(lp *LightProtocol) (uint16, odrResponse) {
-	var err error
-	var result odrTxByHashResponse
-	result.Tx, result.BlockIndex, err = api.GetTransaction(lp.txPool, lp.chain.GetStore(), req.TxHash)
+	result, err := getTxByHash(lp, req.TxHash)
+	if err != nil {
+		return newErrorResponse(txByHashResponseCode, req.ReqID, err)
+	}
+
 	result.ReqID = req.ReqID
 
+	return txByHashResponseCode, result
+}
+
+// getTxByHash returns the tx of the specified hash with the merkle proof if it is packed
+func getTxByHash(lp *LightProtocol, txHash common.Hash) (*odrTxByHashResponse, error) {
+	var err error
+	var result odrTxByHashResponse
+	result.Tx, result.BlockIndex, err = api.GetTransaction(lp.txPool, lp.chain.GetStore(), txHash)
 	if err != nil {
-		err = errors.NewStackedErrorf(err, "failed to get tx by hash %v", req.TxHash)
-		return newErrorResponse(txByHashResponseCode, req.ReqID, err)
+		return nil, errors.NewStackedErrorf(err, "failed to get tx by hash %v", txHash)
 	}
 
 	if result.Tx != nil && result.BlockIndex != nil && !result.BlockIndex.BlockHash.IsEmpty() {
 		block, err := lp.chain.GetStore().GetBlock(result.BlockIndex.BlockHash)
 		if err != nil {
-			err = errors.NewStackedErrorf(err, "failed to get block by hash %v", result.BlockIndex.BlockHash)
-			return newErrorResponse(txByHashResponseCode, req.ReqID, err)
+			return nil, errors.NewStackedErrorf(err, "failed to get block by hash %v", result.BlockIndex.BlockHash)
 		}
 
 		txTrie := types.GetTxTrie(block.Transactions)
-		proof, err := txTrie.GetProof(req.TxHash.Bytes())
+		proof, err := txTrie.GetProof(txHash.Bytes())
 		if err != nil {
-			err = errors.NewStackedError(err, "failed to get tx trie proof")
-			return newErrorResponse(txByHashResponseCode, req.ReqID, err)
+			return nil, errors.NewStackedError(err, "failed to get tx trie proof")
 		}
 
 		result.Proof = mapToArray(proof)
 	}
 
-	return txByHashResponseCode, &result
+	return &result, nil
 }
 
 func (response *odrTxByHashResponse) validateUnpackedTx(txHash common.Hash) error {
@@ -117,3 +126,61 @@ func (response *odrTxByHashResponse) validate(request odrRequest, bcStore store.
 
 	return nil
 }
+
+// ODR object to get transactions by hashes in a single request, e.g. to validate the debts in batch.
+type odrTxsByHashRequest struct {
+	OdrItem
+	TxHashes []common.Hash
+}
+
+type odrTxsByHashResponse struct {
+	OdrItem
+	Txs []*odrTxByHashResponse // in the order of the requested hashes, and the error of each tx is in its OdrItem
+}
+
+func (req *odrTxsByHashRequest) code() uint16 {
+	return txsByHashRequestCode
+}
+
+func (req *odrTxsByHashRequest) handle(lp *LightProtocol) (uint16, odrResponse) {
+	if len(req.TxHashes) > maxTxsPerRequest {
+		err := fmt.Errorf("too many txs requested, have %d, max %d", len(req.TxHashes), maxTxsPerRequest)
+		return newErrorResponse(txsByHashResponseCode, req.ReqID, err)
+	}
+
+	result := &odrTxsByHashResponse{
+		OdrItem: OdrItem{ReqID: req.ReqID},
+		Txs:     make([]*odrTxByHashResponse, len(req.TxHashes)),
+	}
+
+	for i, txHash := range req.TxHashes {
+		tx, err := getTxByHash(lp, txHash)
+		if err != nil {
+			tx = &odrTxByHashResponse{}
+			tx.setError(err)
+		}
+
+		result.Txs[i] = tx
+	}
+
+	return txsByHashResponseCode, result
+}
+
+func (response *odrTxsByHashResponse) validate(request odrRequest, bcStore store.BlockchainStore) error {
+	txHashes := request.(*odrTxsByHashRequest).TxHashes
+	if len(response.Txs) != len(txHashes) {
+		return fmt.Errorf("mismatched number of txs, have %d, expected %d", len(response.Txs), len(txHashes))
+	}
+
+	for i, tx := range response.Txs {
+		if tx.getError() != nil {
+			continue
+		}
+
+		if err := tx.validate(&odrTxByHashRequest{TxHash: txHashes[i]}, bcStore); err != nil {
+			return errors.NewStackedErrorf(err, "failed to validate tx %v", txHashes[i])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package light
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-scdo/api"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_odrTxsByHashRequest_Serializable(t *testing.T) {
+	request := &odrTxsByHashRequest{
+		TxHashes: []common.Hash{common.StringToHash("tx1"), common.StringToHash("tx2")},
+	}
+
+	assertSerializable(t, request, &odrTxsByHashRequest{})
+}
+
+func Test_odrTxsByHashResponse_Serializable(t *testing.T) {
+	response := &odrTxsByHashResponse{
+		Txs: []*odrTxByHashResponse{
+			{
+				OdrProvableResponse: OdrProvableResponse{
+					BlockIndex: &api.BlockIndex{
+						BlockHash:   common.StringToHash("block hash"),
+						BlockHeight: 38,
+						Index:       66,
+					},
+					Proof: []proofNode{{Key: "root", Value: []byte{1, 2, 3}}},
+				},
+			},
+			{
+				OdrProvableResponse: OdrProvableResponse{
+					OdrItem: OdrItem{Error: "tx not found"},
+					Proof:   make([]proofNode, 0),
+				},
+			},
+		},
+	}
+
+	assertSerializable(t, response, &odrTxsByHashResponse{})
+}
+
+func Test_odrTxsByHashResponse_Validate(t *testing.T) {
+	request := &odrTxsByHashRequest{
+		TxHashes: []common.Hash{common.StringToHash("tx1"), common.StringToHash("tx2")},
+	}
+
+	// mismatched number of txs
+	response := &odrTxsByHashResponse{
+		Txs: []*odrTxByHashResponse{{}},
+	}
+	assert.Equal(t, response.validate(request, nil) != nil, true)
+
+	// tx not packed or failed to retrieve
+	response.Txs = append(response.Txs, &odrTxByHashResponse{})
+	response.Txs[1].setError(api.ErrTransactionNotFound)
+	assert.Equal(t, response.validate(request, nil), nil)
+	assert.Equal(t, response.Txs[1].getError() != nil, true)
+}
@@ -24,6 +24,9 @@ const (
 
 	// DiscAnnounceErr disconnect due to failed to send announce message
 	DiscAnnounceErr = "disconnect because send announce message err"
+
+	// DiscTooManyClients disconnect due to too many light clients connected to the server
+	DiscTooManyClients = "disconnect because too many light clients"
 )
 
 var (
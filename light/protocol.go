@@ -94,6 +94,18 @@ func codeToStr(code uint16) string {
 		return "txByHashRequestCode"
 	case txByHashResponseCode:
 		return "txByHashResponseCode"
+	case debtRequestCode:
+		return "debtRequestCode"
+	case debtResponseCode:
+		return "debtResponseCode"
+	case txsByHashRequestCode:
+		return "txsByHashRequestCode"
+	case txsByHashResponseCode:
+		return "txsByHashResponseCode"
+	case accountRequestCode:
+		return "accountRequestCode"
+	case accountResponseCode:
+		return "accountResponseCode"
 	case protocolMsgCodeLength:
 		return "protocolMsgCodeLength"
 	}
@@ -117,6 +129,7 @@ type LightProtocol struct {
 	quitCh              chan struct{}
 	syncCh              chan struct{}
 	chainHeaderChangeCh chan common.Hash
+	load                *serverLoad // load management of the light clients, only in server mode
 	log                 *log.ScdoLog
 
 	shard uint
@@ -127,9 +140,10 @@ func NewLightProtocol(networkID string, txPool TransactionPool, debtPool *core.D
 	log *log.ScdoLog, shard uint) (s *LightProtocol, err error) {
 	s = &LightProtocol{
 		Protocol: p2p.Protocol{
-			Name:    fmt.Sprintf("%s_%d", LightProtoName, shard),
-			Version: LightScdoVersion,
-			Length:  protocolMsgCodeLength,
+			Name:     fmt.Sprintf("%s_%d", LightProtoName, shard),
+			Version:  LightScdoVersion,
+			Length:   protocolMsgCodeLength,
+			CodeName: codeToStr,
 		},
 		bServerMode: serverMode,
 		networkID:   networkID,
@@ -292,6 +306,22 @@ func (lp *LightProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter)
 		}
 	}
 
+	if lp.load != nil {
+		admitted, evicted := lp.load.admit(newPeer.peerID, time.Now())
+		if !admitted {
+			lp.log.Debug("handleAddPeer too many light clients, peer:%s", newPeer.peerStrID)
+			newPeer.Disconnect(DiscTooManyClients)
+			return false
+		}
+
+		if evicted != nil {
+			if p := lp.peerSet.Find(*evicted); p != nil {
+				lp.log.Debug("evict light client %s for peer %s with higher priority", p.peerStrID, newPeer.peerStrID)
+				p.Disconnect(DiscTooManyClients)
+			}
+		}
+	}
+
 	lp.log.Info("add peer %s -> %s to LightProtocol.", p2pPeer.LocalAddr(), p2pPeer.RemoteAddr())
 	lp.peerSet.Add(newPeer)
 	go lp.handleMsg(newPeer)
@@ -314,6 +344,9 @@ func (lp *LightProtocol) handleDelPeer(peer *p2p.Peer) {
 	}
 
 	lp.peerSet.Remove(peer.Node.ID)
+	if lp.load != nil {
+		lp.load.remove(peer.Node.ID)
+	}
 }
 
 func (lp *LightProtocol) handleMsg(peer *peer) {
@@ -325,6 +358,11 @@ handler:
 			break
 		}
 
+		if lp.load != nil && !lp.load.charge(peer.peerID, msg.Code, time.Now()) {
+			lp.log.Debug("request %s from %s is rejected for running out of tokens", codeToStr(msg.Code), peer.peerStrID)
+			continue
+		}
+
 		bNeedDeliverOdr := false
 		switch msg.Code {
 		case announceRequestCode:
@@ -432,6 +470,10 @@ func (lp *LightProtocol) handleOdrRequest(peer *peer, msg *p2p.Message) error {
 
 	lp.log.Debug("begin to handle ODR request, code = %v, payloadLen = %v", codeToStr(msg.Code), len(msg.Payload))
 	respCode, response := request.handle(lp)
+	if lp.load != nil && msg.Code == addTxRequestCode && response.getError() == nil {
+		lp.load.relayed(peer.peerID)
+	}
+
 	buff := common.SerializePanic(response)
 	lp.log.Debug("peer send response, code = %v, payloadSizeBytes = %v, peerID = %v", codeToStr(respCode), len(buff), peer.peerStrID)
 
@@ -447,3 +489,8 @@ func (lp *LightProtocol) GetProtocolVersion() (uint, error) {
 func (lp *LightProtocol) SendDifferentShardTx(tx *types.Transaction, shard uint) {
 	//@todo
 }
+
+// CheckNonceReservation light node does not track nonce reservations of other shards
+func (lp *LightProtocol) CheckNonceReservation(tx *types.Transaction) error {
+	return nil
+}
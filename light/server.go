@@ -34,6 +34,8 @@ func NewServiceServer(service *scdo.ScdoService, conf *node.Config, log *log.Scd
 		return nil, err
 	}
 
+	scdoProtocol.load = newServerLoad(conf.LightServerConfig)
+
 	s := &ServiceServer{
 		log:          log,
 		scdoProtocol: scdoProtocol,
@@ -67,7 +69,12 @@ func (s *ServiceServer) Stop() error {
 
 // APIs implements node.Service, returning the collection of RPC services the scdo package offers.
 func (s *ServiceServer) APIs() (apis []rpc.API) {
-	return
+	return append(apis, rpc.API{
+		Namespace: "light",
+		Version:   "1.0",
+		Service:   NewPublicLightServerAPI(s),
+		Public:    true,
+	})
 }
 
 func (pm *LightProtocol) chainHeaderChanged(e event.Event) {
@@ -0,0 +1,256 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package light
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/node"
+)
+
+const (
+	defaultMaxLightClients    = 100
+	defaultClientBufferLimit  = 1000
+	defaultClientRechargeRate = 50
+
+	// relayReward is the tokens refunded to a light client for a useful relay, e.g. a tx accepted by the tx pool
+	relayReward = 2
+
+	// relayHistorySize is the max number of light clients whose relay counts are kept after disconnected
+	relayHistorySize = 1000
+)
+
+// requestCosts is the cost in tokens of each kind of request served by the light server
+var requestCosts = map[uint16]uint64{
+	announceRequestCode:        1,
+	syncHashRequestCode:        2,
+	downloadHeadersRequestCode: 10,
+	blockRequestCode:           5,
+	addTxRequestCode:           1,
+	trieRequestCode:            5,
+	receiptRequestCode:         5,
+	txByHashRequestCode:        5,
+	debtRequestCode:            5,
+	txsByHashRequestCode:       20,
+	accountRequestCode:         5,
+}
+
+// ClientStats is the request accounting of a light client
+type ClientStats struct {
+	ID       string  `json:"id"`       // node ID of the light client
+	Tokens   float64 `json:"tokens"`   // tokens left to request
+	Served   uint64  `json:"served"`   // number of requests served
+	Rejected uint64  `json:"rejected"` // number of requests rejected for running out of tokens
+	Relayed  uint64  `json:"relayed"`  // number of useful relays, which also gives priority to the client
+}
+
+// ServerStats is the load statistics of the light server
+type ServerStats struct {
+	MaxClients   int            `json:"maxClients"`
+	BufferLimit  uint64         `json:"bufferLimit"`
+	RechargeRate uint64         `json:"rechargeRate"`
+	Served       uint64         `json:"served"`   // total requests served
+	Rejected     uint64         `json:"rejected"` // total requests rejected
+	Clients      []*ClientStats `json:"clients"`
+}
+
+type clientAccount struct {
+	ClientStats
+	lastRecharge time.Time
+}
+
+// serverLoad manages the load of the light server. Each light client owns a token bucket
+// which is charged by the cost of each request and recharged over time. The number of
+// concurrent light clients is limited, and the clients that relay useful data have priority.
+type serverLoad struct {
+	maxClients   int
+	bufferLimit  uint64
+	rechargeRate uint64
+
+	lock     sync.Mutex
+	clients  map[common.Address]*clientAccount
+	relays   *lru.Cache // address -> relay count, kept after the client disconnected
+	served   uint64
+	rejected uint64
+}
+
+func newServerLoad(conf node.LightServerConfig) *serverLoad {
+	l := &serverLoad{
+		maxClients:   conf.MaxClients,
+		bufferLimit:  conf.BufferLimit,
+		rechargeRate: conf.RechargeRate,
+		clients:      make(map[common.Address]*clientAccount),
+		relays:       common.MustNewCache(relayHistorySize),
+	}
+
+	if l.maxClients <= 0 {
+		l.maxClients = defaultMaxLightClients
+	}
+
+	if l.bufferLimit == 0 {
+		l.bufferLimit = defaultClientBufferLimit
+	}
+
+	if l.rechargeRate == 0 {
+		l.rechargeRate = defaultClientRechargeRate
+	}
+
+	return l
+}
+
+// admit admits the light client to connect with a full token bucket. If the max clients is
+// reached, the client is admitted only if it relayed more than the lowest priority client,
+// which is evicted and returned to disconnect.
+func (l *serverLoad) admit(id common.Address, now time.Time) (admitted bool, evicted *common.Address) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.clients[id]; ok {
+		return false, nil
+	}
+
+	var relayed uint64
+	if v, ok := l.relays.Get(id); ok {
+		relayed = v.(uint64)
+	}
+
+	if len(l.clients) >= l.maxClients {
+		lowestID, lowest := l.lowestPriorityClient()
+		if lowest == nil || lowest.Relayed >= relayed {
+			return false, nil
+		}
+
+		delete(l.clients, lowestID)
+		evicted = &lowestID
+	}
+
+	l.clients[id] = &clientAccount{
+		ClientStats: ClientStats{
+			ID:      idToStr(id),
+			Tokens:  float64(l.bufferLimit),
+			Relayed: relayed,
+		},
+		lastRecharge: now,
+	}
+
+	return true, evicted
+}
+
+// lowestPriorityClient returns the client with the least relays, caller should hold the lock.
+func (l *serverLoad) lowestPriorityClient() (common.Address, *clientAccount) {
+	var (
+		lowestID common.Address
+		lowest   *clientAccount
+	)
+
+	for id, client := range l.clients {
+		if lowest == nil || client.Relayed < lowest.Relayed {
+			lowestID, lowest = id, client
+		}
+	}
+
+	return lowestID, lowest
+}
+
+// remove removes the disconnected light client
+func (l *serverLoad) remove(id common.Address) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.clients, id)
+}
+
+// charge charges the light client by the cost of the request, and returns false
+// if the client runs out of tokens and the request should not be served.
+func (l *serverLoad) charge(id common.Address, code uint16, now time.Time) bool {
+	cost, ok := requestCosts[code]
+	if !ok {
+		return true
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	client, ok := l.clients[id]
+	if !ok {
+		return false
+	}
+
+	l.recharge(client, now)
+	if client.Tokens < float64(cost) {
+		client.Rejected++
+		l.rejected++
+		return false
+	}
+
+	client.Tokens -= float64(cost)
+	client.Served++
+	l.served++
+
+	return true
+}
+
+// recharge recharges the tokens of the client up to the buffer limit, caller should hold the lock.
+func (l *serverLoad) recharge(client *clientAccount, now time.Time) {
+	if elapsed := now.Sub(client.lastRecharge); elapsed > 0 {
+		client.Tokens += elapsed.Seconds() * float64(l.rechargeRate)
+		client.lastRecharge = now
+	}
+
+	if limit := float64(l.bufferLimit); client.Tokens > limit {
+		client.Tokens = limit
+	}
+}
+
+// relayed rewards the light client for a useful relay and raises its priority
+func (l *serverLoad) relayed(id common.Address) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	client, ok := l.clients[id]
+	if !ok {
+		return
+	}
+
+	client.Relayed++
+	client.Tokens += relayReward
+	if limit := float64(l.bufferLimit); client.Tokens > limit {
+		client.Tokens = limit
+	}
+
+	l.relays.Add(id, client.Relayed)
+}
+
+// stats returns the load statistics of the light server
+func (l *serverLoad) stats(now time.Time) *ServerStats {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	stats := &ServerStats{
+		MaxClients:   l.maxClients,
+		BufferLimit:  l.bufferLimit,
+		RechargeRate: l.rechargeRate,
+		Served:       l.served,
+		Rejected:     l.rejected,
+		Clients:      make([]*ClientStats, 0, len(l.clients)),
+	}
+
+	for _, client := range l.clients {
+		l.recharge(client, now)
+		clientStats := client.ClientStats
+		stats.Clients = append(stats.Clients, &clientStats)
+	}
+
+	sort.Slice(stats.Clients, func(i, j int) bool {
+		return stats.Clients[i].ID < stats.Clients[j].ID
+	})
+
+	return stats
+}
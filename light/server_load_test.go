@@ -0,0 +1,87 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package light
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ServerLoad_Charge(t *testing.T) {
+	l := newServerLoad(node.LightServerConfig{BufferLimit: 20, RechargeRate: 10})
+	id := common.BytesToAddress([]byte{1})
+	now := time.Now()
+
+	// not admitted
+	assert.Equal(t, l.charge(id, blockRequestCode, now), false)
+
+	admitted, evicted := l.admit(id, now)
+	assert.Equal(t, admitted, true)
+	assert.Equal(t, evicted, (*common.Address)(nil))
+
+	// free of charge for responses
+	assert.Equal(t, l.charge(id, blockResponseCode, now), true)
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, l.charge(id, blockRequestCode, now), true)
+	}
+	assert.Equal(t, l.charge(id, blockRequestCode, now), false)
+
+	// recharged in half a second
+	assert.Equal(t, l.charge(id, blockRequestCode, now.Add(500*time.Millisecond)), true)
+
+	stats := l.stats(now.Add(500 * time.Millisecond))
+	assert.Equal(t, stats.Served, uint64(5))
+	assert.Equal(t, stats.Rejected, uint64(1))
+	assert.Equal(t, len(stats.Clients), 1)
+	assert.Equal(t, stats.Clients[0].Tokens, float64(0))
+
+	// recharged up to the buffer limit
+	stats = l.stats(now.Add(time.Hour))
+	assert.Equal(t, stats.Clients[0].Tokens, float64(20))
+}
+
+func Test_ServerLoad_Admit(t *testing.T) {
+	l := newServerLoad(node.LightServerConfig{MaxClients: 2})
+	id1 := common.BytesToAddress([]byte{1})
+	id2 := common.BytesToAddress([]byte{2})
+	id3 := common.BytesToAddress([]byte{3})
+	now := time.Now()
+
+	admitted, _ := l.admit(id1, now)
+	assert.Equal(t, admitted, true)
+	admitted, _ = l.admit(id2, now)
+	assert.Equal(t, admitted, true)
+
+	// already admitted
+	admitted, _ = l.admit(id1, now)
+	assert.Equal(t, admitted, false)
+
+	// too many clients
+	admitted, _ = l.admit(id3, now)
+	assert.Equal(t, admitted, false)
+
+	// id3 relayed in the previous connection, so it has priority over id2
+	l.remove(id2)
+	l.admit(id3, now)
+	l.relayed(id3)
+	l.remove(id3)
+	l.admit(id2, now)
+
+	l.relayed(id1)
+	admitted, evicted := l.admit(id3, now)
+	assert.Equal(t, admitted, true)
+	assert.Equal(t, *evicted, id2)
+
+	stats := l.stats(now)
+	assert.Equal(t, len(stats.Clients), 2)
+	assert.Equal(t, stats.Clients[0].Relayed, uint64(1))
+	assert.Equal(t, stats.Clients[1].Relayed, uint64(1))
+}
@@ -124,6 +124,11 @@ func (pool *txPool) Remove(txHash common.Hash) {
 	delete(pool.pendingTxs, txHash)
 }
 
+// GetDroppedTransaction always returns nil, the light tx pool does not drop txs itself.
+func (pool *txPool) GetDroppedTransaction(txHash common.Hash) (*types.Transaction, string) {
+	return nil, ""
+}
+
 // GetPendingTxCount return the total number of pending transactions in the transaction pool.
 func (pool *txPool) GetPendingTxCount() int {
 	pool.mutex.RLock()
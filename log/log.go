@@ -131,3 +131,20 @@ func GetLogger(module string) *ScdoLog {
 	logMap[module] = curLog
 	return curLog
 }
+
+// SetDebug sets the log level of all the loggers, DebugLevel if isDebug is true, otherwise InfoLevel
+func SetDebug(isDebug bool) {
+	getLogMutex.Lock()
+	defer getLogMutex.Unlock()
+
+	comm.LogConfiguration.IsDebug = isDebug
+
+	level := logrus.InfoLevel
+	if isDebug {
+		level = logrus.DebugLevel
+	}
+
+	for _, l := range logMap {
+		l.SetLevel(level)
+	}
+}
@@ -3,6 +3,7 @@ package influxdb
 import (
 	"fmt"
 	uurl "net/url"
+	"sync"
 	"time"
 
 	"github.com/influxdata/influxdb/client"
@@ -22,21 +23,28 @@ type reporter struct {
 
 	client *client.Client
 
-	log *log.ScdoLog
+	log  *log.ScdoLog
+	quit chan struct{}
 }
 
 // only one reporter is allowed
-var rep *reporter
+var (
+	rep     *reporter
+	repLock sync.Mutex
+)
 
 // InfluxDBWithTags starts a InfluxDB reporter which will post the metrics from the given registry at each d interval with the specified tags
 func InfluxDBWithTags(r metrics.Registry, d time.Duration, url, database, username, password string, tags map[string]string, log *log.ScdoLog) {
+	repLock.Lock()
 	if rep != nil {
+		repLock.Unlock()
 		log.Error("reporter already running!")
 		return
 	}
 	url = parseHTTPHead(url)
 	u, err := uurl.Parse(url)
 	if err != nil {
+		repLock.Unlock()
 		log.Error("unable to parse InfluxDB url %s. err=%v", url, err)
 		return
 	}
@@ -50,13 +58,32 @@ func InfluxDBWithTags(r metrics.Registry, d time.Duration, url, database, userna
 		password: password,
 		tags:     tags,
 		log:      log,
+		quit:     make(chan struct{}),
 	}
-	if err := rep.makeClient(); err != nil {
+	current := rep
+	repLock.Unlock()
+
+	if err := current.makeClient(); err != nil {
 		log.Error("unable to make InfluxDB client. err=%v", err)
 		return
 	}
 
-	rep.run()
+	current.run()
+}
+
+// Stop stops the running reporter, returns false if no reporter is running
+func Stop() bool {
+	repLock.Lock()
+	defer repLock.Unlock()
+
+	if rep == nil {
+		return false
+	}
+
+	close(rep.quit)
+	rep = nil
+
+	return true
 }
 
 func (r *reporter) makeClient() (err error) {
@@ -70,16 +97,20 @@ func (r *reporter) makeClient() (err error) {
 }
 
 func (r *reporter) run() {
-	intervalTicker := time.Tick(r.interval)
-	pingTicker := time.Tick(time.Second * 5)
+	intervalTicker := time.NewTicker(r.interval)
+	defer intervalTicker.Stop()
+	pingTicker := time.NewTicker(time.Second * 5)
+	defer pingTicker.Stop()
 
 	for {
 		select {
-		case <-intervalTicker:
+		case <-r.quit:
+			return
+		case <-intervalTicker.C:
 			if err := r.send(); err != nil {
 				r.log.Error("unable to send metrics to InfluxDB. err=%v", err)
 			}
-		case <-pingTicker:
+		case <-pingTicker.C:
 			_, _, err := r.client.Ping()
 			if err != nil {
 				r.log.Error("got error while sending a ping to InfluxDB, trying to recreate client. err=%v", err)
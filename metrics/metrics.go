@@ -15,7 +15,57 @@ import (
 	influxdb "github.com/scdoproject/go-scdo/metrics/go-metrics-influxdb"
 )
 
-var MetricsWriteBlockMeter = metrics.GetOrRegisterMeter("core.blockchain.writeBlock.time", nil)
+var (
+	MetricsWriteBlockMeter = metrics.GetOrRegisterMeter("core.blockchain.writeBlock.time", nil)
+
+	// MetricsDeepReorgMeter marks the reorgs refused for exceeding the max reorg depth
+	MetricsDeepReorgMeter = metrics.GetOrRegisterMeter("core.blockchain.deepReorg", nil)
+
+	// MetricsTxValidationCacheHitMeter marks the txs in pool which skip the stateless validation by cache
+	MetricsTxValidationCacheHitMeter = metrics.GetOrRegisterMeter("core.txpool.validationCache.hit", nil)
+
+	// MetricsTxValidationCacheMissMeter marks the txs in pool which run the full validation
+	MetricsTxValidationCacheMissMeter = metrics.GetOrRegisterMeter("core.txpool.validationCache.miss", nil)
+
+	// MetricsWatchdogStallMeter marks the stalls detected by the watchdog
+	MetricsWatchdogStallMeter = metrics.GetOrRegisterMeter("scdo.watchdog.stall", nil)
+
+	// MetricsWatchdogRecoveryMeter marks the recovery actions taken by the watchdog
+	MetricsWatchdogRecoveryMeter = metrics.GetOrRegisterMeter("scdo.watchdog.recovery", nil)
+
+	// MetricsSeenTxsGauge is the number of tx hashes in the seen txs shared by tx pool and peers
+	MetricsSeenTxsGauge = metrics.GetOrRegisterGauge("core.seenTxs.count", nil)
+
+	// MetricsSeenTxsPeersGauge is the number of peers sharing the seen txs
+	MetricsSeenTxsPeersGauge = metrics.GetOrRegisterGauge("core.seenTxs.peers", nil)
+
+	// MetricsDebtPoolPriceHistogram is the price distribution of the debts added to the debt pool
+	MetricsDebtPoolPriceHistogram = metrics.GetOrRegisterHistogram("core.debtpool.price", nil, metrics.NewExpDecaySample(1028, 0.015))
+
+	// MetricsDebtPoolLowPriceMeter marks the debts rejected for the price lower than the minimum debt price
+	MetricsDebtPoolLowPriceMeter = metrics.GetOrRegisterMeter("core.debtpool.lowPrice", nil)
+
+	// MetricsWebhookDeliveryMeter marks the chain events delivered to the webhooks
+	MetricsWebhookDeliveryMeter = metrics.GetOrRegisterMeter("scdo.webhook.delivery", nil)
+
+	// MetricsWebhookFailureMeter marks the chain events dropped by the webhooks after retries or for the full queue
+	MetricsWebhookFailureMeter = metrics.GetOrRegisterMeter("scdo.webhook.failure", nil)
+
+	// MetricsChainDBSizeGauge is the disk usage in bytes of the chain database checked by the disk quota
+	MetricsChainDBSizeGauge = metrics.GetOrRegisterGauge("scdo.diskquota.chaindbSize", nil)
+
+	// MetricsDiskQuotaReclaimedMeter marks the bytes of the receipts and dirty accounts pruned by the disk quota
+	MetricsDiskQuotaReclaimedMeter = metrics.GetOrRegisterMeter("scdo.diskquota.reclaimed", nil)
+
+	// MetricsDiskQuotaPrunedBlocksMeter marks the blocks whose receipts and dirty accounts are pruned by the disk quota
+	MetricsDiskQuotaPrunedBlocksMeter = metrics.GetOrRegisterMeter("scdo.diskquota.prunedBlocks", nil)
+
+	// MetricsTxSyncMeter marks the pending txs synced to the new peers
+	MetricsTxSyncMeter = metrics.GetOrRegisterMeter("scdo.txsync.txs", nil)
+
+	// MetricsTxSyncPeerHistogram is the distribution of the pending txs synced to a new peer
+	MetricsTxSyncPeerHistogram = metrics.GetOrRegisterHistogram("scdo.txsync.peerTxs", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
 
 // Config infos for influxdb
 type Config struct {
@@ -68,15 +118,41 @@ func StartMetrics(
 		database,
 		username,
 		password,
-		map[string]string{
-			"nodename":  nodeName,
-			"networkid": networkID,
-			"version":   version,
-			"coinbase":  coinBase.Hex(),
-			"shardid":   fmt.Sprint(common.LocalShardNumber),
-		},
+		reportTags(nodeName, networkID, version, coinBase),
 		log,
 	)
 
 	go collectRuntimeMetrics()
 }
+
+// ReloadMetricsWithConfig restarts the running metrics reporter with the new configure,
+// it does nothing if the metrics is not started.
+func ReloadMetricsWithConfig(conf *Config, log *log.ScdoLog, name, version string, networkID string, coinBase common.Address) {
+	if conf == nil || !influxdb.Stop() {
+		return
+	}
+
+	log.Info("Reload metrics!")
+
+	go influxdb.InfluxDBWithTags(
+		metrics.DefaultRegistry,
+		time.Second*conf.Duration,
+		conf.Addr,
+		conf.Database,
+		conf.Username,
+		conf.Password,
+		reportTags(name, networkID, version, coinBase),
+		log,
+	)
+}
+
+// reportTags returns the tags of the reported metrics
+func reportTags(nodeName, networkID, version string, coinBase common.Address) map[string]string {
+	return map[string]string{
+		"nodename":  nodeName,
+		"networkid": networkID,
+		"version":   version,
+		"coinbase":  coinBase.Hex(),
+		"shardid":   fmt.Sprint(common.LocalShardNumber),
+	}
+}
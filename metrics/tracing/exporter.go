@@ -0,0 +1,187 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scdoproject/go-scdo/log"
+)
+
+const (
+	otlpTracesPath    = "/v1/traces"
+	otlpExportTimeout = 10 * time.Second
+	instrumentation   = "github.com/scdoproject/go-scdo"
+
+	// span kind internal and status code error defined by OpenTelemetry
+	otlpSpanKindInternal = 1
+	otlpStatusCodeError  = 2
+)
+
+type spanExporter interface {
+	export(spans []*Span)
+}
+
+// otlpExporter exports the spans to the OTLP/HTTP endpoint in JSON encoding
+type otlpExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+	log         *log.ScdoLog
+}
+
+func newExporter(conf *Config, log *log.ScdoLog) *otlpExporter {
+	serviceName := conf.ServiceName
+	if len(serviceName) == 0 {
+		serviceName = defaultServiceName
+	}
+
+	return &otlpExporter{
+		url:         strings.TrimRight(conf.Endpoint, "/") + otlpTracesPath,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: otlpExportTimeout},
+		log:         log,
+	}
+}
+
+func (e *otlpExporter) export(spans []*Span) {
+	body, err := json.Marshal(newOtlpRequest(e.serviceName, spans))
+	if err != nil {
+		e.log.Warn("failed to encode %d spans, %s", len(spans), err)
+		return
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		e.log.Warn("failed to export %d spans, %s", len(spans), err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		e.log.Warn("failed to export %d spans, status %s", len(spans), resp.Status)
+	}
+}
+
+// The OTLP/JSON messages of ExportTraceServiceRequest, see opentelemetry-proto.
+// The trace and span ids are hex encoded, and the 64 bits integers are encoded as strings.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func newOtlpAnyValue(value interface{}) otlpAnyValue {
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpAnyValue{IntValue: &s}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func newOtlpRequest(serviceName string, spans []*Span) *otlpRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		otlpSpans = append(otlpSpans, newOtlpSpan(s))
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{{Key: "service.name", Value: newOtlpAnyValue(serviceName)}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentation},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
+
+func newOtlpSpan(s *Span) otlpSpan {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	span := otlpSpan{
+		TraceID:           s.hexTraceID(),
+		SpanID:            s.hexSpanID(),
+		ParentSpanID:      s.hexParentID(),
+		Name:              s.name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+	}
+
+	for _, attr := range s.attrs {
+		span.Attributes = append(span.Attributes, otlpKeyValue{Key: attr.Key, Value: newOtlpAnyValue(attr.Value)})
+	}
+
+	if len(s.err) > 0 {
+		span.Status = &otlpStatus{Code: otlpStatusCodeError, Message: s.err}
+	}
+
+	return span
+}
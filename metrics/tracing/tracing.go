@@ -0,0 +1,313 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+// Package tracing records the spans of block processing, sync and rpc handling, and exports
+// them to an OpenTelemetry collector or Jaeger with OTLP over HTTP in JSON encoding.
+// Tracing is disabled by default, and the spans are nil which cost nothing until Start is called.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scdoproject/go-scdo/log"
+)
+
+const (
+	defaultServiceName = "scdo"
+	defaultQueueSize   = 4096
+	defaultBatchSize   = 512
+	defaultInterval    = 5 * time.Second
+)
+
+// Config is the configuration of tracing
+type Config struct {
+	// Endpoint is the OTLP/HTTP endpoint of the collector, e.g. http://127.0.0.1:4318, empty to disable tracing
+	Endpoint string `json:"endpoint"`
+
+	// ServiceName is the service name of the exported spans, "scdo" by default
+	ServiceName string `json:"serviceName"`
+
+	// SampleRatio is the ratio of the root spans to record in (0, 1], 0 means all
+	SampleRatio float64 `json:"sampleRatio"`
+}
+
+// Attribute is a key value pair of the span
+type Attribute struct {
+	Key   string
+	Value interface{} // string, int64, bool or float64
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute { return Attribute{key, value} }
+
+// Int returns an integer attribute
+func Int(key string, value int64) Attribute { return Attribute{key, value} }
+
+// Uint returns an integer attribute of unsigned value
+func Uint(key string, value uint64) Attribute { return Attribute{key, int64(value)} }
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute { return Attribute{key, value} }
+
+// Span is a timed operation of a trace. The nil span is valid and records nothing,
+// which is returned when tracing is disabled or the trace is not sampled.
+type Span struct {
+	tracer   *tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+	end      time.Time
+
+	lock  sync.Mutex
+	attrs []Attribute
+	err   string
+	ended bool
+}
+
+var current atomic.Value // *tracer
+
+func currentTracer() *tracer {
+	t, _ := current.Load().(*tracer)
+	return t
+}
+
+// Start starts tracing with the config, and stops the previous tracing if any.
+// Tracing is not started if the endpoint is empty.
+func Start(conf *Config, log *log.ScdoLog) error {
+	Stop()
+
+	if conf == nil || len(conf.Endpoint) == 0 {
+		return nil
+	}
+
+	if conf.SampleRatio < 0 || conf.SampleRatio > 1 {
+		return fmt.Errorf("invalid sample ratio %v, it should be in [0, 1]", conf.SampleRatio)
+	}
+
+	t := newTracer(conf, newExporter(conf, log))
+	current.Store(t)
+	go t.loop()
+
+	log.Info("tracing started, endpoint %s", conf.Endpoint)
+	return nil
+}
+
+// Stop stops tracing and flushes the recorded spans
+func Stop() {
+	if t := currentTracer(); t != nil {
+		current.Store((*tracer)(nil))
+		t.stop()
+	}
+}
+
+// Enabled returns whether tracing is started
+func Enabled() bool {
+	return currentTracer() != nil
+}
+
+// StartSpan starts a root span of a new trace, returns nil if tracing is disabled or not sampled
+func StartSpan(name string, attrs ...Attribute) *Span {
+	t := currentTracer()
+	if t == nil || !t.sample() {
+		return nil
+	}
+
+	s := &Span{tracer: t, name: name, start: time.Now(), attrs: attrs}
+	randomID(s.traceID[:])
+	randomID(s.spanID[:])
+
+	return s
+}
+
+// StartChild starts a child span of the span, returns nil if the span is nil
+func (s *Span) StartChild(name string, attrs ...Attribute) *Span {
+	if s == nil {
+		return nil
+	}
+
+	child := &Span{tracer: s.tracer, traceID: s.traceID, parentID: s.spanID, name: name, start: time.Now(), attrs: attrs}
+	randomID(child.spanID[:])
+
+	return child
+}
+
+// SetAttributes sets the attributes of the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.lock.Unlock()
+}
+
+// SetError marks the span failed with the error if not nil
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.err = err.Error()
+	s.lock.Unlock()
+}
+
+// End ends the span and exports it, only the first call takes effect
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.lock.Unlock()
+
+	s.tracer.enqueue(s)
+}
+
+// EndWithError marks the span failed with the error if not nil, and ends the span
+func (s *Span) EndWithError(err error) {
+	s.SetError(err)
+	s.End()
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of the context with the span
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	if s == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, s)
+}
+
+// SpanFromContext returns the span in the context, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanContextKey{}).(*Span)
+	return s
+}
+
+// StartSpanFromContext starts a child span of the span in the context, or a root span if no span in the context,
+// and returns a copy of the context with the new span.
+func StartSpanFromContext(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	var s *Span
+	if parent := SpanFromContext(ctx); parent != nil {
+		s = parent.StartChild(name, attrs...)
+	} else {
+		s = StartSpan(name, attrs...)
+	}
+
+	return ContextWithSpan(ctx, s), s
+}
+
+func randomID(id []byte) {
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+}
+
+// tracer queues the ended spans and exports them in batches
+type tracer struct {
+	sampleRatio float64
+	exporter    spanExporter
+	queue       chan *Span
+	quit        chan struct{}
+	done        chan struct{}
+}
+
+func newTracer(conf *Config, exporter spanExporter) *tracer {
+	return &tracer{
+		sampleRatio: conf.SampleRatio,
+		exporter:    exporter,
+		queue:       make(chan *Span, defaultQueueSize),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func (t *tracer) sample() bool {
+	if t.sampleRatio == 0 || t.sampleRatio >= 1 {
+		return true
+	}
+
+	return mrand.Float64() < t.sampleRatio
+}
+
+// enqueue queues the ended span to export, the span is dropped if the queue is full,
+// so that a slow collector never blocks block processing.
+func (t *tracer) enqueue(s *Span) {
+	select {
+	case t.queue <- s:
+	default:
+	}
+}
+
+func (t *tracer) loop() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(defaultInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, defaultBatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			t.exporter.export(batch)
+			batch = make([]*Span, 0, defaultBatchSize)
+		}
+	}
+
+	for {
+		select {
+		case s := <-t.queue:
+			if batch = append(batch, s); len(batch) >= defaultBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.quit:
+			for {
+				select {
+				case s := <-t.queue:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (t *tracer) stop() {
+	close(t.quit)
+	<-t.done
+}
+
+func (s *Span) hexTraceID() string { return hex.EncodeToString(s.traceID[:]) }
+func (s *Span) hexSpanID() string  { return hex.EncodeToString(s.spanID[:]) }
+
+func (s *Span) hexParentID() string {
+	if s.parentID == [8]byte{} {
+		return ""
+	}
+
+	return hex.EncodeToString(s.parentID[:])
+}
@@ -0,0 +1,83 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tracing_Disabled(t *testing.T) {
+	Stop()
+	assert.Equal(t, Enabled(), false)
+
+	span := StartSpan("root")
+	assert.Equal(t, span == nil, true)
+
+	// nil span is valid
+	child := span.StartChild("child")
+	child.SetAttributes(String("key", "value"))
+	child.EndWithError(errors.New("failed"))
+	span.End()
+
+	ctx, span := StartSpanFromContext(context.Background(), "rpc")
+	assert.Equal(t, span == nil, true)
+	assert.Equal(t, SpanFromContext(ctx) == nil, true)
+}
+
+func Test_Tracing_Export(t *testing.T) {
+	requests := make(chan *otlpRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, otlpTracesPath)
+
+		var req otlpRequest
+		assert.Equal(t, json.NewDecoder(r.Body).Decode(&req), nil)
+		requests <- &req
+	}))
+	defer server.Close()
+
+	assert.Equal(t, Start(&Config{Endpoint: server.URL, SampleRatio: 2}, log.GetLogger("tracing")) != nil, true)
+	assert.Equal(t, Start(&Config{Endpoint: server.URL}, log.GetLogger("tracing")), nil)
+	assert.Equal(t, Enabled(), true)
+
+	root := StartSpan("root", Uint("height", 10))
+	ctx := ContextWithSpan(context.Background(), root)
+	_, child := StartSpanFromContext(ctx, "child", Bool("head", true))
+	child.EndWithError(errors.New("failed"))
+	root.End()
+	root.End()
+
+	// flush on stop
+	Stop()
+	assert.Equal(t, Enabled(), false)
+
+	req := <-requests
+	assert.Equal(t, len(req.ResourceSpans), 1)
+	assert.Equal(t, *req.ResourceSpans[0].Resource.Attributes[0].Value.StringValue, defaultServiceName)
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Equal(t, len(spans), 2)
+
+	assert.Equal(t, spans[0].Name, "child")
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID)
+	assert.Equal(t, spans[0].ParentSpanID, spans[1].SpanID)
+	assert.Equal(t, *spans[0].Attributes[0].Value.BoolValue, true)
+	assert.Equal(t, spans[0].Status.Code, otlpStatusCodeError)
+	assert.Equal(t, spans[0].Status.Message, "failed")
+
+	assert.Equal(t, spans[1].Name, "root")
+	assert.Equal(t, spans[1].ParentSpanID, "")
+	assert.Equal(t, len(spans[1].TraceID), 32)
+	assert.Equal(t, *spans[1].Attributes[0].Value.IntValue, "10")
+	assert.Equal(t, spans[1].Status == nil, true)
+}
@@ -0,0 +1,151 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+)
+
+const (
+	secondsPerDay = 24 * 60 * 60
+
+	// MaxCoinbaseWeight is the max weight of a coinbase, so that the total weight never overflows
+	MaxCoinbaseWeight = math.MaxUint32
+)
+
+// CoinbaseWeight is the weight, the daily quota and the blocks mined today of a coinbase in pool mode
+type CoinbaseWeight struct {
+	Coinbase common.Address `json:"coinbase"`
+	Weight   uint64         `json:"weight"` // relative weight to be selected as coinbase, 0 means never selected
+	Quota    uint64         `json:"quota"`  // max blocks mined per day, 0 means unlimited
+	Mined    uint64         `json:"mined"`  // blocks mined today
+}
+
+// coinbaseScheduler selects the coinbase from the coinbase list in pool mode. The coinbase
+// is selected with probability proportional to its weight among those under their daily quota.
+type coinbaseScheduler struct {
+	lock    sync.Mutex
+	weights map[common.Address]*CoinbaseWeight
+	sorted  []common.Address // coinbases in order for deterministic selection
+	day     int64            // the day (in UTC) of the mined counts
+}
+
+// newCoinbaseScheduler creates a scheduler with the default weight 1 and no quota for each coinbase
+func newCoinbaseScheduler(coinbaseList []common.Address) *coinbaseScheduler {
+	s := &coinbaseScheduler{
+		weights: make(map[common.Address]*CoinbaseWeight),
+	}
+
+	for _, coinbase := range coinbaseList {
+		s.set(coinbase, 1, 0)
+	}
+
+	return s
+}
+
+// set sets the weight and the daily quota of the coinbase, and adds the coinbase if not exists
+func (s *coinbaseScheduler) set(coinbase common.Address, weight, quota uint64) error {
+	if weight > MaxCoinbaseWeight {
+		return ErrCoinbaseWeightTooLarge
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if w, ok := s.weights[coinbase]; ok {
+		w.Weight, w.Quota = weight, quota
+		return nil
+	}
+
+	s.weights[coinbase] = &CoinbaseWeight{Coinbase: coinbase, Weight: weight, Quota: quota}
+	s.sorted = append(s.sorted, coinbase)
+	sort.Slice(s.sorted, func(i, j int) bool {
+		return bytes.Compare(s.sorted[i].Bytes(), s.sorted[j].Bytes()) < 0
+	})
+
+	return nil
+}
+
+// resetDay resets the mined counts if the day changes, caller should hold the lock
+func (s *coinbaseScheduler) resetDay(now time.Time) {
+	if day := now.Unix() / secondsPerDay; day != s.day {
+		s.day = day
+		for _, w := range s.weights {
+			w.Mined = 0
+		}
+	}
+}
+
+// choose selects a coinbase in weighted random, returns false if no coinbase is available
+func (s *coinbaseScheduler) choose(now time.Time) (common.Address, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.resetDay(now)
+
+	var total uint64
+	for _, w := range s.weights {
+		if w.available() {
+			total += w.Weight
+		}
+	}
+
+	if total == 0 {
+		return common.EmptyAddress, false
+	}
+
+	r := uint64(rand.Int63n(int64(total)))
+	for _, coinbase := range s.sorted {
+		w := s.weights[coinbase]
+		if !w.available() {
+			continue
+		}
+
+		if r < w.Weight {
+			return coinbase, true
+		}
+		r -= w.Weight
+	}
+
+	return common.EmptyAddress, false
+}
+
+// mined counts the block mined with the coinbase
+func (s *coinbaseScheduler) mined(coinbase common.Address, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.resetDay(now)
+	if w, ok := s.weights[coinbase]; ok {
+		w.Mined++
+	}
+}
+
+// list returns the weights of all coinbases
+func (s *coinbaseScheduler) list(now time.Time) []CoinbaseWeight {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.resetDay(now)
+	result := make([]CoinbaseWeight, 0, len(s.sorted))
+	for _, coinbase := range s.sorted {
+		result = append(result, *s.weights[coinbase])
+	}
+
+	return result
+}
+
+// available returns whether the coinbase could be selected
+func (w *CoinbaseWeight) available() bool {
+	return w.Weight > 0 && (w.Quota == 0 || w.Mined < w.Quota)
+}
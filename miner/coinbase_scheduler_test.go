@@ -0,0 +1,75 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CoinbaseScheduler_Choose(t *testing.T) {
+	coinbase1 := common.BytesToAddress([]byte{1})
+	coinbase2 := common.BytesToAddress([]byte{2})
+	now := time.Now()
+
+	// empty coinbase list
+	s := newCoinbaseScheduler(nil)
+	_, ok := s.choose(now)
+	assert.Equal(t, ok, false)
+
+	s = newCoinbaseScheduler([]common.Address{coinbase1, coinbase2})
+	assert.Equal(t, s.set(coinbase2, MaxCoinbaseWeight+1, 0), ErrCoinbaseWeightTooLarge)
+
+	// coinbase with weight 0 is never selected
+	assert.Equal(t, s.set(coinbase1, 0, 0), nil)
+	for i := 0; i < 10; i++ {
+		coinbase, ok := s.choose(now)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, coinbase, coinbase2)
+	}
+
+	// weighted selection
+	assert.Equal(t, s.set(coinbase1, 3, 0), nil)
+	counts := make(map[common.Address]int)
+	for i := 0; i < 4000; i++ {
+		coinbase, _ := s.choose(now)
+		counts[coinbase]++
+	}
+	assert.Equal(t, counts[coinbase1] > 2700 && counts[coinbase1] < 3300, true)
+	assert.Equal(t, counts[coinbase1]+counts[coinbase2], 4000)
+}
+
+func Test_CoinbaseScheduler_Quota(t *testing.T) {
+	coinbase1 := common.BytesToAddress([]byte{1})
+	coinbase2 := common.BytesToAddress([]byte{2})
+	now := time.Unix(secondsPerDay*100, 0)
+
+	s := newCoinbaseScheduler([]common.Address{coinbase1})
+	assert.Equal(t, s.set(coinbase1, 1, 2), nil)
+	assert.Equal(t, s.set(coinbase2, 0, 0), nil)
+
+	s.mined(coinbase1, now)
+	_, ok := s.choose(now)
+	assert.Equal(t, ok, true)
+
+	// quota reached
+	s.mined(coinbase1, now)
+	_, ok = s.choose(now)
+	assert.Equal(t, ok, false)
+	assert.Equal(t, s.list(now), []CoinbaseWeight{
+		{Coinbase: coinbase1, Weight: 1, Quota: 2, Mined: 2},
+		{Coinbase: coinbase2},
+	})
+
+	// reset in the next day
+	coinbase, ok := s.choose(now.Add(24 * time.Hour))
+	assert.Equal(t, ok, true)
+	assert.Equal(t, coinbase, coinbase1)
+	assert.Equal(t, s.list(now.Add(24 * time.Hour))[0].Mined, uint64(0))
+}
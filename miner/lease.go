@@ -0,0 +1,175 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+const (
+	// DefaultLeaseSize is the default number of nonces in a lease, about a few minutes of work of a machine
+	DefaultLeaseSize = uint64(1) << 32
+
+	// maxLeasesPerTask is the max leases of a task, the oldest lease is dropped when exceeded
+	maxLeasesPerTask = 1024
+)
+
+var (
+	// ErrNoWorkTask is returned when there is no task to lease
+	ErrNoWorkTask = errors.New("there is no task so far")
+
+	// ErrLeaseNotFound is returned when the lease is not found, or dropped as the task changed
+	ErrLeaseNotFound = errors.New("lease not found, the task may be changed")
+
+	// ErrNonceOutOfLease is returned when the submitted nonce is not in the nonce range of the lease
+	ErrNonceOutOfLease = errors.New("nonce is out of the lease range")
+
+	// ErrNonceSpaceExhausted is returned when the nonce space of the task is leased out
+	ErrNonceSpaceExhausted = errors.New("nonce space of the task is exhausted")
+)
+
+// WorkLease is a nonce range of the current task leased to a worker in the distributed solo mining, so that
+// the machines of an operator mine the same task without duplicated work.
+type WorkLease struct {
+	ID      uint64
+	Worker  string
+	Header  *types.BlockHeader // header of the task to seal, the nonce is set in the witness
+	Start   uint64             // first nonce of the range
+	End     uint64             // last nonce of the range, inclusive
+	Created int64              // unix time when the lease is created
+
+	task *Task
+}
+
+// leaseBook allocates the nonce ranges of the current task sequentially, the leases are dropped
+// once the task changes.
+type leaseBook struct {
+	lock   sync.Mutex
+	task   *Task
+	next   uint64 // first nonce of the next lease
+	full   bool   // true if the nonce space of the task is leased out
+	nextID uint64
+	leases map[uint64]*WorkLease
+}
+
+// lease leases the next size nonces of the task to the worker
+func (b *leaseBook) lease(task *Task, worker string, size uint64, now time.Time) (*WorkLease, error) {
+	if task == nil {
+		return nil, ErrNoWorkTask
+	}
+
+	if size == 0 {
+		size = DefaultLeaseSize
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.task != task {
+		b.task = task
+		b.next = 0
+		b.full = false
+		b.leases = make(map[uint64]*WorkLease)
+	}
+
+	if b.full {
+		return nil, ErrNonceSpaceExhausted
+	}
+
+	end := b.next + size - 1
+	if end < b.next {
+		end = ^uint64(0) // overflow, lease the rest of the nonce space
+	}
+
+	b.nextID++
+	l := &WorkLease{
+		ID:      b.nextID,
+		Worker:  worker,
+		Header:  task.header.Clone(),
+		Start:   b.next,
+		End:     end,
+		Created: now.Unix(),
+		task:    task,
+	}
+
+	b.leases[l.ID] = l
+	if len(b.leases) > maxLeasesPerTask {
+		delete(b.leases, l.ID-maxLeasesPerTask)
+	}
+
+	if end == ^uint64(0) {
+		b.full = true
+	} else {
+		b.next = end + 1
+	}
+
+	return l, nil
+}
+
+// get returns the lease of the task by id
+func (b *leaseBook) get(task *Task, id uint64) (*WorkLease, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	l, ok := b.leases[id]
+	if !ok || task == nil || l.task != task {
+		return nil, ErrLeaseNotFound
+	}
+
+	return l, nil
+}
+
+// list returns the leases of the task ordered by id
+func (b *leaseBook) list(task *Task) []*WorkLease {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	leases := make([]*WorkLease, 0, len(b.leases))
+	if task == nil || b.task != task {
+		return leases
+	}
+
+	for _, l := range b.leases {
+		leases = append(leases, l)
+	}
+
+	sort.Slice(leases, func(i, j int) bool { return leases[i].ID < leases[j].ID })
+
+	return leases
+}
+
+// LeaseWork leases a range of size nonces of the current task to the worker, 0 means the default
+// DefaultLeaseSize. The miner should be in pool mode so that it only creates the tasks.
+func (miner *Miner) LeaseWork(worker string, size uint64) (*WorkLease, error) {
+	return miner.leases.lease(miner.GetWorkTask(), worker, size, time.Now())
+}
+
+// SubmitLeaseWork submits the nonce found in the lease range to generate the final block
+func (miner *Miner) SubmitLeaseWork(id uint64, nonce uint64) error {
+	task := miner.GetWorkTask()
+	l, err := miner.leases.get(task, id)
+	if err != nil {
+		return err
+	}
+
+	if nonce < l.Start || nonce > l.End {
+		return ErrNonceOutOfLease
+	}
+
+	miner.log.Info("nonce submitted by the lease worker %s, height:%d, nonce:%d", l.Worker, l.Header.Height, nonce)
+
+	return miner.SubmitWork(l.Header.Height, nonce)
+}
+
+// GetWorkLeases returns the leases of the current task
+func (miner *Miner) GetWorkLeases() []*WorkLease {
+	return miner.leases.list(miner.GetWorkTask())
+}
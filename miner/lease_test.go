@@ -0,0 +1,79 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLeaseTask(height uint64) *Task {
+	return NewTask(&types.BlockHeader{Height: height, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}, defaultMinerAddr, nil)
+}
+
+func Test_LeaseBook(t *testing.T) {
+	var book leaseBook
+	now := time.Now()
+
+	_, err := book.lease(nil, "w1", 10, now)
+	assert.Equal(t, err, ErrNoWorkTask)
+
+	// the ranges are leased sequentially without overlap
+	task := newLeaseTask(10)
+	l1, err := book.lease(task, "w1", 10, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, l1.Start, uint64(0))
+	assert.Equal(t, l1.End, uint64(9))
+	assert.Equal(t, l1.Header.Height, uint64(10))
+
+	l2, err := book.lease(task, "w2", 0, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, l2.Start, uint64(10))
+	assert.Equal(t, l2.End, uint64(10)+DefaultLeaseSize-1)
+	assert.Equal(t, book.list(task), []*WorkLease{l1, l2})
+
+	found, err := book.get(task, l2.ID)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, found, l2)
+
+	// the rest of the nonce space is leased on overflow
+	l3, err := book.lease(task, "w3", math.MaxUint64, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, l3.End, uint64(math.MaxUint64))
+	_, err = book.lease(task, "w3", 10, now)
+	assert.Equal(t, err, ErrNonceSpaceExhausted)
+
+	// the leases are dropped once the task changes
+	next := newLeaseTask(11)
+	_, err = book.get(next, l1.ID)
+	assert.Equal(t, err, ErrLeaseNotFound)
+	assert.Equal(t, len(book.list(next)), 0)
+
+	l4, err := book.lease(next, "w1", 10, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, l4.Start, uint64(0))
+	_, err = book.get(next, l1.ID)
+	assert.Equal(t, err, ErrLeaseNotFound)
+}
+
+func Test_LeaseBook_MaxLeases(t *testing.T) {
+	var book leaseBook
+	task := newLeaseTask(10)
+
+	first, _ := book.lease(task, "w1", 1, time.Now())
+	for i := 0; i < maxLeasesPerTask; i++ {
+		book.lease(task, "w1", 1, time.Now())
+	}
+
+	assert.Equal(t, len(book.list(task)), maxLeasesPerTask)
+	_, err := book.get(task, first.ID)
+	assert.Equal(t, err, ErrLeaseNotFound)
+}
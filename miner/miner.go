@@ -9,14 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"math/rand"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/scdoproject/go-scdo/common"
-	"github.com/scdoproject/go-scdo/common/memory"
 	"github.com/scdoproject/go-scdo/consensus"
 	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/core/types"
@@ -34,6 +32,18 @@ var (
 	// ErrNodeIsSyncing is returned when the node is syncing
 	ErrNodeIsSyncing = errors.New("can not start miner when syncing")
 
+	// ErrExtraTooLong is returned when the extra data is longer than types.MaxSignalingExtraSize
+	ErrExtraTooLong = errors.New("extra data is too long")
+
+	// ErrCoinbaseWeightTooLarge is returned when the coinbase weight is larger than MaxCoinbaseWeight
+	ErrCoinbaseWeightTooLarge = errors.New("coinbase weight is too large")
+
+	// ErrClockDrift is returned when the local clock drifts more than common.MaxClockDrift
+	ErrClockDrift = errors.New("can not start miner when the local clock drifts too much")
+
+	// ErrNotInstantSeal is returned when mining a block on demand without the instant seal engine
+	ErrNotInstantSeal = errors.New("mining a block on demand requires the instant seal engine")
+
 	minerCount = 0
 )
 
@@ -53,28 +63,48 @@ type Miner struct {
 	poolMode bool
 	wg       sync.WaitGroup
 	stopChan chan struct{}
-	current  *Task
 	recv     chan *types.Block
 
+	current     *Task // the task being mined, immutable once set, guarded by currentLock
+	currentLock sync.RWMutex
+	leases      leaseBook // nonce ranges of the current task leased to the workers
+
 	scdo ScdoBackend
 	log  *log.ScdoLog
 
 	isFirstDownloader    int32
 	isFirstBlockPrepared int32
 
-	coinbase     common.Address
-	coinbaseList []common.Address
-	engine       consensus.Engine
+	clockDrift int64 // drift of the local clock in nanoseconds, accessed atomically
+
+	coinbase  common.Address
+	scheduler *coinbaseScheduler // selects the coinbase from the coinbase list in pool mode
+	engine    consensus.Engine
+	extra     atomic.Value // extra data in the mined block header for governance signaling, []byte
+
+	templateHook BlockTemplateHook // customizes the txs of the block template, nil to pack the txs by price
 
 	debtVerifier types.DebtVerifier
 	msgChan      chan bool // use msgChan to receive msg setting miner to start or stop, and miner will deal with these msgs sequentially
+
+	warming       int32 // 1 when the miner is waiting for the warmup after sync
+	warmupLock    sync.Mutex
+	warmupConf    WarmupConfig
+	warmupStatus  WarmupStatus
+	warmupQuit    chan struct{} // closed to cancel the running warmup
+	peerAgreement PeerAgreement
+
+	preemptMinWork int64        // min work time in nanoseconds before the task is preempted, negative if disabled, accessed atomically
+	preempting     int32        // 1 when a preemption is scheduled, accessed atomically
+	taskTime       int64        // unix nano when the current task is set, accessed atomically
+	lastSealed     atomic.Value // hash of the last block sealed by the miner itself
 }
 
 // NewMiner constructs and returns a miner instance
 func NewMiner(addr common.Address, addrList []common.Address, scdo ScdoBackend, verifier types.DebtVerifier, engine consensus.Engine, isPoolMode bool) *Miner {
 	miner := &Miner{
 		coinbase:             addr,
-		coinbaseList:         addrList,   // for pool mode
+		scheduler:            newCoinbaseScheduler(addrList),
 		canStart:             1,          // used with downloader, canStart is 0 when downloading
 		stopped:              0,          // indicate miner status (0/1), opposite to Miner.mining
 		stopper:              0,          // indicate where miner could start or not. If stopper is 1, miner won't do mining
@@ -88,15 +118,24 @@ func NewMiner(addr common.Address, addrList []common.Address, scdo ScdoBackend,
 		debtVerifier:         verifier,
 		engine:               engine,
 		msgChan:              make(chan bool, 100),
+		warmupStatus:         WarmupStatus{Decision: WarmupDisabled},
+		preemptMinWork:       int64(defaultPreemptMinWorkTime),
 	}
 
 	event.BlockDownloaderEventManager.AddListener(miner.downloaderEventCallback)
 	event.TransactionInsertedEventManager.AddAsyncListener(miner.newTxOrDebtCallback)
 	event.DebtsInsertedEventManager.AddAsyncListener(miner.newTxOrDebtCallback)
+	event.ChainHeaderChangedEventMananger.AddAsyncListener(miner.chainHeaderChanged)
 	go miner.handleMsg()
 	return miner
 }
 
+// SetClockDrift sets the drift of the local clock checked with NTP, the miner refuses to start
+// if the drift exceeds common.MaxClockDrift.
+func (miner *Miner) SetClockDrift(drift time.Duration) {
+	atomic.StoreInt64(&miner.clockDrift, int64(drift))
+}
+
 // GetEngine gets the miner engine
 func (miner *Miner) GetEngine() consensus.Engine {
 	return miner.engine
@@ -125,18 +164,51 @@ func (miner *Miner) GetCoinbase() common.Address {
 	return miner.coinbase
 }
 
+// SetCoinbaseWeight sets the weight and the daily quota of the coinbase in pool mode,
+// and adds the coinbase to the coinbase list if not exists.
+func (miner *Miner) SetCoinbaseWeight(coinbase common.Address, weight, quota uint64) error {
+	return miner.scheduler.set(coinbase, weight, quota)
+}
+
+// GetCoinbaseWeights gets the weights, the daily quotas and the blocks mined today of the coinbase list in pool mode
+func (miner *Miner) GetCoinbaseWeights() []CoinbaseWeight {
+	return miner.scheduler.list(time.Now())
+}
+
+// SetExtra sets the extra data in the mined block header for governance signaling.
+func (miner *Miner) SetExtra(extra []byte) error {
+	if len(extra) > types.MaxSignalingExtraSize {
+		return ErrExtraTooLong
+	}
+
+	miner.extra.Store(common.CopyBytes(extra))
+	return nil
+}
+
+// GetExtra gets the extra data in the mined block header.
+func (miner *Miner) GetExtra() []byte {
+	extra, _ := miner.extra.Load().([]byte)
+	return extra
+}
+
 // SetStopper. If stopper is 1, miner won't do mining
 func (miner *Miner) SetStopper(stopper int32) {
 	miner.stopper = stopper
 }
 
+// IsStopperSet returns true if the miner is stopped manually and won't do mining
+func (miner *Miner) IsStopperSet() bool {
+	return atomic.LoadInt32(&miner.stopper) == 1
+}
+
 // CanStart is true when the miner is stopped and stopper == 0 and
-// canStart == 1
+// canStart == 1 and the miner is not warming up after sync
 func (miner *Miner) CanStart() bool {
 	if atomic.LoadInt32(&miner.stopper) == 0 &&
 		atomic.LoadInt32(&miner.stopped) == 1 &&
 		atomic.LoadInt32(&miner.mining) == 0 &&
-		atomic.LoadInt32(&miner.canStart) == 1 {
+		atomic.LoadInt32(&miner.canStart) == 1 &&
+		atomic.LoadInt32(&miner.warming) == 0 {
 		return true
 	} else {
 		return false
@@ -179,6 +251,17 @@ func (miner *Miner) handleMsg() {
 
 // Start is used to start the miner
 func (miner *Miner) Start() error {
+	if drift := time.Duration(atomic.LoadInt64(&miner.clockDrift)); drift > common.MaxClockDrift || drift < -common.MaxClockDrift {
+		return ErrClockDrift
+	}
+
+	// the instant seal engine waits for the new txs or debts instead of sealing empty blocks
+	if _, ok := miner.engine.(consensus.InstantSealer); ok && !miner.hasPendingTxsOrDebts() {
+		atomic.StoreInt32(&miner.stopped, 1)
+		miner.log.Debug("no pending txs or debts, waiting for the new ones to seal a block")
+		return nil
+	}
+
 	miner.stopChan = make(chan struct{})
 
 	if istanbul, ok := miner.engine.(consensus.Istanbul); ok {
@@ -234,6 +317,20 @@ func (miner *Miner) IsMining() bool {
 	return atomic.LoadInt32(&miner.mining) == 1
 }
 
+// Restart restarts the mining loop, used to recover a stalled miner.
+// It is a no-op if the miner is stopped manually or by the downloader.
+func (miner *Miner) Restart() {
+	if miner.IsStopperSet() || atomic.LoadInt32(&miner.canStart) == 0 {
+		return
+	}
+
+	miner.log.Info("restart miner")
+	if miner.IsMining() {
+		miner.msgChan <- false
+	}
+	miner.msgChan <- true
+}
+
 // downloaderEventCallback handles events which indicate the downloader state
 func (miner *Miner) downloaderEventCallback(e event.Event) {
 
@@ -241,15 +338,26 @@ func (miner *Miner) downloaderEventCallback(e event.Event) {
 	case event.DownloaderStartEvent:
 		miner.log.Info("got download start event, stop miner")
 		atomic.StoreInt32(&miner.canStart, 0)
+		miner.cancelWarmup()
 		miner.msgChan <- false
 
 	case event.DownloaderDoneEvent, event.DownloaderFailedEvent:
 		atomic.StoreInt32(&miner.canStart, 1)
 		atomic.StoreInt32(&miner.isFirstDownloader, 0)
+		if miner.startWarmup() {
+			miner.log.Info("got download done event, warm up miner")
+			return
+		}
+
 		miner.msgChan <- true
 	}
 }
 
+// hasPendingTxsOrDebts returns true if there are txs or debts in the pools to pack
+func (miner *Miner) hasPendingTxsOrDebts() bool {
+	return miner.scdo.TxPool().GetPendingTxCount() > 0 || miner.scdo.DebtPool().GetDebtCount(false, true) > 0
+}
+
 // newTxOrDebtCallback handles the new tx event
 func (miner *Miner) newTxOrDebtCallback(e event.Event) {
 	miner.msgChan <- true
@@ -268,6 +376,7 @@ out:
 				}
 
 				miner.log.Info("found a new mined block, height:%d, hash:%s, time:%d", result.Header.Height, result.HeaderHash.Hex(), time.Now().Unix())
+				miner.lastSealed.Store(result.HeaderHash)
 				ret := miner.saveBlock(result)
 				if ret != nil {
 					miner.log.Error("failed to save the block, for %s", ret.Error())
@@ -279,6 +388,10 @@ out:
 					h.NewChainHead()
 				}
 
+				if miner.poolMode {
+					miner.scheduler.mined(result.Header.Creator, time.Now())
+				}
+
 				miner.log.Info("saved mined block successfully")
 				event.BlockMinedEventManager.Fire(result) // notify p2p to broadcast the block
 				break
@@ -325,6 +438,7 @@ func (miner *Miner) prepareNewBlock(recv chan *types.Block) error {
 	}
 
 	header := newHeaderByParent(parent, miner.coinbase, timestamp)
+	header.ExtraData = common.CopyBytes(miner.GetExtra())
 	miner.log.Debug("mining a block with coinbase %s", miner.coinbase.Hex())
 
 	err = miner.engine.Prepare(miner.scdo.BlockChain(), header)
@@ -332,48 +446,84 @@ func (miner *Miner) prepareNewBlock(recv chan *types.Block) error {
 		return fmt.Errorf("failed to prepare header, %s", err)
 	}
 
+	// the pow block extra data is only allowed after the signaling fork
+	if header.Consensus != types.IstanbulConsensus && !miner.scdo.BlockChain().Config().IsSignalingExtra(header.Height) {
+		header.ExtraData = nil
+	}
+
+	coinbase := miner.coinbase
 	if miner.poolMode {
 		// pool mining mode
-		miner.chooseCoinBase()
-		header.Creator = miner.coinbase
+		coinbase = miner.chooseCoinBase()
+		header.Creator = coinbase
 	}
 
 	if common.IsShardEnabled() {
-		if coinbaseShardNum := miner.coinbase.Shard(); coinbaseShardNum != common.LocalShardNumber {
+		if coinbaseShardNum := coinbase.Shard(); coinbaseShardNum != common.LocalShardNumber {
 			return fmt.Errorf("invalid coinbase, shard number is [%v], but local shard number is [%v]", coinbaseShardNum, common.LocalShardNumber)
 		}
 	}
 
-	miner.current = NewTask(header, miner.coinbase, miner.debtVerifier)
-	err = miner.current.applyTransactionsAndDebts(miner.scdo, stateDB, miner.scdo.BlockChain().AccountDB(), miner.log)
+	task := NewTask(header, coinbase, miner.debtVerifier)
+	task.templateHook = miner.templateHook
+	err = task.applyTransactionsAndDebts(miner.scdo, stateDB, miner.scdo.BlockChain().AccountDB(), miner.log)
 	if err != nil {
 		return fmt.Errorf("failed to apply transaction %s", err)
 	}
 
 	if miner.poolMode {
 		miner.log.Info("create a new task for the pool, height:%d, difficult:%d", header.Height, header.Difficulty)
-		preBlock := miner.current.generateBlock()
-		miner.current.header = preBlock.Header.Clone()
+		preBlock := task.generateBlock()
+		task.header = preBlock.Header.Clone()
+		miner.setWorkTask(task)
 	} else {
 		miner.log.Info("committing a new task to engine, height:%d, difficult:%d", header.Height, header.Difficulty)
-		miner.commitTask(miner.current, recv)
+		miner.setWorkTask(task)
+		miner.commitTask(task, recv)
 	}
 	return nil
 }
 
 // saveBlock saves the block in the given result to the blockchain
 func (miner *Miner) saveBlock(result *types.Block) error {
-	now := time.Now()
-	// entrance
-	memory.Print(miner.log, "miner saveBlock entrance", now, false)
 	txPool := miner.scdo.TxPool().Pool
 
-	ret := miner.scdo.BlockChain().WriteBlock(result, txPool)
+	return miner.scdo.BlockChain().WriteBlock(result, txPool)
+}
 
-	// entrance
-	memory.Print(miner.log, "miner saveBlock exit", now, true)
+// MineBlock mines a block of the pending txs and debts on demand, and saves and broadcasts it the same as
+// the mining loop. It is only supported by the instant seal engine when the miner is not running, e.g. to
+// mine the blocks one by one in the tests.
+func (miner *Miner) MineBlock() (*types.Block, error) {
+	if _, ok := miner.engine.(consensus.InstantSealer); !ok {
+		return nil, ErrNotInstantSeal
+	}
+
+	if miner.IsMining() {
+		return nil, ErrMinerIsRunning
+	}
+
+	if miner.poolMode {
+		return nil, errors.New("can not mine a block on demand in pool mode")
+	}
+
+	// the instant seal engine never waits for the stop channel, but it is still renewed in case it is closed
+	miner.stopChan = make(chan struct{})
+
+	recv := make(chan *types.Block, 1)
+	if err := miner.prepareNewBlock(recv); err != nil {
+		return nil, err
+	}
+
+	block := <-recv
+	if err := miner.saveBlock(block); err != nil {
+		return nil, fmt.Errorf("failed to save the block, %s", err)
+	}
 
-	return ret
+	miner.log.Info("saved block mined on demand, height:%d, hash:%s", block.Header.Height, block.HeaderHash.Hex())
+	event.BlockMinedEventManager.Fire(block)
+
+	return block, nil
 }
 
 // commitTask commits the given task to the miner
@@ -382,21 +532,34 @@ func (miner *Miner) commitTask(task *Task, recv chan *types.Block) {
 	miner.engine.Seal(miner.scdo.BlockChain(), block, miner.stopChan, recv)
 }
 
-//GetWork get the current task in a printable format
+// GetWork get the current task in a printable format
 func (miner *Miner) GetWork() map[string]interface{} {
-	if miner.current == nil {
+	task := miner.GetWorkTask()
+	if task == nil {
 		miner.log.Info("there is no task so far")
 		return nil
 	}
-	task := miner.current
 	return PrintableOutputTask(task)
 }
 
-// GetWorkTask gets the current task
+// GetWorkTask gets the current task. The task is not changed once set, so it is safe to read
+// after the miner moves on to the next task.
 func (miner *Miner) GetWorkTask() *Task {
+	miner.currentLock.RLock()
+	defer miner.currentLock.RUnlock()
+
 	return miner.current
 }
 
+// setWorkTask sets the current task
+func (miner *Miner) setWorkTask(task *Task) {
+	miner.currentLock.Lock()
+	defer miner.currentLock.Unlock()
+
+	miner.current = task
+	atomic.StoreInt64(&miner.taskTime, time.Now().UnixNano())
+}
+
 // GetCurrentWorkHeader returns the header of current task
 func (miner *Miner) GetCurrentWorkHeader(totalDifficulty *big.Int) map[string]interface{} {
 	task := miner.GetWorkTask()
@@ -414,24 +577,32 @@ func (miner *Miner) SubmitWork(height uint64, nonce uint64) error {
 
 	// validate nonce based on miner.current
 	// If valid, create a block and pass it into miner.recv
-	if miner.current == nil {
+	miner.currentLock.Lock()
+	task := miner.current
+	if task == nil {
+		miner.currentLock.Unlock()
 		return errors.New("there is no task so far")
 	}
 
-	if miner.current.header.Height != height {
+	if task.header.Height != height {
+		miner.currentLock.Unlock()
 		return errors.New("Height not match")
 	}
 
-	taskHeader := miner.current.header.Clone()
+	taskHeader := task.header.Clone()
 	taskHeader.Witness = []byte(strconv.FormatUint(nonce, 10))
 
 	err := miner.engine.VerifyHeader(miner.scdo.BlockChain(), taskHeader)
 	if err != nil {
+		miner.currentLock.Unlock()
 		return err
 	}
-	miner.current.header.Witness = taskHeader.Witness
-	block := miner.current.generateBlock()
+
+	// the task is shared with the readers, so the block is built with the header copy
+	block := types.NewBlock(taskHeader, task.txs, task.receipts, task.debts)
 	miner.current = nil
+	miner.currentLock.Unlock()
+
 	miner.recv <- block
 	return nil
 
@@ -439,23 +610,24 @@ func (miner *Miner) SubmitWork(height uint64, nonce uint64) error {
 
 // GetTaskDifficulty gets the difficulty of current task
 func (miner *Miner) GetTaskDifficulty() *big.Int {
-
-	if miner.current == nil {
+	task := miner.GetWorkTask()
+	if task == nil {
 		miner.log.Info("there is no task so far")
 		return nil
 	}
-	difficulty := miner.current.header.Difficulty
+	difficulty := task.header.Difficulty
 	if difficulty == nil {
 		return nil
 	}
 	return difficulty
 }
 
-// chooseCoinBase selects the coinbase randomly from the given list
-func (miner *Miner) chooseCoinBase() {
-	if len(miner.coinbaseList) == 0 {
-		return
+// chooseCoinBase selects the coinbase by weight from the coinbase list, and falls back
+// to the miner coinbase if no coinbase in the list is available.
+func (miner *Miner) chooseCoinBase() common.Address {
+	if coinbase, ok := miner.scheduler.choose(time.Now()); ok {
+		return coinbase
 	}
-	index := rand.Intn(len(miner.coinbaseList))
-	miner.coinbase = miner.coinbaseList[index]
+
+	return miner.coinbase
 }
@@ -43,6 +43,27 @@ func Test_SetCoinbase(t *testing.T) {
 	assert.Equal(t, miner.GetCoinbase(), newAddr)
 }
 
+func Test_SetExtra(t *testing.T) {
+	miner := NewMiner(defaultMinerAddr, nil, nil, nil, nil, false)
+	assert.Equal(t, len(miner.GetExtra()), 0)
+
+	assert.Equal(t, miner.SetExtra(make([]byte, types.MaxSignalingExtraSize+1)), ErrExtraTooLong)
+
+	// set by rpc while the mining loop reads it
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			miner.GetExtra()
+		}
+	}()
+
+	assert.Equal(t, miner.SetExtra([]byte("signal")), nil)
+	wg.Wait()
+	assert.Equal(t, miner.GetExtra(), []byte("signal"))
+}
+
 func Test_Start(t *testing.T) {
 	// Init LevelDB
 	dir := prepareDbFolder("", "leveldbtest")
@@ -0,0 +1,91 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/event"
+)
+
+// defaultPreemptMinWorkTime is the default min work time on a task before it is preempted
+const defaultPreemptMinWorkTime = time.Second
+
+// SetPreemption sets the min work time on a task before it is preempted by a new canonical head, so that
+// the miner doesn't thrash on the heads arriving in a burst. 0 means the default 1 second, and negative
+// disables the preemption.
+func (miner *Miner) SetPreemption(minWorkTime time.Duration) {
+	if minWorkTime == 0 {
+		minWorkTime = defaultPreemptMinWorkTime
+	}
+
+	atomic.StoreInt64(&miner.preemptMinWork, int64(minWorkTime))
+}
+
+// chainHeaderChanged schedules the preemption of the task being sealed once the HEAD is changed by
+// a block from the other miners.
+func (miner *Miner) chainHeaderChanged(e event.Event) {
+	block := e.(*types.Block)
+
+	// the block mined by the miner itself is followed by a new task anyway
+	if sealed, ok := miner.lastSealed.Load().(common.Hash); ok && sealed == block.HeaderHash {
+		return
+	}
+
+	miner.schedulePreemption()
+}
+
+// schedulePreemption preempts the task once the min work time on it elapses, and the preemptions of the
+// heads arriving in the meantime are merged into one.
+func (miner *Miner) schedulePreemption() {
+	minWork := time.Duration(atomic.LoadInt64(&miner.preemptMinWork))
+	if minWork < 0 || !miner.IsMining() {
+		return
+	}
+
+	// the BFT engine proposes the block on the head agreed by the validators
+	if _, ok := miner.engine.(consensus.Istanbul); ok {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&miner.preempting, 0, 1) {
+		return
+	}
+
+	wait := minWork - time.Since(time.Unix(0, atomic.LoadInt64(&miner.taskTime)))
+	if wait < 0 {
+		wait = 0
+	}
+
+	time.AfterFunc(wait, func() {
+		atomic.StoreInt32(&miner.preempting, 0)
+		miner.preemptIfStale(miner.scdo.BlockChain().CurrentHeader())
+	})
+}
+
+// preemptIfStale restarts the miner to prepare a new task on the HEAD if the current task is on a stale parent,
+// and returns true if preempted.
+func (miner *Miner) preemptIfStale(head *types.BlockHeader) bool {
+	task := miner.GetWorkTask()
+	if task == nil || !miner.IsMining() {
+		return false
+	}
+
+	headHash := head.Hash()
+	if task.header.PreviousBlockHash == headHash {
+		return false
+	}
+
+	miner.log.Info("preempt the task of height %d on the stale parent %s, new HEAD %d %s", task.header.Height,
+		task.header.PreviousBlockHash.Hex(), head.Height, headHash.Hex())
+	miner.Restart()
+
+	return true
+}
@@ -0,0 +1,85 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPreemptionTestMiner(parent common.Hash) *Miner {
+	miner := &Miner{
+		mining:   1,
+		canStart: 1,
+		log:      log.GetLogger("miner"),
+		msgChan:  make(chan bool, 10),
+	}
+
+	miner.SetPreemption(0)
+	miner.setWorkTask(&Task{header: &types.BlockHeader{PreviousBlockHash: parent, Height: 10}})
+
+	return miner
+}
+
+func Test_Miner_SetPreemption(t *testing.T) {
+	miner := &Miner{}
+
+	miner.SetPreemption(0)
+	assert.Equal(t, time.Duration(miner.preemptMinWork), defaultPreemptMinWorkTime)
+
+	miner.SetPreemption(200 * time.Millisecond)
+	assert.Equal(t, time.Duration(miner.preemptMinWork), 200*time.Millisecond)
+
+	// disabled, nothing is scheduled
+	miner.SetPreemption(-1)
+	miner.mining = 1
+	miner.schedulePreemption()
+	assert.Equal(t, miner.preempting, int32(0))
+}
+
+func Test_Miner_PreemptIfStale(t *testing.T) {
+	parent := &types.BlockHeader{Height: 9, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}
+	miner := newPreemptionTestMiner(parent.Hash())
+
+	// the task is on the HEAD
+	assert.Equal(t, miner.preemptIfStale(parent), false)
+	assert.Equal(t, len(miner.msgChan), 0)
+
+	// restarted on a new HEAD
+	head := &types.BlockHeader{Height: 10, PreviousBlockHash: parent.Hash(), Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(2)}
+	assert.Equal(t, miner.preemptIfStale(head), true)
+	assert.Equal(t, <-miner.msgChan, false)
+	assert.Equal(t, <-miner.msgChan, true)
+
+	// not preempted if not mining
+	miner.mining = 0
+	assert.Equal(t, miner.preemptIfStale(head), false)
+}
+
+func Test_Miner_ChainHeaderChanged(t *testing.T) {
+	miner := newPreemptionTestMiner(common.StringToHash("parent"))
+	miner.SetPreemption(time.Hour)
+
+	// the block sealed by the miner itself
+	sealed := &types.Block{HeaderHash: common.StringToHash("sealed"), Header: &types.BlockHeader{}}
+	miner.lastSealed.Store(sealed.HeaderHash)
+	miner.chainHeaderChanged(sealed)
+	assert.Equal(t, miner.preempting, int32(0))
+
+	// the block from the other miners is preempted after the min work time
+	miner.chainHeaderChanged(&types.Block{HeaderHash: common.StringToHash("other"), Header: &types.BlockHeader{}})
+	assert.Equal(t, miner.preempting, int32(1))
+
+	// merged into the scheduled preemption
+	miner.schedulePreemption()
+	assert.Equal(t, miner.preempting, int32(1))
+}
@@ -7,10 +7,8 @@ package miner
 
 import (
 	"math/big"
-	"time"
 
 	"github.com/scdoproject/go-scdo/common"
-	"github.com/scdoproject/go-scdo/common/memory"
 	"github.com/scdoproject/go-scdo/consensus"
 	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/core/state"
@@ -18,6 +16,7 @@ import (
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/database"
 	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
 )
 
 // Task is a mining work for engine, containing block header, transactions, and transaction receipts.
@@ -29,6 +28,7 @@ type Task struct {
 
 	coinbase     common.Address
 	debtVerifier types.DebtVerifier
+	templateHook BlockTemplateHook // customizes the txs of the block, nil to pack the txs by price
 }
 
 // NewTask return Task object
@@ -42,12 +42,14 @@ func NewTask(header *types.BlockHeader, coinbase common.Address, verifier types.
 
 // applyTransactionsAndDebts TODO need to check more about the transactions, such as gas limit
 func (task *Task) applyTransactionsAndDebts(scdo ScdoBackend, statedb *state.Statedb, accountStateDB database.Database, log *log.ScdoLog) error {
-	now := time.Now()
-	// entrance
-	memory.Print(log, "task applyTransactionsAndDebts entrance", now, false)
+	span := tracing.StartSpan("miner.applyTransactionsAndDebts", tracing.Uint("height", task.header.Height))
+	defer span.End()
 
-	// choose debts from the pool
-	size := task.chooseDebts(scdo, statedb, log)
+	// choose debts and txs from the pools by price, as they compete for the block space
+	debts, txs := task.selectByPrice(scdo, span)
+
+	// the debts are applied ahead of the txs
+	size := task.chooseDebts(scdo, statedb, log, debts, span)
 
 	// the reward tx will always be at the first of the block's transactions
 	reward, err := task.handleMinerRewardTx(statedb)
@@ -55,8 +57,8 @@ func (task *Task) applyTransactionsAndDebts(scdo ScdoBackend, statedb *state.Sta
 		return err
 	}
 
-	// choose txs from the pool
-	task.chooseTransactions(scdo, statedb, log, size)
+	// apply the selected txs, and fill the rest of the block with txs from the pool
+	task.chooseTransactions(scdo, statedb, log, size, txs, span)
 
 	log.Info("mining block height:%d, reward:%s, transaction number:%d, debt number: %d",
 		task.header.Height, reward, len(task.txs), len(task.debts))
@@ -69,52 +71,95 @@ func (task *Task) applyTransactionsAndDebts(scdo ScdoBackend, statedb *state.Sta
 
 	task.header.StateHash = root
 
-	// exit
-	memory.Print(log, "task applyTransactionsAndDebts exit", now, true)
-
 	return nil
 }
 
-// chooseDebts choose debts from the debt pool
-func (task *Task) chooseDebts(scdo ScdoBackend, statedb *state.Statedb, log *log.ScdoLog) int {
-	now := time.Now()
-	// entrance
-	memory.Print(log, "task chooseDebts entrance", now, false)
+// selectByPrice takes the processable debts and txs from the pools, and selects them by price in
+// descending order until the block is full. The debts and txs not selected are returned to the pools.
+func (task *Task) selectByPrice(scdo ScdoBackend, span *tracing.Span) ([]*types.Debt, []*types.Transaction) {
+	span = span.StartChild("selectByPrice")
+	defer span.End()
 
-	size := core.BlockByteLimit
+	debts, _ := scdo.DebtPool().GetProcessableDebts(core.BlockByteLimit)
+	txs, _ := scdo.TxPool().GetProcessableTransactions(core.BlockByteLimit)
 
-	for size > 0 {
-		debts, _ := scdo.DebtPool().GetProcessableDebts(size)
-		if len(debts) == 0 {
-			break
-		}
+	selectedDebts, restDebts, selectedTxs, restTxs := selectByPrice(debts, txs, core.BlockByteLimit)
 
-		canonicalHeadBlock := scdo.BlockChain().CurrentBlock()
-		preHeader, err := scdo.BlockChain().GetStore().GetBlockHeader(task.header.PreviousBlockHash)
-		if err != nil {
-			return size
-		}
+	scdo.DebtPool().ReturnDebts(restDebts)
+	scdo.TxPool().ReturnTransactions(restTxs)
 
-		commonAncestor, err := scdo.BlockChain().FindCommonForkAncestor(preHeader, canonicalHeadBlock.Header)
-		if err != nil {
-			return size
-		}
-		for _, d := range debts {
-			log.Debug("debt hash: %v", d.Hash)
-			err := scdo.BlockChain().ApplyDebtWithoutVerify(statedb, d, task.coinbase, preHeader, commonAncestor)
-			if err != nil {
-				log.Debug("apply debt error %s", err)
-				scdo.DebtPool().RemoveDebtByHash(d.Hash)
-				continue
+	span.SetAttributes(tracing.Int("debts", int64(len(selectedDebts))), tracing.Int("txs", int64(len(selectedTxs))))
+
+	return selectedDebts, selectedTxs
+}
+
+// selectByPrice merges the debts and txs by price in descending order, and selects them until
+// the size limit is reached. The debts win the ties, as they settle the transfers from other shards.
+// The relative order of the debts and txs is kept, e.g. the nonce order of the txs of the same account,
+// so the selection stops at the first one exceeding the size limit, and all the rest are not selected.
+func selectByPrice(debts []*types.Debt, txs []*types.Transaction, size int) (selectedDebts, restDebts []*types.Debt,
+	selectedTxs, restTxs []*types.Transaction) {
+	i, j := 0, 0
+
+	for i < len(debts) || j < len(txs) {
+		if j == len(txs) || (i < len(debts) && debts[i].Price().Cmp(txs[j].Price()) >= 0) {
+			if debts[i].Size() > size {
+				break
 			}
 
-			size = size - d.Size()
-			task.debts = append(task.debts, d)
+			size -= debts[i].Size()
+			i++
+		} else {
+			if txs[j].Size() > size {
+				break
+			}
+
+			size -= txs[j].Size()
+			j++
 		}
 	}
 
-	// exit
-	memory.Print(log, "task chooseDebts exit", now, true)
+	return debts[:i], debts[i:], txs[:j], txs[j:]
+}
+
+// chooseDebts applies the selected debts, and returns the remaining size of the block
+func (task *Task) chooseDebts(scdo ScdoBackend, statedb *state.Statedb, log *log.ScdoLog, debts []*types.Debt, span *tracing.Span) int {
+	span = span.StartChild("chooseDebts")
+	defer func() {
+		span.SetAttributes(tracing.Int("debts", int64(len(task.debts))))
+		span.End()
+	}()
+
+	size := core.BlockByteLimit
+	if len(debts) == 0 {
+		return size
+	}
+
+	canonicalHeadBlock := scdo.BlockChain().CurrentBlock()
+	preHeader, err := scdo.BlockChain().GetStore().GetBlockHeader(task.header.PreviousBlockHash)
+	if err != nil {
+		scdo.DebtPool().ReturnDebts(debts)
+		return size
+	}
+
+	commonAncestor, err := scdo.BlockChain().FindCommonForkAncestor(preHeader, canonicalHeadBlock.Header)
+	if err != nil {
+		scdo.DebtPool().ReturnDebts(debts)
+		return size
+	}
+
+	for _, d := range debts {
+		log.Debug("debt hash: %v", d.Hash)
+		err := scdo.BlockChain().ApplyDebtWithoutVerify(statedb, d, task.coinbase, preHeader, commonAncestor)
+		if err != nil {
+			log.Debug("apply debt error %s", err)
+			scdo.DebtPool().RemoveDebtByHash(d.Hash)
+			continue
+		}
+
+		size = size - d.Size()
+		task.debts = append(task.debts, d)
+	}
 
 	return size
 }
@@ -140,25 +185,43 @@ func (task *Task) handleMinerRewardTx(statedb *state.Statedb) (*big.Int, error)
 	return reward, nil
 }
 
-// chooseTransactions choose transactions from the txpool
-func (task *Task) chooseTransactions(scdo ScdoBackend, statedb *state.Statedb, log *log.ScdoLog, size int) {
-	now := time.Now()
-	// entrance
-	memory.Print(log, "task chooseTransactions entrance", now, false)
+// chooseTransactions applies the selected transactions, and then chooses more transactions
+// from the txpool if the block is not full
+func (task *Task) chooseTransactions(scdo ScdoBackend, statedb *state.Statedb, log *log.ScdoLog, size int,
+	txs []*types.Transaction, span *tracing.Span) {
+	span = span.StartChild("chooseTransactions")
+	defer func() {
+		span.SetAttributes(tracing.Int("txs", int64(len(task.txs))))
+		span.End()
+	}()
 
 	txIndex := 1 // the first tx is miner reward
 
-	for size > 0 {
-		txs, txsSize := scdo.TxPool().GetProcessableTransactions(size)
-		if len(txs) == 0 {
-			break
+	for len(txs) > 0 {
+		if task.templateHook != nil {
+			var rest []*types.Transaction
+			txs, rest = task.templateHook.Arrange(&TemplateContext{
+				Header:       task.header,
+				Size:         size,
+				ReceivedTime: scdo.TxPool().GetTxReceivedTime,
+			}, txs)
+			scdo.TxPool().ReturnTransactions(rest)
+
+			// the rest txs are taken again from the pool if nothing is selected
+			if len(txs) == 0 {
+				break
+			}
 		}
 
-		for _, tx := range txs {
-			if err := tx.Validate(statedb, task.header.Height); err != nil {
+		for i, tx := range txs {
+			if tx.Size() > size {
+				scdo.TxPool().ReturnTransactions(txs[i:])
+				return
+			}
+
+			if err := tx.Validate(statedb, scdo.BlockChain().Config(), task.header.Height); err != nil {
 				scdo.TxPool().RemoveTransaction(tx.Hash)
 				log.Error("failed to validate tx %s, for %s", tx.Hash.Hex(), err)
-				txsSize = txsSize - tx.Size()
 				continue
 			}
 
@@ -166,20 +229,21 @@ func (task *Task) chooseTransactions(scdo ScdoBackend, statedb *state.Statedb, l
 			if err != nil {
 				scdo.TxPool().RemoveTransaction(tx.Hash)
 				log.Error("failed to apply tx %s, %s", tx.Hash.Hex(), err)
-				txsSize = txsSize - tx.Size()
 				continue
 			}
 
 			task.txs = append(task.txs, tx)
 			task.receipts = append(task.receipts, receipt)
 			txIndex++
+			size -= tx.Size()
 		}
 
-		size -= txsSize
-	}
+		if size <= 0 {
+			break
+		}
 
-	// exit
-	memory.Print(log, "task chooseTransactions exit", now, true)
+		txs, _ = scdo.TxPool().GetProcessableTransactions(size)
+	}
 }
 
 // generateBlock builds a block from task
@@ -187,6 +251,14 @@ func (task *Task) generateBlock() *types.Block {
 	return types.NewBlock(task.header, task.txs, task.receipts, task.debts)
 }
 
+// PendingBlock returns the block built from the task, which is not sealed yet, and the receipts of its txs
+func (task *Task) PendingBlock() (*types.Block, []*types.Receipt) {
+	receipts := make([]*types.Receipt, len(task.receipts))
+	copy(receipts, task.receipts)
+
+	return task.generateBlock(), receipts
+}
+
 // Result is the result mined by engine. It contains the raw task and mined block.
 type Result struct {
 	task  *Task
@@ -131,3 +131,55 @@ func testWithBackend(verifier types.DebtVerifier, t *testing.T) (*types.Block, *
 
 	return resultBlock, debtPool
 }
+
+func Test_SelectByPrice(t *testing.T) {
+	d1 := types.NewTestDebtDetail(1, 20)
+	d2 := types.NewTestDebtDetail(1, 5)
+	tx1 := types.NewTestTxDetail(1, 10, 1)
+	tx2 := types.NewTestTxDetail(1, 5, 2)
+
+	debts := []*types.Debt{d1, d2}
+	txs := []*types.Transaction{tx1, tx2}
+
+	// all selected
+	selectedDebts, restDebts, selectedTxs, restTxs := selectByPrice(debts, txs, core.BlockByteLimit)
+	assert.Equal(t, selectedDebts, debts)
+	assert.Equal(t, len(restDebts), 0)
+	assert.Equal(t, selectedTxs, txs)
+	assert.Equal(t, len(restTxs), 0)
+
+	// the debt of price 20 and the tx of price 10 are selected
+	size := d1.Size() + tx1.Size()
+	selectedDebts, restDebts, selectedTxs, restTxs = selectByPrice(debts, txs, size)
+	assert.Equal(t, selectedDebts, []*types.Debt{d1})
+	assert.Equal(t, restDebts, []*types.Debt{d2})
+	assert.Equal(t, selectedTxs, []*types.Transaction{tx1})
+	assert.Equal(t, restTxs, []*types.Transaction{tx2})
+
+	// the debt wins the tie of price 5
+	size += d2.Size()
+	selectedDebts, _, selectedTxs, restTxs = selectByPrice(debts, txs, size)
+	assert.Equal(t, selectedDebts, debts)
+	assert.Equal(t, selectedTxs, []*types.Transaction{tx1})
+	assert.Equal(t, restTxs, []*types.Transaction{tx2})
+}
+
+func Test_Task_PendingBlock(t *testing.T) {
+	tx := types.NewTestTxDetail(1, 10, 1)
+	task := &Task{
+		header:   newTestBlockHeader(),
+		txs:      []*types.Transaction{tx},
+		receipts: []*types.Receipt{{TxHash: tx.Hash}},
+	}
+
+	block, receipts := task.PendingBlock()
+	assert.Equal(t, block.Header.Height, task.header.Height)
+	assert.Equal(t, block.Transactions, task.txs)
+	assert.Equal(t, receipts, task.receipts)
+
+	// the task is not changed by the returned block and receipts
+	block.Header.Witness = []byte("nonce")
+	receipts[0] = nil
+	assert.Equal(t, task.header.Witness, []byte{})
+	assert.Equal(t, task.receipts[0].TxHash, tx.Hash)
+}
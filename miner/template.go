@@ -0,0 +1,236 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"plugin"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+const (
+	// TemplateOrderPrice orders the txs by price, which is the order of the txs taken from the pool
+	TemplateOrderPrice = "price"
+
+	// TemplateOrderFIFO orders the txs by the time received in the pool
+	TemplateOrderFIFO = "fifo"
+
+	// TemplatePluginSymbol is the symbol of the block template hook exported by a Go plugin, which is a
+	// variable of type miner.BlockTemplateHook
+	TemplatePluginSymbol = "BlockTemplateHook"
+)
+
+var errInvalidTemplateOrder = errors.New("invalid block template tx order")
+
+// TemplateContext is the context of the block template being built
+type TemplateContext struct {
+	Header *types.BlockHeader
+	Size   int // remaining bytes of the block for the txs
+
+	// ReceivedTime returns the time when the tx is received in the pool, false if unknown
+	ReceivedTime func(hash common.Hash) (time.Time, bool)
+}
+
+// BlockTemplateHook customizes the txs packed in the block template of the miner, e.g. the tx ordering,
+// the exclusion lists or the reserved space for the priority txs.
+type BlockTemplateHook interface {
+	// Arrange returns the txs to apply in order within the remaining size of the block, and the rest
+	// which are returned to the pool. The txs of an account must be kept in nonce order, otherwise
+	// they fail the validation and are removed from the pool.
+	Arrange(ctx *TemplateContext, txs []*types.Transaction) (selected, rest []*types.Transaction)
+}
+
+// TemplateRules is the rule file of the block template, which is a JSON file.
+type TemplateRules struct {
+	// Order is the order of the txs, price or fifo, empty means price
+	Order string `json:"order"`
+
+	// Exclude is the accounts whose txs are never packed, either sent from or to them
+	Exclude []common.Address `json:"exclude"`
+
+	// Priority is the accounts whose txs are packed ahead of the others
+	Priority []common.Address `json:"priority"`
+
+	// ReservedSize is the bytes of the block reserved for the txs of the priority accounts
+	ReservedSize int `json:"reservedSize"`
+}
+
+// ruleHook is the block template hook of the rules
+type ruleHook struct {
+	fifo     bool
+	exclude  map[common.Address]bool
+	priority map[common.Address]bool
+	reserved int
+}
+
+// NewRuleHook creates the block template hook of the rules
+func NewRuleHook(rules *TemplateRules) (BlockTemplateHook, error) {
+	h := &ruleHook{
+		exclude:  make(map[common.Address]bool),
+		priority: make(map[common.Address]bool),
+		reserved: rules.ReservedSize,
+	}
+
+	switch rules.Order {
+	case "", TemplateOrderPrice:
+	case TemplateOrderFIFO:
+		h.fifo = true
+	default:
+		return nil, errInvalidTemplateOrder
+	}
+
+	for _, addr := range rules.Exclude {
+		h.exclude[addr] = true
+	}
+
+	for _, addr := range rules.Priority {
+		h.priority[addr] = true
+	}
+
+	return h, nil
+}
+
+// Arrange implements BlockTemplateHook
+func (h *ruleHook) Arrange(ctx *TemplateContext, txs []*types.Transaction) (selected, rest []*types.Transaction) {
+	var priority, normal []*types.Transaction
+	excluded := make(map[common.Address]bool)
+
+	for _, tx := range txs {
+		from := tx.FromAccount()
+
+		// the later txs of the account are not processable once a tx is excluded
+		if excluded[from] || h.exclude[from] || h.exclude[tx.ToAccount()] {
+			excluded[from] = true
+			rest = append(rest, tx)
+		} else if h.priority[from] {
+			priority = append(priority, tx)
+		} else {
+			normal = append(normal, tx)
+		}
+	}
+
+	if h.fifo && ctx.ReceivedTime != nil {
+		priority = sortByReceivedTime(priority, ctx.ReceivedTime)
+		normal = sortByReceivedTime(normal, ctx.ReceivedTime)
+	}
+
+	size := ctx.Size
+	selected, priority = selectBySize(priority, size)
+	rest = append(rest, priority...)
+
+	// the other txs could not use the reserved space left by the priority txs
+	used := types.GetTransactionsSize(selected)
+	size -= used
+	if used < h.reserved {
+		size -= h.reserved - used
+	}
+
+	normal, left := selectBySize(normal, size)
+	selected = append(selected, normal...)
+	rest = append(rest, left...)
+
+	return selected, rest
+}
+
+// selectBySize selects the txs in order until the size limit is reached, and all the rest are not selected
+// to keep the nonce order of the txs of the same account.
+func selectBySize(txs []*types.Transaction, size int) (selected, rest []*types.Transaction) {
+	for i, tx := range txs {
+		if tx.Size() > size {
+			return txs[:i], txs[i:]
+		}
+
+		size -= tx.Size()
+	}
+
+	return txs, nil
+}
+
+// sortByReceivedTime orders the txs by the time received in the pool, while the txs of the same account
+// are kept in the original nonce order. The txs with unknown received time are ordered last.
+func sortByReceivedTime(txs []*types.Transaction, receivedTime func(common.Hash) (time.Time, bool)) []*types.Transaction {
+	var accounts []common.Address
+	queues := make(map[common.Address][]*types.Transaction)
+	for _, tx := range txs {
+		from := tx.FromAccount()
+		if _, ok := queues[from]; !ok {
+			accounts = append(accounts, from)
+		}
+
+		queues[from] = append(queues[from], tx)
+	}
+
+	sorted := make([]*types.Transaction, 0, len(txs))
+	for len(sorted) < len(txs) {
+		var next common.Address
+		var nextTime time.Time
+		found, nextKnown := false, false
+
+		// the first account in original order wins the ties
+		for _, addr := range accounts {
+			queue := queues[addr]
+			if len(queue) == 0 {
+				continue
+			}
+
+			t, known := receivedTime(queue[0].Hash)
+			if !found || (known && (!nextKnown || t.Before(nextTime))) {
+				next, nextTime, nextKnown, found = addr, t, known, true
+			}
+		}
+
+		sorted = append(sorted, queues[next][0])
+		queues[next] = queues[next][1:]
+	}
+
+	return sorted
+}
+
+// LoadTemplateRules loads the rules of the block template from the JSON file
+func LoadTemplateRules(file string) (*TemplateRules, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules TemplateRules
+	if err = json.Unmarshal(content, &rules); err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to decode block template rules %s", file)
+	}
+
+	return &rules, nil
+}
+
+// LoadTemplatePlugin loads the block template hook exported by the Go plugin
+func LoadTemplatePlugin(path string) (BlockTemplateHook, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to open block template plugin %s", path)
+	}
+
+	symbol, err := p.Lookup(TemplatePluginSymbol)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to lookup block template hook in plugin %s", path)
+	}
+
+	hook, ok := symbol.(*BlockTemplateHook)
+	if !ok || *hook == nil {
+		return nil, fmt.Errorf("%s in plugin %s is not a miner.BlockTemplateHook", TemplatePluginSymbol, path)
+	}
+
+	return *hook, nil
+}
+
+// SetBlockTemplateHook sets the hook to customize the txs of the block template, nil to pack the txs by price.
+func (miner *Miner) SetBlockTemplateHook(hook BlockTemplateHook) {
+	miner.templateHook = hook
+}
@@ -0,0 +1,71 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTemplateTestTx creates a tx of the account received in the pool at the time
+func newTemplateTestTx(from common.Address, nonce uint64, received map[common.Hash]time.Time, at int64) *types.Transaction {
+	tx := types.NewTestTransactionWithNonce(nonce)
+	tx.Data.From = from
+	received[tx.Hash] = time.Unix(at, 0)
+	return tx
+}
+
+func Test_RuleHook_Arrange(t *testing.T) {
+	received := make(map[common.Hash]time.Time)
+	receivedTime := func(hash common.Hash) (time.Time, bool) {
+		at, ok := received[hash]
+		return at, ok
+	}
+
+	a, b, c := common.BytesToAddress([]byte{1}), common.BytesToAddress([]byte{2}), common.BytesToAddress([]byte{3})
+	a0 := newTemplateTestTx(a, 0, received, 30)
+	a1 := newTemplateTestTx(a, 1, received, 10)
+	b0 := newTemplateTestTx(b, 0, received, 20)
+	c0 := newTemplateTestTx(c, 0, received, 5)
+	c1 := newTemplateTestTx(c, 1, received, 40)
+	txs := []*types.Transaction{a0, b0, c0, a1, c1}
+	txSize := a0.Size()
+	ctx := &TemplateContext{Size: 100 * txSize, ReceivedTime: receivedTime}
+
+	// by price
+	hook, err := NewRuleHook(&TemplateRules{})
+	assert.Equal(t, err, nil)
+	selected, rest := hook.Arrange(ctx, txs)
+	assert.Equal(t, selected, txs)
+	assert.Equal(t, len(rest), 0)
+
+	// fifo, the nonce order of the account is kept
+	hook, err = NewRuleHook(&TemplateRules{Order: TemplateOrderFIFO})
+	assert.Equal(t, err, nil)
+	selected, _ = hook.Arrange(ctx, txs)
+	assert.Equal(t, selected, []*types.Transaction{c0, b0, a0, a1, c1})
+
+	// exclusion, the later txs of the excluded account are not selected
+	hook, err = NewRuleHook(&TemplateRules{Exclude: []common.Address{a0.ToAccount()}})
+	assert.Equal(t, err, nil)
+	selected, rest = hook.Arrange(ctx, txs)
+	assert.Equal(t, selected, []*types.Transaction{b0, c0, c1})
+	assert.Equal(t, rest, []*types.Transaction{a0, a1})
+
+	// priority with reserved space
+	hook, err = NewRuleHook(&TemplateRules{Priority: []common.Address{c}, ReservedSize: 3 * txSize})
+	assert.Equal(t, err, nil)
+	selected, rest = hook.Arrange(&TemplateContext{Size: 4 * txSize}, txs)
+	assert.Equal(t, selected, []*types.Transaction{c0, c1, a0})
+	assert.Equal(t, rest, []*types.Transaction{b0, a1})
+
+	_, err = NewRuleHook(&TemplateRules{Order: "random"})
+	assert.Equal(t, err, errInvalidTemplateOrder)
+}
@@ -0,0 +1,200 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+)
+
+const (
+	defaultWarmupStableTime = 5 * time.Second
+	defaultWarmupMinPeers   = 1
+	defaultWarmupTimeout    = 2 * time.Minute
+
+	warmupCheckInterval = time.Second
+)
+
+// decisions of the miner warmup
+const (
+	WarmupDisabled = "disabled" // the miner starts immediately after sync
+	WarmupWaiting  = "waiting"  // waiting for the head to be stable and the peers to agree
+	WarmupReady    = "ready"    // the head is stable and enough peers agree, the miner is started
+	WarmupTimeout  = "timeout"  // the warmup timed out, the miner is started anyway
+	WarmupCanceled = "canceled" // a new sync session started during the warmup
+)
+
+// WarmupConfig is the config of the miner warmup after the sync is done, which delays the start of the
+// miner until the head is stable and the peers agree with it, so that the miner doesn't mine on a stale head.
+type WarmupConfig struct {
+	Enabled    bool
+	StableTime time.Duration // time without a new head, 0 means the default 5 seconds
+	MinPeers   int           // min peers in the local shard which are not ahead of the local head, 0 means the default 1
+	Timeout    time.Duration // max time of the warmup, 0 means the default 2 minutes
+}
+
+// PeerAgreement returns the number of the peers in the local shard which are not ahead of the local head,
+// and the number of all the peers in the local shard.
+type PeerAgreement func() (agreed int, total int)
+
+// WarmupStatus is the decision of the miner warmup after the last sync
+type WarmupStatus struct {
+	Decision    string
+	Start       int64 // unix time when the warmup started, 0 if no warmup yet
+	End         int64 // unix time when the warmup is decided, 0 if waiting
+	HeadHeight  uint64
+	HeadStable  int64 // seconds without a new head
+	AgreedPeers int
+	Peers       int
+}
+
+// SetWarmup sets the warmup config of the miner, and the peer agreement to check during the warmup.
+func (miner *Miner) SetWarmup(conf WarmupConfig, agreement PeerAgreement) {
+	if conf.StableTime <= 0 {
+		conf.StableTime = defaultWarmupStableTime
+	}
+
+	if conf.MinPeers <= 0 {
+		conf.MinPeers = defaultWarmupMinPeers
+	}
+
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultWarmupTimeout
+	}
+
+	miner.warmupLock.Lock()
+	defer miner.warmupLock.Unlock()
+
+	miner.warmupConf = conf
+	miner.peerAgreement = agreement
+	if !conf.Enabled {
+		miner.warmupStatus.Decision = WarmupDisabled
+	}
+}
+
+// IsWarmingUp returns true if the miner is waiting for the warmup after sync
+func (miner *Miner) IsWarmingUp() bool {
+	return atomic.LoadInt32(&miner.warming) == 1
+}
+
+// GetWarmupStatus returns the decision of the miner warmup after the last sync
+func (miner *Miner) GetWarmupStatus() WarmupStatus {
+	miner.warmupLock.Lock()
+	defer miner.warmupLock.Unlock()
+
+	return miner.warmupStatus
+}
+
+// decide returns the warmup decision by the time without a new head, the agreed peers and the warmup duration
+func (conf *WarmupConfig) decide(stable time.Duration, agreed int, elapsed time.Duration) string {
+	if stable >= conf.StableTime && agreed >= conf.MinPeers {
+		return WarmupReady
+	}
+
+	if elapsed >= conf.Timeout {
+		return WarmupTimeout
+	}
+
+	return WarmupWaiting
+}
+
+// startWarmup starts the warmup after sync, and the miner is started when it is decided.
+// It returns false if the warmup is disabled.
+func (miner *Miner) startWarmup() bool {
+	miner.warmupLock.Lock()
+	defer miner.warmupLock.Unlock()
+
+	if !miner.warmupConf.Enabled {
+		return false
+	}
+
+	if miner.warmupQuit != nil {
+		close(miner.warmupQuit)
+	}
+
+	miner.warmupQuit = make(chan struct{})
+	miner.warmupStatus = WarmupStatus{Decision: WarmupWaiting, Start: time.Now().Unix()}
+	atomic.StoreInt32(&miner.warming, 1)
+
+	go miner.warmupLoop(miner.warmupQuit)
+
+	return true
+}
+
+// cancelWarmup cancels the warmup when a new sync session starts
+func (miner *Miner) cancelWarmup() {
+	miner.warmupLock.Lock()
+	defer miner.warmupLock.Unlock()
+
+	if miner.warmupQuit == nil {
+		return
+	}
+
+	close(miner.warmupQuit)
+	miner.warmupQuit = nil
+	miner.warmupStatus.Decision = WarmupCanceled
+	miner.warmupStatus.End = time.Now().Unix()
+	atomic.StoreInt32(&miner.warming, 0)
+}
+
+func (miner *Miner) warmupLoop(quit chan struct{}) {
+	ticker := time.NewTicker(warmupCheckInterval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	head, changed := common.EmptyHash, start
+
+	for {
+		select {
+		case now := <-ticker.C:
+			current := miner.scdo.BlockChain().CurrentBlock()
+			if !current.HeaderHash.Equal(head) {
+				head, changed = current.HeaderHash, now
+			}
+
+			agreed, total := 0, 0
+			if miner.peerAgreement != nil {
+				agreed, total = miner.peerAgreement()
+			}
+
+			if miner.updateWarmup(quit, now, current.Header.Height, now.Sub(changed), agreed, total, now.Sub(start)) {
+				return
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+// updateWarmup updates the warmup status and starts the miner if decided. It returns true if the warmup is done.
+func (miner *Miner) updateWarmup(quit chan struct{}, now time.Time, height uint64, stable time.Duration, agreed, total int, elapsed time.Duration) bool {
+	miner.warmupLock.Lock()
+	defer miner.warmupLock.Unlock()
+
+	// canceled or restarted
+	if miner.warmupQuit != quit {
+		return true
+	}
+
+	status := &miner.warmupStatus
+	status.HeadHeight = height
+	status.HeadStable = int64(stable / time.Second)
+	status.AgreedPeers, status.Peers = agreed, total
+	if status.Decision = miner.warmupConf.decide(stable, agreed, elapsed); status.Decision == WarmupWaiting {
+		return false
+	}
+
+	status.End = now.Unix()
+	miner.warmupQuit = nil
+	atomic.StoreInt32(&miner.warming, 0)
+
+	miner.log.Info("miner warmup %s, head %d stable for %v, %d of %d peers agree", status.Decision, height, stable, agreed, total)
+	miner.msgChan <- true
+
+	return true
+}
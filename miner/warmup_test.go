@@ -0,0 +1,80 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWarmupMiner(enabled bool) *Miner {
+	miner := &Miner{
+		stopped:  1,
+		canStart: 1,
+		log:      log.GetLogger("miner"),
+		msgChan:  make(chan bool, 1),
+	}
+
+	miner.SetWarmup(WarmupConfig{Enabled: enabled}, nil)
+
+	return miner
+}
+
+func Test_WarmupConfig_Decide(t *testing.T) {
+	miner := newWarmupMiner(true)
+	conf := miner.warmupConf
+	assert.Equal(t, conf.StableTime, defaultWarmupStableTime)
+	assert.Equal(t, conf.MinPeers, defaultWarmupMinPeers)
+	assert.Equal(t, conf.Timeout, defaultWarmupTimeout)
+
+	assert.Equal(t, conf.decide(time.Second, 1, time.Second), WarmupWaiting)
+	assert.Equal(t, conf.decide(10*time.Second, 0, 10*time.Second), WarmupWaiting)
+	assert.Equal(t, conf.decide(10*time.Second, 1, 10*time.Second), WarmupReady)
+	assert.Equal(t, conf.decide(time.Second, 0, 3*time.Minute), WarmupTimeout)
+}
+
+func Test_Miner_Warmup(t *testing.T) {
+	// disabled
+	miner := newWarmupMiner(false)
+	assert.Equal(t, miner.startWarmup(), false)
+	assert.Equal(t, miner.GetWarmupStatus().Decision, WarmupDisabled)
+
+	// waiting until the head is stable and the peers agree
+	miner = newWarmupMiner(true)
+	quit := make(chan struct{})
+	miner.warmupQuit, miner.warming = quit, 1
+	assert.Equal(t, miner.CanStart(), false)
+
+	now := time.Now()
+	assert.Equal(t, miner.updateWarmup(quit, now, 10, time.Second, 0, 2, time.Second), false)
+	status := miner.GetWarmupStatus()
+	assert.Equal(t, status.Decision, WarmupWaiting)
+	assert.Equal(t, status.HeadHeight, uint64(10))
+	assert.Equal(t, status.Peers, 2)
+	assert.Equal(t, miner.IsWarmingUp(), true)
+
+	assert.Equal(t, miner.updateWarmup(quit, now, 11, 6*time.Second, 1, 2, 7*time.Second), true)
+	status = miner.GetWarmupStatus()
+	assert.Equal(t, status.Decision, WarmupReady)
+	assert.Equal(t, status.End, now.Unix())
+	assert.Equal(t, status.AgreedPeers, 1)
+	assert.Equal(t, miner.IsWarmingUp(), false)
+	assert.Equal(t, miner.CanStart(), true)
+	assert.Equal(t, <-miner.msgChan, true)
+
+	// the canceled warmup doesn't start the miner
+	assert.Equal(t, miner.startWarmup(), true)
+	assert.Equal(t, miner.IsWarmingUp(), true)
+	quit = miner.warmupQuit
+	miner.cancelWarmup()
+	assert.Equal(t, miner.GetWarmupStatus().Decision, WarmupCanceled)
+	assert.Equal(t, miner.IsWarmingUp(), false)
+	assert.Equal(t, miner.updateWarmup(quit, now, 12, time.Minute, 1, 1, time.Minute), true)
+	assert.Equal(t, len(miner.msgChan), 0)
+}
@@ -0,0 +1,54 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+// PrivateAdminAPI provides an API to manage the node.
+type PrivateAdminAPI struct {
+	n *Node
+}
+
+// NewPrivateAdminAPI creates a new PrivateAdminAPI object for admin rpc service.
+func NewPrivateAdminAPI(n *Node) *PrivateAdminAPI {
+	return &PrivateAdminAPI{n}
+}
+
+// ReloadConfig reloads the config file and applies the changes of the non-consensus config without restart.
+func (api *PrivateAdminAPI) ReloadConfig() (bool, error) {
+	if err := api.n.ReloadConfig(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Health returns the health of the node, including the sync status, peers, database, miner and head age.
+func (api *PrivateAdminAPI) Health() *Health {
+	return api.n.Health()
+}
+
+// AddRPCListener starts a TCP RPC listener without restart, which serves only the public APIs if restricted.
+// It is dropped when the config is reloaded unless it is added into the config file too.
+func (api *PrivateAdminAPI) AddRPCListener(address string, restricted bool) (bool, error) {
+	if err := api.n.AddRPCListener(RPCListenerConfig{Address: address, Restricted: restricted}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RemoveRPCListener stops the TCP RPC listener of the address without restart.
+func (api *PrivateAdminAPI) RemoveRPCListener(address string) (bool, error) {
+	if err := api.n.RemoveRPCListener(address); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RPCListeners returns the running TCP RPC listeners.
+func (api *PrivateAdminAPI) RPCListeners() []RPCListenerConfig {
+	return api.n.RPCListeners()
+}
@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+)
+
+// PrivateP2PAPI provides an API to manage the block list of the p2p network.
+type PrivateP2PAPI struct {
+	n *Node
+}
+
+// NewPrivateP2PAPI creates a new PrivateP2PAPI object for p2p rpc service.
+func NewPrivateP2PAPI(n *Node) *PrivateP2PAPI {
+	return &PrivateP2PAPI{n}
+}
+
+// BlockIP blocks the IP or CIDR range for the duration, such as "30m" or "24h", and empty
+// duration means never expire. The connected peers in the range are disconnected.
+func (api *PrivateP2PAPI) BlockIP(cidr string, duration string) (*discovery.BlockedIP, error) {
+	var d time.Duration
+	if duration != "" {
+		var err error
+		if d, err = time.ParseDuration(duration); err != nil {
+			return nil, fmt.Errorf("invalid duration %q, %s", duration, err)
+		}
+
+		if d <= 0 {
+			return nil, fmt.Errorf("duration %q should be positive", duration)
+		}
+	}
+
+	if api.n.server == nil {
+		return nil, errors.New("p2p server is not started")
+	}
+
+	return api.n.server.BlockIP(cidr, d)
+}
+
+// UnblockIP removes the IP or CIDR range from the block list, returns false if it is not blocked.
+func (api *PrivateP2PAPI) UnblockIP(cidr string) (bool, error) {
+	blockList, err := api.blockList()
+	if err != nil {
+		return false, err
+	}
+
+	return blockList.Unblock(cidr)
+}
+
+// ListBlocked returns the blocked IPs and CIDR ranges, including the IPs blocked automatically.
+func (api *PrivateP2PAPI) ListBlocked() ([]*discovery.BlockedIP, error) {
+	blockList, err := api.blockList()
+	if err != nil {
+		return nil, err
+	}
+
+	return blockList.List(), nil
+}
+
+func (api *PrivateP2PAPI) blockList() (*discovery.BlockList, error) {
+	if api.n.server == nil || api.n.server.BlockList() == nil {
+		return nil, errors.New("p2p server is not started")
+	}
+
+	return api.n.server.BlockList(), nil
+}
+
+// TrafficStats returns the bytes and messages in and out by protocol message, of the server and
+// of each connected peer.
+func (api *PrivateP2PAPI) TrafficStats() (*p2p.TrafficStats, error) {
+	if api.n.server == nil {
+		return nil, errors.New("p2p server is not started")
+	}
+
+	return api.n.server.TrafficStats(), nil
+}
+
+// SubnetDistribution returns the number of the connected peers by subnet, except the peers of the
+// loopback and private addresses, to check the diversity of the peers against the eclipse attacks.
+func (api *PrivateP2PAPI) SubnetDistribution() (map[string]int, error) {
+	if api.n.server == nil {
+		return nil, errors.New("p2p server is not started")
+	}
+
+	return api.n.server.SubnetDistribution(), nil
+}
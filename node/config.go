@@ -7,12 +7,15 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"math/big"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/log/comm"
 	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
 	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/scdo/snapshot"
 )
 
 // Config is the Configuration of node
@@ -38,8 +41,71 @@ type Config struct {
 	// The configuration of ipc rpc service
 	IpcConfig IpcConfig
 
+	// The configuration of rpc execution limits
+	RPCConfig RPCConfig
+
+	// The configuration of the watchdog which detects stalled sync and mining
+	WatchdogConfig WatchdogConfig
+
+	// The configuration of the miner warmup after sync
+	MinerWarmupConfig MinerWarmupConfig
+
+	// The configuration of the miner preemption on a new head
+	MinerPreemptionConfig MinerPreemptionConfig
+
+	// The configuration of the light server which serves the light clients
+	LightServerConfig LightServerConfig
+
+	// The configuration of the webhooks which post the chain events
+	WebhookConfig WebhookConfig
+
+	// The configuration of the disk usage quota of the chain database
+	DiskQuotaConfig DiskQuotaConfig
+
+	// The configuration of the retention of the receipts and dirty accounts
+	RetentionConfig RetentionConfig
+
+	// The configuration of syncing the blocks from the trusted rpc endpoints
+	RPCSyncConfig RPCSyncConfig
+
+	// The configuration of the local dev network, used only in dev mode
+	DevConfig DevConfig
+
+	// The configuration of syncing the pending txs to the new peers
+	TxSyncConfig TxSyncConfig
+
+	// The configuration of the hashes known by each peer, which are not sent to the peer again
+	PeerKnownCacheConfig PeerKnownCacheConfig
+
+	// The configuration of mining the nonce ranges leased from the primary node
+	LeaseMiningConfig LeaseMiningConfig
+
+	// The configuration of the warm standby which follows the chain until promoted to active
+	FollowerConfig FollowerConfig
+
+	// The configuration of the scheduled backups of the chain and state databases
+	BackupConfig BackupConfig
+
+	// The configuration of customizing the txs of the block template of the miner
+	BlockTemplateConfig BlockTemplateConfig
+
+	// The configuration of the paths of the databases, which are under the data dir by default
+	DataDirsConfig DataDirsConfig
+
+	// The configuration of reporting the slow txs when the blocks are written
+	SlowTxConfig SlowTxConfig
+
+	// The configuration of the rpc endpoints of the nodes of other shards
+	ShardRPCConfig ShardRPCConfig
+
 	// metrics config info
 	MetricsConfig *metrics.Config
+
+	// tracing config info
+	TracingConfig *tracing.Config
+
+	// the configuration of bootstrapping from snapshot archive
+	SnapshotConfig *snapshot.Config
 }
 
 // IpcConfig config for ipc rpc service
@@ -47,6 +113,254 @@ type IpcConfig struct {
 	PipeName string `json:"name"`
 }
 
+// RPCConfig config for the execution limits of rpc calls, e.g. call and estimateGas
+type RPCConfig struct {
+	// EVMTimeout is the wall-clock timeout in milliseconds of an evm execution, 0 means the default 5 seconds
+	EVMTimeout int64 `json:"evmTimeout"`
+
+	// GasCap is the max gas limit of an evm execution, 0 means the default 25,000,000
+	GasCap uint64 `json:"gasCap"`
+}
+
+// WatchdogConfig config for the watchdog which detects stalled sync and mining and recovers them
+type WatchdogConfig struct {
+	// Enabled starts the watchdog with the node
+	Enabled bool `json:"enabled"`
+
+	// Interval is the check interval in seconds, 0 means the default 30 seconds
+	Interval int64 `json:"interval"`
+
+	// MaxHeadAge is the max age in seconds of the chain head before it is stalled, 0 means the default 10 minutes
+	MaxHeadAge int64 `json:"maxHeadAge"`
+
+	// MaxSyncDuration is the max duration in seconds of a sync session before it is stalled, 0 means the default 30 minutes
+	MaxSyncDuration int64 `json:"maxSyncDuration"`
+
+	// MinPeers is the min number of peers in the local shard, 0 means the default 1
+	MinPeers int `json:"minPeers"`
+}
+
+// MinerWarmupConfig config for the miner warmup after sync, which delays the start of the miner
+// until the head is stable and the peers agree with it, so that the miner doesn't mine on a stale head
+type MinerWarmupConfig struct {
+	// Enabled delays the start of the miner after sync, otherwise the miner starts immediately
+	Enabled bool `json:"enabled"`
+
+	// StableTime is the seconds without a new head before the miner starts, 0 means the default 5 seconds
+	StableTime int64 `json:"stableTime"`
+
+	// MinPeers is the min number of the peers in the local shard which are not ahead of the local head, 0 means the default 1
+	MinPeers int `json:"minPeers"`
+
+	// Timeout is the max seconds of the warmup, after which the miner starts anyway, 0 means the default 2 minutes
+	Timeout int64 `json:"timeout"`
+}
+
+// MinerPreemptionConfig config for the miner preemption, which aborts the task being sealed on a stale parent
+// once a new canonical head arrives, and prepares a new task on the new head
+type MinerPreemptionConfig struct {
+	// MinWorkTime is the min milliseconds of the work on a task before it is preempted, so that the miner doesn't
+	// thrash on the heads arriving in a burst, 0 means the default 1000 milliseconds, and negative disables the preemption
+	MinWorkTime int64 `json:"minWorkTime"`
+}
+
+// WebhookConfig config for the webhooks which post the chain events to the urls of the operators
+type WebhookConfig struct {
+	// Endpoints are the urls to post the chain events, no webhook if empty
+	Endpoints []WebhookEndpoint `json:"endpoints"`
+
+	// MaxRetries is the max retries of a failed delivery, 0 means the default 5
+	MaxRetries int `json:"maxRetries"`
+
+	// RetryInterval is the interval in seconds before the first retry, which is doubled by every retry, 0 means the default 1 second
+	RetryInterval int64 `json:"retryInterval"`
+
+	// Timeout is the timeout in seconds of a delivery, 0 means the default 10 seconds
+	Timeout int64 `json:"timeout"`
+}
+
+// WebhookEndpoint is an url to post the chain events
+type WebhookEndpoint struct {
+	URL string `json:"url"`
+
+	// Secret is the HMAC-SHA256 key to sign the body of the deliveries, which are not signed if empty
+	Secret string `json:"secret"`
+
+	// Events are the chain events posted to the url, empty means all events
+	Events []string `json:"events"`
+}
+
+// DiskQuotaConfig config for the disk usage quota of the chain database, which prunes the receipts and dirty
+// accounts of the oldest blocks when exceeded. The block headers and bodies are never pruned.
+type DiskQuotaConfig struct {
+	// MaxChainDBSize is the max disk usage in MB of the chain database, 0 means no quota
+	MaxChainDBSize uint64 `json:"maxChainDBSize"`
+
+	// KeepBlocks is the number of the most recent blocks which are never pruned, 0 means the default 100000, at least 1024
+	KeepBlocks uint64 `json:"keepBlocks"`
+
+	// Interval is the check interval in seconds, 0 means the default 10 minutes
+	Interval int64 `json:"interval"`
+}
+
+// RetentionConfig config for the retention of the receipts and dirty accounts, which are pruned in background
+// once older than the retention period, independently from the block headers and bodies which are kept.
+type RetentionConfig struct {
+	// ReceiptsDays is the number of days to keep the receipts, 0 means forever
+	ReceiptsDays uint64 `json:"receiptsDays"`
+
+	// DirtyAccountsDays is the number of days to keep the dirty accounts, 0 means forever
+	DirtyAccountsDays uint64 `json:"dirtyAccountsDays"`
+
+	// Interval is the check interval in seconds, 0 means the default 10 minutes
+	Interval int64 `json:"interval"`
+}
+
+// RPCSyncConfig config for syncing the blocks from the trusted rpc endpoints, for the nodes which cannot get
+// healthy p2p connectivity. The blocks are validated fully and written as the blocks synced from peers.
+type RPCSyncConfig struct {
+	// Endpoints are the rpc addresses of the trusted nodes in the local shard, tcp address like 127.0.0.1:8027
+	// or http, ws and ipc urls, no rpc sync if empty
+	Endpoints []string `json:"endpoints"`
+
+	// BatchSize is the number of blocks fetched in a request, 0 means the default and max 64
+	BatchSize uint `json:"batchSize"`
+
+	// Interval is the interval in seconds to check the HEAD of the trusted nodes, 0 means the default 10 seconds
+	Interval int64 `json:"interval"`
+}
+
+// ShardRPCConfig config for the rpc endpoints of the nodes of other shards, which serve the queries
+// across shards, e.g. the aggregate balance of the same key in all shards
+type ShardRPCConfig struct {
+	// Endpoints are the rpc addresses of the nodes of other shards, tcp address like 127.0.0.1:8027
+	// or http, ws and ipc urls. The light clients of other shards are used if empty
+	Endpoints []string `json:"endpoints"`
+}
+
+// DevConfig config for the local dev network, which seals a block instantly once there are txs in the pool
+type DevConfig struct {
+	// Accounts are the developer accounts pre-funded in the genesis, map key is the account address
+	// and value is the balance, which are merged into the genesis accounts in dev mode
+	Accounts map[common.Address]*big.Int `json:"accounts"`
+}
+
+// TxSyncConfig config for syncing the pending txs in the pool to the new peers of the local shard
+type TxSyncConfig struct {
+	// Enabled sends the pending txs to the new peers of the local shard after the handshake
+	Enabled bool `json:"enabled"`
+
+	// PackSize is the number of txs sent in a message, 0 means the default 1024
+	PackSize int `json:"packSize"`
+
+	// Rate is the max number of txs per second sent to a peer, 0 means no limit
+	Rate int `json:"rate"`
+}
+
+// SlowTxConfig config for reporting the txs whose execution is slow when the blocks are written
+type SlowTxConfig struct {
+	// Threshold is the execution time in milliseconds of a tx to be reported as slow,
+	// 0 means the default 100, and negative disables the report
+	Threshold int64 `json:"threshold"`
+
+	// ReportSize is the number of the recent slow txs kept in the report, 0 means the default 100
+	ReportSize int `json:"reportSize"`
+}
+
+// PeerKnownCacheConfig config for the LRU caches of the tx, block and debt hashes known by each peer,
+// which are skipped when broadcasting to the peer
+type PeerKnownCacheConfig struct {
+	// Txs is the max number of tx hashes known by a peer, 0 means the default 25000.
+	// It is used only if the peer has no slot in the seen txs shared with the tx pool.
+	Txs int `json:"txs"`
+
+	// Blocks is the max number of block hashes known by a peer, 0 means the default 250
+	Blocks int `json:"blocks"`
+
+	// Debts is the max number of debt hashes known by a peer, 0 means the default 10000
+	Debts int `json:"debts"`
+
+	// Expiry is the duration in seconds after which a known hash is forgotten, so that it could be sent
+	// to a long-lived peer again. 0 means the default 10 minutes, and negative never expires.
+	Expiry int64 `json:"expiry"`
+}
+
+// LeaseMiningConfig config for the distributed solo mining, in which the node works for the primary node of the
+// same operator, and mines the nonce ranges of the primary task leased over rpc instead of creating its own tasks
+type LeaseMiningConfig struct {
+	// Primary is the rpc address of the primary node in pool mode with access to the miner APIs,
+	// tcp address like 127.0.0.1:8027 or http, ws and ipc urls, no lease mining if empty
+	Primary string `json:"primary"`
+
+	// Worker is the name of the worker reported to the primary node, the node name if empty
+	Worker string `json:"worker"`
+
+	// LeaseSize is the number of nonces in a lease, 0 means the default 2^32
+	LeaseSize uint64 `json:"leaseSize"`
+}
+
+// FollowerConfig config for the warm standby of a mining node for the high availability. The follower syncs the
+// chain and the pending txs from the peers as usual, but doesn't mine or accept the txs submitted by rpc until it
+// is promoted to active by the admin_promote rpc, e.g. when the primary node fails.
+type FollowerConfig struct {
+	// Enabled starts the node as a follower
+	Enabled bool `json:"enabled"`
+}
+
+// BackupConfig config for the scheduled backups of the chain and state databases, which are consistent
+// point-in-time snapshots restored by the node restore command.
+type BackupConfig struct {
+	// Interval is the number of blocks between the backups, 0 means no backup
+	Interval uint64 `json:"interval"`
+
+	// Dir is the directory of the backups, the backup folder in the data dir if empty
+	Dir string `json:"dir"`
+
+	// Keep is the number of the most recent backups kept, the older ones are removed, 0 means the default 3
+	Keep int `json:"keep"`
+
+	// UploadHook is an optional command run with the backup path as the argument once a backup is created,
+	// e.g. a script to upload the backup to a remote storage
+	UploadHook string `json:"uploadHook"`
+}
+
+// BlockTemplateConfig config for customizing the txs of the block template of the miner, e.g. the tx ordering,
+// the exclusion lists or the reserved space for the priority txs. The txs are packed by price if neither is set.
+type BlockTemplateConfig struct {
+	// RuleFile is the JSON rule file of the block template, see miner.TemplateRules
+	RuleFile string `json:"ruleFile"`
+
+	// Plugin is the Go plugin which exports the BlockTemplateHook variable of type miner.BlockTemplateHook,
+	// which is used instead of the rule file if set
+	Plugin string `json:"plugin"`
+}
+
+// DataDirsConfig config for the paths of the databases, so that the operators could put the hot account
+// state on SSD and the cold blocks on cheap disks. The databases are under the data dir if the paths are
+// empty, and moved from the data dir to the configured paths once the node starts.
+type DataDirsConfig struct {
+	// ChainDB is the path of the blockchain database
+	ChainDB string `json:"chainDB"`
+
+	// AccountStateDB is the path of the account state database
+	AccountStateDB string `json:"accountStateDB"`
+
+	// DebtManagerDB is the path of the debt manager database
+	DebtManagerDB string `json:"debtManagerDB"`
+}
+
+// LightServerConfig config for the load management of the light server
+type LightServerConfig struct {
+	// MaxClients is the max number of the concurrent light clients, 0 means the default 100
+	MaxClients int `json:"maxClients"`
+
+	// BufferLimit is the max tokens of a light client to request in burst, 0 means the default 1000
+	BufferLimit uint64 `json:"bufferLimit"`
+
+	// RechargeRate is the tokens per second recharged to a light client, 0 means the default 50
+	RechargeRate uint64 `json:"rechargeRate"`
+}
+
 // BasicConfig config for Node
 type BasicConfig struct {
 	// The name of the node
@@ -61,6 +375,10 @@ type BasicConfig struct {
 	// RPCAddr is the address on which to start RPC server.
 	RPCAddr string `json:"address"`
 
+	// RPCListeners are the additional TCP RPC listeners, e.g. a full-access one on localhost and
+	// a restricted one on the public interface, which could be changed without restart
+	RPCListeners []RPCListenerConfig `json:"rpcListeners"`
+
 	// coinbase used by the miner
 	Coinbase string `json:"coinbase"`
 
@@ -69,6 +387,36 @@ type BasicConfig struct {
 
 	// MinerAlgorithm miner algorithm
 	MinerAlgorithm string `json:"algorithm"`
+
+	// SlowRPCThreshold is the duration in milliseconds above which RPC calls are logged as slow, 0 means disabled
+	SlowRPCThreshold int64 `json:"slowRPCThreshold"`
+
+	// MaxReorgDepth is the max depth of a chain reorg, deeper reorgs are refused, 0 means unlimited
+	MaxReorgDepth uint64 `json:"maxReorgDepth"`
+
+	// Cache is the memory in MB of the state trie node cache, 0 means the default 128 MB
+	Cache int `json:"cache"`
+
+	// DBEngine is the database engine, "leveldb" or "memory", empty means the default leveldb.
+	// The memory engine is for the ephemeral nodes, the data is lost once the node stops.
+	DBEngine string `json:"dbEngine"`
+
+	// StateMismatchReport dumps the account differences to a report file in the statemismatch folder
+	// of the data dir when the state root hash of a block mismatches, for the bug triage across node versions.
+	StateMismatchReport bool `json:"stateMismatchReport"`
+
+	// StateDiff computes and stores the state diff of every written block for debug_getStateDiff.
+	// Otherwise, the state diffs are only computed while there are importedBlocks or storageChanges subscriptions.
+	StateDiff bool `json:"stateDiff"`
+}
+
+// RPCListenerConfig config for a TCP RPC listener
+type RPCListenerConfig struct {
+	// Address is the address on which to listen, e.g. 127.0.0.1:8027
+	Address string `json:"address"`
+
+	// Restricted serves only the public APIs if true, otherwise all the APIs including admin, p2p and miner
+	Restricted bool `json:"restricted"`
 }
 
 // HTTPServer config for http server
@@ -97,15 +445,29 @@ type WSServerConfig struct {
 type ScdoConfig struct {
 	TxConf core.TransactionPoolConfig
 
+	DebtConf core.DebtPoolConfig
+
 	Coinbase common.Address
 
 	CoinbasePrivateKey *ecdsa.PrivateKey
 
 	CoinbaseList []common.Address
 
+	// PoolAccounts is the weight and the daily quota of the coinbases in CoinbaseList
+	PoolAccounts map[common.Address]*PoolAccount
+
 	GenesisConfig core.GenesisInfo
 }
 
+// PoolAccount is the weight and the daily quota of a coinbase in pool mode
+type PoolAccount struct {
+	// Weight is the relative weight to be selected as coinbase, 1 by default
+	Weight uint64 `json:"weight"`
+
+	// Quota is the max number of blocks mined per day, 0 means unlimited
+	Quota uint64 `json:"quota"`
+}
+
 func (conf *Config) Clone() *Config {
 	cloned := *conf
 	if conf.MetricsConfig != nil {
@@ -113,5 +475,15 @@ func (conf *Config) Clone() *Config {
 		cloned.MetricsConfig = &temp
 	}
 
+	if conf.SnapshotConfig != nil {
+		temp := *conf.SnapshotConfig
+		cloned.SnapshotConfig = &temp
+	}
+
+	if conf.TracingConfig != nil {
+		temp := *conf.TracingConfig
+		cloned.TracingConfig = &temp
+	}
+
 	return &cloned
 }
@@ -0,0 +1,103 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// paths of the HTTP health endpoints
+const (
+	HealthPath     = "/health"      // readiness, 200 if the node is ready to serve, otherwise 503
+	LivenessPath   = "/health/live" // liveness, 200 as long as the node serves HTTP requests
+	healthMimeType = "application/json"
+)
+
+// Health is the health of the node reported by the health endpoints
+type Health struct {
+	Ready  bool     // whether the node is ready to serve, e.g. the explorer traffic
+	Issues []string `json:",omitempty"` // why the node is not ready
+
+	Synced       bool
+	BlocksBehind uint64       // blocks behind the best peer of the local shard
+	Peers        map[uint]int // shard -> peer count
+	DBWritable   bool
+	MinerStatus  string `json:",omitempty"`
+	Follower     bool   `json:",omitempty"` // whether the node is a warm standby not promoted to active yet
+	LastBlockAge int64  // seconds since the HEAD block is created
+}
+
+// AddIssue adds an issue which makes the node not ready
+func (h *Health) AddIssue(format string, args ...interface{}) {
+	h.Issues = append(h.Issues, fmt.Sprintf(format, args...))
+}
+
+// HealthReporter is implemented by the services which report their health
+type HealthReporter interface {
+	ReportHealth(h *Health)
+}
+
+// Health returns the health of the node, which is ready if no issue is reported by the services.
+func (n *Node) Health() *Health {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	h := &Health{
+		Peers: make(map[uint]int),
+	}
+
+	if n.server != nil {
+		for _, info := range n.server.PeersInfo() {
+			h.Peers[info.Shard]++
+		}
+	}
+
+	for _, service := range n.services {
+		if reporter, ok := service.(HealthReporter); ok {
+			reporter.ReportHealth(h)
+		}
+	}
+
+	h.Ready = len(h.Issues) == 0
+
+	return h
+}
+
+// newHealthHandler serves the health endpoints, and the other requests by the next handler.
+// The health endpoints are served before the cors and vhosts checks, so that the probes
+// of load balancers could access them by the IP address.
+func (n *Node) newHealthHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, HealthPath) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.URL.Path {
+		case HealthPath:
+			h := n.Health()
+			status := http.StatusOK
+			if !h.Ready {
+				status = http.StatusServiceUnavailable
+			}
+
+			writeHealth(w, status, h)
+		case LivenessPath:
+			writeHealth(w, http.StatusOK, map[string]bool{"alive": true})
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func writeHealth(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("content-type", healthMimeType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,77 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testHealthService struct {
+	issue string
+}
+
+func (s *testHealthService) Protocols() []p2p.Protocol      { return nil }
+func (s *testHealthService) APIs() []rpc.API                { return nil }
+func (s *testHealthService) Start(server *p2p.Server) error { return nil }
+func (s *testHealthService) Stop() error                    { return nil }
+
+func (s *testHealthService) ReportHealth(h *Health) {
+	h.Synced = true
+	h.DBWritable = true
+	if len(s.issue) > 0 {
+		h.AddIssue("%s", s.issue)
+	}
+}
+
+func getHealth(t *testing.T, handler http.Handler, path string) (int, *Health) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var h Health
+	assert.Equal(t, json.Unmarshal(recorder.Body.Bytes(), &h), nil)
+
+	return recorder.Code, &h
+}
+
+func Test_Node_HealthHandler(t *testing.T) {
+	service := &testHealthService{}
+	n := &Node{services: []Service{service}}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := n.newHealthHandler(next)
+
+	// ready
+	code, h := getHealth(t, handler, HealthPath)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, h.Ready, true)
+	assert.Equal(t, h.Synced, true)
+
+	// not ready
+	service.issue = "not synced"
+	code, h = getHealth(t, handler, HealthPath)
+	assert.Equal(t, code, http.StatusServiceUnavailable)
+	assert.Equal(t, h.Ready, false)
+	assert.Equal(t, h.Issues, []string{"not synced"})
+
+	// alive even if not ready
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+	assert.Equal(t, recorder.Code, http.StatusOK)
+
+	// rpc requests
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, recorder.Code, http.StatusTeapot)
+}
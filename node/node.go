@@ -27,6 +27,9 @@ var (
 	ErrNodeStopped        = errors.New("node is not started")
 	ErrServiceStartFailed = errors.New("failed to start node service")
 	ErrServiceStopFailed  = errors.New("failed to stop node service")
+
+	ErrConfigLoaderIsNull  = errors.New("config loader is null")
+	ErrConfigNotReloadable = errors.New("consensus config can not be reloaded")
 )
 
 // StopError represents an error which is returned when a node fails to stop any registered service
@@ -49,8 +52,8 @@ type Node struct {
 	log  *log.ScdoLog
 	lock sync.RWMutex
 
-	tcpListener net.Listener // TCP RPC listener socket to serve API requests
-	tcpHandler  *rpc.Server  // TCP RPC request handler to process the API requests
+	rpcAPIs      []rpc.API               // APIs served by the RPC endpoints
+	rpcListeners map[string]*rpcListener // TCP RPC listeners by address
 
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
 	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
@@ -64,6 +67,8 @@ type Node struct {
 	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
 
 	shard uint
+
+	configLoader ConfigLoader // loads the config to reload
 }
 
 // New creates a new P2P node.
@@ -0,0 +1,143 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics"
+	rpc "github.com/scdoproject/go-scdo/rpc"
+)
+
+// ConfigLoader loads the node config, e.g. from the config file
+type ConfigLoader func() (*Config, error)
+
+// ConfigReloader is implemented by the services which apply the reloaded config without restart
+type ConfigReloader interface {
+	ReloadConfig(conf *Config) error
+}
+
+// SetConfigLoader sets the loader used to reload the config
+func (n *Node) SetConfigLoader(loader ConfigLoader) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.configLoader = loader
+}
+
+// ReloadConfig reloads the config by the config loader, and applies the changes of the non-consensus config,
+// including the log level, rpc limits, rpc listeners, tx pool capacity, max peers and metrics, without restart.
+// The changes of the consensus and genesis config are rejected.
+func (n *Node) ReloadConfig() error {
+	n.lock.RLock()
+	loader := n.configLoader
+	n.lock.RUnlock()
+
+	if loader == nil {
+		return ErrConfigLoaderIsNull
+	}
+
+	conf, err := loader()
+	if err != nil {
+		return errors.NewStackedError(err, "failed to load config")
+	}
+
+	return n.ApplyConfig(conf)
+}
+
+// ApplyConfig applies the changes of the non-consensus config without restart.
+func (n *Node) ApplyConfig(conf *Config) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err := checkConfigReloadable(n.config, conf); err != nil {
+		return err
+	}
+
+	// log level
+	log.SetDebug(conf.LogConfig.IsDebug)
+	n.config.LogConfig.IsDebug = conf.LogConfig.IsDebug
+
+	// slow rpc threshold
+	threshold := time.Duration(conf.BasicConfig.SlowRPCThreshold) * time.Millisecond
+	n.config.BasicConfig.SlowRPCThreshold = conf.BasicConfig.SlowRPCThreshold
+	for _, handler := range []*rpc.Server{n.ipcHandler, n.httpHandler, n.wsHandler} {
+		if handler != nil {
+			handler.SetSlowCallThreshold(threshold)
+		}
+	}
+	for _, l := range n.rpcListeners {
+		l.handler.SetSlowCallThreshold(threshold)
+	}
+
+	// rpc listeners
+	if err := n.reloadRPCListeners(&conf.BasicConfig); err != nil {
+		return errors.NewStackedError(err, "failed to reload rpc listeners")
+	}
+	n.config.BasicConfig.RPCAddr = conf.BasicConfig.RPCAddr
+	n.config.BasicConfig.RPCListeners = conf.BasicConfig.RPCListeners
+
+	// max peers
+	if n.server != nil {
+		if conf.P2PConfig.MaxConnections > 0 {
+			n.server.SetMaxConnections(conf.P2PConfig.MaxConnections)
+		}
+
+		if conf.P2PConfig.MaxActiveConnections > 0 {
+			n.server.SetMaxActiveConnections(conf.P2PConfig.MaxActiveConnections)
+		}
+	}
+	n.config.P2PConfig.MaxConnections = conf.P2PConfig.MaxConnections
+	n.config.P2PConfig.MaxActiveConnections = conf.P2PConfig.MaxActiveConnections
+
+	// metrics
+	if !reflect.DeepEqual(n.config.MetricsConfig, conf.MetricsConfig) {
+		metrics.ReloadMetricsWithConfig(conf.MetricsConfig, n.log, conf.BasicConfig.Name, conf.BasicConfig.Version,
+			conf.P2PConfig.NetworkID, conf.ScdoConfig.Coinbase)
+		n.config.MetricsConfig = conf.MetricsConfig
+	}
+
+	// rpc limits, tx pool capacity, min debt price and debt expiry applied by services
+	for _, service := range n.services {
+		if reloader, ok := service.(ConfigReloader); ok {
+			if err := reloader.ReloadConfig(conf); err != nil {
+				return errors.NewStackedErrorf(err, "failed to reload config of service %v", reflect.TypeOf(service))
+			}
+		}
+	}
+	n.config.RPCConfig = conf.RPCConfig
+	n.config.ScdoConfig.TxConf = conf.ScdoConfig.TxConf
+	n.config.ScdoConfig.DebtConf = conf.ScdoConfig.DebtConf
+
+	n.log.Info("config reloaded")
+
+	return nil
+}
+
+// checkConfigReloadable returns an error if any consensus or genesis config is changed
+func checkConfigReloadable(current, conf *Config) error {
+	changed := ""
+
+	switch {
+	case current.ScdoConfig.GenesisConfig.Hash() != conf.ScdoConfig.GenesisConfig.Hash():
+		changed = "genesis"
+	case current.P2PConfig.NetworkID != conf.P2PConfig.NetworkID:
+		changed = "networkID"
+	case current.BasicConfig.MinerAlgorithm != conf.BasicConfig.MinerAlgorithm:
+		changed = "algorithm"
+	case !current.ScdoConfig.Coinbase.Equal(conf.ScdoConfig.Coinbase):
+		changed = "coinbase"
+	case current.BasicConfig.PrivateKey != conf.BasicConfig.PrivateKey:
+		changed = "privateKey"
+	default:
+		return nil
+	}
+
+	return errors.NewStackedErrorf(ErrConfigNotReloadable, "%s is changed", changed)
+}
@@ -0,0 +1,76 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/log/comm"
+	"github.com/stretchr/testify/assert"
+)
+
+// testReloadService is a test implementation of the Service and ConfigReloader interface.
+type testReloadService struct {
+	TestServiceA
+	conf *Config
+}
+
+func (s *testReloadService) ReloadConfig(conf *Config) error {
+	s.conf = conf
+	return nil
+}
+
+func Test_Node_ApplyConfig(t *testing.T) {
+	service := &testReloadService{}
+	n := &Node{
+		config:   testNodeConfig(),
+		services: []Service{service},
+		log:      log.GetLogger("node"),
+	}
+	defer log.SetDebug(true)
+
+	// consensus config changed
+	conf := testNodeConfig()
+	conf.P2PConfig.NetworkID = "changed"
+	err := n.ApplyConfig(conf)
+	assert.Equal(t, errors.IsOrContains(err, ErrConfigNotReloadable), true)
+	assert.Equal(t, service.conf, (*Config)(nil))
+
+	// non-consensus config changed
+	conf = testNodeConfig()
+	conf.LogConfig.IsDebug = false
+	conf.BasicConfig.SlowRPCThreshold = 100
+	conf.RPCConfig.GasCap = 1000
+	conf.ScdoConfig.TxConf.Capacity = 10
+	conf.P2PConfig.MaxConnections = 20
+	assert.Equal(t, n.ApplyConfig(conf), nil)
+	assert.Equal(t, service.conf, conf)
+	assert.Equal(t, comm.LogConfiguration.IsDebug, false)
+	assert.Equal(t, n.config.BasicConfig.SlowRPCThreshold, int64(100))
+	assert.Equal(t, n.config.RPCConfig.GasCap, uint64(1000))
+	assert.Equal(t, n.config.ScdoConfig.TxConf.Capacity, 10)
+	assert.Equal(t, n.config.P2PConfig.MaxConnections, 20)
+}
+
+func Test_Node_ReloadConfig(t *testing.T) {
+	n := &Node{
+		config: testNodeConfig(),
+		log:    log.GetLogger("node"),
+	}
+	defer log.SetDebug(true)
+
+	assert.Equal(t, n.ReloadConfig(), ErrConfigLoaderIsNull)
+
+	n.SetConfigLoader(func() (*Config, error) {
+		conf := testNodeConfig()
+		conf.LogConfig.IsDebug = false
+		return conf, nil
+	})
+	assert.Equal(t, n.ReloadConfig(), nil)
+	assert.Equal(t, n.config.LogConfig.IsDebug, false)
+}
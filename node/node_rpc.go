@@ -8,6 +8,7 @@ package node
 import (
 	"net"
 	"strings"
+	"time"
 
 	rpc "github.com/scdoproject/go-scdo/rpc"
 )
@@ -17,7 +18,20 @@ import (
 // assumptions about the state of the node.
 func (n *Node) startRPC(services []Service) error {
 	// Gather all the possible APIs to surface
-	apis := []rpc.API{}
+	apis := []rpc.API{
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewPrivateAdminAPI(n),
+			Public:    false,
+		},
+		{
+			Namespace: "p2p",
+			Version:   "1.0",
+			Service:   NewPrivateP2PAPI(n),
+			Public:    false,
+		},
+	}
 	for _, service := range services {
 		apis = append(apis, service.APIs()...)
 	}
@@ -46,65 +60,25 @@ func (n *Node) startRPC(services []Service) error {
 	return nil
 }
 
-// startTCP initializes and starts the TCP RPC endpoint.
+// newRPCServer creates an RPC server with the configured slow call threshold.
+func (n *Node) newRPCServer() *rpc.Server {
+	handler := rpc.NewServer()
+	handler.SetSlowCallThreshold(time.Duration(n.config.BasicConfig.SlowRPCThreshold) * time.Millisecond)
+
+	return handler
+}
+
+// startTCP initializes and starts the TCP RPC listeners.
 func (n *Node) startTCP(apis []rpc.API) error {
-	endpoint := n.config.BasicConfig.RPCAddr
-	// Short circuit if the TCP endpoint isn't being exposed
-	if endpoint == "" {
-		return nil
-	}
+	n.rpcAPIs = apis
+	n.rpcListeners = make(map[string]*rpcListener)
 
-	// Register all the APIs exposed by the services
-	handler := rpc.NewServer()
-	for _, api := range apis {
-		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+	for _, conf := range rpcListenerConfigs(&n.config.BasicConfig) {
+		if err := n.startRPCListener(conf); err != nil {
 			return err
 		}
-		n.log.Debug("registered RPC service namespace %s", api.Namespace)
 	}
 
-	// All APIs registered, start the TCP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
-		return err
-	}
-
-	go func() {
-		n.log.Info("RPC opened at address %s", endpoint)
-
-		for {
-			conn, err := listener.Accept()
-			if err != nil {
-				// Terminate if the listener was closed
-				n.lock.RLock()
-				closed := n.tcpListener == nil
-				n.lock.RUnlock()
-				if conn != nil {
-					conn.Close() // Need to close this fault connect. Still have a chance to accept it later (tested)
-				}
-				if closed {
-					return
-				}
-				// Not closed, just some error; report and continue
-				n.log.Error("failed to accept RPC. err %s", err)
-				continue
-			}
-			n.log.Debug("RPC call from %v", conn)
-			connStr := conn.RemoteAddr().String()
-			if !strings.HasPrefix(connStr, "127.0.0.1") && !strings.HasPrefix(connStr, "localhost") {
-				handler.ChangeMinerRequestStatus()
-			}
-			go handler.ServeCodec(rpc.NewJSONCodec(conn), rpc.OptionMethodInvocation|rpc.OptionSubscriptions)
-		}
-	}()
-
-	// All listeners booted successfully
-	n.tcpListener = listener
-	n.tcpHandler = handler
-
 	return nil
 }
 
@@ -131,7 +105,7 @@ func (n *Node) startIPC(apis []rpc.API) error {
 // StartIPCEndpoint starts an IPC endpoint.
 func (n *Node) startIPCEndpoint(ipcEndpoint string, apis []rpc.API) (net.Listener, *rpc.Server, error) {
 	// Register all the APIs exposed by the services.
-	handler := rpc.NewServer()
+	handler := n.newRPCServer()
 	for _, api := range apis {
 		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
 			return nil, nil, err
@@ -158,16 +132,11 @@ func (n *Node) stopRPC() {
 }
 
 func (n *Node) stopTCP() {
-	if n.tcpListener != nil {
-		n.tcpListener.Close()
-		n.tcpListener = nil
-
-		n.log.Info("TCP closed")
-	}
-	if n.tcpHandler != nil {
-		n.tcpHandler.Stop()
-		n.tcpHandler = nil
+	for address, l := range n.rpcListeners {
+		l.stop()
+		n.log.Info("TCP closed. address %s", address)
 	}
+	n.rpcListeners = nil
 }
 
 func (n *Node) stopIPC() {
@@ -195,7 +164,7 @@ func (n *Node) startHTTP(apis []rpc.API) error {
 	}
 
 	// Register all the APIs exposed by the services
-	handler := rpc.NewServer()
+	handler := n.newRPCServer()
 	for _, api := range apis {
 		if api.Public {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -214,7 +183,9 @@ func (n *Node) startHTTP(apis []rpc.API) error {
 		return err
 	}
 
-	go rpc.NewHTTPServer(cors, vhosts, handler).Serve(listener)
+	server := rpc.NewHTTPServer(cors, vhosts, handler)
+	server.Handler = n.newHealthHandler(server.Handler)
+	go server.Serve(listener)
 	n.log.Info("HTTP endpoint opened. url http://%s, cors %s, whitehost %s", endpoint, strings.Join(cors, ","), strings.Join(vhosts, ","))
 
 	// All listeners booted successfully
@@ -250,7 +221,7 @@ func (n *Node) startWS(apis []rpc.API) error {
 	}
 
 	// Register all the APIs exposed by the services
-	handler := rpc.NewServer()
+	handler := n.newRPCServer()
 	for _, api := range apis {
 		if api.Public {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -0,0 +1,201 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	rpc "github.com/scdoproject/go-scdo/rpc"
+)
+
+// error infos of the rpc listeners
+var (
+	ErrRPCNotStarted          = errors.New("rpc is not started")
+	ErrRPCListenerExists      = errors.New("rpc listener already exists")
+	ErrRPCListenerNotFound    = errors.New("rpc listener not found")
+	ErrRPCListenerAddressNull = errors.New("rpc listener address is empty")
+)
+
+// rpcListener is a TCP RPC listener, which serves all the APIs, or only the public APIs if restricted.
+type rpcListener struct {
+	config   RPCListenerConfig
+	listener net.Listener
+	handler  *rpc.Server
+	closed   int32
+}
+
+func (l *rpcListener) stop() {
+	atomic.StoreInt32(&l.closed, 1)
+	l.listener.Close()
+	l.handler.Stop()
+}
+
+// rpcListenerConfigs returns the TCP RPC listeners of the config, the RPCAddr is a full-access listener.
+// The listeners of the same address are merged, and the first one wins.
+func rpcListenerConfigs(conf *BasicConfig) []RPCListenerConfig {
+	var confs []RPCListenerConfig
+	if conf.RPCAddr != "" {
+		confs = append(confs, RPCListenerConfig{Address: conf.RPCAddr})
+	}
+
+	seen := make(map[string]bool)
+	var result []RPCListenerConfig
+	for _, c := range append(confs, conf.RPCListeners...) {
+		if c.Address != "" && !seen[c.Address] {
+			seen[c.Address] = true
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+// startRPCListener starts a TCP RPC listener. It is called with the node lock held.
+func (n *Node) startRPCListener(conf RPCListenerConfig) error {
+	if conf.Address == "" {
+		return ErrRPCListenerAddressNull
+	}
+
+	if _, ok := n.rpcListeners[conf.Address]; ok {
+		return ErrRPCListenerExists
+	}
+
+	// Register the APIs exposed by the services, only the public ones if restricted
+	handler := n.newRPCServer()
+	for _, api := range n.rpcAPIs {
+		if conf.Restricted && !api.Public {
+			continue
+		}
+
+		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+			return err
+		}
+		n.log.Debug("registered RPC service namespace %s at address %s", api.Namespace, conf.Address)
+	}
+
+	listener, err := net.Listen("tcp", conf.Address)
+	if err != nil {
+		return err
+	}
+
+	l := &rpcListener{conf, listener, handler, 0}
+	go n.serveRPCListener(l)
+
+	n.rpcListeners[conf.Address] = l
+	n.log.Info("RPC opened at address %s, restricted %t", conf.Address, conf.Restricted)
+
+	return nil
+}
+
+func (n *Node) serveRPCListener(l *rpcListener) {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if conn != nil {
+				conn.Close() // Need to close this fault connect. Still have a chance to accept it later (tested)
+			}
+			// Terminate if the listener was closed
+			if atomic.LoadInt32(&l.closed) == 1 {
+				return
+			}
+			// Not closed, just some error; report and continue
+			n.log.Error("failed to accept RPC. err %s", err)
+			continue
+		}
+		n.log.Debug("RPC call from %v", conn)
+		connStr := conn.RemoteAddr().String()
+		if !strings.HasPrefix(connStr, "127.0.0.1") && !strings.HasPrefix(connStr, "localhost") {
+			l.handler.ChangeMinerRequestStatus()
+		}
+		go l.handler.ServeCodec(rpc.NewJSONCodec(conn), rpc.OptionMethodInvocation|rpc.OptionSubscriptions)
+	}
+}
+
+// stopRPCListener stops the TCP RPC listener of the address. It is called with the node lock held.
+func (n *Node) stopRPCListener(address string) error {
+	l, ok := n.rpcListeners[address]
+	if !ok {
+		return ErrRPCListenerNotFound
+	}
+
+	delete(n.rpcListeners, address)
+	l.stop()
+	n.log.Info("RPC closed at address %s", address)
+
+	return nil
+}
+
+// reloadRPCListeners starts the new listeners of the config and stops the removed ones, the listener
+// whose access is changed is restarted. The other listeners keep serving. It is called with the node
+// lock held, and does nothing if the rpc is not started.
+func (n *Node) reloadRPCListeners(conf *BasicConfig) error {
+	if n.rpcListeners == nil {
+		return nil
+	}
+
+	desired := make(map[string]RPCListenerConfig)
+	for _, c := range rpcListenerConfigs(conf) {
+		desired[c.Address] = c
+	}
+
+	for address, l := range n.rpcListeners {
+		if c, ok := desired[address]; !ok || c != l.config {
+			n.stopRPCListener(address)
+		}
+	}
+
+	for _, c := range rpcListenerConfigs(conf) {
+		if _, ok := n.rpcListeners[c.Address]; !ok {
+			if err := n.startRPCListener(c); err != nil {
+				return fmt.Errorf("failed to start rpc listener %s, %s", c.Address, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// AddRPCListener starts a TCP RPC listener without restart. It is not persisted, and is
+// dropped when the config is reloaded unless it is added into the config file too.
+func (n *Node) AddRPCListener(conf RPCListenerConfig) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.rpcListeners == nil {
+		return ErrRPCNotStarted
+	}
+
+	return n.startRPCListener(conf)
+}
+
+// RemoveRPCListener stops the TCP RPC listener of the address without restart, the connections
+// of the listener are closed.
+func (n *Node) RemoveRPCListener(address string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.stopRPCListener(address)
+}
+
+// RPCListeners returns the running TCP RPC listeners
+func (n *Node) RPCListeners() []RPCListenerConfig {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	confs := make([]RPCListenerConfig, 0, len(n.rpcListeners))
+	for _, l := range n.rpcListeners {
+		confs = append(confs, l.config)
+	}
+
+	sort.Slice(confs, func(i, j int) bool { return confs[i].Address < confs[j].Address })
+
+	return confs
+}
@@ -0,0 +1,85 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package node
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/log"
+	rpc "github.com/scdoproject/go-scdo/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func rpcModules(t *testing.T, address string) map[string]string {
+	client, err := rpc.DialTCP(context.Background(), address)
+	assert.Equal(t, err, nil)
+	defer client.Close()
+
+	var modules map[string]string
+	if err = client.Call(&modules, "rpc_modules"); err != nil {
+		return nil
+	}
+
+	return modules
+}
+
+func Test_Node_RPCListeners(t *testing.T) {
+	conf := testNodeConfig()
+	conf.BasicConfig.RPCAddr = "127.0.0.1:28027"
+	conf.BasicConfig.RPCListeners = []RPCListenerConfig{
+		{Address: "127.0.0.1:28028", Restricted: true},
+		{Address: "127.0.0.1:28027", Restricted: true},
+	}
+
+	n := &Node{config: conf, log: log.GetLogger("node")}
+	apis := []rpc.API{
+		{Namespace: "admin", Version: "1.0", Service: NewPrivateAdminAPI(n), Public: false},
+		{Namespace: "monitor", Version: "1.0", Service: testServiceA, Public: true},
+	}
+
+	assert.Equal(t, n.AddRPCListener(RPCListenerConfig{Address: "127.0.0.1:28029"}), ErrRPCNotStarted)
+	assert.Equal(t, n.startTCP(apis), nil)
+	defer n.stopTCP()
+
+	// the RPCAddr is a full-access listener, and wins the listener of the same address
+	assert.Equal(t, n.RPCListeners(), []RPCListenerConfig{
+		{Address: "127.0.0.1:28027"},
+		{Address: "127.0.0.1:28028", Restricted: true},
+	})
+	assert.Equal(t, rpcModules(t, "127.0.0.1:28027"), map[string]string{"rpc": "1.0", "admin": "1.0", "monitor": "1.0"})
+	assert.Equal(t, rpcModules(t, "127.0.0.1:28028"), map[string]string{"rpc": "1.0", "monitor": "1.0"})
+
+	// add and remove without restart
+	assert.Equal(t, n.AddRPCListener(RPCListenerConfig{Address: "127.0.0.1:28029"}), nil)
+	assert.Equal(t, n.AddRPCListener(RPCListenerConfig{Address: "127.0.0.1:28029"}), ErrRPCListenerExists)
+	assert.Equal(t, n.AddRPCListener(RPCListenerConfig{}), ErrRPCListenerAddressNull)
+	assert.Equal(t, rpcModules(t, "127.0.0.1:28029")["admin"], "1.0")
+
+	assert.Equal(t, n.RemoveRPCListener("127.0.0.1:28028"), nil)
+	assert.Equal(t, n.RemoveRPCListener("127.0.0.1:28028"), ErrRPCListenerNotFound)
+	_, err := rpc.DialTCP(context.Background(), "127.0.0.1:28028")
+	assert.Equal(t, err != nil, true)
+
+	// reload keeps the unchanged listener, restarts the changed one and stops the removed ones
+	kept := n.rpcListeners["127.0.0.1:28027"]
+	conf = testNodeConfig()
+	conf.BasicConfig.RPCAddr = "127.0.0.1:28027"
+	conf.BasicConfig.RPCListeners = []RPCListenerConfig{{Address: "127.0.0.1:28028"}}
+	assert.Equal(t, n.ApplyConfig(conf), nil)
+	assert.Equal(t, n.rpcListeners["127.0.0.1:28027"] == kept, true)
+	assert.Equal(t, n.RPCListeners(), []RPCListenerConfig{{Address: "127.0.0.1:28027"}, {Address: "127.0.0.1:28028"}})
+	assert.Equal(t, rpcModules(t, "127.0.0.1:28028")["admin"], "1.0")
+	assert.Equal(t, n.config.BasicConfig.RPCListeners, conf.BasicConfig.RPCListeners)
+
+	conf.BasicConfig.RPCListeners[0].Restricted = true
+	assert.Equal(t, n.ApplyConfig(conf), nil)
+	assert.Equal(t, rpcModules(t, "127.0.0.1:28028"), map[string]string{"rpc": "1.0", "monitor": "1.0"})
+
+	conf.BasicConfig.RPCAddr = ""
+	assert.Equal(t, n.ApplyConfig(conf), nil)
+	assert.Equal(t, n.RPCListeners(), []RPCListenerConfig{{Address: "127.0.0.1:28028", Restricted: true}})
+}
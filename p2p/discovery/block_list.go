@@ -0,0 +1,234 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlockedIP is an IP or CIDR range in the block list
+type BlockedIP struct {
+	CIDR   string
+	Manual bool  // blocked by the admin, otherwise blocked automatically for misbehavior
+	Until  int64 // unix time when the block expires, 0 means never
+}
+
+type blockEntry struct {
+	BlockedIP
+	ipNet *net.IPNet
+}
+
+// BlockList is the list of the blocked IPs and CIDR ranges, which are rejected by both
+// the UDP discovery and the TCP connections. The automatically blocked IPs expire in
+// blockDuration after their last message, and the manually blocked ranges expire at
+// the given time.
+type BlockList struct {
+	lock    sync.RWMutex
+	entries map[string]*blockEntry // CIDR -> entry
+}
+
+// NewBlockList creates an empty block list
+func NewBlockList() *BlockList {
+	return &BlockList{
+		entries: make(map[string]*blockEntry),
+	}
+}
+
+// ParseCIDR parses an IP or CIDR range, and an IP is parsed as a single address range.
+func ParseCIDR(cidr string) (*net.IPNet, error) {
+	if !strings.Contains(cidr, "/") {
+		ip := net.ParseIP(cidr)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP %q", cidr)
+		}
+
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q, %s", cidr, err)
+	}
+
+	return ipNet, nil
+}
+
+// Block blocks the IP or CIDR range manually for the duration, 0 means never expire.
+func (b *BlockList) Block(cidr string, duration time.Duration, now time.Time) (*BlockedIP, error) {
+	ipNet, err := ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &blockEntry{BlockedIP{CIDR: ipNet.String(), Manual: true}, ipNet}
+	if duration > 0 {
+		entry.Until = now.Add(duration).Unix()
+	}
+
+	b.lock.Lock()
+	b.entries[entry.CIDR] = entry
+	b.lock.Unlock()
+
+	blocked := entry.BlockedIP
+	return &blocked, nil
+}
+
+// Unblock removes the IP or CIDR range from the block list, returns false if it is not blocked.
+func (b *BlockList) Unblock(cidr string) (bool, error) {
+	ipNet, err := ParseCIDR(cidr)
+	if err != nil {
+		return false, err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.entries[ipNet.String()]; !ok {
+		return false, nil
+	}
+
+	delete(b.entries, ipNet.String())
+
+	return true, nil
+}
+
+// blockAuto blocks the IP automatically for blockDuration, the manual block of the same IP is kept.
+func (b *BlockList) blockAuto(ip net.IP, now time.Time) {
+	ipNet, err := ParseCIDR(ip.String())
+	if err != nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if entry, ok := b.entries[ipNet.String()]; ok && entry.Manual {
+		return
+	}
+
+	b.entries[ipNet.String()] = &blockEntry{BlockedIP{CIDR: ipNet.String(), Until: now.Add(blockDuration).Unix()}, ipNet}
+}
+
+// Has returns whether the IP is in any unexpired IP or CIDR range of the block list
+func (b *BlockList) Has(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	now := time.Now().Unix()
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for _, entry := range b.entries {
+		if (entry.Until == 0 || entry.Until > now) && entry.ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// seen extends the automatic block of the IP which still sends messages
+func (b *BlockList) seen(ip net.IP, now time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, entry := range b.entries {
+		if !entry.Manual && entry.ipNet.Contains(ip) {
+			entry.Until = now.Add(blockDuration).Unix()
+		}
+	}
+}
+
+// removeExpired removes the expired entries, and returns the removed CIDRs
+func (b *BlockList) removeExpired(now time.Time) []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var removed []string
+	for cidr, entry := range b.entries {
+		if entry.Until != 0 && entry.Until <= now.Unix() {
+			delete(b.entries, cidr)
+			removed = append(removed, cidr)
+		}
+	}
+
+	return removed
+}
+
+// List returns the entries of the block list sorted by CIDR
+func (b *BlockList) List() []*BlockedIP {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	list := make([]*BlockedIP, 0, len(b.entries))
+	for _, entry := range b.entries {
+		blocked := entry.BlockedIP
+		list = append(list, &blocked)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].CIDR < list[j].CIDR })
+
+	return list
+}
+
+// Count returns the number of the entries
+func (b *BlockList) Count() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return len(b.entries)
+}
+
+// MarshalJSON encodes the entries of the block list
+func (b *BlockList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.List())
+}
+
+// UnmarshalJSON decodes the entries of the block list, or the IPs and their last message
+// time of the legacy backup file.
+func (b *BlockList) UnmarshalJSON(data []byte) error {
+	var list []*BlockedIP
+	if err := json.Unmarshal(data, &list); err != nil {
+		var legacy map[string]int64
+		if json.Unmarshal(data, &legacy) != nil {
+			return err
+		}
+
+		for ip, lastSeen := range legacy {
+			list = append(list, &BlockedIP{CIDR: ip, Until: time.Unix(lastSeen, 0).Add(blockDuration).Unix()})
+		}
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.entries == nil {
+		b.entries = make(map[string]*blockEntry)
+	}
+
+	for _, blocked := range list {
+		ipNet, err := ParseCIDR(blocked.CIDR)
+		if err != nil {
+			return err
+		}
+
+		b.entries[ipNet.String()] = &blockEntry{BlockedIP{ipNet.String(), blocked.Manual, blocked.Until}, ipNet}
+	}
+
+	return nil
+}
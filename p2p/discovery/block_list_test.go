@@ -0,0 +1,103 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BlockList_CIDR(t *testing.T) {
+	b := NewBlockList()
+	now := time.Now()
+
+	blocked, err := b.Block("10.0.0.7/24", 0, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, blocked, &BlockedIP{CIDR: "10.0.0.0/24", Manual: true})
+
+	assert.Equal(t, b.Has(net.ParseIP("10.0.0.1")), true)
+	assert.Equal(t, b.Has(net.ParseIP("10.0.0.255")), true)
+	assert.Equal(t, b.Has(net.ParseIP("10.0.1.1")), false)
+
+	// single IP
+	blocked, err = b.Block("192.168.1.1", time.Hour, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, blocked, &BlockedIP{CIDR: "192.168.1.1/32", Manual: true, Until: now.Add(time.Hour).Unix()})
+	assert.Equal(t, b.Has(net.ParseIP("192.168.1.1")), true)
+	assert.Equal(t, b.Has(net.ParseIP("192.168.1.2")), false)
+
+	_, err = b.Block("10.0.0.300", 0, now)
+	assert.Equal(t, err != nil, true)
+	_, err = b.Block("10.0.0.0/33", 0, now)
+	assert.Equal(t, err != nil, true)
+
+	assert.Equal(t, b.List(), []*BlockedIP{
+		{CIDR: "10.0.0.0/24", Manual: true},
+		{CIDR: "192.168.1.1/32", Manual: true, Until: now.Add(time.Hour).Unix()},
+	})
+
+	// unblock
+	ok, err := b.Unblock("10.0.0.0/24")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, b.Has(net.ParseIP("10.0.0.1")), false)
+
+	ok, err = b.Unblock("10.0.0.0/24")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ok, false)
+
+	// expired
+	assert.Equal(t, b.removeExpired(now.Add(2*time.Hour)), []string{"192.168.1.1/32"})
+	assert.Equal(t, b.Count(), 0)
+}
+
+func Test_BlockList_Auto(t *testing.T) {
+	b := NewBlockList()
+	now := time.Now()
+	ip := net.ParseIP("10.0.0.1")
+
+	b.blockAuto(ip, now)
+	assert.Equal(t, b.Has(ip), true)
+	assert.Equal(t, b.List(), []*BlockedIP{{CIDR: "10.0.0.1/32", Until: now.Add(blockDuration).Unix()}})
+
+	// the automatic block is extended when seen
+	b.seen(ip, now.Add(time.Minute))
+	assert.Equal(t, b.List()[0].Until, now.Add(time.Minute+blockDuration).Unix())
+
+	// the manual block is not overwritten
+	_, err := b.Block("10.0.0.1", 0, now)
+	assert.Equal(t, err, nil)
+	b.blockAuto(ip, now)
+	b.seen(ip, now)
+	assert.Equal(t, b.List(), []*BlockedIP{{CIDR: "10.0.0.1/32", Manual: true}})
+}
+
+func Test_BlockList_JSON(t *testing.T) {
+	b := NewBlockList()
+	now := time.Now()
+
+	_, err := b.Block("10.0.0.0/8", 0, now)
+	assert.Equal(t, err, nil)
+	b.blockAuto(net.ParseIP("192.168.1.1"), now)
+
+	data, err := json.Marshal(b)
+	assert.Equal(t, err, nil)
+
+	loaded := NewBlockList()
+	assert.Equal(t, json.Unmarshal(data, loaded), nil)
+	assert.Equal(t, loaded.List(), b.List())
+	assert.Equal(t, loaded.Has(net.ParseIP("10.1.2.3")), true)
+
+	// legacy backup file of IPs and their last message time
+	legacy := NewBlockList()
+	assert.Equal(t, json.Unmarshal([]byte(`{"172.16.0.1": 1000}`), legacy), nil)
+	assert.Equal(t, legacy.List(), []*BlockedIP{{CIDR: "172.16.0.1/32", Until: time.Unix(1000, 0).Add(blockDuration).Unix()}})
+	assert.Equal(t, legacy.Has(net.ParseIP("172.16.0.1")), false)
+}
@@ -24,6 +24,7 @@ type NodeHook func(node *Node)
 // Database definition
 type Database struct {
 	m              map[common.Hash]*Node
+	dials          map[common.Address]*DialStats // node id -> dial history
 	log            *log.ScdoLog
 	mutex          sync.RWMutex
 	addNodeHook    NodeHook
@@ -38,7 +39,7 @@ const (
 	NodesBackupFileName = "nodes.json"
 )
 
-// StartSaveNodes will save to a file and open a timer to backup the nodes info
+// StartSaveNodes will save to a file and open a timer to backup the nodes info and the dial history
 func (db *Database) StartSaveNodes(nodeDir string, done chan struct{}) {
 	ticker := time.NewTicker(NodesBackupInterval)
 	defer ticker.Stop()
@@ -47,6 +48,7 @@ func (db *Database) StartSaveNodes(nodeDir string, done chan struct{}) {
 		select {
 		case <-ticker.C:
 			go db.SaveNodes(nodeDir)
+			go db.SaveDialHistory(nodeDir, time.Now())
 		case <-done:
 			return
 		}
@@ -94,8 +96,9 @@ func (db *Database) SaveNodes(nodeDir string) {
 // NewDatabase new database
 func NewDatabase(log *log.ScdoLog) *Database {
 	return &Database{
-		m:   make(map[common.Hash]*Node),
-		log: log,
+		m:     make(map[common.Hash]*Node),
+		dials: make(map[common.Address]*DialStats),
+		log:   log,
 	}
 }
 
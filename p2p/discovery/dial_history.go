@@ -0,0 +1,182 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
+)
+
+const (
+	// DialHistoryFileName is the dial history backup file name
+	DialHistoryFileName = "dialHistory.json"
+
+	dialBackoffMin = 30 * time.Second
+	dialBackoffMax = time.Hour
+
+	// dialHistoryExpiry is the time to keep the dial history of the nodes which are not in the database
+	dialHistoryExpiry = 7 * 24 * time.Hour
+)
+
+// DialStats is the dial history of a node, which is persisted so that the known dead nodes are not
+// dialed again right after restart, and the good nodes are dialed first.
+type DialStats struct {
+	Node         string `json:"node"`
+	LastSuccess  int64  `json:"lastSuccess,omitempty"`  // unix time of the last successful dial, 0 if never
+	LastAttempt  int64  `json:"lastAttempt,omitempty"`  // unix time of the last dial
+	Failures     int    `json:"failures,omitempty"`     // consecutive dial failures
+	BackoffUntil int64  `json:"backoffUntil,omitempty"` // unix time before which the node is not dialed
+}
+
+// dialBackoff returns the backoff after the consecutive dial failures, which doubles from
+// dialBackoffMin for each failure and is at most dialBackoffMax.
+func dialBackoff(failures int) time.Duration {
+	backoff := dialBackoffMin
+	for i := 1; i < failures && backoff < dialBackoffMax; i++ {
+		backoff *= 2
+	}
+
+	if backoff > dialBackoffMax {
+		backoff = dialBackoffMax
+	}
+
+	return backoff
+}
+
+// DialSucceeded records a successful dial of the node, and clears its backoff
+func (db *Database) DialSucceeded(n *Node, now time.Time) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	stats := db.dialStats(n)
+	stats.LastSuccess, stats.LastAttempt = now.Unix(), now.Unix()
+	stats.Failures, stats.BackoffUntil = 0, 0
+}
+
+// DialFailed records a failed dial of the node, and backs off the next dial exponentially
+func (db *Database) DialFailed(n *Node, now time.Time) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	stats := db.dialStats(n)
+	stats.Failures++
+	stats.LastAttempt = now.Unix()
+	stats.BackoffUntil = now.Add(dialBackoff(stats.Failures)).Unix()
+}
+
+// CanDial returns false if the node is backing off after the dial failures
+func (db *Database) CanDial(id common.Address, now time.Time) bool {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	stats, ok := db.dials[id]
+	return !ok || stats.BackoffUntil <= now.Unix()
+}
+
+// GetDialStats returns the dial history of the node
+func (db *Database) GetDialStats(id common.Address) (DialStats, bool) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	if stats, ok := db.dials[id]; ok {
+		return *stats, true
+	}
+
+	return DialStats{}, false
+}
+
+// dialStats returns the dial history of the node, which is created if not exists. It is called with the lock held.
+func (db *Database) dialStats(n *Node) *DialStats {
+	stats, ok := db.dials[n.ID]
+	if !ok {
+		stats = &DialStats{}
+		db.dials[n.ID] = stats
+	}
+
+	stats.Node = n.String()
+
+	return stats
+}
+
+// SaveDialHistory dumps the dial history into disk file, the history of the nodes which are not
+// in the database and not dialed in dialHistoryExpiry is dropped.
+func (db *Database) SaveDialHistory(nodeDir string, now time.Time) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	history := make([]*DialStats, 0, len(db.dials))
+	for id, stats := range db.dials {
+		if _, ok := db.m[crypto.HashBytes(id.Bytes())]; !ok && stats.LastAttempt < now.Add(-dialHistoryExpiry).Unix() {
+			delete(db.dials, id)
+			continue
+		}
+
+		history = append(history, stats)
+	}
+
+	data, err := json.MarshalIndent(history, "", "\t")
+	if err != nil {
+		db.log.Error("json marshal error, [%s]", err.Error())
+		return
+	}
+
+	if err := os.MkdirAll(nodeDir, os.ModePerm); err != nil {
+		db.log.Error("filePath:[%s], failed to create folder, [%s]", nodeDir, err.Error())
+		return
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(nodeDir, DialHistoryFileName), data, 0666); err != nil {
+		db.log.Error("dial history backup failed, for:[%s]", err.Error())
+		return
+	}
+
+	db.log.Debug("backups dial history. size %d", len(history))
+}
+
+// LoadDialHistory loads the dial history from disk file, and returns the nodes in the history
+func (db *Database) LoadDialHistory(nodeDir string) []*Node {
+	fileFullPath := filepath.Join(nodeDir, DialHistoryFileName)
+	if !common.FileOrFolderExists(fileFullPath) {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(fileFullPath)
+	if err != nil {
+		db.log.Error("failed to read dial history backup file for:[%s]", err)
+		return nil
+	}
+
+	var history []*DialStats
+	if err = json.Unmarshal(data, &history); err != nil {
+		db.log.Error("failed to unmarshal dial history for:[%s]", err)
+		return nil
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	var nodes []*Node
+	for _, stats := range history {
+		n, err := NewNodeFromString(stats.Node)
+		if err != nil {
+			db.log.Debug("new node from string failed for:[%s]", err)
+			continue
+		}
+
+		db.dials[n.ID] = stats
+		nodes = append(nodes, n)
+	}
+
+	db.log.Debug("load dial history of %d nodes from back file", len(nodes))
+
+	return nodes
+}
@@ -0,0 +1,71 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DialBackoff(t *testing.T) {
+	assert.Equal(t, dialBackoff(1), dialBackoffMin)
+	assert.Equal(t, dialBackoff(2), 2*dialBackoffMin)
+	assert.Equal(t, dialBackoff(4), 8*dialBackoffMin)
+	assert.Equal(t, dialBackoff(100), dialBackoffMax)
+}
+
+func Test_Database_DialHistory(t *testing.T) {
+	db := NewDatabase(log.GetLogger("discovery"))
+	good := MustNewNodeWithAddr(*crypto.MustGenerateShardAddress(1), "127.0.0.1:9888", 1)
+	dead := MustNewNodeWithAddr(*crypto.MustGenerateShardAddress(1), "127.0.0.1:9889", 1)
+	now := time.Now()
+
+	assert.Equal(t, db.CanDial(dead.ID, now), true)
+
+	db.DialFailed(dead, now)
+	db.DialFailed(dead, now)
+	assert.Equal(t, db.CanDial(dead.ID, now), false)
+	assert.Equal(t, db.CanDial(dead.ID, now.Add(dialBackoff(2))), true)
+
+	stats, ok := db.GetDialStats(dead.ID)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, stats.Failures, 2)
+	assert.Equal(t, stats.LastSuccess, int64(0))
+
+	db.DialFailed(good, now)
+	db.DialSucceeded(good, now)
+	assert.Equal(t, db.CanDial(good.ID, now), true)
+	stats, _ = db.GetDialStats(good.ID)
+	assert.Equal(t, stats.Failures, 0)
+	assert.Equal(t, stats.LastSuccess, now.Unix())
+
+	// the dial history is restored after restart
+	tempFolder := common.GetTempFolder()
+	db.add(good, false)
+	db.SaveDialHistory(tempFolder, now)
+	defer os.Remove(filepath.Join(tempFolder, DialHistoryFileName))
+
+	loaded := NewDatabase(log.GetLogger("discovery"))
+	nodes := loaded.LoadDialHistory(tempFolder)
+	assert.Equal(t, len(nodes), 2)
+	assert.Equal(t, loaded.CanDial(dead.ID, now), false)
+	stats, _ = loaded.GetDialStats(good.ID)
+	assert.Equal(t, stats.LastSuccess, now.Unix())
+
+	// the expired history of the node not in database is dropped
+	db.SaveDialHistory(tempFolder, now.Add(dialHistoryExpiry+time.Second))
+	_, ok = db.GetDialStats(dead.ID)
+	assert.Equal(t, ok, false)
+	_, ok = db.GetDialStats(good.ID)
+	assert.Equal(t, ok, true)
+}
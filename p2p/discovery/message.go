@@ -221,12 +221,8 @@ func (m *findNode) send(t *udp) {
 				return true
 			}
 
-			for _, n := range r.Nodes {
-				t.log.Debug("received node: %s", n.SelfID.Hex())
-
-				node := n.ToNode()
-				t.addNode(node, false)
-			}
+			// verify the nodes asynchronously, as the callback is called in the reply loop
+			go t.verifyNodes(r.Nodes, addr)
 
 			return true
 		},
@@ -257,12 +253,12 @@ func sendFindNodeRequest(u *udp, nodes []*Node, target common.Address) {
 
 		concurrentCount++
 		if concurrentCount == discoveryConcurrentNumber {
-			time.Sleep(discoveryInterval)
+			time.Sleep(u.pacer.discoveryInterval())
 			concurrentCount = 0
 		}
 	}
 
-	time.Sleep(discoveryInterval)
+	time.Sleep(u.pacer.discoveryInterval())
 }
 
 func sendFindShardNodeRequest(u *udp, shard uint, to *Node) {
@@ -288,9 +284,7 @@ func (m *findShardNode) send(t *udp) {
 			r := resp.(*shardNode)
 			t.log.Debug("got response [shardNodeMsg] with nodes number %d in shard %d from:%s",
 				len(r.Nodes), r.RequestShard, addr)
-			for _, node := range r.Nodes {
-				t.addNode(node.ToNode(), false)
-			}
+			go t.verifyNodes(r.Nodes, addr)
 
 			return true
 		},
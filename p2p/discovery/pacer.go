@@ -0,0 +1,111 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+)
+
+const (
+	// the fastest interval used when the table is underfull or in burst mode,
+	// must be bigger than response timeout so requests of a round do not overlap
+	minDiscoveryInterval = responseTimeout + time.Second
+
+	// the slowest interval used when the table is full
+	maxDiscoveryInterval = 2 * discoveryInterval
+	maxPingpongInterval  = 2 * pingpongInterval
+
+	// duration of the burst mode triggered on start and after network partition
+	burstDuration = 2 * time.Minute
+
+	// the table is considered partitioned when it loses more than half of its nodes between two observations
+	partitionLossRatio = 2
+)
+
+// pacer adapts the ping and findnode rates to the fullness of the table.
+type pacer struct {
+	lock       sync.Mutex
+	table      *Table
+	burstUntil time.Time
+	lastCount  int
+}
+
+func newPacer(table *Table) *pacer {
+	return &pacer{
+		table: table,
+	}
+}
+
+// burst enters the burst mode, in which the fastest interval is used.
+func (p *pacer) burst() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.burstUntil = time.Now().Add(burstDuration)
+}
+
+// observe checks the table size and triggers the burst mode when the network seems partitioned.
+// It returns whether the pacer is in burst mode.
+func (p *pacer) observe() bool {
+	count := p.table.count()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	if p.lastCount > 0 && (count == 0 || count*partitionLossRatio < p.lastCount) {
+		p.table.log.Warn("discovery table shrinks from %d to %d nodes, enter burst mode", p.lastCount, count)
+		p.burstUntil = now.Add(burstDuration)
+	}
+	p.lastCount = count
+
+	return now.Before(p.burstUntil)
+}
+
+// fullness returns the fill ratio of the shard buckets in [0, 1], and whether any shard bucket is underfull.
+func (p *pacer) fullness() (float64, bool) {
+	total, underfull := 0, false
+	for i := 1; i < common.ShardCount+1; i++ {
+		size := p.table.shardBuckets[i].size()
+		if size < shardTargeNodeNumber {
+			underfull = true
+		}
+
+		if size > bucketSize {
+			size = bucketSize
+		}
+		total += size
+	}
+
+	return float64(total) / float64(common.ShardCount*bucketSize), underfull
+}
+
+// interval returns the interval between min and max according to the table fullness.
+func (p *pacer) interval(max time.Duration) time.Duration {
+	if p.observe() {
+		return minDiscoveryInterval
+	}
+
+	ratio, underfull := p.fullness()
+	if underfull {
+		return minDiscoveryInterval
+	}
+
+	return minDiscoveryInterval + time.Duration(float64(max-minDiscoveryInterval)*ratio)
+}
+
+// discoveryInterval returns the sleep interval between findnode requests.
+func (p *pacer) discoveryInterval() time.Duration {
+	return p.interval(maxDiscoveryInterval)
+}
+
+// pingpongInterval returns the sleep interval between ping requests.
+func (p *pacer) pingpongInterval() time.Duration {
+	return p.interval(maxPingpongInterval)
+}
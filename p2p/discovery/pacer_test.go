@@ -0,0 +1,48 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Pacer_Interval(t *testing.T) {
+	table := newTestTable()
+	p := newPacer(table)
+
+	// empty table is underfull
+	assert.Equal(t, p.discoveryInterval(), minDiscoveryInterval)
+
+	// full table backs off
+	for i := 1; i < common.ShardCount+1; i++ {
+		for j := 0; j < bucketSize; j++ {
+			table.shardBuckets[i].addNode(NewNode(*crypto.MustGenerateShardAddress(uint(i)), nil, 0, uint(i)))
+		}
+	}
+	assert.Equal(t, p.discoveryInterval(), maxDiscoveryInterval)
+	assert.Equal(t, p.pingpongInterval(), maxPingpongInterval)
+
+	// burst mode uses the fastest interval
+	p.burst()
+	assert.Equal(t, p.discoveryInterval(), minDiscoveryInterval)
+}
+
+func Test_Pacer_Partition(t *testing.T) {
+	table := newTestTable()
+	p := newPacer(table)
+
+	node := NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 1)
+	table.addNode(node)
+	assert.Equal(t, p.observe(), false)
+
+	// losing all nodes triggers the burst mode
+	table.deleteNode(node)
+	assert.Equal(t, p.observe(), true)
+}
@@ -11,13 +11,15 @@ import (
 	"github.com/scdoproject/go-scdo/common"
 )
 
-// StartService start node udp service
-func StartService(nodeDir string, myID common.Address, myAddr *net.UDPAddr, bootstrap []*Node, shard uint) (*Database, *UDP) {
+// StartService start node udp service, and the nodes from the same subnet in the table are limited by the subnet limit
+func StartService(nodeDir string, myID common.Address, myAddr *net.UDPAddr, bootstrap []*Node, shard uint, subnetLimit SubnetLimit) (*Database, *UDP) {
 	udp := newUDP(myID, myAddr, shard)
+	udp.table.subnetLimit = subnetLimit
 	if bootstrap != nil {
 		udp.trustNodes = bootstrap
 	}
 	udp.loadNodes(nodeDir)
+	udp.loadDialHistory(nodeDir)
 	udp.loadBlockList(nodeDir)
 	udp.StartServe(nodeDir)
 
@@ -0,0 +1,101 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	// DefaultSubnetPrefix is the default prefix length of the IPv4 subnets
+	DefaultSubnetPrefix = 24
+
+	// subnetPrefixIPv6 is the prefix length of the IPv6 subnets, which is usually assigned to a site
+	subnetPrefixIPv6 = 48
+
+	// DefaultMaxNodesPerSubnet is the default max number of the nodes from the same subnet in the discovery table
+	DefaultMaxNodesPerSubnet = 10
+)
+
+// SubnetLimit limits the number of the nodes from the same subnet, so that an attacker with the addresses of
+// a few subnets could not fill the discovery table or the connections to eclipse the node. The loopback and
+// private addresses are not limited, which are used by the local networks.
+type SubnetLimit struct {
+	Prefix int // prefix length of the IPv4 subnets
+	Max    int // max number of the nodes from the same subnet, 0 means no limit
+}
+
+// NewSubnetLimit creates the limit of the subnets with the prefix length of the IPv4 subnets, 0 means the default
+// prefix. max is the max number of the nodes from the same subnet, 0 means defaultMax, and negative means no limit.
+func NewSubnetLimit(prefix int, max int, defaultMax int) SubnetLimit {
+	if prefix <= 0 || prefix > 32 {
+		prefix = DefaultSubnetPrefix
+	}
+
+	if max == 0 {
+		max = defaultMax
+	} else if max < 0 {
+		max = 0
+	}
+
+	return SubnetLimit{Prefix: prefix, Max: max}
+}
+
+// Subnet returns the subnet of the IP in CIDR notation, e.g. "1.2.3.0/24", or empty if the IP is
+// not limited, i.e. nil, loopback or private.
+func (l SubnetLimit) Subnet(ip net.IP) string {
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() {
+		return ""
+	}
+
+	prefix := l.Prefix
+	if prefix <= 0 {
+		prefix = DefaultSubnetPrefix
+	}
+
+	bits := 32
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else {
+		prefix, bits = subnetPrefixIPv6, 128
+	}
+
+	return fmt.Sprintf("%s/%d", ip.Mask(net.CIDRMask(prefix, bits)), prefix)
+}
+
+// Allows returns whether a node of the IP could be added, given the IPs of the existing nodes
+func (l SubnetLimit) Allows(ip net.IP, existing []net.IP) bool {
+	if l.Max <= 0 {
+		return true
+	}
+
+	subnet := l.Subnet(ip)
+	if subnet == "" {
+		return true
+	}
+
+	count := 0
+	for _, e := range existing {
+		if l.Subnet(e) == subnet {
+			count++
+		}
+	}
+
+	return count < l.Max
+}
+
+// Distribution returns the number of the IPs by subnet, and the IPs which are not limited are excluded
+func (l SubnetLimit) Distribution(ips []net.IP) map[string]int {
+	distribution := make(map[string]int)
+	for _, ip := range ips {
+		if subnet := l.Subnet(ip); subnet != "" {
+			distribution[subnet]++
+		}
+	}
+
+	return distribution
+}
@@ -0,0 +1,62 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"net"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SubnetLimit_Subnet(t *testing.T) {
+	limit := NewSubnetLimit(0, 0, DefaultMaxNodesPerSubnet)
+	assert.Equal(t, limit, SubnetLimit{Prefix: DefaultSubnetPrefix, Max: DefaultMaxNodesPerSubnet})
+
+	assert.Equal(t, limit.Subnet(net.ParseIP("1.2.3.4")), "1.2.3.0/24")
+	assert.Equal(t, limit.Subnet(net.ParseIP("2001:db8:1:2::1")), "2001:db8:1::/48")
+	assert.Equal(t, NewSubnetLimit(16, 0, 0).Subnet(net.ParseIP("1.2.3.4")), "1.2.0.0/16")
+
+	// the local addresses are not limited
+	assert.Equal(t, limit.Subnet(nil), "")
+	assert.Equal(t, limit.Subnet(net.ParseIP("127.0.0.1")), "")
+	assert.Equal(t, limit.Subnet(net.ParseIP("192.168.1.1")), "")
+
+	// negative means no limit
+	assert.Equal(t, NewSubnetLimit(0, -1, DefaultMaxNodesPerSubnet).Max, 0)
+}
+
+func Test_SubnetLimit_Allows(t *testing.T) {
+	limit := NewSubnetLimit(0, 2, DefaultMaxNodesPerSubnet)
+	existing := []net.IP{net.ParseIP("1.2.3.4"), net.ParseIP("1.2.3.5"), net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.1")}
+
+	assert.Equal(t, limit.Allows(net.ParseIP("1.2.3.6"), existing), false)
+	assert.Equal(t, limit.Allows(net.ParseIP("1.2.4.6"), existing), true)
+	assert.Equal(t, limit.Allows(net.ParseIP("127.0.0.1"), existing), true)
+	assert.Equal(t, NewSubnetLimit(0, -1, 2).Allows(net.ParseIP("1.2.3.6"), existing), true)
+
+	assert.Equal(t, limit.Distribution(existing), map[string]int{"1.2.3.0/24": 2})
+}
+
+func Test_Table_SubnetLimit(t *testing.T) {
+	table := newTestTable()
+	table.subnetLimit = NewSubnetLimit(0, 2, DefaultMaxNodesPerSubnet)
+
+	newNode := func(ip string) *Node {
+		return NewNodeWithAddr(*crypto.MustGenerateShardAddress(1), &net.UDPAddr{IP: net.ParseIP(ip), Port: 9000}, 1)
+	}
+
+	node1 := newNode("1.2.3.4")
+	assert.Equal(t, table.addNode(node1), true)
+	assert.Equal(t, table.addNode(newNode("1.2.3.5")), true)
+	assert.Equal(t, table.addNode(newNode("1.2.3.6")), false)
+	assert.Equal(t, table.addNode(newNode("1.2.4.6")), true)
+	assert.Equal(t, table.count(), 3)
+
+	// the existing node is updated
+	assert.Equal(t, table.addNode(node1), true)
+}
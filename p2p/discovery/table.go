@@ -35,6 +35,9 @@ type Table struct {
 	// info of local node
 	selfNode *Node
 
+	// limit of the nodes from the same subnet
+	subnetLimit SubnetLimit
+
 	log *log.ScdoLog
 }
 
@@ -42,8 +45,9 @@ func newTable(id common.Address, addr *net.UDPAddr, shard uint, log *log.ScdoLog
 	selfNode := NewNodeWithAddr(id, addr, shard)
 
 	table := &Table{
-		selfNode: selfNode,
-		log:      log,
+		selfNode:    selfNode,
+		subnetLimit: NewSubnetLimit(0, 0, DefaultMaxNodesPerSubnet),
+		log:         log,
 	}
 
 	for i := 0; i < nBuckets; i++ {
@@ -81,6 +85,11 @@ func (t *Table) findConnectedNodes(target common.Hash) []*Node {
 
 func (t *Table) addNode(node *Node) bool {
 	if isShardValid(node.Shard) {
+		if !t.subnetLimit.Allows(node.IP, t.otherNodeIPs(node.ID)) {
+			t.log.Debug("skip node %s, too many nodes from the subnet %s", node, t.subnetLimit.Subnet(node.IP))
+			return false
+		}
+
 		if node.Shard != t.selfNode.Shard {
 			t.shardBuckets[node.Shard].addNode(node)
 
@@ -97,6 +106,31 @@ func (t *Table) addNode(node *Node) bool {
 	return false
 }
 
+// otherNodeIPs returns the IPs of the nodes in the table except the node of the id
+func (t *Table) otherNodeIPs(id common.Address) []net.IP {
+	var ips []net.IP
+	collect := func(b *bucket) {
+		b.lock.RLock()
+		defer b.lock.RUnlock()
+
+		for _, n := range b.peers {
+			if !n.ID.Equal(id) {
+				ips = append(ips, n.IP)
+			}
+		}
+	}
+
+	for _, b := range t.buckets {
+		collect(b)
+	}
+
+	for _, b := range t.shardBuckets {
+		collect(b)
+	}
+
+	return ips
+}
+
 // getPeersCount obtain all peers count
 func (t *Table) count() int {
 	count := 0
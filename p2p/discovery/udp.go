@@ -27,10 +27,10 @@ const (
 	responseTimeout = 20 * time.Second
 
 	pingpongConcurrentNumber = 5
-	pingpongInterval         = 25 * time.Second // sleep between ping pong, must big than response time out
+	pingpongInterval         = 25 * time.Second // base sleep between ping pong, adapted by the pacer, must be bigger than response timeout
 
 	discoveryConcurrentNumber = 5
-	discoveryInterval         = 25 * time.Second // sleep between discovery, must big than response time out
+	discoveryInterval         = 25 * time.Second // base sleep between discovery, adapted by the pacer, must be bigger than response timeout
 
 	// a node will be delete after n continuous time out.
 	timeoutCountForDeleteNode = 16
@@ -58,10 +58,12 @@ type udp struct {
 	addPending chan *pending
 	writer     chan *send
 
-	log *log.ScdoLog
+	log      *log.ScdoLog
+	pacer    *pacer
+	verifier *verifier
 
 	timeoutNodesCount cmap.ConcurrentMap //node id -> count
-	blockList         cmap.ConcurrentMap //blockList for ip, key is IP  and value is last (ping) message unix-timestamp
+	blockList         *BlockList         //blocked IPs and CIDR ranges
 }
 
 type pending struct {
@@ -99,9 +101,10 @@ func newUDP(id common.Address, addr *net.UDPAddr, shard uint) *udp {
 		panic(fmt.Sprintf("failed to listen addr %s ", addr.String()))
 	}
 
+	table := newTable(id, addr, shard, discoverylog)
 	transport := &udp{
 		conn:      conn,
-		table:     newTable(id, addr, shard, discoverylog),
+		table:     table,
 		self:      NewNodeWithAddr(id, addr, shard),
 		localAddr: addr,
 
@@ -112,8 +115,10 @@ func newUDP(id common.Address, addr *net.UDPAddr, shard uint) *udp {
 		writer:     make(chan *send, 1),
 
 		log:               discoverylog,
+		pacer:             newPacer(table),
+		verifier:          newVerifier(),
 		timeoutNodesCount: cmap.New(),
-		blockList:         cmap.New(),
+		blockList:         NewBlockList(),
 		// toTrustNodes:      make([]*Node, 0),
 	}
 
@@ -144,6 +149,11 @@ func (u *udp) GetBlockListCount() int {
 	return u.blockList.Count()
 }
 
+// BlockList returns the block list shared with the TCP connections
+func (u *udp) BlockList() *BlockList {
+	return u.blockList
+}
+
 func (u *udp) sendMsg(t msgType, msg interface{}, toID common.Address, toAddr *net.UDPAddr) {
 	encoding, err := common.Serialize(msg)
 	if err != nil {
@@ -214,7 +224,7 @@ func (u *udp) handleMsg(from *net.UDPAddr, data []byte) {
 			}
 			if msg.Version != discoveryProtocolVersion {
 				u.log.Error("pingMsg invalid discoveryProtocolVersion from addr:%s", from)
-				u.blockList.Set(from.IP.String(), time.Now().Unix())
+				u.blockList.blockAuto(from.IP, time.Now())
 				return
 			}
 			// response ping
@@ -328,8 +338,8 @@ func (u *udp) readLoop() {
 
 			continue
 		}
-		if u.blockList.Has(remoteAddr.IP.String()) {
-			u.blockList.Set(remoteAddr.IP.String(), time.Now().Unix())
+		if u.blockList.Has(remoteAddr.IP) {
+			u.blockList.seen(remoteAddr.IP, time.Now())
 			u.log.Warn("blockList update,addr:%s", remoteAddr)
 			continue
 		}
@@ -445,13 +455,13 @@ func (u *udp) discovery() {
 
 				concurrentCount++
 				if concurrentCount == discoveryConcurrentNumber {
-					time.Sleep(discoveryInterval)
+					time.Sleep(u.pacer.discoveryInterval())
 					concurrentCount = 0
 				}
 			}
 		}
 
-		time.Sleep(discoveryInterval)
+		time.Sleep(u.pacer.discoveryInterval())
 	}
 }
 
@@ -497,7 +507,7 @@ func (u *udp) pingPongService() {
 		u.log.Debug("loop ping pong nodes %d", len(loopPingPongNodes))
 		concurrentCount := 0
 		for _, n := range loopPingPongNodes {
-			if u.blockList.Has(n.IP.String()) {
+			if u.blockList.Has(n.IP) {
 				u.log.Warn("skip ping node in block list,%s", n.IP.String())
 				continue
 			}
@@ -505,12 +515,13 @@ func (u *udp) pingPongService() {
 
 			concurrentCount++
 			if concurrentCount == pingpongConcurrentNumber {
-				time.Sleep(pingpongInterval)
+				time.Sleep(u.pacer.pingpongInterval())
 				concurrentCount = 0
 			}
 		}
-		u.log.Debug("loop pingpong sleep with %d, now %+v", pingpongInterval, time.Now())
-		time.Sleep(pingpongInterval)
+		interval := u.pacer.pingpongInterval()
+		u.log.Debug("loop pingpong sleep with %s, now %+v", interval, time.Now())
+		time.Sleep(interval)
 	}
 }
 
@@ -527,6 +538,7 @@ func (u *udp) ping(value *Node) {
 }
 
 func (u *udp) StartServe(nodeDir string) {
+	u.pacer.burst()
 	go u.checkBlockList()
 	go u.readLoop()
 	go u.loopReply()
@@ -546,12 +558,8 @@ func (u *udp) checkBlockList() {
 	for {
 		select {
 		case <-ticker.C:
-			for _, key := range u.blockList.Keys() {
-				timestamp, _ := u.blockList.Get(key)
-				if time.Now().Sub(time.Unix(timestamp.(int64), 0)) > blockDuration {
-					u.blockList.Remove(key)
-					u.log.Debug("remove from block list:%s", key)
-				}
+			for _, cidr := range u.blockList.removeExpired(time.Now()) {
+				u.log.Debug("remove from block list:%s", cidr)
 			}
 		}
 	}
@@ -689,6 +697,25 @@ func (u *udp) loadNodes(nodeDir string) {
 	u.log.Debug("load %d nodes from back file", len(u.bootstrapNodes))
 }
 
+// loadDialHistory loads the dial history, and the nodes dialed successfully before are added to the bootstrap nodes
+func (u *udp) loadDialHistory(nodeDir string) {
+	for _, n := range u.db.LoadDialHistory(nodeDir) {
+		if stats, _ := u.db.GetDialStats(n.ID); stats.LastSuccess > 0 && !u.hasBootstrapNode(n) {
+			u.bootstrapNodes = append(u.bootstrapNodes, n)
+		}
+	}
+}
+
+func (u *udp) hasBootstrapNode(n *Node) bool {
+	for _, b := range u.bootstrapNodes {
+		if b.ID.Equal(n.ID) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (u *udp) loadBlockList(nodeDir string) {
 	fileFullPath := filepath.Join(nodeDir, blockListBackupFile)
 
@@ -703,16 +730,10 @@ func (u *udp) loadBlockList(nodeDir string) {
 		return
 	}
 
-	var nodes map[string]int64
-	err = json.Unmarshal(data, &nodes)
-	if err != nil {
+	if err = json.Unmarshal(data, u.blockList); err != nil {
 		u.log.Error("failed to unmarshal block list for:[%s]", err)
 		return
 	}
 
-	for i := range nodes {
-		u.blockList.Set(i, nodes[i])
-	}
-
 	u.log.Debug("load %d blocked IPs from back file", u.blockList.Count())
 }
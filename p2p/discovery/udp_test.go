@@ -23,9 +23,11 @@ func newTestUDP() *udp {
 
 	log := log.GetLogger("discovery")
 	addr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:9666")
+	table := newTable(selfNode.ID, addr, 1, log)
 	return &udp{
 		trustNodes:        []*Node{node1, node2},
-		table:             newTable(selfNode.ID, addr, 1, log),
+		table:             table,
+		pacer:             newPacer(table),
 		self:              NewNodeWithAddr(selfNode.ID, addr, 1),
 		db:                NewDatabase(log),
 		writer:            make(chan *send, 1),
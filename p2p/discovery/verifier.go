@@ -0,0 +1,152 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// max number of nodes from the neighbors and shard node responses of a source IP verified in a window
+	maxVerifyNodesPerSource = 16
+	verifyWindow            = time.Minute
+)
+
+// verifier verifies the nodes learned from the neighbors and shard node responses by a ping before they are
+// added in the table, so that the unreachable addresses sent by a malicious node could not fill the buckets.
+// The verifications are rate limited per source IP.
+type verifier struct {
+	lock    sync.Mutex
+	pending map[string]time.Time    // udp address of the node being verified -> deadline
+	sources map[string]*sourceQuota // source IP -> quota in the current window
+}
+
+type sourceQuota struct {
+	count       int
+	windowStart time.Time
+}
+
+func newVerifier() *verifier {
+	return &verifier{
+		pending: make(map[string]time.Time),
+		sources: make(map[string]*sourceQuota),
+	}
+}
+
+// start returns whether to verify the node learned from the source, false if the node is being verified
+// or the quota of the source is used up.
+func (v *verifier) start(n *Node, source net.IP, now time.Time) bool {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.prune(now)
+
+	addr := n.GetUDPAddr().String()
+	if _, ok := v.pending[addr]; ok {
+		return false
+	}
+
+	quota := v.sources[source.String()]
+	if quota == nil {
+		quota = &sourceQuota{windowStart: now}
+		v.sources[source.String()] = quota
+	}
+
+	if quota.count >= maxVerifyNodesPerSource {
+		return false
+	}
+
+	quota.count++
+	v.pending[addr] = now.Add(responseTimeout)
+
+	return true
+}
+
+// done marks the verification of the node done
+func (v *verifier) done(n *Node) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	delete(v.pending, n.GetUDPAddr().String())
+}
+
+// prune removes the expired verifications and quotas
+func (v *verifier) prune(now time.Time) {
+	for addr, deadline := range v.pending {
+		if now.After(deadline) {
+			delete(v.pending, addr)
+		}
+	}
+
+	for source, quota := range v.sources {
+		if now.Sub(quota.windowStart) >= verifyWindow {
+			delete(v.sources, source)
+		}
+	}
+}
+
+// verifyNodes pings the nodes learned from the neighbors or shard node response of the source,
+// and the nodes are added in the table only when the pong is received.
+func (u *udp) verifyNodes(nodes []*rpcNode, source *net.UDPAddr) {
+	now := time.Now()
+	for _, n := range nodes {
+		node := n.ToNode()
+		if u.self.ID.Equal(node.ID) || !isShardValid(node.Shard) || node.IP == nil {
+			continue
+		}
+
+		// the known nodes are verified by the ping pong service
+		if _, ok := u.db.FindByNodeID(node.ID); ok {
+			continue
+		}
+
+		if u.blockList.Has(node.IP) {
+			continue
+		}
+
+		if !u.verifier.start(node, source.IP, now) {
+			u.log.Debug("skip to verify node %s from %s", node, source)
+			continue
+		}
+
+		u.verifyNode(node)
+	}
+}
+
+// verifyNode pings the node and adds it in the table on pong
+func (u *udp) verifyNode(n *Node) {
+	p := &pending{
+		from: n,
+		code: pongMsgType,
+
+		callback: func(resp interface{}, addr *net.UDPAddr) (done bool) {
+			u.verifier.done(n)
+
+			r := resp.(*pong)
+			node := NewNodeWithAddr(r.SelfID, addr, r.SelfShard)
+			u.addNode(node, true)
+			u.timeoutNodesCount.Set(node.ID.Hex(), 0)
+
+			u.log.Debug("verified node: %s", node)
+
+			return true
+		},
+		errorCallBack: func() {
+			u.verifier.done(n)
+		},
+	}
+
+	msg := &ping{
+		Version:   discoveryProtocolVersion,
+		SelfID:    u.self.ID,
+		SelfShard: u.self.Shard,
+	}
+
+	u.addPending <- p
+	u.sendMsg(pingMsgType, msg, n.ID, n.GetUDPAddr())
+}
@@ -0,0 +1,91 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVerifyNode(port int) *Node {
+	return MustNewNodeWithAddr(*crypto.MustGenerateShardAddress(2), fmt.Sprintf("127.0.0.1:%d", port), 2)
+}
+
+func Test_Verifier_Start(t *testing.T) {
+	v := newVerifier()
+	source := net.ParseIP("10.0.0.1")
+	now := time.Now()
+
+	node := newTestVerifyNode(9100)
+	assert.Equal(t, v.start(node, source, now), true)
+
+	// being verified
+	assert.Equal(t, v.start(node, net.ParseIP("10.0.0.2"), now), false)
+
+	// verify again when done or expired
+	v.done(node)
+	assert.Equal(t, v.start(node, source, now), true)
+	assert.Equal(t, v.start(node, source, now.Add(responseTimeout+time.Second)), true)
+}
+
+func Test_Verifier_SourceQuota(t *testing.T) {
+	v := newVerifier()
+	source := net.ParseIP("10.0.0.1")
+	now := time.Now()
+
+	for i := 0; i < maxVerifyNodesPerSource; i++ {
+		assert.Equal(t, v.start(newTestVerifyNode(9100+i), source, now), true)
+	}
+
+	// quota of the source is used up
+	assert.Equal(t, v.start(newTestVerifyNode(9000), source, now), false)
+
+	// other sources are not affected
+	assert.Equal(t, v.start(newTestVerifyNode(9000), net.ParseIP("10.0.0.2"), now), true)
+
+	// quota is reset in the next window
+	assert.Equal(t, v.start(newTestVerifyNode(9001), source, now.Add(verifyWindow)), true)
+}
+
+func Test_UDP_VerifyNodes(t *testing.T) {
+	u := newTestUDP()
+	u.verifier = newVerifier()
+	u.blockList = NewBlockList()
+	u.addPending = make(chan *pending, maxVerifyNodesPerSource+1)
+	u.writer = make(chan *send, maxVerifyNodesPerSource+1)
+
+	source, _ := net.ResolveUDPAddr("udp", "10.0.0.1:9000")
+	node := newTestVerifyNode(9100)
+	u.verifyNodes([]*rpcNode{convertToRPCNode(node)}, source)
+
+	// not added before pong
+	assert.Equal(t, u.db.size(), 0)
+	assert.Equal(t, (<-u.writer).code, pingMsgType)
+
+	p := <-u.addPending
+	assert.Equal(t, p.from, node)
+
+	p.callback(&pong{discoveryProtocolVersion, node.ID, node.Shard}, node.GetUDPAddr())
+	assert.Equal(t, u.db.size(), 1)
+
+	// the known node is not verified again
+	u.verifyNodes([]*rpcNode{convertToRPCNode(node)}, source)
+	assert.Equal(t, len(u.addPending), 0)
+
+	// rate limited per source
+	var nodes []*rpcNode
+	for i := 0; i < 2*maxVerifyNodesPerSource; i++ {
+		nodes = append(nodes, convertToRPCNode(newTestVerifyNode(9200+i)))
+	}
+
+	u.verifyNodes(nodes, source)
+	assert.Equal(t, len(u.addPending), maxVerifyNodesPerSource-1)
+}
@@ -12,6 +12,14 @@ var (
 	metricsDeletePeerMeter = metrics.NewRegisteredMeter("p2p.deletepeer", nil)
 	metricsPeerCountGauge  = metrics.NewRegisteredGauge("p2p.peercount", nil)
 
+	// metricsPingTimeoutMeter marks the peers dropped for missing pings
+	metricsPingTimeoutMeter = metrics.NewRegisteredMeter("p2p.pingtimeout", nil)
+
+	// distribution of the peers by subnet, and the connections rejected for too many peers from the same subnet
+	metricsSubnetCountGauge       = metrics.NewRegisteredGauge("p2p.subnetcount", nil)
+	metricsMaxPeersPerSubnetGauge = metrics.NewRegisteredGauge("p2p.maxpeerspersubnet", nil)
+	metricsSubnetRejectMeter      = metrics.NewRegisteredMeter("p2p.subnetreject", nil)
+
 	metricsSendMessageCountMeter  = metrics.NewRegisteredMeter("p2p.sendmessagecount", nil)
 	metricsReceiveMessageCountMeter  = metrics.NewRegisteredMeter("p2p.receivemessagecount", nil)
 	metricsSendPortSpeedMeter = metrics.NewRegisteredMeter("p2p.sendportspeed", nil)
@@ -132,7 +132,8 @@ func (set *nodeSet) ifNeedAddNodes(shardid uint) bool {
 
 }
 
-// randSelect select one node randomly from nodeMap which is not connected yet
+// randSelect select one node per shard from nodeMap which is not connected yet and not backing off after
+// the dial failures, the node dialed successfully most recently is preferred, otherwise a random one
 func (set *nodeSet) randSelect(srv *Server) []*discovery.Node {
 	set.lock.RLock()
 	defer set.lock.RUnlock()
@@ -141,6 +142,10 @@ func (set *nodeSet) randSelect(srv *Server) []*discovery.Node {
 	var retNodes []*discovery.Node
 	var shardNodeCounts [common.ShardCount]int
 
+	var bestNodes [common.ShardCount]*discovery.Node
+	var bestSuccess [common.ShardCount]int64
+	now := time.Now()
+
 	for _, v := range set.nodeMap {
 		pe := srv.peerSet.find(v.node.ID)
 		if pe != nil && v.bConnected {
@@ -149,13 +154,27 @@ func (set *nodeSet) randSelect(srv *Server) []*discovery.Node {
 			continue
 		}
 
+		// skip the nodes backing off after the dial failures
+		if !srv.canDial(v.node, now) {
+			continue
+		}
+
 		nodeL[v.node.Shard-1] = append(nodeL[v.node.Shard-1], v.node)
+
+		// the node dialed successfully most recently is selected first
+		if lastSuccess := srv.lastDialSuccess(v.node); lastSuccess > bestSuccess[v.node.Shard-1] {
+			bestNodes[v.node.Shard-1], bestSuccess[v.node.Shard-1] = v.node, lastSuccess
+		}
 	}
 
 	for i := 0; i < common.ShardCount; i++ {
 		if shardNodeCounts[i] >= maxActiveConnsPerShard {
 			continue
 		}
+		if bestNodes[i] != nil {
+			retNodes = append(retNodes, bestNodes[i])
+			continue
+		}
 		len := len(nodeL[i])
 		if len > 0 {
 			k := rand.Int31n(int32(len))
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/scdoproject/go-scdo/common"
@@ -21,8 +22,14 @@ import (
 const (
 	pingInterval   = 1 * time.Second                  // ping interval for peer tcp connection. Should be 15
 	discServerQuit = "disconnect because server quit" // p2p.server need quit, all peers should quit as it can
+
+	// maxMissedPings is the max number of pings without any message received from the peer,
+	// the peer is regarded as dead and dropped if exceeded, e.g. the connection is silently dropped by NAT.
+	maxMissedPings = 20
 )
 
+var errPingTimeout = errors.New("no response of the pings")
+
 // Peer represents a connected remote node.
 type Peer struct {
 	protocolErr   chan error
@@ -35,6 +42,12 @@ type Peer struct {
 	wg   sync.WaitGroup
 	log  *log.ScdoLog
 	lock sync.Mutex
+
+	lastSeen    int64 // unix nano of the last message received, accessed atomically
+	missedPings int32 // number of pings sent since the last message received, accessed atomically
+
+	traffic      *trafficStats // traffic of the peer by message
+	totalTraffic *trafficStats // traffic of all peers, shared by the peers of the server, nil if not counted
 }
 
 // NewPeer creates and returns a new peer.
@@ -49,9 +62,28 @@ func NewPeer(conn *connection, log *log.ScdoLog, node *discovery.Node) *Peer {
 		protocolErr:   make(chan error),
 		Node:          node,
 		lock:          sync.Mutex{},
+		lastSeen:      time.Now().UnixNano(),
+		traffic:       newTrafficStats(),
+	}
+}
+
+// recordTraffic counts the message of the protocol in the traffic stats, nil protocol for the control messages
+func (p *Peer) recordTraffic(proto *Protocol, code uint16, in bool, payloadSize int) {
+	size := headBuffLength + payloadSize
+	if p.traffic != nil {
+		p.traffic.add(proto, code, in, size)
+	}
+
+	if p.totalTraffic != nil {
+		p.totalTraffic.add(proto, code, in, size)
 	}
 }
 
+// TrafficStats returns the traffic of the peer by message
+func (p *Peer) TrafficStats() map[string]TrafficCounter {
+	return p.traffic.snapshot()
+}
+
 func (p *Peer) setProtocols(protocols []Protocol) {
 	offset := baseProtoCode
 	protoMap := make(map[string]protocolRW)
@@ -63,6 +95,7 @@ func (p *Peer) setProtocols(protocols []Protocol) {
 			Protocol: protocol,
 			in:       make(chan Message, 1),
 			close:    p.closed,
+			peer:     p,
 		}
 
 		protoMap[protocol.cap().String()] = protoRW
@@ -80,9 +113,10 @@ func (p *Peer) getShardNumber() uint {
 // run assumes that SubProtocol will never quit, otherwise proto.DelPeerCh may be closed before peer.run quits?
 func (p *Peer) run() (err error) {
 	var readErr = make(chan error, 1)
+	var pingErr = make(chan error, 1)
 	p.wg.Add(2)
 	go p.readLoop(readErr)
-	go p.pingLoop()
+	go p.pingLoop(pingErr)
 
 	// Wait for an error or disconnect.
 errLoop:
@@ -91,6 +125,10 @@ errLoop:
 		case err = <-readErr:
 			p.log.Debug("p2p.peer.run read err %s", err)
 			break errLoop
+		case err = <-pingErr:
+			p.log.Info("p2p peer %s is dead, %s, last seen at %s", p.RemoteAddr(), err, p.LastSeen())
+			metricsPingTimeoutMeter.Mark(1)
+			break errLoop
 		case reason := <-p.disconnection:
 			p.log.Info("p2p peer got disconnection request")
 			err = fmt.Errorf("disconnection error received, %s", reason)
@@ -119,7 +157,9 @@ func (p *Peer) close() {
 	p.rw.fd.Close()
 }
 
-func (p *Peer) pingLoop() {
+// pingLoop pings the peer periodically, and reports errPingTimeout if the
+// peer misses more than maxMissedPings pings.
+func (p *Peer) pingLoop(pingErr chan<- error) {
 	ping := time.NewTimer(pingInterval)
 	defer p.log.Debug("exit ping loop.")
 	defer p.wg.Done()
@@ -127,6 +167,11 @@ func (p *Peer) pingLoop() {
 	for {
 		select {
 		case <-ping.C:
+			if atomic.AddInt32(&p.missedPings, 1) > maxMissedPings {
+				pingErr <- errPingTimeout
+				return
+			}
+
 			p.sendCtlMsg(ctlMsgPingCode)
 
 			ping.Reset(pingInterval)
@@ -193,10 +238,21 @@ func (p *Peer) notifyProtocolsDeletePeer() {
 	}
 }
 
+// LastSeen returns the time of the last message received from the peer
+func (p *Peer) LastSeen() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&p.lastSeen))
+}
+
 func (p *Peer) handle(msgRecv *Message) (err error) {
+	// any message shows the peer is alive, as the pong may be delayed by large messages
+	atomic.StoreInt64(&p.lastSeen, time.Now().UnixNano())
+	atomic.StoreInt32(&p.missedPings, 0)
+
 	// control msg
 
 	if msgRecv.Code < baseProtoCode {
+		p.recordTraffic(nil, msgRecv.Code, true, len(msgRecv.Payload))
+
 		switch {
 		case msgRecv.Code == ctlMsgPingCode:
 			err = p.sendCtlMsg(ctlMsgPongCode)
@@ -224,6 +280,8 @@ func (p *Peer) handle(msgRecv *Message) (err error) {
 		return fmt.Errorf(fmt.Sprintf("could not found mapping proto with code %d", msgRecv.Code))
 	}
 
+	p.recordTraffic(&protocolTarget.Protocol, msgRecv.Code-protocolTarget.offset, true, len(msgRecv.Payload))
+
 	if !protocolTarget.bQuited {
 		protocolTarget.in <- *msgRecv
 	}
@@ -237,6 +295,9 @@ func (p *Peer) sendCtlMsg(msgCode uint16) error {
 	}
 
 	err := p.rw.WriteMsg(&hsMsg)
+	if err == nil {
+		p.recordTraffic(nil, msgCode, false, 0)
+	}
 
 	return err
 }
@@ -259,6 +320,7 @@ type protocolRW struct {
 	in      chan Message // read message channel, message will be transferred here when it is a protocol message
 	rw      MsgReadWriter
 	close   chan struct{}
+	peer    *Peer // peer to count the traffic, nil if not counted
 }
 
 func (rw *protocolRW) WriteMsg(msg *Message) (err error) {
@@ -266,9 +328,14 @@ func (rw *protocolRW) WriteMsg(msg *Message) (err error) {
 		return errors.New("invalid msg code")
 	}
 
+	code := msg.Code
 	msg.Code += rw.offset
 
-	return rw.rw.WriteMsg(msg)
+	if err = rw.rw.WriteMsg(msg); err == nil && rw.peer != nil {
+		rw.peer.recordTraffic(&rw.Protocol, code, false, len(msg.Payload))
+	}
+
+	return err
 }
 
 func (rw *protocolRW) ReadMsg() (*Message, error) {
@@ -300,8 +367,10 @@ type PeerInfo struct {
 		LocalAddress  string `json:"localAddress"`  // Local endpoint of the TCP data connection
 		RemoteAddress string `json:"remoteAddress"` // Remote endpoint of the TCP data connection
 	} `json:"network"`
-	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
-	Shard     uint                   `json:"shard"`     // shard id of the node
+	Protocols   map[string]interface{} `json:"protocols"`   // Sub-protocol specific metadata fields
+	Shard       uint                   `json:"shard"`       // shard id of the node
+	LastSeen    time.Time              `json:"lastSeen"`    // time of the last message received
+	MissedPings int32                  `json:"missedPings"` // number of pings sent since the last message received
 }
 
 // Info returns data of the peer but not contain id and name.
@@ -324,10 +393,12 @@ func (p *Peer) Info() *PeerInfo {
 	}
 
 	info := &PeerInfo{
-		ID:        p.Node.ID.Hex(),
-		Caps:      caps,
-		Protocols: protocols,
-		Shard:     p.getShardNumber(),
+		ID:          p.Node.ID.Hex(),
+		Caps:        caps,
+		Protocols:   protocols,
+		Shard:       p.getShardNumber(),
+		LastSeen:    p.LastSeen(),
+		MissedPings: atomic.LoadInt32(&p.missedPings),
 	}
 	info.Network.LocalAddress = p.LocalAddr().String()
 	info.Network.RemoteAddress = p.RemoteAddr().String()
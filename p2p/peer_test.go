@@ -6,8 +6,11 @@
 package p2p
 
 import (
+	"io"
+	"io/ioutil"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/crypto"
@@ -92,3 +95,35 @@ func Test_peer_RunAndClose(t *testing.T) {
 	//assert.Equal(t, ok2, false)
 	//assert.Equal(t, ok3, false)
 }
+
+func Test_peer_PingTimeout(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	// the remote reads the pings but never responds
+	go io.Copy(ioutil.Discard, remote)
+
+	node := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 1)
+	p := NewPeer(&connection{fd: local, log: log.GetLogger("peer")}, log.GetLogger("peer"), node)
+
+	// any message received resets the missed pings
+	p.missedPings = maxMissedPings
+	assert.Equal(t, p.handle(&Message{Code: ctlMsgPongCode}), nil)
+	assert.Equal(t, p.Info().MissedPings, int32(0))
+	assert.Equal(t, time.Since(p.Info().LastSeen) < time.Second, true)
+
+	// the next ping exceeds the max missed pings
+	p.missedPings = maxMissedPings
+	pingErr := make(chan error, 1)
+	p.wg.Add(1)
+	go p.pingLoop(pingErr)
+
+	select {
+	case err := <-pingErr:
+		assert.Equal(t, err, errPingTimeout)
+	case <-time.After(3 * pingInterval):
+		t.Fatal("ping timeout not detected")
+	}
+
+	p.wg.Wait()
+}
@@ -0,0 +1,55 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package p2p
+
+import (
+	"net"
+	"sort"
+
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+)
+
+// PipePeers connects two nodes in the same process with an in-memory pipe instead of the tcp connection,
+// and runs the protocols on both sides without the discovery and the handshake of the server, e.g. to wire
+// the in-process networks of the integration tests. Both sides should run the same protocols. The peers are
+// disconnected once the returned function is called.
+func PipePeers(local *discovery.Node, localProtocols []Protocol, remote *discovery.Node, remoteProtocols []Protocol,
+	log *log.ScdoLog) (disconnect func()) {
+	localFd, remoteFd := net.Pipe()
+
+	// the local peer represents the remote node, and vice versa
+	runPipePeer(localFd, remote, localProtocols, log)
+	runPipePeer(remoteFd, local, remoteProtocols, log)
+
+	return func() {
+		localFd.Close()
+		remoteFd.Close()
+	}
+}
+
+// runPipePeer runs the peer of the remote node on the pipe, and notifies the protocols once the peer
+// is added or deleted, the same as the peers of the server.
+func runPipePeer(fd net.Conn, node *discovery.Node, protocols []Protocol, log *log.ScdoLog) *Peer {
+	// the message codes of the protocols are offset in the order of the caps on both sides
+	sorted := make([]Protocol, len(protocols))
+	copy(sorted, protocols)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		caps := capsByNameAndVersion{sorted[i].cap(), sorted[j].cap()}
+		return caps.Less(0, 1)
+	})
+
+	peer := NewPeer(&connection{fd: fd, log: log}, log, node)
+	peer.setProtocols(sorted)
+
+	go func() {
+		peer.notifyProtocolsAddPeer()
+		peer.run()
+		peer.notifyProtocolsDeletePeer()
+	}()
+
+	return peer
+}
@@ -0,0 +1,66 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPipeProtocol(received chan<- []byte, deleted chan<- *Peer) Protocol {
+	protocol := *newProtocol()
+	protocol.AddPeer = func(peer *Peer, rw MsgReadWriter) bool {
+		go func() {
+			if msg, err := rw.ReadMsg(); err == nil {
+				received <- msg.Payload
+			}
+		}()
+
+		return rw.WriteMsg(&Message{Code: 1, Payload: []byte(peer.Node.ID.Hex())}) == nil
+	}
+	protocol.DeletePeer = func(peer *Peer) {
+		deleted <- peer
+	}
+
+	return protocol
+}
+
+func Test_PipePeers(t *testing.T) {
+	local := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 1)
+	remote := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 1)
+
+	received := make(chan []byte, 2)
+	deleted := make(chan *Peer, 2)
+	disconnect := PipePeers(local, []Protocol{newPipeProtocol(received, deleted)},
+		remote, []Protocol{newPipeProtocol(received, deleted)}, log.GetLogger("pipe"))
+
+	// each side receives the message with the id of itself from the other side
+	ids := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case payload := <-received:
+			ids[string(payload)] = true
+		case <-time.After(3 * time.Second):
+			t.Fatal("message not received through the pipe")
+		}
+	}
+
+	assert.Equal(t, ids, map[string]bool{local.ID.Hex(): true, remote.ID.Hex(): true})
+
+	disconnect()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-deleted:
+		case <-time.After(3 * time.Second):
+			t.Fatal("peer not deleted once disconnected")
+		}
+	}
+}
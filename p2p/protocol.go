@@ -36,6 +36,9 @@ type Protocol struct {
 
 	// GetPeer this method will be called for get peer information
 	GetPeer func(address common.Address) interface{}
+
+	// CodeName returns the name of the message code in the traffic stats, nil to use the code number
+	CodeName func(code uint16) string
 }
 
 func (p *Protocol) cap() Cap {
@@ -58,6 +58,9 @@ const (
 	// maxConnectionsPerIp represents max connections that node from one ip can connect to.
 	// Reject connections if  ipSet[ip] > maxConnectionsPerIp.
 	maxConnsPerShardPerIp = uint(maxConnsPerShard / 2)
+
+	// defaultMaxPeersPerSubnet is the default max number of peers from the same subnet
+	defaultMaxPeersPerSubnet = 4
 )
 
 // Config is the Configuration of p2p
@@ -76,6 +79,24 @@ type Config struct {
 
 	// PrivateKey private key for p2p module, do not use it as any accounts
 	PrivateKey *ecdsa.PrivateKey `json:"-"`
+
+	// MaxConnections is the max connections that node can connect to, 0 means the default
+	MaxConnections int `json:"maxConnections"`
+
+	// MaxActiveConnections is the max connections that node can actively connect to, 0 means the default
+	MaxActiveConnections int `json:"maxActiveConnections"`
+
+	// SubnetPrefix is the prefix length of the IPv4 subnets, in which the nodes and peers are limited
+	// against the eclipse attacks, 0 means the default /24
+	SubnetPrefix int `json:"subnetPrefix"`
+
+	// MaxNodesPerSubnet is the max number of nodes from the same subnet in the discovery table,
+	// 0 means the default, and negative means no limit
+	MaxNodesPerSubnet int `json:"maxNodesPerSubnet"`
+
+	// MaxPeersPerSubnet is the max number of peers from the same subnet, 0 means the default,
+	// and negative means no limit
+	MaxPeersPerSubnet int `json:"maxPeersPerSubnet"`
 }
 
 // Server manages all p2p peer connections.
@@ -122,7 +143,12 @@ type Server struct {
 	// Need not connect to a new node if srv.PeerCount > maxActiveConnections.
 	maxActiveConnections int
 
+	// subnetLimit limits the peers from the same subnet
+	subnetLimit discovery.SubnetLimit
+
 	peerNumLock sync.Mutex // lock for num of peers per shard
+
+	traffic *trafficStats // traffic of all peers by message
 }
 
 // NewServer initialize a server
@@ -142,7 +168,7 @@ func NewServer(genesis core.GenesisInfo, config Config, protocols []Protocol) *S
 	genesis.Masteraccount = masteraccount
 	genesis.Balance = balance
 
-	return &Server{
+	srv := &Server{
 		Config:               config,
 		running:              false,
 		log:                  log.GetLogger("p2p"),
@@ -155,7 +181,19 @@ func NewServer(genesis core.GenesisInfo, config Config, protocols []Protocol) *S
 		genesisHash:          hash,
 		maxConnections:       maxConnsPerShard * common.ShardCount,
 		maxActiveConnections: maxActiveConnsPerShard * common.ShardCount,
+		subnetLimit:          discovery.NewSubnetLimit(config.SubnetPrefix, config.MaxPeersPerSubnet, defaultMaxPeersPerSubnet),
+		traffic:              newTrafficStats(),
+	}
+
+	if config.MaxConnections > 0 {
+		srv.maxConnections = config.MaxConnections
 	}
+
+	if config.MaxActiveConnections > 0 {
+		srv.maxActiveConnections = config.MaxActiveConnections
+	}
+
+	return srv
 }
 
 //
@@ -185,7 +223,8 @@ func (srv *Server) Start(nodeDir string, shard uint) (err error) {
 	srv.SelfNode = discovery.NewNodeWithAddr(*address, addr, shard)
 
 	srv.log.Info("Starting P2P Server, MyNodeID [%s]", srv.SelfNode)
-	srv.kadDB, srv.udp = discovery.StartService(nodeDir, *address, addr, srv.Config.StaticNodes, shard)
+	nodesLimit := discovery.NewSubnetLimit(srv.SubnetPrefix, srv.MaxNodesPerSubnet, discovery.DefaultMaxNodesPerSubnet)
+	srv.kadDB, srv.udp = discovery.StartService(nodeDir, *address, addr, srv.Config.StaticNodes, shard, nodesLimit)
 	srv.kadDB.SetHookForNewNode(srv.addNode)
 	srv.kadDB.SetHookForDeleteNode(srv.deleteNode)
 	// add static nodes to srv node set;
@@ -268,6 +307,11 @@ func (srv *Server) connectNode(node *discovery.Node) {
 		return
 	}
 
+	if srv.isBlocked(node.IP) {
+		srv.log.Debug("skip connecting to the blocked node %s", node)
+		return
+	}
+
 	//TODO UDPPort==> TCPPort
 	addr, err := net.ResolveTCPAddr("tcp4", fmt.Sprintf("%s:%d", node.IP.String(), node.UDPPort))
 	if err != nil {
@@ -275,21 +319,40 @@ func (srv *Server) connectNode(node *discovery.Node) {
 		return
 	}
 
+	if !srv.canDial(node, time.Now()) {
+		srv.log.Debug("skip connecting to the node %s backing off after dial failures", node)
+		return
+	}
+
 	conn, err := net.DialTimeout("tcp", addr.String(), defaultDialTimeout)
 	if err != nil {
 		srv.log.Debug("connect to a new node err: %s, node: %s", err, node)
 		if conn != nil {
 			conn.Close()
 		}
+		srv.kadDB.DialFailed(node, time.Now())
 		return
 	}
 
 	srv.log.Info("connect to a node with %s -> %s", conn.LocalAddr(), conn.RemoteAddr())
 	if err := srv.setupConn(conn, outboundConn, node); err != nil {
 		srv.log.Debug("failed to add new node. err=%s", err)
+		srv.kadDB.DialFailed(node, time.Now())
+		return
 	}
-	return
 
+	srv.kadDB.DialSucceeded(node, time.Now())
+}
+
+// canDial returns false if the node is backing off after the dial failures
+func (srv *Server) canDial(node *discovery.Node, now time.Time) bool {
+	return srv.kadDB.CanDial(node.ID, now)
+}
+
+// lastDialSuccess returns the unix time of the last successful dial of the node, 0 if never
+func (srv *Server) lastDialSuccess(node *discovery.Node) int64 {
+	stats, _ := srv.kadDB.GetDialStats(node.ID)
+	return stats.LastSuccess
 }
 
 func (srv *Server) deleteNode(node *discovery.Node) {
@@ -329,6 +392,7 @@ func (srv *Server) addPeer(p *Peer) (bool, bool) { //bool, bool: addPeer isAdd,
 	go p.notifyProtocolsAddPeer()
 	metricsAddPeerMeter.Mark(1)
 	metricsPeerCountGauge.Update(int64(srv.PeerCount()))
+	srv.updateSubnetMetrics()
 
 	return true, false
 }
@@ -349,6 +413,7 @@ func (srv *Server) deletePeer(id common.Address) {
 
 		metricsDeletePeerMeter.Mark(1)
 		metricsPeerCountGauge.Update(int64(srv.PeerCount()))
+		srv.updateSubnetMetrics()
 	} else {
 		srv.log.Info("server.run delPeerChan received. peer not match")
 	}
@@ -529,6 +594,19 @@ func (srv *Server) listenLoop() {
 // Assume the inbound side is server side; outbound side is client side.
 func (srv *Server) setupConn(fd net.Conn, flags int, dialDest *discovery.Node) (err error) {
 
+	if flags == inboundConn {
+		if addr, ok := fd.RemoteAddr().(*net.TCPAddr); ok && srv.isBlocked(addr.IP) {
+			fd.Close()
+			return fmt.Errorf("reject the connection from the blocked IP %s", addr.IP)
+		}
+	}
+
+	if ip := connIP(fd, flags, dialDest); !srv.subnetLimit.Allows(ip, srv.peerIPs()) {
+		fd.Close()
+		metricsSubnetRejectMeter.Mark(1)
+		return fmt.Errorf("reject the connection with %s, too many peers from the subnet %s", ip, srv.subnetLimit.Subnet(ip))
+	}
+
 	if flags == inboundConn && srv.PeerCount() > srv.maxConnections {
 		srv.log.Warn("setup connection with peer %s. reached max incoming connection limit, reject!", dialDest)
 		return errors.New("too many incoming connections")
@@ -541,6 +619,7 @@ func (srv *Server) setupConn(fd net.Conn, flags int, dialDest *discovery.Node) (
 
 	srv.log.Debug("setup connection with peer %s", dialDest)
 	peer := NewPeer(&connection{fd: fd, log: srv.log}, srv.log, dialDest)
+	peer.totalTraffic = srv.traffic
 
 	var caps []Cap
 
@@ -870,6 +949,106 @@ func (srv *Server) PeersInfo() []PeerInfo {
 	return infos
 }
 
+// TrafficStats returns the traffic of the server and the connected peers by message, the traffic of
+// the server includes the peers disconnected.
+func (srv *Server) TrafficStats() *TrafficStats {
+	stats := &TrafficStats{
+		Total: srv.traffic.snapshot(),
+		Peers: make(map[string]map[string]TrafficCounter),
+	}
+
+	for _, p := range srv.peerSet.getPeers() {
+		if p != nil {
+			stats.Peers[p.Node.ID.Hex()] = p.TrafficStats()
+		}
+	}
+
+	return stats
+}
+
+// BlockList returns the block list shared by the UDP discovery and the TCP connections,
+// or nil if the server is not started.
+func (srv *Server) BlockList() *discovery.BlockList {
+	if srv.udp == nil {
+		return nil
+	}
+
+	return srv.udp.BlockList()
+}
+
+func (srv *Server) isBlocked(ip net.IP) bool {
+	blockList := srv.BlockList()
+	return blockList != nil && blockList.Has(ip)
+}
+
+// BlockIP blocks the IP or CIDR range for the duration, 0 means never expire, and
+// disconnects the connected peers in the range.
+func (srv *Server) BlockIP(cidr string, duration time.Duration) (*discovery.BlockedIP, error) {
+	blockList := srv.BlockList()
+	if blockList == nil {
+		return nil, errors.New("p2p server is not started")
+	}
+
+	blocked, err := blockList.Block(cidr, duration, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range srv.peerSet.getPeers() {
+		if addr, ok := p.RemoteAddr().(*net.TCPAddr); ok && blockList.Has(addr.IP) {
+			srv.log.Info("disconnect the blocked peer %s", addr)
+			go p.Disconnect("blocked")
+		}
+	}
+
+	return blocked, nil
+}
+
+// connIP returns the remote IP of the connection, or the IP of the dialed node
+func connIP(fd net.Conn, flags int, dialDest *discovery.Node) net.IP {
+	if flags == outboundConn && dialDest != nil {
+		return dialDest.IP
+	}
+
+	if addr, ok := fd.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.IP
+	}
+
+	return nil
+}
+
+// peerIPs returns the IPs of the connected peers
+func (srv *Server) peerIPs() []net.IP {
+	var ips []net.IP
+	for _, p := range srv.peerSet.getPeers() {
+		if p != nil && p.Node != nil {
+			ips = append(ips, p.Node.IP)
+		}
+	}
+
+	return ips
+}
+
+// SubnetDistribution returns the number of the connected peers by subnet, except the peers of
+// the loopback and private addresses.
+func (srv *Server) SubnetDistribution() map[string]int {
+	return srv.subnetLimit.Distribution(srv.peerIPs())
+}
+
+func (srv *Server) updateSubnetMetrics() {
+	distribution := srv.SubnetDistribution()
+
+	maxPeers := 0
+	for _, count := range distribution {
+		if count > maxPeers {
+			maxPeers = count
+		}
+	}
+
+	metricsSubnetCountGauge.Update(int64(len(distribution)))
+	metricsMaxPeersPerSubnetGauge.Update(int64(maxPeers))
+}
+
 // IsListening return whether the node is listen or not
 func (srv *Server) IsListening() bool {
 	return srv.listener != nil
@@ -0,0 +1,102 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package p2p
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ctlProtoName is the name of the control messages in the traffic stats, e.g. ping and pong
+const ctlProtoName = "p2p"
+
+// TrafficCounter is the traffic of a kind of messages, the bytes include the message header
+type TrafficCounter struct {
+	MessagesIn  uint64 `json:"messagesIn"`
+	MessagesOut uint64 `json:"messagesOut"`
+	BytesIn     uint64 `json:"bytesIn"`
+	BytesOut    uint64 `json:"bytesOut"`
+}
+
+// TrafficStats is the traffic of the p2p server by message, and the traffic of each connected peer
+type TrafficStats struct {
+	Total map[string]TrafficCounter            `json:"total"`
+	Peers map[string]map[string]TrafficCounter `json:"peers"` // peer id => traffic by message
+}
+
+type trafficKey struct {
+	proto string
+	code  uint16
+}
+
+type trafficCounter struct {
+	name string
+	TrafficCounter
+}
+
+// trafficStats counts the traffic by message, which is named with the protocol name and the
+// message code in the protocol, e.g. "scdo/blockMsgCode".
+type trafficStats struct {
+	lock     sync.Mutex
+	counters map[trafficKey]*trafficCounter
+}
+
+func newTrafficStats() *trafficStats {
+	return &trafficStats{
+		counters: make(map[trafficKey]*trafficCounter),
+	}
+}
+
+// add counts a message of the protocol, nil protocol for the control messages
+func (s *trafficStats) add(proto *Protocol, code uint16, in bool, size int) {
+	key := trafficKey{ctlProtoName, code}
+	if proto != nil {
+		key.proto = proto.Name
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &trafficCounter{name: trafficName(proto, key)}
+		s.counters[key] = c
+	}
+
+	if in {
+		c.MessagesIn++
+		c.BytesIn += uint64(size)
+	} else {
+		c.MessagesOut++
+		c.BytesOut += uint64(size)
+	}
+}
+
+func (s *trafficStats) snapshot() map[string]TrafficCounter {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make(map[string]TrafficCounter, len(s.counters))
+	for _, c := range s.counters {
+		// the codes may share a name, e.g. the unknown codes
+		r := result[c.name]
+		r.MessagesIn += c.MessagesIn
+		r.MessagesOut += c.MessagesOut
+		r.BytesIn += c.BytesIn
+		r.BytesOut += c.BytesOut
+		result[c.name] = r
+	}
+
+	return result
+}
+
+func trafficName(proto *Protocol, key trafficKey) string {
+	if proto != nil && proto.CodeName != nil {
+		return fmt.Sprintf("%s/%s", key.proto, proto.CodeName(key.code))
+	}
+
+	return fmt.Sprintf("%s/%d", key.proto, key.code)
+}
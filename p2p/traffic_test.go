@@ -0,0 +1,43 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TrafficStats(t *testing.T) {
+	stats := newTrafficStats()
+
+	named := &Protocol{
+		Name: "scdo",
+		CodeName: func(code uint16) string {
+			if code == 1 {
+				return "blockMsgCode"
+			}
+
+			return "unknown"
+		},
+	}
+	unnamed := &Protocol{Name: "lightscdo"}
+
+	stats.add(named, 1, true, 100)
+	stats.add(named, 1, false, 50)
+	stats.add(named, 1, true, 10)
+	stats.add(named, 2, true, 7)
+	stats.add(named, 3, false, 8)
+	stats.add(unnamed, 5, true, 20)
+	stats.add(nil, ctlMsgPingCode, false, 4)
+
+	result := stats.snapshot()
+	assert.Equal(t, len(result), 4)
+	assert.Equal(t, result["scdo/blockMsgCode"], TrafficCounter{MessagesIn: 2, MessagesOut: 1, BytesIn: 110, BytesOut: 50})
+	assert.Equal(t, result["scdo/unknown"], TrafficCounter{MessagesIn: 1, MessagesOut: 1, BytesIn: 7, BytesOut: 8})
+	assert.Equal(t, result["lightscdo/5"], TrafficCounter{MessagesIn: 1, BytesIn: 20})
+	assert.Equal(t, result["p2p/3"], TrafficCounter{MessagesOut: 1, BytesOut: 4})
+}
@@ -0,0 +1,114 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// methodMetrics records the call count, error count and latency of an RPC method
+type methodMetrics struct {
+	calls   metrics.Counter
+	errors  metrics.Counter
+	latency metrics.Timer
+}
+
+// MethodStats is the statistics of an RPC method
+type MethodStats struct {
+	Calls     int64   `json:"calls"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+	MeanMs    float64 `json:"meanMs"`
+	MaxMs     float64 `json:"maxMs"`
+	P95Ms     float64 `json:"p95Ms"`
+	P99Ms     float64 `json:"p99Ms"`
+}
+
+var (
+	methodMetricsLock sync.Mutex
+	methodMetricsMap  = make(map[string]*methodMetrics)
+)
+
+// getMethodMetrics returns the metrics of the given method, registering them on first use
+func getMethodMetrics(method string) *methodMetrics {
+	methodMetricsLock.Lock()
+	defer methodMetricsLock.Unlock()
+
+	if m, ok := methodMetricsMap[method]; ok {
+		return m
+	}
+
+	m := &methodMetrics{
+		calls:   metrics.GetOrRegisterCounter("rpc."+method+".calls", nil),
+		errors:  metrics.GetOrRegisterCounter("rpc."+method+".errors", nil),
+		latency: metrics.GetOrRegisterTimer("rpc."+method+".latency", nil),
+	}
+	methodMetricsMap[method] = m
+
+	return m
+}
+
+// methodStats returns the statistics of all methods that have been called
+func methodStats() map[string]MethodStats {
+	methodMetricsLock.Lock()
+	defer methodMetricsLock.Unlock()
+
+	stats := make(map[string]MethodStats, len(methodMetricsMap))
+	for method, m := range methodMetricsMap {
+		calls, errs := m.calls.Count(), m.errors.Count()
+		snapshot := m.latency.Snapshot()
+		ps := snapshot.Percentiles([]float64{0.95, 0.99})
+
+		s := MethodStats{
+			Calls:  calls,
+			Errors: errs,
+			MeanMs: snapshot.Mean() / float64(time.Millisecond),
+			MaxMs:  float64(snapshot.Max()) / float64(time.Millisecond),
+			P95Ms:  ps[0] / float64(time.Millisecond),
+			P99Ms:  ps[1] / float64(time.Millisecond),
+		}
+		if calls > 0 {
+			s.ErrorRate = float64(errs) / float64(calls)
+		}
+		stats[method] = s
+	}
+
+	return stats
+}
+
+// recordCall updates the metrics of the called method and logs the call if it is slower than the threshold
+func (s *Server) recordCall(req *serverRequest, elapsed time.Duration, failed bool) {
+	method := req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)
+
+	m := getMethodMetrics(method)
+	m.calls.Inc(1)
+	m.latency.Update(elapsed)
+	if failed {
+		m.errors.Inc(1)
+	}
+
+	if threshold := s.SlowCallThreshold(); threshold > 0 && elapsed >= threshold {
+		s.log.Warn("slow rpc call, method %s, args size %d, duration %s, failed %t", method, req.argsSize, elapsed, failed)
+	}
+}
+
+// SetSlowCallThreshold sets the duration above which calls are logged as slow, 0 disables the slow call log
+func (s *Server) SetSlowCallThreshold(threshold time.Duration) {
+	s.slowCallMu.Lock()
+	s.slowCallThreshold = threshold
+	s.slowCallMu.Unlock()
+}
+
+// SlowCallThreshold returns the duration above which calls are logged as slow
+func (s *Server) SlowCallThreshold() time.Duration {
+	s.slowCallMu.RLock()
+	defer s.slowCallMu.RUnlock()
+
+	return s.slowCallThreshold
+}
@@ -18,13 +18,17 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
 	"gopkg.in/fatih/set.v0"
 )
 
@@ -48,6 +52,7 @@ func NewServer() *Server {
 		codecs:            set.New(),
 		run:               1,
 		minerRemoteRequst: false,
+		log:               log.GetLogger("rpc"),
 	}
 
 	// register a default service which will provide meta information about the RPC service such as the services and
@@ -73,6 +78,11 @@ func (s *RPCService) Modules() map[string]string {
 	return modules
 }
 
+// MethodStats returns the call count, error rate and latency of the called RPC methods
+func (s *RPCService) MethodStats() map[string]MethodStats {
+	return methodStats()
+}
+
 // RegisterName will create a service for the given rcvr type under the given name. When no methods on the given rcvr
 // match the criteria to be either a RPC method or a subscription an error is returned. Otherwise a new service is
 // created and added to the service collection this server instance serves.
@@ -296,6 +306,9 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, rpcErr), nil
 	}
 
+	// trace the call, and the methods with context could start child spans
+	ctx, span := tracing.StartSpanFromContext(ctx, "rpc."+req.svcname+serviceMethodSeparator+formatName(req.callb.method.Name))
+
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
 		arguments = append(arguments, reflect.ValueOf(ctx))
@@ -305,13 +318,20 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	// execute RPC method and return result
+	start := time.Now()
 	reply := req.callb.method.Func.Call(arguments)
+	failed := req.callb.errPos >= 0 && !reply[req.callb.errPos].IsNil()
+	s.recordCall(req, time.Since(start), failed)
+	if failed {
+		span.SetError(reply[req.callb.errPos].Interface().(error))
+	}
+	span.End()
 	if len(reply) == 0 {
 		return codec.CreateResponse(req.id, nil), nil
 	}
 
 	if req.callb.errPos >= 0 { // test if method returned an error
-		if !reply[req.callb.errPos].IsNil() {
+		if failed {
 			e := reply[req.callb.errPos].Interface().(error)
 			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
 			return res, nil
@@ -435,6 +455,9 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 		if callb, ok := svc.callbacks[r.method]; ok { // lookup RPC method
 			requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+			if raw, ok := r.params.(json.RawMessage); ok {
+				requests[i].argsSize = len(raw)
+			}
 			if r.params != nil && len(callb.argTypes) > 0 {
 				if args, err := codec.ParseRequestArguments(callb.argTypes, r.params); err == nil {
 					requests[i].args = args
@@ -160,3 +160,31 @@ func TestServerMethodExecution(t *testing.T) {
 func TestServerMethodWithCtx(t *testing.T) {
 	testServerMethodExecution(t, "echoWithCtx")
 }
+
+func TestServerMethodStats(t *testing.T) {
+	testServerMethodExecution(t, "echo")
+
+	stats := methodStats()
+	s, ok := stats["test_echo"]
+	if !ok {
+		t.Fatalf("expected stats of test_echo to be recorded")
+	}
+	if s.Calls < 1 {
+		t.Errorf("expected at least 1 call, got %d", s.Calls)
+	}
+	if s.Errors != 0 || s.ErrorRate != 0 {
+		t.Errorf("expected no errors, got %d (rate %f)", s.Errors, s.ErrorRate)
+	}
+}
+
+func TestServerSlowCallThreshold(t *testing.T) {
+	server := NewServer()
+	if server.SlowCallThreshold() != 0 {
+		t.Fatalf("expected slow call log disabled by default")
+	}
+
+	server.SetSlowCallThreshold(time.Second)
+	if server.SlowCallThreshold() != time.Second {
+		t.Fatalf("expected threshold %s, got %s", time.Second, server.SlowCallThreshold())
+	}
+}
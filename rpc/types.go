@@ -22,8 +22,10 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/scdoproject/go-scdo/log"
 	"gopkg.in/fatih/set.v0"
 )
 
@@ -60,6 +62,7 @@ type serverRequest struct {
 	callb         *callback
 	args          []reflect.Value
 	isUnsubscribe bool
+	argsSize      int
 	err           Error
 }
 
@@ -75,6 +78,10 @@ type Server struct {
 	codecsMu          sync.Mutex
 	codecs            *set.Set
 	minerRemoteRequst bool
+
+	slowCallMu        sync.RWMutex
+	slowCallThreshold time.Duration // calls slower than it are logged, 0 means disabled
+	log               *log.ScdoLog
 }
 
 // rpcRequest represents a raw incoming RPC request
@@ -0,0 +1,30 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"errors"
+)
+
+// PrivateAdminAPI provides an API to manage the node, e.g. the high availability and the chain reorgs.
+type PrivateAdminAPI struct {
+	s *ScdoService
+}
+
+// NewPrivateAdminAPI creates a new PrivateAdminAPI object for admin rpc service.
+func NewPrivateAdminAPI(s *ScdoService) *PrivateAdminAPI {
+	return &PrivateAdminAPI{s}
+}
+
+// AllowDeepReorgOnce allows the next chain reorg deeper than the max reorg depth
+func (api *PrivateAdminAPI) AllowDeepReorgOnce() (bool, error) {
+	if api.s.chain.MaxReorgDepth() == 0 {
+		return false, errors.New("max reorg depth is not configured")
+	}
+
+	api.s.chain.AllowDeepReorgOnce()
+	return true, nil
+}
@@ -0,0 +1,244 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/sdk"
+)
+
+// sources of the balances returned by GetAggregateBalance
+const (
+	BalanceSourceLocal       = "local"       // the state of the local shard
+	BalanceSourceRPC         = "rpc"         // the rpc endpoints of the nodes of other shards
+	BalanceSourceLightClient = "lightClient" // the light clients of other shards
+)
+
+// shardRPCTimeout is the timeout of a request to the rpc endpoints of other shards
+const shardRPCTimeout = 10 * time.Second
+
+var errNoAggregateAccount = errors.New("no public key or address")
+
+// ShardBalance is the balance of an account in its shard
+type ShardBalance struct {
+	Shard   uint
+	Account common.Address
+	Balance *big.Int `json:",omitempty"`
+	Source  string   `json:",omitempty"` // where the balance is retrieved from
+	Error   string   `json:",omitempty"` // error of retrieving the balance
+}
+
+// AggregateBalance is the balances of the accounts across shards, and their total
+type AggregateBalance struct {
+	Total    *big.Int // sum of the balances retrieved successfully
+	Balances []*ShardBalance
+}
+
+// shardBalanceReader reads the balances of other shards, e.g. from the light chains of the shards
+type shardBalanceReader interface {
+	GetBalance(shard uint, account common.Address) (*big.Int, error)
+}
+
+// balanceSource retrieves the balance of an account from a node of the account shard
+type balanceSource struct {
+	name       string
+	getBalance func(account common.Address) (*big.Int, error)
+}
+
+// GetAggregateBalance returns the balances of the accounts in their shards and the total. A public key is
+// expanded to its addresses in all shards, so that the holdings of the same key are queried at once. The
+// balances of other shards are retrieved from the configured rpc endpoints, or the light clients of the shards.
+func (api *PublicScdoAPI) GetAggregateBalance(pubkeyOrAddressList []string) (*AggregateBalance, error) {
+	accounts, err := parseAggregateAccounts(pubkeyOrAddressList)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregateBalances(accounts, api.s.balanceSources), nil
+}
+
+// balanceSources returns the sources of the balances of the shard in the order of preference
+func (s *ScdoService) balanceSources(shard uint) []balanceSource {
+	if shard == common.LocalShardNumber {
+		return []balanceSource{{BalanceSourceLocal, s.getLocalBalance}}
+	}
+
+	var sources []balanceSource
+	if s.shardRPC != nil {
+		sources = append(sources, balanceSource{BalanceSourceRPC, s.shardRPC.getBalance})
+	}
+
+	if reader, ok := s.debtVerifier.(shardBalanceReader); ok {
+		sources = append(sources, balanceSource{BalanceSourceLightClient, func(account common.Address) (*big.Int, error) {
+			return reader.GetBalance(shard, account)
+		}})
+	}
+
+	return sources
+}
+
+func (s *ScdoService) getLocalBalance(account common.Address) (*big.Int, error) {
+	statedb, err := s.chain.GetCurrentState()
+	if err != nil {
+		return nil, err
+	}
+
+	balance := statedb.GetBalance(account)
+	if err = statedb.GetDbErr(); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// aggregateBalances retrieves the balance of each account from the first source of its shard which succeeds
+func aggregateBalances(accounts []common.Address, sources func(shard uint) []balanceSource) *AggregateBalance {
+	result := &AggregateBalance{
+		Total:    big.NewInt(0),
+		Balances: make([]*ShardBalance, 0, len(accounts)),
+	}
+
+	for _, account := range accounts {
+		balance := &ShardBalance{
+			Shard:   account.Shard(),
+			Account: account,
+		}
+
+		shardSources := sources(balance.Shard)
+		if len(shardSources) == 0 {
+			balance.Error = fmt.Sprintf("no rpc endpoint or light client of shard %d", balance.Shard)
+		}
+
+		for _, source := range shardSources {
+			value, err := source.getBalance(account)
+			if err != nil {
+				balance.Error = fmt.Sprintf("failed to get the balance from %s, %s", source.name, err)
+				continue
+			}
+
+			balance.Balance, balance.Source, balance.Error = value, source.name, ""
+			result.Total.Add(result.Total, value)
+			break
+		}
+
+		result.Balances = append(result.Balances, balance)
+	}
+
+	return result
+}
+
+// parseAggregateAccounts parses the addresses, and expands the public keys to their addresses in all shards.
+// The duplicated addresses are counted once.
+func parseAggregateAccounts(pubkeyOrAddressList []string) ([]common.Address, error) {
+	if len(pubkeyOrAddressList) == 0 {
+		return nil, errNoAggregateAccount
+	}
+
+	var accounts []common.Address
+	seen := make(map[common.Address]bool)
+	add := func(account common.Address) {
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+
+	for _, item := range pubkeyOrAddressList {
+		if account, err := common.HexToAddress(item); err == nil {
+			add(account)
+			continue
+		}
+
+		pubkey, err := hexutil.HexToBytes(item)
+		if err != nil || (len(pubkey) != 64 && len(pubkey) != 65) {
+			return nil, fmt.Errorf("invalid public key or address %s", item)
+		}
+
+		key := crypto.ToECDSAPub(pubkey)
+		if key.X == nil {
+			return nil, fmt.Errorf("invalid public key %s", item)
+		}
+
+		for shard := uint(1); shard <= common.ShardCount; shard++ {
+			account, err := crypto.GetAddress(key, shard)
+			if err != nil {
+				return nil, err
+			}
+
+			add(*account)
+		}
+	}
+
+	if len(accounts) > maxSizeLimit {
+		return nil, fmt.Errorf("too many accounts, max is %d", maxSizeLimit)
+	}
+
+	return accounts, nil
+}
+
+// shardRPCClient is the client of the rpc endpoints of the nodes of other shards, dialed on demand
+type shardRPCClient struct {
+	conf *sdk.Config
+
+	lock   sync.Mutex
+	client *sdk.Client
+}
+
+func newShardRPCClient(conf node.ShardRPCConfig) *shardRPCClient {
+	return &shardRPCClient{
+		conf: sdk.DefaultConfig(conf.Endpoints...),
+	}
+}
+
+// getBalance returns the balance of the account at the HEAD block of the node of the account shard
+func (c *shardRPCClient) getBalance(account common.Address) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shardRPCTimeout)
+	defer cancel()
+
+	client, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetBalance(ctx, account)
+}
+
+// dial connects to the endpoints once, and the client retries with other nodes of the shard on failures
+func (c *shardRPCClient) dial(ctx context.Context) (*sdk.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.client == nil {
+		client, err := sdk.Dial(ctx, c.conf)
+		if err != nil {
+			return nil, err
+		}
+
+		c.client = client
+	}
+
+	return c.client, nil
+}
+
+func (c *shardRPCClient) close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+}
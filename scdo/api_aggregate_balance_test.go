@@ -0,0 +1,75 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseAggregateAccounts(t *testing.T) {
+	addr, key := crypto.MustGenerateShardKeyPair(2)
+	pubkey := hexutil.BytesToHex(crypto.FromECDSAPub(&key.PublicKey))
+
+	// the public key is expanded to all shards, and the duplicated address is counted once
+	accounts, err := parseAggregateAccounts([]string{addr.Hex(), pubkey})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(accounts), common.ShardCount)
+	assert.Equal(t, accounts[0], *addr)
+
+	for _, account := range accounts {
+		assert.Equal(t, account.Shard() > 0 && account.Shard() <= common.ShardCount, true)
+	}
+
+	_, err = parseAggregateAccounts(nil)
+	assert.Equal(t, err, errNoAggregateAccount)
+
+	_, err = parseAggregateAccounts([]string{"0x1234"})
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_AggregateBalances(t *testing.T) {
+	addr1 := *crypto.MustGenerateShardAddress(1)
+	addr2 := *crypto.MustGenerateShardAddress(2)
+	addr3 := *crypto.MustGenerateShardAddress(3)
+
+	failed := func(common.Address) (*big.Int, error) { return nil, errors.New("unavailable") }
+	balanceOf := func(v int64) func(common.Address) (*big.Int, error) {
+		return func(common.Address) (*big.Int, error) { return big.NewInt(v), nil }
+	}
+
+	sources := func(shard uint) []balanceSource {
+		switch shard {
+		case 1:
+			return []balanceSource{{BalanceSourceLocal, balanceOf(10)}}
+		case 2:
+			// fall back to the light client
+			return []balanceSource{{BalanceSourceRPC, failed}, {BalanceSourceLightClient, balanceOf(5)}}
+		default:
+			return nil
+		}
+	}
+
+	result := aggregateBalances([]common.Address{addr1, addr2, addr3}, sources)
+	assert.Equal(t, result.Total, big.NewInt(15))
+	assert.Equal(t, len(result.Balances), 3)
+
+	assert.Equal(t, result.Balances[0].Source, BalanceSourceLocal)
+	assert.Equal(t, result.Balances[0].Balance, big.NewInt(10))
+
+	assert.Equal(t, result.Balances[1].Source, BalanceSourceLightClient)
+	assert.Equal(t, result.Balances[1].Error, "")
+
+	assert.Equal(t, result.Balances[2].Shard, uint(3))
+	assert.Equal(t, result.Balances[2].Balance == nil, true)
+	assert.Equal(t, result.Balances[2].Error != "", true)
+}
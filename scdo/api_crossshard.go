@@ -0,0 +1,127 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"errors"
+	"time"
+
+	api2 "github.com/scdoproject/go-scdo/api"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// lifecycle status of the cross shard transaction returned by GetCrossShardTxStatus
+const (
+	CrossShardStatusPending    = "pending"    // in the tx pool of the source shard
+	CrossShardStatusMined      = "mined"      // packed in the source shard, but not confirmed
+	CrossShardStatusConfirmed  = "confirmed"  // confirmed in the source shard, and the debt is propagating
+	CrossShardStatusDebtPacked = "debtPacked" // the debt is packed in the target shard, but not confirmed
+	CrossShardStatusCompleted  = "completed"  // the debt is confirmed in the target shard
+)
+
+var errNotCrossShardTx = errors.New("not a cross shard transaction")
+
+// CrossShardTxStatus is the end-to-end status of a cross shard transaction
+type CrossShardTxStatus struct {
+	TxHash    common.Hash
+	Status    string
+	FromShard uint
+	ToShard   uint
+
+	// source shard
+	BlockHash     common.Hash `json:",omitempty"`
+	BlockHeight   uint64      `json:",omitempty"`
+	Confirmations uint64      `json:",omitempty"`
+	Failed        bool        // whether the tx execution failed in the source shard
+
+	// debt propagation
+	DebtHash      common.Hash
+	DebtPending   bool   // whether the debt is still tracked and resent by the debt manager
+	DebtAcked     bool   // whether the debt is acknowledged by a peer of the target shard
+	DebtPacked    bool   // whether the debt is packed in the target shard
+	DebtConfirmed bool   // whether the debt is confirmed in the target shard
+	DebtError     string `json:",omitempty"` // error of checking the debt in the target shard
+}
+
+// GetCrossShardTxStatus returns the lifecycle status of the cross shard transaction sent from the local shard,
+// which combines the tx receipt, the debt propagation and the debt inclusion in the target shard checked
+// by the light client of the target shard.
+func (api *PublicScdoAPI) GetCrossShardTxStatus(txHash string) (*CrossShardTxStatus, error) {
+	hash, err := common.HexToHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	bcStore := api.s.chain.GetStore()
+	tx, idx, err := api2.GetTransaction(api.s.txPool, bcStore, hash)
+	if err != nil || tx == nil {
+		return nil, api2.ErrTransactionNotFound
+	}
+
+	debt := types.NewDebtWithoutContext(tx)
+	if debt == nil {
+		return nil, errNotCrossShardTx
+	}
+
+	status := &CrossShardTxStatus{
+		TxHash:    hash,
+		Status:    CrossShardStatusPending,
+		FromShard: tx.Data.From.Shard(),
+		ToShard:   tx.Data.To.Shard(),
+		DebtHash:  debt.Hash,
+	}
+
+	if idx == nil {
+		return status, nil
+	}
+
+	// the tx index may be stale if the block is reverted by a chain reorg
+	if canonicalHash, err := bcStore.GetBlockHash(idx.BlockHeight); err != nil || !canonicalHash.Equal(idx.BlockHash) {
+		return status, nil
+	}
+
+	status.Status = CrossShardStatusMined
+	status.BlockHash, status.BlockHeight = idx.BlockHash, idx.BlockHeight
+	status.Confirmations = api.s.chain.CurrentHeader().Height - idx.BlockHeight + 1
+
+	if receipt, err := bcStore.GetReceiptByTxHash(hash); err == nil {
+		status.Failed = receipt.Failed
+	}
+
+	// the debt is propagated once the tx is confirmed
+	if status.Confirmations <= common.ConfirmedBlockNumber {
+		return status, nil
+	}
+
+	status.Status = CrossShardStatusConfirmed
+	if api.s.scdoProtocol != nil {
+		if info := api.s.scdoProtocol.debtManager.Get(debt.Hash); info != nil {
+			status.DebtPending = true
+			status.DebtPacked = info.isPacked
+			status.DebtAcked = info.isAcked(time.Now())
+		}
+	}
+
+	if api.s.debtVerifier == nil {
+		status.DebtError = "no light client of the target shard"
+		return status, nil
+	}
+
+	packed, confirmed, err := api.s.debtVerifier.IfDebtPacked(debt)
+	if err != nil {
+		status.DebtError = err.Error()
+	}
+
+	status.DebtPacked, status.DebtConfirmed = packed || status.DebtPacked, confirmed
+	if status.DebtConfirmed {
+		status.Status = CrossShardStatusCompleted
+	} else if status.DebtPacked {
+		status.Status = CrossShardStatusDebtPacked
+	}
+
+	return status, nil
+}
@@ -6,6 +6,7 @@
 package scdo
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +14,8 @@ import (
 	"runtime/pprof"
 
 	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/core/types"
 )
 
@@ -36,6 +39,68 @@ func (api *PrivateDebugAPI) PrintBlock(height int64) (*types.Block, error) {
 	return block, nil
 }
 
+// GetDebtShardStatus returns the propagation status of the debts sent from the local shard to each target
+// shard, including whether the target shard is unreachable, i.e. no peer of it acknowledges the debts.
+func (api *PrivateDebugAPI) GetDebtShardStatus() ([]*DebtShardStatus, error) {
+	if api.s.scdoProtocol == nil {
+		return nil, errors.New("scdo protocol is not started")
+	}
+
+	return api.s.scdoProtocol.debtManager.ShardStatus(), nil
+}
+
+// SlowTxReport returns the recent txs whose execution exceeds the threshold when the blocks are written, the newest first
+func (api *PrivateDebugAPI) SlowTxReport() *core.SlowTxReport {
+	return api.s.chain.SlowTxReport()
+}
+
+// GetStateDiff returns the account balance, nonce, code and storage changes of the block with the given hash.
+// The state diff is only stored with --diag.statediff or while there are importedBlocks or storageChanges subscriptions.
+func (api *PrivateDebugAPI) GetStateDiff(blockHash string) (map[string]interface{}, error) {
+	hash, err := common.HexToHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := api.s.chain.GetStore().GetStateDiff(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state diff, error:%s, block hash:%s, the state diffs are stored with --diag.statediff", err, blockHash)
+	}
+
+	return map[string]interface{}{
+		"blockHash": blockHash,
+		"accounts":  rpcOutputStateDiff(diffs),
+	}, nil
+}
+
+// rpcOutputStateDiff converts the account changes of a block to the RPC output
+func rpcOutputStateDiff(diffs []*types.AccountDiff) []map[string]interface{} {
+	accounts := make([]map[string]interface{}, 0, len(diffs))
+	for _, diff := range diffs {
+		storage := make([]map[string]interface{}, 0, len(diff.Storage))
+		for _, s := range diff.Storage {
+			storage = append(storage, map[string]interface{}{
+				"key":    s.Key.Hex(),
+				"before": hexutil.BytesToHex(s.Before),
+				"after":  hexutil.BytesToHex(s.After),
+			})
+		}
+
+		accounts = append(accounts, map[string]interface{}{
+			"account":        diff.Address.Hex(),
+			"balanceBefore":  diff.BalanceBefore,
+			"balanceAfter":   diff.BalanceAfter,
+			"nonceBefore":    diff.NonceBefore,
+			"nonceAfter":     diff.NonceAfter,
+			"codeHashBefore": diff.CodeHashBefore.Hex(),
+			"codeHashAfter":  diff.CodeHashAfter.Hex(),
+			"storage":        storage,
+		})
+	}
+
+	return accounts
+}
+
 // TpsInfo tps detail info
 type TpsInfo struct {
 	StartHeight uint64
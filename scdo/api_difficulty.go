@@ -0,0 +1,230 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/consensus/utils"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+const (
+	// networkHashrateBlocks is the number of recent blocks to estimate the network hashrate
+	networkHashrateBlocks = 100
+
+	// maxDifficultyRange is the max number of blocks returned by GetDifficultyRange
+	maxDifficultyRange = 10000
+)
+
+var (
+	errNoSolveEstimator        = errors.New("the consensus engine does not support the difficulty estimation")
+	errDifficultyRangeTooLarge = fmt.Errorf("difficulty range should not exceed %d blocks", maxDifficultyRange)
+)
+
+// DifficultyInfo is the difficulty of a block and the chance to seal a block of the difficulty
+type DifficultyInfo struct {
+	Height           uint64
+	Hash             common.Hash
+	Difficulty       *big.Int
+	SolveProbability float64 // probability that one nonce attempt seals a block of the difficulty
+	ExpectedAttempts float64 // expected number of nonce attempts to seal a block of the difficulty, 0 if too many to estimate
+}
+
+// DifficultyPoint is the difficulty and total difficulty of a canonical block
+type DifficultyPoint struct {
+	Height          uint64
+	Hash            common.Hash
+	Difficulty      *big.Int
+	TotalDifficulty *big.Int
+}
+
+// TimeToBlockEstimate is the expected time for a miner of the hashrate to seal the next block
+type TimeToBlockEstimate struct {
+	Height           uint64   // height of the next block
+	Difficulty       *big.Int // difficulty of the next block if it is sealed now
+	Hashrate         float64  // nonce attempts per second of the miner
+	SolveProbability float64
+	ExpectedAttempts float64
+	ExpectedTime     float64 // expected seconds to seal the next block without competition
+
+	NetworkHashrate float64 // nonce attempts per second of the shard estimated by the recent blocks
+	BlockInterval   float64 // average seconds between the recent blocks
+	NetworkShare    float64 // share of the miner in the total hashrate after joining the shard
+	BlocksPerDay    float64 // expected blocks sealed by the miner per day after joining the shard
+}
+
+// GetDifficulty returns the difficulty of the block at the height, and the expected nonce attempts to seal
+// a block of the difficulty. The chain head is used when the height is less than 0.
+func (api *PublicScdoAPI) GetDifficulty(height int64) (*DifficultyInfo, error) {
+	header := api.s.chain.CurrentHeader()
+	if height >= 0 {
+		if header = api.s.chain.GetHeaderByHeight(uint64(height)); header == nil {
+			return nil, errors.NewStackedErrorf(errors.New("block not found"), "failed to get block %d", height)
+		}
+	}
+
+	info := &DifficultyInfo{
+		Height:     header.Height,
+		Hash:       header.Hash(),
+		Difficulty: header.Difficulty,
+	}
+
+	if estimator, ok := api.s.miner.GetEngine().(consensus.SolveEstimator); ok {
+		info.SolveProbability = estimator.SolveProbability(header.Difficulty)
+		info.ExpectedAttempts = expectedAttempts(info.SolveProbability)
+	}
+
+	return info, nil
+}
+
+// GetDifficultyRange returns the difficulty and total difficulty of the canonical blocks from the height to the
+// height inclusively, at most 10000 blocks, and the heights after the chain head are skipped. The difficulty is
+// the difference of the total difficulties stored by the block hash, so that no block header is loaded.
+func (api *PublicScdoAPI) GetDifficultyRange(from, to uint64) ([]*DifficultyPoint, error) {
+	return getDifficultyRange(api.s.chain.GetStore(), api.s.chain.CurrentHeader().Height, from, to)
+}
+
+func getDifficultyRange(bcStore store.BlockchainStore, headHeight, from, to uint64) ([]*DifficultyPoint, error) {
+	if from > to {
+		return nil, errors.New("from height should not be greater than to height")
+	}
+
+	if to-from >= maxDifficultyRange {
+		return nil, errDifficultyRangeTooLarge
+	}
+
+	if to > headHeight {
+		to = headHeight
+	}
+
+	if from > to {
+		return nil, nil
+	}
+
+	// the total difficulty of the parent block, not found for the genesis block
+	var parentTD *big.Int
+	if from > 0 {
+		if hash, err := bcStore.GetBlockHash(from - 1); err == nil {
+			if parentTD, err = bcStore.GetBlockTotalDifficulty(hash); err != nil {
+				return nil, errors.NewStackedErrorf(err, "failed to get total difficulty of block %v", hash)
+			}
+		}
+	}
+
+	points := make([]*DifficultyPoint, 0, to-from+1)
+	for height := from; height <= to; height++ {
+		hash, err := bcStore.GetBlockHash(height)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get block hash by height %d", height)
+		}
+
+		td, err := bcStore.GetBlockTotalDifficulty(hash)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get total difficulty of block %v", hash)
+		}
+
+		difficulty := new(big.Int).Set(td)
+		if parentTD != nil {
+			difficulty.Sub(td, parentTD)
+		}
+
+		points = append(points, &DifficultyPoint{height, hash, difficulty, td})
+		parentTD = td
+	}
+
+	return points, nil
+}
+
+// EstimateTimeToBlock returns the expected time for a miner of the hashrate, in nonce attempts per second,
+// to seal the next block of the shard, and the share of the miner compared to the recent blocks of the shard.
+func (api *PrivateMinerAPI) EstimateTimeToBlock(hashrate float64) (*TimeToBlockEstimate, error) {
+	if hashrate <= 0 {
+		return nil, errors.New("hashrate should be positive")
+	}
+
+	estimator, ok := api.s.miner.GetEngine().(consensus.SolveEstimator)
+	if !ok {
+		return nil, errNoSolveEstimator
+	}
+
+	chain := api.s.chain
+	head := chain.CurrentHeader()
+
+	// the difficulty decreases with the time since the head block
+	now := uint64(time.Now().Unix())
+	if headTime := head.CreateTimestamp.Uint64(); now < headTime {
+		now = headTime
+	}
+
+	headers := []*types.BlockHeader{head}
+	for len(headers) <= networkHashrateBlocks && headers[len(headers)-1].Height > common.ScdoForkHeight {
+		parent := chain.GetHeaderByHash(headers[len(headers)-1].PreviousBlockHash)
+		if parent == nil {
+			break
+		}
+
+		headers = append(headers, parent)
+	}
+
+	return estimateTimeToBlock(estimator, hashrate, utils.GetDifficult(chain.Config(), now, head), head.Height+1, headers), nil
+}
+
+// estimateTimeToBlock estimates the time to seal the next block of the difficulty, and the network hashrate
+// by the recent headers in descending order.
+func estimateTimeToBlock(estimator consensus.SolveEstimator, hashrate float64, difficulty *big.Int, height uint64, headers []*types.BlockHeader) *TimeToBlockEstimate {
+	estimate := &TimeToBlockEstimate{
+		Height:           height,
+		Difficulty:       difficulty,
+		Hashrate:         hashrate,
+		SolveProbability: estimator.SolveProbability(difficulty),
+	}
+
+	estimate.ExpectedAttempts = expectedAttempts(estimate.SolveProbability)
+	estimate.ExpectedTime = estimate.ExpectedAttempts / hashrate
+
+	// the time of the genesis block is set by users, so the interval after it is skipped
+	var attempts float64
+	var blocks int
+	for i := 0; i+1 < len(headers) && headers[i+1].Height > common.ScdoForkHeight; i++ {
+		attempts += expectedAttempts(estimator.SolveProbability(headers[i].Difficulty))
+		blocks++
+	}
+
+	if blocks > 0 {
+		start, end := headers[blocks].CreateTimestamp.Uint64(), headers[0].CreateTimestamp.Uint64()
+		if end > start {
+			elapsed := float64(end - start)
+			estimate.NetworkHashrate = attempts / elapsed
+			estimate.BlockInterval = elapsed / float64(blocks)
+		}
+	}
+
+	if estimate.BlockInterval > 0 {
+		// the difficulty adjusts to keep the block interval after the miner joins the shard
+		estimate.NetworkShare = hashrate / (hashrate + estimate.NetworkHashrate)
+		estimate.BlocksPerDay = estimate.NetworkShare * 86400 / estimate.BlockInterval
+	} else if estimate.ExpectedTime > 0 {
+		estimate.NetworkShare = 1
+		estimate.BlocksPerDay = 86400 / estimate.ExpectedTime
+	}
+
+	return estimate
+}
+
+func expectedAttempts(probability float64) float64 {
+	if probability <= 0 {
+		return 0
+	}
+
+	return 1 / probability
+}
@@ -0,0 +1,102 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSolveEstimator solves a block in difficulty attempts
+type testSolveEstimator struct{}
+
+func (testSolveEstimator) SolveProbability(difficulty *big.Int) float64 {
+	return 1 / float64(difficulty.Int64())
+}
+
+func newTestDifficultyHeader(height uint64, timestamp int64, difficulty int64) *types.BlockHeader {
+	return &types.BlockHeader{
+		Height:          height,
+		CreateTimestamp: big.NewInt(timestamp),
+		Difficulty:      big.NewInt(difficulty),
+	}
+}
+
+func Test_EstimateTimeToBlock(t *testing.T) {
+	genesis := uint64(common.ScdoForkHeight)
+
+	// recent headers in descending order
+	headers := []*types.BlockHeader{
+		newTestDifficultyHeader(genesis+3, 1040, 3000),
+		newTestDifficultyHeader(genesis+2, 1020, 1000),
+		newTestDifficultyHeader(genesis+1, 1000, 1000),
+		newTestDifficultyHeader(genesis, 1, 1000),
+	}
+
+	estimate := estimateTimeToBlock(testSolveEstimator{}, 50, big.NewInt(2000), genesis+4, headers)
+	assert.Equal(t, estimate, &TimeToBlockEstimate{
+		Height:           genesis + 4,
+		Difficulty:       big.NewInt(2000),
+		Hashrate:         50,
+		SolveProbability: 1.0 / 2000,
+		ExpectedAttempts: 2000,
+		ExpectedTime:     40,
+		NetworkHashrate:  100, // 4000 attempts in 40 seconds, the interval after the genesis block is skipped
+		BlockInterval:    20,
+		NetworkShare:     50.0 / 150,
+		BlocksPerDay:     50.0 / 150 * 86400 / 20,
+	})
+
+	// no recent blocks except the genesis block
+	estimate = estimateTimeToBlock(testSolveEstimator{}, 50, big.NewInt(2000), genesis+1, headers[3:])
+	assert.Equal(t, estimate.NetworkHashrate, float64(0))
+	assert.Equal(t, estimate.NetworkShare, float64(1))
+	assert.Equal(t, estimate.BlocksPerDay, 86400.0/40)
+}
+
+func Test_GetDifficultyRange(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	// the total difficulty of the block at height h is h+1
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	blocks := []*types.Block{chain.putBlock(t, nil, "")}
+	for i := 0; i < 4; i++ {
+		blocks = append(blocks, chain.putBlock(t, blocks[i], ""))
+	}
+
+	points, err := getDifficultyRange(chain.bcStore, 4, 0, 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(points), 2)
+	assert.Equal(t, points[0].Hash, blocks[0].HeaderHash)
+	assert.Equal(t, points[0].Difficulty, big.NewInt(1))
+	assert.Equal(t, points[1].Difficulty, big.NewInt(1))
+	assert.Equal(t, points[1].TotalDifficulty, big.NewInt(2))
+
+	// the heights after the head are skipped
+	points, err = getDifficultyRange(chain.bcStore, 4, 3, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(points), 2)
+	assert.Equal(t, points[0].Height, uint64(3))
+	assert.Equal(t, points[0].Difficulty, big.NewInt(1))
+	assert.Equal(t, points[1].TotalDifficulty, big.NewInt(5))
+
+	points, err = getDifficultyRange(chain.bcStore, 4, 5, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(points), 0)
+
+	_, err = getDifficultyRange(chain.bcStore, 4, 2, 1)
+	assert.NotEqual(t, err, nil)
+
+	_, err = getDifficultyRange(chain.bcStore, 4, 0, maxDifficultyRange)
+	assert.Equal(t, err, errDifficultyRangeTooLarge)
+}
@@ -0,0 +1,110 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// maxLogIndexRange is the max number of logs returned by GetLogsByIndex
+const maxLogIndexRange = 10000
+
+var errLogIndexRangeTooLarge = fmt.Errorf("log index range should not exceed %d logs", maxLogIndexRange)
+
+// IndexedLog is a log with its index in the block and its global index along the chain
+type IndexedLog struct {
+	Log            *types.Log
+	TxHash         common.Hash
+	LogIndex       uint64 // index of the log in the block
+	GlobalLogIndex uint64 // index of the log along the chain, which increases monotonically
+}
+
+// BlockLogs is the logs of a block in a log index range
+type BlockLogs struct {
+	BlockHash     common.Hash
+	Height        uint64
+	FirstLogIndex uint64 // global index of the first log in the block
+	LogCount      uint64 // number of logs in the block
+	Logs          []*IndexedLog
+}
+
+// GetLogsByIndex returns the logs of the block whose indices in the block are in the range [from, to],
+// and the indices after the last log of the block are skipped. With the global log index, an indexer
+// could resume from the last ingested log of a block and ingest every log exactly once. If the node is
+// upgraded with an existing chain, the global log index counts from the first block written after the
+// upgrade, and is unavailable for the blocks below.
+func (api *PublicScdoAPI) GetLogsByIndex(blockHash string, from, to uint64) (*BlockLogs, error) {
+	hash, err := common.HexToHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return getLogsByIndex(api.s.chain.GetStore(), hash, from, to)
+}
+
+func getLogsByIndex(bcStore store.BlockchainStore, hash common.Hash, from, to uint64) (*BlockLogs, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid log index range [%d, %d]", from, to)
+	}
+
+	if to-from >= maxLogIndexRange {
+		return nil, errLogIndexRangeTooLarge
+	}
+
+	header, err := bcStore.GetBlockHeader(hash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get block header by hash %v", hash)
+	}
+
+	index, err := bcStore.GetBlockLogIndex(hash)
+	if err != nil {
+		if start, startErr := bcStore.GetLogIndexStartHeight(); startErr == nil && header.Height < start {
+			return nil, fmt.Errorf("global log index unavailable below height %d", start)
+		}
+
+		return nil, errors.NewStackedErrorf(err, "failed to get log index of block %v", hash)
+	}
+
+	receipts, err := bcStore.GetReceiptsByBlockHash(hash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get receipts of block %v", hash)
+	}
+
+	result := &BlockLogs{
+		BlockHash:     hash,
+		Height:        header.Height,
+		FirstLogIndex: index.First,
+		LogCount:      index.Count,
+		Logs:          make([]*IndexedLog, 0),
+	}
+
+	logIndex := uint64(0)
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if logIndex > to {
+				return result, nil
+			}
+
+			if logIndex >= from {
+				result.Logs = append(result.Logs, &IndexedLog{
+					Log:            log,
+					TxHash:         receipt.TxHash,
+					LogIndex:       logIndex,
+					GlobalLogIndex: index.First + logIndex,
+				})
+			}
+
+			logIndex++
+		}
+	}
+
+	return result, nil
+}
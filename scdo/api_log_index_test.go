@@ -0,0 +1,107 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeLogIndexTestBlock writes a block with a receipt of the specified number of logs per tx
+func writeLogIndexTestBlock(t *testing.T, bcStore store.BlockchainStore, parent *types.Block, logs ...int) *types.Block {
+	header := &types.BlockHeader{
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(1),
+	}
+
+	if parent != nil {
+		header.PreviousBlockHash = parent.HeaderHash
+		header.Height = parent.Header.Height + 1
+	}
+
+	block := types.NewBlock(header, nil, nil, nil)
+	data := &store.BlockData{}
+	for i, n := range logs {
+		receipt := &types.Receipt{TxHash: common.BytesToHash([]byte{byte(header.Height), byte(i)})}
+		for j := 0; j < n; j++ {
+			receipt.Logs = append(receipt.Logs, &types.Log{Data: []byte{byte(i), byte(j)}, BlockNumber: header.Height})
+		}
+		data.Receipts = append(data.Receipts, receipt)
+	}
+
+	assert.Equal(t, bcStore.WriteBlock(block, big.NewInt(int64(header.Height+1)), true, data), nil)
+
+	return block
+}
+
+func Test_GetLogsByIndex(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := store.NewBlockchainDatabase(db)
+	parent := writeLogIndexTestBlock(t, bcStore, nil, 2, 0, 1)
+	block := writeLogIndexTestBlock(t, bcStore, parent, 1, 3)
+
+	logs, err := getLogsByIndex(bcStore, parent.HeaderHash, 0, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, logs.Height, uint64(0))
+	assert.Equal(t, logs.FirstLogIndex, uint64(0))
+	assert.Equal(t, logs.LogCount, uint64(3))
+	assert.Equal(t, len(logs.Logs), 3)
+	assert.Equal(t, logs.Logs[2].TxHash, common.BytesToHash([]byte{0, 2}))
+	assert.Equal(t, logs.Logs[2].LogIndex, uint64(2))
+
+	// the global log index follows the parent block
+	logs, err = getLogsByIndex(bcStore, block.HeaderHash, 1, 2)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, logs.FirstLogIndex, uint64(3))
+	assert.Equal(t, logs.LogCount, uint64(4))
+	assert.Equal(t, len(logs.Logs), 2)
+	assert.Equal(t, logs.Logs[0].Log.Data, []byte{1, 0})
+	assert.Equal(t, logs.Logs[0].LogIndex, uint64(1))
+	assert.Equal(t, logs.Logs[0].GlobalLogIndex, uint64(4))
+	assert.Equal(t, logs.Logs[1].GlobalLogIndex, uint64(5))
+
+	logs, err = getLogsByIndex(bcStore, block.HeaderHash, 4, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(logs.Logs), 0)
+
+	_, err = getLogsByIndex(bcStore, block.HeaderHash, 2, 1)
+	assert.NotEqual(t, err, nil)
+
+	_, err = getLogsByIndex(bcStore, block.HeaderHash, 0, maxLogIndexRange)
+	assert.Equal(t, err, errLogIndexRangeTooLarge)
+
+	_, err = getLogsByIndex(bcStore, common.StringToHash("unknown"), 0, 1)
+	assert.NotEqual(t, err, nil)
+}
+
+func Test_GetLogsByIndex_BelowStartHeight(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	bcStore := store.NewBlockchainDatabase(db)
+
+	// the blocks written before the upgrade have no log index
+	old := types.NewBlock(&types.BlockHeader{Height: 3, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}, nil, nil, nil)
+	assert.Equal(t, bcStore.PutBlock(old, big.NewInt(4), true), nil)
+
+	parent := types.NewBlock(&types.BlockHeader{Height: 4, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(2)}, nil, nil, nil)
+	block := writeLogIndexTestBlock(t, bcStore, parent, 1)
+
+	logs, err := getLogsByIndex(bcStore, block.HeaderHash, 0, 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, logs.FirstLogIndex, uint64(0))
+
+	_, err = getLogsByIndex(bcStore, old.HeaderHash, 0, 1)
+	assert.Equal(t, err.Error(), "global log index unavailable below height 5")
+}
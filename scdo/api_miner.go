@@ -8,8 +8,10 @@ package scdo
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
 	"github.com/scdoproject/go-scdo/miner"
 )
 
@@ -25,6 +27,10 @@ func NewPrivateMinerAPI(s *ScdoService) *PrivateMinerAPI {
 
 // Start API is used to start the miner with the given number of threads.
 func (api *PrivateMinerAPI) Start() (bool, error) {
+	if api.s.IsFollower() {
+		return false, errFollowerMode
+	}
+
 	if api.s.miner.IsMining() {
 		return true, miner.ErrMinerIsRunning
 	}
@@ -34,11 +40,13 @@ func (api *PrivateMinerAPI) Start() (bool, error) {
 
 // Status API is used to view the miner's status.
 func (api *PrivateMinerAPI) Status() (string, error) {
-	if api.s.miner.IsMining() {
-		return "Running", nil
-	}
+	return api.s.minerStatus(), nil
+}
 
-	return "Stopped", nil
+// GetWarmupStatus API returns the decision of the miner warmup after the last sync, and the
+// head stability and the peer agreement checked by the warmup.
+func (api *PrivateMinerAPI) GetWarmupStatus() miner.WarmupStatus {
+	return api.s.miner.GetWarmupStatus()
 }
 
 // Stop API is used to stop the miner.
@@ -88,6 +96,52 @@ func (api *PrivateMinerAPI) SetCoinbase(coinbaseStr string) (bool, error) {
 	return true, nil
 }
 
+// SetCoinbaseWeight API is used to set the weight and the daily quota of the coinbase in pool mode,
+// the coinbase is added to the coinbase list if not exists.
+func (api *PrivateMinerAPI) SetCoinbaseWeight(coinbaseStr string, weight, quota uint64) (bool, error) {
+	coinbase, err := common.HexToAddress(coinbaseStr)
+	if err != nil {
+		return false, err
+	}
+	if common.IsShardEnabled() && coinbase.Shard() != common.LocalShardNumber {
+		return false, fmt.Errorf("invalid shard number: coinbase shard number is [%v], but local shard number is [%v]", coinbase.Shard(), common.LocalShardNumber)
+	}
+
+	if err = api.s.miner.SetCoinbaseWeight(coinbase, weight, quota); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetCoinbaseWeights API is used to get the weights, the daily quotas and the blocks mined today of the coinbase list in pool mode.
+func (api *PrivateMinerAPI) GetCoinbaseWeights() []miner.CoinbaseWeight {
+	return api.s.miner.GetCoinbaseWeights()
+}
+
+// SetExtra API is used to set the extra data in the mined block header for governance signaling.
+// The extra is a hex encoded bitfield with 0x prefix, or a plain string otherwise.
+func (api *PrivateMinerAPI) SetExtra(extra string) (bool, error) {
+	data := []byte(extra)
+	if strings.HasPrefix(extra, "0x") {
+		var err error
+		if data, err = hexutil.HexToBytes(extra); err != nil {
+			return false, fmt.Errorf("invalid hex extra data, %s", err)
+		}
+	}
+
+	if err := api.s.miner.SetExtra(data); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetExtra API is used to get the hex encoded extra data in the mined block header.
+func (api *PrivateMinerAPI) GetExtra() (string, error) {
+	return hexutil.BytesToHex(api.s.miner.GetExtra()), nil
+}
+
 // GetCoinbase API is used to get the coinbase.
 func (api *PrivateMinerAPI) GetCoinbase() (string, error) {
 	return api.s.miner.GetCoinbase().Hex(), nil
@@ -96,3 +150,29 @@ func (api *PrivateMinerAPI) GetCoinbase() (string, error) {
 func (api *PrivateMinerAPI) GetTarget() string {
 	return api.s.miner.GetTaskDifficulty().String()
 }
+
+// LeaseWork API leases a range of size nonces of the current task to the worker in the distributed solo mining,
+// 0 means the default size. The node should be in pool mode, so that the nonce space is mined by the workers only.
+func (api *PrivateMinerAPI) LeaseWork(worker string, size uint64) (*miner.WorkLease, error) {
+	return api.s.miner.LeaseWork(worker, size)
+}
+
+// SubmitLeaseNonce API submits the nonce found in the range of the lease to generate the final block.
+func (api *PrivateMinerAPI) SubmitLeaseNonce(id uint64, nonce uint64) (bool, error) {
+	if err := api.s.miner.SubmitLeaseWork(id, nonce); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetWorkLeases API returns the nonce ranges of the current task leased to the workers.
+func (api *PrivateMinerAPI) GetWorkLeases() []*miner.WorkLease {
+	return api.s.miner.GetWorkLeases()
+}
+
+// GetOrphanStats API returns the number of the blocks found by the local miner which failed to become
+// canonical, the orphan rate and the recent orphaned blocks.
+func (api *PrivateMinerAPI) GetOrphanStats() *OrphanStats {
+	return api.s.orphanTracker.stats()
+}
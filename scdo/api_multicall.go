@@ -0,0 +1,104 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	api2 "github.com/scdoproject/go-scdo/api"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+var errNoMulticall = errors.New("no call")
+
+// MulticallRequest is a read-only call of a contract in the multicall
+type MulticallRequest struct {
+	Contract string `json:"contract"`
+	Payload  string `json:"payload"`
+}
+
+// MulticallResult is the result of a call in the multicall, either the receipt or the error of the call
+type MulticallResult struct {
+	Receipt map[string]interface{} `json:"receipt,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// Multicall executes the read-only calls on the statedb of the given block height in a single request, and
+// the results are returned in the order of the calls. Every call is executed on a fresh statedb of the block,
+// so that the changes of a call, e.g. the fee and nonce of the caller, are not seen by the next call. A failed
+// call does not abort the other calls, and all the calls share the evm timeout of a single call.
+func (api *PublicScdoAPI) Multicall(ctx context.Context, calls []MulticallRequest, height int64) ([]*MulticallResult, error) {
+	if len(calls) == 0 {
+		return nil, errNoMulticall
+	}
+
+	if len(calls) > maxSizeLimit {
+		return nil, fmt.Errorf("too many calls, max is %d", maxSizeLimit)
+	}
+
+	block, _, from, err := api.newCallStatedb(height)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := api.evmTimeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	newStatedb := func() (*state.Statedb, error) {
+		return api.newCallerStatedb(block.Header.StateHash, from)
+	}
+
+	coinbase := api.s.miner.GetCoinbase()
+	apply := func(call MulticallRequest, index int, statedb *state.Statedb) (*types.Receipt, error) {
+		tx, err := api.newCallTransaction(from, call.Contract, call.Payload)
+		if err != nil {
+			return nil, err
+		}
+
+		return api.applyTransaction(ctx, tx, index, coinbase, statedb, block.Header)
+	}
+
+	results, err := multicall(ctx, calls, newStatedb, apply)
+	if err == context.DeadlineExceeded {
+		return nil, fmt.Errorf("multicall aborted (timeout = %v)", timeout)
+	}
+
+	return results, err
+}
+
+// multicall applies the calls one by one, each on a new statedb, and stops once the request is cancelled
+func multicall(ctx context.Context, calls []MulticallRequest, newStatedb func() (*state.Statedb, error),
+	apply func(call MulticallRequest, index int, statedb *state.Statedb) (*types.Receipt, error)) ([]*MulticallResult, error) {
+	results := make([]*MulticallResult, 0, len(calls))
+	for i, call := range calls {
+		statedb, err := newStatedb()
+		if err != nil {
+			return nil, err
+		}
+
+		receipt, err := apply(call, i, statedb)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		result := &MulticallResult{}
+		if err == nil {
+			result.Receipt, err = api2.PrintableReceipt(receipt)
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,91 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus/pow"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database/memorydb"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/miner"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMulticallAPI(t *testing.T) (*PublicScdoAPI, func()) {
+	db, dispose := memorydb.NewTestDatabase()
+	bcStore := store.NewBlockchainDatabase(db)
+	genesis := core.GetGenesis(core.NewGenesisInfo(nil, 1, 1, big.NewInt(0), types.PowConsensus, nil))
+	assert.Equal(t, genesis.InitializeAndValidate(bcStore, db), nil)
+
+	chain, err := core.NewBlockchain(bcStore, db, "", pow.NewEngine(1), nil, -1)
+	assert.Equal(t, err, nil)
+
+	s := &ScdoService{log: log.GetLogger("scdo"), chain: chain, accountStateDB: db}
+	s.miner = miner.NewMiner(*crypto.MustGenerateShardAddress(1), nil, nil, nil, nil, false)
+
+	return NewPublicScdoAPI(s), dispose
+}
+
+func Test_Multicall(t *testing.T) {
+	api, dispose := newTestMulticallAPI(t)
+	defer dispose()
+
+	block, _, from, err := api.newCallStatedb(-1)
+	assert.Equal(t, err, nil)
+
+	to := crypto.MustGenerateShardAddress(1).Hex()
+	calls := []MulticallRequest{{Contract: to, Payload: "0x01"}, {Contract: "0x", Payload: "0x01"}, {Contract: to, Payload: "0x02"}}
+
+	newStatedb := func() (*state.Statedb, error) {
+		return api.newCallerStatedb(block.Header.StateHash, from)
+	}
+
+	apply := func(call MulticallRequest, index int, statedb *state.Statedb) (*types.Receipt, error) {
+		// the fee and nonce of the previous calls are not seen
+		assert.Equal(t, statedb.GetBalance(from), common.ScdoToWen)
+		assert.Equal(t, statedb.GetNonce(from), uint64(0))
+
+		tx, err := api.newCallTransaction(from, call.Contract, call.Payload)
+		if err != nil {
+			return nil, err
+		}
+
+		receipt, err := api.s.chain.ApplyTransactionWithContext(context.Background(), tx, index, api.s.miner.GetCoinbase(), statedb, block.Header)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, receipt.TotalFee > 0, true)
+		assert.Equal(t, statedb.GetBalance(from).Cmp(common.ScdoToWen) < 0, true)
+
+		return receipt, nil
+	}
+
+	results, err := multicall(context.Background(), calls, newStatedb, apply)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(results), 3)
+	assert.Equal(t, results[0].Error, "")
+	assert.Equal(t, results[1].Receipt == nil, true)
+	assert.Equal(t, len(results[1].Error) > 0, true)
+	assert.Equal(t, results[2].Receipt["totalFee"], results[0].Receipt["totalFee"])
+
+	results, err = api.Multicall(context.Background(), calls, -1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(results), 3)
+	assert.Equal(t, results[2].Receipt["totalFee"], results[0].Receipt["totalFee"])
+
+	// aborted if the request is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = multicall(ctx, calls, newStatedb, apply)
+	assert.Equal(t, err, context.Canceled)
+}
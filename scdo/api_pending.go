@@ -0,0 +1,63 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+
+	api2 "github.com/scdoproject/go-scdo/api"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+var errNoPendingBlock = errors.New("no pending block, the miner has no task on the current head")
+
+// GetPendingBlock returns the block being mined on the current head, including the txs and debts chosen
+// by the miner and the projected receipts if fulltx is true. The header hash changes when the block is sealed.
+func (api *PublicScdoAPI) GetPendingBlock(fulltx bool) (map[string]interface{}, error) {
+	task := api.s.miner.GetWorkTask()
+	if task == nil {
+		return nil, errNoPendingBlock
+	}
+
+	block, receipts := task.PendingBlock()
+	head := api.s.chain.CurrentBlock()
+	totalDifficulty, err := api.s.chain.GetStore().GetBlockTotalDifficulty(head.HeaderHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpcOutputPendingBlock(block, receipts, head, totalDifficulty, fulltx)
+}
+
+// rpcOutputPendingBlock converts the pending block to the RPC output, the block of a stale task
+// which is not on the current head is not returned.
+func rpcOutputPendingBlock(block *types.Block, receipts []*types.Receipt, head *types.Block, headTD *big.Int, fulltx bool) (map[string]interface{}, error) {
+	if !block.Header.PreviousBlockHash.Equal(head.HeaderHash) {
+		return nil, errNoPendingBlock
+	}
+
+	totalDifficulty := new(big.Int).Add(headTD, block.Header.Difficulty)
+	output, err := api2.PrintableOutputBlock(block, fulltx, totalDifficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	output["pending"] = true
+
+	if fulltx {
+		outputReceipts := make([]map[string]interface{}, len(receipts))
+		for i, receipt := range receipts {
+			if outputReceipts[i], err = api2.PrintableReceipt(receipt); err != nil {
+				return nil, err
+			}
+		}
+
+		output["receipts"] = outputReceipts
+	}
+
+	return output, nil
+}
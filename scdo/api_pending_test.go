@@ -0,0 +1,50 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RpcOutputPendingBlock(t *testing.T) {
+	head := types.NewBlock(&types.BlockHeader{Height: 10, Difficulty: big.NewInt(5), CreateTimestamp: big.NewInt(1)}, nil, nil, nil)
+
+	header := &types.BlockHeader{
+		PreviousBlockHash: head.HeaderHash,
+		Height:            11,
+		Difficulty:        big.NewInt(3),
+		CreateTimestamp:   big.NewInt(2),
+	}
+	tx := types.NewTestTxDetail(1, 1, 1)
+	receipts := []*types.Receipt{{TxHash: tx.Hash, UsedGas: 21000}}
+	block := types.NewBlock(header, []*types.Transaction{tx}, receipts, nil)
+
+	// hashes only
+	output, err := rpcOutputPendingBlock(block, receipts, head, big.NewInt(100), false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, output["pending"], true)
+	assert.Equal(t, output["hash"], block.HeaderHash.Hex())
+	assert.Equal(t, output["totalDifficulty"], big.NewInt(103))
+	assert.Equal(t, output["transactions"], []interface{}{tx.Hash.Hex()})
+	assert.Equal(t, output["receipts"], nil)
+
+	// full txs with the projected receipts
+	output, err = rpcOutputPendingBlock(block, receipts, head, big.NewInt(100), true)
+	assert.Equal(t, err, nil)
+	outputReceipts := output["receipts"].([]map[string]interface{})
+	assert.Equal(t, len(outputReceipts), 1)
+	assert.Equal(t, outputReceipts[0]["txhash"], tx.Hash.Hex())
+	assert.Equal(t, outputReceipts[0]["usedGas"], uint64(21000))
+
+	// stale task not on the current head
+	newHead := types.NewBlock(&types.BlockHeader{Height: 11, Difficulty: big.NewInt(5), CreateTimestamp: big.NewInt(3)}, nil, nil, nil)
+	_, err = rpcOutputPendingBlock(block, receipts, newHead, big.NewInt(105), false)
+	assert.Equal(t, err, errNoPendingBlock)
+}
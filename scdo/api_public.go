@@ -6,6 +6,7 @@
 package scdo
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"strings"
@@ -16,8 +17,10 @@ import (
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/consensus"
 	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/svm"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/crypto"
 )
@@ -29,6 +32,14 @@ type PublicScdoAPI struct {
 
 const maxSizeLimit = 64
 
+const (
+	// defaultEVMTimeout is the default wall-clock timeout of an evm execution in rpc calls
+	defaultEVMTimeout = 5 * time.Second
+
+	// defaultGasCap is the default max gas limit of an evm execution in rpc calls
+	defaultGasCap = uint64(25000000)
+)
+
 // NewPublicScodAPI creates a new PublicScdoAPI object for rpc service.
 func NewPublicScdoAPI(s *ScdoService) *PublicScdoAPI {
 	return &PublicScdoAPI{s}
@@ -36,7 +47,7 @@ func NewPublicScdoAPI(s *ScdoService) *PublicScdoAPI {
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block.
-func (api *PublicScdoAPI) EstimateGas(tx *types.Transaction) (uint64, error) {
+func (api *PublicScdoAPI) EstimateGas(ctx context.Context, tx *types.Transaction) (uint64, error) {
 	// Get the block by block height, if the height is less than zero, get the current block.
 	block, err := getBlock(api.s.chain, -1)
 	if err != nil {
@@ -49,9 +60,16 @@ func (api *PublicScdoAPI) EstimateGas(tx *types.Transaction) (uint64, error) {
 		return 0, err
 	}
 
+	// Cap the gas limit to avoid the runaway evm execution
+	if gasCap := api.gasCap(); tx.Data.GasLimit > gasCap {
+		capped := *tx
+		capped.Data.GasLimit = gasCap
+		tx = &capped
+	}
+
 	coinbase := api.s.miner.GetCoinbase()
 	// Get the transaction receipt, and the fee give to the miner coinbase
-	receipt, err := api.s.chain.ApplyTransaction(tx,-1, coinbase, statedb, block.Header)
+	receipt, err := api.applyTransaction(ctx, tx, -1, coinbase, statedb, block.Header)
 	if err != nil {
 		return 0, err
 	}
@@ -65,12 +83,7 @@ func (api *PublicScdoAPI) EstimateGas(tx *types.Transaction) (uint64, error) {
 func (api *PublicScdoAPI) GetInfo() (api2.GetMinerInfo2, error) {
 	block := api.s.chain.CurrentBlock()
 
-	var status string
-	if api.s.miner.IsMining() {
-		status = "Running"
-	} else {
-		status = "Stopped"
-	}
+	status := api.s.minerStatus()
 	p1 := api.s.scdoProtocol.peerSet.getPeerCountByShard(1)
 	p2 := api.s.scdoProtocol.peerSet.getPeerCountByShard(2)
 	p3 := api.s.scdoProtocol.peerSet.getPeerCountByShard(3)
@@ -91,55 +104,124 @@ func (api *PublicScdoAPI) GetInfo() (api2.GetMinerInfo2, error) {
 
 // Call is to execute a given transaction on a statedb of a given block height.
 // It does not affect this statedb and blockchain and is useful for executing and retrieve values.
-func (api *PublicScdoAPI) Call(contract, payload string, height int64) (map[string]interface{}, error) {
-	contractAddr, err := common.HexToAddress(contract)
+func (api *PublicScdoAPI) Call(ctx context.Context, contract, payload string, height int64) (map[string]interface{}, error) {
+	block, statedb, from, err := api.newCallStatedb(height)
 	if err != nil {
-		return nil, fmt.Errorf("invalid contract address: %s", err)
+		return nil, err
 	}
 
-	msg, err := hexutil.HexToBytes(payload)
+	tx, err := api.newCallTransaction(from, contract, payload)
 	if err != nil {
-		return nil, fmt.Errorf("invalid payload, %s", err)
+		return nil, err
 	}
 
-	// Get the block by block height, if the height is less than zero, get the current block.
-	block, err := getBlock(api.s.chain, height)
+	// Get the transaction receipt, and the fee give to the miner coinbase
+	receipt, err := api.applyTransaction(ctx, tx, 0, api.s.miner.GetCoinbase(), statedb, block.Header)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the statedb by the given block height
-	statedb, err := state.NewStatedb(block.Header.StateHash, api.s.accountStateDB)
+	// Format the receipt
+	result, err := api2.PrintableReceipt(receipt)
 	if err != nil {
 		return nil, err
 	}
 
+	return result, nil
+}
+
+// newCallStatedb returns the block of the given height and its statedb, in which a caller account
+// of the coinbase shard is funded to pay the fee of the calls.
+func (api *PublicScdoAPI) newCallStatedb(height int64) (*types.Block, *state.Statedb, common.Address, error) {
+	// Get the block by block height, if the height is less than zero, get the current block.
+	block, err := getBlock(api.s.chain, height)
+	if err != nil {
+		return nil, nil, common.EmptyAddress, err
+	}
+
+	// Get the statedb by the given block height
 	coinbase := api.s.miner.GetCoinbase()
 	from := crypto.MustGenerateShardAddress(coinbase.Shard())
-	statedb.CreateAccount(*from)
-	statedb.SetBalance(*from, common.ScdoToWen)
+	statedb, err := api.newCallerStatedb(block.Header.StateHash, *from)
+	if err != nil {
+		return nil, nil, common.EmptyAddress, err
+	}
+
+	return block, statedb, *from, nil
+}
+
+// newCallerStatedb returns the statedb of the given root, in which the caller is funded to pay the fee of the calls.
+func (api *PublicScdoAPI) newCallerStatedb(root common.Hash, from common.Address) (*state.Statedb, error) {
+	statedb, err := state.NewStatedb(root, api.s.accountStateDB)
+	if err != nil {
+		return nil, err
+	}
+
+	statedb.CreateAccount(from)
+	statedb.SetBalance(from, common.ScdoToWen)
+
+	return statedb, nil
+}
+
+// newCallTransaction creates the tx of the caller to call the contract with the hex payload
+func (api *PublicScdoAPI) newCallTransaction(from common.Address, contract, payload string) (*types.Transaction, error) {
+	contractAddr, err := common.HexToAddress(contract)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %s", err)
+	}
+
+	msg, err := hexutil.HexToBytes(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload, %s", err)
+	}
 
 	amount, price, nonce := big.NewInt(0), big.NewInt(1), uint64(1)
-	// gasLimit = balance / fee
+	// gasLimit = balance / fee, and capped to avoid the runaway evm execution
 	gasLimit := common.ScdoToWen.Uint64()
-	tx, err := types.NewMessageTransaction(*from, contractAddr, amount, price, gasLimit, nonce, msg)
+	if gasCap := api.gasCap(); gasLimit > gasCap {
+		gasLimit = gasCap
+	}
+	tx, err := types.NewMessageTransaction(from, contractAddr, amount, price, gasLimit, nonce, msg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %s", err)
 	}
 
-	// Get the transaction receipt, and the fee give to the miner coinbase
-	receipt, err := api.s.chain.ApplyTransaction(tx, 0, coinbase, statedb, block.Header)
-	if err != nil {
-		return nil, err
+	return tx, nil
+}
+
+// applyTransaction applies the tx with the evm timeout, the execution is aborted
+// once the timeout elapsed or the rpc request is cancelled.
+func (api *PublicScdoAPI) applyTransaction(ctx context.Context, tx *types.Transaction, txIndex int, coinbase common.Address,
+	statedb *state.Statedb, header *types.BlockHeader) (*types.Receipt, error) {
+	timeout := api.evmTimeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	receipt, err := api.s.chain.ApplyTransactionWithContext(ctx, tx, txIndex, coinbase, statedb, header)
+	if errors.IsOrContains(err, svm.ErrExecutionAborted) {
+		api.s.log.Warn("evm execution of rpc call aborted, tx hash %s, timeout %v, %s", tx.Hash.Hex(), timeout, err)
+		return nil, fmt.Errorf("evm execution aborted (timeout = %v)", timeout)
 	}
 
-	// Format the receipt
-	result, err := api2.PrintableReceipt(receipt)
-	if err != nil {
-		return nil, err
+	return receipt, err
+}
+
+// evmTimeout returns the wall-clock timeout of an evm execution in rpc calls
+func (api *PublicScdoAPI) evmTimeout() time.Duration {
+	if timeout := api.s.getRPCConfig().EVMTimeout; timeout > 0 {
+		return time.Duration(timeout) * time.Millisecond
 	}
 
-	return result, nil
+	return defaultEVMTimeout
+}
+
+// gasCap returns the max gas limit of an evm execution in rpc calls
+func (api *PublicScdoAPI) gasCap() uint64 {
+	if gasCap := api.s.getRPCConfig().GasCap; gasCap > 0 {
+		return gasCap
+	}
+
+	return defaultGasCap
 }
 
 // GetLogs Get the logs that satisfies the condition in the block by height and filter
@@ -221,6 +303,76 @@ func (api *PublicScdoAPI) GetShardNum(account common.Address) (uint, error) {
 	}
 }
 
+// IsDebtSpent returns whether the debt is already applied in the canonical chain, and the block which applies it.
+func (api *PublicScdoAPI) IsDebtSpent(debtHash string) (map[string]interface{}, error) {
+	hash, err := common.HexToHash(debtHash)
+	if err != nil {
+		return nil, err
+	}
+
+	spent, err := api.s.chain.GetSpentDebt(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if spent == nil {
+		return map[string]interface{}{"spent": false}, nil
+	}
+
+	return map[string]interface{}{
+		"spent":       true,
+		"blockHash":   spent.BlockHash.Hex(),
+		"blockHeight": spent.BlockHeight,
+	}, nil
+}
+
+// GetCirculatingSupply returns the circulating supply of the local shard at the HEAD block,
+// which is computed from the genesis allocation and the block reward schedule.
+func (api *PublicScdoAPI) GetCirculatingSupply() (*api2.CirculatingSupply, error) {
+	head := api.s.chain.CurrentHeader()
+	genesis := api.s.chain.Genesis().Header.Height
+
+	mined := big.NewInt(0)
+	if head.Height > genesis {
+		mined.Sub(consensus.GetTotalReward(head.Height), consensus.GetTotalReward(genesis))
+	}
+
+	allocation := api.s.genesis.GetAllocation()
+
+	return &api2.CirculatingSupply{
+		Height:            head.Height,
+		Shard:             common.LocalShardNumber,
+		GenesisAllocation: allocation,
+		MinedReward:       mined,
+		CirculatingSupply: new(big.Int).Add(allocation, mined),
+	}, nil
+}
+
+// GetShardHeads returns the best-known chain head of each shard observed from the peers,
+// the height is resolved from the local chain or the light chain of the shard if the head is known.
+func (api *PublicScdoAPI) GetShardHeads() ([]*ShardHead, error) {
+	if api.s.scdoProtocol == nil {
+		return nil, errors.New("scdo protocol not started")
+	}
+
+	heads := api.s.scdoProtocol.shardHeads.list()
+	reader, _ := api.s.debtVerifier.(shardHeaderReader)
+	for _, head := range heads {
+		var header *types.BlockHeader
+		if head.Shard == common.LocalShardNumber {
+			header, _ = api.s.chain.GetStore().GetBlockHeader(head.Hash)
+		} else if reader != nil {
+			header, _ = reader.GetBlockHeader(head.Shard, head.Hash)
+		}
+
+		if header != nil {
+			head.Height = header.Height
+		}
+	}
+
+	return heads, nil
+}
+
 // GetWork get the work needed to be done
 func (api *PublicScdoAPI) GetWork() map[string]interface{} {
 	return api.s.miner.GetWork()
@@ -190,7 +190,7 @@ func Test_Call(t *testing.T) {
 
 	// Verify the result = 5
 	result := make(map[string]interface{})
-	result, err = api.Call(contractAddress.Hex(), payload, -1)
+	result, err = api.Call(context.Background(), contractAddress.Hex(), payload, -1)
 	assert.Equal(t, err, nil)
 	assert.Equal(t, result["result"], "0x0000000000000000000000000000000000000000000000000000000000000005")
 
@@ -205,21 +205,21 @@ func Test_Call(t *testing.T) {
 	_ = sendTx(t, api, statedbCur, callContractTx)
 
 	// Verify the result = 23
-	result, err = api.Call(contractAddress.Hex(), payload, -1)
+	result, err = api.Call(context.Background(), contractAddress.Hex(), payload, -1)
 	assert.Equal(t, err, nil)
 	assert.Equal(t, result["result"], "0x0000000000000000000000000000000000000000000000000000000000000017")
 
 	// Verify the history result = 5
 	height, err := api2.NewPublicScdoAPI(NewScdoBackend(api.s)).GetBlockHeight()
 	assert.Equal(t, err, nil)
-	result, err = api.Call(contractAddress.Hex(), payload, int64(height-1))
+	result, err = api.Call(context.Background(), contractAddress.Hex(), payload, int64(height-1))
 	assert.Equal(t, err, nil)
 	assert.Equal(t, result["result"], "0x0000000000000000000000000000000000000000000000000000000000000005")
 
 	// Verify the invalid contractAddress and payload
-	result, err = api.Call("contractAddress.Hex()", payload, -1)
+	result, err = api.Call(context.Background(), "contractAddress.Hex()", payload, -1)
 	assert.Equal(t, err == nil, false)
-	result, err = api.Call(contractAddress.Hex(), "payload", -1)
+	result, err = api.Call(context.Background(), contractAddress.Hex(), "payload", -1)
 	assert.Equal(t, err == nil, false)
 }
 
@@ -247,7 +247,7 @@ func Test_EstimateGas(t *testing.T) {
 	to1 := crypto.MustGenerateShardAddress(from.Shard())
 	transferCSTx, err1 := types.NewTransaction(from, *to1, big.NewInt(1), big.NewInt(1), statedb.GetNonce(from))
 	assert.NoError(t, err1)
-	estimateGas1, err2 := api.EstimateGas(transferCSTx)
+	estimateGas1, err2 := api.EstimateGas(context.Background(), transferCSTx)
 	assert.NoError(t, err2)
 	assert.Equal(t, estimateGas1, types.TransferAmountIntrinsicGas)
 
@@ -258,7 +258,7 @@ func Test_EstimateGas(t *testing.T) {
 	}
 	transferDSTx, err3 := types.NewTransaction(from, *to2, big.NewInt(1), big.NewInt(1), statedb.GetNonce(from))
 	assert.NoError(t, err3)
-	estimateGas2, err4 := api.EstimateGas(transferDSTx)
+	estimateGas2, err4 := api.EstimateGas(context.Background(), transferDSTx)
 	assert.NoError(t, err4)
 	assert.Equal(t, estimateGas2, types.CrossShardTotalGas)
 
@@ -267,7 +267,7 @@ func Test_EstimateGas(t *testing.T) {
 	assert.NoError(t, err5)
 	createContractTx, err6 := types.NewContractTransaction(from, big.NewInt(0), big.NewInt(1), 500000, 0, bytecode)
 	assert.NoError(t, err6)
-	estimateGas3, err7 := api.EstimateGas(createContractTx)
+	estimateGas3, err7 := api.EstimateGas(context.Background(), createContractTx)
 	assert.NoError(t, err7)
 	assert.NotZero(t, estimateGas3)
 
@@ -276,7 +276,7 @@ func Test_EstimateGas(t *testing.T) {
 	assert.NoError(t, err8)
 	callContractTx, err9 := types.NewMessageTransaction(from, createContractTx.Data.To, big.NewInt(0), big.NewInt(1), 500000, 0, bytecode1)
 	assert.NoError(t, err9)
-	estimateGas4, err10 := api.EstimateGas(callContractTx)
+	estimateGas4, err10 := api.EstimateGas(context.Background(), callContractTx)
 	assert.NoError(t, err10)
 	assert.NotZero(t, estimateGas4)
 }
@@ -0,0 +1,48 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// RawBlock is the block with the txs, debts and receipts, whose header hash and merkle roots
+// could be verified by the clients without running a full node.
+type RawBlock struct {
+	Block    *types.Block
+	Receipts []*types.Receipt
+}
+
+// GetRawBlock returns the raw block of the hash with its receipts, the receipts are nil if pruned
+func (api *PublicScdoAPI) GetRawBlock(hash string) (*RawBlock, error) {
+	blockHash, err := common.HexToHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return getRawBlock(api.s.chain.GetStore(), blockHash)
+}
+
+func getRawBlock(bcStore store.BlockchainStore, hash common.Hash) (*RawBlock, error) {
+	block, err := bcStore.GetBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// the receipts of the old blocks may be pruned for the disk quota or the retention policy
+	if prunedHeight, err := bcStore.GetReceiptsPrunedHeight(); err == nil && block.Header.Height <= prunedHeight {
+		return &RawBlock{block, nil}, nil
+	}
+
+	receipts, err := bcStore.GetReceiptsByBlockHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawBlock{block, receipts}, nil
+}
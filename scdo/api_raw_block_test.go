@@ -0,0 +1,51 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetRawBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rawblock")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.NewLevelDB(dir)
+	assert.Equal(t, err, nil)
+	defer db.Close()
+
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	genesis := chain.putBlock(t, nil, "")
+	block := chain.putBlock(t, genesis, "")
+	assert.Equal(t, chain.bcStore.PutReceipts(block.HeaderHash, []*types.Receipt{{UsedGas: 1}}), nil)
+
+	raw, err := getRawBlock(chain.bcStore, block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, raw.Block.HeaderHash, block.HeaderHash)
+	assert.Equal(t, len(raw.Receipts), 1)
+	assert.Equal(t, raw.Receipts[0].UsedGas, uint64(1))
+
+	// the receipts are nil after pruned
+	_, err = chain.bcStore.PruneBlockData([]common.Hash{block.HeaderHash}, block.Header.Height)
+	assert.Equal(t, err, nil)
+	raw, err = getRawBlock(chain.bcStore, block.HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, raw.Block.HeaderHash, block.HeaderHash)
+	assert.Equal(t, raw.Receipts == nil, true)
+
+	// block not found
+	_, err = getRawBlock(chain.bcStore, common.StringToHash("unknown"))
+	assert.Equal(t, err != nil, true)
+}
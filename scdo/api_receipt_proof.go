@@ -0,0 +1,181 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+
+	api2 "github.com/scdoproject/go-scdo/api"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/trie"
+)
+
+var (
+	errReceiptsPruned   = store.ErrReceiptsPruned
+	errProofHeaderHash  = errors.New("header hash mismatch")
+	errProofKeyMissing  = errors.New("key is not proven by the root")
+	errProofReceiptHash = errors.New("receipt is not of the tx")
+)
+
+// ProofNode is a node of the merkle trie proof, the hash and the encoded node are hex encoded
+type ProofNode struct {
+	Hash string `json:"hash"`
+	Node string `json:"node"`
+}
+
+// ReceiptProof is the evidence that a tx and its receipt are packed in a block, the merkle proofs tie the tx
+// and receipt to the TxHash and ReceiptHash roots of the header, and the header to the block hash. The tx and
+// receipt are encoded in the leaf nodes, so the proof could be archived and verified without the node.
+type ReceiptProof struct {
+	BlockHash    common.Hash            `json:"blockHash"`
+	Header       *types.BlockHeader     `json:"header"`
+	TxHash       common.Hash            `json:"txHash"`
+	TxIndex      uint                   `json:"txIndex"`
+	Receipt      map[string]interface{} `json:"receipt"`
+	TxProof      []ProofNode            `json:"txProof"`
+	ReceiptProof []ProofNode            `json:"receiptProof"`
+}
+
+// GetReceiptProof returns the receipt of the packed tx with the block header, and the merkle proofs of
+// the tx and receipt against the roots of the header.
+func (api *PublicScdoAPI) GetReceiptProof(txHash string) (*ReceiptProof, error) {
+	hash, err := common.HexToHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return getReceiptProof(api.s.chain.GetStore(), hash)
+}
+
+func getReceiptProof(bcStore store.BlockchainStore, txHash common.Hash) (*ReceiptProof, error) {
+	txIndex, err := bcStore.GetTxIndex(txHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get tx index by hash %v", txHash.Hex())
+	}
+
+	block, err := bcStore.GetBlock(txIndex.BlockHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get block by hash %v", txIndex.BlockHash.Hex())
+	}
+
+	// the receipts of the old blocks may be pruned for the disk quota or the retention policy
+	if prunedHeight, err := bcStore.GetReceiptsPrunedHeight(); err == nil && block.Header.Height <= prunedHeight {
+		return nil, errReceiptsPruned
+	}
+
+	receipts, err := bcStore.GetReceiptsByBlockHash(txIndex.BlockHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get receipts by block hash %v", txIndex.BlockHash.Hex())
+	}
+
+	if int(txIndex.Index) >= len(receipts) || int(txIndex.Index) >= len(block.Transactions) {
+		return nil, fmt.Errorf("tx index %d out of range", txIndex.Index)
+	}
+
+	txProof, err := types.GetTxTrie(block.Transactions).GetProof(txHash.Bytes())
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to get tx trie proof")
+	}
+
+	receiptProof, err := types.GetReceiptTrie(receipts).GetProof(txHash.Bytes())
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to get receipt trie proof")
+	}
+
+	receipt, err := api2.PrintableReceipt(receipts[txIndex.Index])
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReceiptProof{
+		BlockHash:    block.HeaderHash,
+		Header:       block.Header,
+		TxHash:       txHash,
+		TxIndex:      txIndex.Index,
+		Receipt:      receipt,
+		TxProof:      toProofNodes(txProof),
+		ReceiptProof: toProofNodes(receiptProof),
+	}, nil
+}
+
+func toProofNodes(proof map[string][]byte) []ProofNode {
+	nodes := make([]ProofNode, 0, len(proof))
+	for hash, node := range proof {
+		nodes = append(nodes, ProofNode{hexutil.BytesToHex([]byte(hash)), hexutil.BytesToHex(node)})
+	}
+
+	return nodes
+}
+
+func fromProofNodes(nodes []ProofNode) (map[string][]byte, error) {
+	proof := make(map[string][]byte)
+	for _, n := range nodes {
+		hash, err := hexutil.HexToBytes(n.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := hexutil.HexToBytes(n.Node)
+		if err != nil {
+			return nil, err
+		}
+
+		proof[string(hash)] = node
+	}
+
+	return proof, nil
+}
+
+// Verify verifies the block hash of the header and the merkle proofs against the roots of the header,
+// and returns the tx and receipt proven.
+func (p *ReceiptProof) Verify() (*types.Transaction, *types.Receipt, error) {
+	if p.Header == nil || p.Header.Hash() != p.BlockHash {
+		return nil, nil, errProofHeaderHash
+	}
+
+	tx := new(types.Transaction)
+	if err := verifyProofValue(p.Header.TxHash, p.TxHash, p.TxProof, tx); err != nil {
+		return nil, nil, errors.NewStackedError(err, "failed to verify tx proof")
+	}
+
+	if !tx.Hash.Equal(p.TxHash) || tx.CalculateHash() != p.TxHash {
+		return nil, nil, types.ErrHashMismatch
+	}
+
+	receipt := new(types.Receipt)
+	if err := verifyProofValue(p.Header.ReceiptHash, p.TxHash, p.ReceiptProof, receipt); err != nil {
+		return nil, nil, errors.NewStackedError(err, "failed to verify receipt proof")
+	}
+
+	if !receipt.TxHash.Equal(p.TxHash) {
+		return nil, nil, errProofReceiptHash
+	}
+
+	return tx, receipt, nil
+}
+
+// verifyProofValue verifies the merkle proof of the key against the root, and decodes the value proven
+func verifyProofValue(root common.Hash, key common.Hash, nodes []ProofNode, value interface{}) error {
+	proof, err := fromProofNodes(nodes)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := trie.VerifyProof(root, key.Bytes(), proof)
+	if err != nil {
+		return err
+	}
+
+	if encoded == nil {
+		return errProofKeyMissing
+	}
+
+	return common.Deserialize(encoded, value)
+}
@@ -0,0 +1,83 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetReceiptProof(t *testing.T) {
+	dir, err := ioutil.TempDir("", "receiptproof")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.NewLevelDB(dir)
+	assert.Equal(t, err, nil)
+	defer db.Close()
+
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	genesis := chain.putBlock(t, nil, "")
+
+	var txs []*types.Transaction
+	var receipts []*types.Receipt
+	for i := uint64(0); i < 3; i++ {
+		tx := types.NewTestTransactionWithNonce(i)
+		txs = append(txs, tx)
+		receipts = append(receipts, &types.Receipt{TxHash: tx.Hash, UsedGas: i + 1})
+	}
+
+	header := &types.BlockHeader{
+		PreviousBlockHash: genesis.HeaderHash,
+		Height:            1,
+		Difficulty:        big.NewInt(1),
+		CreateTimestamp:   big.NewInt(2),
+	}
+	block := types.NewBlock(header, txs, receipts, nil)
+	assert.Equal(t, chain.bcStore.PutBlock(block, big.NewInt(2), true), nil)
+	assert.Equal(t, chain.bcStore.PutReceipts(block.HeaderHash, receipts), nil)
+
+	proof, err := getReceiptProof(chain.bcStore, txs[1].Hash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, proof.BlockHash, block.HeaderHash)
+	assert.Equal(t, proof.TxIndex, uint(1))
+
+	tx, receipt, err := proof.Verify()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tx.Hash, txs[1].Hash)
+	assert.Equal(t, receipt.UsedGas, uint64(2))
+
+	// tampered header
+	proof.Header.Height = 2
+	_, _, err = proof.Verify()
+	assert.Equal(t, err, errProofHeaderHash)
+	proof.Header.Height = 1
+
+	// proof of another tx
+	other, err := getReceiptProof(chain.bcStore, txs[2].Hash)
+	assert.Equal(t, err, nil)
+	other.TxHash = txs[1].Hash
+	_, _, err = other.Verify()
+	assert.Equal(t, err != nil, true)
+
+	// tx not found
+	_, err = getReceiptProof(chain.bcStore, common.StringToHash("unknown"))
+	assert.Equal(t, err != nil, true)
+
+	// receipts pruned
+	_, err = chain.bcStore.PruneBlockData([]common.Hash{block.HeaderHash}, block.Header.Height)
+	assert.Equal(t, err, nil)
+	_, err = getReceiptProof(chain.bcStore, txs[1].Hash)
+	assert.Equal(t, err, errReceiptsPruned)
+}
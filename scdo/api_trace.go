@@ -0,0 +1,173 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/core/vm"
+)
+
+// tracers supported by TraceBlockByNumber and TraceTransaction
+const (
+	TracerStructLogger = "structLogger" // evm op code logs of each tx
+	TracerGasAudit     = "gasAudit"     // gas of the op code groups and refund sources of each tx
+	TracerNone         = "none"         // receipts only
+)
+
+// TxTrace is the trace of a tx replayed in a block
+type TxTrace struct {
+	TxIndex     int
+	TxHash      common.Hash
+	Failed      bool
+	UsedGas     uint64
+	PostState   common.Hash
+	ReturnValue string
+	StructLogs  []vm.StructLog `json:",omitempty"`
+	GasAudit    *vm.GasAudit   `json:",omitempty"`
+
+	// whether the replayed receipt matches the stored receipt, the first mismatched tx locates the divergence
+	ReceiptMatched bool
+}
+
+// BlockTrace is the result of replaying a block on the state of its parent block
+type BlockTrace struct {
+	BlockHash        common.Hash
+	Height           uint64
+	StateRoot        common.Hash // state root hash in the block header
+	ReplayStateRoot  common.Hash // state root hash after the block is replayed
+	StateRootMatched bool
+	Txs              []*TxTrace
+}
+
+// TraceBlockByNumber re-executes all the txs of the block at the height, or the HEAD block if height is -1,
+// on the state of its parent block, and returns the trace of each tx and the comparison of the resulting
+// state root with the stored one. tracer is structLogger by default, gasAudit to audit the gas accounting
+// and refunds, or none to trace the receipts only.
+func (api *PrivateDebugAPI) TraceBlockByNumber(height int64, tracer string) (*BlockTrace, error) {
+	block, err := getBlock(api.s.chain, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.traceBlock(block, tracer)
+}
+
+// TraceTransaction re-executes the block which packs the tx on the state of its parent block, and returns
+// the trace of the tx. tracer is the same as TraceBlockByNumber.
+func (api *PrivateDebugAPI) TraceTransaction(txHash string, tracer string) (*TxTrace, error) {
+	hash, err := common.HexToHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	bcStore := api.s.chain.GetStore()
+	txIndex, err := bcStore.GetTxIndex(hash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get tx index by hash %v", hash.Hex())
+	}
+
+	block, err := bcStore.GetBlock(txIndex.BlockHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get block by hash %v", txIndex.BlockHash.Hex())
+	}
+
+	trace, err := api.traceBlock(block, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	if int(txIndex.Index) >= len(trace.Txs) {
+		return nil, fmt.Errorf("tx index %d out of range", txIndex.Index)
+	}
+
+	return trace.Txs[txIndex.Index], nil
+}
+
+func (api *PrivateDebugAPI) traceBlock(block *types.Block, tracer string) (*BlockTrace, error) {
+	if tracer == "" {
+		tracer = TracerStructLogger
+	}
+
+	if tracer != TracerStructLogger && tracer != TracerGasAudit && tracer != TracerNone {
+		return nil, fmt.Errorf("unsupported tracer %q, it should be %s, %s or %s", tracer, TracerStructLogger, TracerGasAudit, TracerNone)
+	}
+
+	if block.Header.Height <= api.s.chain.Genesis().Header.Height {
+		return nil, fmt.Errorf("genesis block %d could not be traced", block.Header.Height)
+	}
+
+	loggers := make(map[int]*vm.StructLogger)
+	audits := make(map[int]*vm.GasAuditLogger)
+	newTracer := func(txIndex int, tx *types.Transaction) vm.Tracer {
+		switch tracer {
+		case TracerStructLogger:
+			logger := vm.NewStructLogger(nil)
+			loggers[txIndex] = logger
+			return logger
+		case TracerGasAudit:
+			logger := vm.NewGasAuditLogger()
+			audits[txIndex] = logger
+			return logger
+		default:
+			return nil
+		}
+	}
+
+	root, receipts, err := api.s.chain.ReplayBlock(block, newTracer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay block, error:%s, block height:%d", err, block.Header.Height)
+	}
+
+	stored, err := api.s.chain.GetStore().GetReceiptsByBlockHash(block.HeaderHash)
+	if err != nil {
+		api.s.log.Debug("failed to get receipts of block %s, %s", block.HeaderHash.Hex(), err)
+	}
+
+	trace := &BlockTrace{
+		BlockHash:        block.HeaderHash,
+		Height:           block.Header.Height,
+		StateRoot:        block.Header.StateHash,
+		ReplayStateRoot:  root,
+		StateRootMatched: root.Equal(block.Header.StateHash),
+	}
+
+	for i, receipt := range receipts {
+		txTrace := &TxTrace{
+			TxIndex:     i,
+			TxHash:      receipt.TxHash,
+			Failed:      receipt.Failed,
+			UsedGas:     receipt.UsedGas,
+			PostState:   receipt.PostState,
+			ReturnValue: hexutil.BytesToHex(receipt.Result),
+		}
+
+		if i < len(stored) {
+			txTrace.ReceiptMatched = receiptMatched(receipt, stored[i])
+		}
+
+		if logger := loggers[i]; logger != nil {
+			txTrace.StructLogs = logger.StructLogs()
+		}
+
+		if logger := audits[i]; logger != nil {
+			txTrace.GasAudit = logger.GasAudit()
+		}
+
+		trace.Txs = append(trace.Txs, txTrace)
+	}
+
+	return trace, nil
+}
+
+func receiptMatched(replayed, stored *types.Receipt) bool {
+	return replayed.TxHash.Equal(stored.TxHash) && replayed.PostState.Equal(stored.PostState) &&
+		replayed.Failed == stored.Failed && replayed.UsedGas == stored.UsedGas && replayed.TotalFee == stored.TotalFee
+}
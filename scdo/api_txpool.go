@@ -9,6 +9,7 @@ import (
 	api2 "github.com/scdoproject/go-scdo/api"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/core/types"
 )
 
@@ -22,11 +23,36 @@ func NewTransactionPoolAPI(s *ScdoService) *TransactionPoolAPI {
 	return &TransactionPoolAPI{s}
 }
 
+// GetAdmissionRejections returns the local admission policies of the tx pool and the number of txs rejected by each policy
+func (api *TransactionPoolAPI) GetAdmissionRejections() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"policies":   api.s.TxPool().GetAdmissionPolicies(),
+		"rejections": api.s.TxPool().GetAdmissionRejections(),
+	}, nil
+}
+
+// GetDependencyGraph returns the nonce chains of the accounts in the tx pool and the ordering constraints
+// across the accounts, e.g. a tx spends the balance transferred by another tx. If the account is specified,
+// only its nonce chain and the constraints involving it are returned.
+func (api *TransactionPoolAPI) GetDependencyGraph(account *common.Address) (*core.TxDependencyGraph, error) {
+	if account == nil {
+		return api.s.TxPool().GetDependencyGraph(common.EmptyAddress)
+	}
+
+	return api.s.TxPool().GetDependencyGraph(*account)
+}
+
 // GetPendingDebts returns all pending debts
 func (api *TransactionPoolAPI) GetPendingDebts() ([]*types.Debt, error) {
 	return api.s.DebtPool().GetDebts(false, true), nil
 }
 
+// GetExpiredDebts returns the debts expired without being packed, which are not requeued as their txs are
+// not confirmed in the source shard any more, or requeued too many times
+func (api *TransactionPoolAPI) GetExpiredDebts() ([]*core.ExpiredDebt, error) {
+	return api.s.DebtPool().GetExpiredDebts(), nil
+}
+
 // GetDebtByHash return the debt info by debt hash
 func (api *TransactionPoolAPI) GetDebtByHash(debtHash string) (map[string]interface{}, error) {
 	hashByte, err := hexutil.HexToBytes(debtHash)
@@ -36,8 +62,13 @@ func (api *TransactionPoolAPI) GetDebtByHash(debtHash string) (map[string]interf
 	hash := common.BytesToHash(hashByte)
 
 	debt, blockIdx, err := api2.GetDebt(api.s.DebtPool(), api.s.chain.GetStore(), hash)
+	var expired *core.ExpiredDebt
 	if err != nil {
-		return nil, err
+		if expired = api.s.DebtPool().GetExpiredDebt(hash); expired == nil {
+			return nil, err
+		}
+
+		debt = expired.Debt
 	}
 	debtData := map[string]interface{}{
 		"Account": debt.Data.Account.Hex(),
@@ -57,7 +88,11 @@ func (api *TransactionPoolAPI) GetDebtByHash(debtHash string) (map[string]interf
 		"debt": debtOutput,
 	}
 
-	if blockIdx == nil {
+	if expired != nil {
+		output["status"] = "expired"
+		output["reason"] = expired.Reason
+		output["expiredAt"] = expired.ExpiredAt
+	} else if blockIdx == nil {
 		output["status"] = "pool"
 	} else {
 		output["status"] = "block"
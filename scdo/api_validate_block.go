@@ -0,0 +1,86 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	api2 "github.com/scdoproject/go-scdo/api"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// ValidateBlock validates the block built externally, e.g. by a mining pool, and executes it on the state of
+// its parent block without writing anything. The block is the hex encoded rlp bytes or the json of the block.
+// It returns the receipts and the state root of the execution, and the error if the block is invalid.
+func (api *PrivateDebugAPI) ValidateBlock(rlpOrJSON string) (map[string]interface{}, error) {
+	block, err := decodeValidateBlockInput(rlpOrJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	root, receipts, err := api.s.chain.DryRunBlock(block)
+
+	output := map[string]interface{}{
+		"blockHash": block.HeaderHash.Hex(),
+		"height":    block.Header.Height,
+		"valid":     err == nil,
+	}
+
+	if err != nil {
+		output["error"] = err.Error()
+	}
+
+	// the block is executed, even if the state or receipts root hash mismatches
+	if receipts != nil {
+		outputReceipts := make([]map[string]interface{}, len(receipts))
+		for i, receipt := range receipts {
+			if outputReceipts[i], err = api2.PrintableReceipt(receipt); err != nil {
+				return nil, err
+			}
+		}
+
+		output["stateHash"] = root.Hex()
+		output["receiptHash"] = types.ReceiptMerkleRootHash(receipts).Hex()
+		output["receipts"] = outputReceipts
+	}
+
+	return output, nil
+}
+
+// decodeValidateBlockInput decodes the block from the json or the hex encoded rlp bytes
+func decodeValidateBlockInput(rlpOrJSON string) (*types.Block, error) {
+	input := strings.TrimSpace(rlpOrJSON)
+	if len(input) == 0 {
+		return nil, errors.New("empty block")
+	}
+
+	var block types.Block
+	if strings.HasPrefix(input, "{") {
+		if err := json.Unmarshal([]byte(input), &block); err != nil {
+			return nil, fmt.Errorf("failed to decode block json, %s", err)
+		}
+	} else {
+		encoded, err := hexutil.HexToBytes(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block rlp hex, %s", err)
+		}
+
+		if err = common.Deserialize(encoded, &block); err != nil {
+			return nil, fmt.Errorf("failed to decode block rlp, %s", err)
+		}
+	}
+
+	if block.Header == nil {
+		return nil, types.ErrBlockHeaderNil
+	}
+
+	return &block, nil
+}
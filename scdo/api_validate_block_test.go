@@ -0,0 +1,52 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DecodeValidateBlockInput(t *testing.T) {
+	header := &types.BlockHeader{
+		PreviousBlockHash: common.StringToHash("parent"),
+		Height:            10,
+		Difficulty:        big.NewInt(100),
+		CreateTimestamp:   big.NewInt(1),
+		Witness:           []byte{},
+		ExtraData:         []byte{},
+	}
+	block := types.NewBlock(header, nil, nil, nil)
+
+	// rlp
+	decoded, err := decodeValidateBlockInput(hexutil.BytesToHex(common.SerializePanic(block)))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.HeaderHash, block.HeaderHash)
+	assert.Equal(t, decoded.Header.Height, uint64(10))
+
+	// json, e.g. printed by debug_printBlock
+	encoded, err := json.Marshal(block)
+	assert.Equal(t, err, nil)
+	decoded, err = decodeValidateBlockInput(" " + string(encoded))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.HeaderHash, block.HeaderHash)
+	assert.Equal(t, decoded.Header.Difficulty, big.NewInt(100))
+
+	_, err = decodeValidateBlockInput("")
+	assert.NotEqual(t, err, nil)
+
+	_, err = decodeValidateBlockInput("0xzz")
+	assert.NotEqual(t, err, nil)
+
+	_, err = decodeValidateBlockInput("{}")
+	assert.Equal(t, err, types.ErrBlockHeaderNil)
+}
@@ -0,0 +1,247 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/scdo/snapshot"
+	"github.com/scdoproject/go-scdo/trie"
+)
+
+const (
+	defaultBackupKeep = 3
+
+	// backupCheckInterval is the interval to check whether the chain grows by the backup interval
+	backupCheckInterval = 10 * time.Second
+
+	// backupTmpDir is the folder of the backup being created, which is renamed once completed
+	backupTmpDir = "snapshot.tmp"
+)
+
+var errBackupNotSupported = errors.New("database does not support checkpoint")
+
+// backupChain is the blockchain to back up
+type backupChain interface {
+	CurrentBlock() *types.Block
+}
+
+// backupScheduler creates the consistent point-in-time backups of the chain and state databases every
+// interval blocks, and keeps the most recent ones, for the operators to recover the node from a backup.
+type backupScheduler struct {
+	chain   backupChain
+	chainDB database.Database
+	stateDB database.Database
+	log     *log.ScdoLog
+
+	dir        string
+	interval   uint64
+	keep       int
+	uploadHook []string
+
+	lastHeight uint64 // height of the last backup
+
+	quitCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newBackupScheduler(chain backupChain, chainDB, stateDB database.Database, dataDir string, conf node.BackupConfig) *backupScheduler {
+	b := &backupScheduler{
+		chain:      chain,
+		chainDB:    chainDB,
+		stateDB:    stateDB,
+		log:        log.GetLogger("backup"),
+		dir:        conf.Dir,
+		interval:   conf.Interval,
+		keep:       conf.Keep,
+		uploadHook: strings.Fields(conf.UploadHook),
+		quitCh:     make(chan struct{}),
+	}
+
+	if b.dir == "" {
+		b.dir = filepath.Join(dataDir, BackupDir)
+	}
+
+	if b.keep <= 0 {
+		b.keep = defaultBackupKeep
+	}
+
+	// continue the schedule of the existing backups after restart
+	if backups, err := snapshot.ListBackups(b.dir); err == nil && len(backups) > 0 {
+		if manifest, err := snapshot.ReadBackupManifest(backups[len(backups)-1]); err == nil {
+			b.lastHeight = manifest.Height
+		}
+	}
+
+	return b
+}
+
+func (b *backupScheduler) start() {
+	b.wg.Add(1)
+	go b.loop()
+}
+
+func (b *backupScheduler) stop() {
+	close(b.quitCh)
+	b.wg.Wait()
+}
+
+func (b *backupScheduler) loop() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(backupCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.check()
+		case <-b.quitCh:
+			return
+		}
+	}
+}
+
+func (b *backupScheduler) check() {
+	if height := b.chain.CurrentBlock().Header.Height; height < b.lastHeight+b.interval {
+		return
+	}
+
+	start := time.Now()
+	path, manifest, err := b.backup()
+	if err != nil {
+		b.log.Warn("failed to back up the chain and state databases, %s", err)
+		return
+	}
+
+	b.lastHeight = manifest.Height
+	b.log.Info("backed up the chain at height %d, block hash %s to %s, elapsed %v", manifest.Height, manifest.BlockHash.Hex(), path, time.Since(start))
+
+	removed, err := snapshot.RotateBackups(b.dir, b.keep)
+	if err != nil {
+		b.log.Warn("failed to remove the old backups, %s", err)
+	}
+
+	for _, old := range removed {
+		b.log.Info("removed the old backup %s", old)
+	}
+
+	if len(b.uploadHook) > 0 {
+		b.upload(path)
+	}
+}
+
+// backup checkpoints the chain and state databases into a new backup, and returns its path and manifest.
+func (b *backupScheduler) backup() (string, *snapshot.BackupManifest, error) {
+	chainDB, ok := checkpointer(b.chainDB)
+	if !ok {
+		return "", nil, errBackupNotSupported
+	}
+
+	stateDB, ok := checkpointer(b.stateDB)
+	if !ok {
+		return "", nil, errBackupNotSupported
+	}
+
+	tmpDir := filepath.Join(b.dir, backupTmpDir)
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return "", nil, err
+	}
+
+	// The account states of a block are written before the block, so the state db is checkpointed
+	// after the chain db to include the states of all the blocks in the backup.
+	chainDBPath := filepath.Join(tmpDir, BlockChainDir)
+	if err := chainDB.Checkpoint(chainDBPath); err != nil {
+		return "", nil, err
+	}
+
+	if err := stateDB.Checkpoint(filepath.Join(tmpDir, AccountStateDir)); err != nil {
+		return "", nil, err
+	}
+
+	manifest, err := readBackupHead(chainDBPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err = snapshot.WriteBackupManifest(tmpDir, manifest); err != nil {
+		return "", nil, err
+	}
+
+	path := filepath.Join(b.dir, snapshot.BackupName(manifest.Height))
+	if err = os.RemoveAll(path); err != nil {
+		return "", nil, err
+	}
+
+	if err = os.Rename(tmpDir, path); err != nil {
+		return "", nil, err
+	}
+
+	return path, manifest, nil
+}
+
+// upload runs the upload hook with the backup path
+func (b *backupScheduler) upload(path string) {
+	args := append(append([]string{}, b.uploadHook[1:]...), path)
+	output, err := exec.Command(b.uploadHook[0], args...).CombinedOutput()
+	if err != nil {
+		b.log.Warn("failed to run the backup upload hook for %s, %s, output: %s", path, err, output)
+		return
+	}
+
+	b.log.Info("ran the backup upload hook for %s", path)
+}
+
+// readBackupHead returns the manifest of the HEAD block in the checkpointed chain db
+func readBackupHead(chainDBPath string) (*snapshot.BackupManifest, error) {
+	db, err := leveldb.NewLevelDB(chainDBPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	bcStore := store.NewBlockchainDatabase(db)
+	hash, err := bcStore.GetHeadBlockHash()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := bcStore.GetBlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot.BackupManifest{
+		Height:    header.Height,
+		BlockHash: hash,
+		Time:      time.Now().Unix(),
+	}, nil
+}
+
+// checkpointer returns the database which supports checkpoint, the trie node cache is unwrapped as it
+// writes through to the database.
+func checkpointer(db database.Database) (database.Checkpointer, bool) {
+	if cache, ok := db.(*trie.NodeCache); ok {
+		db = cache.Database
+	}
+
+	c, ok := db.(database.Checkpointer)
+	return c, ok
+}
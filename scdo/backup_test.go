@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/scdo/snapshot"
+	"github.com/scdoproject/go-scdo/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBackupChain struct {
+	bcStore store.BlockchainStore
+}
+
+func (c *mockBackupChain) CurrentBlock() *types.Block {
+	hash, _ := c.bcStore.GetHeadBlockHash()
+	header, _ := c.bcStore.GetBlockHeader(hash)
+	return &types.Block{HeaderHash: hash, Header: header}
+}
+
+func (c *mockBackupChain) putHeader(t *testing.T, height uint64) *types.BlockHeader {
+	header := &types.BlockHeader{Height: height, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(int64(height))}
+	assert.Equal(t, c.bcStore.PutBlockHeader(header.Hash(), header, big.NewInt(int64(height)), true), nil)
+	return header
+}
+
+func Test_BackupScheduler(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "backup")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dataDir)
+
+	chainDB, err := leveldb.NewLevelDB(filepath.Join(dataDir, BlockChainDir))
+	assert.Equal(t, err, nil)
+	defer chainDB.Close()
+
+	stateDB, err := leveldb.NewLevelDB(filepath.Join(dataDir, AccountStateDir))
+	assert.Equal(t, err, nil)
+	defer stateDB.Close()
+	assert.Equal(t, stateDB.PutString("state", "1"), nil)
+
+	chain := &mockBackupChain{bcStore: store.NewBlockchainDatabase(chainDB)}
+	chain.putHeader(t, 1)
+
+	conf := node.BackupConfig{Interval: 2, Keep: 1}
+	b := newBackupScheduler(chain, chainDB, trie.NewNodeCache(stateDB, nil, 0), dataDir, conf)
+	assert.Equal(t, b.dir, filepath.Join(dataDir, BackupDir))
+
+	b.check()
+	header := chain.putHeader(t, 2)
+	b.check()
+
+	backups, err := snapshot.ListBackups(b.dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(backups), 1)
+
+	manifest, err := snapshot.ReadBackupManifest(backups[0])
+	assert.Equal(t, err, nil)
+	assert.Equal(t, manifest.Height, uint64(2))
+	assert.Equal(t, manifest.BlockHash, header.Hash())
+	assert.Equal(t, b.lastHeight, uint64(2))
+
+	db, err := leveldb.NewLevelDB(filepath.Join(backups[0], AccountStateDir))
+	assert.Equal(t, err, nil)
+	value, err := db.GetString("state")
+	db.Close()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "1")
+
+	// the older backups are removed
+	chain.putHeader(t, 3)
+	b.check()
+	chain.putHeader(t, 4)
+	b.check()
+
+	backups, err = snapshot.ListBackups(b.dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(backups), 1)
+	assert.Equal(t, filepath.Base(backups[0]), snapshot.BackupName(4))
+
+	// the schedule continues after restart
+	assert.Equal(t, newBackupScheduler(chain, chainDB, stateDB, dataDir, conf).lastHeight, uint64(4))
+}
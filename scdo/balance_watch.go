@@ -0,0 +1,322 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/rpc"
+)
+
+const (
+	// maxBalanceWatches is the max number of accounts watched by a subscription
+	maxBalanceWatches = 10000
+
+	// maxBalanceWatchDepth is the max number of new blocks whose dirty accounts are walked through
+	// when the head is changed, otherwise the watched accounts are compared between the old and new head states.
+	maxBalanceWatchDepth = 64
+
+	// balanceChangesBuffSize is the number of notifications buffered for a subscriber
+	balanceChangesBuffSize = 64
+)
+
+// BalanceChange is the change of the balance or nonce of a watched account
+type BalanceChange struct {
+	Address       common.Address
+	BalanceBefore *big.Int
+	BalanceAfter  *big.Int
+	NonceBefore   uint64
+	NonceAfter    uint64
+}
+
+// BalanceChanges is notified to the subscriber when the balance or nonce of any watched account is changed by a new HEAD block
+type BalanceChanges struct {
+	BlockHash common.Hash
+	Height    uint64
+
+	// whether the changes are the difference between the states of the old and new HEAD blocks,
+	// e.g. the chain is reorganized, instead of the changes of the block.
+	Reorg bool
+
+	Changes []*BalanceChange
+}
+
+// BalanceChanged creates a subscription which notifies the BalanceChanges of the new HEAD blocks
+// when the balance or nonce of any watched account is changed, so that the exchanges could credit
+// the deposits without scanning the txs of every block. It is only available on the connections
+// which support notifications, e.g. websocket and ipc.
+func (api *PublicScdoAPI) BalanceChanged(ctx context.Context, addresses []common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	if len(addresses) == 0 {
+		return nil, errors.New("no account is watched")
+	}
+
+	if len(addresses) > maxBalanceWatches {
+		return nil, fmt.Errorf("too many accounts are watched, %d > %d", len(addresses), maxBalanceWatches)
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	changes := api.s.balanceWatcher.subscribe(rpcSub.ID, addresses)
+
+	go func() {
+		defer api.s.balanceWatcher.unsubscribe(rpcSub.ID)
+
+		for {
+			select {
+			case c := <-changes:
+				if err := notifier.Notify(rpcSub.ID, c); err != nil {
+					api.s.log.Debug("failed to notify balance changes, subscription:%s, %s", rpcSub.ID, err)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+type balanceSubscription struct {
+	addresses map[common.Address]bool
+	changes   chan *BalanceChanges
+}
+
+// balanceWatcher computes the balance changes of the watched accounts from the dirty accounts of
+// the new HEAD blocks, and dispatches them to the subscriptions.
+type balanceWatcher struct {
+	lock  sync.Mutex
+	chain watchChain
+	head  *types.Block // the last HEAD block whose changes are dispatched
+	subs  map[rpc.ID]*balanceSubscription
+	log   *log.ScdoLog
+}
+
+func newBalanceWatcher(chain watchChain, log *log.ScdoLog) *balanceWatcher {
+	return &balanceWatcher{
+		chain: chain,
+		head:  chain.CurrentBlock(),
+		subs:  make(map[rpc.ID]*balanceSubscription),
+		log:   log,
+	}
+}
+
+func (w *balanceWatcher) subscribe(id rpc.ID, addresses []common.Address) <-chan *BalanceChanges {
+	sub := &balanceSubscription{
+		addresses: make(map[common.Address]bool),
+		changes:   make(chan *BalanceChanges, balanceChangesBuffSize),
+	}
+
+	for _, addr := range addresses {
+		sub.addresses[addr] = true
+	}
+
+	w.lock.Lock()
+	w.subs[id] = sub
+	w.lock.Unlock()
+
+	return sub.changes
+}
+
+func (w *balanceWatcher) unsubscribe(id rpc.ID) {
+	w.lock.Lock()
+	delete(w.subs, id)
+	w.lock.Unlock()
+}
+
+// chainHeaderChanged handles the chain header changed event. The current HEAD is used instead of
+// the block of the event, since the async events may be handled out of order.
+func (w *balanceWatcher) chainHeaderChanged(e event.Event) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	head := w.chain.CurrentBlock()
+	if head == nil || (w.head != nil && w.head.HeaderHash.Equal(head.HeaderHash)) {
+		return
+	}
+
+	from := w.head
+	w.head = head
+
+	if from == nil || len(w.subs) == 0 {
+		return
+	}
+
+	for _, changes := range w.collect(from, head) {
+		w.dispatch(changes)
+	}
+}
+
+// collect returns the balance changes of the blocks from the old HEAD (exclusive) to the new HEAD.
+// If the new HEAD is not a descendant of the old one within maxBalanceWatchDepth blocks, the watched
+// accounts of the old and new HEAD states are compared instead.
+func (w *balanceWatcher) collect(from, to *types.Block) []*BalanceChanges {
+	bcStore := w.chain.GetStore()
+
+	if blocks := newHeadBlocks(bcStore, from, to, maxBalanceWatchDepth, w.log); blocks != nil {
+		return w.collectDirtyAccounts(bcStore, from, blocks)
+	}
+
+	changes := w.compareStates(from, to, w.watched())
+	if changes == nil {
+		return nil
+	}
+
+	changes.Reorg = true
+
+	return []*BalanceChanges{changes}
+}
+
+// collectDirtyAccounts returns the balance changes of the blocks, whose watched dirty accounts are
+// compared between the states of the block and its parent. The blocks are in descending order.
+func (w *balanceWatcher) collectDirtyAccounts(bcStore store.BlockchainStore, from *types.Block, blocks []*types.Block) []*BalanceChanges {
+	var result []*BalanceChanges
+	parent := from
+	for i := len(blocks) - 1; i >= 0; i-- {
+		block := blocks[i]
+		accounts, err := bcStore.GetDirtyAccountsByBlockHash(block.HeaderHash)
+		if err != nil {
+			w.log.Warn("failed to get dirty accounts of block %s, %s", block.HeaderHash.Hex(), err)
+			accounts = w.watched()
+		}
+
+		var watched []common.Address
+		for _, addr := range accounts {
+			if w.isWatched(addr) {
+				watched = append(watched, addr)
+			}
+		}
+
+		if len(watched) > 0 {
+			if changes := w.compareStates(parent, block, watched); changes != nil {
+				result = append(result, changes)
+			}
+		}
+
+		parent = block
+	}
+
+	return result
+}
+
+// compareStates returns the changes of the accounts between the states of the blocks.
+func (w *balanceWatcher) compareStates(from, to *types.Block, addresses []common.Address) *BalanceChanges {
+	fromState, err := w.chain.GetState(from.Header.StateHash)
+	if err != nil {
+		w.log.Warn("failed to get state of block %s, %s", from.HeaderHash.Hex(), err)
+		return nil
+	}
+
+	toState, err := w.chain.GetState(to.Header.StateHash)
+	if err != nil {
+		w.log.Warn("failed to get state of block %s, %s", to.HeaderHash.Hex(), err)
+		return nil
+	}
+
+	changes := &BalanceChanges{
+		BlockHash: to.HeaderHash,
+		Height:    to.Header.Height,
+	}
+
+	for _, addr := range addresses {
+		if c := newBalanceChange(addr, fromState, toState); c != nil {
+			changes.Changes = append(changes.Changes, c)
+		}
+	}
+
+	if len(changes.Changes) == 0 {
+		return nil
+	}
+
+	return changes
+}
+
+func (w *balanceWatcher) isWatched(addr common.Address) bool {
+	for _, sub := range w.subs {
+		if sub.addresses[addr] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watched returns the sorted accounts watched by any subscription
+func (w *balanceWatcher) watched() []common.Address {
+	var addresses []common.Address
+	seen := make(map[common.Address]bool)
+	for _, sub := range w.subs {
+		for addr := range sub.addresses {
+			if !seen[addr] {
+				seen[addr] = true
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i].Bytes(), addresses[j].Bytes()) < 0
+	})
+
+	return addresses
+}
+
+// dispatch sends the changes of the watched accounts to each subscription. The changes are dropped
+// for the subscription whose buffer is full, i.e. the subscriber is too slow.
+func (w *balanceWatcher) dispatch(changes *BalanceChanges) {
+	for id, sub := range w.subs {
+		var watched []*BalanceChange
+		for _, c := range changes.Changes {
+			if sub.addresses[c.Address] {
+				watched = append(watched, c)
+			}
+		}
+
+		if len(watched) == 0 {
+			continue
+		}
+
+		select {
+		case sub.changes <- &BalanceChanges{changes.BlockHash, changes.Height, changes.Reorg, watched}:
+		default:
+			w.log.Warn("balance changes of block %s dropped for slow subscription %s", changes.BlockHash.Hex(), id)
+		}
+	}
+}
+
+// newBalanceChange returns the change of the account between the states, or nil if neither the balance nor the nonce is changed
+func newBalanceChange(addr common.Address, from, to *state.Statedb) *BalanceChange {
+	c := &BalanceChange{
+		Address:       addr,
+		BalanceBefore: from.GetBalance(addr),
+		BalanceAfter:  to.GetBalance(addr),
+		NonceBefore:   from.GetNonce(addr),
+		NonceAfter:    to.GetNonce(addr),
+	}
+
+	if c.BalanceBefore.Cmp(c.BalanceAfter) == 0 && c.NonceBefore == c.NonceAfter {
+		return nil
+	}
+
+	return c
+}
@@ -0,0 +1,128 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/memorydb"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAccount struct {
+	balance int64
+	nonce   uint64
+}
+
+func newBalanceState(t *testing.T, chain *mockStorageWatchChain, accounts map[common.Address]testAccount) common.Hash {
+	statedb, err := state.NewStatedb(common.EmptyHash, chain.db)
+	assert.Equal(t, err, nil)
+
+	for addr, account := range accounts {
+		statedb.CreateAccount(addr)
+		statedb.SetBalance(addr, big.NewInt(account.balance))
+		statedb.SetNonce(addr, account.nonce)
+	}
+
+	batch := chain.db.NewBatch()
+	root, err := statedb.Commit(batch)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, batch.Commit(), nil)
+
+	return root
+}
+
+func putBalanceBlock(t *testing.T, chain *mockStorageWatchChain, parent *types.Block, root common.Hash, dirty ...common.Address) *types.Block {
+	block := chain.putBlock(t, parent, root, nil)
+	assert.Equal(t, chain.bcStore.PutDirtyAccounts(block.HeaderHash, dirty), nil)
+	chain.head = block
+
+	return block
+}
+
+func Test_BalanceWatcher(t *testing.T) {
+	db, dispose := memorydb.NewTestDatabase()
+	defer dispose()
+
+	chain := &mockStorageWatchChain{db: db, bcStore: store.NewBlockchainDatabase(db)}
+	addr1, addr2, addr3 := common.BytesToAddress([]byte("addr1")), common.BytesToAddress([]byte("addr2")), common.BytesToAddress([]byte("addr3"))
+
+	genesis := putBalanceBlock(t, chain, nil, newBalanceState(t, chain, nil))
+	watcher := newBalanceWatcher(chain, log.GetLogger("scdo"))
+	changes1 := watcher.subscribe("sub1", []common.Address{addr1})
+	changes2 := watcher.subscribe("sub2", []common.Address{addr2})
+
+	// block 1 deposits to addr1 and addr3
+	root1 := newBalanceState(t, chain, map[common.Address]testAccount{addr1: {10, 0}, addr3: {30, 0}})
+	block1 := putBalanceBlock(t, chain, genesis, root1, addr1, addr3)
+	watcher.chainHeaderChanged(block1)
+
+	assert.Equal(t, len(changes1), 1)
+	assert.Equal(t, len(changes2), 0)
+	assert.Equal(t, <-changes1, &BalanceChanges{
+		BlockHash: block1.HeaderHash,
+		Height:    1,
+		Changes:   []*BalanceChange{{Address: addr1, BalanceBefore: big.NewInt(0), BalanceAfter: big.NewInt(10)}},
+	})
+
+	// the same head is notified only once
+	watcher.chainHeaderChanged(block1)
+	assert.Equal(t, len(changes1), 0)
+
+	// blocks 2 and 3 are notified in order, the nonce change is notified too
+	root2 := newBalanceState(t, chain, map[common.Address]testAccount{addr1: {10, 1}, addr2: {20, 0}, addr3: {30, 0}})
+	block2 := putBalanceBlock(t, chain, block1, root2, addr1, addr2)
+	root3 := newBalanceState(t, chain, map[common.Address]testAccount{addr1: {10, 1}, addr2: {25, 0}, addr3: {30, 0}})
+	block3 := putBalanceBlock(t, chain, block2, root3, addr2)
+	watcher.chainHeaderChanged(block3)
+
+	assert.Equal(t, <-changes1, &BalanceChanges{
+		BlockHash: block2.HeaderHash,
+		Height:    2,
+		Changes:   []*BalanceChange{{Address: addr1, BalanceBefore: big.NewInt(10), BalanceAfter: big.NewInt(10), NonceAfter: 1}},
+	})
+	assert.Equal(t, len(changes1), 0)
+	assert.Equal(t, <-changes2, &BalanceChanges{
+		BlockHash: block2.HeaderHash,
+		Height:    2,
+		Changes:   []*BalanceChange{{Address: addr2, BalanceBefore: big.NewInt(0), BalanceAfter: big.NewInt(20)}},
+	})
+	assert.Equal(t, <-changes2, &BalanceChanges{
+		BlockHash: block3.HeaderHash,
+		Height:    3,
+		Changes:   []*BalanceChange{{Address: addr2, BalanceBefore: big.NewInt(20), BalanceAfter: big.NewInt(25)}},
+	})
+
+	// reorg to another block 2 which only changes addr3
+	fork2 := putBalanceBlock(t, chain, block1, newBalanceState(t, chain, map[common.Address]testAccount{addr1: {10, 0}, addr3: {35, 0}}), addr3)
+	watcher.chainHeaderChanged(fork2)
+
+	assert.Equal(t, <-changes1, &BalanceChanges{
+		BlockHash: fork2.HeaderHash,
+		Height:    2,
+		Reorg:     true,
+		Changes:   []*BalanceChange{{Address: addr1, BalanceBefore: big.NewInt(10), BalanceAfter: big.NewInt(10), NonceBefore: 1}},
+	})
+	assert.Equal(t, <-changes2, &BalanceChanges{
+		BlockHash: fork2.HeaderHash,
+		Height:    2,
+		Reorg:     true,
+		Changes:   []*BalanceChange{{Address: addr2, BalanceBefore: big.NewInt(25), BalanceAfter: big.NewInt(0)}},
+	})
+
+	// unsubscribed
+	watcher.unsubscribe("sub1")
+	block3 = putBalanceBlock(t, chain, fork2, newBalanceState(t, chain, map[common.Address]testAccount{addr1: {5, 0}, addr3: {35, 0}}), addr1)
+	watcher.chainHeaderChanged(block3)
+	assert.Equal(t, len(changes1), 0)
+	assert.Equal(t, len(changes2), 0)
+}
@@ -0,0 +1,144 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"context"
+	"sync"
+
+	api2 "github.com/scdoproject/go-scdo/api"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/rpc"
+)
+
+// importedBlocksBuffSize is the number of imported blocks buffered for a subscriber
+const importedBlocksBuffSize = 256
+
+// ImportedBlocks creates a subscription which notifies every block written into the chain, including
+// the blocks of the forks, with its receipts and state diff, so that the co-located indexers don't have
+// to query the receipts by hash afterwards. The blocks are notified in the order they are imported, and
+// the blocks are dropped for the subscriber which is too slow. It is only available on the connections
+// which support notifications, e.g. websocket and ipc.
+func (api *PublicScdoAPI) ImportedBlocks(ctx context.Context, fulltx bool) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	imported := api.s.blockFirehose.subscribe(rpcSub.ID)
+
+	go func() {
+		defer api.s.blockFirehose.unsubscribe(rpcSub.ID)
+
+		for {
+			select {
+			case e := <-imported:
+				output, err := rpcOutputImportedBlock(e, fulltx)
+				if err != nil {
+					api.s.log.Warn("failed to output imported block %s, %s", e.Block.HeaderHash.Hex(), err)
+					continue
+				}
+
+				if err := notifier.Notify(rpcSub.ID, output); err != nil {
+					api.s.log.Debug("failed to notify imported block, subscription:%s, %s", rpcSub.ID, err)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// rpcOutputImportedBlock converts the imported block and its execution results to the RPC output
+func rpcOutputImportedBlock(e *core.BlockImportedEvent, fulltx bool) (map[string]interface{}, error) {
+	block, err := api2.PrintableOutputBlock(e.Block, fulltx, e.TotalDifficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]map[string]interface{}, len(e.Receipts))
+	for i, receipt := range e.Receipts {
+		if receipts[i], err = api2.PrintableReceipt(receipt); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"head":      e.IsHead,
+		"block":     block,
+		"receipts":  receipts,
+		"stateDiff": rpcOutputStateDiff(e.StateDiff),
+	}, nil
+}
+
+// stateDiffChain is the chain which computes the state diffs of the written blocks while they are consumed
+type stateDiffChain interface {
+	AddStateDiffConsumer()
+	RemoveStateDiffConsumer()
+}
+
+// blockFirehose dispatches the imported blocks to the subscriptions
+type blockFirehose struct {
+	lock  sync.RWMutex
+	chain stateDiffChain
+	subs  map[rpc.ID]chan *core.BlockImportedEvent
+	log   *log.ScdoLog
+}
+
+func newBlockFirehose(chain stateDiffChain, log *log.ScdoLog) *blockFirehose {
+	return &blockFirehose{
+		chain: chain,
+		subs:  make(map[rpc.ID]chan *core.BlockImportedEvent),
+		log:   log,
+	}
+}
+
+func (f *blockFirehose) subscribe(id rpc.ID) <-chan *core.BlockImportedEvent {
+	imported := make(chan *core.BlockImportedEvent, importedBlocksBuffSize)
+
+	f.lock.Lock()
+	f.subs[id] = imported
+	f.lock.Unlock()
+
+	f.chain.AddStateDiffConsumer()
+
+	return imported
+}
+
+func (f *blockFirehose) unsubscribe(id rpc.ID) {
+	f.lock.Lock()
+	_, found := f.subs[id]
+	delete(f.subs, id)
+	f.lock.Unlock()
+
+	if found {
+		f.chain.RemoveStateDiffConsumer()
+	}
+}
+
+// blockImported handles the block imported event. It is a sync listener to keep the blocks in order,
+// so the block is dropped instead of blocking the chain for the subscription whose buffer is full.
+func (f *blockFirehose) blockImported(e event.Event) {
+	imported := e.(*core.BlockImportedEvent)
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	for id, sub := range f.subs {
+		select {
+		case sub <- imported:
+		default:
+			f.log.Warn("imported block %s dropped for slow subscription %s", imported.Block.HeaderHash.Hex(), id)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newImportedBlockEvent(height uint64) *core.BlockImportedEvent {
+	header := &types.BlockHeader{Height: height, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}
+	tx := types.NewTestTxDetail(1, 1, height)
+	receipt := &types.Receipt{TxHash: tx.Hash, UsedGas: 21000}
+	diff := &types.AccountDiff{
+		Address:       tx.Data.To,
+		BalanceBefore: big.NewInt(0),
+		BalanceAfter:  big.NewInt(1),
+		Storage:       []*types.StorageDiff{{Key: common.StringToHash("key"), After: []byte{1}}},
+	}
+
+	return &core.BlockImportedEvent{
+		Block:           types.NewBlock(header, []*types.Transaction{tx}, []*types.Receipt{receipt}, nil),
+		Receipts:        []*types.Receipt{receipt},
+		StateDiff:       []*types.AccountDiff{diff},
+		TotalDifficulty: big.NewInt(int64(height + 1)),
+		IsHead:          true,
+	}
+}
+
+func Test_BlockFirehose(t *testing.T) {
+	chain := &mockStorageWatchChain{}
+	f := newBlockFirehose(chain, log.GetLogger("scdo"))
+	fast := f.subscribe("fast")
+	slow := f.subscribe("slow")
+	assert.Equal(t, chain.consumers, 2)
+
+	// the blocks are dispatched in order, and dropped for the slow subscription
+	for i := uint64(1); i <= importedBlocksBuffSize+1; i++ {
+		f.blockImported(newImportedBlockEvent(i))
+		if i < importedBlocksBuffSize {
+			assert.Equal(t, (<-fast).Block.Header.Height, i)
+		}
+	}
+
+	assert.Equal(t, len(fast), 2)
+	assert.Equal(t, len(slow), importedBlocksBuffSize)
+	assert.Equal(t, (<-slow).Block.Header.Height, uint64(1))
+
+	f.unsubscribe("slow")
+	f.unsubscribe("slow")
+	assert.Equal(t, chain.consumers, 1)
+	f.blockImported(newImportedBlockEvent(1000))
+	assert.Equal(t, len(fast), 3)
+	assert.Equal(t, len(slow), importedBlocksBuffSize-1)
+}
+
+func Test_RpcOutputImportedBlock(t *testing.T) {
+	e := newImportedBlockEvent(10)
+
+	output, err := rpcOutputImportedBlock(e, true)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, output["head"], true)
+
+	block := output["block"].(map[string]interface{})
+	assert.Equal(t, block["hash"], e.Block.HeaderHash.Hex())
+	assert.Equal(t, block["totalDifficulty"], e.TotalDifficulty)
+
+	receipts := output["receipts"].([]map[string]interface{})
+	assert.Equal(t, len(receipts), 1)
+	assert.Equal(t, receipts[0]["txhash"], e.Receipts[0].TxHash.Hex())
+
+	diffs := output["stateDiff"].([]map[string]interface{})
+	assert.Equal(t, len(diffs), 1)
+	assert.Equal(t, diffs[0]["account"], e.StateDiff[0].Address.Hex())
+	assert.Equal(t, len(diffs[0]["storage"].([]map[string]interface{})), 1)
+}
@@ -0,0 +1,289 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/p2p"
+)
+
+const (
+	// maxPendingCompactBlocks is the max number of compact blocks waiting for the missing txs
+	maxPendingCompactBlocks = 16
+
+	// compactBlockTimeout is the duration to wait for the missing txs before falling back to the full block
+	compactBlockTimeout = 10 * time.Second
+)
+
+var (
+	errCompactBlockTxsMismatch = errors.New("compact block txs mismatch")
+)
+
+// compactBlock announces a block with its header and the short IDs of its txs,
+// so that the peers could reconstruct the block from their tx pools.
+type compactBlock struct {
+	HeaderHash common.Hash
+	Header     *types.BlockHeader
+	Prefilled  []*types.Transaction // the reward tx which is never in the tx pool
+	ShortIDs   []uint64             // short IDs of the txs following the prefilled ones
+	Debts      []*types.Debt
+}
+
+// blockTxsRequest requests the txs of the specified indexes in a block
+type blockTxsRequest struct {
+	HeaderHash common.Hash
+	Indexes    []uint64
+}
+
+// blockTxsResponse returns the txs requested by blockTxsRequest, in the same order
+type blockTxsResponse struct {
+	HeaderHash common.Hash
+	Txs        []*types.Transaction
+}
+
+// pendingCompactBlock is a compact block waiting for the missing txs
+type pendingCompactBlock struct {
+	block   *types.Block
+	ids     []uint64 // short IDs of the block txs, 0 for prefilled txs
+	missing []uint64 // indexes of the missing txs in block
+	peer    *peer
+	expire  time.Time
+}
+
+// shortTxID returns the short ID of a tx, which is the first 8 bytes of the tx hash
+func shortTxID(hash common.Hash) uint64 {
+	return binary.BigEndian.Uint64(hash[:8])
+}
+
+func newCompactBlock(block *types.Block) *compactBlock {
+	cb := &compactBlock{
+		HeaderHash: block.HeaderHash,
+		Header:     block.Header,
+		Debts:      block.Debts,
+	}
+
+	if len(block.Transactions) > 0 {
+		cb.Prefilled = block.Transactions[:1]
+	}
+
+	for _, tx := range block.GetExcludeRewardTransactions() {
+		cb.ShortIDs = append(cb.ShortIDs, shortTxID(tx.Hash))
+	}
+
+	return cb
+}
+
+// reconstruct builds the block from the given pool txs, and returns the pending block with the missing tx indexes.
+func (cb *compactBlock) reconstruct(poolTxs []*types.Transaction) *pendingCompactBlock {
+	shortIDToTx := make(map[uint64]*types.Transaction, len(poolTxs))
+	for _, tx := range poolTxs {
+		shortIDToTx[shortTxID(tx.Hash)] = tx
+	}
+
+	offset := len(cb.Prefilled)
+	pending := &pendingCompactBlock{
+		block: &types.Block{
+			HeaderHash:   cb.HeaderHash,
+			Header:       cb.Header,
+			Transactions: make([]*types.Transaction, offset+len(cb.ShortIDs)),
+			Debts:        cb.Debts,
+		},
+		ids:    make([]uint64, offset+len(cb.ShortIDs)),
+		expire: time.Now().Add(compactBlockTimeout),
+	}
+
+	copy(pending.block.Transactions, cb.Prefilled)
+	for i, id := range cb.ShortIDs {
+		pending.ids[offset+i] = id
+		if tx, ok := shortIDToTx[id]; ok {
+			pending.block.Transactions[offset+i] = tx
+		} else {
+			pending.missing = append(pending.missing, uint64(offset+i))
+		}
+	}
+
+	return pending
+}
+
+// fill fills the missing txs of the pending block with the given txs
+func (pending *pendingCompactBlock) fill(txs []*types.Transaction) error {
+	if len(txs) != len(pending.missing) {
+		return errCompactBlockTxsMismatch
+	}
+
+	for i, index := range pending.missing {
+		if txs[i] == nil || shortTxID(txs[i].Hash) != pending.ids[index] {
+			return errCompactBlockTxsMismatch
+		}
+
+		pending.block.Transactions[index] = txs[i]
+	}
+
+	pending.missing = nil
+	return nil
+}
+
+func (p *peer) sendCompactBlock(block *types.Block) error {
+	if p.knownBlocks.Contains(block.HeaderHash) {
+		return nil
+	}
+
+	buff := common.SerializePanic(newCompactBlock(block))
+
+	p.log.Debug("peer send [compactBlockMsgCode] with size %d byte, height %d", len(buff), block.Header.Height)
+	err := p2p.SendMessage(p.rw, compactBlockMsgCode, buff)
+	if err == nil {
+		p.knownBlocks.Add(block.HeaderHash)
+	}
+
+	return err
+}
+
+func (p *peer) sendBlockTxsRequest(request *blockTxsRequest) error {
+	buff := common.SerializePanic(request)
+
+	p.log.Debug("peer send [blockTxsRequestMsgCode] with %d txs", len(request.Indexes))
+	return p2p.SendMessage(p.rw, blockTxsRequestMsgCode, buff)
+}
+
+func (p *peer) sendBlockTxs(response *blockTxsResponse) error {
+	buff := common.SerializePanic(response)
+
+	p.log.Debug("peer send [blockTxsMsgCode] with size %d byte", len(buff))
+	return p2p.SendMessage(p.rw, blockTxsMsgCode, buff)
+}
+
+// propagateCompactBlock announces the block to the local shard peers which do not know it yet,
+// the peers not handling the compact block get the block hash instead.
+func (sp *ScdoProtocol) propagateCompactBlock(block *types.Block) {
+	for _, peer := range sp.peerSet.getPeerByShard(common.LocalShardNumber) {
+		if !peer.supportsMsg(compactBlockMsgCode) {
+			if err := peer.SendBlockHash(block.HeaderHash); err != nil {
+				sp.log.Warn("failed to send block hash to peer=%s, err=%s", peer.peerStrID, err)
+			}
+
+			continue
+		}
+
+		if err := peer.sendCompactBlock(block); err != nil {
+			sp.log.Warn("failed to send compact block to peer=%s, err=%s", peer.peerStrID, err)
+		}
+	}
+}
+
+// handleCompactBlock reconstructs the announced block from the tx pool, and requests the missing txs if any.
+func (sp *ScdoProtocol) handleCompactBlock(peer *peer, cb *compactBlock) {
+	if cb.Header == nil || cb.Header.Creator.Shard() != common.LocalShardNumber {
+		return
+	}
+
+	peer.knownBlocks.Add(cb.HeaderHash)
+	if has, err := sp.chain.GetStore().HasBlock(cb.HeaderHash); err == nil && has {
+		return
+	}
+
+	// the block may include the queued txs together with the txs filling their nonce gaps
+	poolTxs := append(sp.txPool.GetTransactions(true, true), sp.txPool.GetQueuedTransactions()...)
+	pending := cb.reconstruct(poolTxs)
+	pending.peer = peer
+	sp.log.Debug("got compact block, height %d, hash %s, txs %d, missing %d", cb.Header.Height, cb.HeaderHash.Hex(),
+		len(pending.block.Transactions), len(pending.missing))
+
+	if len(pending.missing) == 0 {
+		sp.importCompactBlock(pending)
+		return
+	}
+
+	sp.pendingCompactBlocks.Add(cb.HeaderHash, pending)
+	request := &blockTxsRequest{
+		HeaderHash: cb.HeaderHash,
+		Indexes:    pending.missing,
+	}
+
+	if err := peer.sendBlockTxsRequest(request); err != nil {
+		sp.log.Warn("failed to send block txs request to peer=%s, err=%s", peer.peerStrID, err)
+		sp.pendingCompactBlocks.Remove(cb.HeaderHash)
+		sp.requestFullBlock(peer, cb.HeaderHash)
+	}
+}
+
+// handleBlockTxsRequest responds the requested txs of a block
+func (sp *ScdoProtocol) handleBlockTxsRequest(peer *peer, request *blockTxsRequest) {
+	response := &blockTxsResponse{
+		HeaderHash: request.HeaderHash,
+	}
+
+	// an empty response makes the peer fall back to the full block
+	if block, err := sp.chain.GetStore().GetBlock(request.HeaderHash); err == nil {
+		for _, index := range request.Indexes {
+			if index >= uint64(len(block.Transactions)) {
+				response.Txs = nil
+				break
+			}
+
+			response.Txs = append(response.Txs, block.Transactions[index])
+		}
+	} else {
+		sp.log.Debug("not found requested block of compact block txs, %s", err)
+	}
+
+	if err := peer.sendBlockTxs(response); err != nil {
+		sp.log.Warn("failed to send block txs to peer=%s, err=%s", peer.peerStrID, err)
+	}
+}
+
+// handleBlockTxs fills the pending compact block with the received txs, and falls back to the full block on failure.
+func (sp *ScdoProtocol) handleBlockTxs(peer *peer, response *blockTxsResponse) {
+	value, ok := sp.pendingCompactBlocks.Get(response.HeaderHash)
+	if !ok {
+		return
+	}
+
+	sp.pendingCompactBlocks.Remove(response.HeaderHash)
+	pending := value.(*pendingCompactBlock)
+	if pending.peer != peer || time.Now().After(pending.expire) {
+		sp.requestFullBlock(peer, response.HeaderHash)
+		return
+	}
+
+	if err := pending.fill(response.Txs); err != nil {
+		sp.log.Debug("failed to fill compact block %s, %s", response.HeaderHash.Hex(), err)
+		sp.requestFullBlock(peer, response.HeaderHash)
+		return
+	}
+
+	sp.importCompactBlock(pending)
+}
+
+// importCompactBlock writes the reconstructed block into chain and relays it,
+// the full block is requested if the reconstructed one is invalid, e.g. short ID collision.
+func (sp *ScdoProtocol) importCompactBlock(pending *pendingCompactBlock) {
+	block := pending.block
+	if err := block.Validate(); err != nil {
+		sp.log.Debug("invalid reconstructed compact block %s, %s", block.HeaderHash.Hex(), err)
+		sp.requestFullBlock(pending.peer, block.HeaderHash)
+		return
+	}
+
+	if err := sp.chain.WriteBlock(block, sp.txPool.Pool); err != nil {
+		sp.log.Debug("failed to write compact block %s, %s", block.HeaderHash.Hex(), err)
+		return
+	}
+
+	sp.propagateCompactBlock(block)
+}
+
+// requestFullBlock falls back to request the full block from the peer
+func (sp *ScdoProtocol) requestFullBlock(peer *peer, blockHash common.Hash) {
+	if err := peer.SendBlockRequest(blockHash); err != nil {
+		sp.log.Warn("failed to send block request msg to peer=%s, err=%s", peer.peerStrID, err)
+	}
+}
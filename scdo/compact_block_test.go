@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	log2 "github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCompactBlock(txCount int) *types.Block {
+	block := &types.Block{
+		HeaderHash: common.StringToHash("block"),
+		Header:     &types.BlockHeader{Height: 10},
+	}
+
+	for i := 0; i < txCount; i++ {
+		block.Transactions = append(block.Transactions, &types.Transaction{
+			Hash: crypto.MustHash(fmt.Sprintf("tx%d", i)),
+		})
+	}
+
+	return block
+}
+
+func Test_CompactBlock_Reconstruct(t *testing.T) {
+	block := newTestCompactBlock(4)
+	cb := newCompactBlock(block)
+	assert.Equal(t, len(cb.Prefilled), 1)
+	assert.Equal(t, len(cb.ShortIDs), 3)
+
+	// all txs in pool
+	pending := cb.reconstruct(block.Transactions[1:])
+	assert.Equal(t, len(pending.missing), 0)
+	assert.Equal(t, pending.block.Transactions, block.Transactions)
+
+	// tx 2 missing
+	pending = cb.reconstruct([]*types.Transaction{block.Transactions[1], block.Transactions[3]})
+	assert.Equal(t, pending.missing, []uint64{2})
+	assert.Equal(t, pending.fill([]*types.Transaction{block.Transactions[3]}), errCompactBlockTxsMismatch)
+	assert.Equal(t, pending.fill(nil), errCompactBlockTxsMismatch)
+	assert.Equal(t, pending.fill([]*types.Transaction{block.Transactions[2]}), nil)
+	assert.Equal(t, pending.block.Transactions, block.Transactions)
+}
+
+func Test_CompactBlock_Empty(t *testing.T) {
+	block := newTestCompactBlock(0)
+	cb := newCompactBlock(block)
+	assert.Equal(t, len(cb.Prefilled), 0)
+
+	pending := cb.reconstruct(nil)
+	assert.Equal(t, len(pending.missing), 0)
+	assert.Equal(t, len(pending.block.Transactions), 0)
+}
+
+func Test_PropagateCompactBlock_LegacyPeer(t *testing.T) {
+	newTestPeer := func(statusVersion uint32) (*peer, *mockGossipMsgReadWriter) {
+		n := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, common.LocalShardNumber)
+		rw := &mockGossipMsgReadWriter{make(chan *p2p.Message, 10)}
+		p := newPeer(common.ScdoVersion, &p2p.Peer{Node: n}, rw, log2.GetLogger("test"), node.PeerKnownCacheConfig{})
+		p.statusVersion = statusVersion
+		return p, rw
+	}
+
+	legacy, legacyRW := newTestPeer(uint32(common.ScdoVersion))
+	upgraded, upgradedRW := newTestPeer(statusProtocolVersion)
+
+	sp := &ScdoProtocol{peerSet: newPeerSet(), log: log2.GetLogger("test")}
+	sp.peerSet.Add(legacy)
+	sp.peerSet.Add(upgraded)
+
+	block := newTestCompactBlock(2)
+	sp.propagateCompactBlock(block)
+
+	// the legacy peer gets the block hash instead of the unknown message code
+	assert.Equal(t, (<-legacyRW.msgs).Code, blockHashMsgCode)
+	assert.Equal(t, (<-upgradedRW.msgs).Code, compactBlockMsgCode)
+	assert.Equal(t, legacy.knownBlocks.Contains(block.HeaderHash), true)
+
+	// announced once
+	sp.propagateCompactBlock(block)
+	assert.Equal(t, len(legacyRW.msgs), 0)
+	assert.Equal(t, len(upgradedRW.msgs), 0)
+}
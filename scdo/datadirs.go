@@ -0,0 +1,205 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/node"
+)
+
+// migratingSuffix is the suffix of the database being copied across disks, which is renamed once completed
+const migratingSuffix = ".migrating"
+
+// DataDirs is the paths of the databases of the node
+type DataDirs struct {
+	ChainDB        string
+	AccountStateDB string
+	DebtManagerDB  string
+}
+
+// dbDir is a database with its default path under the data dir and the configured path
+type dbDir struct {
+	name       string
+	defaultDir string
+	dir        string
+}
+
+// ResolveDataDirs returns the paths of the databases, which are under the data dir unless configured.
+// The relative paths are based on the data dir. Returns error if the paths are the same or nested, in
+// which case the databases are mixed.
+func ResolveDataDirs(dataDir string, conf node.DataDirsConfig) (*DataDirs, error) {
+	resolve := func(path, defaultDir string) (string, error) {
+		if path == "" {
+			path = filepath.Join(dataDir, defaultDir)
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(dataDir, path)
+		}
+
+		return filepath.Abs(path)
+	}
+
+	var dirs DataDirs
+	var err error
+	if dirs.ChainDB, err = resolve(conf.ChainDB, BlockChainDir); err != nil {
+		return nil, err
+	}
+
+	if dirs.AccountStateDB, err = resolve(conf.AccountStateDB, AccountStateDir); err != nil {
+		return nil, err
+	}
+
+	if dirs.DebtManagerDB, err = resolve(conf.DebtManagerDB, DebtManagerDir); err != nil {
+		return nil, err
+	}
+
+	root, err := filepath.Abs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	all := dirs.list(dataDir)
+	for i, a := range all {
+		if isSubDir(root, a.dir) {
+			return nil, fmt.Errorf("the %s path %s contains the data dir %s", a.name, a.dir, root)
+		}
+
+		for _, b := range all[i+1:] {
+			if isSubDir(a.dir, b.dir) || isSubDir(b.dir, a.dir) {
+				return nil, fmt.Errorf("the %s path %s and the %s path %s overlap", a.name, a.dir, b.name, b.dir)
+			}
+		}
+	}
+
+	return &dirs, nil
+}
+
+func (dirs *DataDirs) list(dataDir string) []dbDir {
+	return []dbDir{
+		{"chain db", filepath.Join(dataDir, BlockChainDir), dirs.ChainDB},
+		{"account state db", filepath.Join(dataDir, AccountStateDir), dirs.AccountStateDB},
+		{"debt manager db", filepath.Join(dataDir, DebtManagerDir), dirs.DebtManagerDB},
+	}
+}
+
+// MigrateDataDirs moves the databases from their default paths under the data dir to the configured
+// paths, e.g. the databases restored from a snapshot. The databases are copied if on different disks.
+// Returns the names of the migrated databases, or error if a database exists in both paths.
+func MigrateDataDirs(dataDir string, dirs *DataDirs) ([]string, error) {
+	var migrated []string
+	for _, d := range dirs.list(dataDir) {
+		src, err := filepath.Abs(d.defaultDir)
+		if err != nil {
+			return migrated, err
+		}
+
+		if src == d.dir {
+			continue
+		}
+
+		if _, err = os.Stat(src); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return migrated, err
+		}
+
+		if _, err = os.Stat(d.dir); err == nil {
+			return migrated, fmt.Errorf("the %s exists in both %s and %s", d.name, src, d.dir)
+		}
+
+		if err = moveDir(src, d.dir); err != nil {
+			return migrated, errors.NewStackedErrorf(err, "failed to move the %s from %s to %s", d.name, src, d.dir)
+		}
+
+		migrated = append(migrated, d.name)
+	}
+
+	return migrated, nil
+}
+
+// moveDir renames the src dir to dst, or copies it to dst and removes the src if they are on different disks
+func moveDir(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	// copy to a temp dir first, so that a broken copy is never opened as the database
+	tmpDir := dst + migratingSuffix
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+
+	if err := copyDir(src, tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	if err := os.Rename(tmpDir, dst); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(src)
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err = out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// isSubDir returns whether the path is the dir or under the dir
+func isSubDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,96 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ResolveDataDirs(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "datadirs")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dataDir)
+
+	dirs, err := ResolveDataDirs(dataDir, node.DataDirsConfig{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, dirs.ChainDB, filepath.Join(dataDir, BlockChainDir))
+	assert.Equal(t, dirs.AccountStateDB, filepath.Join(dataDir, AccountStateDir))
+	assert.Equal(t, dirs.DebtManagerDB, filepath.Join(dataDir, DebtManagerDir))
+
+	ssd := filepath.Join(dataDir, "ssd", "state")
+	dirs, err = ResolveDataDirs(dataDir, node.DataDirsConfig{AccountStateDB: ssd, ChainDB: "hdd"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, dirs.ChainDB, filepath.Join(dataDir, "hdd"))
+	assert.Equal(t, dirs.AccountStateDB, ssd)
+
+	// nested paths
+	_, err = ResolveDataDirs(dataDir, node.DataDirsConfig{ChainDB: "db", AccountStateDB: "db/state"})
+	assert.Equal(t, err != nil, true)
+
+	_, err = ResolveDataDirs(dataDir, node.DataDirsConfig{ChainDB: "hdd", DebtManagerDB: "hdd"})
+	assert.Equal(t, err != nil, true)
+
+	_, err = ResolveDataDirs(dataDir, node.DataDirsConfig{ChainDB: filepath.Dir(dataDir)})
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_MigrateDataDirs(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "datadirs")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dataDir)
+
+	for _, dir := range []string{BlockChainDir, AccountStateDir} {
+		assert.Equal(t, os.MkdirAll(filepath.Join(dataDir, dir, "sub"), 0755), nil)
+		assert.Equal(t, ioutil.WriteFile(filepath.Join(dataDir, dir, "sub", "CURRENT"), []byte(dir), 0644), nil)
+	}
+
+	dirs, err := ResolveDataDirs(dataDir, node.DataDirsConfig{AccountStateDB: "ssd/state", DebtManagerDB: "ssd/debt"})
+	assert.Equal(t, err, nil)
+
+	migrated, err := MigrateDataDirs(dataDir, dirs)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, migrated, []string{"account state db"})
+
+	content, err := ioutil.ReadFile(filepath.Join(dirs.AccountStateDB, "sub", "CURRENT"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(content), AccountStateDir)
+
+	_, err = os.Stat(filepath.Join(dataDir, AccountStateDir))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	// nothing to migrate
+	migrated, err = MigrateDataDirs(dataDir, dirs)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(migrated), 0)
+
+	// the database exists in both paths
+	assert.Equal(t, os.MkdirAll(filepath.Join(dataDir, AccountStateDir), 0755), nil)
+	_, err = MigrateDataDirs(dataDir, dirs)
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_CopyDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datadirs")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	assert.Equal(t, os.MkdirAll(filepath.Join(src, "sub"), 0755), nil)
+	assert.Equal(t, ioutil.WriteFile(filepath.Join(src, "sub", "000001.ldb"), []byte("data"), 0644), nil)
+
+	dst := filepath.Join(dir, "dst")
+	assert.Equal(t, copyDir(src, dst), nil)
+
+	content, err := ioutil.ReadFile(filepath.Join(dst, "sub", "000001.ldb"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(content), "data")
+}
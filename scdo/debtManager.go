@@ -27,6 +27,14 @@ type propagateDebts interface {
 
 const (
 	checkInterval = 12 * common.BlockPackInterval
+
+	// debtAckExpiry is the duration after which an acknowledged but not packed debt is resent, in case
+	// it is dropped by the debt pools of the target shard.
+	debtAckExpiry = 10 * checkInterval
+
+	// debtUnreachableResends is the number of the resends without ack, after which the target shard
+	// of the debt is reported unreachable.
+	debtUnreachableResends = 3
 )
 
 var maxDebtBatchSize = 5000
@@ -37,6 +45,28 @@ type DebtInfo struct {
 
 	// debt is packed, but not confirmed. confirmed block will be removed from debt manager.
 	isPacked bool
+
+	// time when the debt is acknowledged by a target shard peer, zero if not acknowledged yet
+	ackTimestamp time.Time
+
+	// number of the resends since the debt is added or acknowledged
+	resends int
+}
+
+// isAcked returns whether the debt is acknowledged by a target shard peer and the ack is not expired
+func (info *DebtInfo) isAcked(now time.Time) bool {
+	return !info.ackTimestamp.IsZero() && now.Sub(info.ackTimestamp) < debtAckExpiry
+}
+
+// DebtShardStatus is the propagation status of the debts to a target shard
+type DebtShardStatus struct {
+	Shard   uint
+	Pending int       // debts tracked and not confirmed
+	Acked   int       // debts acknowledged by the peers of the shard
+	LastAck time.Time // last time a debt is acknowledged by the peers of the shard, zero if never
+
+	// whether there is a debt resent debtUnreachableResends times without being acknowledged
+	Unreachable bool
 }
 
 type DebtManager struct {
@@ -49,6 +79,8 @@ type DebtManager struct {
 	chain       *core.Blockchain
 	blockHeights []uint64 
 	dmDB        database.Database
+
+	lastAcks map[uint]time.Time // last ack time of each target shard
 }
 
 func NewDebtManager(debtChecker types.DebtVerifier, p propagateDebts, chain *core.Blockchain, debtManagerDB database.Database) *DebtManager {
@@ -60,6 +92,7 @@ func NewDebtManager(debtChecker types.DebtVerifier, p propagateDebts, chain *cor
 		log:         log.GetLogger("debt_manager"),
 		chain:       chain,
 		dmDB:        debtManagerDB, 
+		lastAcks:    make(map[uint]time.Time),
 	}
 }
 
@@ -141,6 +174,87 @@ func (m *DebtManager) Has(hash common.Hash) bool {
 	return m.debts[hash] != nil
 }
 
+// Get returns the propagation info of the debt, or nil if the debt is not in debt manager
+func (m *DebtManager) Get(hash common.Hash) *DebtInfo {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	info := m.debts[hash]
+	if info == nil {
+		return nil
+	}
+
+	copied := *info
+
+	return &copied
+}
+
+// Ack marks the debts acknowledged by a peer of the shard, which are not resent until the ack expires.
+// The debts of other target shards are skipped, and returns the number of the acknowledged debts.
+func (m *DebtManager) Ack(shard uint, hashes []common.Hash) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	acked := 0
+	for _, hash := range hashes {
+		info := m.debts[hash]
+		if info == nil || info.debt.Data.Account.Shard() != shard {
+			continue
+		}
+
+		info.ackTimestamp = now
+		info.resends = 0
+		acked++
+	}
+
+	if acked > 0 {
+		m.lastAcks[shard] = now
+	}
+
+	return acked
+}
+
+// ShardStatus returns the propagation status of the debts to each target shard which has pending debts
+// or ever acknowledged the debts, ordered by shard.
+func (m *DebtManager) ShardStatus() []*DebtShardStatus {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	status := make(map[uint]*DebtShardStatus)
+	get := func(shard uint) *DebtShardStatus {
+		if status[shard] == nil {
+			status[shard] = &DebtShardStatus{Shard: shard, LastAck: m.lastAcks[shard]}
+		}
+
+		return status[shard]
+	}
+
+	for shard := range m.lastAcks {
+		get(shard)
+	}
+
+	for _, info := range m.debts {
+		s := get(info.debt.Data.Account.Shard())
+		s.Pending++
+		if info.isAcked(now) {
+			s.Acked++
+		} else if info.resends >= debtUnreachableResends {
+			s.Unreachable = true
+		}
+	}
+
+	var results []*DebtShardStatus
+	for shard := uint(0); shard <= common.ShardCount; shard++ {
+		if s := status[shard]; s != nil {
+			results = append(results, s)
+		}
+	}
+
+	return results
+}
+
 // checking resend debt if it is not packed after timeout
 func (m *DebtManager) checking() {
 	toChecking := m.GetAll()
@@ -185,27 +299,51 @@ func (m *DebtManager) checking() {
 	pool.Close()
 
 	// resend
-	toSend := make([][]*types.Debt, common.ShardCount+1)
-	for _, info := range toChecking {
-		// if the debt is not packed or confirmed, we will send it again.
-		if !info.isPacked && m.Has(info.debt.Hash) {
-			shard := info.debt.Data.Account.Shard()
-			if len(toSend[shard]) < maxDebtBatchSize {
-				toSend[shard] = append(toSend[shard], info.debt)
-			}
+	toSend := m.toResend(toChecking)
+	m.propagation.propagateDebtMap(toSend, false)
 
-			m.log.Debug("debt is not packed or confirmed, send again. hash:%s", info.debt.Hash.Hex())
+	for _, s := range m.ShardStatus() {
+		if s.Unreachable {
+			m.log.Warn("shard %d is unreachable, %d debts pending, %d acknowledged, last ack at %s", s.Shard, s.Pending, s.Acked, s.LastAck)
 		}
 	}
 
-	m.propagation.propagateDebtMap(toSend, false)
-
 	err := m.reinjectDebtFromDatabase()
 	if err != nil {
 		m.log.Warn("Error in debt reinjection")
 	}
 }
 
+// toResend returns the debts to send again by target shard, which are neither packed nor acknowledged
+func (m *DebtManager) toResend(infos []*DebtInfo) [][]*types.Debt {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	toSend := make([][]*types.Debt, common.ShardCount+1)
+	for _, info := range infos {
+		// if the debt is not packed or confirmed, we will send it again.
+		if info.isPacked || m.debts[info.debt.Hash] == nil {
+			continue
+		}
+
+		// the target shard received the debt, wait for it to be packed
+		if info.isAcked(now) {
+			continue
+		}
+
+		shard := info.debt.Data.Account.Shard()
+		if len(toSend[shard]) < maxDebtBatchSize {
+			toSend[shard] = append(toSend[shard], info.debt)
+			info.resends++
+		}
+
+		m.log.Debug("debt is not packed or confirmed, send again. hash:%s", info.debt.Hash.Hex())
+	}
+
+	return toSend
+}
+
 func (m *DebtManager) TimingChecking() {
 	for {
 		m.log.Debug("start checking")
@@ -0,0 +1,73 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDebtToShard(shard uint) *types.Debt {
+	account := *crypto.MustGenerateShardAddress(shard)
+	return &types.Debt{Hash: crypto.HashBytes(account.Bytes()), Data: types.DebtData{Account: account}}
+}
+
+func Test_DebtManager_Ack(t *testing.T) {
+	m := NewDebtManager(nil, nil, nil, nil)
+	d1, d2 := newTestDebtToShard(1), newTestDebtToShard(2)
+	m.AddDebts([]*types.Debt{d1, d2})
+
+	// acked by the peer of other shard
+	assert.Equal(t, m.Ack(2, []common.Hash{d1.Hash}), 0)
+	assert.Equal(t, m.Ack(1, []common.Hash{d1.Hash, common.StringToHash("unknown")}), 1)
+	assert.Equal(t, m.Get(d1.Hash).isAcked(time.Now()), true)
+	assert.Equal(t, m.Get(d2.Hash).isAcked(time.Now()), false)
+
+	// the acked debt is not resent until the ack expires
+	toSend := m.toResend(m.GetAll())
+	assert.Equal(t, len(toSend[1]), 0)
+	assert.Equal(t, toSend[2], []*types.Debt{d2})
+
+	m.debts[d1.Hash].ackTimestamp = time.Now().Add(-debtAckExpiry)
+	toSend = m.toResend(m.GetAll())
+	assert.Equal(t, toSend[1], []*types.Debt{d1})
+}
+
+func Test_DebtManager_ShardStatus(t *testing.T) {
+	m := NewDebtManager(nil, nil, nil, nil)
+	d1, d2 := newTestDebtToShard(1), newTestDebtToShard(2)
+	m.AddDebts([]*types.Debt{d1, d2})
+	m.Ack(1, []common.Hash{d1.Hash})
+
+	for i := 0; i < debtUnreachableResends; i++ {
+		m.toResend(m.GetAll())
+	}
+
+	status := m.ShardStatus()
+	assert.Equal(t, len(status), 2)
+	assert.Equal(t, status[0].Shard, uint(1))
+	assert.Equal(t, status[0].Pending, 1)
+	assert.Equal(t, status[0].Acked, 1)
+	assert.Equal(t, status[0].LastAck.IsZero(), false)
+	assert.Equal(t, status[0].Unreachable, false)
+
+	assert.Equal(t, status[1].Shard, uint(2))
+	assert.Equal(t, status[1].Acked, 0)
+	assert.Equal(t, status[1].LastAck.IsZero(), true)
+	assert.Equal(t, status[1].Unreachable, true)
+
+	// reachable once acked, and the shard status is kept after the debts are confirmed
+	m.Ack(2, []common.Hash{d2.Hash})
+	m.Remove(d2.Hash)
+	status = m.ShardStatus()
+	assert.Equal(t, status[1].Pending, 0)
+	assert.Equal(t, status[1].Unreachable, false)
+}
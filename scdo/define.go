@@ -18,7 +18,8 @@ const (
 
 	forceSyncInterval = time.Second * 7 // interval time of synchronising with remote peer
 
-	txsyncPackSize = 1024
+	// defaultTxSyncPackSize is the default number of pending txs sent in a message to the new peer
+	defaultTxSyncPackSize = 1024
 
 	// AccountStateDir account state info directory based on config.DataRoot
 	AccountStateDir = "/db/accountState"
@@ -28,6 +29,12 @@ const (
 
 	// BlockChainRecoveryPointFile is used to store the recovery point info of blockchain.
 	BlockChainRecoveryPointFile = "recoveryPoint.json"
+
+	// StateMismatchReportDir is the folder of the state root mismatch reports based on config.DataRoot
+	StateMismatchReportDir = "statemismatch"
+
+	// BackupDir is the default folder of the chain backups based on config.DataRoot
+	BackupDir = "backup"
 )
 
 // statusData the structure for peers to exchange status
@@ -0,0 +1,219 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/node"
+)
+
+const (
+	defaultDiskQuotaInterval   = 10 * time.Minute
+	defaultDiskQuotaKeepBlocks = 100000
+
+	// minDiskQuotaKeepBlocks keeps the receipts of the recent blocks, which are checked for the chain
+	// integrity at startup and used by the reorgs and the chain event consumers
+	minDiskQuotaKeepBlocks = 1024
+
+	// diskQuotaPruneBatch is the number of blocks pruned in a batch
+	diskQuotaPruneBatch = 1000
+)
+
+// diskQuotaChain is the blockchain to prune the block data
+type diskQuotaChain interface {
+	CurrentBlock() *types.Block
+	GetStore() store.BlockchainStore
+}
+
+// compacter is the database which reclaims the disk space of the deleted keys by compaction
+type compacter interface {
+	Compact() error
+}
+
+// diskQuota checks the disk usage of the chain database, and prunes the receipts and dirty accounts
+// of the oldest blocks when the quota is exceeded, for the nodes with small disks.
+type diskQuota struct {
+	chain  diskQuotaChain
+	db     database.Database
+	dbPath string
+	log    *log.ScdoLog
+
+	maxSize    int64 // in bytes
+	keepBlocks uint64
+	interval   time.Duration
+
+	quitCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newDiskQuota(chain diskQuotaChain, db database.Database, dbPath string, conf node.DiskQuotaConfig) *diskQuota {
+	q := &diskQuota{
+		chain:      chain,
+		db:         db,
+		dbPath:     dbPath,
+		log:        log.GetLogger("diskquota"),
+		maxSize:    int64(conf.MaxChainDBSize) * 1024 * 1024,
+		keepBlocks: conf.KeepBlocks,
+		interval:   durationOrDefault(conf.Interval, defaultDiskQuotaInterval),
+		quitCh:     make(chan struct{}),
+	}
+
+	if q.keepBlocks == 0 {
+		q.keepBlocks = defaultDiskQuotaKeepBlocks
+	} else if q.keepBlocks < minDiskQuotaKeepBlocks {
+		q.keepBlocks = minDiskQuotaKeepBlocks
+	}
+
+	return q
+}
+
+func (q *diskQuota) start() {
+	q.wg.Add(1)
+	go q.loop()
+}
+
+func (q *diskQuota) stop() {
+	close(q.quitCh)
+	q.wg.Wait()
+}
+
+func (q *diskQuota) loop() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	q.check()
+
+	for {
+		select {
+		case <-ticker.C:
+			q.check()
+		case <-q.quitCh:
+			return
+		}
+	}
+}
+
+func (q *diskQuota) check() {
+	size, err := dirSize(q.dbPath)
+	if err != nil {
+		q.log.Warn("failed to get the disk usage of the chain database, %s", err)
+		return
+	}
+
+	metrics.MetricsChainDBSizeGauge.Update(size)
+	if size <= q.maxSize {
+		return
+	}
+
+	reclaimed, blocks, err := q.prune(size - q.maxSize)
+	if err != nil {
+		q.log.Warn("failed to prune the block data, %s", err)
+	}
+
+	if blocks == 0 {
+		q.log.Warn("chain database size %d exceeds the quota %d, but no block data to prune", size, q.maxSize)
+		return
+	}
+
+	if c, ok := q.db.(compacter); ok {
+		if err = c.Compact(); err != nil {
+			q.log.Warn("failed to compact the chain database, %s", err)
+		}
+	}
+
+	if newSize, err := dirSize(q.dbPath); err == nil {
+		metrics.MetricsChainDBSizeGauge.Update(newSize)
+		size = newSize
+	}
+
+	q.log.Info("pruned the receipts and dirty accounts of %d blocks, %d bytes reclaimed, chain database size %d, quota %d",
+		blocks, reclaimed, size, q.maxSize)
+}
+
+// prune prunes the receipts and dirty accounts of the oldest blocks until the target bytes are reclaimed,
+// except the most recent blocks. Returns the reclaimed bytes and the number of the pruned blocks.
+func (q *diskQuota) prune(target int64) (reclaimed int64, blocks uint64, err error) {
+	head := q.chain.CurrentBlock().Header.Height
+	if head <= q.keepBlocks {
+		return 0, 0, nil
+	}
+
+	bcStore := q.chain.GetStore()
+	limit := head - q.keepBlocks
+
+	// start from the genesis block if never pruned
+	height, err := bcStore.GetPrunedHeight()
+	if err != nil {
+		height = 0
+	}
+
+	for height < limit && reclaimed < target {
+		select {
+		case <-q.quitCh:
+			return reclaimed, blocks, nil
+		default:
+		}
+
+		end := height + diskQuotaPruneBatch
+		if end > limit {
+			end = limit
+		}
+
+		hashes := make([]common.Hash, 0, end-height)
+		for h := height + 1; h <= end; h++ {
+			hash, err := bcStore.GetBlockHash(h)
+			if err != nil {
+				return reclaimed, blocks, err
+			}
+
+			hashes = append(hashes, hash)
+		}
+
+		size, err := bcStore.PruneBlockData(hashes, end)
+		if err != nil {
+			return reclaimed, blocks, err
+		}
+
+		reclaimed += int64(size)
+		blocks += end - height
+		height = end
+
+		metrics.MetricsDiskQuotaReclaimedMeter.Mark(int64(size))
+		metrics.MetricsDiskQuotaPrunedBlocksMeter.Mark(int64(len(hashes)))
+	}
+
+	return reclaimed, blocks, nil
+}
+
+// dirSize returns the total size in bytes of the files in the directory
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
@@ -0,0 +1,80 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiskQuota_Prune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diskquota")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.NewLevelDB(dir)
+	assert.Equal(t, err, nil)
+	defer db.Close()
+
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	blocks := []*types.Block{chain.putBlock(t, nil, "")}
+	for i := 1; i <= 20; i++ {
+		block := chain.putBlock(t, blocks[i-1], "")
+		assert.Equal(t, chain.bcStore.PutReceipts(block.HeaderHash, []*types.Receipt{{UsedGas: uint64(i)}}), nil)
+		blocks = append(blocks, block)
+	}
+	chain.head = blocks[20]
+
+	q := newDiskQuota(chain, db, dir, node.DiskQuotaConfig{MaxChainDBSize: 1, KeepBlocks: 5})
+	assert.Equal(t, q.keepBlocks, uint64(minDiskQuotaKeepBlocks))
+	q.keepBlocks = 5
+
+	// the quota is not exceeded
+	q.check()
+	_, err = chain.bcStore.GetPrunedHeight()
+	assert.Equal(t, err != nil, true)
+
+	// prune the oldest blocks until the target bytes are reclaimed
+	reclaimed, pruned, err := q.prune(1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, reclaimed > 0, true)
+	assert.Equal(t, pruned, uint64(15))
+
+	height, err := chain.bcStore.GetPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, uint64(15))
+
+	_, err = chain.bcStore.GetReceiptsByBlockHash(blocks[15].HeaderHash)
+	assert.Equal(t, err != nil, true)
+	receipts, err := chain.bcStore.GetReceiptsByBlockHash(blocks[16].HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipts[0].UsedGas, uint64(16))
+
+	// the block bodies are kept
+	block, err := chain.bcStore.GetBlockByHeight(1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, block.HeaderHash, blocks[1].HeaderHash)
+
+	// nothing to prune until the head moves
+	_, pruned, err = q.prune(1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pruned, uint64(0))
+
+	// the quota is exceeded, prune the blocks behind the new head
+	chain.head = chain.putBlock(t, blocks[20], "")
+	q.maxSize = 1
+	q.check()
+	height, err = chain.bcStore.GetPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, uint64(16))
+}
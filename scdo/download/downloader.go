@@ -19,6 +19,7 @@ import (
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/event"
 	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
 	"github.com/scdoproject/go-scdo/p2p"
 )
 
@@ -178,6 +179,19 @@ func (d *Downloader) getSyncInfo(info *SyncInfo) {
 	info.Downloaded = d.tm.downloadedNum
 }
 
+// SyncTarget returns the height of the last block to download in the current session,
+// false if the blocks are not being downloaded.
+func (d *Downloader) SyncTarget() (uint64, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	if d.syncStatus != statusFetching {
+		return 0, false
+	}
+
+	return d.tm.toNo, true
+}
+
 // Synchronise try to sync with remote peer.
 func (d *Downloader) Synchronise(id string, head common.Hash) error {
 	// Make sure only one routine can pass at once
@@ -292,7 +306,12 @@ func (d *Downloader) fetchHeight(conn *peerConn) (*types.BlockHeader, error) {
 		return nil, err
 	}
 
-	return verifyBlockHeadersMsg(msg, head)
+	header, err := verifyBlockHeadersMsg(msg, head)
+	if err != nil {
+		conn.stats.recordFailure(time.Now())
+	}
+
+	return header, err
 }
 
 func verifyBlockHeadersMsg(msg interface{}, head common.Hash) (*types.BlockHeader, error) {
@@ -490,13 +509,17 @@ outLoop:
 			magic := rand2.Uint32()
 			d.log.Debug("request header by number. start=%d, amount=%d, magic=%d, id=%s", startNo, amount, magic, conn.peerID)
 
+			span := tracing.StartSpan("downloader.headers", tracing.String("peer", conn.peerID), tracing.Uint("start", startNo), tracing.Int("amount", int64(amount)))
+			requested := time.Now()
 			go conn.peer.RequestHeadersByHashOrNumber(magic, common.Hash{}, startNo, amount, false)
 
 			msg, err := conn.waitMsg(magic, BlockHeadersMsg, d.cancelCh)
 			if err != nil {
+				span.EndWithError(err)
 				d.log.Debug("peerDownload waitMsg BlockHeadersMsg err! err=%s, magic=%d, id=%s", err, magic, conn.peerID)
 				break
 			}
+			span.End()
 
 			headers := msg.([]*types.BlockHeader)
 			startHeight := uint64(0)
@@ -507,12 +530,20 @@ outLoop:
 			}
 
 			d.log.Debug("got block header msg length= %d. start=%d, end=%d, magic=%d, id=%s", len(headers), startHeight, endHeight, magic, conn.peerID)
+			slow := conn.stats.recordDelivery(len(headers), time.Since(requested))
 
 			if err = tm.deliverHeaderMsg(peerID, headers); err != nil {
+				conn.stats.recordFailure(time.Now())
 				d.log.Warn("peerDownload deliverHeaderMsg err! %s", err)
 				break
 			}
 
+			// the master cancels the session, so that the sync is retried with the next best peer
+			if slow {
+				d.log.Info("peerDownload rotate away from slow peer %s", conn.peerID)
+				break
+			}
+
 			d.log.Debug("get request header info success")
 		}
 
@@ -523,13 +554,17 @@ outLoop:
 			magic := rand2.Uint32()
 			d.log.Debug("request block by number. start=%d, amount=%d, magic=%d, id=%s", startNo, amount, magic, conn.peerID)
 
+			span := tracing.StartSpan("downloader.blocks", tracing.String("peer", conn.peerID), tracing.Uint("start", startNo), tracing.Int("amount", int64(amount)))
+			requested := time.Now()
 			go conn.peer.RequestBlocksByHashOrNumber(magic, common.Hash{}, startNo, amount)
 
 			msg, err := conn.waitMsg(magic, BlocksMsg, d.cancelCh)
 			if err != nil {
+				span.EndWithError(err)
 				d.log.Debug("peerDownload waitMsg BlocksMsg err! err=%s", err)
 				break
 			}
+			span.End()
 
 			blocks := msg.([]*types.Block)
 			startHeight := uint64(0)
@@ -540,8 +575,14 @@ outLoop:
 			}
 			d.log.Debug("got blocks message length=%d. start=%d, end=%d, magic=%d, id=%s", len(blocks), startHeight, endHeight, magic, conn.peerID)
 
+			slow := conn.stats.recordDelivery(len(blocks), time.Since(requested))
 			tm.deliverBlockMsg(peerID, blocks)
 			d.log.Debug("get request blocks success")
+
+			if slow {
+				d.log.Info("peerDownload rotate away from slow peer %s", conn.peerID)
+				break
+			}
 		}
 
 		if hasReqData {
@@ -576,15 +617,37 @@ func (d *Downloader) processBlocks(headInfos []*downloadInfo, ancestor uint64, l
 	if len(headInfos) > 0 {
 		d.log.Info(" [%d] blocks will be processed into local database", len(headInfos))
 	}
-	for _, h := range headInfos {
+
+	span := tracing.StartSpan("downloader.processBlocks", tracing.Int("blocks", int64(len(headInfos))), tracing.String("peer", conn.peerID))
+	defer span.End()
+
+	// verify the merkle roots of the blocks concurrently while the blocks are written in order
+	blocks := make([]*types.Block, len(headInfos))
+	for i, h := range headInfos {
+		blocks[i] = h.block
+	}
+	prevalidated := d.chain.PrevalidateBlocks(blocks)
+
+	for i, h := range headInfos {
 		// add it for all received block messages
 		d.log.Info("got block message and save it. height=%d, hash=%s, time=%d", h.block.Header.Height, h.block.HeaderHash.Hex(), time.Now().UnixNano())
+		err := <-prevalidated[i]
+		if err != nil {
+			// the peer sent a bad block
+			d.log.Error("failed to prevalidate block %d, hash=%s, err=%s", h.block.Header.Height, h.block.HeaderHash.Hex(), err)
+			span.SetError(err)
+			conn.peer.DisconnectPeer("peerDownload bad block")
+			d.Cancel()
+			break
+		}
+
 		// writeblock
 		txPool := d.scdo.TxPool().Pool
-		err := d.chain.WriteBlock(h.block, txPool)
+		err = d.chain.WriteBlock(h.block, txPool)
 
 		if err != nil && !errors.IsOrContains(err, core.ErrBlockAlreadyExists) {
 			d.log.Error("failed to write block err=%s", err)
+			span.SetError(err)
 			// recover local blocks if localTotalDifficulty is larger than the synchronized total difficulty
 			// if writeblock fails in the middle (the whole process not successfully completed), then we need to consider write back our localblocks
 			// if localblock totaldifficult is larger than the break point's one. It means this sync attempt should be abonded
@@ -40,6 +40,7 @@ type peerConn struct {
 	peer           Peer
 	waitingMsgMap  map[uint16]chan *p2p.Message
 	lockForWaiting sync.RWMutex
+	stats          peerStats
 
 	log    *log.ScdoLog
 	quitCh chan struct{}
@@ -118,6 +119,7 @@ Again:
 	case <-timeout.C:
 		p.log.Debug("Downloader.waitMsg  timeout msg=%s pid=%s", CodeToStr(msgCode), p.peerID)
 		//err = fmt.Errorf("Download.peerconn wait for msg %s timeout.magic= %d ip= %s", CodeToStr(msgCode), magic, p.peerID)
+		p.stats.recordFailure(time.Now())
 		err = errReceivedQuitMsg
 	}
 
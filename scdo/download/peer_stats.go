@@ -0,0 +1,144 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package downloader
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// statsSmoothing is the weight of the latest measurement in the rolling stats
+	statsSmoothing = 0.3
+
+	// failureHalfLife is the time for the recent failures of a peer to decay by half
+	failureHalfLife = 10 * time.Minute
+
+	// defaultDeliveryRate is the optimistic delivery rate of the peers not measured yet, so that they are tried
+	defaultDeliveryRate = 100
+
+	// slowDeliveryRate is the delivery rate in items per second below which a delivery is slow
+	slowDeliveryRate = 2
+
+	// maxSlowDeliveries is the number of consecutive slow deliveries to rotate away from the peer mid-sync
+	maxSlowDeliveries = 3
+)
+
+// PeerSyncStats is the rolling stats of the sync requests to a peer
+type PeerSyncStats struct {
+	Latency      time.Duration // rolling round trip time of the requests
+	DeliveryRate float64       // rolling items delivered per second, including the round trip time
+	Failures     float64       // recent failed requests, decayed by half every failureHalfLife
+	Deliveries   uint64        // number of the measured deliveries
+}
+
+// Score returns the health of the peer to rank the sync peers, the higher the better
+func (s PeerSyncStats) Score() float64 {
+	rate := s.DeliveryRate
+	if s.Deliveries == 0 {
+		rate = defaultDeliveryRate
+	}
+
+	return rate / (1 + s.Failures)
+}
+
+// peerStats tracks the rolling stats of the sync requests to a peer
+type peerStats struct {
+	stats       PeerSyncStats
+	lastFailure time.Time
+	slow        int // consecutive slow deliveries
+	lock        sync.Mutex
+}
+
+// recordDelivery records a delivered response of the items, and returns whether the peer is consistently slow.
+func (s *peerStats) recordDelivery(items int, elapsed time.Duration) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stats.Latency == 0 {
+		s.stats.Latency = elapsed
+	} else {
+		s.stats.Latency = time.Duration(statsSmoothing*float64(elapsed) + (1-statsSmoothing)*float64(s.stats.Latency))
+	}
+
+	// the empty response is not a measure of the throughput, e.g. the peer has no more blocks
+	if items == 0 {
+		return false
+	}
+
+	rate := float64(items) / math.Max(elapsed.Seconds(), 0.001)
+	if s.stats.Deliveries == 0 {
+		s.stats.DeliveryRate = rate
+	} else {
+		s.stats.DeliveryRate = statsSmoothing*rate + (1-statsSmoothing)*s.stats.DeliveryRate
+	}
+	s.stats.Deliveries++
+
+	if rate < slowDeliveryRate {
+		s.slow++
+	} else {
+		s.slow = 0
+	}
+
+	return s.slow >= maxSlowDeliveries
+}
+
+// recordFailure records a failed request, e.g. timeout or invalid response
+func (s *peerStats) recordFailure(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stats.Failures = s.decayedFailures(now) + 1
+	s.lastFailure = now
+}
+
+// snapshot returns the stats with the failures decayed to now
+func (s *peerStats) snapshot(now time.Time) PeerSyncStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats := s.stats
+	stats.Failures = s.decayedFailures(now)
+
+	return stats
+}
+
+func (s *peerStats) decayedFailures(now time.Time) float64 {
+	if s.stats.Failures == 0 {
+		return 0
+	}
+
+	return s.stats.Failures * math.Pow(0.5, float64(now.Sub(s.lastFailure))/float64(failureHalfLife))
+}
+
+// PeerHealth returns the health score of the peer to rank the sync peers, which is the rolling delivery
+// rate discounted by the recent failures. The peers not measured yet are scored optimistically.
+func (d *Downloader) PeerHealth(peerID string) float64 {
+	d.lock.RLock()
+	conn, ok := d.peers[peerID]
+	d.lock.RUnlock()
+
+	if !ok {
+		return PeerSyncStats{}.Score()
+	}
+
+	return conn.stats.snapshot(time.Now()).Score()
+}
+
+// PeerStats returns the rolling sync stats of the registered peers
+func (d *Downloader) PeerStats() map[string]PeerSyncStats {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	now := time.Now()
+	result := make(map[string]PeerSyncStats, len(d.peers))
+	for id, conn := range d.peers {
+		result[id] = conn.stats.snapshot(now)
+	}
+
+	return result
+}
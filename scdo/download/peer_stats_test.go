@@ -0,0 +1,65 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PeerStats_RecordDelivery(t *testing.T) {
+	var stats peerStats
+
+	// optimistic score of the peer not measured
+	assert.Equal(t, stats.snapshot(time.Now()).Score(), float64(defaultDeliveryRate))
+
+	assert.Equal(t, stats.recordDelivery(100, time.Second), false)
+	assert.Equal(t, stats.snapshot(time.Now()).DeliveryRate, float64(100))
+	assert.Equal(t, stats.snapshot(time.Now()).Latency, time.Second)
+
+	// the empty response only measures the latency
+	assert.Equal(t, stats.recordDelivery(0, 2*time.Second), false)
+	assert.Equal(t, stats.snapshot(time.Now()).Deliveries, uint64(1))
+
+	// rotate away after consecutive slow deliveries
+	for i := 1; i < maxSlowDeliveries; i++ {
+		assert.Equal(t, stats.recordDelivery(1, 10*time.Second), false)
+	}
+	assert.Equal(t, stats.recordDelivery(1, 10*time.Second), true)
+
+	// a fast delivery resets the slow deliveries
+	assert.Equal(t, stats.recordDelivery(100, time.Second), false)
+	assert.Equal(t, stats.recordDelivery(1, 10*time.Second), false)
+}
+
+func Test_PeerStats_RecordFailure(t *testing.T) {
+	var stats peerStats
+	now := time.Now()
+
+	stats.recordDelivery(100, time.Second)
+	stats.recordFailure(now)
+	assert.Equal(t, stats.snapshot(now).Failures, float64(1))
+	assert.Equal(t, stats.snapshot(now).Score(), float64(50))
+
+	// the failures decay by half every half life
+	stats.recordFailure(now)
+	assert.Equal(t, stats.snapshot(now.Add(failureHalfLife)).Failures, float64(1))
+}
+
+func Test_Downloader_PeerHealth(t *testing.T) {
+	d := &Downloader{peers: make(map[string]*peerConn)}
+	d.peers["fast"] = &peerConn{}
+	d.peers["slow"] = &peerConn{}
+
+	d.peers["fast"].stats.recordDelivery(100, time.Second)
+	d.peers["slow"].stats.recordDelivery(1, 10*time.Second)
+
+	assert.Equal(t, d.PeerHealth("fast") > d.PeerHealth("slow"), true)
+	assert.Equal(t, d.PeerHealth("unknown"), float64(defaultDeliveryRate))
+	assert.Equal(t, len(d.PeerStats()), 2)
+}
@@ -0,0 +1,94 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"sync/atomic"
+
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/miner"
+)
+
+var (
+	errFollowerMode = errors.New("node is a follower, promote it to active first")
+	errNotFollower  = errors.New("node is not a follower")
+)
+
+// setFollower makes the node a warm standby, which syncs the chain and the pending txs from the peers as usual,
+// but the miner is not started, e.g. after sync, and the txs submitted by rpc are rejected.
+func (s *ScdoService) setFollower() {
+	atomic.StoreInt32(&s.follower, 1)
+	s.miner.SetStopper(1)
+}
+
+// IsFollower returns true if the node is a warm standby not promoted to active yet
+func (s *ScdoService) IsFollower() bool {
+	return atomic.LoadInt32(&s.follower) == 1
+}
+
+// Promote promotes the follower to active, which accepts the txs submitted by rpc and starts the miner with the
+// configured coinbase. The tx pool synced from the peers in the follower mode is kept, so that the first block
+// mined after the failover is not empty.
+func (s *ScdoService) Promote() error {
+	if !atomic.CompareAndSwapInt32(&s.follower, 1, 0) {
+		return errNotFollower
+	}
+
+	s.log.Info("follower is promoted to active, start the miner with coinbase %s", s.miner.GetCoinbase().Hex())
+
+	s.miner.SetStopper(0)
+	if err := s.miner.Start(); err != nil && err != miner.ErrMinerIsRunning {
+		return errors.NewStackedError(err, "promoted to active but failed to start the miner")
+	}
+
+	return nil
+}
+
+// followerPool rejects the txs submitted by rpc in the follower mode, and the txs from the peers are still
+// added into the tx pool. The other methods of the tx pool are promoted, e.g. the queued txs.
+type followerPool struct {
+	*core.TransactionPool
+	s *ScdoService
+}
+
+func (p *followerPool) AddTransaction(tx *types.Transaction) error {
+	if p.s.IsFollower() {
+		return errFollowerMode
+	}
+
+	return p.TransactionPool.AddTransaction(tx)
+}
+
+// followerProtocol rejects the txs of other shards submitted by rpc in the follower mode, which are checked by
+// the nonce reservation before sent to the peers of other shards.
+type followerProtocol struct {
+	*ScdoProtocol
+	s *ScdoService
+}
+
+func (p *followerProtocol) CheckNonceReservation(tx *types.Transaction) error {
+	if p.s.IsFollower() {
+		return errFollowerMode
+	}
+
+	return p.ScdoProtocol.CheckNonceReservation(tx)
+}
+
+// Promote promotes the follower node to active, and starts the miner with the configured coinbase.
+func (api *PrivateAdminAPI) Promote() (bool, error) {
+	if err := api.s.Promote(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsFollower returns true if the node is a follower not promoted to active yet.
+func (api *PrivateAdminAPI) IsFollower() bool {
+	return api.s.IsFollower()
+}
@@ -0,0 +1,55 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/miner"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Follower_RejectWrites(t *testing.T) {
+	s := &ScdoService{log: log.GetLogger("scdo")}
+	s.miner = miner.NewMiner(*crypto.MustGenerateShardAddress(1), nil, nil, nil, nil, false)
+	s.setFollower()
+	assert.Equal(t, s.IsFollower(), true)
+	assert.Equal(t, s.miner.IsStopperSet(), true)
+
+	backend := NewScdoBackend(s)
+	assert.Equal(t, backend.TxPoolBackend().AddTransaction(&types.Transaction{}), errFollowerMode)
+	assert.Equal(t, backend.ProtocolBackend().CheckNonceReservation(&types.Transaction{}), errFollowerMode)
+
+	_, err := NewPrivateMinerAPI(s).Start()
+	assert.Equal(t, err, errFollowerMode)
+
+	// the other methods of the tx pool are still available, e.g. the queued txs
+	_, ok := backend.TxPoolBackend().(interface{ IsQueuedTransaction(common.Hash) bool })
+	assert.Equal(t, ok, true)
+}
+
+func Test_Follower_Promote(t *testing.T) {
+	s := &ScdoService{log: log.GetLogger("scdo")}
+	s.miner = miner.NewMiner(*crypto.MustGenerateShardAddress(1), nil, nil, nil, nil, false)
+	s.setFollower()
+
+	// the miner refuses to start for the clock drift, but the node is promoted anyway
+	s.miner.SetClockDrift(time.Hour)
+	result, err := NewPrivateAdminAPI(s).Promote()
+	assert.Equal(t, result, false)
+	assert.Equal(t, errors.IsOrContains(err, miner.ErrClockDrift), true)
+	assert.Equal(t, NewPrivateAdminAPI(s).IsFollower(), false)
+	assert.Equal(t, s.miner.IsStopperSet(), false)
+
+	_, err = NewPrivateAdminAPI(s).Promote()
+	assert.Equal(t, err, errNotFollower)
+}
@@ -0,0 +1,94 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"encoding/binary"
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/node"
+)
+
+// healthMaxBlocksBehind is the max number of blocks behind the best peer to be regarded as synced
+const healthMaxBlocksBehind = 3
+
+// keyHealthCheck is the key written in the chain database to check whether the database is writable
+var keyHealthCheck = []byte("HealthCheck")
+
+// ReportHealth reports the sync status, database, miner and head age of the node. The node is not ready
+// if it is not synced, has too few peers in the local shard, fails to write the database, or the head is
+// older than the max head age of the watchdog config.
+func (s *ScdoService) ReportHealth(h *node.Health) {
+	now := time.Now()
+	header := s.chain.CurrentHeader()
+
+	h.BlocksBehind = s.blocksBehind(header)
+	h.Synced = h.BlocksBehind <= healthMaxBlocksBehind
+	if !h.Synced {
+		h.AddIssue("%d blocks behind the best peer", h.BlocksBehind)
+	}
+
+	minPeers := s.watchdogConfig.MinPeers
+	if minPeers <= 0 {
+		minPeers = defaultWatchdogMinPeers
+	}
+
+	if count := h.Peers[common.LocalShardNumber]; count < minPeers {
+		h.AddIssue("%d peers in the local shard, min %d", count, minPeers)
+	}
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(now.Unix()))
+	if err := s.chainDB.Put(keyHealthCheck, value); err != nil {
+		h.AddIssue("chain database is not writable, %s", err)
+	} else {
+		h.DBWritable = true
+	}
+
+	h.MinerStatus = s.minerStatus()
+	h.Follower = s.IsFollower()
+
+	h.LastBlockAge = now.Unix() - header.CreateTimestamp.Int64()
+	if maxHeadAge := durationOrDefault(s.watchdogConfig.MaxHeadAge, defaultWatchdogMaxHeadAge); h.LastBlockAge > int64(maxHeadAge/time.Second) {
+		h.AddIssue("HEAD block %d is %d seconds old", header.Height, h.LastBlockAge)
+	}
+}
+
+// blocksBehind returns the number of blocks to download in the current sync session, or estimates it by
+// the total difficulty of the best peer in the local shard and the difficulty of the HEAD block.
+func (s *ScdoService) blocksBehind(header *types.BlockHeader) uint64 {
+	if target, ok := s.Downloader().SyncTarget(); ok {
+		if target > header.Height {
+			return target - header.Height
+		}
+
+		return 0
+	}
+
+	best := s.scdoProtocol.peerSet.bestPeer(common.LocalShardNumber)
+	if best == nil {
+		return 0
+	}
+
+	localTD, err := s.chain.GetStore().GetBlockTotalDifficulty(header.Hash())
+	if err != nil || header.Difficulty == nil || header.Difficulty.Sign() <= 0 {
+		return 0
+	}
+
+	_, bestTD := best.Head()
+	if bestTD.Cmp(localTD) <= 0 {
+		return 0
+	}
+
+	// round up the estimated blocks
+	diff := new(big.Int).Sub(bestTD, localTD)
+	diff.Add(diff, header.Difficulty).Sub(diff, big.NewInt(1))
+
+	return diff.Div(diff, header.Difficulty).Uint64()
+}
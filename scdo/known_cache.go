@@ -0,0 +1,70 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-scdo/common"
+)
+
+// defaultKnownExpiry is the default duration after which a hash known by a peer is forgotten
+const defaultKnownExpiry = 10 * time.Minute
+
+// knownCache is the LRU set of the hashes known by a peer. The hashes expire after the expiry, so that
+// a long-lived peer doesn't remember them forever and they could be sent to the peer again.
+type knownCache struct {
+	cache  *lru.Cache
+	expiry time.Duration // never expire if 0
+}
+
+// newKnownCache creates a known cache of the size, or the default size if it is not positive. expiry is
+// in seconds, 0 means the default 10 minutes and negative never expires.
+func newKnownCache(size int, defaultSize int, expiry int64) *knownCache {
+	if size <= 0 {
+		size = defaultSize
+	}
+
+	c := &knownCache{cache: common.MustNewCache(size)}
+	if expiry == 0 {
+		c.expiry = defaultKnownExpiry
+	} else if expiry > 0 {
+		c.expiry = time.Duration(expiry) * time.Second
+	}
+
+	return c
+}
+
+// Add adds the hash, or refreshes the time of the hash if it is already known
+func (c *knownCache) Add(hash common.Hash) {
+	c.cache.Add(hash, time.Now())
+}
+
+// Contains returns whether the hash is known and not expired, the expired hash is removed
+func (c *knownCache) Contains(hash common.Hash) bool {
+	value, ok := c.cache.Peek(hash)
+	if !ok {
+		return false
+	}
+
+	if c.expiry > 0 && time.Since(value.(time.Time)) >= c.expiry {
+		c.cache.Remove(hash)
+		return false
+	}
+
+	return true
+}
+
+// Remove forgets the hash, e.g. the block is reverted by a chain reorg
+func (c *knownCache) Remove(hash common.Hash) {
+	c.cache.Remove(hash)
+}
+
+// Len returns the number of the hashes, including the expired ones not removed yet
+func (c *knownCache) Len() int {
+	return c.cache.Len()
+}
@@ -0,0 +1,63 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_KnownCache(t *testing.T) {
+	c := newKnownCache(0, 2, 0)
+	assert.Equal(t, c.expiry, defaultKnownExpiry)
+
+	h1, h2, h3 := common.StringToHash("h1"), common.StringToHash("h2"), common.StringToHash("h3")
+	c.Add(h1)
+	c.Add(h2)
+	c.Add(h3)
+	assert.Equal(t, c.Len(), 2)
+	assert.Equal(t, c.Contains(h1), false)
+	assert.Equal(t, c.Contains(h3), true)
+
+	c.Remove(h3)
+	assert.Equal(t, c.Contains(h3), false)
+
+	// expired
+	c.cache.Add(h2, time.Now().Add(-defaultKnownExpiry))
+	assert.Equal(t, c.Contains(h2), false)
+	assert.Equal(t, c.Len(), 0)
+}
+
+func Test_KnownCache_Expiry(t *testing.T) {
+	c := newKnownCache(10, 2, -1)
+	assert.Equal(t, c.cache.Len(), 0)
+	assert.Equal(t, c.expiry, time.Duration(0))
+
+	hash := common.StringToHash("hash")
+	c.cache.Add(hash, time.Now().Add(-24*time.Hour))
+	assert.Equal(t, c.Contains(hash), true)
+
+	c = newKnownCache(10, 2, 30)
+	assert.Equal(t, c.expiry, 30*time.Second)
+}
+
+func Test_Peer_ForgetBlock(t *testing.T) {
+	p := getTestPeer(1)
+	tx := &types.Transaction{Hash: common.StringToHash("tx")}
+	block := &types.Block{HeaderHash: common.StringToHash("block"), Transactions: []*types.Transaction{tx}}
+
+	p.knownBlocks.Add(block.HeaderHash)
+	p.markKnownTx(tx.Hash)
+	assert.Equal(t, p.isKnownTx(tx.Hash), true)
+
+	p.forgetBlock(block)
+	assert.Equal(t, p.knownBlocks.Contains(block.HeaderHash), false)
+	assert.Equal(t, p.isKnownTx(tx.Hash), false)
+}
@@ -0,0 +1,198 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/miner"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/sdk"
+)
+
+const (
+	// leaseCheckInterval is the interval to check whether the primary node moves to a new task
+	leaseCheckInterval = 2 * time.Second
+
+	// leaseRetryInterval is the interval to retry after the failures of the primary node
+	leaseRetryInterval = 5 * time.Second
+
+	// leaseTimeout is the timeout of a request to the primary node
+	leaseTimeout = 10 * time.Second
+)
+
+var errLeaseEngineNotSupported = errors.New("consensus engine does not support mining a nonce range")
+
+// leaseClient is the client of the primary node
+type leaseClient interface {
+	Call(ctx context.Context, shard uint, result interface{}, method string, args ...interface{}) error
+	Close()
+}
+
+// leaseMiner mines the nonce ranges leased from the primary node of the same operator, and submits
+// the nonce found back to the primary node, which creates the tasks and writes the mined blocks.
+type leaseMiner struct {
+	engine consensus.RangeSealer
+	log    *log.ScdoLog
+
+	conf   *sdk.Config
+	client leaseClient // dialed on demand, and redialed after failures
+	worker string
+	size   uint64
+
+	ctx    context.Context // canceled on stop to abort the mining and the requests in flight
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newLeaseMiner(engine consensus.Engine, conf node.LeaseMiningConfig) (*leaseMiner, error) {
+	sealer, ok := engine.(consensus.RangeSealer)
+	if !ok {
+		return nil, errLeaseEngineNotSupported
+	}
+
+	m := &leaseMiner{
+		engine: sealer,
+		log:    log.GetLogger("leasemining"),
+		conf:   sdk.DefaultConfig(conf.Primary),
+		worker: conf.Worker,
+		size:   conf.LeaseSize,
+	}
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+
+	return m, nil
+}
+
+func (m *leaseMiner) start() {
+	m.wg.Add(1)
+	go m.loop()
+}
+
+func (m *leaseMiner) stop() {
+	m.cancel()
+	m.wg.Wait()
+
+	if m.client != nil {
+		m.client.Close()
+		m.client = nil
+	}
+}
+
+func (m *leaseMiner) loop() {
+	defer m.wg.Done()
+
+	for m.ctx.Err() == nil {
+		err := m.mine()
+		if err == nil {
+			continue
+		}
+
+		if m.ctx.Err() == nil {
+			m.log.Warn("failed to mine the lease of the primary node, %s", err)
+		}
+
+		select {
+		case <-time.After(leaseRetryInterval):
+		case <-m.ctx.Done():
+		}
+	}
+}
+
+// call calls the primary node with timeout, and closes the client after failures so that
+// the primary node is dialed again in the next call
+func (m *leaseMiner) call(result interface{}, method string, args ...interface{}) error {
+	if m.client == nil {
+		client, err := sdk.Dial(m.ctx, m.conf)
+		if err != nil {
+			return err
+		}
+
+		m.client = client
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, leaseTimeout)
+	defer cancel()
+
+	err := m.client.Call(ctx, common.LocalShardNumber, result, method, args...)
+	if err != nil {
+		m.client.Close()
+		m.client = nil
+	}
+
+	return err
+}
+
+// mine leases a nonce range of the primary task and mines it, until the nonce is found, the range
+// is exhausted or the primary node moves to a new task.
+func (m *leaseMiner) mine() error {
+	var lease miner.WorkLease
+	if err := m.call(&lease, "miner_leaseWork", m.worker, m.size); err != nil {
+		return err
+	}
+
+	if lease.Header == nil {
+		return miner.ErrNoWorkTask
+	}
+
+	m.log.Debug("mining the lease %d, height:%d, nonce range [%d, %d]", lease.ID, lease.Header.Height, lease.Start, lease.End)
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	results := make(chan *types.Block, 1)
+	m.engine.SealRange(&types.Block{Header: lease.Header}, lease.Start, lease.End, stop, results)
+
+	ticker := time.NewTicker(leaseCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case block := <-results:
+			if block == nil {
+				return nil // range exhausted, lease the next one
+			}
+
+			return m.submit(&lease, block)
+		case <-ticker.C:
+			var height uint64
+			if err := m.call(&height, "scdo_getBlockHeight"); err != nil {
+				return err
+			}
+
+			if height >= lease.Header.Height {
+				m.log.Debug("the primary node moves to a new task, height:%d", height+1)
+				return nil
+			}
+		case <-m.ctx.Done():
+			return m.ctx.Err()
+		}
+	}
+}
+
+func (m *leaseMiner) submit(lease *miner.WorkLease, block *types.Block) error {
+	nonce, err := strconv.ParseUint(string(block.Header.Witness), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	if err = m.call(&ok, "miner_submitLeaseNonce", lease.ID, nonce); err != nil {
+		return errors.NewStackedErrorf(err, "failed to submit nonce %d of lease %d", nonce, lease.ID)
+	}
+
+	m.log.Info("submitted the nonce to the primary node, height:%d, nonce:%d", lease.Header.Height, nonce)
+
+	return nil
+}
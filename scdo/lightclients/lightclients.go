@@ -8,9 +8,11 @@ package lightclients
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"path/filepath"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-scdo/api"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/common/errors"
 	"github.com/scdoproject/go-scdo/consensus"
@@ -99,6 +101,65 @@ func (manager *LightClientsManager) ValidateDebt(debt *types.Debt) (packed bool,
 		return false, false, errors.NewStackedErrorf(err, "failed to get tx %v", debt.Data.TxHash)
 	}
 
+	return manager.confirmDebtTx(debt, tx, index, backend.ChainBackend().CurrentHeader())
+}
+
+// ValidateDebts validates the debts like ValidateDebt, and the txs of the debts from the same shard are
+// retrieved in a few requests. The results are in the order of the debts.
+func (manager *LightClientsManager) ValidateDebts(debts []*types.Debt) []*types.DebtVerifyResult {
+	results := make([]*types.DebtVerifyResult, len(debts))
+
+	groups := make(map[uint][]int) // source shard -> indexes of the debts whose txs to retrieve
+	for i, debt := range debts {
+		fromShard := debt.Data.From.Shard()
+		if fromShard == 0 || fromShard > common.ShardCount || fromShard == manager.localShard {
+			results[i] = &types.DebtVerifyResult{Err: errWrongShardDebt}
+		} else if _, ok := manager.confirmedTxs[fromShard].Get(debt.Data.TxHash); ok {
+			results[i] = &types.DebtVerifyResult{Packed: true, Confirmed: true}
+		} else {
+			groups[fromShard] = append(groups[fromShard], i)
+		}
+	}
+
+	for shard, indexes := range groups {
+		backend := manager.lightClientsBackend[shard]
+		txHashes := make([]common.Hash, len(indexes))
+		for j, i := range indexes {
+			txHashes[j] = debts[i].Data.TxHash
+		}
+
+		txs, err := backend.GetTransactions(txHashes)
+		if err != nil {
+			// fall back to validate the debts one by one, e.g. the server does not support the batch request
+			for _, i := range indexes {
+				packed, confirmed, err := manager.ValidateDebt(debts[i])
+				results[i] = &types.DebtVerifyResult{Packed: packed, Confirmed: confirmed, Err: err}
+			}
+
+			continue
+		}
+
+		// all the debts are confirmed against the same header, in case of the chain head changed in the middle
+		header := backend.ChainBackend().CurrentHeader()
+		for j, i := range indexes {
+			if txs[j].Err != nil {
+				err := errors.NewStackedErrorf(txs[j].Err, "failed to get tx %v", txHashes[j])
+				results[i] = &types.DebtVerifyResult{Err: err}
+				continue
+			}
+
+			packed, confirmed, err := manager.confirmDebtTx(debts[i], txs[j].Tx, txs[j].BlockIndex, header)
+			results[i] = &types.DebtVerifyResult{Packed: packed, Confirmed: confirmed, Err: err}
+		}
+	}
+
+	return results
+}
+
+// confirmDebtTx checks the debt against its tx retrieved from the source shard,
+// and whether the tx is confirmed by the header of the light chain.
+func (manager *LightClientsManager) confirmDebtTx(debt *types.Debt, tx *types.Transaction, index *api.BlockIndex,
+	header *types.BlockHeader) (packed bool, confirmed bool, retErr error) {
 	if index == nil {
 		return false, false, errNotFoundTx
 	}
@@ -108,14 +169,17 @@ func (manager *LightClientsManager) ValidateDebt(debt *types.Debt) (packed bool,
 		return false, false, errNotMatchedTx
 	}
 
-	header := backend.ChainBackend().CurrentHeader()
-	duration := header.Height - index.BlockHeight
-	if duration < common.ConfirmedBlockNumber {
+	if header.Height < index.BlockHeight+common.ConfirmedBlockNumber {
+		var duration uint64
+		if header.Height > index.BlockHeight {
+			duration = header.Height - index.BlockHeight
+		}
+
 		return true, false, fmt.Errorf("invalid debt because not enough confirmed block number, wanted is %d, actual is %d", common.ConfirmedBlockNumber, duration)
 	}
 
 	// cache the confirmed tx
-	cache.Add(debt.Data.TxHash, true)
+	manager.confirmedTxs[debt.Data.From.Shard()].Add(debt.Data.TxHash, true)
 
 	return true, true, nil
 }
@@ -174,3 +238,21 @@ func (manager *LightClientsManager) IfDebtPacked(debt *types.Debt) (packed bool,
 
 	return true, true, nil
 }
+
+// GetBlockHeader returns the block header of the specified hash in the light chain of the shard
+func (manager *LightClientsManager) GetBlockHeader(shard uint, hash common.Hash) (*types.BlockHeader, error) {
+	if shard == 0 || shard > common.ShardCount || shard == manager.localShard {
+		return nil, fmt.Errorf("no light chain of shard %d", shard)
+	}
+
+	return manager.lightClientsBackend[shard].ChainBackend().GetStore().GetBlockHeader(hash)
+}
+
+// GetBalance returns the proven balance of the account at the HEAD of the light chain of the shard
+func (manager *LightClientsManager) GetBalance(shard uint, account common.Address) (*big.Int, error) {
+	if shard == 0 || shard > common.ShardCount || shard == manager.localShard {
+		return nil, fmt.Errorf("no light chain of shard %d", shard)
+	}
+
+	return manager.lightClientsBackend[shard].GetBalance(account, common.EmptyHash)
+}
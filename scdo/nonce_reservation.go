@@ -0,0 +1,119 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+const (
+	// nonceReservationPeerCapacity is the max number of reservations kept from each peer of other shards,
+	// so that a peer could not flush the reservations gossiped by the others
+	nonceReservationPeerCapacity = 10000
+
+	// nonceReservationTimeout is the duration a reservation is kept, it is best effort only
+	nonceReservationTimeout = 10 * time.Minute
+)
+
+var errNilReservedTx = errors.New("nil reserved tx")
+
+// nonceReservation announces that the account nonce is used by a pending cross-shard tx in its own shard.
+// It carries the signed tx, so that only the account owner could reserve its nonces.
+type nonceReservation struct {
+	Tx *types.Transaction
+}
+
+// validate verifies the signature of the reserved tx
+func (r *nonceReservation) validate() error {
+	if r.Tx == nil {
+		return errNilReservedTx
+	}
+
+	return r.Tx.ValidateWithoutState(true, false)
+}
+
+type reservedNonce struct {
+	txHash common.Hash
+	expire time.Time
+}
+
+// nonceReservations records the nonce reservations gossiped by the peers of other shards, which are
+// kept for each peer separately and removed when the peer is disconnected.
+type nonceReservations struct {
+	lock  sync.RWMutex
+	peers map[string]*lru.Cache
+}
+
+func newNonceReservations() *nonceReservations {
+	return &nonceReservations{
+		peers: make(map[string]*lru.Cache),
+	}
+}
+
+func nonceReservationKey(account common.Address, nonce uint64) string {
+	return fmt.Sprintf("%s-%d", account.Hex(), nonce)
+}
+
+// add records the reservation of the validated tx from the peer, a later reservation overrides
+// the previous one of the same account nonce from the peer
+func (r *nonceReservations) add(peerID string, tx *types.Transaction) {
+	r.lock.Lock()
+	cache, ok := r.peers[peerID]
+	if !ok {
+		cache = common.MustNewCache(nonceReservationPeerCapacity)
+		r.peers[peerID] = cache
+	}
+	r.lock.Unlock()
+
+	cache.Add(nonceReservationKey(tx.Data.From, tx.Data.AccountNonce), &reservedNonce{
+		txHash: tx.Hash,
+		expire: time.Now().Add(nonceReservationTimeout),
+	})
+}
+
+// removePeer removes the reservations from the disconnected peer
+func (r *nonceReservations) removePeer(peerID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.peers, peerID)
+}
+
+// check returns an error if the tx nonce is reserved by another tx
+func (r *nonceReservations) check(tx *types.Transaction) error {
+	key := nonceReservationKey(tx.Data.From, tx.Data.AccountNonce)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, cache := range r.peers {
+		value, ok := cache.Get(key)
+		if !ok {
+			continue
+		}
+
+		reserved := value.(*reservedNonce)
+		if time.Now().After(reserved.expire) {
+			cache.Remove(key)
+			continue
+		}
+
+		if reserved.txHash != tx.Hash {
+			return errors.NewStackedErrorf(core.ErrNoncePending, "account:%s, tx nonce:%d, reserved by tx %s in shard %d",
+				tx.Data.From.Hex(), tx.Data.AccountNonce, reserved.txHash.Hex(), tx.Data.From.Shard())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	log2 "github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReservedTx(from common.Address, nonce uint64, hash string) *types.Transaction {
+	return &types.Transaction{
+		Hash: common.StringToHash(hash),
+		Data: types.TransactionData{
+			From:         from,
+			AccountNonce: nonce,
+		},
+	}
+}
+
+func newTestSignedReservedTx(t *testing.T, nonce uint64) *types.Transaction {
+	from, priv, err := crypto.GenerateKeyPair(1)
+	assert.Equal(t, err, nil)
+
+	tx, err := types.NewTransaction(*from, *crypto.MustGenerateRandomAddress(), big.NewInt(1), big.NewInt(1), nonce)
+	assert.Equal(t, err, nil)
+	tx.Sign(priv)
+
+	return tx
+}
+
+func Test_NonceReservations(t *testing.T) {
+	reservations := newNonceReservations()
+	from := *crypto.MustGenerateRandomAddress()
+
+	tx := newTestReservedTx(from, 5, "tx1")
+	assert.Equal(t, reservations.check(tx), nil)
+
+	reservations.add("peer1", tx)
+	assert.Equal(t, reservations.check(tx), nil)
+
+	// conflicting tx with the same account nonce
+	err := reservations.check(newTestReservedTx(from, 5, "tx2"))
+	assert.Equal(t, errors.IsOrContains(err, core.ErrNoncePending), true)
+
+	// different nonce
+	assert.Equal(t, reservations.check(newTestReservedTx(from, 6, "tx2")), nil)
+
+	// the reservations are removed with the peer
+	reservations.removePeer("peer1")
+	assert.Equal(t, reservations.check(newTestReservedTx(from, 5, "tx2")), nil)
+}
+
+func Test_NonceReservations_PeerCapacity(t *testing.T) {
+	reservations := newNonceReservations()
+	from := *crypto.MustGenerateRandomAddress()
+
+	other := *crypto.MustGenerateRandomAddress()
+
+	reservations.add("peer1", newTestReservedTx(from, 0, "tx0"))
+	for i := 0; i <= nonceReservationPeerCapacity; i++ {
+		reservations.add("peer2", newTestReservedTx(other, uint64(i), "tx"))
+	}
+
+	// the reservations of other peers are not flushed
+	err := reservations.check(newTestReservedTx(from, 0, "tx1"))
+	assert.Equal(t, errors.IsOrContains(err, core.ErrNoncePending), true)
+	assert.Equal(t, reservations.peers["peer2"].Len(), nonceReservationPeerCapacity)
+}
+
+func Test_NonceReservations_Expired(t *testing.T) {
+	reservations := newNonceReservations()
+	from := *crypto.MustGenerateRandomAddress()
+
+	reservations.add("peer1", newTestReservedTx(from, 5, "reserved"))
+	cache := reservations.peers["peer1"]
+	cache.Add(nonceReservationKey(from, 5), &reservedNonce{
+		txHash: common.StringToHash("reserved"),
+		expire: time.Now().Add(-time.Second),
+	})
+
+	assert.Equal(t, reservations.check(newTestReservedTx(from, 5, "tx1")), nil)
+	assert.Equal(t, cache.Len(), 0)
+}
+
+func Test_nonceReservation_Validate(t *testing.T) {
+	tx := newTestSignedReservedTx(t, 5)
+
+	var reservation nonceReservation
+	assert.Equal(t, common.Deserialize(common.SerializePanic(&nonceReservation{Tx: tx}), &reservation), nil)
+	assert.Equal(t, reservation.validate(), nil)
+	assert.Equal(t, reservation.Tx.Hash, tx.Hash)
+
+	// the tx not signed by the account owner
+	forged := newTestSignedReservedTx(t, 5)
+	forged.Data.From = tx.Data.From
+	assert.Equal(t, (&nonceReservation{Tx: forged}).validate() != nil, true)
+
+	assert.Equal(t, (&nonceReservation{}).validate(), errNilReservedTx)
+}
+
+func Test_PropagateNonceReservation_LegacyPeer(t *testing.T) {
+	newTestPeer := func(statusVersion uint32) (*peer, *mockGossipMsgReadWriter) {
+		n := discovery.NewNode(*crypto.MustGenerateShardAddress(2), nil, 0, common.LocalShardNumber+1)
+		rw := &mockGossipMsgReadWriter{make(chan *p2p.Message, 10)}
+		p := newPeer(common.ScdoVersion, &p2p.Peer{Node: n}, rw, log2.GetLogger("test"), node.PeerKnownCacheConfig{})
+		p.statusVersion = statusVersion
+		return p, rw
+	}
+
+	legacy, legacyRW := newTestPeer(uint32(common.ScdoVersion))
+	upgraded, upgradedRW := newTestPeer(statusProtocolVersion)
+
+	sp := &ScdoProtocol{peerSet: newPeerSet(), log: log2.GetLogger("test")}
+	sp.peerSet.Add(legacy)
+	sp.peerSet.Add(upgraded)
+
+	sp.propagateNonceReservation(newTestSignedReservedTx(t, 5))
+
+	// the legacy peer disconnects on the unknown message code
+	assert.Equal(t, len(legacyRW.msgs), 0)
+	assert.Equal(t, (<-upgradedRW.msgs).Code, nonceReservationMsgCode)
+}
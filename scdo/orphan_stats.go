@@ -0,0 +1,208 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/log"
+)
+
+const (
+	// orphanSettleDepth is the number of blocks on top of a mined block before it is settled as canonical or orphaned
+	orphanSettleDepth = common.ConfirmedBlockNumber
+
+	// maxRecentOrphans is the max number of the recent orphaned blocks kept as examples
+	maxRecentOrphans = 32
+
+	// maxUnsettledMinedBlocks is the max number of the mined blocks waiting to be settled
+	maxUnsettledMinedBlocks = 1024
+)
+
+// keyOrphanStats is the key of the orphan stats persisted in the chain database
+var keyOrphanStats = []byte("OrphanStats")
+
+// MinedBlock is a block found by the local miner
+type MinedBlock struct {
+	Hash    common.Hash
+	Height  uint64
+	Creator common.Address
+	MinedAt int64 // unix time when the block is mined
+
+	// hash of the canonical block at the height when the block is settled as orphaned
+	CanonicalHash common.Hash `json:",omitempty"`
+}
+
+// OrphanStats is the stats of the blocks found by the local miner which failed to become canonical
+type OrphanStats struct {
+	Mined      uint64  // number of the settled mined blocks, either canonical or orphaned
+	Orphaned   uint64  // number of the settled mined blocks which are not canonical
+	OrphanRate float64 // Orphaned / Mined
+	Unsettled  int     // number of the mined blocks which are not deep enough to be settled
+	Depth      uint64  // number of blocks on top of a mined block before it is settled
+
+	RecentOrphans []*MinedBlock // the most recent orphaned blocks, the latest first
+}
+
+// orphanRecord is the state of the tracker persisted in the chain database
+type orphanRecord struct {
+	Mined         uint64
+	Orphaned      uint64
+	Unsettled     []*MinedBlock
+	RecentOrphans []*MinedBlock
+}
+
+type orphanChain interface {
+	CurrentBlock() *types.Block
+	GetStore() store.BlockchainStore
+}
+
+// orphanTracker records the blocks found by the local miner, and settles them as canonical or orphaned
+// once the HEAD is deep enough. The counts and the recent orphans are persisted across restarts, so that
+// the miners could quantify the block propagation problems.
+type orphanTracker struct {
+	lock   sync.Mutex
+	chain  orphanChain
+	db     database.Database
+	depth  uint64
+	record orphanRecord
+	log    *log.ScdoLog
+}
+
+func newOrphanTracker(chain orphanChain, db database.Database, log *log.ScdoLog) *orphanTracker {
+	t := &orphanTracker{
+		chain: chain,
+		db:    db,
+		depth: orphanSettleDepth,
+		log:   log,
+	}
+
+	if value, err := db.Get(keyOrphanStats); err == nil {
+		if err = json.Unmarshal(value, &t.record); err != nil {
+			log.Warn("failed to decode the orphan stats, reset them, %s", err)
+			t.record = orphanRecord{}
+		}
+	}
+
+	return t
+}
+
+func (t *orphanTracker) start() {
+	event.BlockMinedEventManager.AddAsyncListener(t.blockMined)
+	event.ChainHeaderChangedEventMananger.AddAsyncListener(t.chainHeaderChanged)
+}
+
+func (t *orphanTracker) stop() {
+	event.BlockMinedEventManager.RemoveListener(t.blockMined)
+	event.ChainHeaderChangedEventMananger.RemoveListener(t.chainHeaderChanged)
+}
+
+func (t *orphanTracker) blockMined(e event.Event) {
+	block := e.(*types.Block)
+	t.addMinedBlock(&MinedBlock{
+		Hash:    block.HeaderHash,
+		Height:  block.Header.Height,
+		Creator: block.Header.Creator,
+		MinedAt: time.Now().Unix(),
+	})
+}
+
+func (t *orphanTracker) addMinedBlock(block *MinedBlock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// drop the oldest ones if the chain stops growing for a long time
+	if len(t.record.Unsettled) >= maxUnsettledMinedBlocks {
+		t.record.Unsettled = t.record.Unsettled[len(t.record.Unsettled)-maxUnsettledMinedBlocks+1:]
+	}
+
+	t.record.Unsettled = append(t.record.Unsettled, block)
+	t.persist()
+}
+
+// chainHeaderChanged settles the mined blocks with the current HEAD, since the async events may be handled out of order
+func (t *orphanTracker) chainHeaderChanged(e event.Event) {
+	if head := t.chain.CurrentBlock(); head != nil {
+		t.settle(head.Header.Height)
+	}
+}
+
+// settle checks whether the mined blocks at least depth blocks below the HEAD height are canonical
+func (t *orphanTracker) settle(headHeight uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	bcStore := t.chain.GetStore()
+	var unsettled []*MinedBlock
+	for _, block := range t.record.Unsettled {
+		if block.Height+t.depth > headHeight {
+			unsettled = append(unsettled, block)
+			continue
+		}
+
+		canonicalHash, err := bcStore.GetBlockHash(block.Height)
+		if err != nil {
+			t.log.Debug("failed to get the canonical block hash at height %d, %s", block.Height, err)
+			unsettled = append(unsettled, block)
+			continue
+		}
+
+		t.record.Mined++
+		if !canonicalHash.Equal(block.Hash) {
+			t.record.Orphaned++
+			block.CanonicalHash = canonicalHash
+			t.record.RecentOrphans = append([]*MinedBlock{block}, t.record.RecentOrphans...)
+			if len(t.record.RecentOrphans) > maxRecentOrphans {
+				t.record.RecentOrphans = t.record.RecentOrphans[:maxRecentOrphans]
+			}
+
+			t.log.Warn("mined block %v at height %d is orphaned, canonical block is %v", block.Hash, block.Height, canonicalHash)
+		}
+	}
+
+	if len(unsettled) != len(t.record.Unsettled) {
+		t.record.Unsettled = unsettled
+		t.persist()
+	}
+}
+
+func (t *orphanTracker) persist() {
+	value, err := json.Marshal(&t.record)
+	if err != nil {
+		t.log.Warn("failed to encode the orphan stats, %s", err)
+		return
+	}
+
+	if err = t.db.Put(keyOrphanStats, value); err != nil {
+		t.log.Warn("failed to persist the orphan stats, %s", err)
+	}
+}
+
+func (t *orphanTracker) stats() *OrphanStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stats := &OrphanStats{
+		Mined:         t.record.Mined,
+		Orphaned:      t.record.Orphaned,
+		Unsettled:     len(t.record.Unsettled),
+		Depth:         t.depth,
+		RecentOrphans: append([]*MinedBlock{}, t.record.RecentOrphans...),
+	}
+
+	if stats.Mined > 0 {
+		stats.OrphanRate = float64(stats.Orphaned) / float64(stats.Mined)
+	}
+
+	return stats
+}
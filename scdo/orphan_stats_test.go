@@ -0,0 +1,63 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OrphanTracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orphanstats")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.NewLevelDB(dir)
+	assert.Equal(t, err, nil)
+	defer db.Close()
+
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	genesis := chain.putBlock(t, nil, "")
+	canonical := chain.putBlock(t, genesis, "canonical")
+	head := chain.putBlock(t, canonical, "")
+	chain.head = head
+
+	tracker := newOrphanTracker(chain, db, log.GetLogger("orphan"))
+	tracker.depth = 1
+
+	tracker.addMinedBlock(&MinedBlock{Hash: canonical.HeaderHash, Height: 1})
+	tracker.addMinedBlock(&MinedBlock{Hash: genesis.HeaderHash, Height: 1})
+	tracker.addMinedBlock(&MinedBlock{Hash: head.HeaderHash, Height: 2})
+
+	// the block at the HEAD height is not deep enough
+	tracker.settle(head.Header.Height)
+	stats := tracker.stats()
+	assert.Equal(t, stats.Mined, uint64(2))
+	assert.Equal(t, stats.Orphaned, uint64(1))
+	assert.Equal(t, stats.OrphanRate, 0.5)
+	assert.Equal(t, stats.Unsettled, 1)
+	assert.Equal(t, len(stats.RecentOrphans), 1)
+	assert.Equal(t, stats.RecentOrphans[0].Hash, genesis.HeaderHash)
+	assert.Equal(t, stats.RecentOrphans[0].CanonicalHash, canonical.HeaderHash)
+
+	// the stats are restored after restart
+	tracker = newOrphanTracker(chain, db, log.GetLogger("orphan"))
+	tracker.depth = 1
+	assert.Equal(t, tracker.stats().Mined, uint64(2))
+	assert.Equal(t, tracker.stats().Unsettled, 1)
+
+	tracker.settle(head.Header.Height + 1)
+	stats = tracker.stats()
+	assert.Equal(t, stats.Mined, uint64(3))
+	assert.Equal(t, stats.Orphaned, uint64(1))
+	assert.Equal(t, stats.Unsettled, 0)
+}
@@ -11,11 +11,13 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 
-	lru "github.com/hashicorp/golang-lru"
 	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
 	"github.com/scdoproject/go-scdo/p2p"
 	downloader "github.com/scdoproject/go-scdo/scdo/download"
 )
@@ -37,13 +39,16 @@ var (
 	errNetworkNotMatch          = errors.New("NetworkID not match")
 	errGenesisNotMatch          = errors.New("Genesis not match")
 	errGenesisDifficultNotMatch = errors.New("Genesis Difficult not match")
+	errForkIDNotMatch           = errors.New("Fork ID not match")
 )
 
 // PeerInfo represents a short summary of a connected peer.
 type PeerInfo struct {
-	Version    uint     `json:"version"`    // Scdo protocol version negotiated
-	Difficulty *big.Int `json:"difficulty"` // Total difficulty of the peer's blockchain
-	Head       string   `json:"head"`       // SHA3 hash of the peer's best owned block
+	Version    uint     `json:"version"`          // Scdo protocol version negotiated
+	Difficulty *big.Int `json:"difficulty"`       // Total difficulty of the peer's blockchain
+	Head       string   `json:"head"`             // SHA3 hash of the peer's best owned block
+	SyncedTxs  uint64   `json:"syncedTxs"`        // Number of the pending txs synced to the peer after connected
+	ForkID     string   `json:"forkID,omitempty"` // Fork id of the peer in the handshake, empty for the legacy peers
 }
 
 type peer struct {
@@ -52,14 +57,25 @@ type peer struct {
 	peerStrID string
 	version   uint // Scdo protocol version negotiated
 	head      common.Hash
-	td        *big.Int // total difficulty
+	td        *big.Int       // total difficulty
+	forkID    *common.ForkID // fork id in the handshake, nil for the legacy peers without fork id
 	lock      sync.RWMutex
 
+	statusVersion uint32 // protocol version in the status handshake, common.ScdoVersion for the legacy peers
+
 	rw p2p.MsgReadWriter // the read write method for this peer
 
-	knownTxs    *lru.Cache // Set of transaction hashes known by this peer
-	knownBlocks *lru.Cache // Set of block hashes known by this peer
-	knownDebts  *lru.Cache // Set of debt hashes known by this peer
+	knownTxs    *knownCache // Set of transaction hashes known by this peer, if not sharing the seen txs
+	knownBlocks *knownCache // Set of block hashes known by this peer
+	knownDebts  *knownCache // Set of debt hashes known by this peer
+
+	knownTxsLock sync.RWMutex
+	seenTxs      *core.SeenTxs // seen txs shared with the tx pool, which records the known txs of the peer in its slot
+	seenTxsSlot  int
+
+	gossip *gossipQueue // txs and debts queued to send asynchronously
+
+	syncedTxs uint64 // number of the pending txs synced to the peer, accessed atomically
 
 	log *log.ScdoLog
 }
@@ -68,31 +84,17 @@ func idToStr(id common.Address) string {
 	return fmt.Sprintf("%x", id[:8])
 }
 
-func newPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, log *log.ScdoLog) *peer {
-	knownTxsCache, err := lru.New(maxKnownTxs)
-	if err != nil {
-		panic(err)
-	}
-
-	knownBlockCache, err := lru.New(maxKnownBlocks)
-	if err != nil {
-		panic(err)
-	}
-
-	knownDebtCache, err := lru.New(maxKnownDebts)
-	if err != nil {
-		panic(err)
-	}
-
+func newPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, log *log.ScdoLog, conf node.PeerKnownCacheConfig) *peer {
 	return &peer{
 		Peer:        p,
 		version:     version,
 		td:          big.NewInt(0),
 		peerID:      p.Node.ID,
 		peerStrID:   idToStr(p.Node.ID),
-		knownTxs:    knownTxsCache,
-		knownBlocks: knownBlockCache,
-		knownDebts:  knownDebtCache,
+		knownTxs:    newKnownCache(conf.Txs, maxKnownTxs, conf.Expiry),
+		knownBlocks: newKnownCache(conf.Blocks, maxKnownBlocks, conf.Expiry),
+		knownDebts:  newKnownCache(conf.Debts, maxKnownDebts, conf.Expiry),
+		gossip:      newGossipQueue(),
 		rw:          rw,
 		log:         log,
 	}
@@ -102,11 +104,22 @@ func newPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, log *log.ScdoLog)
 func (p *peer) Info() *PeerInfo {
 	hash, td := p.Head()
 
-	return &PeerInfo{
+	info := &PeerInfo{
 		Version:    p.version,
 		Difficulty: td,
 		Head:       hex.EncodeToString(hash[0:]),
+		SyncedTxs:  atomic.LoadUint64(&p.syncedTxs),
 	}
+
+	if p.forkID != nil {
+		info.ForkID = fmt.Sprintf("%08x/%d", p.forkID.Hash, p.forkID.Next)
+	}
+
+	return info
+}
+
+func (p *peer) addSyncedTxs(count int) {
+	atomic.AddUint64(&p.syncedTxs, uint64(count))
 }
 
 // Send writes an RLP-encoded message with the given code.
@@ -115,15 +128,83 @@ func (p *peer) Send(msgcode uint16, data interface{}) error {
 	return p2p.SendMessage(p.rw, msgcode, buff)
 }
 
+// useSeenTxs records the known txs of the peer in the seen txs shared with the tx pool,
+// and the peer keeps its own known txs if no slot of the seen txs is free.
+func (p *peer) useSeenTxs(seen *core.SeenTxs) {
+	slot, ok := seen.AcquirePeerSlot()
+	if !ok {
+		return
+	}
+
+	p.knownTxsLock.Lock()
+	defer p.knownTxsLock.Unlock()
+
+	p.seenTxs, p.seenTxsSlot = seen, slot
+	p.knownTxs = nil
+}
+
+// releaseSeenTxs releases the slot of the seen txs when the peer disconnected
+func (p *peer) releaseSeenTxs() {
+	p.knownTxsLock.Lock()
+	defer p.knownTxsLock.Unlock()
+
+	if p.seenTxs != nil {
+		p.seenTxs.ReleasePeerSlot(p.seenTxsSlot)
+		p.seenTxs = nil
+	}
+}
+
+// isKnownTx returns whether the tx is known by the peer
+func (p *peer) isKnownTx(txHash common.Hash) bool {
+	p.knownTxsLock.RLock()
+	defer p.knownTxsLock.RUnlock()
+
+	if p.seenTxs != nil {
+		return p.seenTxs.KnownByPeer(txHash, p.seenTxsSlot)
+	}
+
+	return p.knownTxs != nil && p.knownTxs.Contains(txHash)
+}
+
+// markKnownTx marks the tx is known by the peer
+func (p *peer) markKnownTx(txHash common.Hash) {
+	p.knownTxsLock.RLock()
+	defer p.knownTxsLock.RUnlock()
+
+	if p.seenTxs != nil {
+		p.seenTxs.MarkPeer(txHash, p.seenTxsSlot)
+	} else if p.knownTxs != nil {
+		p.knownTxs.Add(txHash)
+	}
+}
+
+// forgetBlock forgets the block and its txs known by the peer, e.g. the block is reverted by a chain
+// reorg, so that they could be sent to the peer again.
+func (p *peer) forgetBlock(block *types.Block) {
+	p.knownBlocks.Remove(block.HeaderHash)
+
+	p.knownTxsLock.RLock()
+	defer p.knownTxsLock.RUnlock()
+
+	// the seen txs are shared with the tx pool and expire by epoch
+	if p.seenTxs != nil || p.knownTxs == nil {
+		return
+	}
+
+	for _, tx := range block.Transactions {
+		p.knownTxs.Remove(tx.Hash)
+	}
+}
+
 func (p *peer) sendTransactionHash(txHash common.Hash) error {
-	if p.knownTxs.Contains(txHash) {
+	if p.isKnownTx(txHash) {
 		return nil
 	}
 	buff := common.SerializePanic(txHash)
 
 	err := p2p.SendMessage(p.rw, transactionHashMsgCode, buff)
 	if err == nil {
-		p.knownTxs.Add(txHash, nil)
+		p.markKnownTx(txHash)
 	}
 
 	return err
@@ -147,7 +228,7 @@ func (p *peer) sendDebts(debts []*types.Debt, filter bool) error {
 		err := p2p.SendMessage(p.rw, debtMsgCode, buff)
 		if err == nil {
 			for _, d := range filterDebts {
-				p.knownDebts.Add(d.Hash, nil)
+				p.knownDebts.Add(d.Hash)
 			}
 		}
 
@@ -157,6 +238,19 @@ func (p *peer) sendDebts(debts []*types.Debt, filter bool) error {
 	return nil
 }
 
+func (p *peer) sendNonceReservation(reservation *nonceReservation) error {
+	buff := common.SerializePanic(reservation)
+
+	return p2p.SendMessage(p.rw, nonceReservationMsgCode, buff)
+}
+
+// sendDebtAck acknowledges the debts accepted into the debt pool to the peer of the source shard
+func (p *peer) sendDebtAck(hashes []common.Hash) error {
+	buff := common.SerializePanic(hashes)
+
+	return p2p.SendMessage(p.rw, debtAckMsgCode, buff)
+}
+
 func (p *peer) sendTransactionRequest(txHash common.Hash) error {
 	buff := common.SerializePanic(txHash)
 
@@ -176,7 +270,7 @@ func (p *peer) SendBlockHash(blockHash common.Hash) error {
 	p.log.Debug("peer send [blockHashMsgCode] with size %d byte", len(buff))
 	err := p2p.SendMessage(p.rw, blockHashMsgCode, buff)
 	if err == nil {
-		p.knownBlocks.Add(blockHash, nil)
+		p.knownBlocks.Add(blockHash)
 	}
 
 	return err
@@ -295,10 +389,12 @@ func (p *peer) sendHeadStatus(msg *chainHeadStatus, wg *sync.WaitGroup) error {
 	return p2p.SendMessage(p.rw, statusChainHeadMsgCode, buff)
 }
 
-// handShake exchange networkid td etc between two connected peers.
-func (p *peer) handShake(networkID string, td *big.Int, head common.Hash, genesis common.Hash, difficult uint64) error {
+// handShake exchange networkid td etc between two connected peers. The fork id of the chain config at
+// the head height is exchanged too, and the peer with incompatible fork config is rejected.
+func (p *peer) handShake(networkID string, td *big.Int, head common.Hash, genesis common.Hash, difficult uint64,
+	config *common.ChainConfig, height uint64) error {
 	msg := &statusData{
-		ProtocolVersion: uint32(common.ScdoVersion),
+		ProtocolVersion: statusProtocolVersion,
 		NetworkID:       networkID,
 		TD:              td,
 		CurrentBlock:    head,
@@ -329,11 +425,69 @@ func (p *peer) handShake(networkID string, td *big.Int, head common.Hash, genesi
 		return err
 	}
 
+	p.statusVersion = retStatusMsg.ProtocolVersion
+
+	// the fork id is exchanged in a separate message after the status, since the legacy peers
+	// could not decode the status with more fields
+	if p.supportsMsg(forkIDMsgCode) {
+		if err = p.exchangeForkID(config, height); err != nil {
+			return err
+		}
+	} else {
+		p.log.Warn("legacy peer %s without fork id, which may not be upgraded for the next fork", p.peerStrID)
+	}
+
 	p.head = retStatusMsg.CurrentBlock
 	p.td = retStatusMsg.TD
 	return nil
 }
 
+// supportsMsg returns true if the peer handles the message code, which should not be sent to the peer
+// otherwise, e.g. the legacy peers disconnect on the unknown codes.
+func (p *peer) supportsMsg(code uint16) bool {
+	version, ok := msgCodeVersions[code]
+	return !ok || p.statusVersion >= version
+}
+
+// exchangeForkID sends the local fork id to the peer and verifies the fork id of the peer
+func (p *peer) exchangeForkID(config *common.ChainConfig, height uint64) error {
+	if err := p2p.SendMessage(p.rw, forkIDMsgCode, common.SerializePanic(config.ForkID(height))); err != nil {
+		return err
+	}
+
+	retMsg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+
+	if retMsg.Code != forkIDMsgCode {
+		return errMsgNotMatch
+	}
+
+	var remote common.ForkID
+	if err = common.Deserialize(retMsg.Payload, &remote); err != nil {
+		return err
+	}
+
+	if err = verifyForkID(remote, config, height); err != nil {
+		return err
+	}
+
+	p.forkID = &remote
+	return nil
+}
+
+// verifyForkID checks the fork id of the peer against the chain config at the local head height
+func verifyForkID(remote common.ForkID, config *common.ChainConfig, height uint64) error {
+	if err := config.CheckForkID(height, remote); err != nil {
+		local := config.ForkID(height)
+		return fmt.Errorf("%s, local %08x/%d at height %d, remote %08x/%d", errForkIDNotMatch, local.Hash, local.Next,
+			height, remote.Hash, remote.Next)
+	}
+
+	return nil
+}
+
 func verifyGenesisAndNetworkID(retStatusMsg statusData, genesis common.Hash, networkID string, shard uint, difficult uint64) error {
 	if retStatusMsg.NetworkID != networkID {
 		return errNetworkNotMatch
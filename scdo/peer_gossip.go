@@ -0,0 +1,346 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+const (
+	maxQueuedTxs   = 4096 // max txs queued to send to a peer, the oldest are dropped when it is full
+	maxQueuedDebts = 4096 // max debts queued to send to a peer, the oldest are dropped when it is full
+
+	maxTxsPerGossipMsg   = 256 // max txs sent in a transactionsMsgCode message
+	maxDebtsPerGossipMsg = 256 // max debts sent in a debtMsgCode message
+
+	maxQueuedTxHashes      = 4096                   // max tx hashes queued to announce to a peer, the oldest are dropped when it is full
+	maxTxHashesPerAnnounce = 256                    // max tx hashes announced to a peer in a round
+	txAnnounceInterval     = 100 * time.Millisecond // min interval between the full rounds of tx hash announcements to a peer
+)
+
+// gossipQueue is the bounded queue of the txs and debts to send to a peer asynchronously.
+// The queued items are coalesced by hash, and the oldest items are dropped under pressure,
+// so that a burst of txs never blocks the caller on a slow peer.
+type gossipQueue struct {
+	lock    sync.Mutex
+	txs     []*types.Transaction
+	txSet   map[common.Hash]bool
+	debts   []*types.Debt
+	debtSet map[common.Hash]bool
+	hashes  []common.Hash // tx hashes to announce
+	hashSet map[common.Hash]bool
+
+	wakeup    chan struct{}
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+func newGossipQueue() *gossipQueue {
+	return &gossipQueue{
+		txSet:   make(map[common.Hash]bool),
+		debtSet: make(map[common.Hash]bool),
+		hashSet: make(map[common.Hash]bool),
+		wakeup:  make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+	}
+}
+
+// addTxs queues the txs which are not queued yet, and returns the number of the dropped oldest txs.
+func (q *gossipQueue) addTxs(txs []*types.Transaction) int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, tx := range txs {
+		if tx != nil && !q.txSet[tx.Hash] {
+			q.txSet[tx.Hash] = true
+			q.txs = append(q.txs, tx)
+		}
+	}
+
+	dropped := 0
+	if n := len(q.txs) - maxQueuedTxs; n > 0 {
+		for _, tx := range q.txs[:n] {
+			delete(q.txSet, tx.Hash)
+		}
+
+		q.txs = append([]*types.Transaction(nil), q.txs[n:]...)
+		dropped = n
+	}
+
+	q.notify()
+
+	return dropped
+}
+
+// addDebts queues the debts which are not queued yet, and returns the number of the dropped oldest debts.
+func (q *gossipQueue) addDebts(debts []*types.Debt) int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, d := range debts {
+		if d != nil && !q.debtSet[d.Hash] {
+			q.debtSet[d.Hash] = true
+			q.debts = append(q.debts, d)
+		}
+	}
+
+	dropped := 0
+	if n := len(q.debts) - maxQueuedDebts; n > 0 {
+		for _, d := range q.debts[:n] {
+			delete(q.debtSet, d.Hash)
+		}
+
+		q.debts = append([]*types.Debt(nil), q.debts[n:]...)
+		dropped = n
+	}
+
+	q.notify()
+
+	return dropped
+}
+
+// addTxHashes queues the tx hashes to announce which are not queued yet, and returns the number of the
+// dropped oldest hashes.
+func (q *gossipQueue) addTxHashes(hashes []common.Hash) int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, hash := range hashes {
+		if !q.hashSet[hash] {
+			q.hashSet[hash] = true
+			q.hashes = append(q.hashes, hash)
+		}
+	}
+
+	dropped := 0
+	if n := len(q.hashes) - maxQueuedTxHashes; n > 0 {
+		for _, hash := range q.hashes[:n] {
+			delete(q.hashSet, hash)
+		}
+
+		q.hashes = append([]common.Hash(nil), q.hashes[n:]...)
+		dropped = n
+	}
+
+	q.notify()
+
+	return dropped
+}
+
+// popTxs removes and returns at most n oldest queued txs
+func (q *gossipQueue) popTxs(n int) []*types.Transaction {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if n > len(q.txs) {
+		n = len(q.txs)
+	}
+
+	txs := q.txs[:n:n]
+	q.txs = q.txs[n:]
+	for _, tx := range txs {
+		delete(q.txSet, tx.Hash)
+	}
+
+	return txs
+}
+
+// popDebts removes and returns at most n oldest queued debts
+func (q *gossipQueue) popDebts(n int) []*types.Debt {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if n > len(q.debts) {
+		n = len(q.debts)
+	}
+
+	debts := q.debts[:n:n]
+	q.debts = q.debts[n:]
+	for _, d := range debts {
+		delete(q.debtSet, d.Hash)
+	}
+
+	return debts
+}
+
+// popTxHashes removes and returns at most n oldest queued tx hashes
+func (q *gossipQueue) popTxHashes(n int) []common.Hash {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if n > len(q.hashes) {
+		n = len(q.hashes)
+	}
+
+	hashes := q.hashes[:n:n]
+	q.hashes = q.hashes[n:]
+	for _, hash := range hashes {
+		delete(q.hashSet, hash)
+	}
+
+	return hashes
+}
+
+func (q *gossipQueue) notify() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (q *gossipQueue) close() {
+	q.closeOnce.Do(func() { close(q.quit) })
+}
+
+// queueTransactions queues the txs unknown by the peer to send asynchronously
+func (p *peer) queueTransactions(txs []*types.Transaction) {
+	var unknown []*types.Transaction
+	for _, tx := range txs {
+		if !p.isKnownTx(tx.Hash) {
+			unknown = append(unknown, tx)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return
+	}
+
+	if dropped := p.gossip.addTxs(unknown); dropped > 0 {
+		p.log.Debug("dropped %d oldest queued txs of peer %s", dropped, p.peerStrID)
+	}
+}
+
+// queueTxHashes queues the hashes of the txs unknown by the peer to announce asynchronously,
+// and the peer requests the full txs it has not seen.
+func (p *peer) queueTxHashes(hashes []common.Hash) {
+	var unknown []common.Hash
+	for _, hash := range hashes {
+		if !p.isKnownTx(hash) {
+			unknown = append(unknown, hash)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return
+	}
+
+	if dropped := p.gossip.addTxHashes(unknown); dropped > 0 {
+		p.log.Debug("dropped %d oldest queued tx hashes of peer %s", dropped, p.peerStrID)
+	}
+}
+
+// txPushPeers splits the peers into the ones to push the full tx, which is a random subset of sqrt of
+// the peers, and the others to announce the tx hash, so that the bandwidth of propagating the large txs
+// scales sub-linearly with the number of peers.
+func txPushPeers(peers []*peer) (push []*peer, announce []*peer) {
+	count := int(math.Ceil(math.Sqrt(float64(len(peers)))))
+	for i, j := range rand.Perm(len(peers)) {
+		if i < count {
+			push = append(push, peers[j])
+		} else {
+			announce = append(announce, peers[j])
+		}
+	}
+
+	return push, announce
+}
+
+// queueDebts queues the debts to send asynchronously, the debts known by the peer are skipped if filter is true.
+func (p *peer) queueDebts(debts []*types.Debt, filter bool) {
+	var queued []*types.Debt
+	for _, d := range debts {
+		if d != nil && (!filter || !p.knownDebts.Contains(d.Hash)) {
+			queued = append(queued, d)
+		}
+	}
+
+	if len(queued) == 0 {
+		return
+	}
+
+	if dropped := p.gossip.addDebts(queued); dropped > 0 {
+		p.log.Debug("dropped %d oldest queued debts of peer %s", dropped, p.peerStrID)
+	}
+}
+
+// broadcast sends the queued txs and debts to the peer in batches until the peer is removed,
+// and disconnects the peer if failed to send.
+func (p *peer) broadcast() {
+	for {
+		select {
+		case <-p.gossip.wakeup:
+		case <-p.gossip.quit:
+			return
+		}
+
+		for {
+			txs := p.gossip.popTxs(maxTxsPerGossipMsg)
+			debts := p.gossip.popDebts(maxDebtsPerGossipMsg)
+			hashes := p.gossip.popTxHashes(maxTxHashesPerAnnounce)
+			if len(txs) == 0 && len(debts) == 0 && len(hashes) == 0 {
+				break
+			}
+
+			if err := p.sendQueued(txs, debts, hashes); err != nil {
+				p.log.Warn("failed to send queued txs and debts to peer=%s, err=%s", p.peerStrID, err)
+				p.Disconnect(err.Error())
+				return
+			}
+
+			// limit the rate of the announcements, since a tx hash is sent in a message
+			if len(hashes) == maxTxHashesPerAnnounce {
+				select {
+				case <-time.After(txAnnounceInterval):
+				case <-p.gossip.quit:
+					return
+				}
+			}
+
+			select {
+			case <-p.gossip.quit:
+				return
+			default:
+			}
+		}
+	}
+}
+
+func (p *peer) sendQueued(txs []*types.Transaction, debts []*types.Debt, hashes []common.Hash) error {
+	if len(txs) > 0 {
+		if err := p.sendTransactions(txs); err != nil {
+			return err
+		}
+
+		for _, tx := range txs {
+			p.markKnownTx(tx.Hash)
+		}
+	}
+
+	if len(debts) > 0 {
+		if err := p.sendDebts(debts, false); err != nil {
+			return err
+		}
+	}
+
+	for _, hash := range hashes {
+		if err := p.sendTransactionHash(hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stopBroadcast stops sending the queued txs and debts
+func (p *peer) stopBroadcast() {
+	p.gossip.close()
+}
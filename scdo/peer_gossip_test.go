@@ -0,0 +1,157 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	log2 "github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockGossipMsgReadWriter struct {
+	msgs chan *p2p.Message
+}
+
+func (rw *mockGossipMsgReadWriter) ReadMsg() (*p2p.Message, error) { return nil, nil }
+func (rw *mockGossipMsgReadWriter) WriteMsg(msg *p2p.Message) error {
+	rw.msgs <- msg
+	return nil
+}
+
+func newTestGossipTxs(from, to int) []*types.Transaction {
+	var txs []*types.Transaction
+	for i := from; i < to; i++ {
+		txs = append(txs, &types.Transaction{Hash: common.BigToHash(big.NewInt(int64(i)))})
+	}
+
+	return txs
+}
+
+func Test_GossipQueue(t *testing.T) {
+	q := newGossipQueue()
+
+	// coalesced by hash
+	assert.Equal(t, q.addTxs(newTestGossipTxs(0, 10)), 0)
+	assert.Equal(t, q.addTxs(newTestGossipTxs(5, 15)), 0)
+	assert.Equal(t, len(q.txs), 15)
+
+	// batched
+	txs := q.popTxs(10)
+	assert.Equal(t, txs, newTestGossipTxs(0, 10))
+	assert.Equal(t, q.popTxs(10), newTestGossipTxs(10, 15))
+	assert.Equal(t, len(q.popTxs(10)), 0)
+
+	// the popped txs could be queued again
+	assert.Equal(t, q.addTxs(txs[:1]), 0)
+	assert.Equal(t, q.popTxs(10), txs[:1])
+
+	// drop the oldest under pressure
+	assert.Equal(t, q.addTxs(newTestGossipTxs(0, maxQueuedTxs)), 0)
+	assert.Equal(t, q.addTxs(newTestGossipTxs(maxQueuedTxs, maxQueuedTxs+10)), 10)
+	assert.Equal(t, len(q.txs), maxQueuedTxs)
+	assert.Equal(t, len(q.txSet), maxQueuedTxs)
+	assert.Equal(t, q.popTxs(1), newTestGossipTxs(10, 11))
+
+	// debts
+	debts := []*types.Debt{{Hash: common.StringToHash("debt1")}, {Hash: common.StringToHash("debt2")}}
+	assert.Equal(t, q.addDebts(debts), 0)
+	assert.Equal(t, q.addDebts(debts[1:]), 0)
+	assert.Equal(t, q.popDebts(maxDebtsPerGossipMsg), debts)
+}
+
+func Test_Peer_Broadcast(t *testing.T) {
+	n := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 0)
+	rw := &mockGossipMsgReadWriter{make(chan *p2p.Message, 10)}
+	peer := newPeer(common.ScdoVersion, &p2p.Peer{Node: n}, rw, log2.GetLogger("test"), node.PeerKnownCacheConfig{})
+	defer peer.stopBroadcast()
+
+	txs := newTestGossipTxs(0, maxTxsPerGossipMsg+10)
+	peer.markKnownTx(txs[0].Hash)
+	peer.queueTransactions(txs)
+	go peer.broadcast()
+
+	for _, expected := range [][]*types.Transaction{txs[1 : maxTxsPerGossipMsg+1], txs[maxTxsPerGossipMsg+1:]} {
+		select {
+		case msg := <-rw.msgs:
+			assert.Equal(t, msg.Code, transactionsMsgCode)
+
+			var sent []*types.Transaction
+			assert.Equal(t, common.Deserialize(msg.Payload, &sent), nil)
+			assert.Equal(t, len(sent), len(expected))
+			assert.Equal(t, sent[0].Hash, expected[0].Hash)
+		case <-time.After(time.Second):
+			t.Fatal("txs not sent")
+		}
+	}
+
+	// the sent txs are known by the peer
+	assert.Equal(t, peer.isKnownTx(txs[1].Hash), true)
+	peer.queueTransactions(txs[:maxTxsPerGossipMsg+1])
+	assert.Equal(t, len(peer.gossip.popTxs(maxTxsPerGossipMsg)), 0)
+}
+
+func Test_GossipQueue_TxHashes(t *testing.T) {
+	q := newGossipQueue()
+
+	var hashes []common.Hash
+	for _, tx := range newTestGossipTxs(0, maxQueuedTxHashes+10) {
+		hashes = append(hashes, tx.Hash)
+	}
+
+	assert.Equal(t, q.addTxHashes(hashes[:10]), 0)
+	assert.Equal(t, q.addTxHashes(hashes[5:maxQueuedTxHashes]), 0)
+	assert.Equal(t, q.addTxHashes(hashes[maxQueuedTxHashes:]), 10)
+	assert.Equal(t, len(q.hashSet), maxQueuedTxHashes)
+	assert.Equal(t, q.popTxHashes(2), hashes[10:12])
+}
+
+func Test_TxPushPeers(t *testing.T) {
+	for _, c := range []struct{ peers, push int }{{0, 0}, {1, 1}, {2, 2}, {4, 2}, {10, 4}, {50, 8}} {
+		var peers []*peer
+		for i := 0; i < c.peers; i++ {
+			peers = append(peers, getTestPeer(1))
+		}
+
+		push, announce := txPushPeers(peers)
+		assert.Equal(t, len(push), c.push)
+		assert.Equal(t, len(announce), c.peers-c.push)
+	}
+}
+
+func Test_Peer_BroadcastTxHashes(t *testing.T) {
+	n := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 0)
+	rw := &mockGossipMsgReadWriter{make(chan *p2p.Message, 10)}
+	peer := newPeer(common.ScdoVersion, &p2p.Peer{Node: n}, rw, log2.GetLogger("test"), node.PeerKnownCacheConfig{})
+	defer peer.stopBroadcast()
+
+	txs := newTestGossipTxs(0, 2)
+	peer.markKnownTx(txs[0].Hash)
+	peer.queueTxHashes([]common.Hash{txs[0].Hash, txs[1].Hash})
+	go peer.broadcast()
+
+	select {
+	case msg := <-rw.msgs:
+		assert.Equal(t, msg.Code, transactionHashMsgCode)
+
+		var hash common.Hash
+		assert.Equal(t, common.Deserialize(msg.Payload, &hash), nil)
+		assert.Equal(t, hash, txs[1].Hash)
+	case <-time.After(time.Second):
+		t.Fatal("tx hash not announced")
+	}
+
+	// the announced tx is known by the peer
+	assert.Equal(t, peer.isKnownTx(txs[1].Hash), true)
+}
@@ -8,9 +8,11 @@ package scdo
 import (
 	"math/big"
 	rand "math/rand"
+	"sort"
 	"sync"
 
 	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
 )
 
 type peerSet struct {
@@ -48,42 +50,87 @@ func (p *peerSet) bestPeer(shard uint) *peer {
 	return bestPeer
 }
 
-func (p *peerSet) bestPeers(shard uint, localTD *big.Int) []*peer {
+// numOfBestPeers is the max number of the peers to synchronise with in order
+const numOfBestPeers = 3
+
+// bestPeers returns the peers whose total difficulties are larger than the local one, ranked by the health
+// score of the peer, e.g. the delivery rate and recent failures, and then the total difficulty. So that
+// a peer with high total difficulty but terrible latency does not stall the sync. The peers are ranked by
+// the total difficulty only if the health is nil.
+func (p *peerSet) bestPeers(shard uint, localTD *big.Int, health func(peerID string) float64) []*peer {
+	type candidate struct {
+		peer  *peer
+		td    *big.Int
+		score float64
+	}
 
-	var bestPeers [3]*peer
-	peers := p.getPeerByShard(shard)
+	var candidates []candidate
+	for _, peer := range p.getPeerByShard(shard) {
+		if _, td := peer.Head(); td.Cmp(localTD) > 0 {
+			c := candidate{peer: peer, td: td}
+			if health != nil {
+				c.score = health(peer.peerStrID)
+			}
 
-	NumOfBestPeers := 3
-	if len(peers) < NumOfBestPeers {
-		NumOfBestPeers = len(peers)
+			candidates = append(candidates, c)
+		}
 	}
 
-	count := 0
-	for _, peer := range peers {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
 
-		if _, td := peer.Head(); td.Cmp(localTD) > 0 {
-			if count < NumOfBestPeers {
-				bestPeers[count] = peer
-				count++
-			} else {
-				for i := 0; i < count; i++ {
-					if _, TD := bestPeers[i].Head(); td.Cmp(TD) > 0 {
-						bestPeers[i] = peer
-						break
-					}
+		return candidates[i].td.Cmp(candidates[j].td) > 0
+	})
 
-				}
-			}
+	ranked := make([]*peer, len(candidates))
+	for i, c := range candidates {
+		ranked[i] = c.peer
+	}
 
+	return diversePeers(ranked, numOfBestPeers)
+}
+
+// diversePeers selects at most n peers in the ranked order, and prefers the peers of the subnets which are not
+// selected yet, so that the sync does not depend on the peers of a subnet, which are likely run by the same party.
+func diversePeers(ranked []*peer, n int) []*peer {
+	limit := discovery.NewSubnetLimit(0, 0, 0)
+	subnets := make(map[string]bool)
+	selected := make([]bool, len(ranked))
+
+	var result []*peer
+	for i, p := range ranked {
+		if len(result) == n {
+			break
+		}
+
+		var subnet string
+		if p.Peer != nil && p.Node != nil {
+			subnet = limit.Subnet(p.Node.IP)
+		}
+
+		if subnet != "" && subnets[subnet] {
+			continue
 		}
 
+		subnets[subnet] = true
+		selected[i] = true
+		result = append(result, p)
 	}
-	var bestpeers []*peer
-	for i := 0; i < count; i++ {
-		bestpeers = append(bestpeers, bestPeers[i])
+
+	// fill up with the peers of the selected subnets
+	for i, p := range ranked {
+		if len(result) == n {
+			break
+		}
+
+		if !selected[i] {
+			result = append(result, p)
+		}
 	}
-	return bestpeers
 
+	return result
 }
 
 func (p *peerSet) Find(address common.Address) *peer {
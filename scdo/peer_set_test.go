@@ -6,11 +6,14 @@
 package scdo
 
 import (
+	"math/big"
 	"net"
 	"testing"
 
+	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/crypto"
 	log2 "github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
 	"github.com/scdoproject/go-scdo/p2p"
 	"github.com/scdoproject/go-scdo/p2p/discovery"
 	"github.com/stretchr/testify/assert"
@@ -19,9 +22,9 @@ import (
 func getTestPeer(shard uint) *peer {
 	log := log2.GetLogger("test")
 	addr := crypto.MustGenerateRandomAddress()
-	node := discovery.NewNodeWithAddr(*addr, &net.UDPAddr{}, shard)
-	p2pPeer := p2p.NewPeer(nil, nil, node)
-	peer := newPeer(1, p2pPeer, nil, log)
+	n := discovery.NewNodeWithAddr(*addr, &net.UDPAddr{}, shard)
+	p2pPeer := p2p.NewPeer(nil, nil, n)
+	peer := newPeer(1, p2pPeer, nil, log, node.PeerKnownCacheConfig{})
 
 	return peer
 }
@@ -74,3 +77,48 @@ func Test_PeerSet_Remove(t *testing.T) {
 	assert.Equal(t, len(set.shardPeers[0]), 0)
 	assert.Equal(t, len(set.shardPeers[1]), 0)
 }
+
+func Test_PeerSet_BestPeers(t *testing.T) {
+	set := newPeerSet()
+	tds := []int64{10, 50, 40, 30, 20}
+	peers := make([]*peer, len(tds))
+	for i, td := range tds {
+		peers[i] = getTestPeer(0)
+		peers[i].SetHead(common.StringToHash("head"), big.NewInt(td))
+		set.Add(peers[i])
+	}
+
+	// ranked by total difficulty without health
+	assert.Equal(t, set.bestPeers(0, big.NewInt(10), nil), []*peer{peers[1], peers[2], peers[3]})
+	assert.Equal(t, set.bestPeers(0, big.NewInt(30), nil), []*peer{peers[1], peers[2]})
+	assert.Equal(t, len(set.bestPeers(0, big.NewInt(50), nil)), 0)
+
+	// the slow peer with the highest total difficulty is ranked last
+	scores := map[string]float64{peers[1].peerStrID: 0.5, peers[4].peerStrID: 200}
+	health := func(id string) float64 {
+		if score, ok := scores[id]; ok {
+			return score
+		}
+
+		return 100
+	}
+
+	assert.Equal(t, set.bestPeers(0, big.NewInt(10), health), []*peer{peers[4], peers[2], peers[3]})
+	assert.Equal(t, set.bestPeers(0, big.NewInt(30), health), []*peer{peers[2], peers[1]})
+}
+
+func Test_PeerSet_BestPeersDiversity(t *testing.T) {
+	set := newPeerSet()
+	tds := []int64{50, 40, 30, 20}
+	ips := []string{"1.2.3.4", "1.2.3.5", "1.2.3.6", "5.6.7.8"}
+	peers := make([]*peer, len(tds))
+	for i, td := range tds {
+		peers[i] = getTestPeer(0)
+		peers[i].Node.IP = net.ParseIP(ips[i])
+		peers[i].SetHead(common.StringToHash("head"), big.NewInt(td))
+		set.Add(peers[i])
+	}
+
+	// the peer of another subnet is preferred to the peers of the same subnet
+	assert.Equal(t, set.bestPeers(0, big.NewInt(10), nil), []*peer{peers[0], peers[3], peers[1]})
+}
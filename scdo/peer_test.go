@@ -6,15 +6,17 @@
 package scdo
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
-	"strings"
 	"testing"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/crypto"
 	log2 "github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
 	"github.com/scdoproject/go-scdo/p2p"
 	"github.com/scdoproject/go-scdo/p2p/discovery"
 	"github.com/stretchr/testify/assert"
@@ -31,10 +33,10 @@ func Test_peer_Info(t *testing.T) {
 	var myHash common.Hash
 	copy(myHash[0:20], myAddr[:])
 	bigInt := big.NewInt(100)
-	okStr := fmt.Sprintf(`{"version":1,"difficulty":100,"head":"%v000000000000000000000000"}`, strings.TrimPrefix(myAddr.Hex(), "0x"))
+	okStr := fmt.Sprintf(`{"version":1,"difficulty":100,"head":"%v000000000000000000000000","syncedTxs":0}`, hex.EncodeToString(myAddr.Bytes()))
 
 	// Create peer for test
-	peer := newPeer(common.ScdoVersion, p2pPeer, nil, log)
+	peer := newPeer(common.ScdoVersion, p2pPeer, nil, log, node.PeerKnownCacheConfig{})
 	peer.SetHead(myHash, bigInt)
 
 	peerInfo := peer.Info()
@@ -69,3 +71,121 @@ func Test_verifyGenesis(t *testing.T) {
 	err = verifyGenesisAndNetworkID(statusData, errorHash, networkID, 1, 8000000)
 	assert.Equal(t, err != nil, true)
 }
+
+func Test_verifyForkID(t *testing.T) {
+	config := common.DefaultChainConfig()
+	config.SignalingExtraForkHeight = common.ScdoForkHeight + 100
+	height := uint64(common.ScdoForkHeight + 150)
+
+	assert.Equal(t, verifyForkID(config.ForkID(height), config, height), nil)
+
+	// the peer not upgraded for the fork passed
+	assert.Equal(t, verifyForkID(common.ForkID{Hash: 0}, config, height) != nil, true)
+}
+
+func Test_statusData_Legacy(t *testing.T) {
+	type legacyStatusData struct {
+		ProtocolVersion uint32
+		NetworkID       string
+		TD              *big.Int
+		CurrentBlock    common.Hash
+		GenesisBlock    common.Hash
+		Shard           uint
+		Difficult       uint64
+	}
+
+	legacy := legacyStatusData{1, "scdo", big.NewInt(1), common.EmptyHash, common.EmptyHash, 1, 8000000}
+
+	var status statusData
+	assert.Equal(t, common.Deserialize(common.SerializePanic(legacy), &status), nil)
+	assert.Equal(t, status.NetworkID, "scdo")
+
+	// the legacy peers decode the status of the upgraded peers
+	status.ProtocolVersion = statusProtocolVersion
+	var decoded legacyStatusData
+	assert.Equal(t, common.Deserialize(common.SerializePanic(status), &decoded), nil)
+	assert.Equal(t, decoded.ProtocolVersion, statusProtocolVersion)
+	assert.Equal(t, decoded.Difficult, uint64(8000000))
+}
+
+// mockHandShakeMsgReadWriter replies the queued messages of the remote peer
+type mockHandShakeMsgReadWriter struct {
+	replies []*p2p.Message
+	sent    []*p2p.Message
+}
+
+func (rw *mockHandShakeMsgReadWriter) ReadMsg() (*p2p.Message, error) {
+	if len(rw.replies) == 0 {
+		return nil, io.EOF
+	}
+
+	msg := rw.replies[0]
+	rw.replies = rw.replies[1:]
+	return msg, nil
+}
+
+func (rw *mockHandShakeMsgReadWriter) WriteMsg(msg *p2p.Message) error {
+	rw.sent = append(rw.sent, msg)
+	return nil
+}
+
+func Test_peer_handShake_ForkID(t *testing.T) {
+	config := common.DefaultChainConfig()
+	genesis := common.StringToHash("genesis")
+	n := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 0)
+
+	handShake := func(remote statusData, remoteForkID *common.ForkID) (*peer, *mockHandShakeMsgReadWriter, error) {
+		rw := &mockHandShakeMsgReadWriter{}
+		rw.replies = append(rw.replies, &p2p.Message{Code: statusDataMsgCode, Payload: common.SerializePanic(remote)})
+		if remoteForkID != nil {
+			rw.replies = append(rw.replies, &p2p.Message{Code: forkIDMsgCode, Payload: common.SerializePanic(remoteForkID)})
+		}
+
+		p := newPeer(common.ScdoVersion, &p2p.Peer{Node: n}, rw, log2.GetLogger("test"), node.PeerKnownCacheConfig{})
+		err := p.handShake("scdo", big.NewInt(1), common.EmptyHash, genesis, 8000000, config, common.ScdoForkHeight)
+		return p, rw, err
+	}
+
+	status := statusData{uint32(common.ScdoVersion), "scdo", big.NewInt(1), common.EmptyHash, genesis, common.LocalShardNumber, 8000000}
+
+	// the fork id is not sent to the legacy peers
+	p, rw, err := handShake(status, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, p.forkID == nil, true)
+	assert.Equal(t, len(rw.sent), 1)
+
+	status.ProtocolVersion = statusProtocolVersion
+	forkID := config.ForkID(common.ScdoForkHeight)
+	p, rw, err = handShake(status, &forkID)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *p.forkID, forkID)
+	assert.Equal(t, rw.sent[1].Code, forkIDMsgCode)
+
+	_, _, err = handShake(status, &common.ForkID{Hash: 1})
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_peer_SupportsMsg(t *testing.T) {
+	n := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 0)
+	peer := newPeer(common.ScdoVersion, &p2p.Peer{Node: n}, nil, log2.GetLogger("test"), node.PeerKnownCacheConfig{})
+
+	// the legacy peers only handle the codes they know
+	peer.statusVersion = uint32(common.ScdoVersion)
+	assert.Equal(t, peer.supportsMsg(debtMsgCode), true)
+	assert.Equal(t, peer.supportsMsg(debtAckMsgCode), false)
+	assert.Equal(t, peer.supportsMsg(nonceReservationMsgCode), false)
+	assert.Equal(t, peer.supportsMsg(compactBlockMsgCode), false)
+	assert.Equal(t, peer.supportsMsg(forkIDMsgCode), false)
+
+	peer.statusVersion = statusProtocolVersion
+	assert.Equal(t, peer.supportsMsg(debtMsgCode), true)
+	assert.Equal(t, peer.supportsMsg(debtAckMsgCode), true)
+	assert.Equal(t, peer.supportsMsg(nonceReservationMsgCode), true)
+	assert.Equal(t, peer.supportsMsg(compactBlockMsgCode), true)
+	assert.Equal(t, peer.supportsMsg(forkIDMsgCode), true)
+
+	// the codes added after the legacy peers are all versioned
+	for code := nonceReservationMsgCode; code < protocolMsgCodeLength; code++ {
+		assert.Equal(t, msgCodeVersions[code] > uint32(common.ScdoVersion), true)
+	}
+}
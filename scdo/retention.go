@@ -0,0 +1,189 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+)
+
+const defaultRetentionInterval = 10 * time.Minute
+
+// retentionTarget is the block data pruned by the retention policy independently
+type retentionTarget struct {
+	name         string
+	period       time.Duration
+	prunedHeight func(bcStore store.BlockchainStore) (uint64, error)
+	prune        func(bcStore store.BlockchainStore, hashes []common.Hash, prunedHeight uint64) (int, error)
+}
+
+// retentionPruner prunes the receipts and dirty accounts of the blocks older than their retention periods,
+// for the operators who keep the full blocks but only the recent receipts.
+type retentionPruner struct {
+	chain    diskQuotaChain
+	log      *log.ScdoLog
+	targets  []*retentionTarget
+	interval time.Duration
+
+	// keepBlocks is the number of the most recent blocks which are never pruned, as the disk quota does
+	keepBlocks uint64
+
+	quitCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newRetentionPruner(chain diskQuotaChain, conf node.RetentionConfig) *retentionPruner {
+	r := &retentionPruner{
+		chain:      chain,
+		log:        log.GetLogger("retention"),
+		interval:   durationOrDefault(conf.Interval, defaultRetentionInterval),
+		keepBlocks: minDiskQuotaKeepBlocks,
+		quitCh:     make(chan struct{}),
+	}
+
+	if conf.ReceiptsDays > 0 {
+		r.targets = append(r.targets, &retentionTarget{
+			name:         "receipts",
+			period:       time.Duration(conf.ReceiptsDays) * 24 * time.Hour,
+			prunedHeight: store.BlockchainStore.GetReceiptsPrunedHeight,
+			prune:        store.BlockchainStore.PruneReceipts,
+		})
+	}
+
+	if conf.DirtyAccountsDays > 0 {
+		r.targets = append(r.targets, &retentionTarget{
+			name:         "dirty accounts",
+			period:       time.Duration(conf.DirtyAccountsDays) * 24 * time.Hour,
+			prunedHeight: store.BlockchainStore.GetDirtyAccountsPrunedHeight,
+			prune:        store.BlockchainStore.PruneDirtyAccounts,
+		})
+	}
+
+	return r
+}
+
+func (r *retentionPruner) start() {
+	r.wg.Add(1)
+	go r.loop()
+}
+
+func (r *retentionPruner) stop() {
+	close(r.quitCh)
+	r.wg.Wait()
+}
+
+func (r *retentionPruner) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.check()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.check()
+		case <-r.quitCh:
+			return
+		}
+	}
+}
+
+func (r *retentionPruner) check() {
+	now := time.Now()
+
+	for _, target := range r.targets {
+		cutoff := now.Add(-target.period).Unix()
+		if cutoff <= 0 {
+			continue
+		}
+
+		reclaimed, blocks, err := r.prune(target, uint64(cutoff))
+		if err != nil {
+			r.log.Warn("failed to prune the %s, %s", target.name, err)
+		}
+
+		if blocks > 0 {
+			r.log.Info("pruned the %s of %d blocks older than %v, %d bytes reclaimed", target.name, blocks, target.period, reclaimed)
+		}
+	}
+}
+
+// prune prunes the block data of the target from the oldest blocks until the block created after the cutoff
+// unix time, except the most recent blocks. Returns the reclaimed bytes and the number of the pruned blocks.
+func (r *retentionPruner) prune(target *retentionTarget, cutoff uint64) (reclaimed int64, blocks uint64, err error) {
+	head := r.chain.CurrentBlock().Header.Height
+	if head <= r.keepBlocks {
+		return 0, 0, nil
+	}
+
+	bcStore := r.chain.GetStore()
+	limit := head - r.keepBlocks
+
+	// start from the genesis block if never pruned
+	height, err := target.prunedHeight(bcStore)
+	if err != nil {
+		height = 0
+	}
+
+	for height < limit {
+		select {
+		case <-r.quitCh:
+			return reclaimed, blocks, nil
+		default:
+		}
+
+		end := height + diskQuotaPruneBatch
+		if end > limit {
+			end = limit
+		}
+
+		hashes := make([]common.Hash, 0, end-height)
+		expired := true
+		for h := height + 1; h <= end && expired; h++ {
+			hash, err := bcStore.GetBlockHash(h)
+			if err != nil {
+				return reclaimed, blocks, err
+			}
+
+			header, err := bcStore.GetBlockHeader(hash)
+			if err != nil {
+				return reclaimed, blocks, err
+			}
+
+			// the block timestamps are increasing, so the later blocks are not expired either
+			if expired = header.CreateTimestamp.Uint64() <= cutoff; expired {
+				hashes = append(hashes, hash)
+			}
+		}
+
+		if len(hashes) == 0 {
+			break
+		}
+
+		end = height + uint64(len(hashes))
+		size, err := target.prune(bcStore, hashes, end)
+		if err != nil {
+			return reclaimed, blocks, err
+		}
+
+		reclaimed += int64(size)
+		blocks += uint64(len(hashes))
+		height = end
+
+		if !expired {
+			break
+		}
+	}
+
+	return reclaimed, blocks, nil
+}
@@ -0,0 +1,72 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Retention_Prune(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	blocks := []*types.Block{chain.putBlock(t, nil, "")}
+	for i := 1; i <= 20; i++ {
+		block := chain.putBlock(t, blocks[i-1], "")
+		assert.Equal(t, chain.bcStore.PutReceipts(block.HeaderHash, []*types.Receipt{{UsedGas: uint64(i)}}), nil)
+		assert.Equal(t, chain.bcStore.PutDirtyAccounts(block.HeaderHash, []common.Address{common.EmptyAddress}), nil)
+		blocks = append(blocks, block)
+	}
+	chain.head = blocks[20]
+
+	r := newRetentionPruner(chain, node.RetentionConfig{ReceiptsDays: 30})
+	assert.Equal(t, len(r.targets), 1)
+	r.keepBlocks = 5
+
+	// the block timestamp of height h is h + 1
+	reclaimed, pruned, err := r.prune(r.targets[0], 11)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, reclaimed > 0, true)
+	assert.Equal(t, pruned, uint64(10))
+
+	height, err := chain.bcStore.GetReceiptsPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, uint64(10))
+
+	_, err = chain.bcStore.GetReceiptsByBlockHash(blocks[10].HeaderHash)
+	assert.Equal(t, err, store.ErrReceiptsPruned)
+	receipts, err := chain.bcStore.GetReceiptsByBlockHash(blocks[11].HeaderHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, receipts[0].UsedGas, uint64(11))
+
+	// the dirty accounts are retained independently
+	_, err = chain.bcStore.GetDirtyAccountsPrunedHeight()
+	assert.Equal(t, err != nil, true)
+	_, err = chain.bcStore.GetDirtyAccountsByBlockHash(blocks[1].HeaderHash)
+	assert.Equal(t, err, nil)
+
+	// the most recent blocks are kept
+	_, pruned, err = r.prune(r.targets[0], 100)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pruned, uint64(5))
+
+	height, err = chain.bcStore.GetReceiptsPrunedHeight()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, height, uint64(15))
+
+	// nothing to prune until the head moves
+	_, pruned, err = r.prune(r.targets[0], 100)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pruned, uint64(0))
+}
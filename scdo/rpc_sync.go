@@ -0,0 +1,267 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/sdk"
+)
+
+const (
+	defaultRPCSyncInterval = 10 * time.Second
+
+	// rpcSyncMaxRewinds is the max batches to step back to find the common ancestor with the trusted chain
+	rpcSyncMaxRewinds = 16
+
+	// rpcSyncTimeout is the timeout of a request to the trusted rpc endpoints
+	rpcSyncTimeout = 30 * time.Second
+)
+
+var errRPCSyncForkTooDeep = errors.New("common ancestor with the trusted chain not found")
+
+// GetRawBlocks returns at most size canonical blocks from the height with the txs and debts, which are
+// imported by the nodes syncing from the trusted rpc endpoints.
+func (api *PublicScdoAPI) GetRawBlocks(height uint64, size uint) ([]*types.Block, error) {
+	return getRawBlocks(api.s.chain.GetStore(), height, size)
+}
+
+func getRawBlocks(bcStore store.BlockchainStore, height uint64, size uint) ([]*types.Block, error) {
+	if size > maxSizeLimit {
+		size = maxSizeLimit
+	}
+
+	blocks := make([]*types.Block, 0, size)
+	for h := height; uint(len(blocks)) < size; h++ {
+		block, err := bcStore.GetBlockByHeight(h)
+		if err != nil {
+			// no more blocks after the HEAD
+			break
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// rpcSyncChain is the blockchain to import the blocks synced from the trusted rpc endpoints
+type rpcSyncChain interface {
+	CurrentBlock() *types.Block
+	GetStore() store.BlockchainStore
+	PrevalidateBlocks(blocks []*types.Block) []<-chan error
+	WriteBlock(block *types.Block, txPool *core.Pool) error
+}
+
+// rpcSyncClient is the client of the trusted rpc endpoints
+type rpcSyncClient interface {
+	GetBlockHeight(ctx context.Context, shard uint) (uint64, error)
+	GetRawBlocks(ctx context.Context, shard uint, height uint64, size uint) ([]*types.Block, error)
+	Close()
+}
+
+// rpcSyncer syncs the blocks in batches from the trusted rpc endpoints of the local shard, for the nodes
+// without healthy p2p connectivity. The blocks are validated fully and written as the blocks synced from peers.
+type rpcSyncer struct {
+	chain  rpcSyncChain
+	txPool *core.Pool
+	log    *log.ScdoLog
+
+	conf      *sdk.Config
+	client    rpcSyncClient // dialed on demand, and redialed after failures
+	batchSize uint
+	interval  time.Duration
+
+	ctx    context.Context // canceled on stop to abort the requests in flight
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newRPCSyncer(chain rpcSyncChain, txPool *core.Pool, conf node.RPCSyncConfig) *rpcSyncer {
+	r := &rpcSyncer{
+		chain:     chain,
+		txPool:    txPool,
+		log:       log.GetLogger("rpcsync"),
+		conf:      sdk.DefaultConfig(conf.Endpoints...),
+		batchSize: conf.BatchSize,
+		interval:  durationOrDefault(conf.Interval, defaultRPCSyncInterval),
+	}
+
+	if r.batchSize == 0 || r.batchSize > maxSizeLimit {
+		r.batchSize = maxSizeLimit
+	}
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	return r
+}
+
+func (r *rpcSyncer) start() {
+	r.wg.Add(1)
+	go r.loop()
+}
+
+func (r *rpcSyncer) stop() {
+	r.cancel()
+	r.wg.Wait()
+
+	if r.client != nil {
+		r.client.Close()
+		r.client = nil
+	}
+}
+
+func (r *rpcSyncer) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.sync(); err != nil && r.ctx.Err() == nil {
+			r.log.Warn("failed to sync from the trusted rpc endpoints, %s", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// sync imports the blocks from the local HEAD to the HEAD of the trusted chain
+func (r *rpcSyncer) sync() error {
+	if r.client == nil {
+		client, err := sdk.Dial(r.ctx, r.conf)
+		if err != nil {
+			return err
+		}
+
+		r.client = client
+	}
+
+	remote, err := r.remoteHeight()
+	if err != nil {
+		return err
+	}
+
+	head := r.chain.CurrentBlock().Header.Height
+	if remote <= head {
+		return nil
+	}
+
+	r.log.Info("sync from the trusted rpc endpoints, local height %d, remote height %d", head, remote)
+	event.BlockDownloaderEventManager.Fire(event.DownloaderStartEvent)
+
+	if err = r.syncBlocks(head+1, remote); err != nil {
+		event.BlockDownloaderEventManager.Fire(event.DownloaderFailedEvent)
+		return err
+	}
+
+	event.BlockDownloaderEventManager.Fire(event.DownloaderDoneEvent)
+
+	return nil
+}
+
+func (r *rpcSyncer) remoteHeight() (uint64, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, rpcSyncTimeout)
+	defer cancel()
+
+	height, err := r.client.GetBlockHeight(ctx, common.LocalShardNumber)
+	if err != nil {
+		r.closeClient()
+	}
+
+	return height, err
+}
+
+// closeClient closes the client after failures, so that the endpoints are dialed again in the next sync
+func (r *rpcSyncer) closeClient() {
+	r.client.Close()
+	r.client = nil
+}
+
+// syncBlocks fetches and imports the blocks in batches from the height, and steps back to the
+// common ancestor if the local chain forks from the trusted chain.
+func (r *rpcSyncer) syncBlocks(from uint64, to uint64) error {
+	bcStore := r.chain.GetStore()
+	rewinds := 0
+
+	for from <= to {
+		ctx, cancel := context.WithTimeout(r.ctx, rpcSyncTimeout)
+		blocks, err := r.client.GetRawBlocks(ctx, common.LocalShardNumber, from, r.batchSize)
+		cancel()
+
+		if err != nil {
+			r.closeClient()
+			return err
+		}
+
+		if len(blocks) == 0 {
+			return nil
+		}
+
+		for i, block := range blocks {
+			if block == nil || block.Header == nil || block.Header.Height != from+uint64(i) {
+				return fmt.Errorf("unexpected block at height %d from the trusted rpc endpoints", from+uint64(i))
+			}
+		}
+
+		if found, err := bcStore.HasBlock(blocks[0].Header.PreviousBlockHash); err != nil || !found {
+			if rewinds >= rpcSyncMaxRewinds || from <= 1 {
+				return errRPCSyncForkTooDeep
+			}
+
+			rewinds++
+			if from > uint64(r.batchSize) {
+				from -= uint64(r.batchSize)
+			} else {
+				from = 1
+			}
+
+			continue
+		}
+
+		if err = r.importBlocks(blocks); err != nil {
+			return err
+		}
+
+		from += uint64(len(blocks))
+	}
+
+	return nil
+}
+
+// importBlocks validates and writes the blocks in order
+func (r *rpcSyncer) importBlocks(blocks []*types.Block) error {
+	prevalidated := r.chain.PrevalidateBlocks(blocks)
+
+	for i, block := range blocks {
+		if err := <-prevalidated[i]; err != nil {
+			return fmt.Errorf("invalid block %d, hash %v, %s", block.Header.Height, block.HeaderHash.Hex(), err)
+		}
+
+		if err := r.chain.WriteBlock(block, r.txPool); err != nil && !errors.IsOrContains(err, core.ErrBlockAlreadyExists) {
+			return fmt.Errorf("failed to write block %d, hash %v, %s", block.Header.Height, block.HeaderHash.Hex(), err)
+		}
+	}
+
+	r.log.Info("imported %d blocks from the trusted rpc endpoints, height %d", len(blocks), blocks[len(blocks)-1].Header.Height)
+
+	return nil
+}
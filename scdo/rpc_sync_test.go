@@ -0,0 +1,142 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/consensus"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/memorydb"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockRPCSyncChain writes the blocks to the store, and the written block becomes the HEAD
+type mockRPCSyncChain struct {
+	mockWebhookChain
+	written int
+}
+
+func (c *mockRPCSyncChain) PrevalidateBlocks(blocks []*types.Block) []<-chan error {
+	results := make([]<-chan error, len(blocks))
+	for i := range blocks {
+		ch := make(chan error, 1)
+		ch <- nil
+		results[i] = ch
+	}
+
+	return results
+}
+
+func (c *mockRPCSyncChain) WriteBlock(block *types.Block, txPool *core.Pool) error {
+	if found, _ := c.bcStore.HasBlock(block.Header.PreviousBlockHash); !found {
+		return consensus.ErrBlockInvalidParentHash
+	}
+
+	if err := c.bcStore.PutBlock(block, big.NewInt(int64(block.Header.Height+1)), true); err != nil {
+		return err
+	}
+
+	c.head = block
+	c.written++
+
+	return nil
+}
+
+// mockRPCSyncClient serves the blocks of the trusted chain
+type mockRPCSyncClient struct {
+	chain *mockWebhookChain
+}
+
+func (c *mockRPCSyncClient) GetBlockHeight(ctx context.Context, shard uint) (uint64, error) {
+	return c.chain.head.Header.Height, nil
+}
+
+func (c *mockRPCSyncClient) GetRawBlocks(ctx context.Context, shard uint, height uint64, size uint) ([]*types.Block, error) {
+	return getRawBlocks(c.chain.bcStore, height, size)
+}
+
+func (c *mockRPCSyncClient) Close() {}
+
+func newRPCSyncTestChain(t *testing.T, genesis string) (*mockWebhookChain, func()) {
+	db, dispose := memorydb.NewTestDatabase()
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	chain.head = chain.putBlock(t, nil, genesis)
+
+	return chain, dispose
+}
+
+func Test_GetRawBlocks(t *testing.T) {
+	chain, dispose := newRPCSyncTestChain(t, "")
+	defer dispose()
+
+	for i := 0; i < 100; i++ {
+		chain.head = chain.putBlock(t, chain.head, "")
+	}
+
+	blocks, err := getRawBlocks(chain.bcStore, 1, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(blocks), 10)
+	assert.Equal(t, blocks[0].Header.Height, uint64(1))
+
+	// at most maxSizeLimit blocks
+	blocks, _ = getRawBlocks(chain.bcStore, 1, 1000)
+	assert.Equal(t, len(blocks), maxSizeLimit)
+
+	// no more blocks after the HEAD
+	blocks, _ = getRawBlocks(chain.bcStore, 95, 10)
+	assert.Equal(t, len(blocks), 6)
+	assert.Equal(t, blocks[5].HeaderHash, chain.head.HeaderHash)
+
+	blocks, _ = getRawBlocks(chain.bcStore, 101, 10)
+	assert.Equal(t, len(blocks), 0)
+}
+
+func Test_RPCSyncer_Sync(t *testing.T) {
+	remote, disposeRemote := newRPCSyncTestChain(t, "")
+	defer disposeRemote()
+
+	for i := 0; i < 10; i++ {
+		remote.head = remote.putBlock(t, remote.head, "")
+	}
+
+	// the local chain forks from the trusted chain after the genesis block
+	local, disposeLocal := newRPCSyncTestChain(t, "")
+	defer disposeLocal()
+
+	chain := &mockRPCSyncChain{mockWebhookChain: *local}
+	for i := 0; i < 3; i++ {
+		chain.head = chain.putBlock(t, chain.head, "fork")
+	}
+
+	r := newRPCSyncer(chain, nil, node.RPCSyncConfig{Endpoints: []string{"127.0.0.1:8027"}, BatchSize: 2})
+	r.client = &mockRPCSyncClient{remote}
+
+	assert.Equal(t, r.sync(), nil)
+	assert.Equal(t, chain.head.HeaderHash, remote.head.HeaderHash)
+	assert.Equal(t, chain.written, 10)
+
+	// nothing to sync
+	assert.Equal(t, r.sync(), nil)
+	assert.Equal(t, chain.written, 10)
+
+	// no common ancestor with the chain of another genesis block
+	other, disposeOther := newRPCSyncTestChain(t, "other")
+	defer disposeOther()
+
+	for i := 0; i < 20; i++ {
+		other.head = other.putBlock(t, other.head, "")
+	}
+
+	r.client = &mockRPCSyncClient{other}
+	assert.Equal(t, r.sync(), errRPCSyncForkTooDeep)
+	assert.Equal(t, chain.written, 10)
+}
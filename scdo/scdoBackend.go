@@ -22,8 +22,8 @@ func NewScdoBackend(s *ScdoService) *ScdoBackend {
 	return &ScdoBackend{s}
 }
 
-// TxPoolBackend tx pool
-func (sd *ScdoBackend) TxPoolBackend() api.Pool { return sd.s.txPool }
+// TxPoolBackend tx pool, which rejects the txs submitted by rpc in the follower mode
+func (sd *ScdoBackend) TxPoolBackend() api.Pool { return &followerPool{sd.s.txPool, sd.s} }
 
 // GetNetVersion net version
 func (sd *ScdoBackend) GetNetVersion() string { return sd.s.netVersion }
@@ -53,8 +53,10 @@ func (sd *ScdoBackend) IsSyncing() bool {
 	return d.IsSyncing()
 }
 
-// ProtocolBackend return protocol
-func (sd *ScdoBackend) ProtocolBackend() api.Protocol { return sd.s.scdoProtocol }
+// ProtocolBackend return protocol, which rejects the txs of other shards submitted by rpc in the follower mode
+func (sd *ScdoBackend) ProtocolBackend() api.Protocol {
+	return &followerProtocol{sd.s.scdoProtocol, sd.s}
+}
 
 // GetBlock returns the requested block by hash or height
 func (sd *ScdoBackend) GetBlock(hash common.Hash, height int64) (*types.Block, error) {
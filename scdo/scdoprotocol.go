@@ -6,12 +6,11 @@
 package scdo
 
 import (
-	"errors"
 	"fmt"
 	"sync"
 	"time"
 
-	"github.com/scdoproject/go-scdo/common/memory"
+	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/consensus"
@@ -19,14 +18,13 @@ import (
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/event"
 	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/metrics/tracing"
+	"github.com/scdoproject/go-scdo/node"
 	"github.com/scdoproject/go-scdo/p2p"
 	downloader "github.com/scdoproject/go-scdo/scdo/download"
 )
 
-var (
-	errSyncFinished = errors.New("Sync Finished!")
-)
-
 var (
 	transactionHashMsgCode    uint16 = 0
 	transactionRequestMsgCode uint16 = 1
@@ -40,9 +38,35 @@ var (
 
 	debtMsgCode uint16 = 13
 
-	protocolMsgCodeLength uint16 = 14
+	nonceReservationMsgCode uint16 = 14
+
+	compactBlockMsgCode    uint16 = 15
+	blockTxsRequestMsgCode uint16 = 16
+	blockTxsMsgCode        uint16 = 17
+
+	debtAckMsgCode uint16 = 18
+
+	forkIDMsgCode uint16 = 19
+
+	protocolMsgCodeLength uint16 = 20
 )
 
+// statusProtocolVersion is the protocol version sent in the status handshake. The p2p capability version
+// is kept as common.ScdoVersion to connect with the legacy peers, which send common.ScdoVersion in the
+// status and disconnect on the message codes they do not know.
+const statusProtocolVersion uint32 = 3
+
+// msgCodeVersions is the least status protocol version of the peers handling the message code,
+// the codes not listed are handled by all peers.
+var msgCodeVersions = map[uint16]uint32{
+	nonceReservationMsgCode: 2,
+	compactBlockMsgCode:     2,
+	blockTxsRequestMsgCode:  2,
+	blockTxsMsgCode:         2,
+	debtAckMsgCode:          2,
+	forkIDMsgCode:           3,
+}
+
 func codeToStr(code uint16) string {
 	switch code {
 	case transactionHashMsgCode:
@@ -63,6 +87,18 @@ func codeToStr(code uint16) string {
 		return "statusChainHeadMsgCode"
 	case debtMsgCode:
 		return "debtMsgCode"
+	case nonceReservationMsgCode:
+		return "nonceReservationMsgCode"
+	case compactBlockMsgCode:
+		return "compactBlockMsgCode"
+	case blockTxsRequestMsgCode:
+		return "blockTxsRequestMsgCode"
+	case blockTxsMsgCode:
+		return "blockTxsMsgCode"
+	case debtAckMsgCode:
+		return "debtAckMsgCode"
+	case forkIDMsgCode:
+		return "forkIDMsgCode"
 	}
 
 	return downloader.CodeToStr(code)
@@ -85,6 +121,19 @@ type ScdoProtocol struct {
 	log    *log.ScdoLog
 
 	debtManager *DebtManager
+
+	nonceReservations *nonceReservations
+
+	pendingCompactBlocks *lru.Cache // compact blocks waiting for the missing txs
+
+	shardHeads *shardHeads // best-known chain heads of all shards
+
+	txSyncConfig node.TxSyncConfig // sync the pending txs to the new peers of the local shard
+
+	peerKnownCacheConfig node.PeerKnownCacheConfig // sizes and expiry of the known caches of the peers
+
+	headLock sync.Mutex
+	lastHead common.Hash // last chain head, to find the blocks reverted by a reorg
 }
 
 // Downloader return a pointer of the downloader
@@ -94,9 +143,10 @@ func (s *ScdoProtocol) Downloader() *downloader.Downloader { return s.downloader
 func NewScdoProtocol(scdo *ScdoService, log *log.ScdoLog) (s *ScdoProtocol, err error) {
 	s = &ScdoProtocol{
 		Protocol: p2p.Protocol{
-			Name:    common.ScdoProtoName,
-			Version: common.ScdoVersion,
-			Length:  protocolMsgCodeLength,
+			Name:     common.ScdoProtoName,
+			Version:  common.ScdoVersion,
+			Length:   protocolMsgCodeLength,
+			CodeName: codeToStr,
 		},
 		networkID:  scdo.networkID,
 		txPool:     scdo.TxPool(),
@@ -107,7 +157,20 @@ func NewScdoProtocol(scdo *ScdoService, log *log.ScdoLog) (s *ScdoProtocol, err
 		quitCh:     make(chan struct{}),
 		syncCh:     make(chan struct{}),
 
-		peerSet: newPeerSet(),
+		peerSet:              newPeerSet(),
+		nonceReservations:    newNonceReservations(),
+		pendingCompactBlocks: common.MustNewCache(maxPendingCompactBlocks),
+		shardHeads:           newShardHeads(),
+		txSyncConfig:         scdo.txSyncConfig,
+		peerKnownCacheConfig: scdo.peerKnownCacheConfig,
+	}
+
+	if current := s.chain.CurrentBlock(); current != nil {
+		s.lastHead = current.HeaderHash
+	}
+
+	if s.txSyncConfig.PackSize <= 0 {
+		s.txSyncConfig.PackSize = defaultTxSyncPackSize
 	}
 
 	s.Protocol.AddPeer = s.handleAddPeer
@@ -160,7 +223,7 @@ func (sp *ScdoProtocol) syncer() {
 				continue
 			}
 			sp.wg.Add(1)
-			go sp.synchronise(sp.peerSet.bestPeers(common.LocalShardNumber, localTD))
+			go sp.synchronise(sp.peerSet.bestPeers(common.LocalShardNumber, localTD, sp.downloader.PeerHealth))
 		case <-forceSync.C:
 			if !sp.downloader.IsSyncStatusNone() {
 				continue
@@ -173,7 +236,7 @@ func (sp *ScdoProtocol) syncer() {
 				continue
 			}
 			sp.wg.Add(1)
-			go sp.synchronise(sp.peerSet.bestPeers(common.LocalShardNumber, localTD))
+			go sp.synchronise(sp.peerSet.bestPeers(common.LocalShardNumber, localTD, sp.downloader.PeerHealth))
 		case <-sp.quitCh:
 			return
 		}
@@ -182,9 +245,8 @@ func (sp *ScdoProtocol) syncer() {
 
 func (sp *ScdoProtocol) synchronise(peers []*peer) {
 	defer sp.wg.Done()
-	now := time.Now()
-	// entrance
-	memory.Print(sp.log, "ScdoProtocol synchronise entrance", now, false)
+	span := tracing.StartSpan("scdo.synchronise")
+	defer span.End()
 
 	if len(peers) == 0 {
 		return
@@ -217,8 +279,7 @@ func (sp *ScdoProtocol) synchronise(peers []*peer) {
 				sp.log.Debug("synchronise err. %s", err)
 			}
 
-			// three step
-			memory.Print(sp.log, "ScdoProtocol synchronise downloader error", now, true)
+			span.SetError(err)
 
 			continue
 		}
@@ -226,18 +287,14 @@ func (sp *ScdoProtocol) synchronise(peers []*peer) {
 		//broadcast chain head
 		sp.broadcastChainHead()
 
-		// exit
-		memory.Print(sp.log, "ScdoProtocol synchronise exit", now, true)
-
 		return
 	}
 }
 
 func (sp *ScdoProtocol) broadcastChainHead() {
 
-	now := time.Now()
-	// entrance
-	memory.Print(sp.log, "ScdoProtocol broadcastChainHead entrance", now, false)
+	span := tracing.StartSpan("scdo.broadcastChainHead")
+	defer span.End()
 
 	block := sp.chain.CurrentBlock()
 	head := block.HeaderHash
@@ -269,80 +326,128 @@ func (sp *ScdoProtocol) broadcastChainHead() {
 		}
 	}
 	wg.Wait()
-	// exit
-	memory.Print(sp.log, "ScdoProtocol broadcastChainHead exit", now, true)
 }
 
-// syncTransactions sends pending transactions to remote peer.
+// syncTransactions sends the pending txs in the pool which are unknown to the new peer, in packs
+// of the configured size and at most the configured rate.
 func (sp *ScdoProtocol) syncTransactions(p *peer) {
 	defer sp.wg.Done()
-	sp.wg.Add(1)
-	pending := sp.txPool.GetTransactions(false, true)
 
+	pending := sp.txPool.GetTransactions(false, true)
 	sp.log.Debug("syncTransactions peerid:%s pending length:%d", p.peerStrID, len(pending))
-	if len(pending) == 0 {
-		return
+
+	synced := syncTxsToPeer(p, pending, sp.txSyncConfig, sp.quitCh)
+	metrics.MetricsTxSyncMeter.Mark(int64(synced))
+	metrics.MetricsTxSyncPeerHistogram.Update(int64(synced))
+
+	sp.log.Debug("syncTransactions peerid:%s synced:%d", p.peerStrID, synced)
+}
+
+// syncTxsToPeer sends the txs unknown to the peer in packs, and waits between the packs to limit the
+// rate if configured. Returns the number of the txs sent before failed or quit.
+func syncTxsToPeer(p *peer, txs []*types.Transaction, conf node.TxSyncConfig, quit <-chan struct{}) int {
+	var interval time.Duration
+	if conf.Rate > 0 {
+		interval = time.Duration(conf.PackSize) * time.Second / time.Duration(conf.Rate)
 	}
-	var (
-		resultCh = make(chan error, 1)
-		curPos   = 0
-	)
 
-	send := func(pos int) {
-		// sends txs from pos
-		needSend := len(pending) - pos
-		if needSend > txsyncPackSize {
-			needSend = txsyncPackSize
+	synced := 0
+	pack := make([]*types.Transaction, 0, conf.PackSize)
+	for i, tx := range txs {
+		if !p.isKnownTx(tx.Hash) {
+			pack = append(pack, tx)
 		}
 
-		if needSend == 0 {
-			resultCh <- errSyncFinished
-			return
+		if len(pack) < conf.PackSize && i < len(txs)-1 {
+			continue
 		}
-		curPos = curPos + needSend
-		go func() { resultCh <- p.sendTransactions(pending[pos : pos+needSend]) }()
-	}
 
-	send(curPos)
-loopOut:
-	for {
-		select {
-		case err := <-resultCh:
-			if err == errSyncFinished || err != nil {
-				break loopOut
+		if len(pack) == 0 {
+			break
+		}
+
+		if err := p.sendTransactions(pack); err != nil {
+			p.log.Debug("failed to sync txs to peer %s, %s", p.peerStrID, err)
+			break
+		}
+
+		for _, sent := range pack {
+			p.markKnownTx(sent.Hash)
+		}
+		synced += len(pack)
+		p.addSyncedTxs(len(pack))
+		pack = pack[:0]
+
+		if interval > 0 && i < len(txs)-1 {
+			select {
+			case <-time.After(interval):
+			case <-quit:
+				return synced
 			}
-			send(curPos)
-		case <-sp.quitCh:
-			break loopOut
 		}
 	}
-	close(resultCh)
+
+	return synced
 }
 
 func (p *ScdoProtocol) handleNewTx(e event.Event) {
-	now := time.Now()
-	// entrance
-	memory.Print(p.log, "ScdoProtocol handleNewTx entrance", now, false)
+	span := tracing.StartSpan("scdo.handleNewTx")
+	defer span.End()
 
 	tx := e.(*types.Transaction)
 
 	// find shardId by tx from address.
 	shardId := tx.Data.From.Shard()
-	peers := p.peerSet.getPeerByShard(shardId)
-	for _, peer := range peers {
-		if peer.knownTxs.Contains(tx.Hash) {
+	var peers []*peer
+	for _, peer := range p.peerSet.getPeerByShard(shardId) {
+		if peer.isKnownTx(tx.Hash) {
 			p.log.Debug("scdoprotocol handleNewTx: peer: %s already contains tx %s", peer.peerStrID, tx.Hash.String())
 			continue
 		}
 
-		if err := peer.sendTransaction(tx); err != nil {
-			p.log.Warn("failed to send transaction to peer=%s, err=%s", peer.Node.GetUDPAddr(), err)
-			peer.Disconnect(err.Error())
+		peers = append(peers, peer)
+	}
+
+	// the peers of other shards only accept the full txs
+	if shardId != common.LocalShardNumber {
+		for _, peer := range peers {
+			peer.queueTransactions([]*types.Transaction{tx})
+		}
+	} else {
+		push, announce := txPushPeers(peers)
+		for _, peer := range push {
+			peer.queueTransactions([]*types.Transaction{tx})
+		}
+
+		for _, peer := range announce {
+			peer.queueTxHashes([]common.Hash{tx.Hash})
 		}
 	}
 
-	//exit
-	memory.Print(p.log, "ScdoProtocol handleNewTx exit", now, true)
+	if tx.IsCrossShardTx() {
+		p.propagateNonceReservation(tx)
+	}
+}
+
+// propagateNonceReservation gossips the account nonce used by the cross-shard tx to the peers of other shards,
+// so that a conflicting tx submitted to other shard nodes could be rejected early.
+func (p *ScdoProtocol) propagateNonceReservation(tx *types.Transaction) {
+	reservation := &nonceReservation{Tx: tx}
+
+	for _, peer := range p.peerSet.getAllPeers() {
+		if peer.Node.Shard == common.LocalShardNumber || !peer.supportsMsg(nonceReservationMsgCode) {
+			continue
+		}
+
+		if err := peer.sendNonceReservation(reservation); err != nil {
+			p.log.Debug("failed to send nonce reservation to peer=%s, err=%s", peer.peerStrID, err)
+		}
+	}
+}
+
+// CheckNonceReservation returns an error if the tx of other shard conflicts with a pending tx reserved in its shard
+func (p *ScdoProtocol) CheckNonceReservation(tx *types.Transaction) error {
+	return p.nonceReservations.check(tx)
 }
 
 func (p *ScdoProtocol) handleNewDebt(e event.Event) {
@@ -351,30 +456,46 @@ func (p *ScdoProtocol) handleNewDebt(e event.Event) {
 }
 
 func (p *ScdoProtocol) propagateDebtMap(debtsMap [][]*types.Debt, filter bool) {
-	now := time.Now()
-	// entrance
-	memory.Print(p.log, "ScdoProtocol propagateDebtMap entrance", now, false)
+	span := tracing.StartSpan("scdo.propagateDebtMap")
+	defer span.End()
 
-	//peers := p.peerSet.getAllPeers()
-	wg := new(sync.WaitGroup)
 	peers := p.peerSet.getPropagatePeers()
 	for _, peer := range peers {
 		if len(debtsMap[peer.Node.Shard]) > 0 {
-			wg.Add(1)
-			go peer.sendDebts(debtsMap[peer.Node.Shard], filter)
-			//err := peer.sendDebts(debtsMap[peer.Node.Shard], filter)
-			//if err != nil {
-			//	p.log.Warn("failed to send debts to peer=%s, err=%s", peer.Node, err)
-			//}
+			peer.queueDebts(debtsMap[peer.Node.Shard], filter)
 		}
 	}
-	wg.Wait()
-	// exit
-	memory.Print(p.log, "ScdoProtocol propagateDebtMap exit", now, true)
+}
+
+// handleDebts adds the debts received from the peer into the debt pool, and acknowledges the debts of the
+// local shard accepted into the pool to the peer of the source shard, so that it stops resending them.
+func (p *ScdoProtocol) handleDebts(peer *peer, debts []*types.Debt) {
+	var accepted []common.Hash
+	for _, d := range debts {
+		if d == nil || p.debtPool.AddDebt(d) != nil {
+			continue
+		}
+
+		if d.Data.Account.Shard() == common.LocalShardNumber {
+			accepted = append(accepted, d.Hash)
+		}
+	}
+
+	p.log.Debug("add %d debts, %d accepted", len(debts), len(accepted))
+
+	// the peers not handling the ack keep resending the debts until they are packed
+	if len(accepted) == 0 || peer.Node.Shard == common.LocalShardNumber || !peer.supportsMsg(debtAckMsgCode) {
+		return
+	}
+
+	if err := peer.sendDebtAck(accepted); err != nil {
+		p.log.Debug("failed to send debt ack to peer=%s, err=%s", peer.peerStrID, err)
+	}
 }
 
 func (p *ScdoProtocol) handleNewBlock(e event.Event) {
 	block := e.(*types.Block)
+	p.forgetRevertedBlocks(block)
 
 	// propagate confirmed block
 	if block.Header.Height > common.ConfirmedBlockNumber {
@@ -388,9 +509,8 @@ func (p *ScdoProtocol) handleNewBlock(e event.Event) {
 				p.log.Warn("failed to load confirmed block height %d, err %s", confirmedHeight, err)
 			}
 		} else {
-			now := time.Now()
-			// entrance
-			memory.Print(p.log, "ScdoProtocol handleNewBlock entrance", now, false)
+			span := tracing.StartSpan("scdo.handleNewBlock")
+			defer span.End()
 
 			debts := types.NewDebtMap(confirmedBlock.Transactions)
 			size := 0
@@ -403,25 +523,62 @@ func (p *ScdoProtocol) handleNewBlock(e event.Event) {
 				go p.propagateDebtMap(debts, true)
 			}
 
-			// exit
-			memory.Print(p.log, "ScdoProtocol handleNewBlock exit", now, true)
+		}
+	}
+}
+
+// forgetRevertedBlocks removes the blocks reverted by a chain reorg and their txs from the known caches
+// of the peers, so that they could be relayed again if they are included in the new canonical chain.
+func (p *ScdoProtocol) forgetRevertedBlocks(head *types.Block) {
+	p.headLock.Lock()
+	oldHead := p.lastHead
+	p.lastHead = head.HeaderHash
+	p.headLock.Unlock()
+
+	if oldHead.IsEmpty() || oldHead == head.HeaderHash || oldHead == head.Header.PreviousBlockHash {
+		return
+	}
+
+	// the canonical height-to-hash mapping is updated before the chain header changed event
+	store := p.chain.GetStore()
+	var reverted []*types.Block
+	for hash := oldHead; len(reverted) < maxKnownBlocks; {
+		block, err := store.GetBlock(hash)
+		if err != nil {
+			break
+		}
+
+		if canonical, err := store.GetBlockHash(block.Header.Height); err == nil && canonical == hash {
+			break
+		}
+
+		reverted = append(reverted, block)
+		hash = block.Header.PreviousBlockHash
+	}
+
+	if len(reverted) == 0 {
+		return
+	}
+
+	p.log.Debug("forget %d reverted blocks from the known caches of peers, old head %v, new head %v", len(reverted), oldHead.Hex(), head.HeaderHash.Hex())
+	for _, peer := range p.peerSet.getAllPeers() {
+		for _, block := range reverted {
+			peer.forgetBlock(block)
 		}
 	}
 }
 
 func (p *ScdoProtocol) handleNewMinedBlock(e event.Event) {
-	now := time.Now()
-	// entrance
-	memory.Print(p.log, "ScdoProtocol handleNewMinedBlock entrance", now, false)
+	span := tracing.StartSpan("scdo.handleNewMinedBlock")
+	defer span.End()
 	block := e.(*types.Block)
 
 	p.log.Debug("handleNewMinedBlock broadcast chainhead changed. new block: %d %s <- %s ",
 		block.Header.Height, block.HeaderHash.Hex(), block.Header.PreviousBlockHash.Hex())
 
+	p.propagateCompactBlock(block)
 	p.broadcastChainHead()
 
-	// exit
-	memory.Print(p.log, "ScdoProtocol handleNewMinedBlock exit", now, true)
 }
 
 func (p *ScdoProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
@@ -430,7 +587,7 @@ func (p *ScdoProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bo
 		return false
 	}
 
-	newPeer := newPeer(common.ScdoVersion, p2pPeer, rw, p.log)
+	newPeer := newPeer(common.ScdoVersion, p2pPeer, rw, p.log, p.peerKnownCacheConfig)
 
 	block := p.chain.CurrentBlock()
 	head := block.HeaderHash
@@ -444,19 +601,26 @@ func (p *ScdoProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bo
 		return false
 	}
 
-	if err := newPeer.handShake(p.networkID, localTD, head, genesisBlock.HeaderHash, genesisBlock.Header.Difficulty.Uint64()); err != nil {
+	if err := newPeer.handShake(p.networkID, localTD, head, genesisBlock.HeaderHash, genesisBlock.Header.Difficulty.Uint64(),
+		p.chain.Config(), block.Header.Height); err != nil {
 		p.log.Debug("handleAddPeer err. %s", err)
 		newPeer.Disconnect(DiscHandShakeErr)
 		return false
 	}
 
 	p.log.Debug("add peer %s -> %s to ScdoProtocol. nodeid=%s", p2pPeer.LocalAddr(), p2pPeer.RemoteAddr(), newPeer.peerStrID)
+	peerHead, peerTD := newPeer.Head()
+	p.shardHeads.update(newPeer.Node.Shard, newPeer.peerStrID, peerHead, peerTD, time.Now())
+	newPeer.useSeenTxs(p.txPool.SeenTxs())
 	p.peerSet.Add(newPeer)
+	go newPeer.broadcast()
 	if newPeer.Node.Shard == common.LocalShardNumber {
 		p.downloader.RegisterPeer(newPeer.peerStrID, newPeer)
-
 	}
-	//go p.syncTransactions(newPeer)
+	if newPeer.Node.Shard == common.LocalShardNumber && p.txSyncConfig.Enabled {
+		p.wg.Add(1)
+		go p.syncTransactions(newPeer)
+	}
 	go p.handleMsg(newPeer)
 	return true
 }
@@ -470,7 +634,12 @@ func (s *ScdoProtocol) handleGetPeer(address common.Address) interface{} {
 
 func (s *ScdoProtocol) handleDelPeer(peer *p2p.Peer) {
 	s.log.Debug("delete peer from peer set. %s", peer.Node)
+	if p := s.peerSet.Find(peer.Node.ID); p != nil {
+		p.stopBroadcast()
+		p.releaseSeenTxs()
+	}
 	s.peerSet.Remove(peer.Node.ID)
+	s.nonceReservations.removePeer(idToStr(peer.Node.ID))
 
 	if peer.Node.Shard == common.LocalShardNumber {
 		s.downloader.UnRegisterPeer(idToStr(peer.Node.ID))
@@ -487,15 +656,7 @@ func (p *ScdoProtocol) SendDifferentShardTx(tx *types.Transaction, shard uint) {
 	}
 
 	for _, peerinfo := range peers {
-		if !peerinfo.knownTxs.Contains(tx.Hash) {
-			err := peerinfo.sendTransaction(tx)
-			if err != nil {
-				p.log.Warn("failed to send transaction to peer=%s, tx hash=%s, err=%s", peerinfo.Node, tx.Hash, err)
-				continue
-			}
-
-			peerinfo.knownTxs.Add(tx.Hash, nil)
-		}
+		peerinfo.queueTransactions([]*types.Transaction{tx})
 	}
 }
 
@@ -511,7 +672,7 @@ handler:
 
 		// skip unsupported message from different shard peer
 		if peer.Node.Shard != common.LocalShardNumber {
-			if msg.Code != transactionsMsgCode && msg.Code != debtMsgCode && msg.Code != statusChainHeadMsgCode {
+			if msg.Code != transactionsMsgCode && msg.Code != debtMsgCode && msg.Code != debtAckMsgCode && msg.Code != statusChainHeadMsgCode && msg.Code != nonceReservationMsgCode {
 				continue
 			}
 		}
@@ -519,13 +680,9 @@ handler:
 		// print transaction and debt pool length
 		p.log.Debug("handleMsg tx pool and debt pool length, tx %d, debt %d", p.txPool.GetTxCount(), p.debtPool.GetDebtCount(true, true))
 
-		// set time now
-		now := time.Now()
-
 		switch msg.Code {
 		case transactionHashMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg transactionHashMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.transactionHash", tracing.String("peer", peer.peerStrID))
 
 			var txHash common.Hash
 			err := common.Deserialize(msg.Payload, &txHash)
@@ -534,9 +691,14 @@ handler:
 				continue
 			}
 
-			if !peer.knownTxs.Contains(txHash) {
+			if !peer.isKnownTx(txHash) {
 				//update peer known transaction
-				peer.knownTxs.Add(txHash, nil)
+				peer.markKnownTx(txHash)
+
+				// skip the request if the tx is already in pool or recent blocks
+				if p.txPool.SeenTxs().Cached(txHash) {
+					continue
+				}
 
 				if err := peer.sendTransactionRequest(txHash); err != nil {
 					p.log.Warn("failed to send transaction request msg to peer=%s, err=%s", peer.RemoteAddr().String(), err.Error())
@@ -546,12 +708,10 @@ handler:
 
 			}
 
-			// exit
-			memory.Print(p.log, "handleMsg transactionHashMsgCode exit", now, true)
+			span.End()
 
 		case transactionRequestMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg transactionRequestMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.transactionRequest", tracing.String("peer", peer.peerStrID))
 
 			var txHash common.Hash
 			err := common.Deserialize(msg.Payload, &txHash)
@@ -573,12 +733,10 @@ handler:
 				continue
 			}
 
-			// exit
-			memory.Print(p.log, "handleMsg transactionRequestMsgCode exit", now, true)
+			span.End()
 
 		case transactionsMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg transactionsMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.transactions", tracing.String("peer", peer.peerStrID))
 
 			var txs []*types.Transaction
 			err := common.Deserialize(msg.Payload, &txs)
@@ -589,7 +747,7 @@ handler:
 
 			go func() {
 				for _, tx := range txs {
-					peer.knownTxs.Add(tx.Hash, nil)
+					peer.markKnownTx(tx.Hash)
 					shard := tx.Data.From.Shard()
 					if shard != common.LocalShardNumber {
 						p.SendDifferentShardTx(tx, shard)
@@ -600,11 +758,10 @@ handler:
 				}
 			}()
 
-			memory.Print(p.log, "handleMsg transactionsMsgCode exit", now, true)
+			span.End()
 
 		case blockHashMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg blockHashMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.blockHash", tracing.String("peer", peer.peerStrID))
 
 			var blockHash common.Hash
 			err := common.Deserialize(msg.Payload, &blockHash)
@@ -616,7 +773,7 @@ handler:
 			p.log.Debug("got block hash msg %s", blockHash.Hex())
 
 			if !peer.knownBlocks.Contains(blockHash) {
-				peer.knownBlocks.Add(blockHash, nil)
+				peer.knownBlocks.Add(blockHash)
 
 				err := peer.SendBlockRequest(blockHash)
 				if err != nil {
@@ -625,12 +782,10 @@ handler:
 				}
 			}
 
-			//exit
-			memory.Print(p.log, "handleMsg blockHashMsgCode exit", now, true)
+			span.End()
 
 		case blockRequestMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg blockRequestMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.blockRequest", tracing.String("peer", peer.peerStrID))
 
 			var blockHash common.Hash
 			err := common.Deserialize(msg.Payload, &blockHash)
@@ -651,12 +806,10 @@ handler:
 			//p.log.Warn("failed to send block msg to peer=%s, err=%s", peer.RemoteAddr().String(), err.Error())
 			//}
 
-			// exit
-			memory.Print(p.log, "handleMsg blockRequestMsgCode exit", now, true)
+			span.End()
 
 		case blockMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg blockMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.block", tracing.String("peer", peer.peerStrID))
 
 			var block types.Block
 			err := common.Deserialize(msg.Payload, &block)
@@ -666,18 +819,16 @@ handler:
 			}
 
 			p.log.Info("got block message and save it. height:%d, hash:%s, time: %d", block.Header.Height, block.HeaderHash.Hex(), time.Now().UnixNano())
-			peer.knownBlocks.Add(block.HeaderHash, nil)
+			peer.knownBlocks.Add(block.HeaderHash)
 			if block.GetShardNumber() == common.LocalShardNumber {
 				// @todo need to make sure WriteBlock handle block fork
 				go p.chain.WriteBlock(&block, p.txPool.Pool)
 			}
 
-			// exit
-			memory.Print(p.log, "handleMsg blockMsgCode exit", now, true)
+			span.End()
 
 		case debtMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg debtMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.debt", tracing.String("peer", peer.peerStrID))
 
 			var debts []*types.Debt
 			err := common.Deserialize(msg.Payload, &debts)
@@ -688,17 +839,69 @@ handler:
 
 			p.log.Debug("got %d debts message [%s]", len(debts), codeToStr(msg.Code))
 			for _, d := range debts {
-				peer.knownDebts.Add(d.Hash, nil)
+				peer.knownDebts.Add(d.Hash)
 			}
 
-			go p.debtPool.AddDebtArray(debts)
+			go p.handleDebts(peer, debts)
+
+			span.End()
+
+		case debtAckMsgCode:
+			var hashes []common.Hash
+			if err := common.Deserialize(msg.Payload, &hashes); err != nil {
+				p.log.Warn("failed to deserialize debt ack msg %s", err)
+				continue
+			}
+
+			acked := p.debtManager.Ack(peer.Node.Shard, hashes)
+			p.log.Debug("got %d debt acks from peer %s of shard %d, %d acknowledged", len(hashes), peer.peerStrID, peer.Node.Shard, acked)
+
+		case nonceReservationMsgCode:
+			var reservation nonceReservation
+			if err := common.Deserialize(msg.Payload, &reservation); err != nil {
+				p.log.Warn("failed to deserialize nonce reservation msg %s", err)
+				continue
+			}
+
+			if err := reservation.validate(); err != nil {
+				p.log.Warn("invalid nonce reservation from peer %s, %s", peer.peerStrID, err)
+				continue
+			}
+
+			// the local tx pool is authoritative for the accounts of local shard
+			if reservation.Tx.Data.From.Shard() != common.LocalShardNumber {
+				p.nonceReservations.add(peer.peerStrID, reservation.Tx)
+			}
+
+		case compactBlockMsgCode:
+			var cb compactBlock
+			if err := common.Deserialize(msg.Payload, &cb); err != nil {
+				p.log.Warn("failed to deserialize compact block msg %s", err)
+				continue
+			}
+
+			go p.handleCompactBlock(peer, &cb)
+
+		case blockTxsRequestMsgCode:
+			var request blockTxsRequest
+			if err := common.Deserialize(msg.Payload, &request); err != nil {
+				p.log.Warn("failed to deserialize block txs request msg %s", err)
+				continue
+			}
+
+			go p.handleBlockTxsRequest(peer, &request)
+
+		case blockTxsMsgCode:
+			var response blockTxsResponse
+			if err := common.Deserialize(msg.Payload, &response); err != nil {
+				p.log.Warn("failed to deserialize block txs msg %s", err)
+				continue
+			}
 
-			//exit
-			memory.Print(p.log, "handleMsg debtMsgCode exit", now, true)
+			go p.handleBlockTxs(peer, &response)
 
 		case downloader.GetBlockHeadersMsg:
-			//entrance
-			memory.Print(p.log, "handleMsg downloader.GetBlockHeadersMsg entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.downloader.GetBlockHeaders", tracing.String("peer", peer.peerStrID))
 
 			var query blockHeadersQuery
 			err := common.Deserialize(msg.Payload, &query)
@@ -745,12 +948,10 @@ handler:
 
 			go peer.sendBlockHeaders(query.Magic, headList)
 
-			// exit
-			memory.Print(p.log, "handleMsg downloader.GetBlockHeadersMsg exit", now, true)
+			span.End()
 
 		case downloader.GetBlocksMsg:
-			// entrance
-			memory.Print(p.log, "handleMsg downloader.GetBlocksMsg entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.downloader.GetBlocks", tracing.String("peer", peer.peerStrID))
 
 			p.log.Debug("Received downloader.GetBlocksMsg")
 			var query blocksQuery
@@ -806,22 +1007,18 @@ handler:
 
 			go peer.sendBlocks(query.Magic, blocksL)
 
-			// exit
-			memory.Print(p.log, "handleMsg downloader.GetBlocksMsg exit", now, true)
+			span.End()
 
 		case downloader.BlockHeadersMsg, downloader.BlocksPreMsg, downloader.BlocksMsg:
-			// entrance
-			memory.Print(p.log, "handleMsg downloader.BlockHeadersMsg, downloader.BlocksPreMsg, downloader.BlocksMsg entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.downloader", tracing.String("peer", peer.peerStrID))
 
 			p.log.Debug("Received downloader Msg. %s peerid:%s", codeToStr(msg.Code), peer.peerStrID)
 			go p.downloader.DeliverMsg(peer.peerStrID, msg)
 
-			// exit
-			memory.Print(p.log, "handleMsg downloader.BlockHeadersMsg, downloader.BlocksPreMsg, downloader.BlocksMsg exit", now, true)
+			span.End()
 
 		case statusChainHeadMsgCode:
-			// entrance
-			memory.Print(p.log, "handleMsg statusChainHeadMsgCode entrance", now, false)
+			span := tracing.StartSpan("scdo.handleMsg.statusChainHead", tracing.String("peer", peer.peerStrID))
 
 			var status chainHeadStatus
 			err := common.Deserialize(msg.Payload, &status)
@@ -833,10 +1030,10 @@ handler:
 
 			p.log.Debug("Received statusChainHeadMsgCode. peer=%s, ip=%s, remoteTD=%d", peer.peerStrID, peer.Peer.RemoteAddr(), status.TD)
 			peer.SetHead(status.CurrentBlock, status.TD)
+			p.shardHeads.update(peer.Node.Shard, peer.peerStrID, status.CurrentBlock, status.TD, time.Now())
 			p.syncCh <- struct{}{}
 
-			// exit
-			memory.Print(p.log, "handleMsg statusChainHeadMsgCode exit", now, true)
+			span.End()
 
 		default:
 			p.log.Warn("unknown code %d", msg.Code)
@@ -9,15 +9,20 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/scdoproject/go-scdo/api"
 	"github.com/scdoproject/go-scdo/common"
 	"github.com/scdoproject/go-scdo/consensus"
 	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/state"
 	"github.com/scdoproject/go-scdo/core/store"
 	"github.com/scdoproject/go-scdo/core/types"
 	"github.com/scdoproject/go-scdo/database"
 	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/database/memorydb"
 	"github.com/scdoproject/go-scdo/event"
 	"github.com/scdoproject/go-scdo/log"
 	"github.com/scdoproject/go-scdo/miner"
@@ -26,6 +31,8 @@ import (
 	"github.com/scdoproject/go-scdo/p2p/discovery"
 	"github.com/scdoproject/go-scdo/rpc"
 	downloader "github.com/scdoproject/go-scdo/scdo/download"
+	"github.com/scdoproject/go-scdo/scdo/snapshot"
+	"github.com/scdoproject/go-scdo/trie"
 )
 
 const chainHeaderChangeBuffSize = 100
@@ -40,9 +47,11 @@ type ScdoService struct {
 	scdoProtocol *ScdoProtocol
 	log          *log.ScdoLog
 
+	genesis            *core.Genesis
 	txPool             *core.TransactionPool
 	debtPool           *core.DebtPool
 	chain              *core.Blockchain
+	dbEngine           string            // engine of the databases, leveldb or memory.
 	chainDB            database.Database // database used to store blocks.
 	chainDBPath        string
 	accountStateDB     database.Database // database used to store account state info.
@@ -55,6 +64,43 @@ type ScdoService struct {
 	chainHeaderChangeChannel chan common.Hash
 
 	debtVerifier types.DebtVerifier
+
+	rpcConfig     node.RPCConfig // execution limits of rpc calls
+	rpcConfigLock sync.RWMutex
+
+	watchdogConfig node.WatchdogConfig
+	watchdog       *watchdog
+
+	webhookConfig node.WebhookConfig
+	webhooks      *webhookDispatcher
+
+	diskQuotaConfig node.DiskQuotaConfig
+	diskQuota       *diskQuota
+
+	retentionConfig node.RetentionConfig
+	retention       *retentionPruner
+
+	rpcSyncConfig node.RPCSyncConfig
+	rpcSyncer     *rpcSyncer
+
+	shardRPC *shardRPCClient // nil if no rpc endpoints of other shards configured
+
+	txSyncConfig node.TxSyncConfig
+
+	peerKnownCacheConfig node.PeerKnownCacheConfig
+
+	leaseMiningConfig node.LeaseMiningConfig
+	leaseMiner        *leaseMiner
+
+	follower int32 // 1 if the node is a warm standby not promoted to active yet
+
+	backups *backupScheduler
+
+	storageWatcher *storageWatcher
+	balanceWatcher *balanceWatcher
+	blockFirehose  *blockFirehose
+
+	orphanTracker *orphanTracker
 }
 
 // ServiceContext is a collection of service configuration inherited from node
@@ -94,38 +140,106 @@ func (s *ScdoService) P2PServer() *p2p.Server { return s.p2pServer }
 // NewScdoService create ScdoService
 func NewScdoService(ctx context.Context, conf *node.Config, log *log.ScdoLog, engine consensus.Engine, verifier types.DebtVerifier, startHeight int, isPoolMode bool) (s *ScdoService, err error) {
 	s = &ScdoService{
-		log:          log,
-		networkID:    conf.P2PConfig.NetworkID,
-		netVersion:   conf.BasicConfig.Version,
-		debtVerifier: verifier,
+		log:               log,
+		networkID:         conf.P2PConfig.NetworkID,
+		netVersion:        conf.BasicConfig.Version,
+		dbEngine:          conf.BasicConfig.DBEngine,
+		debtVerifier:      verifier,
+		rpcConfig:         conf.RPCConfig,
+		watchdogConfig:    conf.WatchdogConfig,
+		webhookConfig:     conf.WebhookConfig,
+		diskQuotaConfig:   conf.DiskQuotaConfig,
+		retentionConfig:   conf.RetentionConfig,
+		rpcSyncConfig:     conf.RPCSyncConfig,
+		txSyncConfig:      conf.TxSyncConfig,
+		leaseMiningConfig: conf.LeaseMiningConfig,
+
+		peerKnownCacheConfig: conf.PeerKnownCacheConfig,
+	}
+
+	if len(conf.ShardRPCConfig.Endpoints) > 0 {
+		s.shardRPC = newShardRPCClient(conf.ShardRPCConfig)
+	}
+
+	if s.leaseMiningConfig.Worker == "" {
+		s.leaseMiningConfig.Worker = conf.BasicConfig.Name
 	}
 
 	serviceContext := ctx.Value("ServiceContext").(ServiceContext)
 
+	dirs, err := ResolveDataDirs(serviceContext.DataDir, conf.DataDirsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bootstrap from snapshot archive if configured, the remainder is synced from p2p.
+	if s.dbEngine == database.EngineMemory {
+		log.Info("chain data is kept in memory, and lost once the node stops")
+	} else if err = snapshot.Bootstrap(conf.SnapshotConfig, serviceContext.DataDir, BlockChainDir, dirs.ChainDB); err != nil {
+		log.Warn("failed to bootstrap from snapshot, sync from p2p instead, %s", err)
+	}
+
+	// Move the databases under the data dir to the configured paths, e.g. the ones bootstrapped from snapshot.
+	if s.dbEngine != database.EngineMemory {
+		migrated, err := MigrateDataDirs(serviceContext.DataDir, dirs)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(migrated) > 0 {
+			log.Info("moved the %s to the configured data dirs", strings.Join(migrated, ", "))
+		}
+	}
+
 	// Initialize blockchain DB.
-	if err = s.initBlockchainDB(&serviceContext); err != nil {
+	if err = s.initBlockchainDB(dirs.ChainDB); err != nil {
 		return nil, err
 	}
 
-	leveldb.StartMetrics(s.chainDB, "chaindb", log)
+	if s.dbEngine != database.EngineMemory {
+		leveldb.StartMetrics(s.chainDB, "chaindb", log)
+	}
 
 	// Initialize account state info DB.
-	if err = s.initAccountStateDB(&serviceContext); err != nil {
+	if err = s.initAccountStateDB(dirs.AccountStateDB, conf.BasicConfig.Cache); err != nil {
 		return nil, err
 	}
 
 	// Initialize debt manager DB.
-	if err = s.initDebtManagerDB(&serviceContext); err != nil {
+	if err = s.initDebtManagerDB(dirs.DebtManagerDB); err != nil {
 		return nil, err
 	}
 
 	s.miner = miner.NewMiner(conf.ScdoConfig.Coinbase, conf.ScdoConfig.CoinbaseList, s, s.debtVerifier, engine, isPoolMode)
+	s.miner.SetWarmup(miner.WarmupConfig{
+		Enabled:    conf.MinerWarmupConfig.Enabled,
+		StableTime: time.Duration(conf.MinerWarmupConfig.StableTime) * time.Second,
+		MinPeers:   conf.MinerWarmupConfig.MinPeers,
+		Timeout:    time.Duration(conf.MinerWarmupConfig.Timeout) * time.Second,
+	}, s.warmupPeerAgreement)
+	s.miner.SetPreemption(time.Duration(conf.MinerPreemptionConfig.MinWorkTime) * time.Millisecond)
+	if conf.FollowerConfig.Enabled {
+		s.setFollower()
+	}
+	if err = s.initBlockTemplateHook(conf.BlockTemplateConfig); err != nil {
+		return nil, err
+	}
+
+	for coinbase, account := range conf.ScdoConfig.PoolAccounts {
+		if err = s.miner.SetCoinbaseWeight(coinbase, account.Weight, account.Quota); err != nil {
+			return nil, fmt.Errorf("failed to set the weight of coinbase %s, %s", coinbase.Hex(), err)
+		}
+	}
 
 	// initialize and validate genesis
 	if err = s.initGenesisAndChain(&serviceContext, conf, startHeight); err != nil {
 		return nil, err
 	}
 
+	if conf.BackupConfig.Interval > 0 && s.dbEngine != database.EngineMemory {
+		s.backups = newBackupScheduler(s.chain, s.chainDB, s.accountStateDB, serviceContext.DataDir, conf.BackupConfig)
+	}
+
 	if err = s.initPool(conf); err != nil {
 		return nil, err
 	}
@@ -139,11 +253,23 @@ func NewScdoService(ctx context.Context, conf *node.Config, log *log.ScdoLog, en
 	return s, nil
 }
 
-func (s *ScdoService) initBlockchainDB(serviceContext *ServiceContext) (err error) {
-	s.chainDBPath = filepath.Join(serviceContext.DataDir, BlockChainDir)
+// openDatabase opens the database of the path with the configured engine
+func (s *ScdoService) openDatabase(path string) (database.Database, error) {
+	switch s.dbEngine {
+	case "", database.EngineLevelDB:
+		return leveldb.NewLevelDB(path)
+	case database.EngineMemory:
+		return memorydb.NewMemoryDB(), nil
+	default:
+		return nil, fmt.Errorf("unknown database engine %s", s.dbEngine)
+	}
+}
+
+func (s *ScdoService) initBlockchainDB(path string) (err error) {
+	s.chainDBPath = path
 	s.log.Info("NewScdoService BlockChain datadir is %s", s.chainDBPath)
 
-	if s.chainDB, err = leveldb.NewLevelDB(s.chainDBPath); err != nil {
+	if s.chainDB, err = s.openDatabase(s.chainDBPath); err != nil {
 		s.log.Error("NewScdoService Create BlockChain err. %s", err)
 		return err
 	}
@@ -151,24 +277,27 @@ func (s *ScdoService) initBlockchainDB(serviceContext *ServiceContext) (err erro
 	return nil
 }
 
-func (s *ScdoService) initAccountStateDB(serviceContext *ServiceContext) (err error) {
-	s.accountStateDBPath = filepath.Join(serviceContext.DataDir, AccountStateDir)
+func (s *ScdoService) initAccountStateDB(path string, cacheMB int) (err error) {
+	s.accountStateDBPath = path
 	s.log.Info("NewScdoService account state datadir is %s", s.accountStateDBPath)
 
-	if s.accountStateDB, err = leveldb.NewLevelDB(s.accountStateDBPath); err != nil {
+	if s.accountStateDB, err = s.openDatabase(s.accountStateDBPath); err != nil {
 		s.Stop()
 		s.log.Error("NewScdoService Create BlockChain err: failed to create account state DB, %s", err)
 		return err
 	}
 
+	// the trie nodes are cached in memory and shared by the states of all blocks
+	s.accountStateDB = trie.NewNodeCache(s.accountStateDB, state.TrieDbPrefix, cacheMB*1024*1024)
+
 	return nil
 }
 
-func (s *ScdoService) initDebtManagerDB(serviceContext *ServiceContext) (err error) {
-	s.debtManagerDBPath = filepath.Join(serviceContext.DataDir, DebtManagerDir)
+func (s *ScdoService) initDebtManagerDB(path string) (err error) {
+	s.debtManagerDBPath = path
 	s.log.Info("NewScdoService debt manager datadir is %s", s.debtManagerDBPath)
 
-	if s.debtManagerDB, err = leveldb.NewLevelDB(s.debtManagerDBPath); err != nil {
+	if s.debtManagerDB, err = s.openDatabase(s.debtManagerDBPath); err != nil {
 		s.Stop()
 		s.log.Error("NewScdoService Create BlockChain err: failed to create debt manager DB, %s", err)
 		return err
@@ -177,6 +306,26 @@ func (s *ScdoService) initDebtManagerDB(serviceContext *ServiceContext) (err err
 	return nil
 }
 
+func (s *ScdoService) initBlockTemplateHook(conf node.BlockTemplateConfig) (err error) {
+	var hook miner.BlockTemplateHook
+	if conf.Plugin != "" {
+		hook, err = miner.LoadTemplatePlugin(conf.Plugin)
+	} else if conf.RuleFile != "" {
+		var rules *miner.TemplateRules
+		if rules, err = miner.LoadTemplateRules(conf.RuleFile); err == nil {
+			hook, err = miner.NewRuleHook(rules)
+		}
+	}
+
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("failed to load the block template hook, %s", err)
+	}
+
+	s.miner.SetBlockTemplateHook(hook)
+	return nil
+}
+
 func (s *ScdoService) initGenesisAndChain(serviceContext *ServiceContext, conf *node.Config, startHeight int) (err error) {
 	bcStore := store.NewCachedStore(store.NewBlockchainDatabase(s.chainDB))
 	genesis := core.GetGenesis(&conf.ScdoConfig.GenesisConfig)
@@ -186,6 +335,7 @@ func (s *ScdoService) initGenesisAndChain(serviceContext *ServiceContext, conf *
 		s.log.Error("NewScdoService genesis.Initialize err. %s", err)
 		return err
 	}
+	s.genesis = genesis
 
 	recoveryPointFile := filepath.Join(serviceContext.DataDir, BlockChainRecoveryPointFile)
 	if s.chain, err = core.NewBlockchain(bcStore, s.accountStateDB, recoveryPointFile, s.miner.GetEngine(), s.debtVerifier, startHeight); err != nil {
@@ -193,6 +343,12 @@ func (s *ScdoService) initGenesisAndChain(serviceContext *ServiceContext, conf *
 		s.log.Error("failed to init chain in NewScdoService. %s", err)
 		return err
 	}
+	s.chain.SetMaxReorgDepth(conf.BasicConfig.MaxReorgDepth)
+	s.chain.SetSlowTxConfig(conf.SlowTxConfig.Threshold, conf.SlowTxConfig.ReportSize)
+	if conf.BasicConfig.StateMismatchReport {
+		s.chain.SetStateMismatchReportDir(filepath.Join(serviceContext.DataDir, StateMismatchReportDir))
+	}
+	s.chain.SetStateDiff(conf.BasicConfig.StateDiff)
 
 	return nil
 }
@@ -205,14 +361,69 @@ func (s *ScdoService) initPool(conf *node.Config) (err error) {
 
 	s.chainHeaderChangeChannel = make(chan common.Hash, chainHeaderChangeBuffSize)
 	s.debtPool = core.NewDebtPool(s.chain, s.debtVerifier)
+	s.debtPool.SetMinPrice(conf.ScdoConfig.DebtConf.MinPrice)
+	s.debtPool.SetExpiry(conf.ScdoConfig.DebtConf.Timeout, conf.ScdoConfig.DebtConf.MaxRequeues)
+	s.chain.SetSlowTxConfig(conf.SlowTxConfig.Threshold, conf.SlowTxConfig.ReportSize)
 	s.txPool = core.NewTransactionPool(conf.ScdoConfig.TxConf, s.chain)
 
+	policies, err := core.NewAdmissionPolicies(conf.ScdoConfig.TxConf.Policies)
+	if err != nil {
+		s.Stop()
+		return fmt.Errorf("failed to create tx pool admission policies, %s", err)
+	}
+	s.txPool.SetAdmissionPolicies(policies)
+
 	event.ChainHeaderChangedEventMananger.AddAsyncListener(s.chainHeaderChanged)
 	go s.MonitorChainHeaderChange()
 
+	s.storageWatcher = newStorageWatcher(s.chain, s.log)
+	event.ChainHeaderChangedEventMananger.AddAsyncListener(s.storageWatcher.chainHeaderChanged)
+
+	s.balanceWatcher = newBalanceWatcher(s.chain, s.log)
+	event.ChainHeaderChangedEventMananger.AddAsyncListener(s.balanceWatcher.chainHeaderChanged)
+
+	s.blockFirehose = newBlockFirehose(s.chain, s.log)
+	event.BlockImportedEventManager.AddListener(s.blockFirehose.blockImported)
+
+	s.orphanTracker = newOrphanTracker(s.chain, s.chainDB, s.log)
+	s.orphanTracker.start()
+
 	return nil
 }
 
+// warmupPeerAgreement returns the number of the peers in the local shard whose total difficulty
+// is not larger than the local head, and the number of all the peers in the local shard.
+func (s *ScdoService) warmupPeerAgreement() (int, int) {
+	peers := s.scdoProtocol.peerSet.getPeerByShard(common.LocalShardNumber)
+
+	localTD, err := s.chain.GetStore().GetBlockTotalDifficulty(s.chain.CurrentBlock().HeaderHash)
+	if err != nil {
+		return 0, len(peers)
+	}
+
+	agreed := 0
+	for _, p := range peers {
+		if _, td := p.Head(); td.Cmp(localTD) <= 0 {
+			agreed++
+		}
+	}
+
+	return agreed, len(peers)
+}
+
+// minerStatus returns the status of the miner, Running, WarmingUp or Stopped
+func (s *ScdoService) minerStatus() string {
+	if s.miner.IsMining() {
+		return "Running"
+	}
+
+	if s.miner.IsWarmingUp() {
+		return "WarmingUp"
+	}
+
+	return "Stopped"
+}
+
 // chainHeaderChanged handle chain header changed event.
 // add forked transaction back
 // deleted invalid transaction
@@ -255,6 +466,50 @@ func (s *ScdoService) Start(srvr *p2p.Server) error {
 	s.p2pServer = srvr
 	s.scdoProtocol.Start()
 
+	if s.watchdogConfig.Enabled {
+		s.watchdog = newWatchdog(s, s.watchdogConfig)
+		s.watchdog.start()
+	}
+
+	if len(s.webhookConfig.Endpoints) > 0 {
+		webhooks, err := newWebhookDispatcher(s.chain, s.webhookConfig, log.GetLogger("webhook"))
+		if err != nil {
+			return err
+		}
+
+		s.webhooks = webhooks
+		s.webhooks.start()
+	}
+
+	if s.diskQuotaConfig.MaxChainDBSize > 0 {
+		s.diskQuota = newDiskQuota(s.chain, s.chainDB, s.chainDBPath, s.diskQuotaConfig)
+		s.diskQuota.start()
+	}
+
+	if s.retentionConfig.ReceiptsDays > 0 || s.retentionConfig.DirtyAccountsDays > 0 {
+		s.retention = newRetentionPruner(s.chain, s.retentionConfig)
+		s.retention.start()
+	}
+
+	if len(s.rpcSyncConfig.Endpoints) > 0 {
+		s.rpcSyncer = newRPCSyncer(s.chain, s.txPool.Pool, s.rpcSyncConfig)
+		s.rpcSyncer.start()
+	}
+
+	if s.leaseMiningConfig.Primary != "" {
+		leaseMiner, err := newLeaseMiner(s.miner.GetEngine(), s.leaseMiningConfig)
+		if err != nil {
+			return err
+		}
+
+		s.leaseMiner = leaseMiner
+		s.leaseMiner.start()
+	}
+
+	if s.backups != nil {
+		s.backups.start()
+	}
+
 	return nil
 }
 
@@ -263,6 +518,50 @@ func (s *ScdoService) Stop() error {
 	//TODO
 	// s.txPool.Stop() s.chain.Stop()
 	// retries? leave it to future
+	if s.watchdog != nil {
+		s.watchdog.stop()
+		s.watchdog = nil
+	}
+
+	if s.webhooks != nil {
+		s.webhooks.stop()
+		s.webhooks = nil
+	}
+
+	if s.diskQuota != nil {
+		s.diskQuota.stop()
+		s.diskQuota = nil
+	}
+
+	if s.retention != nil {
+		s.retention.stop()
+		s.retention = nil
+	}
+
+	if s.rpcSyncer != nil {
+		s.rpcSyncer.stop()
+		s.rpcSyncer = nil
+	}
+
+	if s.shardRPC != nil {
+		s.shardRPC.close()
+	}
+
+	if s.orphanTracker != nil {
+		s.orphanTracker.stop()
+		s.orphanTracker = nil
+	}
+
+	if s.leaseMiner != nil {
+		s.leaseMiner.stop()
+		s.leaseMiner = nil
+	}
+
+	if s.backups != nil {
+		s.backups.stop()
+		s.backups = nil
+	}
+
 	if s.scdoProtocol != nil {
 		s.scdoProtocol.Stop()
 		s.scdoProtocol = nil
@@ -286,6 +585,36 @@ func (s *ScdoService) Stop() error {
 	return nil
 }
 
+// ReloadConfig implements node.ConfigReloader, applying the reloaded rpc limits, tx pool capacity, tx pool admission
+// policies, min debt price, debt expiry and slow tx report.
+func (s *ScdoService) ReloadConfig(conf *node.Config) error {
+	policies, err := core.NewAdmissionPolicies(conf.ScdoConfig.TxConf.Policies)
+	if err != nil {
+		return fmt.Errorf("failed to create tx pool admission policies, %s", err)
+	}
+	s.txPool.SetAdmissionPolicies(policies)
+
+	if conf.ScdoConfig.TxConf.Capacity > 0 {
+		s.txPool.SetCapacity(conf.ScdoConfig.TxConf.Capacity)
+	}
+	s.debtPool.SetMinPrice(conf.ScdoConfig.DebtConf.MinPrice)
+	s.debtPool.SetExpiry(conf.ScdoConfig.DebtConf.Timeout, conf.ScdoConfig.DebtConf.MaxRequeues)
+
+	s.rpcConfigLock.Lock()
+	s.rpcConfig = conf.RPCConfig
+	s.rpcConfigLock.Unlock()
+
+	return nil
+}
+
+// getRPCConfig returns the execution limits of rpc calls
+func (s *ScdoService) getRPCConfig() node.RPCConfig {
+	s.rpcConfigLock.RLock()
+	defer s.rpcConfigLock.RUnlock()
+
+	return s.rpcConfig
+}
+
 // APIs implements node.Service, returning the collection of RPC services the scdo package offers.
 // must to make sure that the order of the download api is 5; we get the download api by 5
 func (s *ScdoService) APIs() (apis []rpc.API) {
@@ -321,6 +650,12 @@ func (s *ScdoService) APIs() (apis []rpc.API) {
 			Service:   NewTransactionPoolAPI(s),
 			Public:    true,
 		},
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewPrivateAdminAPI(s),
+			Public:    false,
+		},
 	}...)
 
 	minerApis := s.miner.GetEngine().APIs(s.chain)
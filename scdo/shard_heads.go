@@ -0,0 +1,93 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// ShardHead is the best chain head of a shard observed from the peers
+type ShardHead struct {
+	Shard      uint
+	Hash       common.Hash
+	Height     uint64 `json:",omitempty"` // 0 if the header of the head is unknown to this node
+	TD         *big.Int
+	Peer       string // the peer which reported the head
+	UpdateTime int64  // unix time when the head is reported
+}
+
+// shardHeaderReader reads the block headers of other shards, e.g. from the light chains of the shards
+type shardHeaderReader interface {
+	GetBlockHeader(shard uint, hash common.Hash) (*types.BlockHeader, error)
+}
+
+// shardHeads records the best-known chain head of each shard from the handshakes and
+// chain head status messages of the peers of all shards.
+type shardHeads struct {
+	lock  sync.RWMutex
+	heads map[uint]*ShardHead
+}
+
+func newShardHeads() *shardHeads {
+	return &shardHeads{
+		heads: make(map[uint]*ShardHead),
+	}
+}
+
+// update records the head reported by the peer of the shard if it has larger TD than the known head,
+// or it is the same head reported again.
+func (h *shardHeads) update(shard uint, peer string, hash common.Hash, td *big.Int, now time.Time) bool {
+	if td == nil || !isShardNumberValid(shard) {
+		return false
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if head := h.heads[shard]; head != nil && head.TD.Cmp(td) > 0 {
+		return false
+	} else if head != nil && head.TD.Cmp(td) == 0 && !head.Hash.Equal(hash) {
+		// keep the first observed head of the same TD
+		return false
+	}
+
+	h.heads[shard] = &ShardHead{
+		Shard:      shard,
+		Hash:       hash,
+		TD:         new(big.Int).Set(td),
+		Peer:       peer,
+		UpdateTime: now.Unix(),
+	}
+
+	return true
+}
+
+// list returns the copies of the known heads sorted by shard
+func (h *shardHeads) list() []*ShardHead {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	heads := make([]*ShardHead, 0, len(h.heads))
+	for _, head := range h.heads {
+		copied := *head
+		copied.TD = new(big.Int).Set(head.TD)
+		heads = append(heads, &copied)
+	}
+
+	sort.Slice(heads, func(i, j int) bool { return heads[i].Shard < heads[j].Shard })
+
+	return heads
+}
+
+func isShardNumberValid(shard uint) bool {
+	return shard > 0 && shard <= common.ShardCount
+}
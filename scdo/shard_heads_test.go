@@ -0,0 +1,55 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShardHeads(t *testing.T) {
+	heads := newShardHeads()
+	now := time.Now()
+	hash1, hash2, hash3 := common.StringToHash("head1"), common.StringToHash("head2"), common.StringToHash("head3")
+
+	assert.Equal(t, heads.update(2, "peer1", hash1, big.NewInt(10), now), true)
+	assert.Equal(t, heads.update(1, "peer2", hash2, big.NewInt(5), now), true)
+
+	// invalid shard or td
+	assert.Equal(t, heads.update(0, "peer1", hash1, big.NewInt(10), now), false)
+	assert.Equal(t, heads.update(common.ShardCount+1, "peer1", hash1, big.NewInt(10), now), false)
+	assert.Equal(t, heads.update(3, "peer1", hash1, nil, now), false)
+
+	// smaller td or another head of the same td
+	assert.Equal(t, heads.update(2, "peer3", hash3, big.NewInt(9), now), false)
+	assert.Equal(t, heads.update(2, "peer3", hash3, big.NewInt(10), now), false)
+
+	// the same head reported again by another peer
+	later := now.Add(time.Minute)
+	assert.Equal(t, heads.update(2, "peer3", hash1, big.NewInt(10), later), true)
+
+	list := heads.list()
+	assert.Equal(t, len(list), 2)
+	assert.Equal(t, list[0].Shard, uint(1))
+	assert.Equal(t, list[0].Hash, hash2)
+	assert.Equal(t, list[1].Shard, uint(2))
+	assert.Equal(t, list[1].Peer, "peer3")
+	assert.Equal(t, list[1].UpdateTime, later.Unix())
+
+	// larger td
+	assert.Equal(t, heads.update(2, "peer1", hash3, big.NewInt(11), now), true)
+	list = heads.list()
+	assert.Equal(t, list[1].Hash, hash3)
+	assert.Equal(t, list[1].TD, big.NewInt(11))
+
+	// the returned heads are copies
+	list[1].TD.SetInt64(100)
+	assert.Equal(t, heads.list()[1].TD, big.NewInt(11))
+}
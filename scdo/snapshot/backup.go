@@ -0,0 +1,174 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+)
+
+const (
+	// BackupManifestFile is the file name of the manifest in a backup
+	BackupManifestFile = "backup.json"
+
+	backupPrefix = "snapshot_"
+	restoreDir   = "snapshot.restore"
+)
+
+var errDataDirInitialized = errors.New("data dir is already initialized")
+
+// BackupManifest describes a local backup, which has the same layout as the snapshot archive,
+// i.e. the databases under ArchiveRoot.
+type BackupManifest struct {
+	Height    uint64      `json:"height"`    // height of the backup head block
+	BlockHash common.Hash `json:"blockHash"` // hash of the backup head block
+	Time      int64       `json:"time"`      // unix time in seconds when the backup is created
+}
+
+// BackupName returns the directory name of the backup at the height, which is sorted by height
+func BackupName(height uint64) string {
+	return fmt.Sprintf("%s%020d", backupPrefix, height)
+}
+
+// WriteBackupManifest writes the manifest into the backup dir
+func WriteBackupManifest(dir string, manifest *BackupManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, BackupManifestFile), content, 0644)
+}
+
+// ReadBackupManifest reads the manifest of the backup dir
+func ReadBackupManifest(dir string) (*BackupManifest, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, BackupManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest BackupManifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return nil, errors.NewStackedError(err, "failed to decode backup manifest")
+	}
+
+	return &manifest, nil
+}
+
+// ListBackups returns the paths of the completed backups in the dir, from the oldest to the newest
+func ListBackups(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, f := range files {
+		if !f.IsDir() || !strings.HasPrefix(f.Name(), backupPrefix) {
+			continue
+		}
+
+		// the manifest is written last, so the backups without manifest are incomplete
+		path := filepath.Join(dir, f.Name())
+		if _, err = os.Stat(filepath.Join(path, BackupManifestFile)); err == nil {
+			backups = append(backups, path)
+		}
+	}
+
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// RotateBackups removes the oldest backups in the dir except the most recent keep ones,
+// and returns the paths of the removed backups.
+func RotateBackups(dir string, keep int) ([]string, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	removed := backups[:len(backups)-keep]
+	for _, path := range removed {
+		if err = os.RemoveAll(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// Restore copies the databases of the backup into the data dir, which must not be initialized,
+// and returns the manifest of the backup.
+func Restore(backupDir, dataDir string) (*BackupManifest, error) {
+	manifest, err := ReadBackupManifest(backupDir)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "invalid backup %s", backupDir)
+	}
+
+	target := filepath.Join(dataDir, ArchiveRoot)
+	if _, err = os.Stat(target); err == nil {
+		return nil, errDataDirInitialized
+	}
+
+	tmpDir := filepath.Join(dataDir, restoreDir)
+	defer os.RemoveAll(tmpDir)
+
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return nil, err
+	}
+
+	if err = copyDir(filepath.Join(backupDir, ArchiveRoot), tmpDir); err != nil {
+		return nil, errors.NewStackedError(err, "failed to copy backup")
+	}
+
+	if err = os.Rename(tmpDir, target); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return writeFile(target, f)
+	})
+}
@@ -0,0 +1,93 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestBackup creates a backup at the height with a key in its chain db
+func newTestBackup(t *testing.T, dir string, height uint64) string {
+	path := filepath.Join(dir, BackupName(height))
+
+	db, err := leveldb.NewLevelDB(filepath.Join(path, testChainDBDir))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, db.PutString("height", BackupName(height)), nil)
+	db.Close()
+
+	manifest := &BackupManifest{Height: height, BlockHash: common.StringToHash(BackupName(height))}
+	assert.Equal(t, WriteBackupManifest(path, manifest), nil)
+
+	return path
+}
+
+func Test_RotateBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backups")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	backups, err := ListBackups(filepath.Join(dir, "none"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(backups), 0)
+
+	b100 := newTestBackup(t, dir, 100)
+	b20 := newTestBackup(t, dir, 20)
+	b3 := newTestBackup(t, dir, 3)
+
+	// incomplete backup without manifest
+	assert.Equal(t, os.MkdirAll(filepath.Join(dir, BackupName(200)), 0755), nil)
+
+	backups, err = ListBackups(dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, backups, []string{b3, b20, b100})
+
+	removed, err := RotateBackups(dir, 2)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, removed, []string{b3})
+
+	removed, err = RotateBackups(dir, 2)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(removed), 0)
+
+	backups, err = ListBackups(dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, backups, []string{b20, b100})
+}
+
+func Test_Restore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restore")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	backup := newTestBackup(t, filepath.Join(dir, "backups"), 5)
+	dataDir := filepath.Join(dir, "data")
+
+	manifest, err := Restore(backup, dataDir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, manifest.Height, uint64(5))
+
+	db, err := leveldb.NewLevelDB(filepath.Join(dataDir, testChainDBDir))
+	assert.Equal(t, err, nil)
+	value, err := db.GetString("height")
+	db.Close()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, BackupName(5))
+
+	// data dir is initialized
+	_, err = Restore(backup, dataDir)
+	assert.Equal(t, err, errDataDirInitialized)
+
+	// not a backup
+	_, err = Restore(dir, filepath.Join(dir, "data2"))
+	assert.Equal(t, err != nil, true)
+}
@@ -0,0 +1,423 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/log"
+)
+
+const (
+	// ManifestFile is the file name of the snapshot manifest served by the mirrors
+	ManifestFile = "snapshot.json"
+
+	// ArchiveRoot is the root directory of the snapshot archive, which is unpacked into the data dir
+	ArchiveRoot = "db"
+
+	downloadFile = "snapshot.download"
+	unpackDir    = "snapshot.unpack"
+
+	defaultTimeout = 2 * time.Hour
+)
+
+var (
+	errInsecureMirror     = errors.New("snapshot mirror must use https")
+	errChecksumMismatch   = errors.New("snapshot archive checksum mismatch")
+	errInvalidSignature   = errors.New("invalid snapshot manifest signature")
+	errAnchorMismatch     = errors.New("snapshot block hash does not match the anchor")
+	errInvalidArchivePath = errors.New("invalid file path in snapshot archive")
+)
+
+// Config is the configuration of bootstrapping from a snapshot archive
+type Config struct {
+	// Mirrors are the https base URLs which serve the manifest and the archive
+	Mirrors []string `json:"mirrors"`
+
+	// Signer is the address of the account which signs the snapshot manifest
+	Signer string `json:"signer"`
+
+	// AnchorHash is an optional trusted block hash which the snapshot must match
+	AnchorHash string `json:"anchorHash"`
+
+	// Timeout is the download timeout in seconds of an archive, 0 means the default 2 hours
+	Timeout int64 `json:"timeout"`
+}
+
+// Manifest describes a snapshot archive
+type Manifest struct {
+	Archive   string      `json:"archive"`   // file name of the archive relative to the mirror
+	Checksum  string      `json:"checksum"`  // hex encoded sha256 of the archive
+	Height    uint64      `json:"height"`    // height of the snapshot head block
+	BlockHash common.Hash `json:"blockHash"` // hash of the snapshot head block
+	Signature string      `json:"signature"` // hex encoded signature of the manifest hash
+}
+
+// Hash returns the hash of the manifest to be signed
+func (m *Manifest) Hash() common.Hash {
+	height := make([]byte, 8)
+	binary.BigEndian.PutUint64(height, m.Height)
+
+	return crypto.HashBytes([]byte(m.Archive), []byte(m.Checksum), height, m.BlockHash.Bytes())
+}
+
+// Verify checks whether the manifest is signed by the signer
+func (m *Manifest) Verify(signer common.Address) error {
+	sig, err := hexutil.HexToBytes(m.Signature)
+	if err != nil {
+		return errors.NewStackedError(err, "failed to decode manifest signature")
+	}
+
+	hash := m.Hash()
+	if !(crypto.Signature{Sig: sig}).Verify(signer, hash.Bytes()) {
+		return errInvalidSignature
+	}
+
+	return nil
+}
+
+// Bootstrap downloads a snapshot archive from the configured mirrors and unpacks it into the data dir,
+// the remainder of the chain is synced from p2p later. It does nothing if the chain db is already initialized.
+// chainDBDir is the path of the blockchain db in the archive relative to the data dir, which is used to verify
+// the anchor, and chainDB is the path of the chain db of the node, which may be configured out of the data dir.
+// The chain db is moved to chainDB, and the other databases are left under the data dir to be migrated to
+// their configured paths.
+func Bootstrap(conf *Config, dataDir, chainDBDir, chainDB string) error {
+	if conf == nil || len(conf.Mirrors) == 0 {
+		return nil
+	}
+
+	logger := log.GetLogger("snapshot")
+	if _, err := os.Stat(chainDB); err == nil {
+		logger.Info("chain db %s is already initialized, skip snapshot bootstrap", chainDB)
+		return nil
+	}
+
+	signer, err := common.HexToAddress(conf.Signer)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "invalid snapshot signer %s", conf.Signer)
+	}
+
+	var anchor common.Hash
+	if len(conf.AnchorHash) > 0 {
+		if anchor, err = common.HexToHash(conf.AnchorHash); err != nil {
+			return errors.NewStackedErrorf(err, "invalid snapshot anchor hash %s", conf.AnchorHash)
+		}
+	}
+
+	timeout := defaultTimeout
+	if conf.Timeout > 0 {
+		timeout = time.Duration(conf.Timeout) * time.Second
+	}
+
+	b := &bootstrapper{
+		client:     &http.Client{Timeout: timeout},
+		signer:     signer,
+		anchor:     anchor,
+		dataDir:    dataDir,
+		chainDBDir: chainDBDir,
+		chainDB:    chainDB,
+		log:        logger,
+	}
+
+	for _, mirror := range conf.Mirrors {
+		if err = b.bootstrap(mirror); err == nil {
+			return nil
+		}
+
+		logger.Warn("failed to bootstrap from snapshot mirror %s, %s", mirror, err)
+	}
+
+	return err
+}
+
+type bootstrapper struct {
+	client     *http.Client
+	signer     common.Address
+	anchor     common.Hash
+	dataDir    string
+	chainDBDir string
+	chainDB    string
+	log        *log.ScdoLog
+}
+
+// bootstrap downloads, verifies and unpacks the snapshot from the mirror
+func (b *bootstrapper) bootstrap(mirror string) error {
+	base, err := url.Parse(mirror)
+	if err != nil {
+		return err
+	}
+
+	if base.Scheme != "https" {
+		return errInsecureMirror
+	}
+
+	manifest, err := b.fetchManifest(base)
+	if err != nil {
+		return err
+	}
+
+	if err = manifest.Verify(b.signer); err != nil {
+		return err
+	}
+
+	if !b.anchor.IsEmpty() && manifest.BlockHash != b.anchor {
+		return errAnchorMismatch
+	}
+
+	archive := filepath.Join(b.dataDir, downloadFile)
+	defer os.Remove(archive)
+
+	b.log.Info("download snapshot %s at height %d from %s", manifest.Archive, manifest.Height, mirror)
+	if err = b.download(resolve(base, manifest.Archive), archive, manifest.Checksum); err != nil {
+		return err
+	}
+
+	tmpDir := filepath.Join(b.dataDir, unpackDir)
+	defer os.RemoveAll(tmpDir)
+
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+
+	if err = unpack(archive, tmpDir); err != nil {
+		return errors.NewStackedError(err, "failed to unpack snapshot archive")
+	}
+
+	if err = verifyAnchor(filepath.Join(tmpDir, b.chainDBDir), manifest.Height, manifest.BlockHash); err != nil {
+		return err
+	}
+
+	// move the chain db at last, which marks the bootstrap completed
+	unpackedChainDB := filepath.Join(tmpDir, b.chainDBDir)
+	if err = b.moveUnpacked(filepath.Join(tmpDir, ArchiveRoot), unpackedChainDB); err != nil {
+		return err
+	}
+
+	if err = moveDir(unpackedChainDB, b.chainDB); err != nil {
+		return errors.NewStackedErrorf(err, "failed to move the chain db to %s", b.chainDB)
+	}
+
+	b.log.Info("bootstrapped from snapshot at height %d, block hash %s", manifest.Height, manifest.BlockHash.Hex())
+	return nil
+}
+
+// moveUnpacked moves the unpacked databases except the chain db into the data dir, which are
+// migrated to their configured paths later
+func (b *bootstrapper) moveUnpacked(root, chainDB string) error {
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(b.dataDir, ArchiveRoot)
+	if err = os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		if path == chainDB {
+			continue
+		}
+
+		if err = os.Rename(path, filepath.Join(target, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveDir renames the src dir to dst, or copies it to dst if they are on different disks
+func moveDir(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	// copy to a temp dir first, so that a broken copy is never opened as the database
+	tmpDir := dst + ".unpack"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+
+	if err := copyDir(src, tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	return os.Rename(tmpDir, dst)
+}
+
+func resolve(base *url.URL, file string) string {
+	u := *base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + file
+	return u.String()
+}
+
+func (b *bootstrapper) get(u string) (*http.Response, error) {
+	resp, err := b.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s, status %s", u, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (b *bootstrapper) fetchManifest(base *url.URL) (*Manifest, error) {
+	resp, err := b.get(resolve(base, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var manifest Manifest
+	if err = json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, errors.NewStackedError(err, "failed to decode snapshot manifest")
+	}
+
+	if len(manifest.Archive) == 0 || strings.Contains(manifest.Archive, "..") {
+		return nil, fmt.Errorf("invalid snapshot archive name %s", manifest.Archive)
+	}
+
+	return &manifest, nil
+}
+
+// download saves the archive into the file and verifies its checksum
+func (b *bootstrapper) download(u, file, checksum string) error {
+	resp, err := b.get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, hasher), resp.Body); err != nil {
+		return errors.NewStackedError(err, "failed to download snapshot archive")
+	}
+
+	if hex.EncodeToString(hasher.Sum(nil)) != strings.ToLower(strings.TrimPrefix(checksum, "0x")) {
+		return errChecksumMismatch
+	}
+
+	return nil
+}
+
+// unpack extracts the tar.gz archive into the dir, only the files under ArchiveRoot are allowed
+func unpack(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(header.Name)
+		if filepath.IsAbs(name) || (name != ArchiveRoot && !strings.HasPrefix(name, ArchiveRoot+string(filepath.Separator))) {
+			return errInvalidArchivePath
+		}
+
+		path := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = writeFile(path, reader); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported file type %v of %s in snapshot archive", header.Typeflag, header.Name)
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// verifyAnchor checks whether the unpacked chain db contains the block hash at the height
+func verifyAnchor(chainDBPath string, height uint64, blockHash common.Hash) error {
+	db, err := leveldb.NewLevelDB(chainDBPath)
+	if err != nil {
+		return errors.NewStackedError(err, "failed to open snapshot chain db")
+	}
+	defer db.Close()
+
+	hash, err := store.NewBlockchainDatabase(db).GetBlockHash(height)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to get snapshot block hash at height %d", height)
+	}
+
+	if hash != blockHash {
+		return errAnchorMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testChainDBDir      = "/db/blockchain"
+	testAccountStateDir = "/db/accountState"
+)
+
+// newTestArchive creates a tar.gz archive of a chain db with a block at height 1
+func newTestArchive(t *testing.T) ([]byte, common.Hash) {
+	dir, err := ioutil.TempDir("", "snapshot_src")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.NewLevelDB(filepath.Join(dir, testChainDBDir))
+	assert.Equal(t, err, nil)
+
+	header := &types.BlockHeader{Height: 1, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}
+	hash := header.Hash()
+	assert.Equal(t, store.NewBlockchainDatabase(db).PutBlockHeader(hash, header, big.NewInt(1), true), nil)
+	db.Close()
+
+	stateDB, err := leveldb.NewLevelDB(filepath.Join(dir, testAccountStateDir))
+	assert.Equal(t, err, nil)
+	stateDB.Close()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	err = filepath.Walk(filepath.Join(dir, ArchiveRoot), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, _ := filepath.Rel(dir, path)
+		h, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		h.Name = name
+		if err = tw.WriteHeader(h); err != nil || info.IsDir() {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(content)
+		return err
+	})
+	assert.Equal(t, err, nil)
+	tw.Close()
+	gz.Close()
+
+	return buf.Bytes(), hash
+}
+
+func newTestManifest(archive []byte, hash common.Hash, key *ecdsa.PrivateKey) *Manifest {
+	checksum := sha256.Sum256(archive)
+	manifest := &Manifest{
+		Archive:   "snapshot-1.tar.gz",
+		Checksum:  hex.EncodeToString(checksum[:]),
+		Height:    1,
+		BlockHash: hash,
+	}
+
+	h := manifest.Hash()
+	manifest.Signature = hexutil.BytesToHex(crypto.MustSign(key, h.Bytes()).Sig)
+	return manifest
+}
+
+func newTestMirror(manifest *Manifest, archive []byte) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+ManifestFile, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(manifest)
+	})
+	mux.HandleFunc("/"+manifest.Archive, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+
+	return httptest.NewTLSServer(mux)
+}
+
+func newTestBootstrapper(t *testing.T, server *httptest.Server, signer common.Address) *bootstrapper {
+	dataDir, err := ioutil.TempDir("", "snapshot_dst")
+	assert.Equal(t, err, nil)
+
+	return &bootstrapper{
+		client:     server.Client(),
+		signer:     signer,
+		dataDir:    dataDir,
+		chainDBDir: testChainDBDir,
+		chainDB:    filepath.Join(dataDir, testChainDBDir),
+		log:        log.GetLogger("snapshot"),
+	}
+}
+
+func Test_Bootstrap(t *testing.T) {
+	signer, key := crypto.MustGenerateShardKeyPair(1)
+	archive, hash := newTestArchive(t)
+	manifest := newTestManifest(archive, hash, key)
+
+	server := newTestMirror(manifest, archive)
+	defer server.Close()
+
+	b := newTestBootstrapper(t, server, *signer)
+	defer os.RemoveAll(b.dataDir)
+
+	b.anchor = hash
+	assert.Equal(t, b.bootstrap(server.URL), nil)
+
+	_, err := os.Stat(filepath.Join(b.dataDir, testChainDBDir))
+	assert.Equal(t, err, nil)
+	_, err = os.Stat(filepath.Join(b.dataDir, testAccountStateDir))
+	assert.Equal(t, err, nil)
+	_, err = os.Stat(filepath.Join(b.dataDir, unpackDir))
+	assert.Equal(t, os.IsNotExist(err), true)
+}
+
+func Test_Bootstrap_ChainDBPath(t *testing.T) {
+	signer, key := crypto.MustGenerateShardKeyPair(1)
+	archive, hash := newTestArchive(t)
+
+	server := newTestMirror(newTestManifest(archive, hash, key), archive)
+	defer server.Close()
+
+	b := newTestBootstrapper(t, server, *signer)
+	defer os.RemoveAll(b.dataDir)
+
+	// the chain db is configured out of the data dir
+	b.chainDB = filepath.Join(b.dataDir, "chaindb")
+	assert.Equal(t, b.bootstrap(server.URL), nil)
+
+	_, err := os.Stat(b.chainDB)
+	assert.Equal(t, err, nil)
+	_, err = os.Stat(filepath.Join(b.dataDir, testChainDBDir))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	// the other databases are left under the data dir to migrate
+	_, err = os.Stat(filepath.Join(b.dataDir, testAccountStateDir))
+	assert.Equal(t, err, nil)
+
+	// skipped since the chain db is initialized
+	conf := &Config{Mirrors: []string{server.URL}, Signer: "invalid"}
+	assert.Equal(t, Bootstrap(conf, b.dataDir, testChainDBDir, b.chainDB), nil)
+}
+
+func Test_Bootstrap_Invalid(t *testing.T) {
+	signer, key := crypto.MustGenerateShardKeyPair(1)
+	archive, hash := newTestArchive(t)
+
+	// signed by another account
+	_, otherKey := crypto.MustGenerateShardKeyPair(1)
+	server := newTestMirror(newTestManifest(archive, hash, otherKey), archive)
+	b := newTestBootstrapper(t, server, *signer)
+	assert.Equal(t, b.bootstrap(server.URL), errInvalidSignature)
+	assert.Equal(t, b.bootstrap("http://127.0.0.1"), errInsecureMirror)
+	server.Close()
+	os.RemoveAll(b.dataDir)
+
+	// anchor mismatch
+	server = newTestMirror(newTestManifest(archive, hash, key), archive)
+	b = newTestBootstrapper(t, server, *signer)
+	b.anchor = common.StringToHash("anchor")
+	assert.Equal(t, b.bootstrap(server.URL), errAnchorMismatch)
+	server.Close()
+	os.RemoveAll(b.dataDir)
+
+	// tampered archive
+	manifest := newTestManifest(archive, hash, key)
+	server = newTestMirror(manifest, append(archive, 0))
+	b = newTestBootstrapper(t, server, *signer)
+	assert.Equal(t, b.bootstrap(server.URL), errChecksumMismatch)
+	_, err := os.Stat(filepath.Join(b.dataDir, ArchiveRoot))
+	assert.Equal(t, os.IsNotExist(err), true)
+	server.Close()
+	os.RemoveAll(b.dataDir)
+}
@@ -0,0 +1,362 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/common/errors"
+	"github.com/scdoproject/go-scdo/common/hexutil"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/rpc"
+)
+
+const (
+	// maxStorageWatches is the max number of storage slots watched by a subscription
+	maxStorageWatches = 1024
+
+	// maxStorageWatchDepth is the max number of new blocks whose state diffs are walked through
+	// when the head is changed, otherwise the watched slots are compared between the old and new head states.
+	maxStorageWatchDepth = 64
+
+	// storageChangesBuffSize is the number of notifications buffered for a subscriber
+	storageChangesBuffSize = 64
+)
+
+// StorageWatch is a storage slot of a contract watched by the storageChanges subscription
+type StorageWatch struct {
+	Contract common.Address
+	Key      common.Hash
+}
+
+// StorageChange is the change of a watched storage slot
+type StorageChange struct {
+	Contract common.Address
+	Key      common.Hash
+	Before   string
+	After    string
+}
+
+// StorageChanges is notified to the subscriber when any watched storage slot is changed by a new HEAD block
+type StorageChanges struct {
+	BlockHash common.Hash
+	Height    uint64
+
+	// whether the changes are the difference between the states of the old and new HEAD blocks,
+	// e.g. the chain is reorganized, instead of the state diff of the block.
+	Reorg bool
+
+	Changes []*StorageChange
+}
+
+// StorageChanges creates a subscription which notifies the StorageChanges of the new HEAD blocks
+// when any of the watched storage slots is changed, so that the clients don't have to poll
+// the storage of the contracts on every new block. It is only available on the connections
+// which support notifications, e.g. websocket and ipc.
+func (api *PublicScdoAPI) StorageChanges(ctx context.Context, watches []StorageWatch) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	if len(watches) == 0 {
+		return nil, errors.New("no storage slot is watched")
+	}
+
+	if len(watches) > maxStorageWatches {
+		return nil, fmt.Errorf("too many storage slots are watched, %d > %d", len(watches), maxStorageWatches)
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	changes := api.s.storageWatcher.subscribe(rpcSub.ID, watches)
+
+	go func() {
+		defer api.s.storageWatcher.unsubscribe(rpcSub.ID)
+
+		for {
+			select {
+			case c := <-changes:
+				if err := notifier.Notify(rpcSub.ID, c); err != nil {
+					api.s.log.Debug("failed to notify storage changes, subscription:%s, %s", rpcSub.ID, err)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// watchChain is the chain whose state changes are watched
+type watchChain interface {
+	stateDiffChain
+	CurrentBlock() *types.Block
+	GetStore() store.BlockchainStore
+	GetState(root common.Hash) (*state.Statedb, error)
+}
+
+type storageSubscription struct {
+	watches map[common.Address]map[common.Hash]bool
+	changes chan *StorageChanges
+}
+
+// storageWatcher computes the changes of the watched storage slots from the state diffs of
+// the new HEAD blocks, and dispatches them to the subscriptions.
+type storageWatcher struct {
+	lock  sync.Mutex
+	chain watchChain
+	head  *types.Block // the last HEAD block whose changes are dispatched
+	subs  map[rpc.ID]*storageSubscription
+	log   *log.ScdoLog
+}
+
+func newStorageWatcher(chain watchChain, log *log.ScdoLog) *storageWatcher {
+	return &storageWatcher{
+		chain: chain,
+		head:  chain.CurrentBlock(),
+		subs:  make(map[rpc.ID]*storageSubscription),
+		log:   log,
+	}
+}
+
+func (w *storageWatcher) subscribe(id rpc.ID, watches []StorageWatch) <-chan *StorageChanges {
+	sub := &storageSubscription{
+		watches: make(map[common.Address]map[common.Hash]bool),
+		changes: make(chan *StorageChanges, storageChangesBuffSize),
+	}
+
+	for _, watch := range watches {
+		if sub.watches[watch.Contract] == nil {
+			sub.watches[watch.Contract] = make(map[common.Hash]bool)
+		}
+
+		sub.watches[watch.Contract][watch.Key] = true
+	}
+
+	w.lock.Lock()
+	w.subs[id] = sub
+	w.lock.Unlock()
+
+	w.chain.AddStateDiffConsumer()
+
+	return sub.changes
+}
+
+func (w *storageWatcher) unsubscribe(id rpc.ID) {
+	w.lock.Lock()
+	_, found := w.subs[id]
+	delete(w.subs, id)
+	w.lock.Unlock()
+
+	if found {
+		w.chain.RemoveStateDiffConsumer()
+	}
+}
+
+// chainHeaderChanged handles the chain header changed event. The current HEAD is used instead of
+// the block of the event, since the async events may be handled out of order.
+func (w *storageWatcher) chainHeaderChanged(e event.Event) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	head := w.chain.CurrentBlock()
+	if head == nil || (w.head != nil && w.head.HeaderHash.Equal(head.HeaderHash)) {
+		return
+	}
+
+	from := w.head
+	w.head = head
+
+	if from == nil || len(w.subs) == 0 {
+		return
+	}
+
+	for _, changes := range w.collect(from, head) {
+		w.dispatch(changes)
+	}
+}
+
+// collect returns the storage changes of the blocks from the old HEAD (exclusive) to the new HEAD.
+// If the new HEAD is not a descendant of the old one within maxStorageWatchDepth blocks, or the state
+// diff of any block is not available, the watched slots of the old and new HEAD states are compared instead.
+func (w *storageWatcher) collect(from, to *types.Block) []*StorageChanges {
+	bcStore := w.chain.GetStore()
+
+	if blocks := newHeadBlocks(bcStore, from, to, maxStorageWatchDepth, w.log); blocks != nil {
+		changes, err := w.collectStateDiffs(bcStore, blocks)
+		if err == nil {
+			return changes
+		}
+
+		w.log.Warn("failed to collect the state diffs from block %s to %s, compare the states instead, %s", from.HeaderHash.Hex(), to.HeaderHash.Hex(), err)
+	}
+
+	changes := w.compareStates(from, to)
+	if changes == nil {
+		return nil
+	}
+
+	return []*StorageChanges{changes}
+}
+
+// newHeadBlocks returns the blocks from the old HEAD (exclusive) to the new HEAD in descending order,
+// or nil if the new HEAD is not a descendant of the old one within depth blocks, e.g. reorganized.
+func newHeadBlocks(bcStore store.BlockchainStore, from, to *types.Block, depth int, log *log.ScdoLog) []*types.Block {
+	var blocks []*types.Block
+	for block := to; len(blocks) < depth && block.Header.Height > from.Header.Height; {
+		blocks = append(blocks, block)
+
+		if block.Header.PreviousBlockHash.Equal(from.HeaderHash) {
+			return blocks
+		}
+
+		parent, err := bcStore.GetBlock(block.Header.PreviousBlockHash)
+		if err != nil {
+			log.Warn("failed to get block %s, %s", block.Header.PreviousBlockHash.Hex(), err)
+			return nil
+		}
+
+		block = parent
+	}
+
+	return nil
+}
+
+// collectStateDiffs returns the storage changes of the blocks from their state diffs, the blocks are in descending order.
+// The state diff is not available if the block is written before any subscription.
+func (w *storageWatcher) collectStateDiffs(bcStore store.BlockchainStore, blocks []*types.Block) ([]*StorageChanges, error) {
+	var result []*StorageChanges
+	for i := len(blocks) - 1; i >= 0; i-- {
+		block := blocks[i]
+		diffs, err := bcStore.GetStateDiff(block.HeaderHash)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get state diff of block %s", block.HeaderHash.Hex())
+		}
+
+		changes := &StorageChanges{
+			BlockHash: block.HeaderHash,
+			Height:    block.Header.Height,
+		}
+
+		for _, diff := range diffs {
+			for _, s := range diff.Storage {
+				changes.Changes = append(changes.Changes, newStorageChange(diff.Address, s.Key, s.Before, s.After))
+			}
+		}
+
+		if len(changes.Changes) > 0 {
+			result = append(result, changes)
+		}
+	}
+
+	return result, nil
+}
+
+// compareStates returns the changes of all the watched slots between the states of the blocks.
+func (w *storageWatcher) compareStates(from, to *types.Block) *StorageChanges {
+	fromState, err := w.chain.GetState(from.Header.StateHash)
+	if err != nil {
+		w.log.Warn("failed to get state of block %s, %s", from.HeaderHash.Hex(), err)
+		return nil
+	}
+
+	toState, err := w.chain.GetState(to.Header.StateHash)
+	if err != nil {
+		w.log.Warn("failed to get state of block %s, %s", to.HeaderHash.Hex(), err)
+		return nil
+	}
+
+	changes := &StorageChanges{
+		BlockHash: to.HeaderHash,
+		Height:    to.Header.Height,
+		Reorg:     true,
+	}
+
+	for _, watch := range w.watches() {
+		before := fromState.GetData(watch.Contract, watch.Key)
+		after := toState.GetData(watch.Contract, watch.Key)
+		if !bytes.Equal(before, after) {
+			changes.Changes = append(changes.Changes, newStorageChange(watch.Contract, watch.Key, before, after))
+		}
+	}
+
+	if len(changes.Changes) == 0 {
+		return nil
+	}
+
+	return changes
+}
+
+// watches returns the sorted slots watched by any subscription
+func (w *storageWatcher) watches() []StorageWatch {
+	var watches []StorageWatch
+	seen := make(map[StorageWatch]bool)
+	for _, sub := range w.subs {
+		for contract, keys := range sub.watches {
+			for key := range keys {
+				watch := StorageWatch{contract, key}
+				if !seen[watch] {
+					seen[watch] = true
+					watches = append(watches, watch)
+				}
+			}
+		}
+	}
+
+	sort.Slice(watches, func(i, j int) bool {
+		if c := bytes.Compare(watches[i].Contract.Bytes(), watches[j].Contract.Bytes()); c != 0 {
+			return c < 0
+		}
+
+		return bytes.Compare(watches[i].Key.Bytes(), watches[j].Key.Bytes()) < 0
+	})
+
+	return watches
+}
+
+// dispatch sends the changes of the watched slots to each subscription. The changes are dropped
+// for the subscription whose buffer is full, i.e. the subscriber is too slow.
+func (w *storageWatcher) dispatch(changes *StorageChanges) {
+	for id, sub := range w.subs {
+		var watched []*StorageChange
+		for _, c := range changes.Changes {
+			if sub.watches[c.Contract][c.Key] {
+				watched = append(watched, c)
+			}
+		}
+
+		if len(watched) == 0 {
+			continue
+		}
+
+		select {
+		case sub.changes <- &StorageChanges{changes.BlockHash, changes.Height, changes.Reorg, watched}:
+		default:
+			w.log.Warn("storage changes of block %s dropped for slow subscription %s", changes.BlockHash.Hex(), id)
+		}
+	}
+}
+
+func newStorageChange(contract common.Address, key common.Hash, before, after []byte) *StorageChange {
+	return &StorageChange{
+		Contract: contract,
+		Key:      key,
+		Before:   hexutil.BytesToHex(before),
+		After:    hexutil.BytesToHex(after),
+	}
+}
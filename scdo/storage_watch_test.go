@@ -0,0 +1,183 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/state"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database"
+	"github.com/scdoproject/go-scdo/database/memorydb"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStorageWatchChain struct {
+	db        database.Database
+	bcStore   store.BlockchainStore
+	head      *types.Block
+	consumers int
+}
+
+func (c *mockStorageWatchChain) CurrentBlock() *types.Block      { return c.head }
+func (c *mockStorageWatchChain) GetStore() store.BlockchainStore { return c.bcStore }
+func (c *mockStorageWatchChain) AddStateDiffConsumer()           { c.consumers++ }
+func (c *mockStorageWatchChain) RemoveStateDiffConsumer()        { c.consumers-- }
+func (c *mockStorageWatchChain) GetState(root common.Hash) (*state.Statedb, error) {
+	return state.NewStatedb(root, c.db)
+}
+
+func (c *mockStorageWatchChain) newState(t *testing.T, contract common.Address, data map[common.Hash][]byte) common.Hash {
+	statedb, err := state.NewStatedb(common.EmptyHash, c.db)
+	assert.Equal(t, err, nil)
+
+	statedb.CreateAccount(contract)
+	for key, value := range data {
+		statedb.SetData(contract, key, value)
+	}
+
+	batch := c.db.NewBatch()
+	root, err := statedb.Commit(batch)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, batch.Commit(), nil)
+
+	return root
+}
+
+func (c *mockStorageWatchChain) putBlock(t *testing.T, parent *types.Block, root common.Hash, diffs []*types.AccountDiff) *types.Block {
+	header := &types.BlockHeader{
+		StateHash:       root,
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(1),
+	}
+
+	if parent != nil {
+		header.PreviousBlockHash = parent.HeaderHash
+		header.Height = parent.Header.Height + 1
+		header.CreateTimestamp = big.NewInt(parent.Header.CreateTimestamp.Int64() + 1)
+	}
+
+	block := types.NewBlock(header, nil, nil, nil)
+	assert.Equal(t, c.bcStore.PutBlock(block, big.NewInt(int64(header.Height+1)), true), nil)
+	assert.Equal(t, c.bcStore.PutStateDiff(block.HeaderHash, diffs), nil)
+
+	return block
+}
+
+func Test_StorageWatcher(t *testing.T) {
+	db, dispose := memorydb.NewTestDatabase()
+	defer dispose()
+
+	chain := &mockStorageWatchChain{db: db, bcStore: store.NewBlockchainDatabase(db)}
+	contract := common.BytesToAddress([]byte("contract"))
+	key1, key2, key3 := common.StringToHash("key1"), common.StringToHash("key2"), common.StringToHash("key3")
+
+	genesis := chain.putBlock(t, nil, chain.newState(t, contract, nil), nil)
+	chain.head = genesis
+
+	watcher := newStorageWatcher(chain, log.GetLogger("scdo"))
+	changes1 := watcher.subscribe("sub1", []StorageWatch{{contract, key1}})
+	changes2 := watcher.subscribe("sub2", []StorageWatch{{contract, key2}})
+	assert.Equal(t, chain.consumers, 2)
+
+	// block 1 changes key1 and key3
+	root1 := chain.newState(t, contract, map[common.Hash][]byte{key1: {1}, key3: {3}})
+	block1 := chain.putBlock(t, genesis, root1, []*types.AccountDiff{{
+		Address: contract,
+		Storage: []*types.StorageDiff{{Key: key1, After: []byte{1}}, {Key: key3, After: []byte{3}}},
+	}})
+	chain.head = block1
+	watcher.chainHeaderChanged(block1)
+
+	assert.Equal(t, len(changes1), 1)
+	assert.Equal(t, len(changes2), 0)
+	assert.Equal(t, <-changes1, &StorageChanges{
+		BlockHash: block1.HeaderHash,
+		Height:    1,
+		Changes:   []*StorageChange{{Contract: contract, Key: key1, Before: "0x", After: "0x01"}},
+	})
+
+	// the same head is notified only once
+	watcher.chainHeaderChanged(block1)
+	assert.Equal(t, len(changes1), 0)
+
+	// reorg to another block 1 which changes key2 only
+	root2 := chain.newState(t, contract, map[common.Hash][]byte{key2: {2}})
+	fork1 := chain.putBlock(t, genesis, root2, []*types.AccountDiff{{
+		Address: contract,
+		Storage: []*types.StorageDiff{{Key: key2, After: []byte{2}}},
+	}})
+	chain.head = fork1
+	watcher.chainHeaderChanged(fork1)
+
+	assert.Equal(t, <-changes1, &StorageChanges{
+		BlockHash: fork1.HeaderHash,
+		Height:    1,
+		Reorg:     true,
+		Changes:   []*StorageChange{{Contract: contract, Key: key1, Before: "0x01", After: "0x"}},
+	})
+	assert.Equal(t, <-changes2, &StorageChanges{
+		BlockHash: fork1.HeaderHash,
+		Height:    1,
+		Reorg:     true,
+		Changes:   []*StorageChange{{Contract: contract, Key: key2, Before: "0x", After: "0x02"}},
+	})
+
+	// unsubscribed
+	watcher.unsubscribe("sub1")
+	assert.Equal(t, chain.consumers, 1)
+	block2 := chain.putBlock(t, fork1, root1, []*types.AccountDiff{{
+		Address: contract,
+		Storage: []*types.StorageDiff{{Key: key1, After: []byte{1}}, {Key: key2, Before: []byte{2}}},
+	}})
+	chain.head = block2
+	watcher.chainHeaderChanged(block2)
+
+	assert.Equal(t, len(changes1), 0)
+	assert.Equal(t, <-changes2, &StorageChanges{
+		BlockHash: block2.HeaderHash,
+		Height:    2,
+		Changes:   []*StorageChange{{Contract: contract, Key: key2, Before: "0x02", After: "0x"}},
+	})
+}
+
+func Test_StorageWatcher_NoStateDiff(t *testing.T) {
+	db, dispose := memorydb.NewTestDatabase()
+	defer dispose()
+
+	chain := &mockStorageWatchChain{db: db, bcStore: store.NewBlockchainDatabase(db)}
+	contract := common.BytesToAddress([]byte("contract"))
+	key := common.StringToHash("key")
+
+	genesis := chain.putBlock(t, nil, chain.newState(t, contract, nil), nil)
+	chain.head = genesis
+
+	watcher := newStorageWatcher(chain, log.GetLogger("scdo"))
+	changes := watcher.subscribe("sub", []StorageWatch{{contract, key}})
+
+	// the state diff of the block is not stored, so the states are compared
+	block1 := types.NewBlock(&types.BlockHeader{
+		PreviousBlockHash: genesis.HeaderHash,
+		Height:            1,
+		StateHash:         chain.newState(t, contract, map[common.Hash][]byte{key: {1}}),
+		Difficulty:        big.NewInt(1),
+		CreateTimestamp:   big.NewInt(2),
+	}, nil, nil, nil)
+	assert.Equal(t, chain.bcStore.PutBlock(block1, big.NewInt(2), true), nil)
+	chain.head = block1
+	watcher.chainHeaderChanged(block1)
+
+	assert.Equal(t, <-changes, &StorageChanges{
+		BlockHash: block1.HeaderHash,
+		Height:    1,
+		Reorg:     true,
+		Changes:   []*StorageChange{{Contract: contract, Key: key, Before: "0x", After: "0x01"}},
+	})
+}
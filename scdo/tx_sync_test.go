@@ -0,0 +1,78 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	log2 "github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTxSyncTestPeer() (*peer, *mockGossipMsgReadWriter) {
+	n := discovery.NewNode(*crypto.MustGenerateShardAddress(1), nil, 0, 0)
+	rw := &mockGossipMsgReadWriter{make(chan *p2p.Message, 10)}
+
+	return newPeer(common.ScdoVersion, &p2p.Peer{Node: n}, rw, log2.GetLogger("test"), node.PeerKnownCacheConfig{}), rw
+}
+
+func sentTxPacks(t *testing.T, rw *mockGossipMsgReadWriter) []int {
+	var packs []int
+	for {
+		select {
+		case msg := <-rw.msgs:
+			assert.Equal(t, msg.Code, transactionsMsgCode)
+
+			var sent []*types.Transaction
+			assert.Equal(t, common.Deserialize(msg.Payload, &sent), nil)
+			packs = append(packs, len(sent))
+		default:
+			return packs
+		}
+	}
+}
+
+func Test_SyncTxsToPeer(t *testing.T) {
+	p, rw := newTxSyncTestPeer()
+	txs := newTestGossipTxs(0, 25)
+	p.markKnownTx(txs[3].Hash)
+
+	synced := syncTxsToPeer(p, txs, node.TxSyncConfig{PackSize: 10}, nil)
+	assert.Equal(t, synced, 24)
+	assert.Equal(t, sentTxPacks(t, rw), []int{10, 10, 4})
+	assert.Equal(t, p.Info().SyncedTxs, uint64(24))
+
+	// the synced txs are known by the peer
+	assert.Equal(t, p.isKnownTx(txs[24].Hash), true)
+	assert.Equal(t, syncTxsToPeer(p, txs, node.TxSyncConfig{PackSize: 10}, nil), 0)
+	assert.Equal(t, len(sentTxPacks(t, rw)), 0)
+}
+
+func Test_SyncTxsToPeer_Rate(t *testing.T) {
+	p, rw := newTxSyncTestPeer()
+	txs := newTestGossipTxs(0, 30)
+
+	// 10 txs per 50ms
+	start := time.Now()
+	synced := syncTxsToPeer(p, txs, node.TxSyncConfig{PackSize: 10, Rate: 200}, nil)
+	assert.Equal(t, synced, 30)
+	assert.Equal(t, time.Since(start) >= 100*time.Millisecond, true)
+	assert.Equal(t, sentTxPacks(t, rw), []int{10, 10, 10})
+
+	// stop waiting for the rate when quit
+	quit := make(chan struct{})
+	close(quit)
+	p, rw = newTxSyncTestPeer()
+	assert.Equal(t, syncTxsToPeer(p, txs, node.TxSyncConfig{PackSize: 10, Rate: 1}, quit), 10)
+	assert.Equal(t, sentTxPacks(t, rw), []int{10})
+}
@@ -0,0 +1,248 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/node"
+)
+
+const (
+	defaultWatchdogInterval        = 30 * time.Second
+	defaultWatchdogMaxHeadAge      = 10 * time.Minute
+	defaultWatchdogMaxSyncDuration = 30 * time.Minute
+	defaultWatchdogMinPeers        = 1
+)
+
+// stalls detected by the watchdog
+const (
+	StallHead  = "head"  // the chain head is not updated for a long time
+	StallSync  = "sync"  // the sync session lasts too long
+	StallMiner = "miner" // the miner is expected to mine but idle
+	StallPeers = "peers" // too few peers in the local shard
+)
+
+// WatchdogEvent is fired when the watchdog detects a stall
+type WatchdogEvent struct {
+	Stall   string   // kind of the stall
+	Detail  string   // description of the stall
+	Actions []string // recovery actions taken
+}
+
+// watchdog monitors the head age, the downloader status, the miner liveness and the peer count,
+// and recovers the stalled sync and mining instead of restarting the node manually.
+type watchdog struct {
+	s   *ScdoService
+	log *log.ScdoLog
+
+	interval        time.Duration
+	maxHeadAge      time.Duration // also the cooldown of reporting the same kind of stall
+	maxSyncDuration time.Duration
+	minPeers        int
+
+	lock      sync.Mutex
+	syncStart time.Time // start time of the current sync session, zero if not syncing
+
+	minerIdle  bool                 // whether the miner is idle in the last check
+	lastReport map[string]time.Time // stall -> time of the last report
+
+	quitCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func durationOrDefault(seconds int64, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func newWatchdog(s *ScdoService, conf node.WatchdogConfig) *watchdog {
+	w := &watchdog{
+		s:               s,
+		log:             log.GetLogger("watchdog"),
+		interval:        durationOrDefault(conf.Interval, defaultWatchdogInterval),
+		maxHeadAge:      durationOrDefault(conf.MaxHeadAge, defaultWatchdogMaxHeadAge),
+		maxSyncDuration: durationOrDefault(conf.MaxSyncDuration, defaultWatchdogMaxSyncDuration),
+		minPeers:        conf.MinPeers,
+		lastReport:      make(map[string]time.Time),
+		quitCh:          make(chan struct{}),
+	}
+
+	if w.minPeers <= 0 {
+		w.minPeers = defaultWatchdogMinPeers
+	}
+
+	return w
+}
+
+func (w *watchdog) start() {
+	event.BlockDownloaderEventManager.AddListener(w.downloaderEventCallback)
+
+	w.wg.Add(1)
+	go w.loop()
+}
+
+func (w *watchdog) stop() {
+	event.BlockDownloaderEventManager.RemoveListener(w.downloaderEventCallback)
+	close(w.quitCh)
+	w.wg.Wait()
+}
+
+// downloaderEventCallback tracks the start time of the current sync session
+func (w *watchdog) downloaderEventCallback(e event.Event) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	switch e.(int) {
+	case event.DownloaderStartEvent:
+		w.syncStart = time.Now()
+	case event.DownloaderDoneEvent, event.DownloaderFailedEvent:
+		w.syncStart = time.Time{}
+	}
+}
+
+func (w *watchdog) loop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			w.check(now)
+		case <-w.quitCh:
+			return
+		}
+	}
+}
+
+func (w *watchdog) check(now time.Time) {
+	w.checkPeers(now)
+	w.checkSync(now)
+	w.checkMiner(now)
+	w.checkHead(now)
+}
+
+func (w *watchdog) checkPeers(now time.Time) {
+	if count := w.s.scdoProtocol.peerSet.getPeerCountByShard(common.LocalShardNumber); count < w.minPeers {
+		w.report(now, StallPeers, fmt.Sprintf("%d peers in the local shard, min %d", count, w.minPeers))
+	}
+}
+
+func (w *watchdog) checkSync(now time.Time) {
+	w.lock.Lock()
+	syncStart := w.syncStart
+	w.lock.Unlock()
+
+	if syncStart.IsZero() || now.Sub(syncStart) <= w.maxSyncDuration {
+		return
+	}
+
+	w.report(now, StallSync, fmt.Sprintf("sync session lasts %v", now.Sub(syncStart)), w.dropSlowestPeer, w.restartSyncer)
+}
+
+// checkMiner reports the miner stall if the miner is expected to mine but idle in two consecutive checks
+func (w *watchdog) checkMiner(now time.Time) {
+	if w.s.miner.IsStopperSet() || w.s.miner.IsMining() || !w.s.Downloader().IsSyncStatusNone() {
+		w.minerIdle = false
+		return
+	}
+
+	if !w.minerIdle {
+		w.minerIdle = true
+		return
+	}
+
+	w.report(now, StallMiner, fmt.Sprintf("miner is idle for %v", w.interval), w.restartMiner)
+}
+
+func (w *watchdog) checkHead(now time.Time) {
+	if !w.s.Downloader().IsSyncStatusNone() {
+		return
+	}
+
+	header := w.s.chain.CurrentHeader()
+	age := now.Sub(time.Unix(header.CreateTimestamp.Int64(), 0))
+	if age <= w.maxHeadAge {
+		return
+	}
+
+	w.report(now, StallHead, fmt.Sprintf("head %d is %v old", header.Height, age), w.dropSlowestPeer, w.restartSyncer, w.restartMiner)
+}
+
+// report reports the stall by log, metrics and event, and takes the recovery actions.
+// An action returns the description of the action, or empty if it is not taken.
+// The same kind of stall is reported at most once in the max head age.
+func (w *watchdog) report(now time.Time, stall, detail string, actions ...func() string) {
+	if last, ok := w.lastReport[stall]; ok && now.Sub(last) < w.maxHeadAge {
+		return
+	}
+	w.lastReport[stall] = now
+
+	e := &WatchdogEvent{Stall: stall, Detail: detail}
+	for _, action := range actions {
+		if taken := action(); len(taken) > 0 {
+			e.Actions = append(e.Actions, taken)
+		}
+	}
+
+	w.log.Warn("watchdog detected %s stall, %s, recovery actions %v", stall, detail, e.Actions)
+	metrics.MetricsWatchdogStallMeter.Mark(1)
+	metrics.MetricsWatchdogRecoveryMeter.Mark(int64(len(e.Actions)))
+	event.WatchdogEventManager.Fire(e)
+}
+
+// dropSlowestPeer disconnects the peer with the lowest total difficulty in the local shard
+func (w *watchdog) dropSlowestPeer() string {
+	peers := w.s.scdoProtocol.peerSet.getPeerByShard(common.LocalShardNumber)
+	if len(peers) <= w.minPeers {
+		return ""
+	}
+
+	slowest := peers[0]
+	_, slowestTD := slowest.Head()
+	for _, p := range peers[1:] {
+		if _, td := p.Head(); td.Cmp(slowestTD) < 0 {
+			slowest, slowestTD = p, td
+		}
+	}
+
+	slowest.DisconnectPeer("disconnect by watchdog for stall")
+
+	return fmt.Sprintf("drop slowest peer %s", slowest.peerStrID)
+}
+
+// restartSyncer cancels the current sync session and requests a new one
+func (w *watchdog) restartSyncer() string {
+	w.s.Downloader().Cancel()
+
+	select {
+	case w.s.scdoProtocol.syncCh <- struct{}{}:
+	default:
+	}
+
+	return "restart syncer"
+}
+
+func (w *watchdog) restartMiner() string {
+	if w.s.miner.IsStopperSet() {
+		return ""
+	}
+
+	w.s.miner.Restart()
+
+	return "restart miner"
+}
@@ -0,0 +1,70 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewWatchdog(t *testing.T) {
+	w := newWatchdog(nil, node.WatchdogConfig{})
+	assert.Equal(t, w.interval, defaultWatchdogInterval)
+	assert.Equal(t, w.maxHeadAge, defaultWatchdogMaxHeadAge)
+	assert.Equal(t, w.maxSyncDuration, defaultWatchdogMaxSyncDuration)
+	assert.Equal(t, w.minPeers, defaultWatchdogMinPeers)
+
+	w = newWatchdog(nil, node.WatchdogConfig{Interval: 5, MaxHeadAge: 60, MaxSyncDuration: 120, MinPeers: 3})
+	assert.Equal(t, w.interval, 5*time.Second)
+	assert.Equal(t, w.maxHeadAge, time.Minute)
+	assert.Equal(t, w.maxSyncDuration, 2*time.Minute)
+	assert.Equal(t, w.minPeers, 3)
+}
+
+func Test_Watchdog_DownloaderEvent(t *testing.T) {
+	w := newWatchdog(nil, node.WatchdogConfig{})
+
+	w.downloaderEventCallback(event.DownloaderStartEvent)
+	assert.Equal(t, w.syncStart.IsZero(), false)
+
+	w.downloaderEventCallback(event.DownloaderFailedEvent)
+	assert.Equal(t, w.syncStart.IsZero(), true)
+}
+
+func Test_Watchdog_Report(t *testing.T) {
+	var events []*WatchdogEvent
+	listener := func(e event.Event) {
+		events = append(events, e.(*WatchdogEvent))
+	}
+	event.WatchdogEventManager.AddListener(listener)
+	defer event.WatchdogEventManager.RemoveListener(listener)
+
+	w := newWatchdog(nil, node.WatchdogConfig{MaxHeadAge: 60})
+	taken := func() string { return "taken" }
+	skipped := func() string { return "" }
+
+	now := time.Now()
+	w.report(now, StallHead, "detail", taken, skipped)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0], &WatchdogEvent{Stall: StallHead, Detail: "detail", Actions: []string{"taken"}})
+
+	// reported in cooldown
+	w.report(now.Add(30*time.Second), StallHead, "detail", taken)
+	assert.Equal(t, len(events), 1)
+
+	// other kind of stall
+	w.report(now.Add(30*time.Second), StallPeers, "detail")
+	assert.Equal(t, len(events), 2)
+	assert.Equal(t, events[1].Actions, []string(nil))
+
+	// cooldown passed
+	w.report(now.Add(time.Minute), StallHead, "detail", taken)
+	assert.Equal(t, len(events), 3)
+}
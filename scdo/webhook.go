@@ -0,0 +1,463 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/event"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/metrics"
+	"github.com/scdoproject/go-scdo/node"
+)
+
+const (
+	defaultWebhookMaxRetries    = 5
+	defaultWebhookRetryInterval = time.Second
+	defaultWebhookTimeout       = 10 * time.Second
+
+	// maxWebhookRetryInterval is the max interval between the retries of a delivery
+	maxWebhookRetryInterval = 5 * time.Minute
+
+	// webhookQueueSize is the number of the events queued for an endpoint, the new events are dropped when full
+	webhookQueueSize = 1024
+
+	// maxWebhookBlocks is the max number of the new blocks posted when the head is changed
+	maxWebhookBlocks = 64
+)
+
+// chain events posted to the webhooks
+const (
+	WebhookEventNewBlock      = "newBlock"      // a new block becomes the chain head
+	WebhookEventReorg         = "reorg"         // the chain head is switched to another fork, or a deep reorg is refused
+	WebhookEventDebtConfirmed = "debtConfirmed" // a debt to another shard is confirmed in the local shard
+	WebhookEventBlockMined    = "blockMined"    // the local miner found a block
+)
+
+var webhookEvents = map[string]bool{
+	WebhookEventNewBlock:      true,
+	WebhookEventReorg:         true,
+	WebhookEventDebtConfirmed: true,
+	WebhookEventBlockMined:    true,
+}
+
+// HTTP headers of the webhook deliveries
+const (
+	WebhookHeaderEvent     = "X-Scdo-Event"
+	WebhookHeaderDelivery  = "X-Scdo-Delivery"
+	WebhookHeaderSignature = "X-Scdo-Signature" // "sha256=" and the hex HMAC-SHA256 of the body with the endpoint secret
+)
+
+// WebhookEvent is the JSON body posted to the webhooks
+type WebhookEvent struct {
+	ID    uint64      `json:"id"`    // delivery id, which is increased by every event of the node
+	Event string      `json:"event"` // name of the event
+	Time  int64       `json:"time"`  // unix time when the event is fired
+	Shard uint        `json:"shard"`
+	Data  interface{} `json:"data"`
+}
+
+// WebhookBlock is the data of the newBlock and blockMined events
+type WebhookBlock struct {
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Height     uint64         `json:"height"`
+	Creator    common.Address `json:"creator"`
+	Difficulty *big.Int       `json:"difficulty"`
+	Timestamp  uint64         `json:"timestamp"`
+	Txs        int            `json:"txs"`
+	Debts      int            `json:"debts"`
+}
+
+// WebhookReorg is the data of the reorg event
+type WebhookReorg struct {
+	OldHead        common.Hash `json:"oldHead"`
+	OldHeight      uint64      `json:"oldHeight"`
+	NewHead        common.Hash `json:"newHead"`
+	NewHeight      uint64      `json:"newHeight"`
+	CommonAncestor uint64      `json:"commonAncestor"`
+	Depth          uint64      `json:"depth"`   // number of the blocks removed from the canonical chain
+	Refused        bool        `json:"refused"` // whether the reorg is refused for the max reorg depth, the head is not changed then
+}
+
+// WebhookDebt is the data of the debtConfirmed event
+type WebhookDebt struct {
+	Hash        common.Hash    `json:"hash"`
+	TxHash      common.Hash    `json:"txHash"`
+	From        common.Address `json:"from"`
+	Account     common.Address `json:"account"`
+	Shard       uint           `json:"shard"` // target shard of the debt
+	Amount      *big.Int       `json:"amount"`
+	Price       *big.Int       `json:"price"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	BlockHeight uint64         `json:"blockHeight"`
+}
+
+// webhookChain is the chain whose events are posted to the webhooks
+type webhookChain interface {
+	CurrentBlock() *types.Block
+	GetStore() store.BlockchainStore
+	FindCommonForkAncestor(forkHeader, canonicalHeader *types.BlockHeader) (uint64, error)
+}
+
+type webhookEndpoint struct {
+	url    string
+	secret []byte
+	events map[string]bool // nil means all events
+	queue  chan *WebhookEvent
+}
+
+// webhookDispatcher posts the chain events as signed JSON to the webhook endpoints, so that the integrators
+// don't have to keep a websocket connection. Every endpoint has a queue and delivers the events in order,
+// a failed delivery is retried with exponential backoff and then dropped.
+type webhookDispatcher struct {
+	chain webhookChain
+	log   *log.ScdoLog
+
+	endpoints     []*webhookEndpoint
+	client        *http.Client
+	maxRetries    int
+	retryInterval time.Duration
+
+	nextID uint64
+
+	lock          sync.Mutex
+	head          *types.Block // the last head whose events are posted
+	confirmHeight uint64       // the last height whose debts are posted
+
+	quitCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newWebhookDispatcher(chain webhookChain, conf node.WebhookConfig, log *log.ScdoLog) (*webhookDispatcher, error) {
+	d := &webhookDispatcher{
+		chain:         chain,
+		log:           log,
+		client:        &http.Client{Timeout: durationOrDefault(conf.Timeout, defaultWebhookTimeout)},
+		maxRetries:    conf.MaxRetries,
+		retryInterval: durationOrDefault(conf.RetryInterval, defaultWebhookRetryInterval),
+		quitCh:        make(chan struct{}),
+	}
+
+	if d.maxRetries <= 0 {
+		d.maxRetries = defaultWebhookMaxRetries
+	}
+
+	for _, e := range conf.Endpoints {
+		if e.URL == "" {
+			return nil, fmt.Errorf("empty webhook url")
+		}
+
+		endpoint := &webhookEndpoint{
+			url:    e.URL,
+			secret: []byte(e.Secret),
+			queue:  make(chan *WebhookEvent, webhookQueueSize),
+		}
+
+		for _, name := range e.Events {
+			if !webhookEvents[name] {
+				return nil, fmt.Errorf("unknown webhook event %q of url %s", name, e.URL)
+			}
+
+			if endpoint.events == nil {
+				endpoint.events = make(map[string]bool)
+			}
+
+			endpoint.events[name] = true
+		}
+
+		d.endpoints = append(d.endpoints, endpoint)
+	}
+
+	return d, nil
+}
+
+func (d *webhookDispatcher) start() {
+	d.head = d.chain.CurrentBlock()
+	if d.head != nil && d.head.Header.Height > common.ConfirmedBlockNumber {
+		d.confirmHeight = d.head.Header.Height - common.ConfirmedBlockNumber
+	}
+
+	for _, endpoint := range d.endpoints {
+		d.wg.Add(1)
+		go d.loop(endpoint)
+	}
+
+	event.ChainHeaderChangedEventMananger.AddAsyncListener(d.chainHeaderChanged)
+	event.DeepReorgEventManager.AddAsyncListener(d.deepReorgRefused)
+	event.BlockMinedEventManager.AddAsyncListener(d.blockMined)
+}
+
+func (d *webhookDispatcher) stop() {
+	event.ChainHeaderChangedEventMananger.RemoveListener(d.chainHeaderChanged)
+	event.DeepReorgEventManager.RemoveListener(d.deepReorgRefused)
+	event.BlockMinedEventManager.RemoveListener(d.blockMined)
+
+	close(d.quitCh)
+	d.wg.Wait()
+}
+
+// chainHeaderChanged posts the new blocks, the reorg and the confirmed debts. The current HEAD is used
+// instead of the block of the event, since the async events may be handled out of order.
+func (d *webhookDispatcher) chainHeaderChanged(e event.Event) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	head := d.chain.CurrentBlock()
+	if head == nil || (d.head != nil && d.head.HeaderHash.Equal(head.HeaderHash)) {
+		return
+	}
+
+	from := d.head
+	d.head = head
+
+	ancestor := head.Header.Height - 1
+	if from != nil && !head.Header.PreviousBlockHash.Equal(from.HeaderHash) {
+		var err error
+		if ancestor, err = d.chain.FindCommonForkAncestor(from.Header, head.Header); err != nil {
+			d.log.Warn("failed to find common ancestor of blocks %v and %v for webhooks, %s", from.HeaderHash, head.HeaderHash, err)
+			ancestor = head.Header.Height - 1
+		} else if ancestor < from.Header.Height {
+			d.post(WebhookEventReorg, &WebhookReorg{
+				OldHead:        from.HeaderHash,
+				OldHeight:      from.Header.Height,
+				NewHead:        head.HeaderHash,
+				NewHeight:      head.Header.Height,
+				CommonAncestor: ancestor,
+				Depth:          from.Header.Height - ancestor,
+			})
+		}
+	}
+
+	blocks := d.newBlocks(head, ancestor)
+	for _, block := range blocks {
+		d.post(WebhookEventNewBlock, newWebhookBlock(block))
+	}
+
+	for _, block := range blocks {
+		d.postConfirmedDebts(block.Header.Height)
+	}
+}
+
+// newBlocks returns the blocks after the ancestor height to the new HEAD in ascending order,
+// at most the last maxWebhookBlocks blocks.
+func (d *webhookDispatcher) newBlocks(head *types.Block, ancestor uint64) []*types.Block {
+	blocks := []*types.Block{head}
+	for block := head; len(blocks) < maxWebhookBlocks && block.Header.Height > ancestor+1; {
+		parent, err := d.chain.GetStore().GetBlock(block.Header.PreviousBlockHash)
+		if err != nil {
+			d.log.Debug("failed to get block %v for webhooks, %s", block.Header.PreviousBlockHash, err)
+			break
+		}
+
+		blocks = append(blocks, parent)
+		block = parent
+	}
+
+	// reverse to ascending order
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+
+	return blocks
+}
+
+// postConfirmedDebts posts the debts of the block confirmed by the new block at the height
+func (d *webhookDispatcher) postConfirmedDebts(height uint64) {
+	if height <= common.ConfirmedBlockNumber || height-common.ConfirmedBlockNumber <= d.confirmHeight {
+		return
+	}
+
+	d.confirmHeight = height - common.ConfirmedBlockNumber
+	if d.confirmHeight < common.ScdoForkHeight {
+		return
+	}
+
+	block, err := d.chain.GetStore().GetBlockByHeight(d.confirmHeight)
+	if err != nil {
+		d.log.Warn("failed to get confirmed block %d for webhooks, %s", d.confirmHeight, err)
+		return
+	}
+
+	for shard, debts := range types.NewDebtMap(block.Transactions) {
+		for _, debt := range debts {
+			d.post(WebhookEventDebtConfirmed, &WebhookDebt{
+				Hash:        debt.Hash,
+				TxHash:      debt.Data.TxHash,
+				From:        debt.Data.From,
+				Account:     debt.Data.Account,
+				Shard:       uint(shard),
+				Amount:      debt.Data.Amount,
+				Price:       debt.Data.Price,
+				BlockHash:   block.HeaderHash,
+				BlockHeight: block.Header.Height,
+			})
+		}
+	}
+}
+
+func (d *webhookDispatcher) deepReorgRefused(e event.Event) {
+	reorg := e.(*core.DeepReorgEvent)
+
+	var head common.Hash
+	if current := d.chain.CurrentBlock(); current != nil {
+		head = current.HeaderHash
+	}
+
+	d.post(WebhookEventReorg, &WebhookReorg{
+		OldHead:        head,
+		OldHeight:      reorg.HeadHeight,
+		NewHead:        reorg.BlockHash,
+		NewHeight:      reorg.BlockHeight,
+		CommonAncestor: reorg.CommonAncestor,
+		Depth:          reorg.Depth,
+		Refused:        true,
+	})
+}
+
+func (d *webhookDispatcher) blockMined(e event.Event) {
+	d.post(WebhookEventBlockMined, newWebhookBlock(e.(*types.Block)))
+}
+
+func newWebhookBlock(block *types.Block) *WebhookBlock {
+	return &WebhookBlock{
+		Hash:       block.HeaderHash,
+		ParentHash: block.Header.PreviousBlockHash,
+		Height:     block.Header.Height,
+		Creator:    block.Header.Creator,
+		Difficulty: block.Header.Difficulty,
+		Timestamp:  block.Header.CreateTimestamp.Uint64(),
+		Txs:        len(block.Transactions),
+		Debts:      len(block.Debts),
+	}
+}
+
+// post queues the event to the endpoints which subscribe it
+func (d *webhookDispatcher) post(name string, data interface{}) {
+	e := &WebhookEvent{
+		ID:    atomic.AddUint64(&d.nextID, 1),
+		Event: name,
+		Time:  time.Now().Unix(),
+		Shard: common.LocalShardNumber,
+		Data:  data,
+	}
+
+	for _, endpoint := range d.endpoints {
+		if endpoint.events != nil && !endpoint.events[name] {
+			continue
+		}
+
+		select {
+		case endpoint.queue <- e:
+		default:
+			d.log.Warn("webhook queue of %s is full, drop %s event %d", endpoint.url, name, e.ID)
+			metrics.MetricsWebhookFailureMeter.Mark(1)
+		}
+	}
+}
+
+func (d *webhookDispatcher) loop(endpoint *webhookEndpoint) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case e := <-endpoint.queue:
+			d.deliver(endpoint, e)
+		case <-d.quitCh:
+			return
+		}
+	}
+}
+
+// deliver posts the event to the endpoint, and retries with exponential backoff if failed
+func (d *webhookDispatcher) deliver(endpoint *webhookEndpoint, e *WebhookEvent) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		d.log.Error("failed to encode %s event %d for webhooks, %s", e.Event, e.ID, err)
+		return
+	}
+
+	interval := d.retryInterval
+	for retry := 0; ; retry++ {
+		if err = d.send(endpoint, e, body); err == nil {
+			metrics.MetricsWebhookDeliveryMeter.Mark(1)
+			return
+		}
+
+		if retry >= d.maxRetries {
+			break
+		}
+
+		d.log.Debug("failed to post %s event %d to %s, retry in %v, %s", e.Event, e.ID, endpoint.url, interval, err)
+
+		select {
+		case <-time.After(interval):
+		case <-d.quitCh:
+			return
+		}
+
+		if interval *= 2; interval > maxWebhookRetryInterval {
+			interval = maxWebhookRetryInterval
+		}
+	}
+
+	d.log.Warn("failed to post %s event %d to %s after %d retries, %s", e.Event, e.ID, endpoint.url, d.maxRetries, err)
+	metrics.MetricsWebhookFailureMeter.Mark(1)
+}
+
+func (d *webhookDispatcher) send(endpoint *webhookEndpoint, e *WebhookEvent, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderEvent, e.Event)
+	req.Header.Set(WebhookHeaderDelivery, fmt.Sprint(e.ID))
+	if len(endpoint.secret) > 0 {
+		req.Header.Set(WebhookHeaderSignature, WebhookSignature(endpoint.secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	// drain the body to reuse the connection
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// WebhookSignature returns the signature of the webhook body with the secret, which is set in
+// the X-Scdo-Signature header for the receivers to verify the deliveries.
+func WebhookSignature(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
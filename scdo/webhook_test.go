@@ -0,0 +1,201 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package scdo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/core/store"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/database/memorydb"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWebhookChain struct {
+	bcStore  store.BlockchainStore
+	head     *types.Block
+	ancestor uint64
+}
+
+func (c *mockWebhookChain) CurrentBlock() *types.Block      { return c.head }
+func (c *mockWebhookChain) GetStore() store.BlockchainStore { return c.bcStore }
+func (c *mockWebhookChain) FindCommonForkAncestor(forkHeader, canonicalHeader *types.BlockHeader) (uint64, error) {
+	return c.ancestor, nil
+}
+
+func (c *mockWebhookChain) putBlock(t *testing.T, parent *types.Block, extra string) *types.Block {
+	header := &types.BlockHeader{
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(1),
+		ExtraData:       []byte(extra),
+	}
+
+	if parent != nil {
+		header.PreviousBlockHash = parent.HeaderHash
+		header.Height = parent.Header.Height + 1
+		header.CreateTimestamp = big.NewInt(parent.Header.CreateTimestamp.Int64() + 1)
+	}
+
+	block := types.NewBlock(header, nil, nil, nil)
+	assert.Equal(t, c.bcStore.PutBlock(block, big.NewInt(int64(header.Height+1)), true), nil)
+
+	return block
+}
+
+type webhookDelivery struct {
+	header http.Header
+	body   []byte
+	event  *WebhookEvent
+}
+
+// newWebhookServer returns a server which fails the first failures requests
+func newWebhookServer(failures int32) (*httptest.Server, chan *webhookDelivery) {
+	deliveries := make(chan *webhookDelivery, 100)
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		e := new(WebhookEvent)
+		json.Unmarshal(body, e)
+		deliveries <- &webhookDelivery{r.Header, body, e}
+	}))
+
+	return server, deliveries
+}
+
+func receiveWebhook(t *testing.T, deliveries chan *webhookDelivery) *webhookDelivery {
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+		return nil
+	}
+}
+
+func Test_WebhookDispatcher_Blocks(t *testing.T) {
+	db, dispose := memorydb.NewTestDatabase()
+	defer dispose()
+
+	server, deliveries := newWebhookServer(0)
+	defer server.Close()
+
+	chain := &mockWebhookChain{bcStore: store.NewBlockchainDatabase(db)}
+	genesis := chain.putBlock(t, nil, "")
+	chain.head = genesis
+
+	conf := node.WebhookConfig{Endpoints: []node.WebhookEndpoint{{URL: server.URL, Secret: "secret"}}}
+	d, err := newWebhookDispatcher(chain, conf, log.GetLogger("webhook"))
+	assert.Equal(t, err, nil)
+	d.start()
+	defer d.stop()
+
+	// new blocks in order
+	b1 := chain.putBlock(t, genesis, "")
+	b2 := chain.putBlock(t, b1, "")
+	chain.head = b2
+	d.chainHeaderChanged(b2)
+
+	for _, block := range []*types.Block{b1, b2} {
+		delivery := receiveWebhook(t, deliveries)
+		assert.Equal(t, delivery.event.Event, WebhookEventNewBlock)
+		assert.Equal(t, delivery.header.Get(WebhookHeaderEvent), WebhookEventNewBlock)
+		assert.Equal(t, delivery.header.Get(WebhookHeaderSignature), WebhookSignature([]byte("secret"), delivery.body))
+
+		data := delivery.event.Data.(map[string]interface{})
+		assert.Equal(t, data["hash"], block.HeaderHash.Hex())
+		assert.Equal(t, data["height"], float64(block.Header.Height))
+	}
+
+	// reorg to the fork of b1
+	c2 := chain.putBlock(t, b1, "fork")
+	c3 := chain.putBlock(t, c2, "fork")
+	chain.head, chain.ancestor = c3, 1
+	d.chainHeaderChanged(c3)
+
+	delivery := receiveWebhook(t, deliveries)
+	assert.Equal(t, delivery.event.Event, WebhookEventReorg)
+	data := delivery.event.Data.(map[string]interface{})
+	assert.Equal(t, data["oldHead"], b2.HeaderHash.Hex())
+	assert.Equal(t, data["newHead"], c3.HeaderHash.Hex())
+	assert.Equal(t, data["depth"], float64(1))
+	assert.Equal(t, data["refused"], false)
+
+	for _, block := range []*types.Block{c2, c3} {
+		delivery = receiveWebhook(t, deliveries)
+		assert.Equal(t, delivery.event.Event, WebhookEventNewBlock)
+		assert.Equal(t, delivery.event.Data.(map[string]interface{})["hash"], block.HeaderHash.Hex())
+	}
+
+	// the same head is not posted again
+	d.chainHeaderChanged(c3)
+	select {
+	case <-deliveries:
+		t.Fatal("unexpected webhook delivery")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func Test_WebhookDispatcher_Events(t *testing.T) {
+	server, deliveries := newWebhookServer(0)
+	defer server.Close()
+
+	chain := &mockWebhookChain{}
+	conf := node.WebhookConfig{Endpoints: []node.WebhookEndpoint{{URL: server.URL, Events: []string{WebhookEventReorg}}}}
+	d, err := newWebhookDispatcher(chain, conf, log.GetLogger("webhook"))
+	assert.Equal(t, err, nil)
+	d.start()
+	defer d.stop()
+
+	// the unsubscribed event is not posted
+	d.blockMined(types.NewBlock(&types.BlockHeader{Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}, nil, nil, nil))
+	d.deepReorgRefused(&core.DeepReorgEvent{BlockHeight: 100, HeadHeight: 90, CommonAncestor: 10, Depth: 80})
+
+	delivery := receiveWebhook(t, deliveries)
+	assert.Equal(t, delivery.event.Event, WebhookEventReorg)
+	assert.Equal(t, delivery.header.Get(WebhookHeaderSignature), "")
+
+	data := delivery.event.Data.(map[string]interface{})
+	assert.Equal(t, data["depth"], float64(80))
+	assert.Equal(t, data["refused"], true)
+
+	// unknown event
+	conf.Endpoints[0].Events = []string{"newTx"}
+	_, err = newWebhookDispatcher(chain, conf, log.GetLogger("webhook"))
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_WebhookDispatcher_Retry(t *testing.T) {
+	server, deliveries := newWebhookServer(2)
+	defer server.Close()
+
+	conf := node.WebhookConfig{Endpoints: []node.WebhookEndpoint{{URL: server.URL}}, MaxRetries: 2}
+	d, err := newWebhookDispatcher(&mockWebhookChain{}, conf, log.GetLogger("webhook"))
+	assert.Equal(t, err, nil)
+	d.retryInterval = 10 * time.Millisecond
+	d.start()
+	defer d.stop()
+
+	d.post(WebhookEventBlockMined, nil)
+	delivery := receiveWebhook(t, deliveries)
+	assert.Equal(t, delivery.event.Event, WebhookEventBlockMined)
+	assert.Equal(t, delivery.header.Get(WebhookHeaderDelivery), "1")
+}
@@ -0,0 +1,304 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+// Package sdk provides a typed Go client of the scdo nodes, which routes the requests to the node of the
+// account shard, and retries the requests on connection failures.
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/rpc"
+)
+
+var (
+	// ErrNoEndpoint is returned when no endpoint is configured
+	ErrNoEndpoint = errors.New("no endpoint")
+
+	// ErrShardNotFound is returned when no endpoint of the shard is configured
+	ErrShardNotFound = errors.New("no endpoint of the shard")
+)
+
+// Config is the configuration of the client
+type Config struct {
+	// Endpoints are the rpc addresses of the nodes, tcp address like 127.0.0.1:8027 or http, ws and ipc urls.
+	// The shard of each node is queried on dial, so that the requests are routed to the node of the shard.
+	Endpoints []string
+
+	// Retries is the max number of retries of a request on connection failures
+	Retries int
+
+	// RetryInterval is the interval between retries
+	RetryInterval time.Duration
+
+	// PollInterval is the interval to poll the new block headers of the subscription
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns the default configuration of the client with the endpoints
+func DefaultConfig(endpoints ...string) *Config {
+	return &Config{
+		Endpoints:     endpoints,
+		Retries:       3,
+		RetryInterval: 500 * time.Millisecond,
+		PollInterval:  time.Second,
+	}
+}
+
+// Client is the client of the scdo nodes of one or more shards, which is safe for concurrent use.
+type Client struct {
+	conf   Config
+	shards map[uint][]*rpc.Client
+	order  []uint // shards in the order of endpoints
+
+	lock sync.Mutex
+	next map[uint]int // the endpoint index of the shard to use next
+}
+
+// Dial connects to the nodes of the config, and queries the shard of each node
+func Dial(ctx context.Context, conf *Config) (*Client, error) {
+	if len(conf.Endpoints) == 0 {
+		return nil, ErrNoEndpoint
+	}
+
+	var clients []*rpc.Client
+	for _, endpoint := range conf.Endpoints {
+		client, err := dialEndpoint(ctx, endpoint)
+		if err != nil {
+			closeClients(clients)
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	c, err := newClient(ctx, conf, clients)
+	if err != nil {
+		closeClients(clients)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func dialEndpoint(ctx context.Context, endpoint string) (*rpc.Client, error) {
+	if strings.Contains(endpoint, "://") {
+		return rpc.DialContext(ctx, endpoint)
+	}
+
+	return rpc.DialTCP(ctx, endpoint)
+}
+
+func closeClients(clients []*rpc.Client) {
+	for _, client := range clients {
+		client.Close()
+	}
+}
+
+// newClient creates the client with the connected rpc clients in the order of endpoints
+func newClient(ctx context.Context, conf *Config, clients []*rpc.Client) (*Client, error) {
+	c := &Client{
+		conf:   *conf,
+		shards: make(map[uint][]*rpc.Client),
+		next:   make(map[uint]int),
+	}
+
+	for _, client := range clients {
+		var info struct {
+			Shard uint
+		}
+
+		if err := c.retry(ctx, func(int) error { return client.CallContext(ctx, &info, "scdo_getInfo") }); err != nil {
+			return nil, err
+		}
+
+		if _, ok := c.shards[info.Shard]; !ok {
+			c.order = append(c.order, info.Shard)
+		}
+
+		c.shards[info.Shard] = append(c.shards[info.Shard], client)
+	}
+
+	return c, nil
+}
+
+// Close closes the connections to all the nodes
+func (c *Client) Close() {
+	for _, clients := range c.shards {
+		closeClients(clients)
+	}
+}
+
+// Shards returns the shards of the nodes in the order of endpoints
+func (c *Client) Shards() []uint {
+	return append([]uint(nil), c.order...)
+}
+
+// Call calls the rpc method on the node of the shard, and retries on connection failures,
+// with the next node of the shard if any.
+func (c *Client) Call(ctx context.Context, shard uint, result interface{}, method string, args ...interface{}) error {
+	clients := c.shards[shard]
+	if len(clients) == 0 {
+		return ErrShardNotFound
+	}
+
+	c.lock.Lock()
+	start := c.next[shard]
+	c.lock.Unlock()
+
+	return c.retry(ctx, func(attempt int) error {
+		index := (start + attempt) % len(clients)
+
+		err := clients[index].CallContext(ctx, result, method, args...)
+		if retryable(err) {
+			// use the next node of the shard from now on
+			c.lock.Lock()
+			c.next[shard] = (index + 1) % len(clients)
+			c.lock.Unlock()
+		}
+
+		return err
+	})
+}
+
+// retry calls fn until it succeeds, fails without retry, or the retries are used up
+func (c *Client) retry(ctx context.Context, fn func(attempt int) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || !retryable(err) || attempt >= c.conf.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.conf.RetryInterval):
+		}
+	}
+}
+
+// retryable returns whether the error is caused by the connection rather than the node
+func retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if _, ok := err.(rpc.Error); ok {
+		return false
+	}
+
+	return err != context.Canceled && err != context.DeadlineExceeded && err != rpc.ErrClientQuit
+}
+
+// GetBalance returns the balance of the account at the HEAD block
+func (c *Client) GetBalance(ctx context.Context, account common.Address) (*big.Int, error) {
+	var result struct {
+		Balance *big.Int
+	}
+
+	if err := c.Call(ctx, account.Shard(), &result, "scdo_getBalance", account, "", -1); err != nil {
+		return nil, err
+	}
+
+	return result.Balance, nil
+}
+
+// GetAccountNonce returns the nonce of the account at the HEAD block
+func (c *Client) GetAccountNonce(ctx context.Context, account common.Address) (uint64, error) {
+	var nonce uint64
+	err := c.Call(ctx, account.Shard(), &nonce, "scdo_getAccountNonce", account, "", -1)
+
+	return nonce, err
+}
+
+// SendTransaction sends the signed transaction to the node of the sender shard. Note, the transaction
+// may be already added if the response is lost, and the retry fails as the transaction exists.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	var result bool
+	return c.Call(ctx, tx.Data.From.Shard(), &result, "scdo_addTx", *tx)
+}
+
+// GetBlockHeight returns the HEAD block height of the shard
+func (c *Client) GetBlockHeight(ctx context.Context, shard uint) (uint64, error) {
+	var height uint64
+	err := c.Call(ctx, shard, &height, "scdo_getBlockHeight")
+
+	return height, err
+}
+
+// GetRawBlocks returns at most size canonical blocks of the shard from the height, with the txs and debts
+// which could be validated and imported by the node of the shard.
+func (c *Client) GetRawBlocks(ctx context.Context, shard uint, height uint64, size uint) ([]*types.Block, error) {
+	var blocks []*types.Block
+	err := c.Call(ctx, shard, &blocks, "scdo_getRawBlocks", height, size)
+
+	return blocks, err
+}
+
+// Header is the block header with hash and total difficulty
+type Header struct {
+	Hash              common.Hash
+	Height            uint64
+	PreviousBlockHash common.Hash
+	Creator           common.Address
+	CreateTimestamp   *big.Int
+	Difficulty        *big.Int
+	TotalDifficulty   *big.Int
+	StateHash         common.Hash
+	TxHash            common.Hash
+	ReceiptHash       common.Hash
+	TxDebtHash        common.Hash
+	DebtHash          common.Hash
+}
+
+// rpcBlock is the block returned by the rpc without txs and debts
+type rpcBlock struct {
+	Hash   common.Hash `json:"hash"`
+	Header struct {
+		Height            uint64
+		PreviousBlockHash common.Hash
+		Creator           common.Address
+		CreateTimestamp   *big.Int
+		Difficulty        *big.Int
+		StateHash         common.Hash
+		TxHash            common.Hash
+		ReceiptHash       common.Hash
+		TxDebtHash        common.Hash
+		DebtHash          common.Hash
+	} `json:"header"`
+	TotalDifficulty *big.Int `json:"totalDifficulty"`
+}
+
+// GetHeaderByHeight returns the block header of the shard by height, or the HEAD block header if height is -1
+func (c *Client) GetHeaderByHeight(ctx context.Context, shard uint, height int64) (*Header, error) {
+	var block rpcBlock
+	if err := c.Call(ctx, shard, &block, "scdo_getBlockByHeight", height, false); err != nil {
+		return nil, err
+	}
+
+	h := block.Header
+
+	return &Header{
+		Hash:              block.Hash,
+		Height:            h.Height,
+		PreviousBlockHash: h.PreviousBlockHash,
+		Creator:           h.Creator,
+		CreateTimestamp:   h.CreateTimestamp,
+		Difficulty:        h.Difficulty,
+		TotalDifficulty:   block.TotalDifficulty,
+		StateHash:         h.StateHash,
+		TxHash:            h.TxHash,
+		ReceiptHash:       h.ReceiptHash,
+		TxDebtHash:        h.TxDebtHash,
+		DebtHash:          h.DebtHash,
+	}, nil
+}
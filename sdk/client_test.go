@@ -0,0 +1,277 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestService is a fake scdo service of a shard
+type TestService struct {
+	shard uint
+
+	lock    sync.Mutex
+	headers []*types.BlockHeader
+	txs     []*types.Transaction
+}
+
+func newTestService(shard uint) *TestService {
+	s := &TestService{shard: shard}
+	s.addBlock()
+
+	return s
+}
+
+func (s *TestService) addBlock() *types.BlockHeader {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	header := &types.BlockHeader{
+		Height:          uint64(len(s.headers)),
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(time.Now().Unix()),
+	}
+
+	if len(s.headers) > 0 {
+		header.PreviousBlockHash = s.headers[len(s.headers)-1].Hash()
+	}
+
+	s.headers = append(s.headers, header)
+
+	return header
+}
+
+func (s *TestService) GetInfo() (map[string]interface{}, error) {
+	return map[string]interface{}{"Shard": s.shard}, nil
+}
+
+func (s *TestService) GetBalance(account common.Address, hexHash string, height int64) (map[string]interface{}, error) {
+	if account.Shard() != s.shard {
+		return nil, errors.New("wrong shard")
+	}
+
+	return map[string]interface{}{"Balance": big.NewInt(int64(s.shard) * 100), "Account": account.Hex()}, nil
+}
+
+func (s *TestService) GetAccountNonce(account common.Address, hexHash string, height int64) (uint64, error) {
+	return uint64(s.shard), nil
+}
+
+func (s *TestService) AddTx(tx types.Transaction) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.txs = append(s.txs, &tx)
+
+	return true, nil
+}
+
+func (s *TestService) GetBlockHeight() (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return uint64(len(s.headers) - 1), nil
+}
+
+func (s *TestService) GetBlockByHeight(height int64, fulltx bool) (map[string]interface{}, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if height < 0 {
+		height = int64(len(s.headers) - 1)
+	}
+
+	if height >= int64(len(s.headers)) {
+		return nil, errors.New("block not found")
+	}
+
+	header := s.headers[height]
+
+	return map[string]interface{}{
+		"hash": header.Hash().Hex(),
+		"header": map[string]interface{}{
+			"Height":            header.Height,
+			"PreviousBlockHash": header.PreviousBlockHash,
+			"Difficulty":        header.Difficulty,
+			"CreateTimestamp":   header.CreateTimestamp,
+		},
+		"totalDifficulty": big.NewInt(height + 1),
+	}, nil
+}
+
+func (s *TestService) GetRawBlocks(height uint64, size uint) ([]*types.Block, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var blocks []*types.Block
+	for h := height; h < uint64(len(s.headers)) && len(blocks) < int(size); h++ {
+		blocks = append(blocks, types.NewBlock(s.headers[h], s.txs, nil, nil))
+	}
+
+	return blocks, nil
+}
+
+func newTestClient(t *testing.T, services ...*TestService) *Client {
+	var clients []*rpc.Client
+	for _, s := range services {
+		server := rpc.NewServer()
+		assert.Equal(t, server.RegisterName("scdo", s), nil)
+		clients = append(clients, rpc.DialInProc(server))
+	}
+
+	conf := DefaultConfig()
+	conf.PollInterval = 10 * time.Millisecond
+
+	c, err := newClient(context.Background(), conf, clients)
+	assert.Equal(t, err, nil)
+
+	return c
+}
+
+func Test_Client_ShardRouting(t *testing.T) {
+	s1, s2 := newTestService(1), newTestService(2)
+	c := newTestClient(t, s1, s2)
+	defer c.Close()
+
+	assert.Equal(t, c.Shards(), []uint{1, 2})
+
+	ctx := context.Background()
+	for _, shard := range []uint{1, 2} {
+		account := *crypto.MustGenerateShardAddress(shard)
+
+		balance, err := c.GetBalance(ctx, account)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, balance, big.NewInt(int64(shard)*100))
+
+		nonce, err := c.GetAccountNonce(ctx, account)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, nonce, uint64(shard))
+	}
+
+	_, err := c.GetBlockHeight(ctx, 3)
+	assert.Equal(t, err, ErrShardNotFound)
+}
+
+func Test_Client_SendTransaction(t *testing.T) {
+	s1, s2 := newTestService(1), newTestService(2)
+	c := newTestClient(t, s1, s2)
+	defer c.Close()
+
+	from, key := crypto.MustGenerateShardKeyPair(2)
+	tx, err := types.NewTransaction(*from, *crypto.MustGenerateShardAddress(1), big.NewInt(1), big.NewInt(1), 1)
+	assert.Equal(t, err, nil)
+	tx.Sign(key)
+
+	assert.Equal(t, c.SendTransaction(context.Background(), tx), nil)
+	assert.Equal(t, len(s1.txs), 0)
+	assert.Equal(t, len(s2.txs), 1)
+	assert.Equal(t, s2.txs[0].Hash, tx.Hash)
+}
+
+func Test_Client_GetRawBlocks(t *testing.T) {
+	s := newTestService(1)
+	s.addBlock()
+	s.addBlock()
+	c := newTestClient(t, s)
+	defer c.Close()
+
+	from, key := crypto.MustGenerateShardKeyPair(1)
+	tx, err := types.NewTransaction(*from, *crypto.MustGenerateShardAddress(1), big.NewInt(1), big.NewInt(1), 1)
+	assert.Equal(t, err, nil)
+	tx.Sign(key)
+	s.txs = []*types.Transaction{tx}
+
+	blocks, err := c.GetRawBlocks(context.Background(), 1, 1, 5)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(blocks), 2)
+
+	for i, block := range blocks {
+		expected := types.NewBlock(s.headers[i+1], s.txs, nil, nil)
+		assert.Equal(t, block.HeaderHash, expected.HeaderHash)
+		assert.Equal(t, block.Header, expected.Header)
+		assert.Equal(t, len(block.Transactions), 1)
+		assert.Equal(t, block.Transactions[0].Hash, tx.Hash)
+		assert.Equal(t, block.Validate(), nil)
+	}
+}
+
+func Test_Client_SubscribeNewHeads(t *testing.T) {
+	s := newTestService(1)
+	c := newTestClient(t, s)
+	defer c.Close()
+
+	ctx := context.Background()
+	head, err := c.GetHeaderByHeight(ctx, 1, -1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, head.Height, uint64(0))
+	assert.Equal(t, head.TotalDifficulty, big.NewInt(1))
+
+	ch := make(chan *Header)
+	sub, err := c.SubscribeNewHeads(ctx, 1, ch)
+	assert.Equal(t, err, nil)
+
+	// the skipped block is delivered
+	h1 := s.addBlock()
+	h2 := s.addBlock()
+
+	header := <-ch
+	assert.Equal(t, header.Hash, h1.Hash())
+	assert.Equal(t, header.PreviousBlockHash, head.Hash)
+
+	header = <-ch
+	assert.Equal(t, header.Hash, h2.Hash())
+	assert.Equal(t, header.Height, uint64(2))
+
+	sub.Unsubscribe()
+	_, ok := <-sub.Err()
+	assert.Equal(t, ok, false)
+}
+
+func Test_Client_Retry(t *testing.T) {
+	c := &Client{conf: Config{Retries: 2}}
+	ctx := context.Background()
+
+	// retry on connection errors
+	attempts := 0
+	err := c.retry(ctx, func(int) error {
+		attempts++
+		return errors.New("connection refused")
+	})
+	assert.Equal(t, err.Error(), "connection refused")
+	assert.Equal(t, attempts, 3)
+
+	attempts = 0
+	err = c.retry(ctx, func(attempt int) error {
+		attempts++
+		if attempt == 0 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempts, 2)
+
+	// no retry if the node returns an error
+	s := newTestService(1)
+	c = newTestClient(t, s)
+	defer c.Close()
+
+	_, err = c.GetHeaderByHeight(ctx, 1, 10)
+	assert.Equal(t, err.Error(), "block not found")
+	assert.Equal(t, retryable(err), false)
+	assert.Equal(t, retryable(context.Canceled), false)
+}
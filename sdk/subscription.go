@@ -0,0 +1,110 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package sdk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HeadSubscription is the subscription of the new block headers of a shard
+type HeadSubscription struct {
+	err      chan error
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+// Err returns the channel which receives the error that terminates the subscription,
+// and is closed when the subscription ends.
+func (sub *HeadSubscription) Err() <-chan error {
+	return sub.err
+}
+
+// Unsubscribe stops delivering the new block headers
+func (sub *HeadSubscription) Unsubscribe() {
+	sub.quitOnce.Do(func() {
+		close(sub.quit)
+	})
+}
+
+// SubscribeNewHeads delivers the new HEAD block headers of the shard to ch, which polls the node
+// as the node has no push notification. The skipped blocks are delivered in order if the HEAD
+// grows more than one block in a poll, and the new HEAD is delivered on fork.
+func (c *Client) SubscribeNewHeads(ctx context.Context, shard uint, ch chan<- *Header) (*HeadSubscription, error) {
+	head, err := c.GetHeaderByHeight(ctx, shard, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &HeadSubscription{
+		err:  make(chan error, 1),
+		quit: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(sub.err)
+
+		if err := c.pollNewHeads(ctx, shard, head, ch, sub.quit); err != nil {
+			sub.err <- err
+		}
+	}()
+
+	return sub, nil
+}
+
+// pollNewHeads polls the HEAD until quit, and returns the error if any
+func (c *Client) pollNewHeads(ctx context.Context, shard uint, last *Header, ch chan<- *Header, quit chan struct{}) error {
+	ticker := time.NewTicker(c.conf.PollInterval)
+	defer ticker.Stop()
+
+	deliver := func(header *Header) bool {
+		select {
+		case ch <- header:
+			last = header
+			return true
+		case <-quit:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		head, err := c.GetHeaderByHeight(ctx, shard, -1)
+		if err != nil {
+			return err
+		}
+
+		if head.Hash.Equal(last.Hash) {
+			continue
+		}
+
+		// deliver the skipped blocks in order
+		for height := last.Height + 1; height < head.Height; height++ {
+			header, err := c.GetHeaderByHeight(ctx, shard, int64(height))
+			if err != nil {
+				return err
+			}
+
+			if !deliver(header) {
+				return ctx.Err()
+			}
+		}
+
+		if !deliver(head) {
+			return ctx.Err()
+		}
+	}
+}
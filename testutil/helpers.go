@@ -0,0 +1,154 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package testutil
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/core/types"
+)
+
+// pollInterval is the interval to check whether the nodes are synced
+const pollInterval = 20 * time.Millisecond
+
+// defaultGasPrice is the gas price of the txs sent in the network
+var defaultGasPrice = big.NewInt(1)
+
+// MineBlocks mines the blocks of the pending txs and debts one by one on the first node, and waits for
+// all the nodes to sync the blocks.
+func (net *Network) MineBlocks(count int) []*types.Block {
+	var blocks []*types.Block
+	for i := 0; i < count; i++ {
+		block, err := net.Nodes[0].Service.Miner().MineBlock()
+		if err != nil {
+			net.t.Fatalf("failed to mine block, %s", err)
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	if len(blocks) > 0 {
+		net.WaitForBlock(blocks[len(blocks)-1])
+	}
+
+	return blocks
+}
+
+// WaitForBlock waits for all the nodes to sync the block as the HEAD, or a descendant of it
+func (net *Network) WaitForBlock(block *types.Block) {
+	deadline := time.Now().Add(net.timeout)
+	for i, n := range net.Nodes {
+		for {
+			chain := n.Service.BlockChain()
+			if chain.CurrentHeader().Height >= block.Header.Height &&
+				chain.GetHeaderByHeight(block.Header.Height).Hash() == block.HeaderHash {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				net.t.Fatalf("node %d does not sync block %d %s in %s", i, block.Header.Height, block.HeaderHash.Hex(), net.timeout)
+			}
+
+			time.Sleep(pollInterval)
+		}
+	}
+}
+
+// SendTx signs and sends a tx of the amount from the account to the address via the first node
+func (net *Network) SendTx(from *Account, to common.Address, amount *big.Int) *types.Transaction {
+	tx, err := types.NewTransaction(from.Address, to, amount, defaultGasPrice, from.Nonce)
+	if err != nil {
+		net.t.Fatalf("failed to create tx, %s", err)
+	}
+
+	tx.Sign(from.Key)
+	if err = net.Nodes[0].Service.TxPool().AddTransaction(tx); err != nil {
+		net.t.Fatalf("failed to send tx %s, %s", tx.Hash.Hex(), err)
+	}
+
+	from.Nonce++
+
+	return tx
+}
+
+// SendCrossShardTx sends a tx of the amount from the account to a new account in the shard, and returns
+// the tx and the debt to settle in the target shard once the tx is packed.
+func (net *Network) SendCrossShardTx(from *Account, shard uint, amount *big.Int) (*types.Transaction, *types.Debt) {
+	if shard == net.Shard {
+		net.t.Fatalf("shard %d is not a different shard", shard)
+	}
+
+	to, _ := NewShardAccount(net.t, shard)
+	tx := net.SendTx(from, to, amount)
+
+	return tx, types.NewDebtWithContext(tx)
+}
+
+// Debts returns the debts of the cross shard txs packed in the blocks, which are settled in the
+// target shards, see SettleDebts.
+func (net *Network) Debts(blocks ...*types.Block) []*types.Debt {
+	var debts []*types.Debt
+	for _, block := range blocks {
+		debts = append(debts, types.NewDebts(block.Transactions)...)
+	}
+
+	return debts
+}
+
+// SettleDebts adds the debts from the other shards into the debt pools of the nodes, and mines a block
+// to pack them. The debts are not verified in the source shard, which does not run in the meantime.
+func (net *Network) SettleDebts(debts []*types.Debt) *types.Block {
+	for _, n := range net.Nodes {
+		pool := n.Service.DebtPool()
+		for _, d := range debts {
+			if err := pool.AddDebt(d); err != nil {
+				net.t.Fatalf("failed to add debt %s, %s", d.Hash.Hex(), err)
+			}
+		}
+
+		if err := pool.DoMulCheckingDebt(); err != nil {
+			net.t.Fatalf("failed to check debts, %s", err)
+		}
+	}
+
+	return net.MineBlocks(1)[0]
+}
+
+// AssertDebtSettled asserts that the debt is packed in the canonical chain of every node, and returns
+// the block which packs it.
+func (net *Network) AssertDebtSettled(debt *types.Debt) common.Hash {
+	var blockHash common.Hash
+	for i, n := range net.Nodes {
+		index, err := n.Service.BlockChain().GetStore().GetDebtIndex(debt.Hash)
+		if err != nil || index == nil {
+			net.t.Fatalf("debt %s is not settled on node %d, %v", debt.Hash.Hex(), i, err)
+		}
+
+		if i > 0 && index.BlockHash != blockHash {
+			net.t.Fatalf("debt %s is settled in different blocks %s and %s", debt.Hash.Hex(), blockHash.Hex(), index.BlockHash.Hex())
+		}
+
+		blockHash = index.BlockHash
+	}
+
+	return blockHash
+}
+
+// AssertBalance asserts the balance of the account in the HEAD state of every node
+func (net *Network) AssertBalance(addr common.Address, expected *big.Int) {
+	for i, n := range net.Nodes {
+		statedb, err := n.Service.BlockChain().GetCurrentState()
+		if err != nil {
+			net.t.Fatalf("failed to get the state of node %d, %s", i, err)
+		}
+
+		if balance := statedb.GetBalance(addr); balance.Cmp(expected) != 0 {
+			net.t.Fatalf("unexpected balance of %s on node %d, have %s, expected %s", addr.Hex(), i, balance, expected)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+// Package testutil spins up the in-process networks of the full nodes for the integration tests, so
+// that the tests of the new features need not wire the services, pools and peers by hand.
+//
+// The nodes of a network run the full scdo service with the in-memory databases and the instant seal
+// engine, and are connected with each other by the in-memory p2p pipes. As the local shard number and
+// the event managers are process-wide, only one network runs at a time, and the scenarios across the
+// shards run the networks of the shards in turn, carrying the debts of the source shard to the target
+// shard, see Network.Debts and Network.SettleDebts.
+package testutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/consensus/dev"
+	"github.com/scdoproject/go-scdo/core"
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/scdoproject/go-scdo/database"
+	"github.com/scdoproject/go-scdo/log"
+	"github.com/scdoproject/go-scdo/node"
+	"github.com/scdoproject/go-scdo/p2p"
+	"github.com/scdoproject/go-scdo/p2p/discovery"
+	"github.com/scdoproject/go-scdo/scdo"
+)
+
+const (
+	// networkID is the network id of the in-process networks
+	networkID = "testutil"
+
+	// DefaultTimeout is the default timeout to wait for the nodes to connect or sync
+	DefaultTimeout = 10 * time.Second
+)
+
+var (
+	// DefaultBalance is the default balance of the pre-funded accounts in genesis
+	DefaultBalance = new(big.Int).Mul(big.NewInt(1000000), common.ScdoToWen)
+
+	// genesisTimestamp is the create time of the genesis block, which is overridden for the main shards
+	genesisTimestamp = big.NewInt(1596000000)
+
+	// networkLock allows only one network at a time, since the local shard number is process-wide
+	networkLock sync.Mutex
+)
+
+// Config is the configuration of the in-process network
+type Config struct {
+	Shard    uint     // shard of the network, 1 if not specified
+	Nodes    int      // number of the full nodes, 1 if not specified
+	Accounts int      // number of the pre-funded accounts in genesis, 1 if not specified
+	Balance  *big.Int // balance of the pre-funded accounts, DefaultBalance if not specified
+	Timeout  time.Duration
+}
+
+// Account is a pre-funded account in genesis, whose nonce is tracked by the txs sent in the network
+type Account struct {
+	Address common.Address
+	Key     *ecdsa.PrivateKey
+	Nonce   uint64
+}
+
+// Node is a full node of the network
+type Node struct {
+	Service  *scdo.ScdoService
+	Coinbase common.Address
+	Node     *discovery.Node
+
+	dataDir string
+}
+
+// Network is an in-process network of the full nodes in a shard. Blocks are mined on the first node
+// on demand, and synced to the other nodes through the p2p pipes.
+type Network struct {
+	Shard    uint
+	Nodes    []*Node
+	Accounts []*Account
+
+	t           testing.TB
+	timeout     time.Duration
+	disconnects []func()
+	prevShard   uint
+	closeOnce   sync.Once
+}
+
+// NewNetwork creates and starts the network of the config, and waits for the nodes to connect with
+// each other. The test fails if the network could not be started. It should be closed once done.
+func NewNetwork(t testing.TB, conf Config) *Network {
+	if conf.Shard == common.UndefinedShardNumber {
+		conf.Shard = 1
+	}
+
+	if conf.Nodes <= 0 {
+		conf.Nodes = 1
+	}
+
+	if conf.Accounts <= 0 {
+		conf.Accounts = 1
+	}
+
+	if conf.Balance == nil {
+		conf.Balance = DefaultBalance
+	}
+
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultTimeout
+	}
+
+	networkLock.Lock()
+	net := &Network{
+		Shard:     conf.Shard,
+		t:         t,
+		timeout:   conf.Timeout,
+		prevShard: common.LocalShardNumber,
+	}
+	common.LocalShardNumber = conf.Shard
+
+	genesis := core.GenesisInfo{
+		Accounts:        make(map[common.Address]*big.Int),
+		Difficult:       1,
+		ShardNumber:     conf.Shard,
+		CreateTimestamp: genesisTimestamp,
+	}
+
+	for i := 0; i < conf.Accounts; i++ {
+		addr, key := NewShardAccount(t, conf.Shard)
+		genesis.Accounts[addr] = new(big.Int).Set(conf.Balance)
+		net.Accounts = append(net.Accounts, &Account{Address: addr, Key: key})
+	}
+
+	for i := 0; i < conf.Nodes; i++ {
+		n, err := newNode(i, genesis)
+		if err != nil {
+			net.Close()
+			t.Fatalf("failed to create node %d, %s", i, err)
+		}
+
+		net.Nodes = append(net.Nodes, n)
+	}
+
+	if err := net.connect(); err != nil {
+		net.Close()
+		t.Fatal(err)
+	}
+
+	return net
+}
+
+// NewShardAccount generates a new account in the shard
+func NewShardAccount(t testing.TB, shard uint) (common.Address, *ecdsa.PrivateKey) {
+	addr, key, err := crypto.GenerateKeyPair(shard)
+	if err != nil {
+		t.Fatalf("failed to generate account in shard %d, %s", shard, err)
+	}
+
+	return *addr, key
+}
+
+// newNode creates and starts the full node with the in-memory databases and the instant seal engine
+func newNode(index int, genesis core.GenesisInfo) (*Node, error) {
+	dataDir, err := ioutil.TempDir("", "testutil")
+	if err != nil {
+		return nil, err
+	}
+
+	id, _, err := crypto.GenerateKeyPair(genesis.ShardNumber)
+	if err != nil {
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	coinbase, _, err := crypto.GenerateKeyPair(genesis.ShardNumber)
+	if err != nil {
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	conf := &node.Config{
+		BasicConfig: node.BasicConfig{
+			Name:           fmt.Sprintf("node%d", index),
+			DataDir:        dataDir,
+			MinerAlgorithm: common.DevAlgorithm,
+			DBEngine:       database.EngineMemory,
+		},
+		P2PConfig: p2p.Config{NetworkID: networkID},
+		ScdoConfig: node.ScdoConfig{
+			TxConf:        *core.DefaultTxPoolConfig(),
+			DebtConf:      *core.DefaultDebtPoolConfig(),
+			Coinbase:      *coinbase,
+			GenesisConfig: genesis,
+		},
+	}
+
+	var key interface{} = "ServiceContext"
+	ctx := context.WithValue(context.Background(), key, scdo.ServiceContext{DataDir: dataDir})
+	service, err := scdo.NewScdoService(ctx, conf, log.GetLogger(conf.BasicConfig.Name), dev.NewEngine(), nil, -1, false)
+	if err != nil {
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	if err = service.Start(nil); err != nil {
+		service.Stop()
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	return &Node{
+		Service:  service,
+		Coinbase: *coinbase,
+		Node:     discovery.NewNode(*id, nil, 0, genesis.ShardNumber),
+		dataDir:  dataDir,
+	}, nil
+}
+
+// connect connects every two nodes with the p2p pipes, and waits for the protocol handshakes
+func (net *Network) connect() error {
+	expected := len(net.Nodes) * (len(net.Nodes) - 1)
+	if expected == 0 {
+		return nil
+	}
+
+	added := make(chan struct{}, expected)
+	protocols := func(n *Node) []p2p.Protocol {
+		protos := n.Service.Protocols()
+		for i := range protos {
+			addPeer := protos[i].AddPeer
+			protos[i].AddPeer = func(peer *p2p.Peer, rw p2p.MsgReadWriter) bool {
+				if !addPeer(peer, rw) {
+					return false
+				}
+
+				added <- struct{}{}
+				return true
+			}
+		}
+
+		return protos
+	}
+
+	logger := log.GetLogger("testutil")
+	for i := 0; i < len(net.Nodes); i++ {
+		for j := i + 1; j < len(net.Nodes); j++ {
+			local, remote := net.Nodes[i], net.Nodes[j]
+			disconnect := p2p.PipePeers(local.Node, protocols(local), remote.Node, protocols(remote), logger)
+			net.disconnects = append(net.disconnects, disconnect)
+		}
+	}
+
+	timeout := time.After(net.timeout)
+	for i := 0; i < expected; i++ {
+		select {
+		case <-added:
+		case <-timeout:
+			return fmt.Errorf("only %d of %d peers connected in %s", i, expected, net.timeout)
+		}
+	}
+
+	return nil
+}
+
+// Close disconnects and stops the nodes, and restores the local shard number
+func (net *Network) Close() {
+	net.closeOnce.Do(func() {
+		for _, disconnect := range net.disconnects {
+			disconnect()
+		}
+
+		for _, n := range net.Nodes {
+			n.Service.Miner().Stop()
+			n.Service.Stop()
+			os.RemoveAll(n.dataDir)
+		}
+
+		common.LocalShardNumber = net.prevShard
+		networkLock.Unlock()
+	})
+}
@@ -0,0 +1,43 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package testutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Network_Sync(t *testing.T) {
+	net := NewNetwork(t, Config{Shard: 1, Nodes: 3})
+	defer net.Close()
+
+	to, _ := NewShardAccount(t, 1)
+	net.SendTx(net.Accounts[0], to, big.NewInt(100))
+	net.SendTx(net.Accounts[0], to, big.NewInt(200))
+
+	blocks := net.MineBlocks(1)
+	assert.Equal(t, len(blocks[0].Transactions), 3) // including the reward tx
+	net.AssertBalance(to, big.NewInt(300))
+}
+
+func Test_Network_CrossShard(t *testing.T) {
+	source := NewNetwork(t, Config{Shard: 1, Nodes: 2})
+	_, debt := source.SendCrossShardTx(source.Accounts[0], 2, big.NewInt(500))
+	debts := source.Debts(source.MineBlocks(1)...)
+	source.Close()
+
+	assert.Equal(t, len(debts), 1)
+	assert.Equal(t, debts[0].Hash, debt.Hash)
+
+	target := NewNetwork(t, Config{Shard: 2, Nodes: 2})
+	defer target.Close()
+
+	block := target.SettleDebts(debts)
+	assert.Equal(t, target.AssertDebtSettled(debt), block.HeaderHash)
+	target.AssertBalance(debt.Data.Account, big.NewInt(500))
+}
@@ -0,0 +1,317 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package trie
+
+import (
+	"bytes"
+	"container/list"
+	"sync"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/scdoproject/go-scdo/common"
+	"github.com/scdoproject/go-scdo/database"
+)
+
+const (
+	// DefaultNodeCacheSize is the default memory in bytes of the trie node cache
+	DefaultNodeCacheSize = 128 * 1024 * 1024
+
+	// nodeCacheCommits is the number of the recent commits whose nodes are always kept in the cache
+	nodeCacheCommits = 128
+)
+
+var (
+	metricsNodeCacheHitMeter  = metrics.GetOrRegisterMeter("trie.cache.hit", nil)
+	metricsNodeCacheMissMeter = metrics.GetOrRegisterMeter("trie.cache.miss", nil)
+)
+
+// NodeCache is an in-memory cache of the encoded trie nodes in front of the database, which
+// is shared by the tries of all blocks. As the adjacent blocks share most of the trie, the nodes
+// written by the recent commits are referenced and kept in the cache, and the other nodes are
+// evicted in LRU order when the cache is full. The nodes are only cached when the batch is
+// committed to the database, so the cache never has the nodes of the discarded batches.
+type NodeCache struct {
+	database.Database
+
+	prefix  []byte // db prefix of the trie nodes
+	maxSize int    // max memory in bytes of the cached nodes, which is exceeded only by the referenced nodes
+
+	lock    sync.Mutex
+	nodes   map[string]*cachedNode
+	clean   *list.List // unreferenced nodes, the most recently used at the front
+	size    int        // memory in bytes of all the cached nodes
+	commits [][]string // keys of the nodes of the recent commits, the oldest first
+}
+
+type cachedNode struct {
+	key  string
+	blob []byte
+	refs int           // number of the recent commits which contain the node
+	elem *list.Element // element in the clean list if not referenced
+}
+
+// NewNodeCache creates a trie node cache of the size in bytes in front of the database,
+// 0 means the default 128 MB.
+func NewNodeCache(db database.Database, prefix []byte, size int) *NodeCache {
+	if size <= 0 {
+		size = DefaultNodeCacheSize
+	}
+
+	return &NodeCache{
+		Database: db,
+		prefix:   common.CopyBytes(prefix),
+		maxSize:  size,
+		nodes:    make(map[string]*cachedNode),
+		clean:    list.New(),
+	}
+}
+
+func (c *NodeCache) isNode(key []byte) bool {
+	return bytes.HasPrefix(key, c.prefix)
+}
+
+// Get returns the value of the key from the cache, or the database if not cached.
+func (c *NodeCache) Get(key []byte) ([]byte, error) {
+	if !c.isNode(key) {
+		return c.Database.Get(key)
+	}
+
+	if blob, ok := c.get(string(key)); ok {
+		metricsNodeCacheHitMeter.Mark(1)
+		return blob, nil
+	}
+
+	metricsNodeCacheMissMeter.Mark(1)
+
+	value, err := c.Database.Get(key)
+	if err == nil && len(value) > 0 {
+		c.add(string(key), value)
+	}
+
+	return value, err
+}
+
+// Has returns whether the key is in the cache or the database.
+func (c *NodeCache) Has(key []byte) (bool, error) {
+	if c.isNode(key) {
+		if _, ok := c.get(string(key)); ok {
+			return true, nil
+		}
+	}
+
+	return c.Database.Has(key)
+}
+
+// Put writes the value of the key to the database, and caches the value of the trie node.
+func (c *NodeCache) Put(key []byte, value []byte) error {
+	if err := c.Database.Put(key, value); err != nil {
+		return err
+	}
+
+	if c.isNode(key) {
+		c.add(string(key), value)
+	}
+
+	return nil
+}
+
+// Delete deletes the key from the database and the cache.
+func (c *NodeCache) Delete(key []byte) error {
+	if err := c.Database.Delete(key); err != nil {
+		return err
+	}
+
+	if c.isNode(key) {
+		c.remove(string(key))
+	}
+
+	return nil
+}
+
+// DeleteSring deletes the key from the database and the cache.
+func (c *NodeCache) DeleteSring(key string) error {
+	return c.Delete([]byte(key))
+}
+
+// NewBatch creates a batch which caches the written trie nodes when committed.
+func (c *NodeCache) NewBatch() database.Batch {
+	return &nodeCacheBatch{
+		Batch: c.Database.NewBatch(),
+		cache: c,
+	}
+}
+
+// Size returns the number of the cached nodes and their memory in bytes.
+func (c *NodeCache) Size() (int, int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.nodes), c.size
+}
+
+func (c *NodeCache) get(key string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	node, ok := c.nodes[key]
+	if !ok {
+		return nil, false
+	}
+
+	if node.elem != nil {
+		c.clean.MoveToFront(node.elem)
+	}
+
+	return common.CopyBytes(node.blob), true
+}
+
+// add caches the unreferenced node
+func (c *NodeCache) add(key string, blob []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if node, ok := c.nodes[key]; ok {
+		if node.elem != nil {
+			c.clean.MoveToFront(node.elem)
+		}
+
+		return
+	}
+
+	node := &cachedNode{key: key, blob: common.CopyBytes(blob)}
+	node.elem = c.clean.PushFront(node)
+	c.nodes[key] = node
+	c.size += len(key) + len(blob)
+
+	c.evict()
+}
+
+func (c *NodeCache) remove(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.removeNode(key)
+}
+
+func (c *NodeCache) removeNode(key string) {
+	node, ok := c.nodes[key]
+	if !ok {
+		return
+	}
+
+	if node.elem != nil {
+		c.clean.Remove(node.elem)
+	}
+
+	delete(c.nodes, key)
+	c.size -= len(node.key) + len(node.blob)
+}
+
+// commit references the nodes written by a committed batch, and dereferences the nodes of the
+// oldest commit if there are too many commits.
+func (c *NodeCache) commit(nodes map[string][]byte, deleted []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, key := range deleted {
+		c.removeNode(key)
+	}
+
+	if len(nodes) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(nodes))
+	for key, blob := range nodes {
+		node, ok := c.nodes[key]
+		if !ok {
+			node = &cachedNode{key: key, blob: blob}
+			c.nodes[key] = node
+			c.size += len(key) + len(blob)
+		} else if node.elem != nil {
+			c.clean.Remove(node.elem)
+			node.elem = nil
+		}
+
+		node.refs++
+		keys = append(keys, key)
+	}
+
+	c.commits = append(c.commits, keys)
+	if len(c.commits) > nodeCacheCommits {
+		c.dereference(c.commits[0])
+		c.commits[0] = nil
+		c.commits = c.commits[1:]
+	}
+
+	c.evict()
+}
+
+// dereference releases the nodes of a commit, and the nodes without references can be evicted.
+func (c *NodeCache) dereference(keys []string) {
+	for _, key := range keys {
+		node, ok := c.nodes[key]
+		if !ok || node.refs == 0 {
+			continue
+		}
+
+		if node.refs--; node.refs == 0 {
+			node.elem = c.clean.PushFront(node)
+		}
+	}
+}
+
+// evict removes the least recently used unreferenced nodes until the cache is not full
+func (c *NodeCache) evict() {
+	for c.size > c.maxSize && c.clean.Len() > 0 {
+		c.removeNode(c.clean.Back().Value.(*cachedNode).key)
+	}
+}
+
+// nodeCacheBatch is the batch which flushes the written trie nodes to the cache when committed
+type nodeCacheBatch struct {
+	database.Batch
+	cache   *NodeCache
+	nodes   map[string][]byte
+	deleted []string
+}
+
+func (b *nodeCacheBatch) Put(key []byte, value []byte) {
+	b.Batch.Put(key, value)
+
+	if b.cache.isNode(key) {
+		if b.nodes == nil {
+			b.nodes = make(map[string][]byte)
+		}
+
+		b.nodes[string(key)] = common.CopyBytes(value)
+	}
+}
+
+func (b *nodeCacheBatch) Delete(key []byte) {
+	b.Batch.Delete(key)
+
+	if b.cache.isNode(key) {
+		delete(b.nodes, string(key))
+		b.deleted = append(b.deleted, string(key))
+	}
+}
+
+func (b *nodeCacheBatch) Commit() error {
+	if err := b.Batch.Commit(); err != nil {
+		return err
+	}
+
+	b.cache.commit(b.nodes, b.deleted)
+	b.nodes, b.deleted = nil, nil
+
+	return nil
+}
+
+func (b *nodeCacheBatch) Rollback() {
+	b.Batch.Rollback()
+	b.nodes, b.deleted = nil, nil
+}
@@ -0,0 +1,138 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package trie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NodeCache_Commit(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	cache := NewNodeCache(db, []byte("S"), 0)
+
+	trie := NewEmptyTrie([]byte("S"), cache)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, trie.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))), nil)
+	}
+
+	// not cached until the batch is committed
+	batch := cache.NewBatch()
+	root := trie.Commit(batch)
+	count, _ := cache.Size()
+	assert.Equal(t, count, 0)
+
+	assert.Equal(t, batch.Commit(), nil)
+	count, size := cache.Size()
+	assert.Equal(t, count > 0, true)
+	assert.Equal(t, size > 0, true)
+
+	// the committed nodes are referenced and loaded from the cache
+	assert.Equal(t, cache.clean.Len(), 0)
+
+	loaded, err := NewTrie(root, []byte("S"), cache)
+	assert.Equal(t, err, nil)
+	for i := 0; i < 100; i++ {
+		value, found := trieMustGet(loaded, []byte(fmt.Sprintf("key%d", i)))
+		assert.Equal(t, found, true)
+		assert.Equal(t, value, []byte(fmt.Sprintf("value%d", i)))
+	}
+
+	// rollback discards the nodes
+	assert.Equal(t, loaded.Put([]byte("new"), []byte("value")), nil)
+	batch = cache.NewBatch()
+	loaded.Commit(batch)
+	batch.Rollback()
+	assert.Equal(t, batch.Commit(), nil)
+	newCount, _ := cache.Size()
+	assert.Equal(t, newCount, count)
+
+	// the other keys are not cached
+	assert.Equal(t, cache.Put([]byte("other"), []byte("value")), nil)
+	newCount, _ = cache.Size()
+	assert.Equal(t, newCount, count)
+}
+
+func Test_NodeCache_Evict(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, db.Put([]byte(fmt.Sprintf("S%d", i)), make([]byte, 98)), nil)
+	}
+
+	// the cache holds 3 nodes of 100 bytes
+	cache := NewNodeCache(db, []byte("S"), 300)
+	for i := 0; i < 10; i++ {
+		value, err := cache.Get([]byte(fmt.Sprintf("S%d", i)))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(value), 98)
+	}
+
+	count, size := cache.Size()
+	assert.Equal(t, count, 3)
+	assert.Equal(t, size, 300)
+
+	// the recently used node is not evicted
+	_, err := cache.Get([]byte("S7"))
+	assert.Equal(t, err, nil)
+	_, err = cache.Get([]byte("S0"))
+	assert.Equal(t, err, nil)
+
+	_, ok := cache.get("S7")
+	assert.Equal(t, ok, true)
+	_, ok = cache.get("S8")
+	assert.Equal(t, ok, false)
+
+	// deleted from the cache and the database
+	assert.Equal(t, cache.Delete([]byte("S7")), nil)
+	_, ok = cache.get("S7")
+	assert.Equal(t, ok, false)
+	has, err := cache.Has([]byte("S7"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, has, false)
+}
+
+func Test_NodeCache_Dereference(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	cache := NewNodeCache(db, []byte("S"), 1)
+
+	// the referenced nodes are kept even if the cache is full
+	for i := 0; i < nodeCacheCommits; i++ {
+		batch := cache.NewBatch()
+		batch.Put([]byte(fmt.Sprintf("S%d", i)), []byte{1})
+		assert.Equal(t, batch.Commit(), nil)
+	}
+
+	count, _ := cache.Size()
+	assert.Equal(t, count, nodeCacheCommits)
+
+	// the nodes of the oldest commit are dereferenced and evicted
+	batch := cache.NewBatch()
+	batch.Put([]byte("S0"), []byte{1})
+	batch.Put([]byte("Snew"), []byte{1})
+	assert.Equal(t, batch.Commit(), nil)
+
+	count, _ = cache.Size()
+	assert.Equal(t, count, nodeCacheCommits+1)
+	assert.Equal(t, cache.nodes["S0"].refs, 1)
+
+	batch = cache.NewBatch()
+	batch.Put([]byte("Snext"), []byte{1})
+	assert.Equal(t, batch.Commit(), nil)
+
+	_, ok := cache.get("S1")
+	assert.Equal(t, ok, false)
+	_, ok = cache.get("S0")
+	assert.Equal(t, ok, true)
+}
@@ -464,7 +464,6 @@ func (t *Trie) delete(node noder, key []byte, descendant bool) (bool, noder, err
 
 // loadNode get node from memory cache or database
 func (t *Trie) loadNode(hash []byte) (noder, error) {
-	//TODO need cache nodes
 	key := append(t.dbprefix, hash...)
 	val, err := t.db.Get(key)
 	if err != nil || len(val) == 0 {
@@ -0,0 +1,73 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package trie
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrWalkStopped could be returned by the walk callback to stop the walk without error
+var ErrWalkStopped = errors.New("walk stopped")
+
+// Walk visits the key-value pairs of the trie in the order of keys. The nodes are loaded from the
+// database on demand and not cached in the trie, so that the whole trie could be scanned offline
+// with limited memory. The walk stops once fn returns an error, and ErrWalkStopped is not reported.
+func (t *Trie) Walk(fn func(key, value []byte) error) error {
+	err := t.walk(t.root, nil, fn)
+	if err == ErrWalkStopped {
+		return nil
+	}
+
+	return err
+}
+
+func (t *Trie) walk(node noder, path []byte, fn func(key, value []byte) error) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case hashNode:
+		child, err := t.loadNode(n)
+		if err != nil {
+			return err
+		}
+		return t.walk(child, path, fn)
+	case *ExtensionNode:
+		return t.walk(n.NextNode, appendNibbles(path, n.Key...), fn)
+	case *BranchNode:
+		for i, child := range n.Children {
+			if err := t.walk(child, appendNibbles(path, byte(i)), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *LeafNode:
+		return fn(hexToKeybytes(appendNibbles(path, n.Key...)), n.Value)
+	default:
+		panic(fmt.Sprintf("invalid node: %v", node))
+	}
+}
+
+// appendNibbles returns a new path of the nibbles appended, the path is shared by the siblings
+func appendNibbles(path []byte, nibbles ...byte) []byte {
+	result := make([]byte, len(path), len(path)+len(nibbles))
+	copy(result, path)
+	return append(result, nibbles...)
+}
+
+// hexToKeybytes is the reverse of keybytesToHex, the terminator nibble is ignored
+func hexToKeybytes(hex []byte) []byte {
+	if len(hex) > 0 && hex[len(hex)-1] == byte(numBranchChildren-1) {
+		hex = hex[:len(hex)-1]
+	}
+
+	key := make([]byte, len(hex)/2)
+	for i := range key {
+		key[i] = hex[i*2]*byte(numBranchChildren-1) + hex[i*2+1]
+	}
+
+	return key
+}
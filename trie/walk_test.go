@@ -0,0 +1,63 @@
+/**
+*  @file
+*  @copyright defined in scdo/LICENSE
+ */
+
+package trie
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/scdoproject/go-scdo/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Trie_Walk(t *testing.T) {
+	db, trie, remove := newTestTrie()
+	defer remove()
+
+	kvs := make(map[string][]byte)
+	var keys []string
+	for i := 0; i < 100; i++ {
+		key := crypto.MustHash(uint(i)).Bytes()[:i%20+1]
+		if _, ok := kvs[string(key)]; ok {
+			continue
+		}
+
+		kvs[string(key)] = []byte{byte(i)}
+		keys = append(keys, string(key))
+		assert.Equal(t, trie.Put(key, []byte{byte(i)}), nil)
+	}
+	sort.Strings(keys)
+
+	batch := db.NewBatch()
+	root := trie.Commit(batch)
+	assert.Equal(t, batch.Commit(), nil)
+
+	// walk the persisted trie
+	trie, err := NewTrie(root, []byte("trietest"), db)
+	assert.Equal(t, err, nil)
+
+	var walked []string
+	err = trie.Walk(func(key, value []byte) error {
+		assert.Equal(t, bytes.Equal(kvs[string(key)], value), true)
+		walked = append(walked, string(key))
+		return nil
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, walked, keys)
+
+	// stop the walk
+	walked = nil
+	err = trie.Walk(func(key, value []byte) error {
+		walked = append(walked, string(key))
+		if len(walked) == 10 {
+			return ErrWalkStopped
+		}
+		return nil
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, walked, keys[:10])
+}